@@ -1,5 +1,7 @@
 package ast
 
+import "github.com/arnodel/golua/token"
+
 // A BlockStat is a statement node that represents a block of statements,
 // optionally ending in a return statement (if Return is not a nil slice - note
 // that a bare return is encoded as a non-nil slice of length 0).
@@ -7,6 +9,14 @@ type BlockStat struct {
 	Location
 	Stats  []Stat
 	Return []ExpNode
+
+	// Comments in the block, in source order.  This is only set on the block
+	// returned by parsing.ParseChunk, when the scanner keeps comments (see
+	// scanner.WithComments).
+	Comments []token.Comment
+
+	// Type aliases defined in the block (see parsing.WithTypes).
+	TypeAliases []TypeAlias
 }
 
 var _ Stat = BlockStat{}
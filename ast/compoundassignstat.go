@@ -0,0 +1,46 @@
+package ast
+
+import (
+	"github.com/arnodel/golua/ops"
+)
+
+// CompoundAssignStat represents a compound assignment "var op= exp", e.g.
+// "x += 1" (see token.ExtCompoundAssign).  It is equivalent to
+// "var = var op exp", except that the table and key of var are evaluated only
+// once when var is an index expression.
+type CompoundAssignStat struct {
+	Location
+	Dest  Var
+	Op    ops.Op
+	Value ExpNode
+}
+
+var _ Stat = CompoundAssignStat{}
+
+// NewCompoundAssignStat makes a new CompoundAssignStat.
+func NewCompoundAssignStat(dst Var, op ops.Op, value ExpNode) CompoundAssignStat {
+	return CompoundAssignStat{
+		Location: MergeLocations(dst, value),
+		Dest:     dst,
+		Op:       op,
+		Value:    value,
+	}
+}
+
+// HWrite prints the AST in tree form.
+func (s CompoundAssignStat) HWrite(w HWriter) {
+	w.Writef("compound assign: %s", s.Op)
+	w.Indent()
+	w.Next()
+	w.Writef("dst: ")
+	s.Dest.HWrite(w)
+	w.Next()
+	w.Writef("value: ")
+	s.Value.HWrite(w)
+	w.Dedent()
+}
+
+// ProcessStat uses the given StatProcessor to process the receiver.
+func (s CompoundAssignStat) ProcessStat(p StatProcessor) {
+	p.ProcessCompoundAssignStat(s)
+}
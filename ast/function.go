@@ -11,6 +11,10 @@ type Function struct {
 	ParList
 	Body BlockStat
 	Name string
+
+	// ReturnTypes is the annotation of the types of the values returned by
+	// the function, nil if there is none.
+	ReturnTypes *TypeList
 }
 
 var _ ExpNode = Function{}
@@ -56,6 +60,12 @@ func (f Function) HWrite(w HWriter) {
 type ParList struct {
 	Params  []Name
 	HasDots bool
+
+	// Type annotations of the parameters.  ParamTypes is nil if there are none,
+	// otherwise it has the same length as Params, with nil for parameters
+	// without annotation.
+	ParamTypes []TypeNode
+	DotsType   TypeNode
 }
 
 // NewParList returns ParList instance for the given parameters.
@@ -7,12 +7,15 @@ func NewFunctionStat(fName Var, method Name, fx Function) AssignStat {
 	// TODO: include the "function" keywork in the location calculation
 	if method.Val != "" {
 		loc := fx.Locate()
-		fx = NewFunction(
-			nil, nil,
-			ParList{append([]Name{{Val: "self"}}, fx.Params...), fx.HasDots},
-			fx.Body,
-		)
+		parList := fx.ParList
+		parList.Params = append([]Name{{Val: "self"}}, fx.Params...)
+		if parList.ParamTypes != nil {
+			parList.ParamTypes = append([]TypeNode{nil}, fx.ParamTypes...)
+		}
+		returnTypes := fx.ReturnTypes
+		fx = NewFunction(nil, nil, parList, fx.Body)
 		fx.Location = loc
+		fx.ReturnTypes = returnTypes
 		fx.Name = method.FunctionName()
 		fName = NewIndexExp(fName, method.AstString())
 	} else {
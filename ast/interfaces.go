@@ -49,6 +49,8 @@ type StatProcessor interface {
 	ProcessAssignStat(AssignStat)
 	ProcessBlockStat(BlockStat)
 	ProcessBreakStat(BreakStat)
+	ProcessCompoundAssignStat(CompoundAssignStat)
+	ProcessContinueStat(ContinueStat)
 	ProcessEmptyStat(EmptyStat)
 	ProcessForInStat(ForInStat)
 	ProcessForStat(ForStat)
@@ -71,6 +73,7 @@ type ExpProcessor interface {
 	ProcessFunctionExp(Function)
 	ProcessFunctionCallExp(FunctionCall)
 	ProcessIndexExp(IndexExp)
+	ProcessInterpolatedStringExp(InterpolatedString)
 	ProcessNameExp(Name)
 	ProcessNilExp(Nil)
 	ProcessIntExp(Int)
@@ -0,0 +1,62 @@
+package ast
+
+import (
+	"github.com/arnodel/golua/luastrings"
+	"github.com/arnodel/golua/token"
+)
+
+// InterpolatedString is an interpolated string, e.g. `x = {x}` (see
+// token.ExtInterpolation).  Its value is the concatenation of its texts and
+// of the values of its expressions converted to strings with tostring.
+type InterpolatedString struct {
+	Location
+	Texts [][]byte  // Texts[i] is the text before Exps[i]
+	Exps  []ExpNode // There is one less expression than texts
+}
+
+var _ ExpNode = InterpolatedString{}
+
+// NewInterpolatedString returns an InterpolatedString from the tokens making
+// its texts (see token.INTERPSTRING) and the expressions between them, or an
+// error if the texts contain invalid escape sequences.
+func NewInterpolatedString(toks []*token.Token, exps []ExpNode) (s InterpolatedString, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err2, ok := r.(error)
+			if ok {
+				err = err2
+			}
+		}
+	}()
+	texts := make([][]byte, len(toks))
+	for i, tok := range toks {
+		// Remove the delimiters ("`", "{" or "}")
+		lit := luastrings.NormalizeNewLines(tok.Lit)
+		texts[i] = escapeSeqs.ReplaceAllFunc(lit[1:len(lit)-1], replaceEscapeSeq)
+	}
+	return InterpolatedString{
+		Location: LocFromTokens(toks[0], toks[len(toks)-1]),
+		Texts:    texts,
+		Exps:     exps,
+	}, nil
+}
+
+// ProcessExp uses the given ExpProcessor to process the receiver.
+func (s InterpolatedString) ProcessExp(p ExpProcessor) {
+	p.ProcessInterpolatedStringExp(s)
+}
+
+// HWrite prints a tree representation of the node.
+func (s InterpolatedString) HWrite(w HWriter) {
+	w.Writef("interpolated string")
+	w.Indent()
+	for i, text := range s.Texts {
+		w.Next()
+		w.Writef("%q", text)
+		if i < len(s.Exps) {
+			w.Next()
+			s.Exps[i].HWrite(w)
+		}
+	}
+	w.Dedent()
+}
@@ -68,6 +68,7 @@ type NameAttrib struct {
 	Location
 	Name   Name
 	Attrib LocalAttrib
+	Type   TypeNode // Type annotation, nil if there is none
 }
 
 // NewNameAttrib returns a new NameAttribe for the given name and attrib.
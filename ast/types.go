@@ -0,0 +1,195 @@
+package ast
+
+import (
+	"strings"
+)
+
+// A TypeNode is a type annotation, in the syntax accepted by the parser when
+// type annotations are enabled (see parsing.WithTypes).  Type annotations do
+// not change how code runs; they are checked by package typecheck.
+type TypeNode interface {
+	Node
+	String() string // The type in annotation syntax
+}
+
+// NamedType is a type node for a type given by its name, e.g. "number", "nil"
+// or a type alias.
+type NamedType struct {
+	Location
+	Name string
+}
+
+var _ TypeNode = NamedType{}
+
+// HWrite prints a tree representation of the node.
+func (t NamedType) HWrite(w HWriter) {
+	w.Writef("%s", t)
+}
+
+func (t NamedType) String() string {
+	return t.Name
+}
+
+// OptionalType is a type node for "T?", which is the type T or nil.
+type OptionalType struct {
+	Location
+	Type TypeNode
+}
+
+var _ TypeNode = OptionalType{}
+
+// HWrite prints a tree representation of the node.
+func (t OptionalType) HWrite(w HWriter) {
+	w.Writef("%s", t)
+}
+
+func (t OptionalType) String() string {
+	return typeOperandString(t.Type) + "?"
+}
+
+// UnionType is a type node for "T1 | T2 | ...".
+type UnionType struct {
+	Location
+	Types []TypeNode
+}
+
+var _ TypeNode = UnionType{}
+
+// HWrite prints a tree representation of the node.
+func (t UnionType) HWrite(w HWriter) {
+	w.Writef("%s", t)
+}
+
+func (t UnionType) String() string {
+	parts := make([]string, len(t.Types))
+	for i, tp := range t.Types {
+		parts[i] = typeOperandString(tp)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// ArrayType is a type node for "{T}", a table whose values of type T are
+// indexed by integers.
+type ArrayType struct {
+	Location
+	Elem TypeNode
+}
+
+var _ TypeNode = ArrayType{}
+
+// HWrite prints a tree representation of the node.
+func (t ArrayType) HWrite(w HWriter) {
+	w.Writef("%s", t)
+}
+
+func (t ArrayType) String() string {
+	return "{" + t.Elem.String() + "}"
+}
+
+// MapType is a type node for "{[K]: V}", a table with keys of type K and values
+// of type V.
+type MapType struct {
+	Location
+	Key, Value TypeNode
+}
+
+var _ TypeNode = MapType{}
+
+// HWrite prints a tree representation of the node.
+func (t MapType) HWrite(w HWriter) {
+	w.Writef("%s", t)
+}
+
+func (t MapType) String() string {
+	return "{[" + t.Key.String() + "]: " + t.Value.String() + "}"
+}
+
+// RecordType is a type node for "{name1: T1, name2: T2, ...}", a table with
+// the given fields.
+type RecordType struct {
+	Location
+	Fields []RecordField
+}
+
+// A RecordField is a field in a RecordType (it is not a node).
+type RecordField struct {
+	Name Name
+	Type TypeNode
+}
+
+var _ TypeNode = RecordType{}
+
+// HWrite prints a tree representation of the node.
+func (t RecordType) HWrite(w HWriter) {
+	w.Writef("%s", t)
+}
+
+func (t RecordType) String() string {
+	parts := make([]string, len(t.Fields))
+	for i, f := range t.Fields {
+		parts[i] = f.Name.Val + ": " + f.Type.String()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// FunctionType is a type node for "(P1, P2, ...) -> R" or "(P1, P2, ...) ->
+// (R1, R2, ...)".
+type FunctionType struct {
+	Location
+	Params  TypeList
+	Returns TypeList
+}
+
+var _ TypeNode = FunctionType{}
+
+// HWrite prints a tree representation of the node.
+func (t FunctionType) HWrite(w HWriter) {
+	w.Writef("%s", t)
+}
+
+func (t FunctionType) String() string {
+	ret := t.Returns.String()
+	if len(t.Returns.Types) == 1 && t.Returns.Etc == nil {
+		ret = typeOperandString(t.Returns.Types[0])
+	}
+	return t.Params.String() + " -> " + ret
+}
+
+// A TypeList is a list of types, e.g. the types of the parameters of a
+// function, optionally ending with "...T" for any number of values of type T
+// (it is not a node).
+type TypeList struct {
+	Types []TypeNode
+	Etc   TypeNode
+}
+
+func (l TypeList) String() string {
+	parts := make([]string, len(l.Types), len(l.Types)+1)
+	for i, tp := range l.Types {
+		parts[i] = tp.String()
+	}
+	if l.Etc != nil {
+		parts = append(parts, "..."+typeOperandString(l.Etc))
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// TypeAlias is the definition of a type name, i.e. "type Name = T".  Type
+// aliases are valid in the whole block where they are defined, including
+// before the definition (so they can be mutually recursive).
+type TypeAlias struct {
+	Location
+	Name Name
+	Type TypeNode
+}
+
+// typeOperandString returns the string for a type which is an operand of "?",
+// "|" or "->", which needs brackets if it is a union or a function type.
+func typeOperandString(t TypeNode) string {
+	switch t.(type) {
+	case UnionType, FunctionType:
+		return "(" + t.String() + ")"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,395 @@
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.  If
+// the result visitor w is not nil, Walk visits each of the children of node
+// with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(n Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order, in the same way as Walk in
+// package go/ast.  It starts by calling v.Visit(n); n must not be nil.  If the
+// visitor w returned by v.Visit(n) is not nil, Walk is invoked recursively with
+// visitor w for each of the non-nil children of n, followed by a call of
+// w.Visit(nil).
+//
+// Names are visited wherever they appear, including when they are declared
+// (e.g. local names, function parameters, loop variables, labels and method
+// names).
+func Walk(v Visitor, n Node) {
+	if v = v.Visit(n); v == nil {
+		return
+	}
+	switch n := deref(n).(type) {
+	case AssignStat:
+		for _, dest := range n.Dest {
+			Walk(v, dest)
+		}
+		walkExps(v, n.Src)
+	case CompoundAssignStat:
+		Walk(v, n.Dest)
+		Walk(v, n.Value)
+	case BlockStat:
+		for _, s := range n.Stats {
+			Walk(v, s)
+		}
+		walkExps(v, n.Return)
+	case ForInStat:
+		walkNames(v, n.Vars)
+		walkExps(v, n.Params)
+		Walk(v, n.Body)
+	case ForStat:
+		Walk(v, n.Var)
+		Walk(v, n.Start)
+		Walk(v, n.Stop)
+		if n.Step != nil {
+			Walk(v, n.Step)
+		}
+		Walk(v, n.Body)
+	case FunctionCall:
+		walkCall(v, *n.BFunctionCall)
+	case BFunctionCall:
+		walkCall(v, n)
+	case GotoStat:
+		Walk(v, n.Label)
+	case IfStat:
+		Walk(v, n.If.Cond)
+		Walk(v, n.If.Body)
+		for _, c := range n.ElseIfs {
+			Walk(v, c.Cond)
+			Walk(v, c.Body)
+		}
+		if n.Else != nil {
+			Walk(v, *n.Else)
+		}
+	case LabelStat:
+		Walk(v, n.Name)
+	case LocalFunctionStat:
+		Walk(v, n.Name)
+		Walk(v, n.Function)
+	case LocalStat:
+		for _, na := range n.NameAttribs {
+			Walk(v, na.Name)
+		}
+		walkExps(v, n.Values)
+	case RepeatStat:
+		Walk(v, n.Body)
+		Walk(v, n.Cond)
+	case WhileStat:
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+	case BinOp:
+		Walk(v, n.Left)
+		for _, r := range n.Right {
+			Walk(v, r.Operand)
+		}
+	case UnOp:
+		Walk(v, n.Operand)
+	case Function:
+		walkNames(v, n.Params)
+		Walk(v, n.Body)
+	case IndexExp:
+		Walk(v, n.Coll)
+		Walk(v, n.Idx)
+	case InterpolatedString:
+		walkExps(v, n.Exps)
+	case TableConstructor:
+		for _, f := range n.Fields {
+			if _, ok := f.Key.(NoTableKey); !ok {
+				Walk(v, f.Key)
+			}
+			Walk(v, f.Value)
+		}
+	case BreakStat, ContinueStat, EmptyStat, Bool, Etc, Name, Nil, Int, Float, String:
+		// No children
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+	v.Visit(nil)
+}
+
+func walkExps(v Visitor, exps []ExpNode) {
+	for _, e := range exps {
+		Walk(v, e)
+	}
+}
+
+func walkNames(v Visitor, names []Name) {
+	for _, n := range names {
+		Walk(v, n)
+	}
+}
+
+func walkCall(v Visitor, f BFunctionCall) {
+	Walk(v, f.Target)
+	if f.Method.Val != "" {
+		Walk(v, f.Method)
+	}
+	walkExps(v, f.Args)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(n Node) Visitor {
+	if f(n) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: it starts by calling f(n); n
+// must not be nil.  If f returns true, Inspect invokes f recursively for each
+// of the non-nil children of n, followed by a call of f(nil).
+func Inspect(n Node, f func(Node) bool) {
+	Walk(inspector(f), n)
+}
+
+// deref returns the value pointed to by nodes which the parser creates as
+// pointers.
+func deref(n Node) Node {
+	switch x := n.(type) {
+	case *BinOp:
+		return *x
+	case *UnOp:
+		return *x
+	case *ForStat:
+		return *x
+	case *ForInStat:
+		return *x
+	case *BFunctionCall:
+		return *x
+	case *BlockStat:
+		return *x
+	}
+	return n
+}
+
+// Rewrite returns a copy of the AST rooted at n in which each statement,
+// expression and block has been replaced with the result of calling f on it.
+// The AST is traversed in depth-first order and f is called on a node after
+// its children have been rewritten, so f sees the rewritten children.  The
+// AST n itself is not modified.
+//
+// The result of f must be valid where the node appears: a Stat in a list of
+// statements (or nil to remove the statement from the list), a BlockStat for
+// the body of a function or control structure, a Var for the target of an
+// assignment, a Function for the value of a local function statement and an
+// ExpNode anywhere else.  Rewrite panics otherwise.  Names are only passed to
+// f when they are used as expressions, not when they are declared.
+func Rewrite(n Node, f func(Node) Node) Node {
+	return rewriter(f).node(n)
+}
+
+type rewriter func(Node) Node
+
+func (f rewriter) node(n Node) Node {
+	switch n := n.(type) {
+	case AssignStat:
+		dest := make([]Var, len(n.Dest))
+		for i, v := range n.Dest {
+			r := f.node(v)
+			rv, ok := r.(Var)
+			if !ok {
+				panic(fmt.Sprintf("ast.Rewrite: %T is not assignable", r))
+			}
+			dest[i] = rv
+		}
+		n.Dest = dest
+		n.Src = f.exps(n.Src)
+		return f(n)
+	case CompoundAssignStat:
+		r := f.node(n.Dest)
+		rv, ok := r.(Var)
+		if !ok {
+			panic(fmt.Sprintf("ast.Rewrite: %T is not assignable", r))
+		}
+		n.Dest = rv
+		n.Value = f.exp(n.Value)
+		return f(n)
+	case BlockStat:
+		return f(f.blockContents(n))
+	case *BlockStat:
+		b := f.blockContents(*n)
+		return f(&b)
+	case ForInStat:
+		return f(f.forIn(n))
+	case *ForInStat:
+		s := f.forIn(*n)
+		return f(&s)
+	case ForStat:
+		return f(f.forStat(n))
+	case *ForStat:
+		s := f.forStat(*n)
+		return f(&s)
+	case FunctionCall:
+		c := f.call(*n.BFunctionCall)
+		return f(FunctionCall{&c})
+	case BFunctionCall:
+		return f(f.call(n))
+	case *BFunctionCall:
+		c := f.call(*n)
+		return f(&c)
+	case IfStat:
+		n.If = f.condStat(n.If)
+		if n.ElseIfs != nil {
+			elseIfs := make([]CondStat, len(n.ElseIfs))
+			for i, c := range n.ElseIfs {
+				elseIfs[i] = f.condStat(c)
+			}
+			n.ElseIfs = elseIfs
+		}
+		if n.Else != nil {
+			b := f.block(*n.Else)
+			n.Else = &b
+		}
+		return f(n)
+	case LocalFunctionStat:
+		r := f.node(n.Function)
+		rf, ok := r.(Function)
+		if !ok {
+			panic(fmt.Sprintf("ast.Rewrite: %T is not a function", r))
+		}
+		n.Function = rf
+		return f(n)
+	case LocalStat:
+		n.Values = f.exps(n.Values)
+		return f(n)
+	case RepeatStat:
+		n.CondStat = f.condStat(n.CondStat)
+		return f(n)
+	case WhileStat:
+		n.CondStat = f.condStat(n.CondStat)
+		return f(n)
+	case BinOp:
+		return f(f.binOp(n))
+	case *BinOp:
+		b := f.binOp(*n)
+		return f(&b)
+	case UnOp:
+		n.Operand = f.exp(n.Operand)
+		return f(n)
+	case *UnOp:
+		u := *n
+		u.Operand = f.exp(u.Operand)
+		return f(&u)
+	case Function:
+		n.Body = f.block(n.Body)
+		return f(n)
+	case IndexExp:
+		n.Coll = f.exp(n.Coll)
+		n.Idx = f.exp(n.Idx)
+		return f(n)
+	case InterpolatedString:
+		n.Exps = f.exps(n.Exps)
+		return f(n)
+	case TableConstructor:
+		if n.Fields != nil {
+			fields := make([]TableField, len(n.Fields))
+			for i, fld := range n.Fields {
+				if _, ok := fld.Key.(NoTableKey); !ok {
+					fld.Key = f.exp(fld.Key)
+				}
+				fld.Value = f.exp(fld.Value)
+				fields[i] = fld
+			}
+			n.Fields = fields
+		}
+		return f(n)
+	case BreakStat, ContinueStat, EmptyStat, GotoStat, LabelStat, Bool, Etc, Name, Nil, Int, Float, String:
+		// No children to rewrite
+	default:
+		panic(fmt.Sprintf("ast.Rewrite: unexpected node type %T", n))
+	}
+	return f(n)
+}
+
+func (f rewriter) exp(e ExpNode) ExpNode {
+	if e == nil {
+		return nil
+	}
+	r := f.node(e)
+	re, ok := r.(ExpNode)
+	if !ok {
+		panic(fmt.Sprintf("ast.Rewrite: %T is not an expression", r))
+	}
+	return re
+}
+
+func (f rewriter) exps(es []ExpNode) []ExpNode {
+	if es == nil {
+		return nil
+	}
+	rs := make([]ExpNode, len(es))
+	for i, e := range es {
+		rs[i] = f.exp(e)
+	}
+	return rs
+}
+
+func (f rewriter) block(b BlockStat) BlockStat {
+	r := f.node(b)
+	rb, ok := r.(BlockStat)
+	if !ok {
+		panic(fmt.Sprintf("ast.Rewrite: %T is not a block", r))
+	}
+	return rb
+}
+
+func (f rewriter) blockContents(b BlockStat) BlockStat {
+	var stats []Stat
+	if b.Stats != nil {
+		stats = make([]Stat, 0, len(b.Stats))
+	}
+	for _, s := range b.Stats {
+		r := f.node(s)
+		if r == nil {
+			continue
+		}
+		rs, ok := r.(Stat)
+		if !ok {
+			panic(fmt.Sprintf("ast.Rewrite: %T is not a statement", r))
+		}
+		stats = append(stats, rs)
+	}
+	b.Stats = stats
+	b.Return = f.exps(b.Return)
+	return b
+}
+
+func (f rewriter) condStat(c CondStat) CondStat {
+	c.Cond = f.exp(c.Cond)
+	c.Body = f.block(c.Body)
+	return c
+}
+
+func (f rewriter) forIn(s ForInStat) ForInStat {
+	s.Params = f.exps(s.Params)
+	s.Body = f.block(s.Body)
+	return s
+}
+
+func (f rewriter) forStat(s ForStat) ForStat {
+	s.Start = f.exp(s.Start)
+	s.Stop = f.exp(s.Stop)
+	s.Step = f.exp(s.Step)
+	s.Body = f.block(s.Body)
+	return s
+}
+
+func (f rewriter) call(c BFunctionCall) BFunctionCall {
+	c.Target = f.exp(c.Target)
+	c.Args = f.exps(c.Args)
+	return c
+}
+
+func (f rewriter) binOp(b BinOp) BinOp {
+	right := make([]Operation, len(b.Right))
+	b.Left = f.exp(b.Left)
+	for i, op := range b.Right {
+		right[i] = Operation{Op: op.Op, Operand: f.exp(op.Operand)}
+	}
+	b.Right = right
+	return b
+}
@@ -0,0 +1,164 @@
+package ast_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/luafmt"
+	"github.com/arnodel/golua/ops"
+	"github.com/arnodel/golua/parsing"
+	"github.com/arnodel/golua/scanner"
+)
+
+func parse(t *testing.T, src string) ast.BlockStat {
+	chunk, err := parsing.ParseChunk(scanner.New("test", []byte(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chunk
+}
+
+func format(t *testing.T, chunk ast.BlockStat) string {
+	var buf bytes.Buffer
+	if err := luafmt.Fprint(&buf, chunk); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+const testSource = `local a <const>, b = 1, {x = 2, 3}
+function t.m:f(x, ...)
+    for i = 1, x do
+        print(i)
+    end
+    for k, v in pairs(self) do
+        while not v do
+            v = k .. "x" + 1
+        end
+    end
+    if x then
+        goto l
+    elseif y then
+        return
+    else
+        repeat
+            x = (f(x))
+        until x[1]
+    end
+    ::l::
+end
+local function g()
+    return a
+end
+`
+
+func TestInspect(t *testing.T) {
+	var names []string
+	depth, maxDepth := 0, 0
+	ast.Inspect(parse(t, testSource), func(n ast.Node) bool {
+		if n == nil {
+			depth--
+			return false
+		}
+		depth++
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		if name, ok := n.(ast.Name); ok {
+			names = append(names, name.Val)
+		}
+		return true
+	})
+	expected := "a b t self x i x print i k v pairs self v v k x l y x f x x l g a"
+	if got := strings.Join(names, " "); got != expected {
+		t.Errorf("got names %q", got)
+	}
+	if depth != 0 {
+		t.Errorf("unbalanced calls, depth is %d", depth)
+	}
+	if maxDepth != 12 {
+		t.Errorf("got max depth %d", maxDepth)
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	count := 0
+	ast.Inspect(parse(t, testSource), func(n ast.Node) bool {
+		if n != nil {
+			count++
+		}
+		_, isFunc := n.(ast.Function)
+		return !isFunc
+	})
+	// The chunk, 3 statements and their 15 names and expressions (without
+	// looking inside functions)
+	if count != 19 {
+		t.Errorf("visited %d nodes", count)
+	}
+}
+
+func TestRewriteIdentity(t *testing.T) {
+	chunk := parse(t, testSource)
+	rewritten := ast.Rewrite(chunk, func(n ast.Node) ast.Node { return n })
+	if !reflect.DeepEqual(chunk, rewritten) {
+		t.Error("identity rewrite changed the AST")
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	chunk := parse(t, `
+local x = inc(y) * 2
+print(inc(x, 1))
+debug_only()
+do
+    debug_only()
+end
+`)
+	orig := format(t, chunk)
+	rewritten := ast.Rewrite(chunk, func(n ast.Node) ast.Node {
+		switch n := n.(type) {
+		case ast.FunctionCall:
+			name, ok := n.Target.(ast.Name)
+			switch {
+			case !ok:
+			case name.Val == "inc" && len(n.Args) == 1:
+				// Expand the inc(e) macro to e + 1
+				return ast.NewBinOp(n.Args[0], ops.OpAdd, nil, ast.NewInt(1))
+			case name.Val == "debug_only":
+				// Remove the statement
+				return nil
+			}
+		}
+		return n
+	}).(ast.BlockStat)
+	expected := `local x = (y + 1) * 2
+print(inc(x, 1))
+
+do
+end
+`
+	if got := format(t, rewritten); got != expected {
+		t.Errorf("got:\n%s", got)
+	}
+	if got := format(t, chunk); got != orig {
+		t.Errorf("original AST modified:\n%s", got)
+	}
+}
+
+func TestRewritePanics(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(r.(string), "ast.Int is not assignable") {
+			t.Errorf("unexpected panic value %v", r)
+		}
+	}()
+	ast.Rewrite(parse(t, "x = 1"), func(n ast.Node) ast.Node {
+		if _, ok := n.(ast.Name); ok {
+			return ast.NewInt(1)
+		}
+		return n
+	})
+}
@@ -5,11 +5,37 @@ import (
 
 	"github.com/arnodel/golua/ast"
 	"github.com/arnodel/golua/ir"
+	"github.com/arnodel/golua/token"
 )
 
+// Optimization levels that can be passed to WithOptimizationLevel.
+const (
+	// NoOptimization compiles the AST as it is.
+	NoOptimization = 0
+
+	// InlineFunctions compiles the bodies of small local functions and of
+	// immediately invoked function expressions in place of calls to them.
+	InlineFunctions = 1
+)
+
+// An Option configures how CompileLuaChunk compiles the AST.
+type Option func(*options)
+
+type options struct {
+	optimizationLevel int
+}
+
+// WithOptimizationLevel sets the level of optimization performed when
+// compiling the AST.  The default is NoOptimization.
+func WithOptimizationLevel(level int) Option {
+	return func(opts *options) {
+		opts.optimizationLevel = level
+	}
+}
+
 // CompileLuaChunk compiles the given block statement to IR code and returns a
 // slice or ir.Contant values and the index to the main code constant.
-func CompileLuaChunk(source string, s ast.BlockStat) (kidx uint, consts []ir.Constant, err error) {
+func CompileLuaChunk(source string, s ast.BlockStat, opts ...Option) (kidx uint, consts []ir.Constant, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			compErr, ok := r.(Error)
@@ -23,7 +49,14 @@ func CompileLuaChunk(source string, s ast.BlockStat) (kidx uint, consts []ir.Con
 	rootIrC := ir.NewCodeBuilder("<global chunk>", kp)
 	rootIrC.DeclareLocal("_ENV", rootIrC.GetFreeRegister())
 	irC := rootIrC.NewChild("<main chunk>")
-	c := &compiler{CodeBuilder: irC}
+	c := &compiler{
+		CodeBuilder: irC,
+		opts:        &options{},
+		inlineFuncs: map[variable]*inlineFunc{},
+	}
+	for _, opt := range opts {
+		opt(c.opts)
+	}
 	c.compileFunctionBody(ast.Function{
 		ParList: ast.ParList{HasDots: true},
 		Body:    s,
@@ -34,17 +67,24 @@ func CompileLuaChunk(source string, s ast.BlockStat) (kidx uint, consts []ir.Con
 
 type compiler struct {
 	*ir.CodeBuilder
+	opts *options
+
+	inlineFuncs map[variable]*inlineFunc // Local functions that can be inlined
+	inlineDepth int                      // Depth of nested inlined function bodies
 }
 
 func (c *compiler) NewChild(name string) *compiler {
 	return &compiler{
 		CodeBuilder: c.CodeBuilder.NewChild(name),
+		opts:        c.opts,
+		inlineFuncs: c.inlineFuncs,
 	}
 }
 
 // Names of various labels and registers used during compilation.
 const (
 	breakLblName    = ir.Name("<break>")
+	continueLblName = ir.Name("<continue>")
 	ellipsisRegName = ir.Name("...")
 	callerRegName   = ir.Name("<caller>")
 	loopFRegName    = ir.Name("<f>")
@@ -58,9 +98,22 @@ type Error struct {
 	Message string
 }
 
+// Error returns the error in the "line:col: message" format, or just the
+// message if the AST node has no location (e.g. it was decoded from JSON or
+// made by an AST transformer).
 func (e Error) Error() string {
-	loc := e.Where.Locate().StartPos()
-	return fmt.Sprintf("%d:%d: %s", loc.Line, loc.Column, e.Message)
+	if pos := e.Pos(); pos != nil {
+		return fmt.Sprintf("%d:%d: %s", pos.Line, pos.Column, e.Message)
+	}
+	return e.Message
+}
+
+// Pos returns the position of the error, which may be nil.
+func (e Error) Pos() *token.Pos {
+	if e.Where == nil {
+		return nil
+	}
+	return e.Where.Locate().StartPos()
 }
 
 // The compiler uses other packages that may return non nil errors only if there
@@ -19,6 +19,19 @@ var _ ast.ExpProcessor = (*expCompiler)(nil)
 
 // ProcessBFunctionCallExp compiles a BFunctionCall
 func (c *expCompiler) ProcessBFunctionCallExp(f ast.BFunctionCall) {
+	if fn, ok := c.getInlineFunc(f); ok {
+		ret, done := c.compileInlineBody(fn, f)
+		if len(ret) == 0 {
+			c.emitLoadConst(f, ir.NilType{}, c.dst)
+		} else {
+			c.compileExpInto(ret[0], c.dst)
+			c.TakeRegister(c.dst)
+			c.compileDiscardedExps(ret[1:])
+			c.ReleaseRegister(c.dst)
+		}
+		done()
+		return
+	}
 	c.compileCall(f, false)
 	c.emitInstr(f, ir.Receive{Dst: []ir.Register{c.dst}})
 }
@@ -135,6 +148,40 @@ func (c *expCompiler) ProcessIndexExp(e ast.IndexExp) {
 	c.ReleaseRegister(tReg)
 }
 
+// ProcessInterpolatedStringExp compiles an InterpolatedString.
+func (c *expCompiler) ProcessInterpolatedStringExp(s ast.InterpolatedString) {
+	c.CompileExp(interpolatedStringExp(s))
+}
+
+// interpolatedStringExp returns a standard expression equivalent to an
+// interpolated string: the concatenation of its texts and of its expressions
+// converted with the global tostring function.
+func interpolatedStringExp(s ast.InterpolatedString) ast.ExpNode {
+	var parts []ast.ExpNode
+	for i, text := range s.Texts {
+		if len(text) > 0 || len(s.Exps) == 0 {
+			parts = append(parts, ast.String{Location: s.Location, Val: text})
+		}
+		if i < len(s.Exps) {
+			e := s.Exps[i]
+			loc := e.Locate()
+			parts = append(parts, ast.FunctionCall{BFunctionCall: &ast.BFunctionCall{
+				Location: loc,
+				Target:   globalVar(ast.Name{Location: loc, Val: "tostring"}),
+				Args:     []ast.ExpNode{e},
+			}})
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	concat := &ast.BinOp{Location: s.Location, Left: parts[0], OpType: ops.OpConcat}
+	for _, part := range parts[1:] {
+		concat.Right = append(concat.Right, ast.Operation{Op: ops.OpConcat, Operand: part})
+	}
+	return concat
+}
+
 // ProcessNameExp compiles a NameExp.
 func (c *expCompiler) ProcessNameExp(n ast.Name) {
 	// Is it bound to a local name?
@@ -169,7 +216,8 @@ func (c *expCompiler) ProcessStringExp(s ast.String) {
 
 // ProcessTableConstructorExp compiles a TableConstructorExp.
 func (c *expCompiler) ProcessTableConstructorExp(t ast.TableConstructor) {
-	c.emitInstr(t, ir.MkTable{Dst: c.dst})
+	arrSize, hashSize := tableConstructorSize(t)
+	c.emitInstr(t, ir.MkTable{Dst: c.dst, ArraySize: arrSize, HashSize: hashSize})
 	c.TakeRegister(c.dst)
 	currImplicitKey := 1
 	for i, field := range t.Fields {
@@ -204,6 +252,20 @@ func (c *expCompiler) ProcessTableConstructorExp(t ast.TableConstructor) {
 	c.ReleaseRegister(c.dst)
 }
 
+// tableConstructorSize returns the number of items in the array part and the
+// hash part of the table built by t (not counting the values of a trailing
+// multiple-value expression).
+func tableConstructorSize(t ast.TableConstructor) (arrSize, hashSize int) {
+	for i, field := range t.Fields {
+		if _, noKey := field.Key.(ast.NoTableKey); !noKey {
+			hashSize++
+		} else if _, ok := field.Value.(ast.TailExpNode); !ok || i < len(t.Fields)-1 {
+			arrSize++
+		}
+	}
+	return
+}
+
 // ProcessUnOpExp compiles a UnOpExp.
 func (c *expCompiler) ProcessUnOpExp(u ast.UnOp) {
 	c.emitInstr(u, ir.Transform{
@@ -242,6 +304,20 @@ func (c tailExpCompiler) ProcessEtcTailExp(e ast.Etc) {
 
 // ProcessFunctionCallTailExp compiles a FunctionCall tail expression.
 func (c tailExpCompiler) ProcessFunctionCallTailExp(f ast.FunctionCall) {
+	if fn, ok := c.getInlineFunc(*f.BFunctionCall); ok {
+		ret, done := c.compileInlineBody(fn, *f.BFunctionCall)
+		regs := make([]ir.Register, len(c.dsts))
+		c.compileExpList(ret, regs)
+		if len(ret) > len(regs) {
+			c.compileDiscardedExps(ret[len(regs):])
+		}
+		for i, reg := range regs {
+			c.emitMove(f, c.dsts[i], reg)
+			c.ReleaseRegister(reg)
+		}
+		done()
+		return
+	}
 	c.compileCall(*f.BFunctionCall, false)
 	c.emitInstr(f, ir.Receive{Dst: c.dsts})
 }
@@ -1,6 +1,8 @@
 package astcomp
 
 import (
+	"fmt"
+
 	"github.com/arnodel/golua/ast"
 	"github.com/arnodel/golua/ir"
 	"github.com/arnodel/golua/ops"
@@ -24,6 +26,65 @@ func (c *compiler) ProcessAssignStat(s ast.AssignStat) {
 	c.compileAssignments(s.Dest, resultRegs)
 }
 
+// ProcessCompoundAssignStat compiles a CompoundAssignStat.
+func (c *compiler) ProcessCompoundAssignStat(s ast.CompoundAssignStat) {
+	dest, ok := s.Dest.(ast.IndexExp)
+	if !ok {
+		// Evaluating a name twice has no side effects, so name op= exp can be
+		// compiled as name = name op exp.
+		c.ProcessAssignStat(ast.AssignStat{
+			Location: s.Location,
+			Dest:     []ast.Var{s.Dest},
+			Src: []ast.ExpNode{&ast.BinOp{
+				Location: s.Location,
+				Left:     s.Dest,
+				OpType:   s.Op.Type(),
+				Right:    []ast.Operation{{Op: s.Op, Operand: s.Value}},
+			}},
+		})
+		return
+	}
+
+	// Evaluate the table and the key only once
+	tReg := c.GetFreeRegister()
+	c.compileExpInto(dest.Coll, tReg)
+	c.TakeRegister(tReg)
+	iReg := c.GetFreeRegister()
+	c.compileExpInto(dest.Idx, iReg)
+	c.TakeRegister(iReg)
+	vReg := c.GetFreeRegister()
+	c.emitInstr(dest, ir.Lookup{
+		Dst:   vReg,
+		Table: tReg,
+		Index: iReg,
+	})
+	c.TakeRegister(vReg)
+	c.emitInstr(s, ir.Combine{
+		Op:   s.Op,
+		Dst:  vReg,
+		Lsrc: vReg,
+		Rsrc: c.compileExpNoDestHint(s.Value),
+	})
+	c.emitInstr(dest, ir.SetIndex{
+		Table: tReg,
+		Index: iReg,
+		Src:   vReg,
+	})
+	c.ReleaseRegister(vReg)
+	c.ReleaseRegister(iReg)
+	c.ReleaseRegister(tReg)
+}
+
+// ProcessContinueStat compiles a ContinueStat.
+func (c *compiler) ProcessContinueStat(s ast.ContinueStat) {
+	if !c.CodeBuilder.EmitJump(continueLblName, getLine(s)) {
+		panic(Error{
+			Where:   s,
+			Message: "'continue' outside a loop",
+		})
+	}
+}
+
 // ProcessBlockStat compiles a BlockStat.
 func (c *compiler) ProcessBlockStat(s ast.BlockStat) {
 	c.PushContext()
@@ -87,7 +148,7 @@ func (c *compiler) ProcessForInStat(s ast.ForInStat) {
 	endLbl := c.DeclareGotoLabelNoLine(breakLblName)
 	c.emitInstr(s, ir.JumpIf{Cond: testReg, Label: endLbl})
 	c.emitInstr(s, ir.Transform{Dst: varReg, Op: ops.OpId, Src: var1})
-	c.compileBlock(s.Body)
+	c.compileLoopBody(s.Body)
 
 	c.emitInstr(s, ir.Jump{Label: loopLbl})
 
@@ -143,7 +204,7 @@ func (c *compiler) ProcessForStat(s ast.ForStat) {
 	// iter <- start
 	ir.EmitMoveNoLine(c.CodeBuilder, iterReg, startReg)
 	c.DeclareLocal(ir.Name(s.Var.Val), iterReg)
-	c.compileBlock(s.Body)
+	c.compileLoopBody(s.Body)
 	c.PopContext()
 
 	//Advance the for loop
@@ -169,6 +230,12 @@ func (c *compiler) ProcessForStat(s ast.ForStat) {
 
 // ProcessFunctionCallStat compiles a FunctionCallStat.
 func (c *compiler) ProcessFunctionCallStat(f ast.FunctionCall) {
+	if fn, ok := c.getInlineFunc(*f.BFunctionCall); ok {
+		ret, done := c.compileInlineBody(fn, *f.BFunctionCall)
+		c.compileDiscardedExps(ret)
+		done()
+		return
+	}
 	c.compileCall(*f.BFunctionCall, false)
 	c.emitInstr(f, ir.Receive{})
 }
@@ -250,7 +317,15 @@ func (c *compiler) ProcessRepeatStat(s ast.RepeatStat) {
 
 	loopLbl := c.GetNewLabel()
 	must(c.EmitLabelNoLine(loopLbl))
-	pop := c.compileBlockNoPop(s.Body, false)
+	continueAt := -1
+	if i, loc := continueIndex(s.Body.Stats); loc != nil {
+		checkRepeatContinue(s, i, loc)
+		continueAt = i
+	}
+	pop := c.compileBlockNoPop(s.Body, false, continueAt)
+	if continueAt >= 0 {
+		must(c.EmitGotoLabel(continueLblName))
+	}
 	condReg := c.compileExpNoDestHint(s.Cond)
 	negReg := c.GetFreeRegister()
 	c.emitInstr(s.Cond, ir.Transform{
@@ -276,7 +351,11 @@ func (c *compiler) ProcessWhileStat(s ast.WhileStat) {
 	loopLbl := c.GetNewLabel()
 	must(c.EmitLabelNoLine(loopLbl))
 
-	c.compileCond(s.CondStat, stopLbl)
+	condReg := c.compileExpNoDestHint(s.Cond)
+	c.emitInstr(s.Cond, ir.JumpIf{Cond: condReg, Label: stopLbl, Not: true})
+	c.PushContext()
+	c.compileLoopBody(s.Body)
+	c.PopContext()
 
 	c.emitInstr(s, ir.Jump{Label: loopLbl}) // TODO: better location
 
@@ -293,10 +372,27 @@ func (c *compiler) CompileStat(s ast.Stat) {
 //
 
 func (c *compiler) compileBlock(s ast.BlockStat) {
-	c.compileBlockNoPop(s, true)()
+	c.compileBlockNoPop(s, true, -1)()
+}
+
+// compileLoopBody compiles the body of a loop, followed by the target of the
+// continue statements it contains.
+func (c *compiler) compileLoopBody(s ast.BlockStat) {
+	if _, loc := continueIndex(s.Stats); loc == nil {
+		c.compileBlock(s)
+		return
+	}
+	c.DeclareGotoLabelNoLine(continueLblName)
+	c.compileBlock(s)
+	must(c.EmitGotoLabel(continueLblName))
 }
 
-func (c *compiler) compileBlockNoPop(s ast.BlockStat, complete bool) func() {
+// compileBlockNoPop compiles a block and returns a function that pops the
+// contexts of the locals it declares.  If continueAt is not negative, the
+// target of continue statements is declared before compiling statement
+// continueAt of the block, so that it is in the scope of the locals declared
+// before it (see ProcessRepeatStat).
+func (c *compiler) compileBlockNoPop(s ast.BlockStat, complete bool, continueAt int) func() {
 	totalDepth := 0
 	noBackLabels := getLabels(c.CodeBuilder, s.Stats)
 	truncLen := len(s.Stats)
@@ -310,23 +406,16 @@ func (c *compiler) compileBlockNoPop(s ast.BlockStat, complete bool) func() {
 			c.PushContext()
 			getLabels(c.CodeBuilder, s.Stats[i+1:truncLen])
 		}
+		if i == continueAt {
+			c.DeclareGotoLabelNoLine(continueLblName)
+		}
 		c.CompileStat(stat)
+		if fs, ok := stat.(ast.LocalFunctionStat); ok && c.inlining() {
+			c.registerInlineFunc(fs, s.Stats[i+1:], s.Return)
+		}
 	}
 	if s.Return != nil {
-		if fc, ok := c.getTailCall(s.Return); ok {
-			c.compileCall(*fc.BFunctionCall, true)
-		} else {
-			contReg := c.getCallerReg()
-			c.compilePushArgs(s.Return, contReg)
-			var loc ast.Locator
-			if len(s.Return) > 0 {
-				loc = s.Return[0]
-			}
-			c.emitInstr(loc, ir.Call{
-				Cont: contReg,
-				Tail: true,
-			})
-		}
+		c.compileReturn(s.Return)
 	}
 	return func() {
 		for ; totalDepth > 0; totalDepth-- {
@@ -335,6 +424,24 @@ func (c *compiler) compileBlockNoPop(s ast.BlockStat, complete bool) func() {
 	}
 }
 
+func (c *compiler) compileReturn(exps []ast.ExpNode) {
+	fc, ok := c.getTailCall(exps)
+	if !ok {
+		contReg := c.getCallerReg()
+		c.compilePushArgs(exps, contReg)
+		var loc ast.Locator
+		if len(exps) > 0 {
+			loc = exps[0]
+		}
+		c.emitInstr(loc, ir.Call{
+			Cont: contReg,
+			Tail: true,
+		})
+	} else {
+		c.compileCall(*fc.BFunctionCall, true)
+	}
+}
+
 // Declares goto labels for the statements in order, stopping when encountering
 // a local variable declaration.  Return true if the whole slice was processed
 // (so no need to get back labels)
@@ -380,6 +487,56 @@ func getBackLabels(c *ir.CodeBuilder, statements []ast.Stat) int {
 	return count
 }
 
+// continueIndex returns the index of the first statement containing a continue
+// statement for the loop whose body is made of stats, and the location of that
+// continue statement.  The location is nil if there is none.
+func continueIndex(stats []ast.Stat) (int, ast.Locator) {
+	for i, stat := range stats {
+		var loc ast.Locator
+		ast.Inspect(stat, func(n ast.Node) bool {
+			switch n.(type) {
+			case ast.ContinueStat:
+				loc = n
+			case ast.WhileStat, ast.RepeatStat, ast.ForStat, *ast.ForStat, ast.ForInStat, *ast.ForInStat, ast.Function:
+				// Continue statements in there are for another loop
+				return false
+			}
+			return loc == nil
+		})
+		if loc != nil {
+			return i, loc
+		}
+	}
+	return -1, nil
+}
+
+// checkRepeatContinue checks that the condition of a repeat loop does not use
+// local variables declared after the first continue statement of its body,
+// because they would not be initialised when the continue statement is
+// executed.  The continue statement at loc is in statement i of the body.
+func checkRepeatContinue(s ast.RepeatStat, i int, loc ast.Locator) {
+	declared := map[string]bool{}
+	for _, stat := range s.Body.Stats[i+1:] {
+		switch x := stat.(type) {
+		case ast.LocalStat:
+			for _, na := range x.NameAttribs {
+				declared[na.Name.Val] = true
+			}
+		case ast.LocalFunctionStat:
+			declared[x.Name.Val] = true
+		}
+	}
+	ast.Inspect(s.Cond, func(n ast.Node) bool {
+		if name, ok := n.(ast.Name); ok && declared[name.Val] {
+			panic(Error{
+				Where:   loc,
+				Message: fmt.Sprintf("'continue' jumps into the scope of local '%s' used in the 'until' condition", name.Val),
+			})
+		}
+		return true
+	})
+}
+
 func (c *compiler) getTailCall(rtn []ast.ExpNode) (ast.FunctionCall, bool) {
 	if len(rtn) != 1 || c.HasPendingCloseActions() {
 		return ast.FunctionCall{}, false
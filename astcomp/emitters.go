@@ -11,7 +11,8 @@ import (
 // number when emitting instructions.
 
 func (c *compiler) emitInstr(l ast.Locator, instr ir.Instruction) {
-	c.CodeBuilder.Emit(instr, getLine(l))
+	line, column := getPos(l)
+	c.CodeBuilder.EmitAt(instr, line, column)
 }
 
 func (c *compiler) emitJump(l ast.Locator, lbl ir.Name) {
@@ -32,11 +33,17 @@ func (c *compiler) emitMove(l ast.Locator, dst, src ir.Register) {
 }
 
 func getLine(l ast.Locator) int {
+	line, _ := getPos(l)
+	return line
+}
+
+// getPos returns the line and column where l starts (0 if unknown).
+func getPos(l ast.Locator) (line, column int) {
 	if l != nil {
 		locStart := l.Locate().StartPos()
 		if locStart != nil {
-			return locStart.Line
+			return locStart.Line, locStart.Column
 		}
 	}
-	return 0
+	return 0, 0
 }
@@ -0,0 +1,521 @@
+package astcomp
+
+import (
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ir"
+)
+
+// Functions whose body has more AST nodes than this are not inlined.
+const maxInlineSize = 40
+
+// Calls are not inlined in code which is already inlined this many times deep.
+// This bounds the growth of the code.
+const maxInlineDepth = 3
+
+// An inlineFunc is a function that can be compiled in place of calls to it.
+type inlineFunc struct {
+	ast.Function
+	bindings []binding // How the free names of the function must be resolved
+}
+
+// A variable is identified by the code builder it is defined in and its
+// register in that code builder.
+type variable struct {
+	home *ir.CodeBuilder
+	reg  ir.Register
+}
+
+// A binding records what a name resolved to where a function was defined.
+type binding struct {
+	name ir.Name
+	variable
+	ok bool // If false, the name is not bound to a variable
+}
+
+func (c *compiler) inlining() bool {
+	return c.opts.optimizationLevel >= InlineFunctions
+}
+
+// registerInlineFunc records that calls to the local function defined by s can
+// be inlined, if that is possible.  The rest of the block in which the function
+// is defined must be given in order to check that the local is never assigned
+// to.
+func (c *compiler) registerInlineFunc(s ast.LocalFunctionStat, rest []ast.Stat, ret []ast.ExpNode) {
+	a := analyseFunction(s.Function)
+	if !a.inlinable() || a.isFree(s.Name.Val) {
+		return
+	}
+	ra := newInlineAnalyser()
+	ra.stats(rest)
+	ra.exps(ret)
+	if ra.assigned[s.Name.Val] {
+		return
+	}
+	f, ok := c.resolveName(ir.Name(s.Name.Val))
+	if !ok {
+		panic(compilerBug{})
+	}
+	c.inlineFuncs[f] = &inlineFunc{
+		Function: s.Function,
+		bindings: c.resolveNames(a.free),
+	}
+}
+
+func (c *compiler) resolveName(name ir.Name) (variable, bool) {
+	home, reg, ok := c.ResolveName(name)
+	return variable{home: home, reg: reg}, ok
+}
+
+// resolveNames returns the bindings of the given names in the current scope.
+func (c *compiler) resolveNames(names []string) []binding {
+	var bindings []binding
+	hasGlobals := false
+	for _, name := range names {
+		v, ok := c.resolveName(ir.Name(name))
+		bindings = append(bindings, binding{name: ir.Name(name), variable: v, ok: ok})
+		hasGlobals = hasGlobals || !ok
+	}
+	if hasGlobals {
+		// Globals are looked up in _ENV
+		v, ok := c.resolveName("_ENV")
+		bindings = append(bindings, binding{name: "_ENV", variable: v, ok: ok})
+	}
+	return bindings
+}
+
+// getInlineFunc returns the function that the call f can be replaced with, if
+// any.
+func (c *compiler) getInlineFunc(f ast.BFunctionCall) (*inlineFunc, bool) {
+	if !c.inlining() || c.inlineDepth >= maxInlineDepth || f.Method.Val != "" {
+		return nil, false
+	}
+	var fn *inlineFunc
+	switch t := f.Target.(type) {
+	case ast.Name:
+		v, ok := c.resolveName(ir.Name(t.Val))
+		if !ok {
+			return nil, false
+		}
+		fn = c.inlineFuncs[v]
+		if fn == nil {
+			return nil, false
+		}
+		// The free names of the function must resolve to the same variables
+		// at the call site, i.e. they must not be shadowed.
+		for _, b := range fn.bindings {
+			v, ok := c.resolveName(b.name)
+			if ok != b.ok || ok && v != b.variable {
+				return nil, false
+			}
+		}
+	case ast.Function:
+		// An immediately invoked function expression, its free names are
+		// resolved at the call site.
+		if !analyseFunction(t).inlinable() {
+			return nil, false
+		}
+		fn = &inlineFunc{Function: t}
+	default:
+		return nil, false
+	}
+	// Extra arguments would have to be evaluated and discarded, keep it simple.
+	if len(f.Args) > len(fn.Params) {
+		return nil, false
+	}
+	return fn, true
+}
+
+// compileInlineBody compiles the body of fn in place of the call f to it.  It
+// returns the expressions returned by the function, which should be compiled by
+// the caller before calling the returned function to close the scope of the
+// function body.
+//
+// The compiled instructions keep the line numbers of the function body and
+// belong to an inline frame recording the call, so the function appears in
+// tracebacks and to debug hooks as if it was called.
+func (c *compiler) compileInlineBody(fn *inlineFunc, f ast.BFunctionCall) ([]ast.ExpNode, func()) {
+	paramRegs := make([]ir.Register, len(fn.Params))
+	c.compileExpList(f.Args, paramRegs)
+	line, column := getPos(f)
+	c.PushInlineFrame(fn.Name, line, column)
+	c.PushContext()
+	for i, p := range fn.Params {
+		c.ReleaseRegister(paramRegs[i])
+		c.DeclareLocal(ir.Name(p.Val), paramRegs[i])
+	}
+	c.inlineDepth++
+	pop := c.compileBlockNoPop(ast.BlockStat{Stats: fn.Body.Stats}, false, -1)
+	return fn.Body.Return, func() {
+		pop()
+		if !c.InlineFrameHasCode() {
+			// The call must be seen by debug hooks, so emit something
+			// harmless for the function to be in.
+			reg := c.GetFreeRegister()
+			c.TakeRegister(reg)
+			ir.EmitConstant(c.CodeBuilder, ir.NilType{}, reg, getEndLine(fn))
+			c.ReleaseRegister(reg)
+		}
+		c.inlineDepth--
+		c.PopInlineFrame()
+		c.PopContext()
+	}
+}
+
+// getEndLine returns the line where l ends, or starts if that is not known.
+func getEndLine(l ast.Locator) int {
+	if end := l.Locate().EndPos(); end != nil {
+		return end.Line
+	}
+	return getLine(l)
+}
+
+// compileDiscardedExps compiles expressions whose values are not needed, for
+// their side effects.
+func (c *compiler) compileDiscardedExps(exps []ast.ExpNode) {
+	for _, e := range exps {
+		if fc, ok := e.(ast.FunctionCall); ok {
+			c.ProcessFunctionCallStat(fc)
+		} else {
+			c.compileExpNoDestHint(e)
+		}
+	}
+}
+
+// The inlineAnalyser walks the AST of a function to find out whether it can be
+// inlined.
+type inlineAnalyser struct {
+	scopes    []map[string]bool // Local names in scope
+	free      []string          // Names used but not defined in the function
+	isFreeSet map[string]bool
+	assigned  map[string]bool // Names assigned to anywhere
+
+	size      int // Number of nodes
+	funcDepth int // Number of nested functions being walked
+	loopDepth int // Number of nested loops in the current function
+
+	hasFunction  bool // Contains a nested function definition
+	hasEtc       bool // Uses "..."
+	hasGoto      bool // Contains goto or labels
+	hasClose     bool // Declares a <close> variable
+	hasBadBreak  bool // Contains a break or continue which is not inside a loop
+	hasBadReturn bool // Contains a return other than at the end of the body
+	hasTailCall  bool // Returns the result of a function call
+}
+
+var _ ast.StatProcessor = (*inlineAnalyser)(nil)
+var _ ast.ExpProcessor = (*inlineAnalyser)(nil)
+var _ ast.VarProcessor = (*inlineAnalyser)(nil)
+
+func newInlineAnalyser() *inlineAnalyser {
+	return &inlineAnalyser{
+		scopes:    []map[string]bool{{}},
+		isFreeSet: map[string]bool{},
+		assigned:  map[string]bool{},
+	}
+}
+
+// analyseFunction analyses the body of f, as if it was at the top level.
+func analyseFunction(f ast.Function) *inlineAnalyser {
+	a := newInlineAnalyser()
+	for _, p := range f.Params {
+		a.declare(p.Val)
+	}
+	a.hasEtc = f.HasDots
+	a.stats(f.Body.Stats)
+	a.exps(f.Body.Return)
+	if len(f.Body.Return) == 1 {
+		// That would be a tail call, which cannot be inlined as the caller
+		// disappears from the call stack.
+		_, a.hasTailCall = f.Body.Return[0].(ast.FunctionCall)
+	}
+	return a
+}
+
+func (a *inlineAnalyser) inlinable() bool {
+	return a.size <= maxInlineSize &&
+		!a.hasFunction && !a.hasEtc && !a.hasGoto && !a.hasClose &&
+		!a.hasBadBreak && !a.hasBadReturn && !a.hasTailCall
+}
+
+func (a *inlineAnalyser) isFree(name string) bool {
+	return a.isFreeSet[name]
+}
+
+func (a *inlineAnalyser) pushScope() {
+	a.scopes = append(a.scopes, map[string]bool{})
+}
+
+func (a *inlineAnalyser) popScope() {
+	a.scopes = a.scopes[:len(a.scopes)-1]
+}
+
+func (a *inlineAnalyser) declare(name string) {
+	a.scopes[len(a.scopes)-1][name] = true
+}
+
+func (a *inlineAnalyser) use(name string) {
+	for i := len(a.scopes) - 1; i >= 0; i-- {
+		if a.scopes[i][name] {
+			return
+		}
+	}
+	if !a.isFreeSet[name] {
+		a.isFreeSet[name] = true
+		a.free = append(a.free, name)
+	}
+}
+
+func (a *inlineAnalyser) stats(stats []ast.Stat) {
+	for _, s := range stats {
+		a.size++
+		s.ProcessStat(a)
+	}
+}
+
+func (a *inlineAnalyser) exps(exps []ast.ExpNode) {
+	for _, e := range exps {
+		a.exp(e)
+	}
+}
+
+func (a *inlineAnalyser) exp(e ast.ExpNode) {
+	a.size++
+	e.ProcessExp(a)
+}
+
+// block walks a nested block, in which return statements are not allowed.
+func (a *inlineAnalyser) block(b ast.BlockStat) {
+	a.pushScope()
+	a.stats(b.Stats)
+	a.blockReturn(b)
+	a.popScope()
+}
+
+func (a *inlineAnalyser) blockReturn(b ast.BlockStat) {
+	if b.Return != nil {
+		a.hasBadReturn = a.hasBadReturn || a.funcDepth == 0
+		a.exps(b.Return)
+	}
+}
+
+func (a *inlineAnalyser) loopBody(b ast.BlockStat) {
+	a.loopDepth++
+	a.block(b)
+	a.loopDepth--
+}
+
+// ProcessAssignStat analyses an AssignStat.
+func (a *inlineAnalyser) ProcessAssignStat(s ast.AssignStat) {
+	a.exps(s.Src)
+	for _, v := range s.Dest {
+		a.size++
+		v.ProcessVar(a)
+	}
+}
+
+// ProcessBlockStat analyses a BlockStat.
+func (a *inlineAnalyser) ProcessBlockStat(s ast.BlockStat) {
+	a.block(s)
+}
+
+// ProcessBreakStat analyses a BreakStat.
+func (a *inlineAnalyser) ProcessBreakStat(s ast.BreakStat) {
+	a.hasBadBreak = a.hasBadBreak || a.loopDepth == 0
+}
+
+// ProcessCompoundAssignStat analyses a CompoundAssignStat.
+func (a *inlineAnalyser) ProcessCompoundAssignStat(s ast.CompoundAssignStat) {
+	a.exp(s.Value)
+	a.size++
+	s.Dest.ProcessVar(a)
+}
+
+// ProcessContinueStat analyses a ContinueStat.
+func (a *inlineAnalyser) ProcessContinueStat(s ast.ContinueStat) {
+	a.hasBadBreak = a.hasBadBreak || a.loopDepth == 0
+}
+
+// ProcessEmptyStat analyses an EmptyStat.
+func (a *inlineAnalyser) ProcessEmptyStat(s ast.EmptyStat) {}
+
+// ProcessForInStat analyses a ForInStat.
+func (a *inlineAnalyser) ProcessForInStat(s ast.ForInStat) {
+	a.exps(s.Params)
+	a.pushScope()
+	for _, v := range s.Vars {
+		a.declare(v.Val)
+	}
+	a.loopBody(s.Body)
+	a.popScope()
+}
+
+// ProcessForStat analyses a ForStat.
+func (a *inlineAnalyser) ProcessForStat(s ast.ForStat) {
+	a.exp(s.Start)
+	a.exp(s.Stop)
+	a.exp(s.Step)
+	a.pushScope()
+	a.declare(s.Var.Val)
+	a.loopBody(s.Body)
+	a.popScope()
+}
+
+// ProcessFunctionCallStat analyses a FunctionCall statement.
+func (a *inlineAnalyser) ProcessFunctionCallStat(f ast.FunctionCall) {
+	a.ProcessBFunctionCallExp(*f.BFunctionCall)
+}
+
+// ProcessGotoStat analyses a GotoStat.
+func (a *inlineAnalyser) ProcessGotoStat(s ast.GotoStat) {
+	a.hasGoto = true
+}
+
+// ProcessIfStat analyses an IfStat.
+func (a *inlineAnalyser) ProcessIfStat(s ast.IfStat) {
+	a.exp(s.If.Cond)
+	a.block(s.If.Body)
+	for _, s := range s.ElseIfs {
+		a.exp(s.Cond)
+		a.block(s.Body)
+	}
+	if s.Else != nil {
+		a.block(*s.Else)
+	}
+}
+
+// ProcessLabelStat analyses a LabelStat.
+func (a *inlineAnalyser) ProcessLabelStat(s ast.LabelStat) {
+	a.hasGoto = true
+}
+
+// ProcessLocalFunctionStat analyses a LocalFunctionStat.
+func (a *inlineAnalyser) ProcessLocalFunctionStat(s ast.LocalFunctionStat) {
+	a.declare(s.Name.Val)
+	a.ProcessFunctionExp(s.Function)
+}
+
+// ProcessLocalStat analyses a LocalStat.
+func (a *inlineAnalyser) ProcessLocalStat(s ast.LocalStat) {
+	a.exps(s.Values)
+	for _, na := range s.NameAttribs {
+		a.declare(na.Name.Val)
+		a.hasClose = a.hasClose || na.Attrib == ast.CloseAttrib
+	}
+}
+
+// ProcessRepeatStat analyses a RepeatStat.
+func (a *inlineAnalyser) ProcessRepeatStat(s ast.RepeatStat) {
+	// The condition can see the locals in the body.
+	a.pushScope()
+	a.loopDepth++
+	a.stats(s.Body.Stats)
+	a.blockReturn(s.Body)
+	a.loopDepth--
+	a.exp(s.Cond)
+	a.popScope()
+}
+
+// ProcessWhileStat analyses a WhileStat.
+func (a *inlineAnalyser) ProcessWhileStat(s ast.WhileStat) {
+	a.exp(s.Cond)
+	a.loopBody(s.Body)
+}
+
+// ProcessBFunctionCallExp analyses a BFunctionCall.
+func (a *inlineAnalyser) ProcessBFunctionCallExp(f ast.BFunctionCall) {
+	a.exp(f.Target)
+	a.exps(f.Args)
+}
+
+// ProcessBinOpExp analyses a BinOp.
+func (a *inlineAnalyser) ProcessBinOpExp(b ast.BinOp) {
+	a.exp(b.Left)
+	for _, r := range b.Right {
+		a.exp(r.Operand)
+	}
+}
+
+// ProcesBoolExp analyses a Bool.
+func (a *inlineAnalyser) ProcesBoolExp(b ast.Bool) {}
+
+// ProcessEtcExp analyses an Etc.
+func (a *inlineAnalyser) ProcessEtcExp(e ast.Etc) {
+	a.hasEtc = a.hasEtc || a.funcDepth == 0
+}
+
+// ProcessFunctionExp analyses a Function.
+func (a *inlineAnalyser) ProcessFunctionExp(f ast.Function) {
+	a.hasFunction = true
+	loopDepth := a.loopDepth
+	a.loopDepth = 0
+	a.funcDepth++
+	a.pushScope()
+	for _, p := range f.Params {
+		a.declare(p.Val)
+	}
+	a.block(f.Body)
+	a.popScope()
+	a.funcDepth--
+	a.loopDepth = loopDepth
+}
+
+// ProcessFunctionCallExp analyses a FunctionCall.
+func (a *inlineAnalyser) ProcessFunctionCallExp(f ast.FunctionCall) {
+	a.ProcessBFunctionCallExp(*f.BFunctionCall)
+}
+
+// ProcessIndexExp analyses an IndexExp.
+func (a *inlineAnalyser) ProcessIndexExp(e ast.IndexExp) {
+	a.exp(e.Coll)
+	a.exp(e.Idx)
+}
+
+// ProcessInterpolatedStringExp analyses an InterpolatedString.
+func (a *inlineAnalyser) ProcessInterpolatedStringExp(s ast.InterpolatedString) {
+	// Expressions are converted with the global tostring function.
+	a.use("_ENV")
+	a.exps(s.Exps)
+}
+
+// ProcessNameExp analyses a Name.
+func (a *inlineAnalyser) ProcessNameExp(n ast.Name) {
+	a.use(n.Val)
+}
+
+// ProcessNilExp analyses a Nil.
+func (a *inlineAnalyser) ProcessNilExp(n ast.Nil) {}
+
+// ProcessIntExp analyses an Int.
+func (a *inlineAnalyser) ProcessIntExp(n ast.Int) {}
+
+// ProcessFloatExp analyses a Float.
+func (a *inlineAnalyser) ProcessFloatExp(f ast.Float) {}
+
+// ProcessStringExp analyses a String.
+func (a *inlineAnalyser) ProcessStringExp(s ast.String) {}
+
+// ProcessTableConstructorExp analyses a TableConstructor.
+func (a *inlineAnalyser) ProcessTableConstructorExp(t ast.TableConstructor) {
+	for _, f := range t.Fields {
+		if _, noKey := f.Key.(ast.NoTableKey); !noKey {
+			a.exp(f.Key)
+		}
+		a.exp(f.Value)
+	}
+}
+
+// ProcessUnOpExp analyses an UnOp.
+func (a *inlineAnalyser) ProcessUnOpExp(u ast.UnOp) {
+	a.exp(u.Operand)
+}
+
+// ProcessIndexExpVar analyses an IndexExp l-value.
+func (a *inlineAnalyser) ProcessIndexExpVar(e ast.IndexExp) {
+	a.ProcessIndexExp(e)
+}
+
+// ProcessNameVar analyses a Name l-value.
+func (a *inlineAnalyser) ProcessNameVar(n ast.Name) {
+	a.assigned[n.Val] = true
+	a.use(n.Val)
+}
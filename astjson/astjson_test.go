@@ -0,0 +1,260 @@
+package astjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/astcomp"
+	"github.com/arnodel/golua/luafmt"
+	"github.com/arnodel/golua/parsing"
+	"github.com/arnodel/golua/scanner"
+	"github.com/arnodel/golua/token"
+)
+
+func parse(t *testing.T, name string, src []byte) ast.BlockStat {
+	chunk, err := parsing.ParseChunk(scanner.New(name, src, scanner.WithComments()))
+	if err != nil {
+		t.Skip("does not parse")
+	}
+	return chunk
+}
+
+// Check that the Lua test files of the repository survive encoding and
+// decoding, and that printing a decoded AST without locations produces source
+// code with the same AST.
+func TestRoundTrip(t *testing.T) {
+	var files []string
+	for _, pattern := range []string{"../runtime/lua/*.lua", "../lib/*/lua/*.lua", "../benchmarks/lua/*.lua"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			src, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			chunk := parse(t, file, src)
+			var buf bytes.Buffer
+			if err := Encode(&buf, chunk); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(chunk, decoded) {
+				t.Fatal("decoded AST is different")
+			}
+
+			// Now forget the locations, as a tool generating an AST would.
+			stripped := stripLocations(t, chunk)
+			bare, err := Unmarshal(stripped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf.Reset()
+			if err := luafmt.Fprint(&buf, bare); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.Bytes()
+			reparsed, err := parsing.ParseChunk(scanner.New(file, out))
+			if err != nil {
+				t.Fatalf("error parsing printed source: %s\n%s", err, out)
+			}
+			if stripped2 := stripLocations(t, reparsed); !bytes.Equal(stripped, stripped2) {
+				t.Errorf("printed source has a different AST:\n%s", out)
+			}
+		})
+	}
+}
+
+// stripLocations returns the JSON encoding of chunk without "loc" and
+// "comments" fields or empty statements.
+func stripLocations(t *testing.T, chunk ast.BlockStat) []byte {
+	data, err := Marshal(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	var strip func(v interface{})
+	strip = func(v interface{}) {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			delete(x, "loc")
+			delete(x, "comments")
+			if stats, ok := x["stats"].([]interface{}); ok {
+				// The printer drops empty statements
+				var nonEmpty []interface{}
+				for _, s := range stats {
+					if s.(map[string]interface{})["type"] != "Empty" {
+						nonEmpty = append(nonEmpty, s)
+					}
+				}
+				x["stats"] = nonEmpty
+			}
+			for _, y := range x {
+				strip(y)
+			}
+		case []interface{}:
+			for _, y := range x {
+				strip(y)
+			}
+		}
+	}
+	strip(v)
+	data, err = json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestEncode(t *testing.T) {
+	chunk := parse(t, "test", []byte("local x <const> = -1.5\nprint(x, \"\\xff\")"))
+	data, err := Marshal(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{"type":"Block","stats":[` +
+		`{"type":"Local","loc":{"start":{"line":1,"column":7,"offset":6},"end":{"line":1,"column":20,"offset":19}},` +
+		`"values":[{"type":"UnOp","loc":{"start":{"line":1,"column":19,"offset":18},"end":{"line":1,"column":20,"offset":19}},"op":"-",` +
+		`"operand":{"type":"Float","loc":{"start":{"line":1,"column":20,"offset":19},"end":{"line":1,"column":20,"offset":19}},"value":1.5}}],` +
+		`"names":[{"type":"NameAttrib","loc":{"start":{"line":1,"column":7,"offset":6},"end":{"line":1,"column":10,"offset":9}},"attrib":"const",` +
+		`"var":{"type":"Name","loc":{"start":{"line":1,"column":7,"offset":6},"end":{"line":1,"column":7,"offset":6}},"name":"x"}}]},` +
+		`{"type":"Call","loc":{"start":{"line":2,"column":1,"offset":23},"end":{"line":2,"column":10,"offset":32}},` +
+		`"target":{"type":"Name","loc":{"start":{"line":2,"column":1,"offset":23},"end":{"line":2,"column":1,"offset":23}},"name":"print"},` +
+		`"args":[{"type":"Name","loc":{"start":{"line":2,"column":7,"offset":29},"end":{"line":2,"column":7,"offset":29}},"name":"x"},` +
+		`{"type":"String","loc":{"start":{"line":2,"column":10,"offset":32},"end":{"line":2,"column":10,"offset":32}},"base64":"/w=="}]}]}`
+	if string(data) != expected {
+		t.Errorf("got\n%s", data)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// A chunk generated by a tool, without locations.
+	const src = `{"type": "Block", "stats": [
+		{"type": "For", "var": {"type": "Name", "name": "i"},
+		 "start": {"type": "Int", "value": 1},
+		 "stop": {"type": "Float", "value": "inf"},
+		 "step": {"type": "Int", "value": 2},
+		 "body": {"type": "Block", "stats": [
+			{"type": "Call", "target": {"type": "Name", "name": "f"},
+			 "args": [{"type": "BinOp", "left": {"type": "Name", "name": "i"},
+			           "ops": [{"op": "+", "operand": {"type": "Int", "value": 1}},
+			                   {"op": "-", "operand": {"type": "Int", "value": 2}}]},
+			          {"type": "Table", "fields": [
+			            {"type": "Field", "value": {"type": "Bool", "value": true}},
+			            {"type": "Field", "key": {"type": "String", "value": "k"}, "value": {"type": "Etc"}}]}]}]}}],
+		"return": []}`
+	chunk, err := Unmarshal([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	forStat, ok := chunk.Stats[0].(*ast.ForStat)
+	if !ok {
+		t.Fatalf("expected *ast.ForStat, got %T", chunk.Stats[0])
+	}
+	if stop, ok := forStat.Stop.(ast.Float); !ok || !math.IsInf(stop.Val, 1) {
+		t.Errorf("wrong stop: %v", forStat.Stop)
+	}
+	var buf bytes.Buffer
+	if err := luafmt.Fprint(&buf, chunk); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `for i = 1, 1e9999, 2 do
+    f(i + 1 - 2, {true, k = ...})
+end
+return
+`
+	if buf.String() != expected {
+		t.Errorf("got\n%s", buf.String())
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		src, err string
+	}{
+		{`[]`, "cannot unmarshal array"},
+		{`{"type": "Name", "name": "x"}`, "Name node: expected a Block node"},
+		{`{"type": "Block", "stats": [{"type": "Nil"}]}`, "Nil node: not a statement"},
+		{`{"type": "Block", "stats": [{"type": "Call"}]}`, `Call node: missing "target" field`},
+		{`{"type": "Block", "return": [{"type": "UnOp", "op": "!", "operand": {"type": "Nil"}}]}`, `UnOp node: invalid operator "!"`},
+		{`{"type": "Block", "stats": [{"type": "Assign", "targets": [{"type": "Nil"}]}]}`, "Nil node: not assignable"},
+		{`{"type": "Block", "return": [{"type": "Int", "value": "one"}]}`, "Int node: invalid value"},
+		{`{"type": "Block", "return": [{"type": "BinOp", "left": {"type": "Nil"}, "ops": [{"op": "+", "operand": {"type": "Nil"}}, {"op": "*", "operand": {"type": "Nil"}}]}]}`, "different precedence"},
+		{`{"type": "Block", "return": [{"type": "Interp", "texts": [{"type": "String", "value": "x"}], "exps": [{"type": "Nil"}]}]}`, "one more text than exps"},
+	}
+	for _, test := range tests {
+		_, err := Unmarshal([]byte(test.src))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected error %q, got %v", test.src, test.err, err)
+		}
+	}
+}
+
+// Decoded ASTs have no locations, so compilation errors are reported without a
+// position.
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src, err string
+	}{
+		{`{"type": "Block", "stats": [{"type": "Break"}]}`, "no visible label '<break>'"},
+		{`{"type": "Block", "stats": [{"type": "Continue"}]}`, "'continue' outside a loop"},
+		{`{"type": "Block", "stats": [{"type": "Goto", "var": {"type": "Name", "name": "x"}}]}`, "no visible label 'x'"},
+	}
+	for _, test := range tests {
+		chunk, err := Unmarshal([]byte(test.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = astcomp.CompileLuaChunk("test", chunk)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: expected error %q, got %v", test.src, test.err, err)
+		}
+	}
+}
+
+func TestRoundTripExtensions(t *testing.T) {
+	const src = "t.x += 1\nfor i = 1, 2 do\n    if i == 1 then continue end\nend\nprint(`a{t.x}\\{b\\}`, ``)\n"
+	ext := token.AllExtensions
+	chunk, err := parsing.ParseChunk(scanner.New("test", []byte(src), scanner.WithExtensions(ext)), parsing.WithExtensions(ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Marshal(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(chunk, decoded) {
+		t.Fatal("decoded AST is different")
+	}
+	var buf bytes.Buffer
+	if err := luafmt.Fprint(&buf, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != src {
+		t.Errorf("got\n%s", buf.String())
+	}
+}
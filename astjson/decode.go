@@ -0,0 +1,468 @@
+package astjson
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ops"
+	"github.com/arnodel/golua/token"
+)
+
+// Decode reads the JSON representation of a chunk from r and returns its AST.
+// The JSON must be a "Block" node as produced by Encode, although "loc" fields
+// may be omitted (e.g. if the AST was generated by a tool).
+func Decode(r io.Reader) (ast.BlockStat, error) {
+	var n node
+	if err := json.NewDecoder(r).Decode(&n); err != nil {
+		return ast.BlockStat{}, err
+	}
+	return decodeChunk(&n)
+}
+
+// Unmarshal returns the AST of the chunk whose JSON representation is data.
+func Unmarshal(data []byte) (ast.BlockStat, error) {
+	var n node
+	if err := json.Unmarshal(data, &n); err != nil {
+		return ast.BlockStat{}, err
+	}
+	return decodeChunk(&n)
+}
+
+// A DecodeError is returned when the JSON is valid but does not describe a
+// valid AST.
+type DecodeError struct {
+	Type    string // Type of the node being decoded
+	Message string
+}
+
+func (e *DecodeError) Error() string {
+	if e.Type == "" {
+		return "astjson: " + e.Message
+	}
+	return fmt.Sprintf("astjson: %s node: %s", e.Type, e.Message)
+}
+
+func decodeChunk(n *node) (b ast.BlockStat, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			derr, ok := r.(*DecodeError)
+			if !ok {
+				panic(r)
+			}
+			err = derr
+		}
+	}()
+	b = decodeBlock(n)
+	return
+}
+
+func fail(n *node, format string, args ...interface{}) {
+	panic(&DecodeError{Type: n.Type, Message: fmt.Sprintf(format, args...)})
+}
+
+func require(n *node, field string, child *node) *node {
+	if child == nil {
+		fail(n, "missing %q field", field)
+	}
+	return child
+}
+
+func decodeLoc(n *node) ast.Location {
+	if n.Loc == nil {
+		return ast.Location{}
+	}
+	return ast.LocFromTokens(decodePos(n.Loc.Start), decodePos(n.Loc.End))
+}
+
+func decodePos(p *pos) *token.Token {
+	if p == nil {
+		return nil
+	}
+	return &token.Token{Pos: token.Pos{Line: p.Line, Column: p.Column, Offset: p.Offset}}
+}
+
+func decodeBlock(n *node) ast.BlockStat {
+	if n.Type != "Block" {
+		fail(n, "expected a Block node")
+	}
+	b := ast.BlockStat{Location: decodeLoc(n)}
+	for _, s := range n.Stats {
+		b.Stats = append(b.Stats, decodeStat(require(n, "stats", s)))
+	}
+	if n.Return != nil {
+		b.Return = decodeExps(n, "return", *n.Return)
+		if b.Return == nil {
+			b.Return = []ast.ExpNode{}
+		}
+	}
+	for _, c := range n.Comments {
+		b.Comments = append(b.Comments, token.Comment{
+			Lit: []byte(c.Text),
+			Pos: decodePos(&c.Pos).Pos,
+		})
+	}
+	return b
+}
+
+func decodeStat(n *node) ast.Stat {
+	loc := decodeLoc(n)
+	switch n.Type {
+	case "Assign":
+		if len(n.Targets) == 0 {
+			fail(n, "no targets")
+		}
+		s := ast.AssignStat{Location: loc, Src: decodeExps(n, "values", n.Values)}
+		for _, t := range n.Targets {
+			s.Dest = append(s.Dest, decodeVar(require(n, "targets", t)))
+		}
+		return s
+	case "Block":
+		return decodeBlock(n)
+	case "Break":
+		return ast.BreakStat{Location: loc}
+	case "CompoundAssign":
+		op, ok := binOps[n.Op]
+		if !ok {
+			fail(n, "invalid operator %q", n.Op)
+		}
+		return ast.CompoundAssignStat{
+			Location: loc,
+			Dest:     decodeVar(require(n, "var", n.Var)),
+			Op:       op,
+			Value:    decodeExp(require(n, "operand", n.Operand)),
+		}
+	case "Continue":
+		return ast.ContinueStat{Location: loc}
+	case "Empty":
+		return ast.EmptyStat{Location: loc}
+	case "ForIn":
+		if len(n.Names) == 0 || len(n.Exps) == 0 {
+			fail(n, "no names or no exps")
+		}
+		return &ast.ForInStat{
+			Location: loc,
+			Vars:     decodeNames(n, "names", n.Names),
+			Params:   decodeExps(n, "exps", n.Exps),
+			Body:     decodeBlock(require(n, "body", n.Body)),
+		}
+	case "For":
+		s := &ast.ForStat{
+			Location: loc,
+			Var:      decodeName(require(n, "var", n.Var)),
+			Start:    decodeExp(require(n, "start", n.Start)),
+			Stop:     decodeExp(require(n, "stop", n.Stop)),
+			Body:     decodeBlock(require(n, "body", n.Body)),
+		}
+		if n.Step != nil {
+			s.Step = decodeExp(n.Step)
+		} else {
+			s.Step = ast.NewInt(1)
+		}
+		return s
+	case "Call":
+		return decodeCall(n)
+	case "Goto":
+		return ast.GotoStat{Location: loc, Label: decodeName(require(n, "var", n.Var))}
+	case "If":
+		if len(n.Clauses) == 0 {
+			fail(n, "no clauses")
+		}
+		var conds []ast.CondStat
+		for _, c := range n.Clauses {
+			conds = append(conds, ast.CondStat{
+				Cond: decodeExp(require(n, "cond", c.Cond)),
+				Body: decodeBlock(require(n, "body", c.Body)),
+			})
+		}
+		s := ast.IfStat{Location: loc, If: conds[0], ElseIfs: conds[1:]}
+		if len(s.ElseIfs) == 0 {
+			s.ElseIfs = nil
+		}
+		if n.Else != nil {
+			body := decodeBlock(n.Else)
+			s.Else = &body
+		}
+		return s
+	case "Label":
+		return ast.LabelStat{Location: loc, Name: decodeName(require(n, "var", n.Var))}
+	case "LocalFunction":
+		f, ok := decodeExp(require(n, "function", n.Function)).(ast.Function)
+		if !ok {
+			fail(n, "function is not a Function node")
+		}
+		return ast.LocalFunctionStat{
+			Location: loc,
+			Name:     decodeName(require(n, "var", n.Var)),
+			Function: f,
+		}
+	case "Local":
+		if len(n.Names) == 0 {
+			fail(n, "no names")
+		}
+		s := ast.LocalStat{Location: loc, Values: decodeExps(n, "values", n.Values)}
+		for _, na := range n.Names {
+			s.NameAttribs = append(s.NameAttribs, decodeNameAttrib(require(n, "names", na)))
+		}
+		return s
+	case "Repeat":
+		return ast.RepeatStat{
+			Location: loc,
+			CondStat: ast.CondStat{
+				Body: decodeBlock(require(n, "body", n.Body)),
+				Cond: decodeExp(require(n, "cond", n.Cond)),
+			},
+		}
+	case "While":
+		return ast.WhileStat{
+			Location: loc,
+			CondStat: ast.CondStat{
+				Cond: decodeExp(require(n, "cond", n.Cond)),
+				Body: decodeBlock(require(n, "body", n.Body)),
+			},
+		}
+	default:
+		fail(n, "not a statement")
+	}
+	return nil
+}
+
+func decodeExps(n *node, field string, ns []*node) []ast.ExpNode {
+	var xs []ast.ExpNode
+	for _, x := range ns {
+		xs = append(xs, decodeExp(require(n, field, x)))
+	}
+	return xs
+}
+
+func decodeExp(n *node) ast.ExpNode {
+	loc := decodeLoc(n)
+	switch n.Type {
+	case "BCall":
+		return decodeCall(n).InBrackets()
+	case "BinOp":
+		if len(n.Ops) == 0 {
+			fail(n, "no ops")
+		}
+		b := &ast.BinOp{Location: loc, Left: decodeExp(require(n, "left", n.Left))}
+		for i, o := range n.Ops {
+			op, ok := binOps[o.Op]
+			if !ok {
+				fail(n, "invalid operator %q", o.Op)
+			}
+			if i == 0 {
+				b.OpType = op.Type()
+			} else if op.Type() != b.OpType {
+				fail(n, "operator %q has a different precedence", o.Op)
+			}
+			b.Right = append(b.Right, ast.Operation{
+				Op:      op,
+				Operand: decodeExp(require(n, "operand", o.Operand)),
+			})
+		}
+		return b
+	case "Bool":
+		var v bool
+		decodeValue(n, &v)
+		return ast.Bool{Location: loc, Val: v}
+	case "Etc":
+		return ast.Etc{Location: loc}
+	case "Function":
+		f := ast.Function{
+			Location: loc,
+			ParList:  ast.ParList{Params: decodeNames(n, "params", n.Params), HasDots: n.Vararg},
+			Body:     decodeBlock(require(n, "body", n.Body)),
+			Name:     n.Name,
+		}
+		if f.Body.Return == nil {
+			f.Body.Return = []ast.ExpNode{}
+		}
+		return f
+	case "Call":
+		return decodeCall(n)
+	case "Index":
+		return decodeVar(n)
+	case "Interp":
+		if len(n.Texts) != len(n.Exps)+1 {
+			fail(n, "there should be one more text than exps")
+		}
+		s := ast.InterpolatedString{Location: loc, Exps: decodeExps(n, "exps", n.Exps)}
+		for _, t := range n.Texts {
+			text, ok := decodeExp(require(n, "texts", t)).(ast.String)
+			if !ok {
+				fail(n, "texts should be String nodes")
+			}
+			s.Texts = append(s.Texts, text.Val)
+		}
+		return s
+	case "Name":
+		return decodeName(n)
+	case "Nil":
+		return ast.Nil{Location: loc}
+	case "Int":
+		var v int64
+		decodeValue(n, &v)
+		return ast.Int{Location: loc, Val: uint64(v)}
+	case "Float":
+		var v interface{}
+		decodeValue(n, &v)
+		f := ast.Float{Location: loc}
+		switch x := v.(type) {
+		case float64:
+			f.Val = x
+		case string:
+			switch x {
+			case "inf":
+				f.Val = math.Inf(1)
+			case "-inf":
+				f.Val = math.Inf(-1)
+			case "nan":
+				f.Val = math.NaN()
+			default:
+				fail(n, "invalid value %q", x)
+			}
+		default:
+			fail(n, "value should be a number")
+		}
+		return f
+	case "String":
+		s := ast.String{Location: loc}
+		if n.Base64 != "" {
+			val, err := base64.StdEncoding.DecodeString(n.Base64)
+			if err != nil {
+				fail(n, "invalid base64: %s", err)
+			}
+			s.Val = val
+		} else {
+			var v string
+			decodeValue(n, &v)
+			if v != "" {
+				s.Val = []byte(v)
+			}
+		}
+		return s
+	case "Table":
+		t := ast.TableConstructor{Location: loc}
+		for _, f := range n.Fields {
+			t.Fields = append(t.Fields, decodeField(require(n, "fields", f)))
+		}
+		return t
+	case "UnOp":
+		op, ok := unOps[n.Op]
+		if !ok {
+			fail(n, "invalid operator %q", n.Op)
+		}
+		return &ast.UnOp{
+			Location: loc,
+			Op:       op,
+			Operand:  decodeExp(require(n, "operand", n.Operand)),
+		}
+	default:
+		fail(n, "not an expression")
+	}
+	return nil
+}
+
+func decodeValue(n *node, v interface{}) {
+	if n.Value == nil {
+		fail(n, "missing \"value\" field")
+	}
+	if err := json.Unmarshal(n.Value, v); err != nil {
+		fail(n, "invalid value: %s", err)
+	}
+}
+
+func decodeVar(n *node) ast.Var {
+	switch n.Type {
+	case "Name":
+		return decodeName(n)
+	case "Index":
+		return ast.IndexExp{
+			Location: decodeLoc(n),
+			Coll:     decodeExp(require(n, "object", n.Object)),
+			Idx:      decodeExp(require(n, "key", n.Key)),
+		}
+	default:
+		fail(n, "not assignable")
+	}
+	return nil
+}
+
+func decodeName(n *node) ast.Name {
+	if n.Type != "Name" {
+		fail(n, "expected a Name node")
+	}
+	if n.Name == "" {
+		fail(n, "empty name")
+	}
+	return ast.Name{Location: decodeLoc(n), Val: n.Name}
+}
+
+func decodeNames(n *node, field string, ns []*node) []ast.Name {
+	var names []ast.Name
+	for _, x := range ns {
+		names = append(names, decodeName(require(n, field, x)))
+	}
+	return names
+}
+
+func decodeNameAttrib(n *node) ast.NameAttrib {
+	if n.Type != "NameAttrib" {
+		fail(n, "expected a NameAttrib node")
+	}
+	na := ast.NameAttrib{Location: decodeLoc(n), Name: decodeName(require(n, "var", n.Var))}
+	switch n.Attrib {
+	case "":
+		na.Attrib = ast.NoAttrib
+	case "const":
+		na.Attrib = ast.ConstAttrib
+	case "close":
+		na.Attrib = ast.CloseAttrib
+	default:
+		fail(n, "invalid attrib %q", n.Attrib)
+	}
+	return na
+}
+
+func decodeCall(n *node) ast.FunctionCall {
+	f := &ast.BFunctionCall{
+		Location: decodeLoc(n),
+		Target:   decodeExp(require(n, "target", n.Target)),
+		Args:     decodeExps(n, "args", n.Args),
+	}
+	if n.Method != nil {
+		f.Method = decodeName(n.Method)
+	}
+	if f.Args == nil {
+		f.Args = []ast.ExpNode{}
+	}
+	return ast.FunctionCall{BFunctionCall: f}
+}
+
+func decodeField(n *node) ast.TableField {
+	if n.Type != "Field" {
+		fail(n, "expected a Field node")
+	}
+	var val node
+	decodeValue(n, &val)
+	f := ast.TableField{Location: decodeLoc(n), Key: ast.NoTableKey{}, Value: decodeExp(&val)}
+	if n.Key != nil {
+		f.Key = decodeExp(n.Key)
+	}
+	return f
+}
+
+var binOps = map[string]ops.Op{}
+var unOps = map[string]ops.Op{}
+
+func init() {
+	for op, name := range binOpNames {
+		binOps[name] = op
+	}
+	for op, name := range unOpNames {
+		unOps[name] = op
+	}
+}
@@ -0,0 +1,534 @@
+// Package astjson converts Lua ASTs (see package ast) to and from JSON, so that
+// external tools can analyse Lua code or generate it and then compile it with
+// package astcomp.
+//
+// Every node is encoded as a JSON object with a "type" field naming its kind
+// (e.g. "Local", "BinOp"), an optional "loc" field giving the start and end
+// positions of the node in the source and fields specific to its kind.  The
+// AST of a chunk is encoded as a "Block" node.  Fields whose value is empty are
+// omitted.
+package astjson
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math"
+	"unicode/utf8"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ops"
+	"github.com/arnodel/golua/token"
+)
+
+// A node is the JSON representation of an AST node.  It has a field for all
+// the possible children of a node, only the ones relevant to Type are set.
+type node struct {
+	Type string `json:"type"`
+	Loc  *loc   `json:"loc,omitempty"`
+
+	// Name, Function
+	Name string `json:"name,omitempty"`
+	// NameAttrib
+	Attrib string `json:"attrib,omitempty"`
+	// Bool, Int, Float, String (a literal) or Field (a node)
+	Value json.RawMessage `json:"value,omitempty"`
+	// String which is not valid UTF-8
+	Base64 string `json:"base64,omitempty"`
+	// UnOp, CompoundAssign
+	Op      string `json:"op,omitempty"`
+	Operand *node  `json:"operand,omitempty"`
+	// BinOp
+	Left *node       `json:"left,omitempty"`
+	Ops  []operation `json:"ops,omitempty"`
+	// Call, BCall (a call in brackets, truncated to one value)
+	Target *node   `json:"target,omitempty"`
+	Method *node   `json:"method,omitempty"`
+	Args   []*node `json:"args,omitempty"`
+	// Index
+	Object *node `json:"object,omitempty"`
+	// Index, Field
+	Key *node `json:"key,omitempty"`
+	// Function
+	Params []*node `json:"params,omitempty"`
+	Vararg bool    `json:"vararg,omitempty"`
+	// Assign
+	Targets []*node `json:"targets,omitempty"`
+	// Assign, Local
+	Values []*node `json:"values,omitempty"`
+	// Local (NameAttrib nodes), ForIn (Name nodes)
+	Names []*node `json:"names,omitempty"`
+	// ForIn, Interp
+	Exps []*node `json:"exps,omitempty"`
+	// Interp (String nodes)
+	Texts []*node `json:"texts,omitempty"`
+	// For, Goto, Label, LocalFunction, NameAttrib, CompoundAssign
+	Var *node `json:"var,omitempty"`
+	// For
+	Start *node `json:"start,omitempty"`
+	Stop  *node `json:"stop,omitempty"`
+	Step  *node `json:"step,omitempty"`
+	// If
+	Clauses []clause `json:"clauses,omitempty"`
+	Else    *node    `json:"else,omitempty"`
+	// While, Repeat
+	Cond *node `json:"cond,omitempty"`
+	// Block
+	Stats    []*node    `json:"stats,omitempty"`
+	Return   *[]*node   `json:"return,omitempty"` // Omitted if there is no return statement
+	Comments []*comment `json:"comments,omitempty"`
+	// LocalFunction
+	Function *node `json:"function,omitempty"`
+	// Table
+	Fields []*node `json:"fields,omitempty"`
+	// For, ForIn, Function, While, Repeat
+	Body *node `json:"body,omitempty"`
+}
+
+type operation struct {
+	Op      string `json:"op"`
+	Operand *node  `json:"operand"`
+}
+
+type clause struct {
+	Cond *node `json:"cond"`
+	Body *node `json:"body"`
+}
+
+type comment struct {
+	Text string `json:"text"`
+	Pos  pos    `json:"pos"`
+}
+
+type loc struct {
+	Start *pos `json:"start,omitempty"`
+	End   *pos `json:"end,omitempty"`
+}
+
+type pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// Encode writes the JSON representation of a chunk to w, indented for
+// readability.
+func Encode(w io.Writer, chunk ast.BlockStat) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(encodeBlock(chunk))
+}
+
+// Marshal returns the JSON representation of a chunk.
+func Marshal(chunk ast.BlockStat) ([]byte, error) {
+	return json.Marshal(encodeBlock(chunk))
+}
+
+func encodeLoc(l ast.Locator) *loc {
+	location := l.Locate()
+	start, end := location.StartPos(), location.EndPos()
+	if start == nil && end == nil {
+		return nil
+	}
+	return &loc{Start: encodePos(start), End: encodePos(end)}
+}
+
+func encodePos(p *token.Pos) *pos {
+	if p == nil {
+		return nil
+	}
+	return &pos{Line: p.Line, Column: p.Column, Offset: p.Offset}
+}
+
+func encodeBlock(b ast.BlockStat) *node {
+	n := &node{Type: "Block", Loc: encodeLoc(b)}
+	for _, s := range b.Stats {
+		n.Stats = append(n.Stats, encodeStat(s))
+	}
+	if b.Return != nil {
+		ret := encodeExps(b.Return)
+		if ret == nil {
+			ret = []*node{}
+		}
+		n.Return = &ret
+	}
+	for _, c := range b.Comments {
+		n.Comments = append(n.Comments, &comment{
+			Text: string(c.Lit),
+			Pos:  *encodePos(&c.Pos),
+		})
+	}
+	return n
+}
+
+func encodeStat(s ast.Stat) *node {
+	e := new(encoder)
+	s.ProcessStat(e)
+	return e.n
+}
+
+func encodeExp(x ast.ExpNode) *node {
+	if x == nil {
+		return nil
+	}
+	e := new(encoder)
+	x.ProcessExp(e)
+	return e.n
+}
+
+func encodeExps(xs []ast.ExpNode) []*node {
+	var ns []*node
+	for _, x := range xs {
+		ns = append(ns, encodeExp(x))
+	}
+	return ns
+}
+
+func encodeName(n ast.Name) *node {
+	return &node{Type: "Name", Loc: encodeLoc(n), Name: n.Val}
+}
+
+func encodeNames(names []ast.Name) []*node {
+	var ns []*node
+	for _, n := range names {
+		ns = append(ns, encodeName(n))
+	}
+	return ns
+}
+
+func encodeCall(tp string, f ast.BFunctionCall) *node {
+	n := &node{
+		Type:   tp,
+		Loc:    encodeLoc(f),
+		Target: encodeExp(f.Target),
+		Args:   encodeExps(f.Args),
+	}
+	if f.Method.Val != "" {
+		n.Method = encodeName(f.Method)
+	}
+	return n
+}
+
+func rawJSON(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// An encoder converts a single AST node to a JSON node.
+type encoder struct {
+	n *node
+}
+
+var _ ast.StatProcessor = (*encoder)(nil)
+var _ ast.ExpProcessor = (*encoder)(nil)
+
+// ProcessAssignStat encodes an AssignStat.
+func (e *encoder) ProcessAssignStat(s ast.AssignStat) {
+	n := &node{Type: "Assign", Loc: encodeLoc(s), Values: encodeExps(s.Src)}
+	for _, v := range s.Dest {
+		n.Targets = append(n.Targets, encodeExp(v))
+	}
+	e.n = n
+}
+
+// ProcessBlockStat encodes a BlockStat.
+func (e *encoder) ProcessBlockStat(s ast.BlockStat) {
+	e.n = encodeBlock(s)
+}
+
+// ProcessBreakStat encodes a BreakStat.
+func (e *encoder) ProcessBreakStat(s ast.BreakStat) {
+	e.n = &node{Type: "Break", Loc: encodeLoc(s)}
+}
+
+// ProcessCompoundAssignStat encodes a CompoundAssignStat.
+func (e *encoder) ProcessCompoundAssignStat(s ast.CompoundAssignStat) {
+	e.n = &node{
+		Type:    "CompoundAssign",
+		Loc:     encodeLoc(s),
+		Var:     encodeExp(s.Dest),
+		Op:      binOpNames[s.Op],
+		Operand: encodeExp(s.Value),
+	}
+}
+
+// ProcessContinueStat encodes a ContinueStat.
+func (e *encoder) ProcessContinueStat(s ast.ContinueStat) {
+	e.n = &node{Type: "Continue", Loc: encodeLoc(s)}
+}
+
+// ProcessEmptyStat encodes an EmptyStat.
+func (e *encoder) ProcessEmptyStat(s ast.EmptyStat) {
+	e.n = &node{Type: "Empty", Loc: encodeLoc(s)}
+}
+
+// ProcessForInStat encodes a ForInStat.
+func (e *encoder) ProcessForInStat(s ast.ForInStat) {
+	e.n = &node{
+		Type:  "ForIn",
+		Loc:   encodeLoc(s),
+		Names: encodeNames(s.Vars),
+		Exps:  encodeExps(s.Params),
+		Body:  encodeBlock(s.Body),
+	}
+}
+
+// ProcessForStat encodes a ForStat.
+func (e *encoder) ProcessForStat(s ast.ForStat) {
+	e.n = &node{
+		Type:  "For",
+		Loc:   encodeLoc(s),
+		Var:   encodeName(s.Var),
+		Start: encodeExp(s.Start),
+		Stop:  encodeExp(s.Stop),
+		Step:  encodeExp(s.Step),
+		Body:  encodeBlock(s.Body),
+	}
+}
+
+// ProcessFunctionCallStat encodes a FunctionCall statement.
+func (e *encoder) ProcessFunctionCallStat(f ast.FunctionCall) {
+	e.n = encodeCall("Call", *f.BFunctionCall)
+}
+
+// ProcessGotoStat encodes a GotoStat.
+func (e *encoder) ProcessGotoStat(s ast.GotoStat) {
+	e.n = &node{Type: "Goto", Loc: encodeLoc(s), Var: encodeName(s.Label)}
+}
+
+// ProcessIfStat encodes an IfStat.
+func (e *encoder) ProcessIfStat(s ast.IfStat) {
+	n := &node{Type: "If", Loc: encodeLoc(s)}
+	for _, c := range append([]ast.CondStat{s.If}, s.ElseIfs...) {
+		n.Clauses = append(n.Clauses, clause{
+			Cond: encodeExp(c.Cond),
+			Body: encodeBlock(c.Body),
+		})
+	}
+	if s.Else != nil {
+		n.Else = encodeBlock(*s.Else)
+	}
+	e.n = n
+}
+
+// ProcessLabelStat encodes a LabelStat.
+func (e *encoder) ProcessLabelStat(s ast.LabelStat) {
+	e.n = &node{Type: "Label", Loc: encodeLoc(s), Var: encodeName(s.Name)}
+}
+
+// ProcessLocalFunctionStat encodes a LocalFunctionStat.
+func (e *encoder) ProcessLocalFunctionStat(s ast.LocalFunctionStat) {
+	e.n = &node{
+		Type:     "LocalFunction",
+		Loc:      encodeLoc(s),
+		Var:      encodeName(s.Name),
+		Function: encodeExp(s.Function),
+	}
+}
+
+var attribNames = map[ast.LocalAttrib]string{
+	ast.ConstAttrib: "const",
+	ast.CloseAttrib: "close",
+}
+
+// ProcessLocalStat encodes a LocalStat.
+func (e *encoder) ProcessLocalStat(s ast.LocalStat) {
+	n := &node{Type: "Local", Loc: encodeLoc(s), Values: encodeExps(s.Values)}
+	for _, na := range s.NameAttribs {
+		n.Names = append(n.Names, &node{
+			Type:   "NameAttrib",
+			Loc:    encodeLoc(na),
+			Var:    encodeName(na.Name),
+			Attrib: attribNames[na.Attrib],
+		})
+	}
+	e.n = n
+}
+
+// ProcessRepeatStat encodes a RepeatStat.
+func (e *encoder) ProcessRepeatStat(s ast.RepeatStat) {
+	e.n = &node{
+		Type: "Repeat",
+		Loc:  encodeLoc(s),
+		Body: encodeBlock(s.Body),
+		Cond: encodeExp(s.Cond),
+	}
+}
+
+// ProcessWhileStat encodes a WhileStat.
+func (e *encoder) ProcessWhileStat(s ast.WhileStat) {
+	e.n = &node{
+		Type: "While",
+		Loc:  encodeLoc(s),
+		Cond: encodeExp(s.Cond),
+		Body: encodeBlock(s.Body),
+	}
+}
+
+// ProcessBFunctionCallExp encodes a BFunctionCall, i.e. a function call in
+// brackets.
+func (e *encoder) ProcessBFunctionCallExp(f ast.BFunctionCall) {
+	e.n = encodeCall("BCall", f)
+}
+
+// ProcessBinOpExp encodes a BinOp.
+func (e *encoder) ProcessBinOpExp(b ast.BinOp) {
+	n := &node{Type: "BinOp", Loc: encodeLoc(b), Left: encodeExp(b.Left)}
+	for _, r := range b.Right {
+		n.Ops = append(n.Ops, operation{
+			Op:      binOpNames[r.Op],
+			Operand: encodeExp(r.Operand),
+		})
+	}
+	e.n = n
+}
+
+// ProcesBoolExp encodes a Bool.
+func (e *encoder) ProcesBoolExp(b ast.Bool) {
+	e.n = &node{Type: "Bool", Loc: encodeLoc(b), Value: rawJSON(b.Val)}
+}
+
+// ProcessEtcExp encodes an Etc.
+func (e *encoder) ProcessEtcExp(x ast.Etc) {
+	e.n = &node{Type: "Etc", Loc: encodeLoc(x)}
+}
+
+// ProcessFunctionExp encodes a Function.
+func (e *encoder) ProcessFunctionExp(f ast.Function) {
+	e.n = &node{
+		Type:   "Function",
+		Loc:    encodeLoc(f),
+		Name:   f.Name,
+		Params: encodeNames(f.Params),
+		Vararg: f.HasDots,
+		Body:   encodeBlock(f.Body),
+	}
+}
+
+// ProcessFunctionCallExp encodes a FunctionCall.
+func (e *encoder) ProcessFunctionCallExp(f ast.FunctionCall) {
+	e.n = encodeCall("Call", *f.BFunctionCall)
+}
+
+// ProcessIndexExp encodes an IndexExp.
+func (e *encoder) ProcessIndexExp(x ast.IndexExp) {
+	e.n = &node{
+		Type:   "Index",
+		Loc:    encodeLoc(x),
+		Object: encodeExp(x.Coll),
+		Key:    encodeExp(x.Idx),
+	}
+}
+
+// ProcessNameExp encodes a Name.
+func (e *encoder) ProcessNameExp(n ast.Name) {
+	e.n = encodeName(n)
+}
+
+// ProcessNilExp encodes a Nil.
+func (e *encoder) ProcessNilExp(n ast.Nil) {
+	e.n = &node{Type: "Nil", Loc: encodeLoc(n)}
+}
+
+// ProcessIntExp encodes an Int.
+func (e *encoder) ProcessIntExp(n ast.Int) {
+	e.n = &node{Type: "Int", Loc: encodeLoc(n), Value: rawJSON(int64(n.Val))}
+}
+
+// ProcessFloatExp encodes a Float.  Values that cannot be represented as JSON
+// numbers are encoded as the strings "inf", "-inf" and "nan".
+func (e *encoder) ProcessFloatExp(f ast.Float) {
+	var v interface{} = f.Val
+	switch {
+	case math.IsInf(f.Val, 1):
+		v = "inf"
+	case math.IsInf(f.Val, -1):
+		v = "-inf"
+	case math.IsNaN(f.Val):
+		v = "nan"
+	}
+	e.n = &node{Type: "Float", Loc: encodeLoc(f), Value: rawJSON(v)}
+}
+
+// ProcessStringExp encodes a String.  Strings which are not valid UTF-8 are
+// encoded in base64.
+func (e *encoder) ProcessStringExp(s ast.String) {
+	n := &node{Type: "String", Loc: encodeLoc(s)}
+	if utf8.Valid(s.Val) {
+		n.Value = rawJSON(string(s.Val))
+	} else {
+		n.Base64 = base64.StdEncoding.EncodeToString(s.Val)
+	}
+	e.n = n
+}
+
+// ProcessInterpolatedStringExp encodes an InterpolatedString.  Its texts are
+// encoded as String nodes without location.
+func (e *encoder) ProcessInterpolatedStringExp(s ast.InterpolatedString) {
+	n := &node{Type: "Interp", Loc: encodeLoc(s), Exps: encodeExps(s.Exps)}
+	for _, text := range s.Texts {
+		n.Texts = append(n.Texts, encodeExp(ast.String{Val: text}))
+	}
+	e.n = n
+}
+
+// ProcessTableConstructorExp encodes a TableConstructor.
+func (e *encoder) ProcessTableConstructorExp(t ast.TableConstructor) {
+	n := &node{Type: "Table", Loc: encodeLoc(t)}
+	for _, f := range t.Fields {
+		field := &node{
+			Type:  "Field",
+			Loc:   encodeLoc(f),
+			Value: rawJSON(encodeExp(f.Value)),
+		}
+		if _, noKey := f.Key.(ast.NoTableKey); !noKey {
+			field.Key = encodeExp(f.Key)
+		}
+		n.Fields = append(n.Fields, field)
+	}
+	e.n = n
+}
+
+// ProcessUnOpExp encodes an UnOp.
+func (e *encoder) ProcessUnOpExp(u ast.UnOp) {
+	e.n = &node{
+		Type:    "UnOp",
+		Loc:     encodeLoc(u),
+		Op:      unOpNames[u.Op],
+		Operand: encodeExp(u.Operand),
+	}
+}
+
+var binOpNames = map[ops.Op]string{
+	ops.OpOr:       "or",
+	ops.OpAnd:      "and",
+	ops.OpLt:       "<",
+	ops.OpLeq:      "<=",
+	ops.OpGt:       ">",
+	ops.OpGeq:      ">=",
+	ops.OpEq:       "==",
+	ops.OpNeq:      "~=",
+	ops.OpBitOr:    "|",
+	ops.OpBitXor:   "~",
+	ops.OpBitAnd:   "&",
+	ops.OpShiftL:   "<<",
+	ops.OpShiftR:   ">>",
+	ops.OpConcat:   "..",
+	ops.OpAdd:      "+",
+	ops.OpSub:      "-",
+	ops.OpMul:      "*",
+	ops.OpDiv:      "/",
+	ops.OpFloorDiv: "//",
+	ops.OpMod:      "%",
+	ops.OpPow:      "^",
+}
+
+var unOpNames = map[ops.Op]string{
+	ops.OpNeg:    "-",
+	ops.OpNot:    "not",
+	ops.OpLen:    "#",
+	ops.OpBitNot: "~",
+}
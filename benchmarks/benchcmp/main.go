@@ -0,0 +1,191 @@
+// Command benchcmp compares the results of several runs of Go benchmarks.
+//
+// Usage:
+//
+//	benchcmp base.txt variant1.txt [variant2.txt ...]
+//
+// Each file contains the output of "go test -bench", possibly with several
+// runs of each benchmark (with the -count flag), in which case the median value
+// is used.  For each metric (time, memory and allocations per operation) a
+// table is printed with a column for each file, showing the change relative to
+// the first file.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s base.txt variant.txt...\n", os.Args[0])
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var runs []*benchRun
+	for _, path := range flag.Args() {
+		run, err := readRun(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %s\n", path, err)
+			os.Exit(1)
+		}
+		runs = append(runs, run)
+	}
+	writeReport(os.Stdout, runs)
+}
+
+// A benchRun contains the results of running benchmarks for one variant.
+type benchRun struct {
+	name    string
+	names   []string                        // Benchmark names, in order of appearance
+	results map[string]map[string][]float64 // Values by benchmark name and unit
+}
+
+// The metrics reported, in order.
+var units = []string{"ns/op", "B/op", "allocs/op"}
+
+// Matches e.g. "BenchmarkLua/nbody-8   	     100	  11594587 ns/op"
+var benchLineRe = regexp.MustCompile(`^(Benchmark\S*?)(?:-\d+)?\s+\d+\s+(.*)$`)
+
+func readRun(path string) (*benchRun, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseRun(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), f)
+}
+
+func parseRun(name string, r io.Reader) (*benchRun, error) {
+	run := &benchRun{name: name, results: map[string]map[string][]float64{}}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		match := benchLineRe.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		bench := match[1]
+		results := run.results[bench]
+		if results == nil {
+			results = map[string][]float64{}
+			run.results[bench] = results
+			run.names = append(run.names, bench)
+		}
+		// The rest of the line is made of (value, unit) pairs.
+		fields := strings.Fields(match[2])
+		for i := 0; i+1 < len(fields); i += 2 {
+			val, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %s", fields[i], bench)
+			}
+			unit := fields[i+1]
+			results[unit] = append(results[unit], val)
+		}
+	}
+	return run, scanner.Err()
+}
+
+// value returns the median of the values recorded for the given benchmark and
+// unit, and false if there are none.
+func (r *benchRun) value(bench, unit string) (float64, bool) {
+	vals := append([]float64(nil), r.results[bench][unit]...)
+	if len(vals) == 0 {
+		return 0, false
+	}
+	sort.Float64s(vals)
+	n := len(vals)
+	if n%2 == 1 {
+		return vals[n/2], true
+	}
+	return (vals[n/2-1] + vals[n/2]) / 2, true
+}
+
+func writeReport(w io.Writer, runs []*benchRun) {
+	base := runs[0]
+	for _, unit := range units {
+		if !hasUnit(runs, unit) {
+			continue
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		header := []string{unit}
+		for _, run := range runs {
+			header = append(header, run.name)
+			if run != base {
+				header = append(header, "delta")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, bench := range base.names {
+			row := []string{strings.TrimPrefix(bench, "Benchmark")}
+			baseVal, hasBase := base.value(bench, unit)
+			for _, run := range runs {
+				val, ok := run.value(bench, unit)
+				if !ok {
+					row = append(row, "-")
+				} else {
+					row = append(row, formatValue(val, unit))
+				}
+				if run == base {
+					continue
+				}
+				if ok && hasBase {
+					row = append(row, formatDelta(baseVal, val))
+				} else {
+					row = append(row, "")
+				}
+			}
+			fmt.Fprintln(tw, strings.TrimRight(strings.Join(row, "\t"), "\t"))
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+}
+
+func hasUnit(runs []*benchRun, unit string) bool {
+	for _, run := range runs {
+		for _, results := range run.results {
+			if len(results[unit]) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatValue(val float64, unit string) string {
+	if unit != "ns/op" {
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	switch {
+	case val >= 1e9:
+		return fmt.Sprintf("%.2fs", val/1e9)
+	case val >= 1e6:
+		return fmt.Sprintf("%.2fms", val/1e6)
+	case val >= 1e3:
+		return fmt.Sprintf("%.2fµs", val/1e3)
+	default:
+		return fmt.Sprintf("%.2fns", val)
+	}
+}
+
+func formatDelta(base, val float64) string {
+	if base == 0 {
+		if val == 0 {
+			return "~"
+		}
+		return "+inf"
+	}
+	return fmt.Sprintf("%+.1f%%", (val-base)/base*100)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const baseOutput = `goos: linux
+goarch: amd64
+pkg: github.com/arnodel/golua/benchmarks
+BenchmarkLua/nbody-8         	     100	  2000000 ns/op	  1000 B/op	      10 allocs/op
+BenchmarkLua/nbody-8         	     100	  3000000 ns/op	  1000 B/op	      10 allocs/op
+BenchmarkLua/nbody-8         	     100	  9000000 ns/op	  1000 B/op	      10 allocs/op
+BenchmarkLua/strings-8       	    1000	     1500 ns/op	     0 B/op	       0 allocs/op
+PASS
+`
+
+const variantOutput = `BenchmarkLua/nbody-8         	     100	  1500000 ns/op	  1200 B/op	      12 allocs/op
+BenchmarkLua/closures-8      	     100	  1500000 ns/op	  1200 B/op	      12 allocs/op
+`
+
+func TestReport(t *testing.T) {
+	base, err := parseRun("base", strings.NewReader(baseOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	variant, err := parseRun("variant", strings.NewReader(variantOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	writeReport(&out, []*benchRun{base, variant})
+	expected := `ns/op        base    variant  delta
+Lua/nbody    3.00ms  1.50ms   -50.0%
+Lua/strings  1.50µs  -
+
+B/op         base  variant  delta
+Lua/nbody    1000  1200     +20.0%
+Lua/strings  0     -
+
+allocs/op    base  variant  delta
+Lua/nbody    10    12       +20.0%
+Lua/strings  0     -
+
+`
+	if got := out.String(); got != expected {
+		t.Errorf("Unexpected report:\n%s", got)
+	}
+}
@@ -0,0 +1,48 @@
+package benchmarks
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	"github.com/arnodel/golua/luatesting"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Check that the benchmarks produce the expected output.
+func TestBenchmarks(t *testing.T) {
+	luatesting.RunLuaTestsInDir(t, "lua", lib.LoadAll)
+}
+
+func BenchmarkLua(b *testing.B) {
+	paths, err := filepath.Glob("lua/*.lua")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, path := range paths {
+		path := path
+		name := strings.TrimSuffix(filepath.Base(path), ".lua")
+		b.Run(name, func(b *testing.B) {
+			src, err := ioutil.ReadFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			r := rt.New(ioutil.Discard)
+			defer lib.LoadAll(r)()
+			clos, err := r.CompileAndLoadLuaChunk(name, src, rt.TableValue(r.GlobalEnv()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err := rt.Call(r.MainThread(), rt.FunctionValue(clos), nil, rt.NewTerminationWith(nil, 0, false))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
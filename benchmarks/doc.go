@@ -0,0 +1,20 @@
+// Package benchmarks contains a suite of Lua programs used to measure the
+// performance of the runtime.
+//
+// Each program in the lua directory is run as a sub-benchmark of BenchmarkLua,
+// reporting allocations as well as time.  The programs contain expected output
+// lines (as in the test files of other packages) which are checked by
+// TestBenchmarks, so they cannot silently stop doing the work they measure.
+//
+// To see the effect of a change, save the output of the benchmarks for each
+// variant and compare them with the benchcmp command, e.g. for the pool build
+// tags:
+//
+//	go test -run XXX -bench . -count 5 ./benchmarks > base.txt
+//	go test -run XXX -bench . -count 5 -tags nocontpool ./benchmarks > nocontpool.txt
+//	go test -run XXX -bench . -count 5 -tags noregpool ./benchmarks > noregpool.txt
+//	go run ./benchmarks/benchcmp base.txt nocontpool.txt noregpool.txt
+//
+// The same works for comparing two commits: run the benchmarks on each and
+// compare the outputs.
+package benchmarks
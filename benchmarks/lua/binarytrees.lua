@@ -0,0 +1,51 @@
+-- Allocation of many small tables (binary trees benchmark from the benchmarks
+-- game).
+
+local function bottomUpTree(depth)
+    if depth > 0 then
+        depth = depth - 1
+        local left, right = bottomUpTree(depth), bottomUpTree(depth)
+        return { left, right }
+    else
+        return { }
+    end
+end
+
+local function itemCheck(tree)
+    if tree[1] then
+        return 1 + itemCheck(tree[1]) + itemCheck(tree[2])
+    else
+        return 1
+    end
+end
+
+local N = 10
+local mindepth = 4
+local maxdepth = mindepth + 2
+if maxdepth < N then maxdepth = N end
+
+do
+    local stretchdepth = maxdepth + 1
+    local stretchtree = bottomUpTree(stretchdepth)
+    print(string.format("stretch tree of depth %d\t check: %d", stretchdepth, itemCheck(stretchtree)))
+end
+
+local longlivedtree = bottomUpTree(maxdepth)
+
+for depth = mindepth, maxdepth, 2 do
+    local iterations = 2 ^ (maxdepth - depth + mindepth)
+    local check = 0
+    for i = 1, iterations do
+        check = check + itemCheck(bottomUpTree(depth))
+    end
+    print(string.format("%d\t trees of depth %d\t check: %d", iterations, depth, check))
+end
+
+print(string.format("long lived tree of depth %d\t check: %d", maxdepth, itemCheck(longlivedtree)))
+
+--> =stretch tree of depth 11	 check: 4095
+--> =1024	 trees of depth 4	 check: 31744
+--> =256	 trees of depth 6	 check: 32512
+--> =64	 trees of depth 8	 check: 32704
+--> =16	 trees of depth 10	 check: 32752
+--> =long lived tree of depth 10	 check: 2047
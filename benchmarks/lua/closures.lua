@@ -0,0 +1,72 @@
+-- Closure creation, upvalue access and higher order functions.
+
+local N = 5000
+
+local function map(f, t)
+    local r = {}
+    for i = 1, #t do r[i] = f(t[i]) end
+    return r
+end
+
+local function filter(p, t)
+    local r = {}
+    for i = 1, #t do
+        if p(t[i]) then r[#r + 1] = t[i] end
+    end
+    return r
+end
+
+local function reduce(f, acc, t)
+    for i = 1, #t do acc = f(acc, t[i]) end
+    return acc
+end
+
+local nums = {}
+for i = 1, N do nums[i] = i end
+
+local total = 0
+for k = 1, 10 do
+    local squares = map(function(x) return x * x + k end, nums)
+    local odd = filter(function(x) return x % 2 == 1 end, squares)
+    total = total + reduce(function(a, b) return a + b end, 0, odd)
+end
+print(total)
+
+-- Counters sharing upvalues
+local function counter()
+    local n = 0
+    return function() n = n + 1; return n end, function() return n end
+end
+local incs, gets = {}, {}
+for i = 1, 100 do incs[i], gets[i] = counter() end
+for j = 1, 50 do
+    for i = 1, 100 do
+        if i % (j % 10 + 1) == 0 then incs[i]() end
+    end
+end
+local s = 0
+for i = 1, 100 do s = s + gets[i]() end
+print(s)
+
+-- Memoization through closures
+local function memoize(f)
+    local cache = {}
+    return function(n)
+        local v = cache[n]
+        if v == nil then
+            v = f(n)
+            cache[n] = v
+        end
+        return v
+    end
+end
+local fib
+fib = memoize(function(n)
+    if n < 2 then return n end
+    return fib(n - 1) + fib(n - 2)
+end)
+print(fib(80))
+
+--> =208395975000
+--> =1455
+--> =23416728348467685
@@ -0,0 +1,59 @@
+-- Switching between coroutines (ping-pong between a producer and a consumer,
+-- and a chain of generators).
+
+local N = 5000
+
+local producer = coroutine.wrap(function()
+    for i = 1, N do
+        coroutine.yield(i)
+    end
+    return nil
+end)
+
+local sum = 0
+while true do
+    local v = producer()
+    if v == nil then break end
+    sum = sum + v
+end
+print(sum)
+
+-- A chain of filters, each one a coroutine
+local function gen(n)
+    return coroutine.wrap(function()
+        for i = 1, n do coroutine.yield(i) end
+    end)
+end
+
+local function filter(p, g)
+    return coroutine.wrap(function()
+        for x in g do
+            if x % p ~= 0 then coroutine.yield(x) end
+        end
+    end)
+end
+
+local g = gen(N)
+for _, p in ipairs({2, 3, 5, 7}) do
+    g = filter(p, g)
+end
+local count = 0
+for x in g do count = count + 1 end
+print(count)
+
+-- Passing values both ways
+local co = coroutine.create(function(a)
+    while true do
+        a = coroutine.yield(a * 2)
+    end
+end)
+local acc = 0
+for i = 1, N do
+    local _, v = coroutine.resume(co, i)
+    acc = acc + v
+end
+print(acc)
+
+--> =12502500
+--> =1143
+--> =25005000
@@ -0,0 +1,38 @@
+-- String building: concatenation, table.concat, string.format, string.rep and
+-- pattern matching.
+
+local N = 2000
+
+-- Concatenation in a loop
+local s = ""
+for i = 1, N do
+    s = s .. i % 10
+end
+print(#s)
+
+-- Building with a buffer
+local buf = {}
+for i = 1, N do
+    buf[#buf + 1] = string.format("item%d=%s", i, ("x"):rep(i % 5))
+end
+local joined = table.concat(buf, ";")
+print(#joined)
+
+-- Pattern matching on the result
+local count, total = 0, 0
+for k, v in joined:gmatch("item(%d+)=(x*)") do
+    count = count + 1
+    total = total + #v
+end
+print(count, total)
+
+local replaced, n = joined:gsub("x+", function(xs) return #xs end)
+print(#replaced, n)
+
+print(joined:sub(1, 20):upper())
+
+--> =2000
+--> =22892
+--> =2000	4000
+--> =20492	1600
+--> =ITEM1=X;ITEM2=XX;ITE
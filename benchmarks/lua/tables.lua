@@ -0,0 +1,60 @@
+-- Table-heavy code: array insertion and removal, hash tables with string and
+-- integer keys, sorting and iteration.
+
+local N = 5000
+
+-- Arrays
+local arr = {}
+for i = 1, N do
+    table.insert(arr, (i * 7919) % N)
+end
+table.sort(arr)
+local sum = 0
+for i, v in ipairs(arr) do
+    sum = sum + v
+end
+for i = 1, N // 2 do
+    table.remove(arr)
+end
+print(#arr, sum, arr[1], arr[#arr])
+
+-- Hash tables with string keys
+local counts = {}
+for i = 1, N do
+    local key = "k" .. (i % 100)
+    counts[key] = (counts[key] or 0) + 1
+end
+local nkeys, ntotal = 0, 0
+for k, v in pairs(counts) do
+    nkeys = nkeys + 1
+    ntotal = ntotal + v
+end
+print(nkeys, ntotal)
+
+-- Sparse integer keys and removal
+local sparse = {}
+for i = 1, N do
+    sparse[i * i] = i
+end
+for i = 1, N, 2 do
+    sparse[i * i] = nil
+end
+local n = 0
+for k, v in pairs(sparse) do n = n + 1 end
+print(n)
+
+-- Records
+local records = {}
+for i = 1, N do
+    records[i] = {id = i, name = "name" .. i, score = (i * 31) % 101}
+end
+table.sort(records, function(a, b)
+    if a.score ~= b.score then return a.score > b.score end
+    return a.id < b.id
+end)
+print(records[1].name, records[1].score, records[N].name, records[N].score)
+
+--> =2500	12497500	0	2499
+--> =100	5000
+--> =2500
+--> =name13	100	name4949	0
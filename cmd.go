@@ -11,20 +11,37 @@ import (
 	"strings"
 
 	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/astjson"
+	"github.com/arnodel/golua/code"
+	"github.com/arnodel/golua/gocomp"
 	"github.com/arnodel/golua/lib"
 	"github.com/arnodel/golua/lib/base"
 	"github.com/arnodel/golua/lib/debuglib"
 	"github.com/arnodel/golua/lib/iolib"
+	"github.com/arnodel/golua/luafmt"
 	rt "github.com/arnodel/golua/runtime"
+	"github.com/arnodel/golua/scanner"
+	"github.com/arnodel/golua/sourcemap"
+	"github.com/arnodel/golua/token"
 )
 
 type luaCmd struct {
 	disFlag        bool
 	astFlag        bool
+	astFormat      string
+	astInFlag      bool
+	goFlag         bool
+	goPackage      string
+	goFunc         string
 	unbufferedFlag bool
 	cpuLimit       uint64
 	memLimit       uint64
 	flags          string
+	luaVersion     string
+	typesFlag      bool
+	extensions     string
+	columnsFlag    bool
+	sourceMap      string
 	exec           execFlags
 
 	complianceFlags rt.ComplianceFlags
@@ -33,8 +50,18 @@ type luaCmd struct {
 func (c *luaCmd) setFlags() {
 	flag.BoolVar(&c.disFlag, "dis", false, "Disassemble source instead of running it")
 	flag.BoolVar(&c.astFlag, "ast", false, "Print AST instead of running code")
+	flag.StringVar(&c.astFormat, "astformat", "tree", "Format of the AST printed with -ast: tree, json or lua")
+	flag.BoolVar(&c.astInFlag, "astin", false, "Read the chunk as an AST in JSON format (as printed by -ast -astformat=json)")
+	flag.BoolVar(&c.goFlag, "go", false, "Compile source to Go instead of running it")
+	flag.StringVar(&c.goPackage, "gopkg", "main", "Package of the Go code generated with -go")
+	flag.StringVar(&c.goFunc, "gofunc", "Chunk", "Name of the function returning the main chunk in the Go code generated with -go")
 	flag.BoolVar(&c.unbufferedFlag, "u", false, "Force unbuffered output")
 	flag.Var(&c.exec, "e", "statement to execute")
+	flag.StringVar(&c.luaVersion, "lua", "5.4", "Version of Lua to emulate: 5.1, 5.2, 5.3 or 5.4")
+	flag.BoolVar(&c.typesFlag, "types", false, "Allow type annotations and check them before running code")
+	flag.StringVar(&c.extensions, "ext", "", "Syntax extensions allowed, separated by commas: compound, continue, interpolation or all")
+	flag.BoolVar(&c.columnsFlag, "columns", false, "Include source columns in error messages and tracebacks")
+	flag.StringVar(&c.sourceMap, "sourcemap", "", "Source map (JSON version 3) mapping the chunk to its original source")
 
 	if rt.QuotasAvailable {
 		flag.Uint64Var(&c.cpuLimit, "cpulimit", 0, "CPU limit")
@@ -69,8 +96,28 @@ func (c *luaCmd) run() (retcode int) {
 		}
 	}
 
+	luaVersion, err := rt.ParseLuaVersion(c.luaVersion)
+	if err != nil {
+		return fatal("%s", err)
+	}
+
+	extensions, err := token.ParseExtensions(c.extensions)
+	if err != nil {
+		return fatal("%s", err)
+	}
+
 	// Get a Lua runtime
-	r := rt.New(nil)
+	rtOpts := []rt.RuntimeOption{rt.WithLuaVersion(luaVersion)}
+	if c.typesFlag {
+		rtOpts = append(rtOpts, rt.WithTypeAnnotations())
+	}
+	if extensions != token.NoExtensions {
+		rtOpts = append(rtOpts, rt.WithSyntaxExtensions(extensions))
+	}
+	if c.columnsFlag {
+		rtOpts = append(rtOpts, rt.WithErrorColumns())
+	}
+	r := rt.New(nil, rtOpts...)
 	c.pushContext(r)
 
 	cleanup := lib.LoadAll(r)
@@ -93,6 +140,18 @@ func (c *luaCmd) run() (retcode int) {
 		args = flag.Args()[1:]
 	}
 
+	if c.sourceMap != "" {
+		data, err := ioutil.ReadFile(c.sourceMap)
+		if err != nil {
+			return fatal("Error reading '%s': %s", c.sourceMap, err)
+		}
+		m, err := sourcemap.Parse(data)
+		if err != nil {
+			return fatal("Error reading '%s': %s", c.sourceMap, err)
+		}
+		r.SetSourceMap(chunkName, m)
+	}
+
 	var argVals []rt.Value
 	if len(args) > 0 {
 		argTable := rt.NewTable()
@@ -128,17 +187,29 @@ func (c *luaCmd) run() (retcode int) {
 	}
 
 	if c.astFlag {
-		stat, _, err := r.ParseLuaChunk(chunkName, chunk)
+		stat, err := c.parse(r, chunkName, chunk)
 		if err != nil {
 			return fatal("Error parsing %s: %s", chunkName, err)
 		}
-		w := ast.NewIndentWriter(os.Stdout)
-		stat.HWrite(w)
+		switch c.astFormat {
+		case "tree":
+			w := ast.NewIndentWriter(os.Stdout)
+			stat.HWrite(w)
+		case "json":
+			err = astjson.Encode(os.Stdout, stat)
+		case "lua":
+			err = luafmt.Fprint(os.Stdout, stat)
+		default:
+			return fatal("Unknown AST format: %s", c.astFormat)
+		}
+		if err != nil {
+			return fatal("Error printing AST: %s", err)
+		}
 		return 0
 	}
 
 	if c.disFlag {
-		unit, _, err := r.CompileLuaChunk(chunkName, chunk)
+		unit, err := c.compile(r, chunkName, chunk)
 		if err != nil {
 			return fatal("Error parsing %s: %s", chunkName, err)
 		}
@@ -146,6 +217,18 @@ func (c *luaCmd) run() (retcode int) {
 		return 0
 	}
 
+	if c.goFlag {
+		unit, err := c.compile(r, chunkName, chunk)
+		if err != nil {
+			return fatal("Error parsing %s: %s", chunkName, err)
+		}
+		opts := gocomp.Options{Package: c.goPackage, FuncName: c.goFunc}
+		if err := gocomp.Compile(os.Stdout, unit, opts); err != nil {
+			return fatal("Error compiling %s to Go: %s", chunkName, err)
+		}
+		return 0
+	}
+
 	defer func() {
 		if rec := recover(); rec != nil {
 			quotaExceeded, ok := rec.(rt.ContextTerminationError)
@@ -157,7 +240,16 @@ func (c *luaCmd) run() (retcode int) {
 		}
 	}()
 
-	clos, err := r.LoadFromSourceOrCode(chunkName, chunk, "bt", rt.TableValue(r.GlobalEnv()), true)
+	var clos *rt.Closure
+	if c.astInFlag {
+		var unit *code.Unit
+		unit, err = c.compile(r, chunkName, chunk)
+		if err == nil {
+			clos = r.LoadLuaUnit(unit, rt.TableValue(r.GlobalEnv()))
+		}
+	} else {
+		clos, err = r.LoadFromSourceOrCode(chunkName, chunk, "bt", rt.TableValue(r.GlobalEnv()), true)
+	}
 	if err != nil {
 		return fatal("Error loading %s: %s", chunkName, err)
 	}
@@ -168,6 +260,50 @@ func (c *luaCmd) run() (retcode int) {
 	return 0
 }
 
+// parse returns the AST of the chunk, which is Lua source code or, with -astin,
+// a JSON AST.
+func (c *luaCmd) parse(r *rt.Runtime, name string, chunk []byte) (ast.BlockStat, error) {
+	if c.astInFlag {
+		return astjson.Unmarshal(chunk)
+	}
+	stat, _, err := r.ParseLuaChunk(name, chunk, scanner.WithComments())
+	if err != nil {
+		return ast.BlockStat{}, err
+	}
+	return *stat, nil
+}
+
+// compile returns the compiled code of the chunk (see parse).
+func (c *luaCmd) compile(r *rt.Runtime, name string, chunk []byte) (*code.Unit, error) {
+	if !c.astInFlag {
+		unit, _, err := r.CompileLuaChunk(name, chunk)
+		return unit, err
+	}
+	stat, err := c.parse(r, name, chunk)
+	if err != nil {
+		return nil, err
+	}
+	unit, _, err := r.CompileLuaAST(name, stat)
+	return unit, err
+}
+
+// Subcommands are invoked as "golua <name> args...".  They return the exit code
+// of the process.
+var subcommands = map[string]func(args []string) int{}
+
+// runSubcommand runs the subcommand named by the first command line argument
+// if there is one.
+func runSubcommand() (int, bool) {
+	if len(os.Args) < 2 {
+		return 0, false
+	}
+	sub, ok := subcommands[os.Args[1]]
+	if !ok {
+		return 0, false
+	}
+	return sub(os.Args[2:]), true
+}
+
 func fatal(tpl string, args ...interface{}) int {
 	fmt.Fprintf(os.Stderr, tpl+"\n", args...)
 	return 1
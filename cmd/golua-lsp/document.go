@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/lint"
+	"github.com/arnodel/golua/parsing"
+	"github.com/arnodel/golua/scanner"
+	"github.com/arnodel/golua/token"
+)
+
+// A document is an open Lua file.
+type document struct {
+	uri        string
+	text       []byte
+	lineStarts []int // Offsets of the start of each line
+	index      *index
+	diags      []diagnostic
+}
+
+// newDocument parses and analyses the text of a document.
+func newDocument(uri string, text []byte) *document {
+	d := &document{uri: uri, text: text, lineStarts: []int{0}}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+		default:
+			continue
+		}
+		d.lineStarts = append(d.lineStarts, i+1)
+	}
+	d.diags = []diagnostic{}
+	// Keep going after syntax errors so that the statements that could be
+	// parsed can still be navigated while editing.
+	chunk, errs := parsing.ParseChunkWithRecovery(scanner.New(uri, text))
+	d.index = resolve(chunk)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			d.diags = append(d.diags, d.syntaxDiagnostic(err))
+		}
+		// The partial AST would cause spurious lint warnings
+		return d
+	}
+	for _, ld := range lint.Lint(uri, chunk, lint.Config{}) {
+		start := d.offsetAt(ld.Line, ld.Column)
+		d.diags = append(d.diags, diagnostic{
+			Range:    lspRange{d.position(start), d.position(d.wordEnd(start))},
+			Severity: severityWarning,
+			Source:   "golua-lint",
+			Message:  ld.Message,
+		})
+	}
+	return d
+}
+
+func (d *document) syntaxDiagnostic(err parsing.Error) diagnostic {
+	tok := err.Got
+	start := tok.Offset
+	end := start
+	if tok.Type != token.EOF {
+		end = d.lineEnd(start, start+len(tok.Lit))
+	}
+	return diagnostic{
+		Range:    lspRange{d.position(start), d.position(end)},
+		Severity: severityError,
+		Source:   "golua",
+		Message:  strings.TrimPrefix(err.Error(), fmt.Sprintf("%d:%d: ", tok.Line, tok.Column)),
+	}
+}
+
+// position converts a byte offset to an LSP position, whose character is
+// measured in UTF-16 code units.
+func (d *document) position(offset int) position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	line := len(d.lineStarts) - 1
+	for line > 0 && d.lineStarts[line] > offset {
+		line--
+	}
+	n := 0
+	for _, r := range string(d.text[d.lineStarts[line]:offset]) {
+		n += utf16Len(r)
+	}
+	return position{Line: line, Character: n}
+}
+
+// offset converts an LSP position to a byte offset.
+func (d *document) offset(p position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+	i := d.lineStarts[p.Line]
+	for n := 0; n < p.Character && i < len(d.text); {
+		r, size := utf8.DecodeRune(d.text[i:])
+		if r == '\r' || r == '\n' {
+			break
+		}
+		n += utf16Len(r)
+		i += size
+	}
+	return i
+}
+
+// offsetAt returns the byte offset of a 1-based line and column, where the
+// column counts runes (as in token.Pos).
+func (d *document) offsetAt(line, col int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(d.lineStarts) {
+		return len(d.text)
+	}
+	i := d.lineStarts[line-1]
+	for ; col > 1 && i < len(d.text); col-- {
+		_, size := utf8.DecodeRune(d.text[i:])
+		i += size
+	}
+	return i
+}
+
+// lineEnd returns end, truncated to the end of the line containing start.
+func (d *document) lineEnd(start, end int) int {
+	for i := start; i < end && i < len(d.text); i++ {
+		if d.text[i] == '\n' || d.text[i] == '\r' {
+			return i
+		}
+	}
+	if end > len(d.text) {
+		return len(d.text)
+	}
+	return end
+}
+
+// wordEnd returns the end of the identifier or keyword starting at offset, or
+// the offset after the next character if there is none.
+func (d *document) wordEnd(offset int) int {
+	i := offset
+	for i < len(d.text) && isWordByte(d.text[i]) {
+		i++
+	}
+	if i == offset && i < len(d.text) && d.text[i] != '\n' && d.text[i] != '\r' {
+		_, size := utf8.DecodeRune(d.text[i:])
+		i += size
+	}
+	return i
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// nameRange returns the range of a symbol name at the given position.
+func (d *document) nameRange(pos *token.Pos, name string) lspRange {
+	return lspRange{d.position(pos.Offset), d.position(pos.Offset + len(name))}
+}
+
+// locRange returns the range spanned by an AST location.
+func (d *document) locRange(loc ast.Location) lspRange {
+	start, end := loc.StartPos(), loc.EndPos()
+	if start == nil {
+		return lspRange{}
+	}
+	if end == nil {
+		end = start
+	}
+	return lspRange{d.position(start.Offset), d.position(d.wordEnd(end.Offset))}
+}
+
+//
+// Language features
+//
+
+func (d *document) definition(p position) []location {
+	sym, _ := d.index.symbolAt(d.offset(p))
+	if sym == nil || sym.decl == nil {
+		return nil
+	}
+	return []location{{URI: d.uri, Range: d.nameRange(sym.decl, sym.name)}}
+}
+
+func (d *document) references(p position, includeDecl bool) []location {
+	sym, _ := d.index.symbolAt(d.offset(p))
+	if sym == nil {
+		return nil
+	}
+	locs := []location{}
+	for _, ref := range sym.refs {
+		if ref == sym.decl && !includeDecl {
+			continue
+		}
+		locs = append(locs, location{URI: d.uri, Range: d.nameRange(ref, sym.name)})
+	}
+	return locs
+}
+
+func (d *document) hover(p position) *hover {
+	sym, pos := d.index.symbolAt(d.offset(p))
+	if sym == nil {
+		return nil
+	}
+	value := "```lua\n" + sym.describe() + "\n```"
+	if sym.decl != nil {
+		value += fmt.Sprintf("\n\nDeclared on line %d", sym.decl.Line)
+	}
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: value},
+		Range:    d.nameRange(pos, sym.name),
+	}
+}
+
+func (d *document) symbols() []documentSymbol {
+	return d.documentSymbols(d.index.outline)
+}
+
+func (d *document) documentSymbols(items []*outlineItem) []documentSymbol {
+	syms := make([]documentSymbol, len(items))
+	for i, item := range items {
+		kind := symbolKindVariable
+		if item.isFunc {
+			kind = symbolKindFunction
+			if strings.Contains(item.name, ":") {
+				kind = symbolKindMethod
+			}
+		}
+		sel := d.nameRange(item.selection, lastComponent(item.name))
+		rng := d.locRange(item.loc)
+		if rng == (lspRange{}) {
+			rng = sel
+		}
+		syms[i] = documentSymbol{
+			Name:           item.name,
+			Kind:           kind,
+			Range:          rng,
+			SelectionRange: sel,
+			Children:       d.documentSymbols(item.children),
+		}
+	}
+	return syms
+}
+
+func lastComponent(name string) string {
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
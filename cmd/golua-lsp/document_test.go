@@ -0,0 +1,213 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSource = `local M = {}
+
+function M.greet(name)
+    local msg = "hello " .. name
+    print(msg)
+    return msg
+end
+
+function M:method(x) return self, x end
+
+local function fact(n)
+    if n <= 1 then return 1 end
+    return n * fact(n - 1)
+end
+
+count = 0
+count = count + 1
+for i = 1, 3 do
+    local count = i
+    print(count)
+end
+return M, fact
+`
+
+// at returns the position of the nth (0-based) occurrence of word in the test
+// source.
+func at(t *testing.T, word string, n int) position {
+	t.Helper()
+	for i, line := range strings.Split(testSource, "\n") {
+		for col := 0; ; {
+			j := strings.Index(line[col:], word)
+			if j < 0 {
+				break
+			}
+			if n == 0 {
+				return position{Line: i, Character: col + j}
+			}
+			n--
+			col += j + len(word)
+		}
+	}
+	t.Fatalf("occurrence %d of %q not found", n, word)
+	return position{}
+}
+
+func rangeAt(t *testing.T, word string, n int) lspRange {
+	start := at(t, word, n)
+	end := start
+	end.Character += len(word)
+	return lspRange{start, end}
+}
+
+func TestDefinition(t *testing.T) {
+	d := newDocument("test.lua", []byte(testSource))
+	tests := []struct {
+		name string
+		pos  position
+		want []location
+	}{
+		{"local", at(t, "msg", 2), []location{{"test.lua", rangeAt(t, "msg", 0)}}},
+		{"parameter", at(t, "name", 1), []location{{"test.lua", rangeAt(t, "name", 0)}}},
+		{"recursive", at(t, "fact", 1), []location{{"test.lua", rangeAt(t, "fact", 0)}}},
+		{"global", at(t, "count", 2), []location{{"test.lua", rangeAt(t, "count", 0)}}},
+		{"shadowing", at(t, "count", 4), []location{{"test.lua", rangeAt(t, "count", 3)}}},
+		{"end of name", at(t, "ount", 4), []location{{"test.lua", rangeAt(t, "count", 3)}}},
+		{"builtin", at(t, "print", 0), nil},
+		{"field", at(t, "greet", 0), nil},
+		{"keyword", at(t, "return", 0), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := d.definition(test.pos)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	d := newDocument("test.lua", []byte(testSource))
+	got := d.references(at(t, "count", 0), true)
+	want := []location{
+		{"test.lua", rangeAt(t, "count", 0)},
+		{"test.lua", rangeAt(t, "count", 1)},
+		{"test.lua", rangeAt(t, "count", 2)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got = d.references(at(t, "M", 2), false)
+	want = []location{
+		{"test.lua", rangeAt(t, "M", 1)},
+		{"test.lua", rangeAt(t, "M", 2)},
+		{"test.lua", rangeAt(t, "M", 3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestHover(t *testing.T) {
+	d := newDocument("test.lua", []byte(testSource))
+	tests := []struct {
+		pos  position
+		want string
+	}{
+		{at(t, "fact", 2), "local function fact(n)"},
+		{at(t, "msg", 1), "local msg"},
+		{at(t, "name", 1), "(parameter) name"},
+		{at(t, "self", 0), "(parameter) self"},
+		{at(t, "i = 1", 0), "(loop variable) i"},
+		{at(t, "count", 1), "global count"},
+	}
+	for _, test := range tests {
+		h := d.hover(test.pos)
+		if h == nil {
+			t.Errorf("%v: no hover", test.pos)
+			continue
+		}
+		if !strings.Contains(h.Contents.Value, "```lua\n"+test.want+"\n```") {
+			t.Errorf("%v: got %q, want %q", test.pos, h.Contents.Value, test.want)
+		}
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	d := newDocument("test.lua", []byte(testSource))
+	var names []string
+	var collect func(syms []documentSymbol, prefix string)
+	collect = func(syms []documentSymbol, prefix string) {
+		for _, s := range syms {
+			names = append(names, prefix+s.Name)
+			collect(s.Children, prefix+s.Name+"/")
+		}
+	}
+	collect(d.symbols(), "")
+	want := []string{"M", "M.greet", "M:method", "fact"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	d := newDocument("test.lua", []byte("local x = 1\nlocal y = = 2"))
+	want := []diagnostic{{
+		Range:    lspRange{position{1, 10}, position{1, 11}},
+		Severity: severityError,
+		Source:   "golua",
+		Message:  "unexpected symbol near '='",
+	}}
+	if !reflect.DeepEqual(d.diags, want) {
+		t.Errorf("got %v, want %v", d.diags, want)
+	}
+	d = newDocument("test.lua", []byte("local x = = 1\nprint(x)\nlocal y = )"))
+	want = []diagnostic{
+		{
+			Range:    lspRange{position{0, 10}, position{0, 11}},
+			Severity: severityError,
+			Source:   "golua",
+			Message:  "unexpected symbol near '='",
+		},
+		{
+			Range:    lspRange{position{2, 10}, position{2, 11}},
+			Severity: severityError,
+			Source:   "golua",
+			Message:  "unexpected symbol near ')'",
+		},
+	}
+	if !reflect.DeepEqual(d.diags, want) {
+		t.Errorf("got %v, want %v", d.diags, want)
+	}
+	d = newDocument("test.lua", []byte("local x = 1"))
+	want = []diagnostic{{
+		Range:    lspRange{position{0, 6}, position{0, 7}},
+		Severity: severityWarning,
+		Source:   "golua-lint",
+		Message:  "unused local variable 'x'",
+	}}
+	if !reflect.DeepEqual(d.diags, want) {
+		t.Errorf("got %v, want %v", d.diags, want)
+	}
+}
+
+func TestPartialIndex(t *testing.T) {
+	// Navigation still works in the parts of the document that can be parsed.
+	d := newDocument("test.lua", []byte("local x = 1\nlocal y = = 2\nprint(x)"))
+	got := d.definition(position{2, 6})
+	want := []location{{"test.lua", lspRange{position{0, 6}, position{0, 7}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	d := newDocument("test.lua", []byte("a\r\nb = 'é𝄞' .. c\n"))
+	c := strings.Index(string(d.text), "c")
+	p := d.position(c)
+	if want := (position{1, 13}); p != want {
+		t.Errorf("got %v, want %v", p, want)
+	}
+	if got := d.offset(p); got != c {
+		t.Errorf("got offset %d, want %d", got, c)
+	}
+}
@@ -0,0 +1,32 @@
+// Command golua-lsp is a language server for Lua.  It communicates with the
+// editor over stdin / stdout using the Language Server Protocol and provides
+// diagnostics, go-to-definition, find-references, hover and document symbols.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	os.Exit(newServer(os.Stdin, os.Stdout).run())
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Help for %s:\n%s\nUsage:\n", os.Args[0], helpMessage)
+	flag.PrintDefaults()
+}
+
+const helpMessage = `
+golua-lsp is a language server for Lua.  It is meant to be started by an
+editor and communicates with it over stdin / stdout.
+
+Features:
+  - Syntax errors and lint warnings are reported as diagnostics
+  - Go to definition and find references of local and global variables
+  - Hover shows how a variable was declared
+  - Document symbols list functions and top level locals
+`
@@ -0,0 +1,560 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/token"
+)
+
+type symbolKind uint8
+
+const (
+	globalSymbol symbolKind = iota
+	localSymbol
+	localFunctionSymbol
+	paramSymbol
+	loopVarSymbol
+)
+
+// A symbol is a variable in a Lua chunk.  All global variables with the same
+// name are the same symbol.
+type symbol struct {
+	name   string
+	kind   symbolKind
+	decl   *token.Pos // Declaration (first assignment for globals, may be nil)
+	attrib ast.LocalAttrib
+	fn     *ast.Function // Function definition assigned at declaration, if any
+	refs   []*token.Pos  // All occurrences of the symbol in source order
+}
+
+// An occurrence is the position of a name in the source code together with
+// the symbol it refers to.
+type occurrence struct {
+	pos *token.Pos
+	sym *symbol
+}
+
+// An outlineItem is a named function or a top level local variable, used to
+// provide document symbols.
+type outlineItem struct {
+	name      string
+	kind      symbolKind
+	isFunc    bool
+	loc       ast.Location
+	selection *token.Pos
+	children  []*outlineItem
+}
+
+// An index records all the symbols of a chunk and where they occur.
+type index struct {
+	occurrences []occurrence // Sorted by offset
+	outline     []*outlineItem
+}
+
+// symbolAt returns the symbol whose name covers the given byte offset, and the
+// position of that occurrence.
+func (x *index) symbolAt(offset int) (*symbol, *token.Pos) {
+	i := sort.Search(len(x.occurrences), func(i int) bool {
+		return x.occurrences[i].pos.Offset > offset
+	})
+	if i == 0 {
+		return nil, nil
+	}
+	occ := x.occurrences[i-1]
+	if offset > occ.pos.Offset+len(occ.sym.name) {
+		return nil, nil
+	}
+	return occ.sym, occ.pos
+}
+
+// A lexicalScope maps names to the local symbols declared in a block.
+type lexicalScope map[string]*symbol
+
+// A lexicalContext maintains the nested lexical scopes accessible at a given
+// point in the code.
+type lexicalContext []lexicalScope
+
+func (c lexicalContext) pushNew() lexicalContext {
+	return append(c, lexicalScope{})
+}
+
+func (c lexicalContext) pop() lexicalContext {
+	return c[:len(c)-1]
+}
+
+// get returns the symbol the name refers to in the innermost scope declaring
+// it, or nil if the name is not declared in any scope.
+func (c lexicalContext) get(name string) *symbol {
+	for i := len(c) - 1; i >= 0; i-- {
+		if sym, ok := c[i][name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+// addToTop declares a symbol in the innermost scope.
+func (c lexicalContext) addToTop(sym *symbol) {
+	c[len(c)-1][sym.name] = sym
+}
+
+// A resolver walks the AST of a chunk and associates each name with the symbol
+// it refers to.
+type resolver struct {
+	context lexicalContext
+	globals map[string]*symbol
+	index   *index
+	outline *[]*outlineItem // Where to add new outline items
+	depth   int             // Function nesting depth
+}
+
+var _ ast.StatProcessor = (*resolver)(nil)
+var _ ast.ExpProcessor = (*resolver)(nil)
+
+// resolve builds the index of a chunk.
+func resolve(chunk ast.BlockStat) *index {
+	x := &index{}
+	r := &resolver{
+		globals: map[string]*symbol{},
+		index:   x,
+		outline: &x.outline,
+	}
+	r.block(chunk)
+	sort.SliceStable(x.occurrences, func(i, j int) bool {
+		return x.occurrences[i].pos.Offset < x.occurrences[j].pos.Offset
+	})
+	// Make references appear in source order too
+	for _, occ := range x.occurrences {
+		occ.sym.refs = nil
+	}
+	for _, occ := range x.occurrences {
+		occ.sym.refs = append(occ.sym.refs, occ.pos)
+	}
+	return x
+}
+
+func (r *resolver) occur(sym *symbol, pos *token.Pos) {
+	if pos == nil {
+		return
+	}
+	r.index.occurrences = append(r.index.occurrences, occurrence{pos: pos, sym: sym})
+}
+
+func (r *resolver) declare(name ast.Name, kind symbolKind) *symbol {
+	sym := &symbol{name: name.Val, kind: kind, decl: name.StartPos()}
+	r.context.addToTop(sym)
+	r.occur(sym, sym.decl)
+	return sym
+}
+
+// reference records an occurrence of a name, which refers to a local variable
+// if one is in scope or to a global variable otherwise.
+func (r *resolver) reference(name ast.Name, assigned ast.ExpNode) {
+	sym := r.context.get(name.Val)
+	if sym == nil {
+		sym = r.globals[name.Val]
+		if sym == nil {
+			sym = &symbol{name: name.Val, kind: globalSymbol}
+			r.globals[name.Val] = sym
+		}
+		if assigned != nil && sym.decl == nil {
+			sym.decl = name.StartPos()
+			if f, ok := assigned.(ast.Function); ok {
+				sym.fn = &f
+			}
+		}
+	}
+	r.occur(sym, name.StartPos())
+}
+
+func (r *resolver) addOutline(item *outlineItem) {
+	*r.outline = append(*r.outline, item)
+}
+
+func (r *resolver) exp(e ast.ExpNode) {
+	e.ProcessExp(r)
+}
+
+func (r *resolver) exps(es []ast.ExpNode) {
+	for _, e := range es {
+		r.exp(e)
+	}
+}
+
+func (r *resolver) block(b ast.BlockStat) {
+	r.context = r.context.pushNew()
+	r.blockStats(b)
+	r.context = r.context.pop()
+}
+
+func (r *resolver) blockStats(b ast.BlockStat) {
+	for _, s := range b.Stats {
+		s.ProcessStat(r)
+	}
+	r.exps(b.Return)
+}
+
+// function resolves a function definition.  If item is not nil, outline items
+// found in the body are added to its children.
+func (r *resolver) function(f ast.Function, item *outlineItem) {
+	saved := r.outline
+	if item != nil {
+		r.outline = &item.children
+	}
+	r.depth++
+	r.context = r.context.pushNew()
+	for _, p := range f.Params {
+		r.declare(p, paramSymbol)
+	}
+	r.blockStats(f.Body)
+	r.context = r.context.pop()
+	r.depth--
+	r.outline = saved
+}
+
+//
+// Statements
+//
+
+// ProcessAssignStat resolves an AssignStat.
+func (r *resolver) ProcessAssignStat(s ast.AssignStat) {
+	// Function statements are resolved after their name so that recursive
+	// global functions refer to their own declaration.
+	var funcs []int
+	for i, e := range s.Src {
+		if _, ok := e.(ast.Function); ok && i < len(s.Dest) {
+			funcs = append(funcs, i)
+		} else {
+			r.exp(e)
+		}
+	}
+	for i, v := range s.Dest {
+		var src ast.ExpNode
+		if i < len(s.Src) {
+			src = s.Src[i]
+		}
+		switch x := v.(type) {
+		case ast.Name:
+			r.reference(x, src)
+		default:
+			r.exp(v)
+		}
+	}
+	for _, i := range funcs {
+		f := s.Src[i].(ast.Function)
+		name, selection := funcName(s.Dest[i], f)
+		if name == "" {
+			r.function(f, nil)
+			continue
+		}
+		item := &outlineItem{
+			name:      name,
+			isFunc:    true,
+			loc:       s.Locate(),
+			selection: selection,
+		}
+		if len(s.Dest) > 1 {
+			item.loc = f.Locate()
+		}
+		r.addOutline(item)
+		r.function(f, item)
+	}
+}
+
+// funcName returns the name of a function assigned to v (e.g. "M.f" or "M:f")
+// and the position of its last component, or "" if v is not a dotted name.
+func funcName(v ast.ExpNode, f ast.Function) (string, *token.Pos) {
+	switch x := v.(type) {
+	case ast.Name:
+		return x.Val, x.StartPos()
+	case ast.IndexExp:
+		key, ok := x.Idx.(ast.String)
+		if !ok {
+			return "", nil
+		}
+		prefix := dottedName(x.Coll)
+		if prefix == "" {
+			return "", nil
+		}
+		sep := "."
+		if isMethod(f) {
+			sep = ":"
+		}
+		return prefix + sep + string(key.Val), key.StartPos()
+	}
+	return "", nil
+}
+
+// dottedName returns the name of an expression such as "a.b.c", or "" if e is
+// not of that form.
+func dottedName(e ast.ExpNode) string {
+	switch x := e.(type) {
+	case ast.Name:
+		return x.Val
+	case ast.IndexExp:
+		key, ok := x.Idx.(ast.String)
+		if prefix := dottedName(x.Coll); ok && prefix != "" {
+			return prefix + "." + string(key.Val)
+		}
+	}
+	return ""
+}
+
+// isMethod returns true if the function was defined with the "function a:b()"
+// syntax.
+func isMethod(f ast.Function) bool {
+	return len(f.Params) > 0 && f.Params[0].Val == "self" && f.Params[0].StartPos() == nil
+}
+
+// ProcessBlockStat resolves a BlockStat.
+func (r *resolver) ProcessBlockStat(s ast.BlockStat) {
+	r.block(s)
+}
+
+// ProcessBreakStat resolves a BreakStat.
+func (r *resolver) ProcessBreakStat(s ast.BreakStat) {}
+
+// ProcessCompoundAssignStat resolves a CompoundAssignStat.
+func (r *resolver) ProcessCompoundAssignStat(s ast.CompoundAssignStat) {
+	r.exp(s.Value)
+	r.exp(s.Dest)
+}
+
+// ProcessContinueStat resolves a ContinueStat.
+func (r *resolver) ProcessContinueStat(s ast.ContinueStat) {}
+
+// ProcessEmptyStat resolves an EmptyStat.
+func (r *resolver) ProcessEmptyStat(s ast.EmptyStat) {}
+
+// ProcessForInStat resolves a ForInStat.
+func (r *resolver) ProcessForInStat(s ast.ForInStat) {
+	r.exps(s.Params)
+	r.context = r.context.pushNew()
+	for _, v := range s.Vars {
+		r.declare(v, loopVarSymbol)
+	}
+	r.blockStats(s.Body)
+	r.context = r.context.pop()
+}
+
+// ProcessForStat resolves a ForStat.
+func (r *resolver) ProcessForStat(s ast.ForStat) {
+	r.exp(s.Start)
+	r.exp(s.Stop)
+	r.exp(s.Step)
+	r.context = r.context.pushNew()
+	r.declare(s.Var, loopVarSymbol)
+	r.blockStats(s.Body)
+	r.context = r.context.pop()
+}
+
+// ProcessFunctionCallStat resolves a FunctionCall statement.
+func (r *resolver) ProcessFunctionCallStat(f ast.FunctionCall) {
+	r.ProcessBFunctionCallExp(*f.BFunctionCall)
+}
+
+// ProcessGotoStat resolves a GotoStat.
+func (r *resolver) ProcessGotoStat(s ast.GotoStat) {}
+
+// ProcessIfStat resolves an IfStat.
+func (r *resolver) ProcessIfStat(s ast.IfStat) {
+	r.exp(s.If.Cond)
+	r.block(s.If.Body)
+	for _, c := range s.ElseIfs {
+		r.exp(c.Cond)
+		r.block(c.Body)
+	}
+	if s.Else != nil {
+		r.block(*s.Else)
+	}
+}
+
+// ProcessLabelStat resolves a LabelStat.
+func (r *resolver) ProcessLabelStat(s ast.LabelStat) {}
+
+// ProcessLocalFunctionStat resolves a LocalFunctionStat.
+func (r *resolver) ProcessLocalFunctionStat(s ast.LocalFunctionStat) {
+	// The function name is in scope in its body
+	sym := r.declare(s.Name, localFunctionSymbol)
+	f := s.Function
+	sym.fn = &f
+	item := &outlineItem{
+		name:      s.Name.Val,
+		kind:      localFunctionSymbol,
+		isFunc:    true,
+		loc:       s.Locate(),
+		selection: s.Name.StartPos(),
+	}
+	r.addOutline(item)
+	r.function(f, item)
+}
+
+// ProcessLocalStat resolves a LocalStat.
+func (r *resolver) ProcessLocalStat(s ast.LocalStat) {
+	r.exps(s.Values)
+	for i, na := range s.NameAttribs {
+		sym := r.declare(na.Name, localSymbol)
+		sym.attrib = na.Attrib
+		if i < len(s.Values) {
+			if f, ok := s.Values[i].(ast.Function); ok {
+				sym.fn = &f
+			}
+		}
+		if r.depth == 0 && len(r.context) == 1 {
+			r.addOutline(&outlineItem{
+				name:      na.Name.Val,
+				kind:      localSymbol,
+				isFunc:    sym.fn != nil,
+				loc:       s.Locate(),
+				selection: na.Name.StartPos(),
+			})
+		}
+	}
+}
+
+// ProcessRepeatStat resolves a RepeatStat.
+func (r *resolver) ProcessRepeatStat(s ast.RepeatStat) {
+	// The condition is in the scope of the body
+	r.context = r.context.pushNew()
+	r.blockStats(s.Body)
+	r.exp(s.Cond)
+	r.context = r.context.pop()
+}
+
+// ProcessWhileStat resolves a WhileStat.
+func (r *resolver) ProcessWhileStat(s ast.WhileStat) {
+	r.exp(s.Cond)
+	r.block(s.Body)
+}
+
+//
+// Expressions
+//
+
+// ProcessBFunctionCallExp resolves a BFunctionCall.
+func (r *resolver) ProcessBFunctionCallExp(f ast.BFunctionCall) {
+	r.exp(f.Target)
+	r.exps(f.Args)
+}
+
+// ProcessBinOpExp resolves a BinOp.
+func (r *resolver) ProcessBinOpExp(b ast.BinOp) {
+	r.exp(b.Left)
+	for _, o := range b.Right {
+		r.exp(o.Operand)
+	}
+}
+
+// ProcesBoolExp resolves a Bool.
+func (r *resolver) ProcesBoolExp(b ast.Bool) {}
+
+// ProcessEtcExp resolves an Etc.
+func (r *resolver) ProcessEtcExp(e ast.Etc) {}
+
+// ProcessFunctionExp resolves a Function.
+func (r *resolver) ProcessFunctionExp(f ast.Function) {
+	r.function(f, nil)
+}
+
+// ProcessFunctionCallExp resolves a FunctionCall.
+func (r *resolver) ProcessFunctionCallExp(f ast.FunctionCall) {
+	r.ProcessBFunctionCallExp(*f.BFunctionCall)
+}
+
+// ProcessIndexExp resolves an IndexExp.
+func (r *resolver) ProcessIndexExp(e ast.IndexExp) {
+	r.exp(e.Coll)
+	r.exp(e.Idx)
+}
+
+// ProcessNameExp resolves a Name.
+func (r *resolver) ProcessNameExp(n ast.Name) {
+	r.reference(n, nil)
+}
+
+// ProcessNilExp resolves a Nil.
+func (r *resolver) ProcessNilExp(n ast.Nil) {}
+
+// ProcessIntExp resolves an Int.
+func (r *resolver) ProcessIntExp(n ast.Int) {}
+
+// ProcessFloatExp resolves a Float.
+func (r *resolver) ProcessFloatExp(f ast.Float) {}
+
+// ProcessStringExp resolves a String.
+func (r *resolver) ProcessStringExp(s ast.String) {}
+
+// ProcessInterpolatedStringExp resolves an InterpolatedString.
+func (r *resolver) ProcessInterpolatedStringExp(s ast.InterpolatedString) {
+	r.exps(s.Exps)
+}
+
+// ProcessTableConstructorExp resolves a TableConstructor.
+func (r *resolver) ProcessTableConstructorExp(t ast.TableConstructor) {
+	for _, f := range t.Fields {
+		if _, noKey := f.Key.(ast.NoTableKey); !noKey {
+			r.exp(f.Key)
+		}
+		r.exp(f.Value)
+	}
+}
+
+// ProcessUnOpExp resolves an UnOp.
+func (r *resolver) ProcessUnOpExp(u ast.UnOp) {
+	r.exp(u.Operand)
+}
+
+//
+// Hover
+//
+
+// describe returns a Lua-like description of the symbol, e.g. "local x" or
+// "function f(a, b)".
+func (s *symbol) describe() string {
+	var b strings.Builder
+	switch s.kind {
+	case globalSymbol:
+		if s.fn == nil {
+			b.WriteString("global ")
+		}
+	case localSymbol:
+		b.WriteString("local ")
+	case localFunctionSymbol:
+		b.WriteString("local ")
+	case paramSymbol:
+		b.WriteString("(parameter) ")
+	case loopVarSymbol:
+		b.WriteString("(loop variable) ")
+	}
+	if s.fn != nil {
+		b.WriteString("function ")
+	}
+	b.WriteString(s.name)
+	if s.fn != nil {
+		b.WriteString(signature(*s.fn))
+	}
+	switch s.attrib {
+	case ast.ConstAttrib:
+		b.WriteString(" <const>")
+	case ast.CloseAttrib:
+		b.WriteString(" <close>")
+	}
+	return b.String()
+}
+
+// signature returns the parameter list of a function, e.g. "(a, b, ...)".
+func signature(f ast.Function) string {
+	var params []string
+	for i, p := range f.Params {
+		if i == 0 && isMethod(f) {
+			continue
+		}
+		params = append(params, p.Val)
+	}
+	if f.HasDots {
+		params = append(params, "...")
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// A server implements the language server protocol over a JSON-RPC
+// connection.  Requests are handled sequentially.
+type server struct {
+	in          *bufio.Reader
+	out         io.Writer
+	docs        map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+func newServer(in io.Reader, out io.Writer) *server {
+	return &server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		docs: map[string]*document{},
+	}
+}
+
+// run handles messages until the client sends the "exit" notification or the
+// input is closed, and returns the process exit code.
+func (s *server) run() int {
+	for {
+		req, err := s.read()
+		if err == io.EOF {
+			return 1
+		}
+		if err != nil {
+			if rerr, ok := err.(*responseError); ok {
+				s.reply(nil, nil, rerr)
+				continue
+			}
+			return 1
+		}
+		if req.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		result, rerr := s.handle(req)
+		if req.ID != nil {
+			s.reply(req.ID, result, rerr)
+		}
+	}
+}
+
+// read reads a message with its "Content-Length" header.
+func (s *server) read() (*request, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	req := new(request)
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+	return req, nil
+}
+
+func (s *server) write(msg interface{}) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *server) reply(id *json.RawMessage, result interface{}, err *responseError) {
+	resp := response{JSONRPC: "2.0", ID: id, Error: err}
+	if err == nil {
+		raw, merr := json.Marshal(result)
+		if merr != nil {
+			panic(merr)
+		}
+		resp.Result = raw
+	}
+	s.write(resp)
+}
+
+func (s *server) notify(method string, params interface{}) {
+	s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// handle dispatches a request or notification to the relevant method.  For
+// notifications the returned result is ignored.
+func (s *server) handle(req *request) (interface{}, *responseError) {
+	if !s.initialized && req.Method != "initialize" {
+		if req.ID == nil {
+			return nil, nil
+		}
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+	switch req.Method {
+	case "initialize":
+		s.initialized = true
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				PositionEncoding:       "utf-16",
+				TextDocumentSync:       textDocumentSyncOptions{OpenClose: true, Change: syncFull},
+				DefinitionProvider:     true,
+				ReferencesProvider:     true,
+				HoverProvider:          true,
+				DocumentSymbolProvider: true,
+			},
+			ServerInfo: serverInfo{Name: "golua-lsp"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		// Only full synchronisation is supported, so the last change contains
+		// the whole text.
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []diagnostic{},
+		})
+		return nil, nil
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if d := s.docs[params.TextDocument.URI]; d != nil {
+			return d.definition(params.Position), nil
+		}
+		return nil, nil
+	case "textDocument/references":
+		var params referenceParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if d := s.docs[params.TextDocument.URI]; d != nil {
+			return d.references(params.Position, params.Context.IncludeDeclaration), nil
+		}
+		return nil, nil
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if d := s.docs[params.TextDocument.URI]; d != nil {
+			if h := d.hover(params.Position); h != nil {
+				return h, nil
+			}
+		}
+		return nil, nil
+	case "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if d := s.docs[params.TextDocument.URI]; d != nil {
+			return d.symbols(), nil
+		}
+		return nil, nil
+	default:
+		if req.ID == nil || strings.HasPrefix(req.Method, "$/") {
+			return nil, nil
+		}
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// update analyses the new text of a document and publishes its diagnostics.
+func (s *server) update(uri, text string) {
+	d := newDocument(uri, []byte(text))
+	s.docs[uri] = d
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: d.diags,
+	})
+}
+
+func unmarshalParams(req *request, params interface{}) *responseError {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/textproto"
+	"strconv"
+	"testing"
+)
+
+func encodeMessages(t *testing.T, msgs ...map[string]interface{}) io.Reader {
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n")
+		buf.Write(body)
+	}
+	return &buf
+}
+
+func decodeMessages(t *testing.T, r io.Reader) []map[string]interface{} {
+	var msgs []map[string]interface{}
+	br := bufio.NewReader(r)
+	for {
+		header, err := textproto.NewReader(br).ReadMIMEHeader()
+		if err == io.EOF {
+			return msgs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := make([]byte, n)
+		if _, err := io.ReadFull(br, body); err != nil {
+			t.Fatal(err)
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+type obj = map[string]interface{}
+
+func TestServerSession(t *testing.T) {
+	doc := obj{"uri": "file:///test.lua"}
+	in := encodeMessages(t,
+		obj{"id": 0, "method": "textDocument/hover", "params": obj{}},
+		obj{"id": 1, "method": "initialize", "params": obj{}},
+		obj{"method": "initialized", "params": obj{}},
+		obj{"method": "textDocument/didOpen", "params": obj{
+			"textDocument": obj{"uri": "file:///test.lua", "languageId": "lua", "version": 1, "text": "local x = 1\nprint(x)"},
+		}},
+		obj{"id": 2, "method": "textDocument/definition", "params": obj{
+			"textDocument": doc,
+			"position":     obj{"line": 1, "character": 6},
+		}},
+		obj{"method": "textDocument/didChange", "params": obj{
+			"textDocument":   obj{"uri": "file:///test.lua", "version": 2},
+			"contentChanges": []obj{{"text": "local x = ="}},
+		}},
+		obj{"id": 3, "method": "unknown/method"},
+		obj{"id": 4, "method": "shutdown"},
+		obj{"method": "exit"},
+	)
+	var out bytes.Buffer
+	if code := newServer(in, &out).run(); code != 0 {
+		t.Errorf("exit code %d, want 0", code)
+	}
+	msgs := decodeMessages(t, &out)
+
+	var got []string
+	for _, msg := range msgs {
+		switch {
+		case msg["error"] != nil:
+			got = append(got, "error "+strconv.Itoa(int(msg["error"].(obj)["code"].(float64))))
+		case msg["method"] != nil:
+			diags := msg["params"].(obj)["diagnostics"].([]interface{})
+			got = append(got, msg["method"].(string)+" "+strconv.Itoa(len(diags)))
+		default:
+			got = append(got, "result "+strconv.Itoa(int(msg["id"].(float64))))
+		}
+	}
+	want := []string{
+		"error -32002",
+		"result 1",
+		"textDocument/publishDiagnostics 0",
+		"result 2",
+		"textDocument/publishDiagnostics 1",
+		"error -32601",
+		"result 4",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got messages %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	def := msgs[3]["result"].([]interface{})
+	if len(def) != 1 {
+		t.Fatalf("got definition %v", def)
+	}
+	start := def[0].(obj)["range"].(obj)["start"].(obj)
+	if start["line"] != 0.0 || start["character"] != 6.0 {
+		t.Errorf("got definition start %v", start)
+	}
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	in := encodeMessages(t, obj{"method": "exit"})
+	if code := newServer(in, io.Discard).run(); code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+}
@@ -20,6 +20,16 @@ type Code struct {
 	CellCount              int16    // Number of cell registers needed to run the code
 	RegCount               int16    // Number of registers needed to run the coee
 	UpNames                []string // Names of the upvalues
+	InlineFrames           []InlineFrame
+}
+
+// An InlineFrame is a call to a function whose body was compiled in place of
+// the call.  Inline frames are numbered from 1 in the order of
+// Code.InlineFrames, 0 standing for the function itself.
+type InlineFrame struct {
+	Name         string // Name of the inlined function
+	Line, Column int32  // Position of the call
+	Parent       int16  // Inline frame the call is made from
 }
 
 var _ Constant = Code{}
@@ -76,6 +76,12 @@ func LoadEmptyTable(r Reg) Opcode {
 	return mkType4b(Off, OpTable, r, 0)
 }
 
+// LoadTable encodes r <- {}, with hints for the sizes of the array and hash
+// parts of the table.
+func LoadTable(r Reg, narr, nhash int) Opcode {
+	return mkType4b(Off, OpTable, r, Lit8FromTableSize(narr, nhash))
+}
+
 // LoadNil encodes r <- nil
 func LoadNil(r Reg) Opcode {
 	return mkType4b(Off, OpNil, r, 0)
@@ -232,3 +238,35 @@ func PrepForLoop(rStart, rStop, rStep Reg) Opcode {
 func AdvForLoop(rStart, rStop, rStep Reg) Opcode {
 	return mkType7(On, rStart, rStop, rStep)
 }
+
+// AdvForLoopInt is a version of AdvForLoop where the step is a small integer
+// constant.
+func AdvForLoopInt(rStart, rStop Reg, step int8) Opcode {
+	return mkType6b(Off, rStart, rStop, Index8FromInt8(step))
+}
+
+// AddInt8 encodes r1 <- r2 + n
+func AddInt8(r1, r2 Reg, n int8) Opcode {
+	return mkType8(Off, OpAddInt8, r1, r2, Index8FromInt8(n))
+}
+
+// SubInt8 encodes r1 <- r2 - n
+func SubInt8(r1, r2 Reg, n int8) Opcode {
+	return mkType8(On, OpAddInt8, r1, r2, Index8FromInt8(n))
+}
+
+// LoadLookupK encodes r1 <- r2[Ki]
+//
+// Ki must be a string and i must be less than 256.  The runtime caches the
+// location of the key in the table for fast lookups.
+func LoadLookupK(r1, r2 Reg, i KIndex) Opcode {
+	return mkType8(Off, OpIndexK, r1, r2, Index8FromInt(int(i)))
+}
+
+// SetIndexK encodes r2[Ki] <- r1
+//
+// Ki must be a string and i must be less than 256.  The runtime caches the
+// location of the key in the table for fast updates.
+func SetIndexK(r1, r2 Reg, i KIndex) Opcode {
+	return mkType8(On, OpIndexK, r1, r2, Index8FromInt(int(i)))
+}
@@ -4,16 +4,16 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"math/bits"
 )
 
 // Opcode is the type of opcodes
 type Opcode uint32
 
-// There are 7 types of opcodes (Typ0 - Type7).  The type of opcode is defined
+// There are 9 types of opcodes (Typ0 - Type8).  The type of opcode is defined
 // by the most significant 4 bits of the opcode.
 
-// Prefixes for the different types of opcodes.  Note: there is an unused prefix
-// (0001).
+// Prefixes for the different types of opcodes.
 const (
 	Type1Pfx Opcode = 1 << 31 // 1......
 	Type2Pfx Opcode = 7 << 28 // 0111...
@@ -22,9 +22,11 @@ const (
 	Type5Pfx Opcode = 4 << 28 // 0100...
 	Type6Pfx Opcode = 3 << 28 // 0011...
 	Type7Pfx Opcode = 2 << 28 // 0010...
+	Type8Pfx Opcode = 1 << 28 // 0001...
 	Type0Pfx Opcode = 0 << 28 // 0000...
 
 	type4aFlag Opcode = 1 << 24
+	type6bFlag Opcode = 1 << 24
 )
 
 // TypePfx returns the type prefix of the opcode. (valid for all types apart
@@ -43,6 +45,11 @@ func (c Opcode) HasType4a() bool {
 	return c&type4aFlag != 0
 }
 
+// HasType6b returns true if the opcode is Type6b, assuming that it is Type6.
+func (c Opcode) HasType6b() bool {
+	return c&type6bFlag != 0
+}
+
 // HasType0 returns true if the opcdoe is Type0.
 func (c Opcode) HasType0() bool {
 	return c&(0xf<<28) == 0
@@ -314,6 +321,36 @@ func Lit8FromBool(b bool) Lit8 {
 	return 0
 }
 
+// Lit8FromTableSize encodes size hints for the array part and the hash part of
+// a table.  Each size is rounded up to a power of 2 and capped at 2^14.
+func Lit8FromTableSize(narr, nhash int) Lit8 {
+	return Lit8(tableSizeNibble(narr)<<4 | tableSizeNibble(nhash))
+}
+
+// ToTableSize decodes the size hints for the array part and the hash part of a
+// table.
+func (l Lit8) ToTableSize() (narr, nhash int) {
+	return nibbleTableSize(uint8(l) >> 4), nibbleTableSize(uint8(l) & 0xf)
+}
+
+func tableSizeNibble(n int) uint8 {
+	if n <= 0 {
+		return 0
+	}
+	l := bits.Len(uint(n-1)) + 1
+	if l > 15 {
+		l = 15
+	}
+	return uint8(l)
+}
+
+func nibbleTableSize(b uint8) int {
+	if b == 0 {
+		return 0
+	}
+	return 1 << (b - 1)
+}
+
 // GetL decodes the L field of the opcode.
 func (c Opcode) GetL() Lit8 {
 	return Lit8(c >> 8)
@@ -392,7 +429,7 @@ func mkType5(f Flag, op JumpOp, rA Reg, k encoderToD) Opcode {
 }
 
 // ==================================================================
-// Type6:  0011Fab0 AAAAAAAA BBBBBBBB MMMMMMMM
+// Type6a: 0011Fab0 AAAAAAAA BBBBBBBB MMMMMMMM
 //
 // Load from etc
 
@@ -422,6 +459,26 @@ func mkType6(f Flag, rA, rB Reg, i Index8) Opcode {
 	return Type6Pfx | f.encodeF() | rA.toA() | rB.toB() | i.encodeM()
 }
 
+// ==================================================================
+// Type6b: 0011Fab1 AAAAAAAA BBBBBBBB MMMMMMMM
+//
+// Advance a numeric for loop whose step is a small integer constant.  rA is the
+// loop variable, rB the limit and M encodes the step as an int8 (F is Off).
+
+func mkType6b(f Flag, rA, rB Reg, i Index8) Opcode {
+	return Type6Pfx | type6bFlag | f.encodeF() | rA.toA() | rB.toB() | i.encodeM()
+}
+
+// ToInt8 converts i to an int8.
+func (i Index8) ToInt8() int8 {
+	return int8(i)
+}
+
+// Index8FromInt8 encodes an int8 into an Index8.
+func Index8FromInt8(n int8) Index8 {
+	return Index8(n)
+}
+
 // ==================================================================
 // Type7:  0010Fabc AAAAAAAA BBBBBBBB CCCCCCCC
 //
@@ -431,6 +488,41 @@ func mkType7(f Flag, rA, rB, rC Reg) Opcode {
 	return Type7Pfx | f.encodeF() | rA.toA() | rB.toB() | rC.toC()
 }
 
+// ==================================================================
+// Type8:  0001FabY AAAAAAAA BBBBBBBB MMMMMMMM
+//
+// Specialized versions of Type1 and Type2 opcodes where the right operand is a
+// constant encoded in M.  They are emitted for common patterns and have fast
+// paths in the runtime.
+
+// SpecOp is the type of operators available in Type8 opcodes.
+type SpecOp uint8
+
+// Available specialized operators
+const (
+	OpAddInt8 SpecOp = iota // rA <- rB + M (or rB - M if F is On), M an int8
+	OpIndexK                // rA <- rB[KM] (or rB[KM] <- rA if F is On), KM a string
+)
+
+// encodeY8 encodes a SpecOp into an opcode.
+func (op SpecOp) encodeY8() Opcode {
+	return Opcode(op) << 24
+}
+
+// GetSpecOp decodes the SpecOp from the opcode.
+func (c Opcode) GetSpecOp() SpecOp {
+	return SpecOp((c >> 24) & 1)
+}
+
+// SetM returns a copy of the opcode with a new M.
+func (c Opcode) SetM(i Index8) Opcode {
+	return c&0xffffff00 | i.encodeM()
+}
+
+func mkType8(f Flag, op SpecOp, rA, rB Reg, i Index8) Opcode {
+	return Type8Pfx | f.encodeF() | op.encodeY8() | rA.toA() | rB.toB() | i.encodeM()
+}
+
 // ==================================================================
 // Type0:  0000Fabc AAAAAAAA BBBBBBBB CCCCCCCC
 //
@@ -596,6 +688,9 @@ func (c Opcode) Disassemble(d OpcodeDisassembler, i int) string {
 			k = "CC"
 		case OpTable:
 			k = "{}"
+			if narr, nhash := c.GetL().ToTableSize(); narr != 0 || nhash != 0 {
+				k = fmt.Sprintf("{}[%d, %d]", narr, nhash)
+			}
 		case OpStr0:
 			k = `""`
 		case OpStr1:
@@ -674,6 +769,9 @@ func (c Opcode) Disassemble(d OpcodeDisassembler, i int) string {
 		rB := c.GetB()
 		f := c.GetF()
 		m := c.GetM()
+		if c.HasType6b() {
+			return fmt.Sprintf("advfor %s, %s, %d", rA, rB, m.ToInt8())
+		}
 		if f {
 			return fmt.Sprintf("fill %s, %d, %s", rA, m, rB)
 		}
@@ -685,6 +783,25 @@ func (c Opcode) Disassemble(d OpcodeDisassembler, i int) string {
 			action = "adv"
 		}
 		return fmt.Sprintf("%sfor %s, %s, %s", action, rStart, rStop, rStep)
+	case Type8Pfx:
+		rA := c.GetA()
+		rB := c.GetB()
+		f := c.GetF()
+		m := c.GetM()
+		switch c.GetSpecOp() {
+		case OpAddInt8:
+			if f {
+				return fmt.Sprintf("%s <- %s - %d", rA, rB, m.ToInt8())
+			}
+			return fmt.Sprintf("%s <- %s + %d", rA, rB, m.ToInt8())
+		case OpIndexK:
+			k := fmt.Sprintf("K%d (%s)", m, d.ShortKString(KIndex(m)))
+			if f {
+				return fmt.Sprintf("%s[%s] <- %s", rB, k, rA)
+			}
+			return fmt.Sprintf("%s <- %s[%s]", rA, rB, k)
+		}
+		return "???"
 	default:
 		return "???"
 	}
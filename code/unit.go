@@ -10,6 +10,8 @@ type Unit struct {
 	Source    string     // Shows were the unit comes from (e.g. a filename) - only for information.
 	Code      []Opcode   // The code
 	Lines     []int32    // Optional: source code line for the corresponding opcode
+	Columns   []int32    // Optional: source code column for the corresponding opcode
+	Inlined   []int16    // Optional: inline frame of the corresponding opcode (see Code.InlineFrames)
 	Constants []Constant // All the constants required for running the code
 }
 
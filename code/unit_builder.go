@@ -8,6 +8,10 @@ type Label uint
 type Builder struct {
 	source    string          // identifies the source of the code
 	lines     []int32         // lines in the source code corresponding to the opcodes
+	columns   []int32         // columns in the source code corresponding to the opcodes
+	inlined   []int16         // inline frames corresponding to the opcodes
+	hasInline bool            // true if some opcodes are in an inline frame
+	frame     int16           // inline frame of the opcodes being emitted
 	code      []Opcode        // opcodes emitted
 	jumpTo    map[Label]int   // destination locations for the labels
 	jumpFrom  map[Label][]int // lists of locations for opcode that jump to a given label
@@ -23,16 +27,25 @@ func NewBuilder(source string) *Builder {
 	}
 }
 
-// Emit adds an opcode (associating it with a source code line).
-func (c *Builder) Emit(opcode Opcode, line int) {
+// Emit adds an opcode (associating it with a source code line and column).
+func (c *Builder) Emit(opcode Opcode, line, column int) {
 	c.code = append(c.code, opcode)
 	c.lines = append(c.lines, int32(line))
+	c.columns = append(c.columns, int32(column))
+	c.inlined = append(c.inlined, c.frame)
+}
+
+// SetInlineFrame sets the inline frame of the opcodes emitted from now on (see
+// Code.InlineFrames).
+func (c *Builder) SetInlineFrame(frame int) {
+	c.frame = int16(frame)
+	c.hasInline = c.hasInline || frame != 0
 }
 
 // EmitJump adds a jump opcode, jumping to the given label.  The offset part of
 // the opcode must be left as 0, it will be filled by the builder when the
 // location of the label is known.
-func (c *Builder) EmitJump(opcode Opcode, lbl Label, line int) {
+func (c *Builder) EmitJump(opcode Opcode, lbl Label, line, column int) {
 	jumpToAddr, ok := c.jumpTo[lbl]
 	addr := len(c.code)
 	if ok {
@@ -40,7 +53,7 @@ func (c *Builder) EmitJump(opcode Opcode, lbl Label, line int) {
 	} else {
 		c.jumpFrom[lbl] = append(c.jumpFrom[lbl], addr)
 	}
-	c.Emit(opcode, line)
+	c.Emit(opcode, line, column)
 }
 
 // EmitLabel adds a label for the current location.  It panics if called twice
@@ -74,10 +87,16 @@ func (c *Builder) AddConstant(k Constant) {
 
 // GetUnit returns the build code Unit.
 func (c *Builder) GetUnit() *Unit {
+	var inlined []int16
+	if c.hasInline {
+		inlined = c.inlined
+	}
 	return &Unit{
 		Source:    c.source,
 		Code:      c.code,
 		Lines:     c.lines,
+		Columns:   c.columns,
+		Inlined:   inlined,
 		Constants: c.constants,
 	}
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/arnodel/golua/luafmt"
+)
+
+func init() {
+	subcommands["fmt"] = fmtMain
+}
+
+// fmtMain implements "golua fmt [-l] [-w] [files...]".  Without files it
+// formats stdin to stdout.
+func fmtMain(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	list := fs.Bool("l", false, "list files whose formatting differs")
+	write := fs.Bool("w", false, "write result to the source file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: golua fmt [options] [file...]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fatal("Error reading <stdin>: %s", err)
+		}
+		out, err := luafmt.Source("<stdin>", src)
+		if err != nil {
+			return fatal("<stdin>:%s", err)
+		}
+		os.Stdout.Write(out)
+		return 0
+	}
+	retcode := 0
+	for _, fname := range fs.Args() {
+		src, err := ioutil.ReadFile(fname)
+		if err != nil {
+			return fatal("Error reading '%s': %s", fname, err)
+		}
+		out, err := luafmt.Source(fname, src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s:%s\n", fname, err)
+			retcode = 1
+			continue
+		}
+		changed := !bytes.Equal(src, out)
+		if *list && changed {
+			fmt.Println(fname)
+		}
+		if *write {
+			if changed {
+				fi, err := os.Stat(fname)
+				if err != nil {
+					return fatal("Error writing '%s': %s", fname, err)
+				}
+				if err := ioutil.WriteFile(fname, out, fi.Mode().Perm()); err != nil {
+					return fatal("Error writing '%s': %s", fname, err)
+				}
+			}
+		} else if !*list {
+			os.Stdout.Write(out)
+		}
+	}
+	return retcode
+}
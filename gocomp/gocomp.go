@@ -0,0 +1,956 @@
+// Package gocomp compiles Lua code units to Go source code.
+//
+// Each function in a code unit is compiled to a Go function returning a
+// *runtime.GoFunction.  Registers become local variables of that function,
+// jumps become gotos and calls are made by pushing arguments to continuations
+// like the interpreter does, so compiled functions can call and be called by
+// interpreted or Go functions (tail calls and coroutines work as expected).
+// A function making a call returns the continuation of the callee to the
+// runtime and is resumed by a runtime.Resumption receiving the results, so
+// calls do not nest Go calls and recursion is not limited by the Go stack.
+//
+// Compiled functions behave like their interpreted versions.  They record the
+// line they are executing (see runtime.GoCont.SetLine), so errors, tracebacks,
+// the debug library and debug hooks report them as Lua functions.
+//
+// A typical use is to generate a Go file from a Lua script, e.g.
+//
+//	golua -go -gopkg scripts -gofunc Init init.lua > init_lua.go
+//
+// and then run it with
+//
+//	rt.Call(t, rt.FunctionValue(scripts.Init(rt.TableValue(r.GlobalEnv()))), args, next)
+package gocomp
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/arnodel/golua/code"
+)
+
+// Options control the Go code generated from a code unit.
+type Options struct {
+	Package  string // Name of the package of the generated Go file
+	FuncName string // Name of the exported function returning the main chunk
+}
+
+// Compile writes Go source code implementing the functions in unit to w.  The
+// generated file declares an exported function called opts.FuncName taking the
+// value of _ENV and returning the main chunk as a *runtime.GoFunction.  If some
+// functions in the unit cannot be compiled (e.g. because they use
+// unsupported features), an error is returned and nothing is written to w.
+func Compile(w io.Writer, unit *code.Unit, opts Options) error {
+	if opts.Package == "" {
+		opts.Package = "main"
+	}
+	if opts.FuncName == "" {
+		opts.FuncName = "Chunk"
+	}
+	if len(unit.Constants) == 0 {
+		return fmt.Errorf("no main chunk in unit")
+	}
+	main, ok := unit.Constants[0].(code.Code)
+	if !ok {
+		return fmt.Errorf("no main chunk in unit")
+	}
+	g := &generator{
+		unit:    unit,
+		prefix:  lowerFirst(opts.FuncName),
+		imports: map[string]bool{},
+	}
+	var funcs bytes.Buffer
+	for i, k := range unit.Constants {
+		if c, ok := k.(code.Code); ok {
+			if err := g.compileFunc(&funcs, i, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by golua from %s. DO NOT EDIT.\n\n", unit.Source)
+	fmt.Fprintf(&out, "package %s\n\n", opts.Package)
+	out.WriteString("import (\n")
+	for _, imp := range []string{"math"} {
+		if g.imports[imp] {
+			fmt.Fprintf(&out, "%q\n", imp)
+		}
+	}
+	out.WriteString("\n")
+	if g.imports["code"] {
+		out.WriteString("\"github.com/arnodel/golua/code\"\n")
+	}
+	out.WriteString("rt \"github.com/arnodel/golua/runtime\"\n)\n\n")
+
+	fmt.Fprintf(&out, "// %s returns the main chunk of %s compiled to Go, with env as the value of\n", opts.FuncName, unit.Source)
+	fmt.Fprintf(&out, "// _ENV.\n")
+	fmt.Fprintf(&out, "func %s(env rt.Value) *rt.GoFunction {\n", opts.FuncName)
+	ups := make([]string, main.UpvalueCount)
+	for i := range ups {
+		if i == 0 {
+			ups[i] = "&env"
+		} else {
+			ups[i] = "new(rt.Value)"
+		}
+	}
+	fmt.Fprintf(&out, "return %s([]*rt.Value{%s})\n}\n\n", g.funcName(0), strings.Join(ups, ", "))
+
+	fmt.Fprintf(&out, "const %sSource = %q\n\n", g.prefix, unit.Source)
+	fmt.Fprintf(&out, `// %[1]sEtc returns the value at index i in etc (or nil).
+func %[1]sEtc(etc rt.Value, i int) rt.Value {
+	if vals := etc.AsArray(); i < len(vals) {
+		return vals[i]
+	}
+	return rt.NilValue
+}
+
+`, g.prefix)
+	funcs.WriteTo(&out)
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return fmt.Errorf("error formatting generated code: %s", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generator struct {
+	unit    *code.Unit
+	prefix  string          // Prefix of unexported names in the generated file
+	imports map[string]bool // Imports needed by the generated code
+}
+
+func (g *generator) funcName(k int) string {
+	return fmt.Sprintf("%sF%d", g.prefix, k)
+}
+
+// compileFunc writes a Go function returning a *GoFunction implementing c
+// (which is the kth constant in the unit).
+func (g *generator) compileFunc(w io.Writer, k int, c code.Code) error {
+	fc := &funcCompiler{
+		generator: g,
+		k:         k,
+		code:      c,
+		opcodes:   g.unit.Code[c.StartOffset:c.EndOffset],
+		regs:      map[uint8]bool{},
+		cells:     map[uint8]bool{},
+	}
+	if g.unit.Lines != nil {
+		fc.lines = g.unit.Lines[c.StartOffset:c.EndOffset]
+	}
+	if err := fc.compile(); err != nil {
+		name := c.Name
+		if name == "" {
+			name = "<anon>"
+		}
+		return fmt.Errorf("function %s: %s", name, err)
+	}
+
+	name := g.funcName(k)
+	fmt.Fprintf(w, "// %s implements %s.\n", name, c.ShortString())
+	fmt.Fprintf(w, "func %s(ups []*rt.Value) *rt.GoFunction {\n", name)
+	fmt.Fprintf(w, "f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {\n")
+	if len(fc.resumes) == 0 {
+		fc.writeDecls(w)
+		fc.body.WriteTo(w)
+	} else {
+		fmt.Fprintf(w, "return %sRun(t, c, ups, nil, 0)\n", name)
+	}
+	fmt.Fprintf(w, "}, %q, %d, %t)\n", c.Name, fc.nArgs, fc.hasEtc)
+	fmt.Fprintf(w, "f.SetLuaSource(%sSource)\n", g.prefix)
+	fmt.Fprintf(w, "rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)\n")
+	fmt.Fprintf(w, "return f\n}\n\n")
+	if len(fc.resumes) > 0 {
+		fc.writeResumable(w, name)
+	}
+	return nil
+}
+
+// writeResumable writes the function running the body of a function which
+// makes calls.  The function is suspended by each call and resumed by the
+// Resumption receiving its results, with its variables saved in a state
+// struct in the meantime.
+func (fc *funcCompiler) writeResumable(w io.Writer, name string) {
+	locals := fc.locals()
+	fmt.Fprintf(w, "// %sState holds the variables of %s while it is suspended by a call.\n", name, name)
+	fmt.Fprintf(w, "type %sState struct {\n", name)
+	fmt.Fprintf(w, "c *rt.GoCont\n")
+	var saved []string
+	for _, l := range locals {
+		if l.saved {
+			fmt.Fprintf(w, "%s %s\n", l.name, l.typ)
+			saved = append(saved, l.name)
+		}
+	}
+	fmt.Fprintf(w, "}\n\n")
+
+	fmt.Fprintf(w, "// Resume implements rt.Resumable.\n")
+	fmt.Fprintf(w, "func (s *%sState) Resume(t *rt.Thread, at int) (rt.Cont, error) {\n", name)
+	fmt.Fprintf(w, "return %sRun(t, s.c, nil, s, at)\n}\n\n", name)
+
+	fmt.Fprintf(w, "// %sRun runs %s from the start if s is nil, otherwise it resumes it at\n", name, name)
+	fmt.Fprintf(w, "// opcode at with the variables saved in s.\n")
+	fmt.Fprintf(w, "func %sRun(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *%sState, at int) (rt.Cont, error) {\n", name, name)
+	for _, l := range locals {
+		fmt.Fprintf(w, "var %s %s\n", l.name, l.typ)
+	}
+	fmt.Fprintf(w, "if s == nil {\n")
+	for _, l := range locals {
+		if l.init != "" {
+			fmt.Fprintf(w, "%s = %s\n", l.name, l.init)
+		}
+	}
+	fmt.Fprintf(w, "} else {\n")
+	fmt.Fprintf(w, "%s = s.%s\n", strings.Join(saved, ", "), strings.Join(saved, ", s."))
+	fmt.Fprintf(w, "switch at {\n")
+	for _, pc := range fc.resumes {
+		fmt.Fprintf(w, "case %d:\ngoto L%d\n", pc, pc)
+	}
+	fmt.Fprintf(w, "}\n}\n")
+	fc.body.WriteTo(w)
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// A funcCompiler compiles the opcodes of a single function.
+type funcCompiler struct {
+	*generator
+	k       int // Index of the function in the constants of the unit
+	code    code.Code
+	opcodes []code.Opcode
+	lines   []int32
+	labels  []bool // Opcodes which need a label (see jumps and resumes)
+	jumps   []bool // Opcodes which are the target of a reachable jump
+	resumes []int  // Opcodes following a reachable call, in order
+	body    bytes.Buffer
+
+	// The code is generated twice: the first pass finds which registers are
+	// read, so that the second pass does not emit code declaring variables
+	// which are not used (that would not compile).
+	secondPass bool
+
+	closes bool // True if the function uses to-be-closed variables
+	nArgs  int  // Number of fixed arguments of the function
+	hasEtc bool // True if the function is variadic
+
+	// Values are received either as arguments of the function (recvTerm < 0)
+	// or as results of the call whose termination is k<recvTerm>.
+	recvTerm int
+	recvIdx  int
+
+	lastLine int32 // Line of the previous opcode (0 after a label)
+
+	// Local variables of the generated function
+	regs   map[uint8]bool   // Registers read
+	cells  map[uint8]bool   // Cells used
+	terms  map[uint8]bool   // Terminations for calls, by register
+	upvals map[code.Reg]int // Number of upvalues set for closures, by register
+	vars   map[string]bool  // Other variables used
+	saved  []string         // Variables to save when suspended by a call
+}
+
+func (fc *funcCompiler) compile() error {
+	reachable, err := fc.findReachable()
+	if err != nil {
+		return err
+	}
+	for _, opcode := range fc.opcodes {
+		if !opcode.HasType1() && opcode.TypePfx() == code.Type5Pfx && opcode.GetJ() == code.OpClStack {
+			fc.closes = true
+		}
+	}
+	// Arguments are received at the start of the function
+	fc.nArgs, fc.hasEtc = fc.recvCount(0)
+	if err := fc.emit(reachable); err != nil {
+		return err
+	}
+	fc.secondPass = true
+	fc.body.Reset()
+	fc.saved = nil
+	for _, l := range fc.locals() {
+		if l.saved {
+			fc.saved = append(fc.saved, l.name)
+		}
+	}
+	return fc.emit(reachable)
+}
+
+func (fc *funcCompiler) emit(reachable []bool) error {
+	fc.terms = map[uint8]bool{}
+	fc.upvals = map[code.Reg]int{}
+	fc.vars = map[string]bool{}
+	fc.recvTerm, fc.recvIdx = -1, 0
+	fc.lastLine = 0
+	for pc, opcode := range fc.opcodes {
+		if !reachable[pc] {
+			continue
+		}
+		if fc.labels[pc] {
+			fmt.Fprintf(&fc.body, "L%d:\n", pc)
+		}
+		if fc.jumps[pc] {
+			// It can be reached from another line
+			fc.lastLine = 0
+		}
+		if pc == 0 || fc.labels[pc] {
+			fc.printf("t.RequireCPU(%d)", fc.blockLength(pc))
+		}
+		fc.setLine(pc)
+		if err := fc.compileOpcode(pc, opcode); err != nil {
+			return fmt.Errorf("opcode %d: %s", pc, err)
+		}
+	}
+	return nil
+}
+
+// findReachable returns which opcodes are reachable and sets fc.labels,
+// fc.jumps and fc.resumes.
+func (fc *funcCompiler) findReachable() ([]bool, error) {
+	n := len(fc.opcodes)
+	reachable := make([]bool, n)
+	fc.labels = make([]bool, n)
+	todo := []int{0}
+	for len(todo) > 0 {
+		pc := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		if pc < 0 || pc >= n {
+			return nil, fmt.Errorf("jump out of function")
+		}
+		if reachable[pc] {
+			continue
+		}
+		reachable[pc] = true
+		opcode := fc.opcodes[pc]
+		if jumpTo, ok := jumpTarget(pc, opcode); ok {
+			if jumpTo < 0 || jumpTo >= n {
+				return nil, fmt.Errorf("jump out of function")
+			}
+			fc.labels[jumpTo] = true
+			todo = append(todo, jumpTo)
+		}
+		if !isTerminator(opcode) {
+			todo = append(todo, pc+1)
+		}
+	}
+	fc.jumps = append([]bool(nil), fc.labels...)
+	fc.resumes = nil
+	for pc, opcode := range fc.opcodes {
+		if reachable[pc] && isCall(opcode) && !isTailCall(opcode) {
+			if pc+1 == n {
+				return nil, fmt.Errorf("call at the end of function")
+			}
+			// The function is resumed after the call
+			fc.labels[pc+1] = true
+			fc.resumes = append(fc.resumes, pc+1)
+		}
+	}
+	return reachable, nil
+}
+
+// blockLength returns the number of opcodes in the basic block starting at pc,
+// which is the CPU required to run it.
+func (fc *funcCompiler) blockLength(pc int) int {
+	n := 0
+	for pc < len(fc.opcodes) {
+		n++
+		if isTerminator(fc.opcodes[pc]) {
+			break
+		}
+		pc++
+		if pc < len(fc.opcodes) && fc.labels[pc] {
+			break
+		}
+	}
+	return n
+}
+
+func jumpTarget(pc int, opcode code.Opcode) (int, bool) {
+	if opcode.HasType1() || opcode.TypePfx() != code.Type5Pfx {
+		return 0, false
+	}
+	switch opcode.GetJ() {
+	case code.OpJump, code.OpJumpIf:
+		return pc + int(opcode.GetOffset()), true
+	}
+	return 0, false
+}
+
+func isCall(opcode code.Opcode) bool {
+	return !opcode.HasType1() && opcode.TypePfx() == code.Type5Pfx && opcode.GetJ() == code.OpCall
+}
+
+func isTerminator(opcode code.Opcode) bool {
+	if opcode.HasType1() || opcode.TypePfx() != code.Type5Pfx {
+		return false
+	}
+	switch opcode.GetJ() {
+	case code.OpJump:
+		return true
+	case code.OpCall:
+		return isTailCall(opcode)
+	}
+	return false
+}
+
+// recvCount returns the number of values received by the opcodes starting at
+// pc, and whether the remaining values are received too.
+func (fc *funcCompiler) recvCount(pc int) (n int, etc bool) {
+	for ; pc < len(fc.opcodes); pc++ {
+		opcode := fc.opcodes[pc]
+		if opcode.HasType1() || opcode.TypePfx() != code.Type0Pfx {
+			break
+		}
+		if opcode.GetF() {
+			return n, true
+		}
+		n++
+	}
+	return n, false
+}
+
+// callPC returns the position of the call to the continuation created in reg
+// at pc.
+func (fc *funcCompiler) callPC(pc int, reg code.Reg) int {
+	for pc++; pc < len(fc.opcodes); pc++ {
+		opcode := fc.opcodes[pc]
+		if isCall(opcode) && opcode.GetA() == reg {
+			return pc
+		}
+	}
+	return pc
+}
+
+func isTailCall(opcode code.Opcode) bool {
+	return opcode.GetF() || opcode.GetA() == code.ValueReg(0)
+}
+
+// A local is a variable of a compiled function.
+type local struct {
+	name, typ string
+	init      string // Initial value (if any)
+	saved     bool   // True if it must be saved when the function is suspended
+}
+
+// locals returns the variables used by the compiled function, which writeDecls
+// declares.
+func (fc *funcCompiler) locals() []local {
+	var locals []local
+	if fc.regs[0] {
+		locals = append(locals, local{"r0", "rt.Value", "rt.ContValue(c.Next())", true})
+	}
+	for i := 0; i < int(fc.code.CellCount); i++ {
+		if !fc.cells[uint8(i)] {
+			continue
+		}
+		init := "new(rt.Value)"
+		if i < int(fc.code.UpvalueCount) {
+			init = fmt.Sprintf("ups[%d]", i)
+		}
+		locals = append(locals, local{fmt.Sprintf("c%d", i), "*rt.Value", init, true})
+	}
+	if fc.closes {
+		locals = append(locals, local{"clBase", "int", "t.CloseStackHeight()", true})
+	}
+	for i := 1; i < int(fc.code.RegCount); i++ {
+		if fc.regs[uint8(i)] {
+			locals = append(locals, local{fmt.Sprintf("r%d", i), "rt.Value", "", true})
+		}
+	}
+	for i := 0; i < 256; i++ {
+		if fc.terms[uint8(i)] {
+			locals = append(locals, local{fmt.Sprintf("k%d", i), "*rt.Resumption", "", true})
+		}
+	}
+	var upvals []string
+	for r := range fc.upvals {
+		upvals = append(upvals, upvalsVar(r))
+	}
+	sort.Strings(upvals)
+	for _, u := range upvals {
+		locals = append(locals, local{u, "[]*rt.Value", "", true})
+	}
+	if fc.vars["tmp"] {
+		locals = append(locals, local{"tmp", "rt.Value", "", false})
+	}
+	if fc.vars["cont"] {
+		locals = append(locals, local{"cont", "rt.Cont", "", false})
+	}
+	if fc.vars["err"] {
+		locals = append(locals, local{"err", "error", "", false})
+	}
+	return locals
+}
+
+func (fc *funcCompiler) writeDecls(w io.Writer) {
+	locals := fc.locals()
+	for i := 0; i < len(locals); i++ {
+		l := locals[i]
+		if l.init != "" {
+			fmt.Fprintf(w, "%s := %s\n", l.name, l.init)
+			continue
+		}
+		// Group variables of the same type
+		names := []string{l.name}
+		for i+1 < len(locals) && locals[i+1].init == "" && locals[i+1].typ == l.typ {
+			i++
+			names = append(names, locals[i].name)
+		}
+		fmt.Fprintf(w, "var %s %s\n", strings.Join(names, ", "), l.typ)
+	}
+}
+
+func (fc *funcCompiler) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&fc.body, format, args...)
+	fc.body.WriteByte('\n')
+}
+
+// get returns an expression for the value of register r.
+func (fc *funcCompiler) get(r code.Reg) string {
+	if r.IsCell() {
+		fc.cells[r.Idx()] = true
+		return fmt.Sprintf("(*c%d)", r.Idx())
+	}
+	fc.regs[r.Idx()] = true
+	return fmt.Sprintf("r%d", r.Idx())
+}
+
+// lhs returns an expression that can be assigned to in order to set r.
+func (fc *funcCompiler) lhs(r code.Reg) string {
+	if r.IsCell() {
+		fc.cells[r.Idx()] = true
+		return fmt.Sprintf("*c%d", r.Idx())
+	}
+	if fc.secondPass && !fc.regs[r.Idx()] {
+		// The register is never read
+		return "_"
+	}
+	return fmt.Sprintf("r%d", r.Idx())
+}
+
+// result returns an expression that the result of an operation which may fail
+// can be assigned to before it is stored in r with storeResult.  It is a
+// temporary variable if r is a cell, so that the cell (which may be visible
+// outside the function) is not changed when the operation fails.
+func (fc *funcCompiler) result(r code.Reg) string {
+	if r.IsCell() {
+		fc.vars["tmp"] = true
+		return "tmp"
+	}
+	return fc.lhs(r)
+}
+
+// storeResult emits code storing the value assigned to result(r) in r.
+func (fc *funcCompiler) storeResult(r code.Reg) {
+	if r.IsCell() {
+		fc.printf("%s = tmp", fc.lhs(r))
+	}
+}
+
+// setOrPush emits code that sets register r to val, or pushes val to the
+// continuation in r if push is true.
+func (fc *funcCompiler) setOrPush(push bool, r code.Reg, val string) {
+	if push {
+		fc.printf("%s.AsCont().Push(t.Runtime, %s)", fc.get(r), val)
+	} else {
+		fc.printf("%s = %s", fc.lhs(r), val)
+	}
+}
+
+// checkErr emits code that returns err if it is not nil, opening with the
+// given condition.  The runtime gives the error the current line of the
+// function (see setLine).
+func (fc *funcCompiler) checkErr(cond string, args ...interface{}) {
+	fc.vars["err"] = true
+	fc.printf("if "+cond+"; err != nil {", args...)
+	fc.printf("return nil, err")
+	fc.printf("}")
+}
+
+// setLine emits code recording that the opcode at pc is executing, if it is on
+// a different line from the previous opcode.  This gives errors their position
+// and calls line debug hooks like the interpreter does.
+func (fc *funcCompiler) setLine(pc int) {
+	line := fc.line(pc)
+	if line <= 0 || line == fc.lastLine {
+		return
+	}
+	fc.lastLine = line
+	fc.checkErr("err = c.SetLine(t, %d)", line)
+}
+
+func (fc *funcCompiler) line(pc int) int32 {
+	if fc.lines == nil {
+		return 0
+	}
+	return fc.lines[pc]
+}
+
+// constant returns an expression for the value of the constant with index n.
+func (fc *funcCompiler) constant(n int) (string, error) {
+	if n >= len(fc.unit.Constants) {
+		return "", fmt.Errorf("invalid constant index %d", n)
+	}
+	switch k := fc.unit.Constants[n].(type) {
+	case code.Int:
+		return fmt.Sprintf("rt.IntValue(%d)", int64(k)), nil
+	case code.Float:
+		return floatValue(float64(k), fc.imports), nil
+	case code.String:
+		return fmt.Sprintf("rt.StringValue(%q)", string(k)), nil
+	case code.Bool:
+		return fmt.Sprintf("rt.BoolValue(%t)", bool(k)), nil
+	case code.NilType:
+		return "rt.NilValue", nil
+	default:
+		return "", fmt.Errorf("unsupported constant %s", k.ShortString())
+	}
+}
+
+func floatValue(f float64, imports map[string]bool) string {
+	switch {
+	case math.IsNaN(f):
+		imports["math"] = true
+		return "rt.FloatValue(math.NaN())"
+	case math.IsInf(f, 1):
+		imports["math"] = true
+		return "rt.FloatValue(math.Inf(1))"
+	case math.IsInf(f, -1):
+		imports["math"] = true
+		return "rt.FloatValue(math.Inf(-1))"
+	case f == 0 && math.Signbit(f):
+		imports["math"] = true
+		return "rt.FloatValue(math.Copysign(0, -1))"
+	}
+	return fmt.Sprintf("rt.FloatValue(%s)", strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// Fast paths for some binary operators on numbers.
+var numBinOps = map[code.BinOp]string{
+	code.OpAdd: "rt.Add",
+	code.OpSub: "rt.Sub",
+	code.OpMul: "rt.Mul",
+	code.OpDiv: "rt.Div",
+}
+
+var binOpNames = map[code.BinOp]string{
+	code.OpAdd:      "OpAdd",
+	code.OpSub:      "OpSub",
+	code.OpMul:      "OpMul",
+	code.OpDiv:      "OpDiv",
+	code.OpFloorDiv: "OpFloorDiv",
+	code.OpMod:      "OpMod",
+	code.OpPow:      "OpPow",
+	code.OpBitAnd:   "OpBitAnd",
+	code.OpBitOr:    "OpBitOr",
+	code.OpBitXor:   "OpBitXor",
+	code.OpShiftL:   "OpShiftL",
+	code.OpShiftR:   "OpShiftR",
+	code.OpEq:       "OpEq",
+	code.OpLt:       "OpLt",
+	code.OpLeq:      "OpLeq",
+	code.OpConcat:   "OpConcat",
+}
+
+// binOp emits code setting dst to op(x, y).
+func (fc *funcCompiler) binOp(op code.BinOp, dst code.Reg, x, y string) error {
+	name, ok := binOpNames[op]
+	if !ok {
+		return fmt.Errorf("unsupported binary operator %d", op)
+	}
+	fc.imports["code"] = true
+	res := fc.result(dst)
+	if fast, ok := numBinOps[op]; ok {
+		fc.printf("if v, ok := %s(%s, %s); ok {", fast, x, y)
+		fc.printf("%s = v", res)
+		fc.vars["err"] = true
+		fc.printf("} else if %s, err = rt.BinaryOp(t, code.%s, %s, %s); err != nil {", res, name, x, y)
+		fc.printf("return nil, err")
+		fc.printf("}")
+	} else {
+		fc.checkErr("%s, err = rt.BinaryOp(t, code.%s, %s, %s)", res, name, x, y)
+	}
+	fc.storeResult(dst)
+	return nil
+}
+
+var unOpNames = map[code.UnOp]string{
+	code.OpNeg:    "OpNeg",
+	code.OpBitNot: "OpBitNot",
+	code.OpLen:    "OpLen",
+}
+
+func (fc *funcCompiler) compileOpcode(pc int, opcode code.Opcode) error {
+	if opcode.HasType1() {
+		return fc.binOp(opcode.GetX(), opcode.GetA(), fc.get(opcode.GetB()), fc.get(opcode.GetC()))
+	}
+	switch opcode.TypePfx() {
+	case code.Type0Pfx:
+		dst := fc.lhs(opcode.GetA())
+		switch {
+		case fc.recvTerm < 0 && opcode.GetF():
+			fc.printf("%s = rt.ArrayValue(c.Etc())", dst)
+		case fc.recvTerm < 0:
+			fc.printf("%s = c.Arg(%d)", dst, fc.recvIdx)
+		case opcode.GetF():
+			fc.printf("%s = rt.ArrayValue(k%d.Etc())", dst, fc.recvTerm)
+		default:
+			fc.printf("%s = k%d.Get(%d)", dst, fc.recvTerm, fc.recvIdx)
+		}
+		fc.recvIdx++
+	case code.Type2Pfx:
+		reg, coll, idx := opcode.GetA(), fc.get(opcode.GetB()), fc.get(opcode.GetC())
+		if opcode.GetF() {
+			fc.checkErr("err = rt.SetIndex(t, %s, %s, %s)", coll, idx, fc.get(reg))
+		} else {
+			fc.checkErr("%s, err = rt.Index(t, %s, %s)", fc.result(reg), coll, idx)
+			fc.storeResult(reg)
+		}
+	case code.Type3Pfx:
+		n := opcode.GetN()
+		dst := opcode.GetA()
+		var val string
+		switch opcode.GetY() {
+		case code.OpInt16:
+			val = fmt.Sprintf("rt.IntValue(%d)", int16(n))
+		case code.OpStr2:
+			val = fmt.Sprintf("rt.StringValue(%q)", n.ToStr2())
+		case code.OpK:
+			var err error
+			val, err = fc.constant(int(n))
+			if err != nil {
+				return err
+			}
+		case code.OpClosureK:
+			k := int(n)
+			c, ok := fc.unit.Constants[k].(code.Code)
+			if !ok {
+				return fmt.Errorf("constant %d is not code", k)
+			}
+			ups := "nil"
+			if c.UpvalueCount > 0 {
+				ups = upvalsVar(dst)
+				fc.upvals[dst] = 0
+				fc.printf("%s = make([]*rt.Value, %d)", ups, c.UpvalueCount)
+			}
+			val = fmt.Sprintf("rt.FunctionValue(%s(%s))", fc.funcName(k), ups)
+		default:
+			return fmt.Errorf("unsupported opcode")
+		}
+		fc.setOrPush(opcode.GetF(), dst, val)
+	case code.Type4Pfx:
+		dst := opcode.GetA()
+		push := opcode.GetF()
+		if opcode.HasType4a() {
+			return fc.compileType4a(pc, opcode, dst, push)
+		}
+		var val string
+		switch code.UnOpK(opcode.GetUnOp()) {
+		case code.OpNil:
+			val = "rt.NilValue"
+		case code.OpStr0:
+			val = `rt.StringValue("")`
+		case code.OpTable:
+			val = "rt.TableValue(rt.NewTable())"
+			if narr, nhash := opcode.GetL().ToTableSize(); narr != 0 || nhash != 0 {
+				val = fmt.Sprintf("rt.TableValue(t.NewTableSize(%d, %d))", narr, nhash)
+			}
+		case code.OpStr1:
+			val = fmt.Sprintf("rt.StringValue(%q)", opcode.GetL().ToStr1())
+		case code.OpBool:
+			val = fmt.Sprintf("rt.BoolValue(%t)", opcode.GetL().ToBool())
+		case code.OpClear:
+			if dst.IsCell() {
+				// Only emit code if the cell is used otherwise
+				if fc.cells[dst.Idx()] {
+					fc.printf("c%d = new(rt.Value)", dst.Idx())
+				}
+			} else {
+				fc.printf("%s = rt.NilValue", fc.lhs(dst))
+			}
+			return nil
+		default:
+			return fmt.Errorf("unsupported opcode")
+		}
+		fc.setOrPush(push, dst, val)
+	case code.Type5Pfx:
+		switch opcode.GetJ() {
+		case code.OpJump:
+			fc.printf("goto L%d", pc+int(opcode.GetOffset()))
+		case code.OpJumpIf:
+			not := "!"
+			if opcode.GetF() {
+				not = ""
+			}
+			fc.printf("if %srt.Truth(%s) {", not, fc.get(opcode.GetA()))
+			fc.printf("goto L%d", pc+int(opcode.GetOffset()))
+			fc.printf("}")
+		case code.OpCall:
+			contReg := opcode.GetA()
+			if isTailCall(opcode) {
+				if fc.closes {
+					// Perform pending close actions before leaving
+					fc.checkErr("err = t.CloseToBeClosed(c, clBase)")
+				}
+				if contReg == code.ValueReg(0) {
+					// Returning to the caller
+					fc.printf("return %s.AsCont(), nil", fc.get(contReg))
+				} else {
+					fc.printf("return c.TailCall(t, %s.AsCont())", fc.get(contReg))
+				}
+				return nil
+			}
+			if contReg.IsCell() || !fc.terms[contReg.Idx()] {
+				return fmt.Errorf("call to a continuation with unknown next")
+			}
+			// The results are received by the Resumption in contReg, which
+			// resumes the function at the next opcode.
+			if len(fc.saved) > 0 {
+				fc.printf("s.%s = %s", strings.Join(fc.saved, ", s."), strings.Join(fc.saved, ", "))
+			}
+			fc.printf("return c.Call(t, %s.AsCont())", fc.get(contReg))
+			fc.recvTerm, fc.recvIdx = int(contReg.Idx()), 0
+		case code.OpClStack:
+			if opcode.GetF() {
+				fc.checkErr("err = t.PushToBeClosed(%s)", fc.get(opcode.GetA()))
+			} else {
+				fc.checkErr("err = t.CloseToBeClosed(c, clBase+%d)", opcode.GetClStackOffset())
+			}
+		default:
+			return fmt.Errorf("unsupported opcode")
+		}
+	case code.Type6Pfx:
+		dst := opcode.GetA()
+		if opcode.HasType6b() {
+			fc.printf("%s = rt.AdvForLoop(%s, %s, rt.IntValue(%d))", fc.lhs(dst), fc.get(dst), fc.get(opcode.GetB()), opcode.GetM().ToInt8())
+			return nil
+		}
+		etc := fc.get(opcode.GetB())
+		idx := int(opcode.GetM())
+		if opcode.GetF() {
+			fc.printf("for i, v := range %s.AsArray() {", etc)
+			fc.printf("t.SetTable(%s.AsTable(), rt.IntValue(int64(i+%d)), v)", fc.get(dst), idx)
+			fc.printf("}")
+		} else {
+			fc.printf("%s = %sEtc(%s, %d)", fc.lhs(dst), fc.prefix, etc, idx)
+		}
+	case code.Type7Pfx:
+		start, stop, step := opcode.GetA(), opcode.GetB(), opcode.GetC()
+		if opcode.GetF() {
+			fc.printf("%s = rt.AdvForLoop(%s, %s, %s)", fc.lhs(start), fc.get(start), fc.get(stop), fc.get(step))
+		} else {
+			fc.checkErr("%s, %s, %s, err = rt.PrepForLoop(%s, %s, %s)",
+				fc.lhs(start), fc.lhs(stop), fc.lhs(step), fc.get(start), fc.get(stop), fc.get(step))
+		}
+	case code.Type8Pfx:
+		reg, src := opcode.GetA(), fc.get(opcode.GetB())
+		m := opcode.GetM()
+		switch opcode.GetSpecOp() {
+		case code.OpAddInt8:
+			op := code.OpAdd
+			if opcode.GetF() {
+				op = code.OpSub
+			}
+			return fc.binOp(op, reg, src, fmt.Sprintf("rt.IntValue(%d)", m.ToInt8()))
+		case code.OpIndexK:
+			k, err := fc.constant(int(m))
+			if err != nil {
+				return err
+			}
+			if opcode.GetF() {
+				fc.checkErr("err = rt.SetIndex(t, %s, %s, %s)", src, k, fc.get(reg))
+			} else {
+				fc.checkErr("%s, err = rt.Index(t, %s, %s)", fc.result(reg), src, k)
+				fc.storeResult(reg)
+			}
+		default:
+			return fmt.Errorf("unsupported opcode")
+		}
+	default:
+		return fmt.Errorf("unsupported opcode")
+	}
+	return nil
+}
+
+func (fc *funcCompiler) compileType4a(pc int, opcode code.Opcode, dst code.Reg, push bool) error {
+	src := opcode.GetB()
+	switch op := opcode.GetUnOp(); op {
+	case code.OpNeg, code.OpBitNot, code.OpLen:
+		fc.imports["code"] = true
+		if push {
+			fc.vars["tmp"] = true
+			fc.checkErr("tmp, err = rt.UnaryOp(t, code.%s, %s)", unOpNames[op], fc.get(src))
+			fc.setOrPush(true, dst, "tmp")
+		} else {
+			fc.checkErr("%s, err = rt.UnaryOp(t, code.%s, %s)", fc.result(dst), unOpNames[op], fc.get(src))
+			fc.storeResult(dst)
+		}
+	case code.OpCont:
+		if dst.IsCell() {
+			return fmt.Errorf("continuation in a cell")
+		}
+		fc.terms[dst.Idx()] = true
+		fc.vars["cont"] = true
+		callPC := fc.callPC(pc, dst)
+		n, etc := fc.recvCount(callPC + 1)
+		fc.printf("if s == nil {")
+		fc.printf("s = &%sState{c: c}", fc.funcName(fc.k))
+		fc.printf("}")
+		fc.printf("k%d = rt.NewResumption(c, %d, %t, s, %d)", dst.Idx(), n, etc, callPC+1)
+		fc.checkErr("cont, err = rt.Continue(t, %s, k%d)", fc.get(src), dst.Idx())
+		fc.printf("%s = rt.ContValue(cont)", fc.lhs(dst))
+	case code.OpTailCont:
+		fc.vars["cont"] = true
+		fc.checkErr("cont, err = rt.Continue(t, %s, c.Next())", fc.get(src))
+		fc.printf("%s = rt.ContValue(cont)", fc.lhs(dst))
+	case code.OpId:
+		fc.setOrPush(push, dst, fc.get(src))
+	case code.OpTruth:
+		fc.setOrPush(push, dst, fmt.Sprintf("rt.BoolValue(rt.Truth(%s))", fc.get(src)))
+	case code.OpNot:
+		fc.setOrPush(push, dst, fmt.Sprintf("rt.BoolValue(!rt.Truth(%s))", fc.get(src)))
+	case code.OpUpvalue:
+		i, ok := fc.upvals[dst]
+		if !ok {
+			return fmt.Errorf("upvalue for unknown closure")
+		}
+		if !src.IsCell() {
+			return fmt.Errorf("upvalue is not a cell")
+		}
+		fc.cells[src.Idx()] = true
+		fc.printf("%s[%d] = c%d", upvalsVar(dst), i, src.Idx())
+		fc.upvals[dst] = i + 1
+	case code.OpEtcId:
+		if push {
+			fc.printf("%s.AsCont().PushEtc(t.Runtime, %s.AsArray())", fc.get(dst), fc.get(src))
+		} else {
+			fc.printf("%s = %s", fc.lhs(dst), fc.get(src))
+		}
+	default:
+		return fmt.Errorf("unsupported opcode")
+	}
+	return nil
+}
+
+// upvalsVar returns the name of the variable holding the upvalues of the
+// closure in register r.
+func upvalsVar(r code.Reg) string {
+	if r.IsCell() {
+		return fmt.Sprintf("uc%d", r.Idx())
+	}
+	return fmt.Sprintf("u%d", r.Idx())
+}
+
+func lowerFirst(s string) string {
+	for i, c := range s {
+		return string(unicode.ToLower(c)) + s[i+len(string(c)):]
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package gocomp_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arnodel/golua/gocomp"
+	"github.com/arnodel/golua/gocomp/internal/testchunks"
+	"github.com/arnodel/golua/lib"
+	"github.com/arnodel/golua/luatesting"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+var update = flag.Bool("update", false, "update the generated Go files in internal/testchunks")
+
+const testChunksDir = "internal/testchunks"
+
+// Check that the Go files in internal/testchunks are what the compiler
+// generates from the Lua scripts (or update them with -update).
+func TestGeneratedChunks(t *testing.T) {
+	for name := range testchunks.Chunks {
+		src, err := ioutil.ReadFile(filepath.Join(testChunksDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := rt.New(nil)
+		unit, _, err := r.CompileLuaChunk(name, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		opts := gocomp.Options{Package: "testchunks", FuncName: funcName(name)}
+		if err := gocomp.Compile(&out, unit, opts); err != nil {
+			t.Fatal(err)
+		}
+		goPath := filepath.Join(testChunksDir, strings.TrimSuffix(name, ".lua")+"_lua.go")
+		if *update {
+			if err := ioutil.WriteFile(goPath, out.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := ioutil.ReadFile(goPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), expected) {
+			t.Errorf("%s is out of date, run the tests with -update", goPath)
+		}
+	}
+}
+
+// Run the compiled chunks and check their output.
+func TestCompiledChunks(t *testing.T) {
+	for name, chunk := range testchunks.Chunks {
+		name, chunk := name, chunk
+		t.Run(name, func(t *testing.T) {
+			src, err := ioutil.ReadFile(filepath.Join(testChunksDir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			r := rt.New(&out)
+			defer lib.LoadAll(r)()
+			f := chunk(rt.TableValue(r.GlobalEnv()))
+			if err := rt.Call(r.MainThread(), rt.FunctionValue(f), nil, rt.NewTerminationWith(nil, 0, false)); err != nil {
+				t.Fatal(err)
+			}
+			if err := luatesting.CheckLines(out.Bytes(), luatesting.ExtractLineCheckers(src)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Compare the performance of compiled code with that of interpreted code.
+func BenchmarkCompiled(b *testing.B) {
+	src, err := ioutil.ReadFile(filepath.Join(testChunksDir, "functions.lua"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	run := func(b *testing.B, load func(r *rt.Runtime) rt.Value) {
+		var out bytes.Buffer
+		r := rt.New(&out)
+		defer lib.LoadAll(r)()
+		f := load(r)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := rt.Call(r.MainThread(), f, nil, rt.NewTerminationWith(nil, 0, false)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("interpreted", func(b *testing.B) {
+		run(b, func(r *rt.Runtime) rt.Value {
+			clos, err := r.CompileAndLoadLuaChunk("functions.lua", src, rt.TableValue(r.GlobalEnv()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			return rt.FunctionValue(clos)
+		})
+	})
+	b.Run("compiled", func(b *testing.B) {
+		run(b, func(r *rt.Runtime) rt.Value {
+			return rt.FunctionValue(testchunks.Functions(rt.TableValue(r.GlobalEnv())))
+		})
+	})
+}
+
+// funcName returns the name of the function returning the main chunk of the
+// Lua script called name (e.g. "basics.lua" -> "Basics").
+func funcName(name string) string {
+	name = strings.TrimSuffix(name, ".lua")
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,70 @@
+-- Arithmetic, comparisons and strings
+local a, b = 7, 2
+print(a + b, a - b, a * b, a / b, a // b, a % b, a ^ b)
+--> =9	5	14	3.5	3	1	49
+
+print(a & b, a | b, a ~ b, a << b, a >> 1, ~a, -a)
+--> =2	7	5	28	3	-8	-7
+
+print(a < b, a <= b, a == 7, a ~= 7, "x" < "y", 1 == 1.0)
+--> =false	false	true	false	true	true
+
+print("a" .. "b" .. 1, #"hello", not nil, "10" + 5)
+--> =ab1	5	true	15
+
+print(math.type(1 // 1), math.type(1 / 1), 3 % -2, 5.5 // 2)
+--> =integer	float	-1	2
+
+-- Numeric for loops
+local s = 0
+for i = 1, 10 do s = s + i end
+for i = 10, 1, -3 do s = s + i end
+for x = 0, 1, 0.25 do s = s + x end
+print(s)
+--> =79.5
+
+print(pcall(function() for i = 1, 10, 0 do end end))
+--> ~false\t.*'for' step is zero
+
+-- While, repeat and goto
+local n = 0
+while n < 10 do n = n + 3 end
+repeat n = n - 1 until n < 5
+print(n)
+--> =4
+
+do
+    local i = 1
+    ::top::
+    if i < 100 then
+        i = i * 2
+        goto top
+    end
+    print(i)
+    --> =128
+end
+
+-- Tables
+local t = {1, 2, 3, x = "y", [10] = "ten"}
+t.z = t.x .. "!"
+t[#t + 1] = 4
+print(#t, t.x, t.z, t[10], t[4])
+--> =4	y	y!	ten	4
+
+local keys = {}
+for k in pairs({a = 1, b = 2, c = 3}) do keys[#keys + 1] = k end
+table.sort(keys)
+print(table.concat(keys, ","))
+--> =a,b,c
+
+local total = 0
+for i, v in ipairs({10, 20, 30}) do total = total + i * v end
+print(total)
+--> =140
+
+-- Errors have the position in the source
+print(pcall(function() local x = nil; return x.y end))
+--> ~false\t.*basics.lua:66: attempt to index a nil value
+
+print(pcall(function() return {} + 1 end))
+--> ~false\t.*basics.lua:69: attempt to perform arithmetic on a table value
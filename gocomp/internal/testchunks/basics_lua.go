@@ -0,0 +1,1193 @@
+// Code generated by golua from basics.lua. DO NOT EDIT.
+
+package testchunks
+
+import (
+	"github.com/arnodel/golua/code"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Basics returns the main chunk of basics.lua compiled to Go, with env as the value of
+// _ENV.
+func Basics(env rt.Value) *rt.GoFunction {
+	return basicsF0([]*rt.Value{&env})
+}
+
+const basicsSource = "basics.lua"
+
+// basicsEtc returns the value at index i in etc (or nil).
+func basicsEtc(etc rt.Value, i int) rt.Value {
+	if vals := etc.AsArray(); i < len(vals) {
+		return vals[i]
+	}
+	return rt.NilValue
+}
+
+// basicsF0 implements function <main chunk> [0 - 316].
+func basicsF0(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return basicsF0Run(t, c, ups, nil, 0)
+	}, "<main chunk>", 0, true)
+	f.SetLuaSource(basicsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// basicsF0State holds the variables of basicsF0 while it is suspended by a call.
+type basicsF0State struct {
+	c      *rt.GoCont
+	r0     rt.Value
+	c0     *rt.Value
+	clBase int
+	r2     rt.Value
+	r3     rt.Value
+	r4     rt.Value
+	r5     rt.Value
+	r6     rt.Value
+	r7     rt.Value
+	r8     rt.Value
+	r9     rt.Value
+	r10    rt.Value
+	r11    rt.Value
+	r12    rt.Value
+	r13    rt.Value
+	r14    rt.Value
+	r15    rt.Value
+	r16    rt.Value
+	r17    rt.Value
+	r18    rt.Value
+	r19    rt.Value
+	r20    rt.Value
+	k4     *rt.Resumption
+	k5     *rt.Resumption
+	k6     *rt.Resumption
+	k7     *rt.Resumption
+	k12    *rt.Resumption
+	k13    *rt.Resumption
+	k17    *rt.Resumption
+	k18    *rt.Resumption
+	k19    *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *basicsF0State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return basicsF0Run(t, s.c, nil, s, at)
+}
+
+// basicsF0Run runs basicsF0 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func basicsF0Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *basicsF0State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var clBase int
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var r5 rt.Value
+	var r6 rt.Value
+	var r7 rt.Value
+	var r8 rt.Value
+	var r9 rt.Value
+	var r10 rt.Value
+	var r11 rt.Value
+	var r12 rt.Value
+	var r13 rt.Value
+	var r14 rt.Value
+	var r15 rt.Value
+	var r16 rt.Value
+	var r17 rt.Value
+	var r18 rt.Value
+	var r19 rt.Value
+	var r20 rt.Value
+	var k4 *rt.Resumption
+	var k5 *rt.Resumption
+	var k6 *rt.Resumption
+	var k7 *rt.Resumption
+	var k12 *rt.Resumption
+	var k13 *rt.Resumption
+	var k17 *rt.Resumption
+	var k18 *rt.Resumption
+	var k19 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+		clBase = t.CloseStackHeight()
+	} else {
+		r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19 = s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19
+		switch at {
+		case 20:
+			goto L20
+		case 38:
+			goto L38
+		case 58:
+			goto L58
+		case 74:
+			goto L74
+		case 84:
+			goto L84
+		case 92:
+			goto L92
+		case 103:
+			goto L103
+		case 132:
+			goto L132
+		case 139:
+			goto L139
+		case 142:
+			goto L142
+		case 157:
+			goto L157
+		case 168:
+			goto L168
+		case 208:
+			goto L208
+		case 220:
+			goto L220
+		case 229:
+			goto L229
+		case 246:
+			goto L246
+		case 255:
+			goto L255
+		case 258:
+			goto L258
+		case 273:
+			goto L273
+		case 282:
+			goto L282
+		case 296:
+			goto L296
+		case 303:
+			goto L303
+		case 306:
+			goto L306
+		case 313:
+			goto L313
+		case 316:
+			goto L316
+		}
+	}
+	t.RequireCPU(20)
+	_ = rt.ArrayValue(c.Etc())
+	if err = c.SetLine(t, 2); err != nil {
+		return nil, err
+	}
+	r2 = rt.IntValue(7)
+	r3 = rt.IntValue(2)
+	if err = c.SetLine(t, 3); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, false, s, 20)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	r5 = rt.IntValue(9)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(5)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(14)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.FloatValue(3.5)
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.BinaryOp(t, code.OpFloorDiv, r2, r3); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.BinaryOp(t, code.OpMod, r2, r3); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.BinaryOp(t, code.OpPow, r2, r3); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r4.AsCont())
+L20:
+	t.RequireCPU(18)
+	if err = c.SetLine(t, 6); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, false, s, 38)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	if r5, err = rt.BinaryOp(t, code.OpBitAnd, r2, r3); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.BinaryOp(t, code.OpBitOr, r2, r3); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.BinaryOp(t, code.OpBitXor, r2, r3); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.BinaryOp(t, code.OpShiftL, r2, r3); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(1)
+	if r5, err = rt.BinaryOp(t, code.OpShiftR, r2, r5); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.UnaryOp(t, code.OpBitNot, r2); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(-7)
+	r4.AsCont().Push(t.Runtime, r5)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r4.AsCont())
+L38:
+	t.RequireCPU(20)
+	if err = c.SetLine(t, 9); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, false, s, 58)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	r5 = rt.BoolValue(false)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.BoolValue(false)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.BoolValue(true)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.BoolValue(true)
+	r5 = rt.BoolValue(!rt.Truth(r5))
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.StringValue("x")
+	r6 = rt.StringValue("y")
+	if r6, err = rt.BinaryOp(t, code.OpLt, r5, r6); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r6)
+	r5 = rt.IntValue(1)
+	r6 = rt.FloatValue(1)
+	if r6, err = rt.BinaryOp(t, code.OpEq, r5, r6); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r6)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r4.AsCont())
+L58:
+	t.RequireCPU(16)
+	if err = c.SetLine(t, 12); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, false, s, 74)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	r5 = rt.StringValue("a")
+	r6 = rt.StringValue("b")
+	r7 = rt.IntValue(1)
+	if r7, err = rt.BinaryOp(t, code.OpConcat, r6, r7); err != nil {
+		return nil, err
+	}
+	if r6, err = rt.BinaryOp(t, code.OpConcat, r5, r7); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r6)
+	r5 = rt.IntValue(5)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.BoolValue(true)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.StringValue("10")
+	if v, ok := rt.Add(r5, rt.IntValue(5)); ok {
+		r6 = v
+	} else if r6, err = rt.BinaryOp(t, code.OpAdd, r5, rt.IntValue(5)); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r6)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r4.AsCont())
+L74:
+	t.RequireCPU(10)
+	if err = c.SetLine(t, 15); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, false, s, 103)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("math")); err != nil {
+		return nil, err
+	}
+	if r6, err = rt.Index(t, r5, rt.StringValue("type")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 1, false, s, 84)
+	if cont, err = rt.Continue(t, r6, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r6 = rt.IntValue(1)
+	r7 = rt.IntValue(1)
+	if r7, err = rt.BinaryOp(t, code.OpFloorDiv, r6, r7); err != nil {
+		return nil, err
+	}
+	r5.AsCont().Push(t.Runtime, r7)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r5.AsCont())
+L84:
+	t.RequireCPU(8)
+	r5 = k5.Get(0)
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("math")); err != nil {
+		return nil, err
+	}
+	if r6, err = rt.Index(t, r5, rt.StringValue("type")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 1, false, s, 92)
+	if cont, err = rt.Continue(t, r6, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r6 = rt.FloatValue(1)
+	r5.AsCont().Push(t.Runtime, r6)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r5.AsCont())
+L92:
+	t.RequireCPU(11)
+	r5 = k5.Get(0)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(3)
+	r6 = rt.IntValue(-2)
+	if r6, err = rt.BinaryOp(t, code.OpMod, r5, r6); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r6)
+	r5 = rt.FloatValue(5.5)
+	r6 = rt.IntValue(2)
+	if r6, err = rt.BinaryOp(t, code.OpFloorDiv, r5, r6); err != nil {
+		return nil, err
+	}
+	r4.AsCont().Push(t.Runtime, r6)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r4.AsCont())
+L103:
+	t.RequireCPU(5)
+	if err = c.SetLine(t, 19); err != nil {
+		return nil, err
+	}
+	r4 = rt.IntValue(0)
+	if err = c.SetLine(t, 20); err != nil {
+		return nil, err
+	}
+	r5 = rt.IntValue(1)
+	r6 = rt.IntValue(10)
+	r7 = rt.IntValue(1)
+	if r5, r6, r7, err = rt.PrepForLoop(r5, r6, r7); err != nil {
+		return nil, err
+	}
+L108:
+	t.RequireCPU(4)
+	if !rt.Truth(r5) {
+		goto L112
+	}
+	if err = c.SetLine(t, 20); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r4, r5); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpAdd, r4, r5); err != nil {
+		return nil, err
+	}
+	r5 = rt.AdvForLoop(r5, r6, rt.IntValue(1))
+	if rt.Truth(r5) {
+		goto L108
+	}
+L112:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 21); err != nil {
+		return nil, err
+	}
+	r5 = rt.IntValue(10)
+	r6 = rt.IntValue(1)
+	r7 = rt.IntValue(-3)
+	if r5, r6, r7, err = rt.PrepForLoop(r5, r6, r7); err != nil {
+		return nil, err
+	}
+L116:
+	t.RequireCPU(4)
+	if !rt.Truth(r5) {
+		goto L120
+	}
+	if err = c.SetLine(t, 21); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r4, r5); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpAdd, r4, r5); err != nil {
+		return nil, err
+	}
+	r5 = rt.AdvForLoop(r5, r6, rt.IntValue(-3))
+	if rt.Truth(r5) {
+		goto L116
+	}
+L120:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 22); err != nil {
+		return nil, err
+	}
+	r5 = rt.IntValue(0)
+	r6 = rt.IntValue(1)
+	r7 = rt.FloatValue(0.25)
+	if r5, r6, r7, err = rt.PrepForLoop(r5, r6, r7); err != nil {
+		return nil, err
+	}
+L124:
+	t.RequireCPU(4)
+	if !rt.Truth(r5) {
+		goto L128
+	}
+	if err = c.SetLine(t, 22); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r4, r5); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpAdd, r4, r5); err != nil {
+		return nil, err
+	}
+	r5 = rt.AdvForLoop(r5, r6, r7)
+	if rt.Truth(r5) {
+		goto L124
+	}
+L128:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 23); err != nil {
+		return nil, err
+	}
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 0, false, s, 132)
+	if cont, err = rt.Continue(t, r5, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r5.AsCont().Push(t.Runtime, r4)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r5.AsCont())
+L132:
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 26); err != nil {
+		return nil, err
+	}
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 0, false, s, 142)
+	if cont, err = rt.Continue(t, r5, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	if r6, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k6 = rt.NewResumption(c, 0, true, s, 139)
+	if cont, err = rt.Continue(t, r6, k6); err != nil {
+		return nil, err
+	}
+	r6 = rt.ContValue(cont)
+	r7 = rt.FunctionValue(basicsF14(nil))
+	r6.AsCont().Push(t.Runtime, r7)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r6.AsCont())
+L139:
+	t.RequireCPU(3)
+	r6 = rt.ArrayValue(k6.Etc())
+	r5.AsCont().PushEtc(t.Runtime, r6.AsArray())
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r5.AsCont())
+L142:
+	t.RequireCPU(1)
+	if err = c.SetLine(t, 30); err != nil {
+		return nil, err
+	}
+	r5 = rt.IntValue(0)
+L143:
+	t.RequireCPU(5)
+	if err = c.SetLine(t, 31); err != nil {
+		return nil, err
+	}
+	r6 = rt.IntValue(10)
+	if r6, err = rt.BinaryOp(t, code.OpLt, r5, r6); err != nil {
+		return nil, err
+	}
+	if !rt.Truth(r6) {
+		goto L148
+	}
+	if v, ok := rt.Add(r5, rt.IntValue(3)); ok {
+		r5 = v
+	} else if r5, err = rt.BinaryOp(t, code.OpAdd, r5, rt.IntValue(3)); err != nil {
+		return nil, err
+	}
+	goto L143
+L148:
+	t.RequireCPU(9)
+	if err = c.SetLine(t, 32); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Sub(r5, rt.IntValue(1)); ok {
+		r5 = v
+	} else if r5, err = rt.BinaryOp(t, code.OpSub, r5, rt.IntValue(1)); err != nil {
+		return nil, err
+	}
+	r6 = rt.IntValue(5)
+	if r6, err = rt.BinaryOp(t, code.OpLt, r5, r6); err != nil {
+		return nil, err
+	}
+	r6 = rt.BoolValue(!rt.Truth(r6))
+	if rt.Truth(r6) {
+		goto L148
+	}
+	if err = c.SetLine(t, 33); err != nil {
+		return nil, err
+	}
+	if r6, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k6 = rt.NewResumption(c, 0, false, s, 157)
+	if cont, err = rt.Continue(t, r6, k6); err != nil {
+		return nil, err
+	}
+	r6 = rt.ContValue(cont)
+	r6.AsCont().Push(t.Runtime, r5)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r6.AsCont())
+L157:
+	t.RequireCPU(1)
+	if err = c.SetLine(t, 37); err != nil {
+		return nil, err
+	}
+	r6 = rt.IntValue(1)
+L158:
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 39); err != nil {
+		return nil, err
+	}
+	r7 = rt.IntValue(100)
+	if r7, err = rt.BinaryOp(t, code.OpLt, r6, r7); err != nil {
+		return nil, err
+	}
+	if !rt.Truth(r7) {
+		goto L164
+	}
+	if err = c.SetLine(t, 40); err != nil {
+		return nil, err
+	}
+	r7 = rt.IntValue(2)
+	if v, ok := rt.Mul(r6, r7); ok {
+		r6 = v
+	} else if r6, err = rt.BinaryOp(t, code.OpMul, r6, r7); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 38); err != nil {
+		return nil, err
+	}
+	goto L158
+L164:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 43); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 168)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	r7.AsCont().Push(t.Runtime, r6)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r7.AsCont())
+L168:
+	t.RequireCPU(40)
+	if err = c.SetLine(t, 48); err != nil {
+		return nil, err
+	}
+	r6 = rt.TableValue(t.NewTableSize(4, 2))
+	r7 = rt.IntValue(1)
+	r8 = rt.IntValue(1)
+	if err = rt.SetIndex(t, r6, r8, r7); err != nil {
+		return nil, err
+	}
+	r7 = rt.IntValue(2)
+	r8 = rt.IntValue(2)
+	if err = rt.SetIndex(t, r6, r8, r7); err != nil {
+		return nil, err
+	}
+	r7 = rt.IntValue(3)
+	r8 = rt.IntValue(3)
+	if err = rt.SetIndex(t, r6, r8, r7); err != nil {
+		return nil, err
+	}
+	r7 = rt.StringValue("y")
+	if err = rt.SetIndex(t, r6, rt.StringValue("x"), r7); err != nil {
+		return nil, err
+	}
+	r7 = rt.StringValue("ten")
+	r8 = rt.IntValue(10)
+	if err = rt.SetIndex(t, r6, r8, r7); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 49); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, r6, rt.StringValue("x")); err != nil {
+		return nil, err
+	}
+	r8 = rt.StringValue("!")
+	if r8, err = rt.BinaryOp(t, code.OpConcat, r7, r8); err != nil {
+		return nil, err
+	}
+	r7 = r6
+	if err = rt.SetIndex(t, r7, rt.StringValue("z"), r8); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 50); err != nil {
+		return nil, err
+	}
+	r7 = rt.IntValue(4)
+	r8 = r6
+	if r9, err = rt.UnaryOp(t, code.OpLen, r6); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r9, rt.IntValue(1)); ok {
+		r10 = v
+	} else if r10, err = rt.BinaryOp(t, code.OpAdd, r9, rt.IntValue(1)); err != nil {
+		return nil, err
+	}
+	if err = rt.SetIndex(t, r8, r10, r7); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 51); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 208)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	if r8, err = rt.UnaryOp(t, code.OpLen, r6); err != nil {
+		return nil, err
+	}
+	r7.AsCont().Push(t.Runtime, r8)
+	if r8, err = rt.Index(t, r6, rt.StringValue("x")); err != nil {
+		return nil, err
+	}
+	r7.AsCont().Push(t.Runtime, r8)
+	if r8, err = rt.Index(t, r6, rt.StringValue("z")); err != nil {
+		return nil, err
+	}
+	r7.AsCont().Push(t.Runtime, r8)
+	r8 = rt.IntValue(10)
+	if r8, err = rt.Index(t, r6, r8); err != nil {
+		return nil, err
+	}
+	r7.AsCont().Push(t.Runtime, r8)
+	r8 = rt.IntValue(4)
+	if r8, err = rt.Index(t, r6, r8); err != nil {
+		return nil, err
+	}
+	r7.AsCont().Push(t.Runtime, r8)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r7.AsCont())
+L208:
+	t.RequireCPU(12)
+	if err = c.SetLine(t, 54); err != nil {
+		return nil, err
+	}
+	r7 = rt.TableValue(rt.NewTable())
+	if err = c.SetLine(t, 55); err != nil {
+		return nil, err
+	}
+	if r12, err = rt.Index(t, (*c0), rt.StringValue("pairs")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k12 = rt.NewResumption(c, 4, false, s, 220)
+	if cont, err = rt.Continue(t, r12, k12); err != nil {
+		return nil, err
+	}
+	r12 = rt.ContValue(cont)
+	r13 = rt.TableValue(t.NewTableSize(0, 4))
+	r14 = rt.IntValue(1)
+	if err = rt.SetIndex(t, r13, rt.StringValue("a"), r14); err != nil {
+		return nil, err
+	}
+	r14 = rt.IntValue(2)
+	if err = rt.SetIndex(t, r13, rt.StringValue("b"), r14); err != nil {
+		return nil, err
+	}
+	r14 = rt.IntValue(3)
+	if err = rt.SetIndex(t, r13, rt.StringValue("c"), r14); err != nil {
+		return nil, err
+	}
+	r12.AsCont().Push(t.Runtime, r13)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r12.AsCont())
+L220:
+	t.RequireCPU(5)
+	r8 = k12.Get(0)
+	r9 = k12.Get(1)
+	r10 = k12.Get(2)
+	r11 = k12.Get(3)
+	if err = t.PushToBeClosed(r11); err != nil {
+		return nil, err
+	}
+L225:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 55); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k12 = rt.NewResumption(c, 1, false, s, 229)
+	if cont, err = rt.Continue(t, r8, k12); err != nil {
+		return nil, err
+	}
+	r12 = rt.ContValue(cont)
+	r12.AsCont().Push(t.Runtime, r9)
+	r12.AsCont().Push(t.Runtime, r10)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r12.AsCont())
+L229:
+	t.RequireCPU(11)
+	r12 = k12.Get(0)
+	r13 = rt.NilValue
+	if r13, err = rt.BinaryOp(t, code.OpEq, r12, r13); err != nil {
+		return nil, err
+	}
+	if rt.Truth(r13) {
+		goto L240
+	}
+	r10 = r12
+	r13 = r12
+	r14 = r7
+	if r15, err = rt.UnaryOp(t, code.OpLen, r7); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r15, rt.IntValue(1)); ok {
+		r16 = v
+	} else if r16, err = rt.BinaryOp(t, code.OpAdd, r15, rt.IntValue(1)); err != nil {
+		return nil, err
+	}
+	if err = rt.SetIndex(t, r14, r16, r13); err != nil {
+		return nil, err
+	}
+	goto L225
+L240:
+	t.RequireCPU(6)
+	if err = t.CloseToBeClosed(c, clBase+0); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 56); err != nil {
+		return nil, err
+	}
+	if r12, err = rt.Index(t, (*c0), rt.StringValue("table")); err != nil {
+		return nil, err
+	}
+	if r13, err = rt.Index(t, r12, rt.StringValue("sort")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k12 = rt.NewResumption(c, 0, false, s, 246)
+	if cont, err = rt.Continue(t, r13, k12); err != nil {
+		return nil, err
+	}
+	r12 = rt.ContValue(cont)
+	r12.AsCont().Push(t.Runtime, r7)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r12.AsCont())
+L246:
+	t.RequireCPU(9)
+	if err = c.SetLine(t, 57); err != nil {
+		return nil, err
+	}
+	if r12, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k12 = rt.NewResumption(c, 0, false, s, 258)
+	if cont, err = rt.Continue(t, r12, k12); err != nil {
+		return nil, err
+	}
+	r12 = rt.ContValue(cont)
+	if r13, err = rt.Index(t, (*c0), rt.StringValue("table")); err != nil {
+		return nil, err
+	}
+	if r14, err = rt.Index(t, r13, rt.StringValue("concat")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 0, true, s, 255)
+	if cont, err = rt.Continue(t, r14, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	r13.AsCont().Push(t.Runtime, r7)
+	r14 = rt.StringValue(",")
+	r13.AsCont().Push(t.Runtime, r14)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r13.AsCont())
+L255:
+	t.RequireCPU(3)
+	r13 = rt.ArrayValue(k13.Etc())
+	r12.AsCont().PushEtc(t.Runtime, r13.AsArray())
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r12.AsCont())
+L258:
+	t.RequireCPU(15)
+	if err = c.SetLine(t, 60); err != nil {
+		return nil, err
+	}
+	r12 = rt.IntValue(0)
+	if err = c.SetLine(t, 61); err != nil {
+		return nil, err
+	}
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("ipairs")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k17 = rt.NewResumption(c, 4, false, s, 273)
+	if cont, err = rt.Continue(t, r17, k17); err != nil {
+		return nil, err
+	}
+	r17 = rt.ContValue(cont)
+	r18 = rt.TableValue(t.NewTableSize(4, 0))
+	r19 = rt.IntValue(10)
+	r20 = rt.IntValue(1)
+	if err = rt.SetIndex(t, r18, r20, r19); err != nil {
+		return nil, err
+	}
+	r19 = rt.IntValue(20)
+	r20 = rt.IntValue(2)
+	if err = rt.SetIndex(t, r18, r20, r19); err != nil {
+		return nil, err
+	}
+	r19 = rt.IntValue(30)
+	r20 = rt.IntValue(3)
+	if err = rt.SetIndex(t, r18, r20, r19); err != nil {
+		return nil, err
+	}
+	r17.AsCont().Push(t.Runtime, r18)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r17.AsCont())
+L273:
+	t.RequireCPU(5)
+	r13 = k17.Get(0)
+	r14 = k17.Get(1)
+	r15 = k17.Get(2)
+	r16 = k17.Get(3)
+	if err = t.PushToBeClosed(r16); err != nil {
+		return nil, err
+	}
+L278:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 61); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k19 = rt.NewResumption(c, 2, false, s, 282)
+	if cont, err = rt.Continue(t, r13, k19); err != nil {
+		return nil, err
+	}
+	r19 = rt.ContValue(cont)
+	r19.AsCont().Push(t.Runtime, r14)
+	r19.AsCont().Push(t.Runtime, r15)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r19.AsCont())
+L282:
+	t.RequireCPU(9)
+	r17 = k19.Get(0)
+	r18 = k19.Get(1)
+	r19 = rt.NilValue
+	if r19, err = rt.BinaryOp(t, code.OpEq, r17, r19); err != nil {
+		return nil, err
+	}
+	if rt.Truth(r19) {
+		goto L291
+	}
+	r15 = r17
+	if v, ok := rt.Mul(r17, r18); ok {
+		r19 = v
+	} else if r19, err = rt.BinaryOp(t, code.OpMul, r17, r18); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r12, r19); ok {
+		r12 = v
+	} else if r12, err = rt.BinaryOp(t, code.OpAdd, r12, r19); err != nil {
+		return nil, err
+	}
+	goto L278
+L291:
+	t.RequireCPU(5)
+	if err = t.CloseToBeClosed(c, clBase+0); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 62); err != nil {
+		return nil, err
+	}
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k17 = rt.NewResumption(c, 0, false, s, 296)
+	if cont, err = rt.Continue(t, r17, k17); err != nil {
+		return nil, err
+	}
+	r17 = rt.ContValue(cont)
+	r17.AsCont().Push(t.Runtime, r12)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r17.AsCont())
+L296:
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 66); err != nil {
+		return nil, err
+	}
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k17 = rt.NewResumption(c, 0, false, s, 306)
+	if cont, err = rt.Continue(t, r17, k17); err != nil {
+		return nil, err
+	}
+	r17 = rt.ContValue(cont)
+	if r18, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 0, true, s, 303)
+	if cont, err = rt.Continue(t, r18, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	r19 = rt.FunctionValue(basicsF25(nil))
+	r18.AsCont().Push(t.Runtime, r19)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r18.AsCont())
+L303:
+	t.RequireCPU(3)
+	r18 = rt.ArrayValue(k18.Etc())
+	r17.AsCont().PushEtc(t.Runtime, r18.AsArray())
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r17.AsCont())
+L306:
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 69); err != nil {
+		return nil, err
+	}
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k17 = rt.NewResumption(c, 0, false, s, 316)
+	if cont, err = rt.Continue(t, r17, k17); err != nil {
+		return nil, err
+	}
+	r17 = rt.ContValue(cont)
+	if r18, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &basicsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 0, true, s, 313)
+	if cont, err = rt.Continue(t, r18, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	r19 = rt.FunctionValue(basicsF26(nil))
+	r18.AsCont().Push(t.Runtime, r19)
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r18.AsCont())
+L313:
+	t.RequireCPU(3)
+	r18 = rt.ArrayValue(k18.Etc())
+	r17.AsCont().PushEtc(t.Runtime, r18.AsArray())
+	s.r0, s.c0, s.clBase, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.k4, s.k5, s.k6, s.k7, s.k12, s.k13, s.k17, s.k18, s.k19 = r0, c0, clBase, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, k4, k5, k6, k7, k12, k13, k17, k18, k19
+	return c.Call(t, r17.AsCont())
+L316:
+	t.RequireCPU(1)
+	if err = t.CloseToBeClosed(c, clBase); err != nil {
+		return nil, err
+	}
+	return r0.AsCont(), nil
+}
+
+// basicsF14 implements function <anon> [317 - 324].
+func basicsF14(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2, r3 rt.Value
+		var err error
+		t.RequireCPU(4)
+		if err = c.SetLine(t, 26); err != nil {
+			return nil, err
+		}
+		r1 = rt.IntValue(1)
+		r2 = rt.IntValue(10)
+		r3 = rt.IntValue(0)
+		if r1, r2, r3, err = rt.PrepForLoop(r1, r2, r3); err != nil {
+			return nil, err
+		}
+	L4:
+		t.RequireCPU(3)
+		if !rt.Truth(r1) {
+			goto L7
+		}
+		if err = c.SetLine(t, 26); err != nil {
+			return nil, err
+		}
+		r1 = rt.AdvForLoop(r1, r2, rt.IntValue(0))
+		if rt.Truth(r1) {
+			goto L4
+		}
+	L7:
+		t.RequireCPU(1)
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(basicsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// basicsF25 implements function <anon> [325 - 328].
+func basicsF25(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2 rt.Value
+		var err error
+		t.RequireCPU(4)
+		if err = c.SetLine(t, 66); err != nil {
+			return nil, err
+		}
+		r1 = rt.NilValue
+		if r2, err = rt.Index(t, r1, rt.StringValue("y")); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r2)
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(basicsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// basicsF26 implements function <anon> [329 - 332].
+func basicsF26(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2 rt.Value
+		var err error
+		t.RequireCPU(4)
+		if err = c.SetLine(t, 69); err != nil {
+			return nil, err
+		}
+		r1 = rt.TableValue(rt.NewTable())
+		if v, ok := rt.Add(r1, rt.IntValue(1)); ok {
+			r2 = v
+		} else if r2, err = rt.BinaryOp(t, code.OpAdd, r1, rt.IntValue(1)); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r2)
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(basicsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
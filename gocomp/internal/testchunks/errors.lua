@@ -0,0 +1,63 @@
+-- Errors raised by error() are given the position of the right function
+local function f() error("boom") end
+print(select(2, pcall(f)))
+--> =errors.lua:2: boom
+
+local function g() error("up", 2) end
+local function callg()
+    g()
+end
+print(select(2, pcall(callg)))
+--> =errors.lua:8: up
+
+print(select(2, pcall(function() error("nopos", 0) end)))
+--> =nopos
+
+-- Errors raised by the runtime too
+local function idx(t)
+    return t.x
+        .y
+end
+print(pcall(idx, {}))
+--> =false	errors.lua:18: attempt to index a nil value
+
+-- The debug library sees the current line
+local function where()
+    local info = debug.getinfo(1)
+    return info.source, info.currentline
+end
+print(where())
+--> =errors.lua	26
+
+-- Calls, tail calls, returns and lines are reported to hooks
+local events = {}
+local function callee() return 1 end
+local function caller()
+    local x = callee()
+    return callee()
+end
+debug.sethook(function(ev, line)
+    events[#events+1] = line and ev .. line or ev
+end, "crl")
+caller()
+debug.sethook()
+print(table.concat(events, " "))
+--> =return line42 call line36 call line34 return line37 tail call line34 return line43 call
+
+-- Failed operations do not change upvalues
+local s = 0
+print(pcall(function() s = 1 + {} end), s)
+--> =false	0
+pcall(function() s = s.x end)
+pcall(function() s = -{} end)
+pcall(function() s = ({})[nil].y end)
+print(s)
+--> =0
+
+-- Calls do not nest Go calls, so deep recursion does not overflow
+local function depth(n)
+    if n == 0 then return 0 end
+    return 1 + depth(n - 1)
+end
+print(depth(100000))
+--> =100000
@@ -0,0 +1,1466 @@
+// Code generated by golua from errors.lua. DO NOT EDIT.
+
+package testchunks
+
+import (
+	"github.com/arnodel/golua/code"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Errors returns the main chunk of errors.lua compiled to Go, with env as the value of
+// _ENV.
+func Errors(env rt.Value) *rt.GoFunction {
+	return errorsF0([]*rt.Value{&env})
+}
+
+const errorsSource = "errors.lua"
+
+// errorsEtc returns the value at index i in etc (or nil).
+func errorsEtc(etc rt.Value, i int) rt.Value {
+	if vals := etc.AsArray(); i < len(vals) {
+		return vals[i]
+	}
+	return rt.NilValue
+}
+
+// errorsF0 implements function <main chunk> [0 - 155].
+func errorsF0(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF0Run(t, c, ups, nil, 0)
+	}, "<main chunk>", 0, true)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF0State holds the variables of errorsF0 while it is suspended by a call.
+type errorsF0State struct {
+	c   *rt.GoCont
+	r0  rt.Value
+	c0  *rt.Value
+	c1  *rt.Value
+	c2  *rt.Value
+	c3  *rt.Value
+	c4  *rt.Value
+	c5  *rt.Value
+	r2  rt.Value
+	r3  rt.Value
+	r4  rt.Value
+	r5  rt.Value
+	r6  rt.Value
+	r7  rt.Value
+	r8  rt.Value
+	r9  rt.Value
+	k3  *rt.Resumption
+	k4  *rt.Resumption
+	k5  *rt.Resumption
+	k6  *rt.Resumption
+	k7  *rt.Resumption
+	k8  *rt.Resumption
+	u2  []*rt.Value
+	u3  []*rt.Value
+	u5  []*rt.Value
+	u6  []*rt.Value
+	u7  []*rt.Value
+	u8  []*rt.Value
+	u9  []*rt.Value
+	uc1 []*rt.Value
+	uc5 []*rt.Value
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF0State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF0Run(t, s.c, nil, s, at)
+}
+
+// errorsF0Run runs errorsF0 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF0Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF0State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var c1 *rt.Value
+	var c2 *rt.Value
+	var c3 *rt.Value
+	var c4 *rt.Value
+	var c5 *rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var r5 rt.Value
+	var r6 rt.Value
+	var r7 rt.Value
+	var r8 rt.Value
+	var r9 rt.Value
+	var k3 *rt.Resumption
+	var k4 *rt.Resumption
+	var k5 *rt.Resumption
+	var k6 *rt.Resumption
+	var k7 *rt.Resumption
+	var k8 *rt.Resumption
+	var u2 []*rt.Value
+	var u3 []*rt.Value
+	var u5 []*rt.Value
+	var u6 []*rt.Value
+	var u7 []*rt.Value
+	var u8 []*rt.Value
+	var u9 []*rt.Value
+	var uc1 []*rt.Value
+	var uc5 []*rt.Value
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+		c1 = new(rt.Value)
+		c2 = new(rt.Value)
+		c3 = new(rt.Value)
+		c4 = new(rt.Value)
+		c5 = new(rt.Value)
+	} else {
+		r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5 = s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5
+		switch at {
+		case 13:
+			goto L13
+		case 16:
+			goto L16
+		case 19:
+			goto L19
+		case 33:
+			goto L33
+		case 36:
+			goto L36
+		case 39:
+			goto L39
+		case 51:
+			goto L51
+		case 54:
+			goto L54
+		case 57:
+			goto L57
+		case 66:
+			goto L66
+		case 69:
+			goto L69
+		case 75:
+			goto L75
+		case 78:
+			goto L78
+		case 91:
+			goto L91
+		case 93:
+			goto L93
+		case 97:
+			goto L97
+		case 106:
+			goto L106
+		case 109:
+			goto L109
+		case 118:
+			goto L118
+		case 122:
+			goto L122
+		case 128:
+			goto L128
+		case 134:
+			goto L134
+		case 140:
+			goto L140
+		case 144:
+			goto L144
+		case 152:
+			goto L152
+		case 155:
+			goto L155
+		}
+	}
+	t.RequireCPU(13)
+	_ = rt.ArrayValue(c.Etc())
+	if err = c.SetLine(t, 2); err != nil {
+		return nil, err
+	}
+	u2 = make([]*rt.Value, 1)
+	r2 = rt.FunctionValue(errorsF1(u2))
+	u2[0] = c0
+	if err = c.SetLine(t, 3); err != nil {
+		return nil, err
+	}
+	if r3, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k3 = rt.NewResumption(c, 0, false, s, 19)
+	if cont, err = rt.Continue(t, r3, k3); err != nil {
+		return nil, err
+	}
+	r3 = rt.ContValue(cont)
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, true, s, 16)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	r5 = rt.IntValue(2)
+	r4.AsCont().Push(t.Runtime, r5)
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 0, true, s, 13)
+	if cont, err = rt.Continue(t, r5, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r5.AsCont().Push(t.Runtime, r2)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r5.AsCont())
+L13:
+	t.RequireCPU(3)
+	r5 = rt.ArrayValue(k5.Etc())
+	r4.AsCont().PushEtc(t.Runtime, r5.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r4.AsCont())
+L16:
+	t.RequireCPU(3)
+	r4 = rt.ArrayValue(k4.Etc())
+	r3.AsCont().PushEtc(t.Runtime, r4.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r3.AsCont())
+L19:
+	t.RequireCPU(14)
+	if err = c.SetLine(t, 6); err != nil {
+		return nil, err
+	}
+	uc1 = make([]*rt.Value, 1)
+	*c1 = rt.FunctionValue(errorsF5(uc1))
+	uc1[0] = c0
+	if err = c.SetLine(t, 7); err != nil {
+		return nil, err
+	}
+	u3 = make([]*rt.Value, 1)
+	r3 = rt.FunctionValue(errorsF6(u3))
+	u3[0] = c1
+	if err = c.SetLine(t, 10); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, false, s, 39)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 0, true, s, 36)
+	if cont, err = rt.Continue(t, r5, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r6 = rt.IntValue(2)
+	r5.AsCont().Push(t.Runtime, r6)
+	if r6, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k6 = rt.NewResumption(c, 0, true, s, 33)
+	if cont, err = rt.Continue(t, r6, k6); err != nil {
+		return nil, err
+	}
+	r6 = rt.ContValue(cont)
+	r6.AsCont().Push(t.Runtime, r3)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r6.AsCont())
+L33:
+	t.RequireCPU(3)
+	r6 = rt.ArrayValue(k6.Etc())
+	r5.AsCont().PushEtc(t.Runtime, r6.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r5.AsCont())
+L36:
+	t.RequireCPU(3)
+	r5 = rt.ArrayValue(k5.Etc())
+	r4.AsCont().PushEtc(t.Runtime, r5.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r4.AsCont())
+L39:
+	t.RequireCPU(12)
+	if err = c.SetLine(t, 13); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, false, s, 57)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 0, true, s, 54)
+	if cont, err = rt.Continue(t, r5, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r6 = rt.IntValue(2)
+	r5.AsCont().Push(t.Runtime, r6)
+	if r6, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k6 = rt.NewResumption(c, 0, true, s, 51)
+	if cont, err = rt.Continue(t, r6, k6); err != nil {
+		return nil, err
+	}
+	r6 = rt.ContValue(cont)
+	u7 = make([]*rt.Value, 1)
+	r7 = rt.FunctionValue(errorsF7(u7))
+	u7[0] = c0
+	r6.AsCont().Push(t.Runtime, r7)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r6.AsCont())
+L51:
+	t.RequireCPU(3)
+	r6 = rt.ArrayValue(k6.Etc())
+	r5.AsCont().PushEtc(t.Runtime, r6.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r5.AsCont())
+L54:
+	t.RequireCPU(3)
+	r5 = rt.ArrayValue(k5.Etc())
+	r4.AsCont().PushEtc(t.Runtime, r5.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r4.AsCont())
+L57:
+	t.RequireCPU(9)
+	if err = c.SetLine(t, 17); err != nil {
+		return nil, err
+	}
+	r4 = rt.FunctionValue(errorsF8(nil))
+	if err = c.SetLine(t, 21); err != nil {
+		return nil, err
+	}
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 0, false, s, 69)
+	if cont, err = rt.Continue(t, r5, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	if r6, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k6 = rt.NewResumption(c, 0, true, s, 66)
+	if cont, err = rt.Continue(t, r6, k6); err != nil {
+		return nil, err
+	}
+	r6 = rt.ContValue(cont)
+	r6.AsCont().Push(t.Runtime, r4)
+	r7 = rt.TableValue(rt.NewTable())
+	r6.AsCont().Push(t.Runtime, r7)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r6.AsCont())
+L66:
+	t.RequireCPU(3)
+	r6 = rt.ArrayValue(k6.Etc())
+	r5.AsCont().PushEtc(t.Runtime, r6.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r5.AsCont())
+L69:
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 25); err != nil {
+		return nil, err
+	}
+	u5 = make([]*rt.Value, 1)
+	r5 = rt.FunctionValue(errorsF9(u5))
+	u5[0] = c0
+	if err = c.SetLine(t, 29); err != nil {
+		return nil, err
+	}
+	if r6, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k6 = rt.NewResumption(c, 0, false, s, 78)
+	if cont, err = rt.Continue(t, r6, k6); err != nil {
+		return nil, err
+	}
+	r6 = rt.ContValue(cont)
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, true, s, 75)
+	if cont, err = rt.Continue(t, r5, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L75:
+	t.RequireCPU(3)
+	r7 = rt.ArrayValue(k7.Etc())
+	r6.AsCont().PushEtc(t.Runtime, r7.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r6.AsCont())
+L78:
+	t.RequireCPU(13)
+	if err = c.SetLine(t, 33); err != nil {
+		return nil, err
+	}
+	*c2 = rt.TableValue(rt.NewTable())
+	if err = c.SetLine(t, 34); err != nil {
+		return nil, err
+	}
+	*c3 = rt.FunctionValue(errorsF10(nil))
+	if err = c.SetLine(t, 35); err != nil {
+		return nil, err
+	}
+	u6 = make([]*rt.Value, 1)
+	r6 = rt.FunctionValue(errorsF11(u6))
+	u6[0] = c3
+	if err = c.SetLine(t, 39); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("debug")); err != nil {
+		return nil, err
+	}
+	if r8, err = rt.Index(t, r7, rt.StringValue("sethook")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 91)
+	if cont, err = rt.Continue(t, r8, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	u8 = make([]*rt.Value, 1)
+	r8 = rt.FunctionValue(errorsF14(u8))
+	u8[0] = c2
+	r7.AsCont().Push(t.Runtime, r8)
+	if err = c.SetLine(t, 41); err != nil {
+		return nil, err
+	}
+	r8 = rt.StringValue("crl")
+	r7.AsCont().Push(t.Runtime, r8)
+	if err = c.SetLine(t, 39); err != nil {
+		return nil, err
+	}
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L91:
+	t.RequireCPU(2)
+	if err = c.SetLine(t, 42); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 93)
+	if cont, err = rt.Continue(t, r6, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L93:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 43); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("debug")); err != nil {
+		return nil, err
+	}
+	if r8, err = rt.Index(t, r7, rt.StringValue("sethook")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 97)
+	if cont, err = rt.Continue(t, r8, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L97:
+	t.RequireCPU(9)
+	if err = c.SetLine(t, 44); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 109)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	if r8, err = rt.Index(t, (*c0), rt.StringValue("table")); err != nil {
+		return nil, err
+	}
+	if r9, err = rt.Index(t, r8, rt.StringValue("concat")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k8 = rt.NewResumption(c, 0, true, s, 106)
+	if cont, err = rt.Continue(t, r9, k8); err != nil {
+		return nil, err
+	}
+	r8 = rt.ContValue(cont)
+	r8.AsCont().Push(t.Runtime, (*c2))
+	r9 = rt.StringValue(" ")
+	r8.AsCont().Push(t.Runtime, r9)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r8.AsCont())
+L106:
+	t.RequireCPU(3)
+	r8 = rt.ArrayValue(k8.Etc())
+	r7.AsCont().PushEtc(t.Runtime, r8.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L109:
+	t.RequireCPU(9)
+	if err = c.SetLine(t, 48); err != nil {
+		return nil, err
+	}
+	*c4 = rt.IntValue(0)
+	if err = c.SetLine(t, 49); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 122)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	if r8, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k8 = rt.NewResumption(c, 1, false, s, 118)
+	if cont, err = rt.Continue(t, r8, k8); err != nil {
+		return nil, err
+	}
+	r8 = rt.ContValue(cont)
+	u9 = make([]*rt.Value, 1)
+	r9 = rt.FunctionValue(errorsF19(u9))
+	u9[0] = c4
+	r8.AsCont().Push(t.Runtime, r9)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r8.AsCont())
+L118:
+	t.RequireCPU(4)
+	r8 = k8.Get(0)
+	r7.AsCont().Push(t.Runtime, r8)
+	r7.AsCont().Push(t.Runtime, (*c4))
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L122:
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 51); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 128)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	u8 = make([]*rt.Value, 1)
+	r8 = rt.FunctionValue(errorsF20(u8))
+	u8[0] = c4
+	r7.AsCont().Push(t.Runtime, r8)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L128:
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 52); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 134)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	u8 = make([]*rt.Value, 1)
+	r8 = rt.FunctionValue(errorsF21(u8))
+	u8[0] = c4
+	r7.AsCont().Push(t.Runtime, r8)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L134:
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 53); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 140)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	u8 = make([]*rt.Value, 1)
+	r8 = rt.FunctionValue(errorsF22(u8))
+	u8[0] = c4
+	r7.AsCont().Push(t.Runtime, r8)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L140:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 54); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 144)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	r7.AsCont().Push(t.Runtime, (*c4))
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L144:
+	t.RequireCPU(8)
+	if err = c.SetLine(t, 58); err != nil {
+		return nil, err
+	}
+	uc5 = make([]*rt.Value, 1)
+	*c5 = rt.FunctionValue(errorsF23(uc5))
+	uc5[0] = c5
+	if err = c.SetLine(t, 62); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 155)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	if s == nil {
+		s = &errorsF0State{c: c}
+	}
+	k8 = rt.NewResumption(c, 0, true, s, 152)
+	if cont, err = rt.Continue(t, (*c5), k8); err != nil {
+		return nil, err
+	}
+	r8 = rt.ContValue(cont)
+	r9 = rt.IntValue(100000)
+	r8.AsCont().Push(t.Runtime, r9)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r8.AsCont())
+L152:
+	t.RequireCPU(3)
+	r8 = rt.ArrayValue(k8.Etc())
+	r7.AsCont().PushEtc(t.Runtime, r8.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.c5, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.u2, s.u3, s.u5, s.u6, s.u7, s.u8, s.u9, s.uc1, s.uc5 = r0, c0, c1, c2, c3, c4, c5, r2, r3, r4, r5, r6, r7, r8, r9, k3, k4, k5, k6, k7, k8, u2, u3, u5, u6, u7, u8, u9, uc1, uc5
+	return c.Call(t, r7.AsCont())
+L155:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// errorsF1 implements function f [156 - 161].
+func errorsF1(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF1Run(t, c, ups, nil, 0)
+	}, "f", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF1State holds the variables of errorsF1 while it is suspended by a call.
+type errorsF1State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	k1 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF1State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF1Run(t, s.c, nil, s, at)
+}
+
+// errorsF1Run runs errorsF1 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF1Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF1State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var k1 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, k1 = s.r0, s.c0, s.r1, s.r2, s.k1
+		switch at {
+		case 5:
+			goto L5
+		}
+	}
+	t.RequireCPU(5)
+	if err = c.SetLine(t, 2); err != nil {
+		return nil, err
+	}
+	if r1, err = rt.Index(t, (*c0), rt.StringValue("error")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF1State{c: c}
+	}
+	k1 = rt.NewResumption(c, 0, false, s, 5)
+	if cont, err = rt.Continue(t, r1, k1); err != nil {
+		return nil, err
+	}
+	r1 = rt.ContValue(cont)
+	r2 = rt.StringValue("boom")
+	r1.AsCont().Push(t.Runtime, r2)
+	s.r0, s.c0, s.r1, s.r2, s.k1 = r0, c0, r1, r2, k1
+	return c.Call(t, r1.AsCont())
+L5:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// errorsF5 implements function g [162 - 169].
+func errorsF5(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF5Run(t, c, ups, nil, 0)
+	}, "g", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF5State holds the variables of errorsF5 while it is suspended by a call.
+type errorsF5State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	k1 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF5State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF5Run(t, s.c, nil, s, at)
+}
+
+// errorsF5Run runs errorsF5 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF5Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF5State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var k1 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, k1 = s.r0, s.c0, s.r1, s.r2, s.k1
+		switch at {
+		case 7:
+			goto L7
+		}
+	}
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 6); err != nil {
+		return nil, err
+	}
+	if r1, err = rt.Index(t, (*c0), rt.StringValue("error")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF5State{c: c}
+	}
+	k1 = rt.NewResumption(c, 0, false, s, 7)
+	if cont, err = rt.Continue(t, r1, k1); err != nil {
+		return nil, err
+	}
+	r1 = rt.ContValue(cont)
+	r2 = rt.StringValue("up")
+	r1.AsCont().Push(t.Runtime, r2)
+	r2 = rt.IntValue(2)
+	r1.AsCont().Push(t.Runtime, r2)
+	s.r0, s.c0, s.r1, s.r2, s.k1 = r0, c0, r1, r2, k1
+	return c.Call(t, r1.AsCont())
+L7:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// errorsF6 implements function callg [170 - 172].
+func errorsF6(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF6Run(t, c, ups, nil, 0)
+	}, "callg", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF6State holds the variables of errorsF6 while it is suspended by a call.
+type errorsF6State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	k1 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF6State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF6Run(t, s.c, nil, s, at)
+}
+
+// errorsF6Run runs errorsF6 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF6Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF6State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var k1 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, k1 = s.r0, s.c0, s.r1, s.k1
+		switch at {
+		case 2:
+			goto L2
+		}
+	}
+	t.RequireCPU(2)
+	if err = c.SetLine(t, 8); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF6State{c: c}
+	}
+	k1 = rt.NewResumption(c, 0, false, s, 2)
+	if cont, err = rt.Continue(t, (*c0), k1); err != nil {
+		return nil, err
+	}
+	r1 = rt.ContValue(cont)
+	s.r0, s.c0, s.r1, s.k1 = r0, c0, r1, k1
+	return c.Call(t, r1.AsCont())
+L2:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// errorsF7 implements function <anon> [173 - 180].
+func errorsF7(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF7Run(t, c, ups, nil, 0)
+	}, "", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF7State holds the variables of errorsF7 while it is suspended by a call.
+type errorsF7State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	k1 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF7State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF7Run(t, s.c, nil, s, at)
+}
+
+// errorsF7Run runs errorsF7 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF7Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF7State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var k1 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, k1 = s.r0, s.c0, s.r1, s.r2, s.k1
+		switch at {
+		case 7:
+			goto L7
+		}
+	}
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 13); err != nil {
+		return nil, err
+	}
+	if r1, err = rt.Index(t, (*c0), rt.StringValue("error")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF7State{c: c}
+	}
+	k1 = rt.NewResumption(c, 0, false, s, 7)
+	if cont, err = rt.Continue(t, r1, k1); err != nil {
+		return nil, err
+	}
+	r1 = rt.ContValue(cont)
+	r2 = rt.StringValue("nopos")
+	r1.AsCont().Push(t.Runtime, r2)
+	r2 = rt.IntValue(0)
+	r1.AsCont().Push(t.Runtime, r2)
+	s.r0, s.c0, s.r1, s.r2, s.k1 = r0, c0, r1, r2, k1
+	return c.Call(t, r1.AsCont())
+L7:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// errorsF8 implements function idx [181 - 185].
+func errorsF8(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2, r3 rt.Value
+		var err error
+		t.RequireCPU(5)
+		if err = c.SetLine(t, 17); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		if err = c.SetLine(t, 18); err != nil {
+			return nil, err
+		}
+		if r2, err = rt.Index(t, r1, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if r3, err = rt.Index(t, r2, rt.StringValue("y")); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r3)
+		return r0.AsCont(), nil
+	}, "idx", 1, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF9 implements function where [186 - 197].
+func errorsF9(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF9Run(t, c, ups, nil, 0)
+	}, "where", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF9State holds the variables of errorsF9 while it is suspended by a call.
+type errorsF9State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	k1 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF9State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF9Run(t, s.c, nil, s, at)
+}
+
+// errorsF9Run runs errorsF9 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF9Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF9State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var k1 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, k1 = s.r0, s.c0, s.r1, s.r2, s.k1
+		switch at {
+		case 6:
+			goto L6
+		}
+	}
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 26); err != nil {
+		return nil, err
+	}
+	if r1, err = rt.Index(t, (*c0), rt.StringValue("debug")); err != nil {
+		return nil, err
+	}
+	if r2, err = rt.Index(t, r1, rt.StringValue("getinfo")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF9State{c: c}
+	}
+	k1 = rt.NewResumption(c, 1, false, s, 6)
+	if cont, err = rt.Continue(t, r2, k1); err != nil {
+		return nil, err
+	}
+	r1 = rt.ContValue(cont)
+	r2 = rt.IntValue(1)
+	r1.AsCont().Push(t.Runtime, r2)
+	s.r0, s.c0, s.r1, s.r2, s.k1 = r0, c0, r1, r2, k1
+	return c.Call(t, r1.AsCont())
+L6:
+	t.RequireCPU(6)
+	r1 = k1.Get(0)
+	if err = c.SetLine(t, 27); err != nil {
+		return nil, err
+	}
+	if r2, err = rt.Index(t, r1, rt.StringValue("source")); err != nil {
+		return nil, err
+	}
+	r0.AsCont().Push(t.Runtime, r2)
+	if r2, err = rt.Index(t, r1, rt.StringValue("currentline")); err != nil {
+		return nil, err
+	}
+	r0.AsCont().Push(t.Runtime, r2)
+	return r0.AsCont(), nil
+}
+
+// errorsF10 implements function callee [198 - 200].
+func errorsF10(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1 rt.Value
+		var err error
+		t.RequireCPU(3)
+		if err = c.SetLine(t, 34); err != nil {
+			return nil, err
+		}
+		r1 = rt.IntValue(1)
+		r0.AsCont().Push(t.Runtime, r1)
+		return r0.AsCont(), nil
+	}, "callee", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF11 implements function caller [201 - 205].
+func errorsF11(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF11Run(t, c, ups, nil, 0)
+	}, "caller", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF11State holds the variables of errorsF11 while it is suspended by a call.
+type errorsF11State struct {
+	c  *rt.GoCont
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	k1 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF11State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF11Run(t, s.c, nil, s, at)
+}
+
+// errorsF11Run runs errorsF11 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF11Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF11State, at int) (rt.Cont, error) {
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var k1 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		c0 = ups[0]
+	} else {
+		c0, r1, r2, k1 = s.c0, s.r1, s.r2, s.k1
+		switch at {
+		case 2:
+			goto L2
+		}
+	}
+	t.RequireCPU(2)
+	if err = c.SetLine(t, 36); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &errorsF11State{c: c}
+	}
+	k1 = rt.NewResumption(c, 1, false, s, 2)
+	if cont, err = rt.Continue(t, (*c0), k1); err != nil {
+		return nil, err
+	}
+	r1 = rt.ContValue(cont)
+	s.c0, s.r1, s.r2, s.k1 = c0, r1, r2, k1
+	return c.Call(t, r1.AsCont())
+L2:
+	t.RequireCPU(3)
+	r1 = k1.Get(0)
+	if err = c.SetLine(t, 37); err != nil {
+		return nil, err
+	}
+	if cont, err = rt.Continue(t, (*c0), c.Next()); err != nil {
+		return nil, err
+	}
+	r2 = rt.ContValue(cont)
+	return c.TailCall(t, r2.AsCont())
+}
+
+// errorsF14 implements function <anon> [206 - 217].
+func errorsF14(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var r1, r2, r3, r4, r5, r6 rt.Value
+		var err error
+		t.RequireCPU(5)
+		if err = c.SetLine(t, 39); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		r2 = c.Arg(1)
+		if err = c.SetLine(t, 40); err != nil {
+			return nil, err
+		}
+		r3 = r2
+		if !rt.Truth(r3) {
+			goto L5
+		}
+		if r3, err = rt.BinaryOp(t, code.OpConcat, r1, r2); err != nil {
+			return nil, err
+		}
+	L5:
+		t.RequireCPU(2)
+		if err = c.SetLine(t, 40); err != nil {
+			return nil, err
+		}
+		if rt.Truth(r3) {
+			goto L7
+		}
+		r3 = r1
+	L7:
+		t.RequireCPU(5)
+		if err = c.SetLine(t, 40); err != nil {
+			return nil, err
+		}
+		r4 = (*c0)
+		if r5, err = rt.UnaryOp(t, code.OpLen, (*c0)); err != nil {
+			return nil, err
+		}
+		if v, ok := rt.Add(r5, rt.IntValue(1)); ok {
+			r6 = v
+		} else if r6, err = rt.BinaryOp(t, code.OpAdd, r5, rt.IntValue(1)); err != nil {
+			return nil, err
+		}
+		if err = rt.SetIndex(t, r4, r6, r3); err != nil {
+			return nil, err
+		}
+		return r0.AsCont(), nil
+	}, "", 2, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF19 implements function <anon> [218 - 221].
+func errorsF19(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var r1, r2, tmp rt.Value
+		var err error
+		t.RequireCPU(4)
+		if err = c.SetLine(t, 49); err != nil {
+			return nil, err
+		}
+		r1 = rt.IntValue(1)
+		r2 = rt.TableValue(rt.NewTable())
+		if v, ok := rt.Add(r1, r2); ok {
+			tmp = v
+		} else if tmp, err = rt.BinaryOp(t, code.OpAdd, r1, r2); err != nil {
+			return nil, err
+		}
+		*c0 = tmp
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF20 implements function <anon> [222 - 223].
+func errorsF20(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var tmp rt.Value
+		var err error
+		t.RequireCPU(2)
+		if err = c.SetLine(t, 51); err != nil {
+			return nil, err
+		}
+		if tmp, err = rt.Index(t, (*c0), rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		*c0 = tmp
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF21 implements function <anon> [224 - 226].
+func errorsF21(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var r1, tmp rt.Value
+		var err error
+		t.RequireCPU(3)
+		if err = c.SetLine(t, 52); err != nil {
+			return nil, err
+		}
+		r1 = rt.TableValue(rt.NewTable())
+		if tmp, err = rt.UnaryOp(t, code.OpNeg, r1); err != nil {
+			return nil, err
+		}
+		*c0 = tmp
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF22 implements function <anon> [227 - 231].
+func errorsF22(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var r1, r2, tmp rt.Value
+		var err error
+		t.RequireCPU(5)
+		if err = c.SetLine(t, 53); err != nil {
+			return nil, err
+		}
+		r1 = rt.TableValue(rt.NewTable())
+		r2 = rt.NilValue
+		if r2, err = rt.Index(t, r1, r2); err != nil {
+			return nil, err
+		}
+		if tmp, err = rt.Index(t, r2, rt.StringValue("y")); err != nil {
+			return nil, err
+		}
+		*c0 = tmp
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF23 implements function depth [232 - 247].
+func errorsF23(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return errorsF23Run(t, c, ups, nil, 0)
+	}, "depth", 1, false)
+	f.SetLuaSource(errorsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// errorsF23State holds the variables of errorsF23 while it is suspended by a call.
+type errorsF23State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	r3 rt.Value
+	r4 rt.Value
+	k3 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *errorsF23State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return errorsF23Run(t, s.c, nil, s, at)
+}
+
+// errorsF23Run runs errorsF23 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func errorsF23Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *errorsF23State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var k3 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, r3, r4, k3 = s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k3
+		switch at {
+		case 12:
+			goto L12
+		}
+	}
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 58); err != nil {
+		return nil, err
+	}
+	r1 = c.Arg(0)
+	if err = c.SetLine(t, 59); err != nil {
+		return nil, err
+	}
+	r2 = rt.IntValue(0)
+	if r2, err = rt.BinaryOp(t, code.OpEq, r1, r2); err != nil {
+		return nil, err
+	}
+	if !rt.Truth(r2) {
+		goto L7
+	}
+	r2 = rt.IntValue(0)
+	r0.AsCont().Push(t.Runtime, r2)
+	return r0.AsCont(), nil
+L7:
+	t.RequireCPU(5)
+	if err = c.SetLine(t, 60); err != nil {
+		return nil, err
+	}
+	r2 = rt.IntValue(1)
+	if s == nil {
+		s = &errorsF23State{c: c}
+	}
+	k3 = rt.NewResumption(c, 1, false, s, 12)
+	if cont, err = rt.Continue(t, (*c0), k3); err != nil {
+		return nil, err
+	}
+	r3 = rt.ContValue(cont)
+	if v, ok := rt.Sub(r1, rt.IntValue(1)); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpSub, r1, rt.IntValue(1)); err != nil {
+		return nil, err
+	}
+	r3.AsCont().Push(t.Runtime, r4)
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k3 = r0, c0, r1, r2, r3, r4, k3
+	return c.Call(t, r3.AsCont())
+L12:
+	t.RequireCPU(4)
+	r3 = k3.Get(0)
+	if v, ok := rt.Add(r2, r3); ok {
+		r3 = v
+	} else if r3, err = rt.BinaryOp(t, code.OpAdd, r2, r3); err != nil {
+		return nil, err
+	}
+	r0.AsCont().Push(t.Runtime, r3)
+	return r0.AsCont(), nil
+}
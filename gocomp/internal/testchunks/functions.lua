@@ -0,0 +1,106 @@
+-- Calls, multiple results, varargs
+local function sum(...)
+    local s = 0
+    for i = 1, select("#", ...) do s = s + select(i, ...) end
+    return s, select("#", ...)
+end
+print(sum(1, 2, 3, 4))
+--> =10	4
+
+local function pack(...) return {...} end
+local function first(x) return x end
+local t = pack(1, 2, 3)
+print(#t, first(2, 3), first())
+--> =3	2	nil
+
+local function multi() return 1, 2, 3 end
+print(multi(), multi())
+--> =1	1	2	3
+
+print((multi()))
+--> =1
+
+local function tail(...)
+    local a, b = ...
+    return b, a, select("#", ...)
+end
+print(tail("x", "y", "z"))
+--> =y	x	3
+
+-- Closures and upvalues
+local function counter()
+    local n = 0
+    return function() n = n + 1; return n end
+end
+local c1, c2 = counter(), counter()
+c1(); c1()
+print(c1(), c2())
+--> =3	1
+
+local fs = {}
+for i = 1, 3 do fs[i] = function() return i end end
+print(fs[1](), fs[2](), fs[3]())
+--> =1	2	3
+
+local x = 1
+local function setx(v) x = v end
+setx(42)
+print(x)
+--> =42
+
+-- Recursion and tail calls
+local function fib(n)
+    if n < 2 then return n end
+    return fib(n - 1) + fib(n - 2)
+end
+print(fib(20))
+--> =6765
+
+local function loop(n, acc)
+    if n == 0 then return acc end
+    return loop(n - 1, acc + 1)
+end
+print(loop(100000, 0))
+--> =100000
+
+-- Metamethods
+local V = {}
+V.__index = V
+V.__add = function(p, q) return setmetatable({x = p.x + q.x}, V) end
+V.__eq = function(p, q) return p.x == q.x end
+V.__lt = function(p, q) return p.x < q.x end
+V.__call = function(self, y) return self.x * y end
+V.__len = function() return 99 end
+function V.new(x) return setmetatable({x = x}, V) end
+function V:get() return self.x end
+local p = V.new(1) + V.new(2)
+print(p:get(), p == V.new(3), V.new(1) < p, p(10), #p)
+--> =3	true	true	30	99
+
+-- Errors and pcall
+local ok, err = pcall(error, {code = 1})
+print(ok, err.code)
+--> =false	1
+
+print(select(2, pcall(function() error("boom", 0) end)))
+--> =boom
+
+-- Coroutines can yield from compiled functions
+local co = coroutine.wrap(function(a)
+    local b = coroutine.yield(a + 1)
+    local c = coroutine.yield(b * 2)
+    return a + b + c
+end)
+print(co(1), co(10), co(100))
+--> =2	20	111
+
+local gen = coroutine.wrap(function()
+    for i = 1, 3 do coroutine.yield(i) end
+end)
+print(gen(), gen(), gen())
+--> =1	2	3
+
+-- Calling interpreted code and back
+local f = load("local g = ... return g(5) + 1")
+print(f(function(n) return n * n end))
+--> =26
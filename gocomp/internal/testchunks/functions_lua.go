@@ -0,0 +1,2559 @@
+// Code generated by golua from functions.lua. DO NOT EDIT.
+
+package testchunks
+
+import (
+	"github.com/arnodel/golua/code"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Functions returns the main chunk of functions.lua compiled to Go, with env as the value of
+// _ENV.
+func Functions(env rt.Value) *rt.GoFunction {
+	return functionsF0([]*rt.Value{&env})
+}
+
+const functionsSource = "functions.lua"
+
+// functionsEtc returns the value at index i in etc (or nil).
+func functionsEtc(etc rt.Value, i int) rt.Value {
+	if vals := etc.AsArray(); i < len(vals) {
+		return vals[i]
+	}
+	return rt.NilValue
+}
+
+// functionsF0 implements function <main chunk> [0 - 348].
+func functionsF0(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return functionsF0Run(t, c, ups, nil, 0)
+	}, "<main chunk>", 0, true)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF0State holds the variables of functionsF0 while it is suspended by a call.
+type functionsF0State struct {
+	c   *rt.GoCont
+	r0  rt.Value
+	c0  *rt.Value
+	c1  *rt.Value
+	c2  *rt.Value
+	c3  *rt.Value
+	c4  *rt.Value
+	r2  rt.Value
+	r3  rt.Value
+	r4  rt.Value
+	r5  rt.Value
+	r6  rt.Value
+	r7  rt.Value
+	r8  rt.Value
+	r9  rt.Value
+	r10 rt.Value
+	r11 rt.Value
+	r12 rt.Value
+	r13 rt.Value
+	r14 rt.Value
+	r15 rt.Value
+	r16 rt.Value
+	r17 rt.Value
+	r18 rt.Value
+	r19 rt.Value
+	r20 rt.Value
+	r21 rt.Value
+	k3  *rt.Resumption
+	k4  *rt.Resumption
+	k5  *rt.Resumption
+	k6  *rt.Resumption
+	k7  *rt.Resumption
+	k8  *rt.Resumption
+	k9  *rt.Resumption
+	k10 *rt.Resumption
+	k11 *rt.Resumption
+	k12 *rt.Resumption
+	k13 *rt.Resumption
+	k14 *rt.Resumption
+	k15 *rt.Resumption
+	k16 *rt.Resumption
+	k17 *rt.Resumption
+	k18 *rt.Resumption
+	k19 *rt.Resumption
+	k20 *rt.Resumption
+	u12 []*rt.Value
+	u13 []*rt.Value
+	u15 []*rt.Value
+	u17 []*rt.Value
+	u18 []*rt.Value
+	u19 []*rt.Value
+	u2  []*rt.Value
+	u7  []*rt.Value
+	uc2 []*rt.Value
+	uc3 []*rt.Value
+}
+
+// Resume implements rt.Resumable.
+func (s *functionsF0State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return functionsF0Run(t, s.c, nil, s, at)
+}
+
+// functionsF0Run runs functionsF0 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func functionsF0Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *functionsF0State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var c1 *rt.Value
+	var c2 *rt.Value
+	var c3 *rt.Value
+	var c4 *rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var r5 rt.Value
+	var r6 rt.Value
+	var r7 rt.Value
+	var r8 rt.Value
+	var r9 rt.Value
+	var r10 rt.Value
+	var r11 rt.Value
+	var r12 rt.Value
+	var r13 rt.Value
+	var r14 rt.Value
+	var r15 rt.Value
+	var r16 rt.Value
+	var r17 rt.Value
+	var r18 rt.Value
+	var r19 rt.Value
+	var r20 rt.Value
+	var r21 rt.Value
+	var k3 *rt.Resumption
+	var k4 *rt.Resumption
+	var k5 *rt.Resumption
+	var k6 *rt.Resumption
+	var k7 *rt.Resumption
+	var k8 *rt.Resumption
+	var k9 *rt.Resumption
+	var k10 *rt.Resumption
+	var k11 *rt.Resumption
+	var k12 *rt.Resumption
+	var k13 *rt.Resumption
+	var k14 *rt.Resumption
+	var k15 *rt.Resumption
+	var k16 *rt.Resumption
+	var k17 *rt.Resumption
+	var k18 *rt.Resumption
+	var k19 *rt.Resumption
+	var k20 *rt.Resumption
+	var u12 []*rt.Value
+	var u13 []*rt.Value
+	var u15 []*rt.Value
+	var u17 []*rt.Value
+	var u18 []*rt.Value
+	var u19 []*rt.Value
+	var u2 []*rt.Value
+	var u7 []*rt.Value
+	var uc2 []*rt.Value
+	var uc3 []*rt.Value
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+		c1 = new(rt.Value)
+		c2 = new(rt.Value)
+		c3 = new(rt.Value)
+		c4 = new(rt.Value)
+	} else {
+		r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3 = s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3
+		switch at {
+		case 15:
+			goto L15
+		case 18:
+			goto L18
+		case 28:
+			goto L28
+		case 39:
+			goto L39
+		case 43:
+			goto L43
+		case 46:
+			goto L46
+		case 51:
+			goto L51
+		case 55:
+			goto L55
+		case 58:
+			goto L58
+		case 62:
+			goto L62
+		case 65:
+			goto L65
+		case 77:
+			goto L77
+		case 80:
+			goto L80
+		case 83:
+			goto L83
+		case 86:
+			goto L86
+		case 89:
+			goto L89
+		case 91:
+			goto L91
+		case 95:
+			goto L95
+		case 99:
+			goto L99
+		case 102:
+			goto L102
+		case 121:
+			goto L121
+		case 127:
+			goto L127
+		case 133:
+			goto L133
+		case 136:
+			goto L136
+		case 143:
+			goto L143
+		case 147:
+			goto L147
+		case 155:
+			goto L155
+		case 158:
+			goto L158
+		case 168:
+			goto L168
+		case 171:
+			goto L171
+		case 205:
+			goto L205
+		case 211:
+			goto L211
+		case 219:
+			goto L219
+		case 226:
+			goto L226
+		case 234:
+			goto L234
+		case 241:
+			goto L241
+		case 246:
+			goto L246
+		case 255:
+			goto L255
+		case 263:
+			goto L263
+		case 275:
+			goto L275
+		case 278:
+			goto L278
+		case 281:
+			goto L281
+		case 288:
+			goto L288
+		case 295:
+			goto L295
+		case 301:
+			goto L301
+		case 307:
+			goto L307
+		case 310:
+			goto L310
+		case 317:
+			goto L317
+		case 322:
+			goto L322
+		case 326:
+			goto L326
+		case 330:
+			goto L330
+		case 333:
+			goto L333
+		case 338:
+			goto L338
+		case 345:
+			goto L345
+		case 348:
+			goto L348
+		}
+	}
+	t.RequireCPU(15)
+	_ = rt.ArrayValue(c.Etc())
+	if err = c.SetLine(t, 2); err != nil {
+		return nil, err
+	}
+	u2 = make([]*rt.Value, 1)
+	r2 = rt.FunctionValue(functionsF1(u2))
+	u2[0] = c0
+	if err = c.SetLine(t, 7); err != nil {
+		return nil, err
+	}
+	if r3, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k3 = rt.NewResumption(c, 0, false, s, 18)
+	if cont, err = rt.Continue(t, r3, k3); err != nil {
+		return nil, err
+	}
+	r3 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, true, s, 15)
+	if cont, err = rt.Continue(t, r2, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	r5 = rt.IntValue(1)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(2)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(3)
+	r4.AsCont().Push(t.Runtime, r5)
+	r5 = rt.IntValue(4)
+	r4.AsCont().Push(t.Runtime, r5)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r4.AsCont())
+L15:
+	t.RequireCPU(3)
+	r4 = rt.ArrayValue(k4.Etc())
+	r3.AsCont().PushEtc(t.Runtime, r4.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r3.AsCont())
+L18:
+	t.RequireCPU(10)
+	if err = c.SetLine(t, 10); err != nil {
+		return nil, err
+	}
+	r3 = rt.FunctionValue(functionsF3(nil))
+	if err = c.SetLine(t, 11); err != nil {
+		return nil, err
+	}
+	r4 = rt.FunctionValue(functionsF4(nil))
+	if err = c.SetLine(t, 12); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k5 = rt.NewResumption(c, 1, false, s, 28)
+	if cont, err = rt.Continue(t, r3, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r6 = rt.IntValue(1)
+	r5.AsCont().Push(t.Runtime, r6)
+	r6 = rt.IntValue(2)
+	r5.AsCont().Push(t.Runtime, r6)
+	r6 = rt.IntValue(3)
+	r5.AsCont().Push(t.Runtime, r6)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r5.AsCont())
+L28:
+	t.RequireCPU(11)
+	r5 = k5.Get(0)
+	if err = c.SetLine(t, 13); err != nil {
+		return nil, err
+	}
+	if r6, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k6 = rt.NewResumption(c, 0, false, s, 46)
+	if cont, err = rt.Continue(t, r6, k6); err != nil {
+		return nil, err
+	}
+	r6 = rt.ContValue(cont)
+	if r7, err = rt.UnaryOp(t, code.OpLen, r5); err != nil {
+		return nil, err
+	}
+	r6.AsCont().Push(t.Runtime, r7)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 1, false, s, 39)
+	if cont, err = rt.Continue(t, r4, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	r8 = rt.IntValue(2)
+	r7.AsCont().Push(t.Runtime, r8)
+	r8 = rt.IntValue(3)
+	r7.AsCont().Push(t.Runtime, r8)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r7.AsCont())
+L39:
+	t.RequireCPU(4)
+	r7 = k7.Get(0)
+	r6.AsCont().Push(t.Runtime, r7)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, true, s, 43)
+	if cont, err = rt.Continue(t, r4, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r7.AsCont())
+L43:
+	t.RequireCPU(3)
+	r7 = rt.ArrayValue(k7.Etc())
+	r6.AsCont().PushEtc(t.Runtime, r7.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r6.AsCont())
+L46:
+	t.RequireCPU(5)
+	if err = c.SetLine(t, 16); err != nil {
+		return nil, err
+	}
+	r6 = rt.FunctionValue(functionsF5(nil))
+	if err = c.SetLine(t, 17); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 58)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k8 = rt.NewResumption(c, 1, false, s, 51)
+	if cont, err = rt.Continue(t, r6, k8); err != nil {
+		return nil, err
+	}
+	r8 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r8.AsCont())
+L51:
+	t.RequireCPU(4)
+	r8 = k8.Get(0)
+	r7.AsCont().Push(t.Runtime, r8)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k8 = rt.NewResumption(c, 0, true, s, 55)
+	if cont, err = rt.Continue(t, r6, k8); err != nil {
+		return nil, err
+	}
+	r8 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r8.AsCont())
+L55:
+	t.RequireCPU(3)
+	r8 = rt.ArrayValue(k8.Etc())
+	r7.AsCont().PushEtc(t.Runtime, r8.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r7.AsCont())
+L58:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 20); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k7 = rt.NewResumption(c, 0, false, s, 65)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k8 = rt.NewResumption(c, 1, false, s, 62)
+	if cont, err = rt.Continue(t, r6, k8); err != nil {
+		return nil, err
+	}
+	r8 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r8.AsCont())
+L62:
+	t.RequireCPU(3)
+	r8 = k8.Get(0)
+	r7.AsCont().Push(t.Runtime, r8)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r7.AsCont())
+L65:
+	t.RequireCPU(12)
+	if err = c.SetLine(t, 23); err != nil {
+		return nil, err
+	}
+	u7 = make([]*rt.Value, 1)
+	r7 = rt.FunctionValue(functionsF6(u7))
+	u7[0] = c0
+	if err = c.SetLine(t, 27); err != nil {
+		return nil, err
+	}
+	if r8, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k8 = rt.NewResumption(c, 0, false, s, 80)
+	if cont, err = rt.Continue(t, r8, k8); err != nil {
+		return nil, err
+	}
+	r8 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k9 = rt.NewResumption(c, 0, true, s, 77)
+	if cont, err = rt.Continue(t, r7, k9); err != nil {
+		return nil, err
+	}
+	r9 = rt.ContValue(cont)
+	r10 = rt.StringValue("x")
+	r9.AsCont().Push(t.Runtime, r10)
+	r10 = rt.StringValue("y")
+	r9.AsCont().Push(t.Runtime, r10)
+	r10 = rt.StringValue("z")
+	r9.AsCont().Push(t.Runtime, r10)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r9.AsCont())
+L77:
+	t.RequireCPU(3)
+	r9 = rt.ArrayValue(k9.Etc())
+	r8.AsCont().PushEtc(t.Runtime, r9.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r8.AsCont())
+L80:
+	t.RequireCPU(3)
+	if err = c.SetLine(t, 31); err != nil {
+		return nil, err
+	}
+	r8 = rt.FunctionValue(functionsF10(nil))
+	if err = c.SetLine(t, 35); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k9 = rt.NewResumption(c, 1, false, s, 83)
+	if cont, err = rt.Continue(t, r8, k9); err != nil {
+		return nil, err
+	}
+	r9 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r9.AsCont())
+L83:
+	t.RequireCPU(3)
+	r9 = k9.Get(0)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k10 = rt.NewResumption(c, 1, false, s, 86)
+	if cont, err = rt.Continue(t, r8, k10); err != nil {
+		return nil, err
+	}
+	r10 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r10.AsCont())
+L86:
+	t.RequireCPU(3)
+	r10 = k10.Get(0)
+	if err = c.SetLine(t, 36); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k11 = rt.NewResumption(c, 0, false, s, 89)
+	if cont, err = rt.Continue(t, r9, k11); err != nil {
+		return nil, err
+	}
+	r11 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r11.AsCont())
+L89:
+	t.RequireCPU(2)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k11 = rt.NewResumption(c, 0, false, s, 91)
+	if cont, err = rt.Continue(t, r9, k11); err != nil {
+		return nil, err
+	}
+	r11 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r11.AsCont())
+L91:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 37); err != nil {
+		return nil, err
+	}
+	if r11, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k11 = rt.NewResumption(c, 0, false, s, 102)
+	if cont, err = rt.Continue(t, r11, k11); err != nil {
+		return nil, err
+	}
+	r11 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k12 = rt.NewResumption(c, 1, false, s, 95)
+	if cont, err = rt.Continue(t, r9, k12); err != nil {
+		return nil, err
+	}
+	r12 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r12.AsCont())
+L95:
+	t.RequireCPU(4)
+	r12 = k12.Get(0)
+	r11.AsCont().Push(t.Runtime, r12)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k12 = rt.NewResumption(c, 0, true, s, 99)
+	if cont, err = rt.Continue(t, r10, k12); err != nil {
+		return nil, err
+	}
+	r12 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r12.AsCont())
+L99:
+	t.RequireCPU(3)
+	r12 = rt.ArrayValue(k12.Etc())
+	r11.AsCont().PushEtc(t.Runtime, r12.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r11.AsCont())
+L102:
+	t.RequireCPU(5)
+	if err = c.SetLine(t, 40); err != nil {
+		return nil, err
+	}
+	r11 = rt.TableValue(rt.NewTable())
+	if err = c.SetLine(t, 41); err != nil {
+		return nil, err
+	}
+	r12 = rt.IntValue(1)
+	r13 = rt.IntValue(3)
+	r14 = rt.IntValue(1)
+	if r12, r13, r14, err = rt.PrepForLoop(r12, r13, r14); err != nil {
+		return nil, err
+	}
+L107:
+	t.RequireCPU(8)
+	if !rt.Truth(r12) {
+		goto L115
+	}
+	*c1 = r12
+	if err = c.SetLine(t, 41); err != nil {
+		return nil, err
+	}
+	u15 = make([]*rt.Value, 1)
+	r15 = rt.FunctionValue(functionsF11(u15))
+	u15[0] = c1
+	if err = rt.SetIndex(t, r11, (*c1), r15); err != nil {
+		return nil, err
+	}
+	c1 = new(rt.Value)
+	r12 = rt.AdvForLoop(r12, r13, rt.IntValue(1))
+	if rt.Truth(r12) {
+		goto L107
+	}
+L115:
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 42); err != nil {
+		return nil, err
+	}
+	if r12, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k12 = rt.NewResumption(c, 0, false, s, 136)
+	if cont, err = rt.Continue(t, r12, k12); err != nil {
+		return nil, err
+	}
+	r12 = rt.ContValue(cont)
+	r13 = rt.IntValue(1)
+	if r13, err = rt.Index(t, r11, r13); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 1, false, s, 121)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L121:
+	t.RequireCPU(6)
+	r13 = k13.Get(0)
+	r12.AsCont().Push(t.Runtime, r13)
+	r13 = rt.IntValue(2)
+	if r13, err = rt.Index(t, r11, r13); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 1, false, s, 127)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L127:
+	t.RequireCPU(6)
+	r13 = k13.Get(0)
+	r12.AsCont().Push(t.Runtime, r13)
+	r13 = rt.IntValue(3)
+	if r13, err = rt.Index(t, r11, r13); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 0, true, s, 133)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L133:
+	t.RequireCPU(3)
+	r13 = rt.ArrayValue(k13.Etc())
+	r12.AsCont().PushEtc(t.Runtime, r13.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r12.AsCont())
+L136:
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 45); err != nil {
+		return nil, err
+	}
+	*c1 = rt.IntValue(1)
+	if err = c.SetLine(t, 46); err != nil {
+		return nil, err
+	}
+	u12 = make([]*rt.Value, 1)
+	r12 = rt.FunctionValue(functionsF12(u12))
+	u12[0] = c1
+	if err = c.SetLine(t, 47); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 0, false, s, 143)
+	if cont, err = rt.Continue(t, r12, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	r14 = rt.IntValue(42)
+	r13.AsCont().Push(t.Runtime, r14)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L143:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 48); err != nil {
+		return nil, err
+	}
+	if r13, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 0, false, s, 147)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	r13.AsCont().Push(t.Runtime, (*c1))
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L147:
+	t.RequireCPU(8)
+	if err = c.SetLine(t, 52); err != nil {
+		return nil, err
+	}
+	uc2 = make([]*rt.Value, 1)
+	*c2 = rt.FunctionValue(functionsF13(uc2))
+	uc2[0] = c2
+	if err = c.SetLine(t, 56); err != nil {
+		return nil, err
+	}
+	if r13, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 0, false, s, 158)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k14 = rt.NewResumption(c, 0, true, s, 155)
+	if cont, err = rt.Continue(t, (*c2), k14); err != nil {
+		return nil, err
+	}
+	r14 = rt.ContValue(cont)
+	r15 = rt.IntValue(20)
+	r14.AsCont().Push(t.Runtime, r15)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r14.AsCont())
+L155:
+	t.RequireCPU(3)
+	r14 = rt.ArrayValue(k14.Etc())
+	r13.AsCont().PushEtc(t.Runtime, r14.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L158:
+	t.RequireCPU(10)
+	if err = c.SetLine(t, 59); err != nil {
+		return nil, err
+	}
+	uc3 = make([]*rt.Value, 1)
+	*c3 = rt.FunctionValue(functionsF14(uc3))
+	uc3[0] = c3
+	if err = c.SetLine(t, 63); err != nil {
+		return nil, err
+	}
+	if r13, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 0, false, s, 171)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k14 = rt.NewResumption(c, 0, true, s, 168)
+	if cont, err = rt.Continue(t, (*c3), k14); err != nil {
+		return nil, err
+	}
+	r14 = rt.ContValue(cont)
+	r15 = rt.IntValue(100000)
+	r14.AsCont().Push(t.Runtime, r15)
+	r15 = rt.IntValue(0)
+	r14.AsCont().Push(t.Runtime, r15)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r14.AsCont())
+L168:
+	t.RequireCPU(3)
+	r14 = rt.ArrayValue(k14.Etc())
+	r13.AsCont().PushEtc(t.Runtime, r14.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L171:
+	t.RequireCPU(34)
+	if err = c.SetLine(t, 67); err != nil {
+		return nil, err
+	}
+	*c4 = rt.TableValue(rt.NewTable())
+	if err = c.SetLine(t, 68); err != nil {
+		return nil, err
+	}
+	r13 = (*c4)
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("__index"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 69); err != nil {
+		return nil, err
+	}
+	u13 = make([]*rt.Value, 2)
+	r13 = rt.FunctionValue(functionsF17(u13))
+	u13[0] = c0
+	u13[1] = c4
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("__add"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 70); err != nil {
+		return nil, err
+	}
+	r13 = rt.FunctionValue(functionsF19(nil))
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("__eq"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 71); err != nil {
+		return nil, err
+	}
+	r13 = rt.FunctionValue(functionsF21(nil))
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("__lt"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 72); err != nil {
+		return nil, err
+	}
+	r13 = rt.FunctionValue(functionsF23(nil))
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("__call"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 73); err != nil {
+		return nil, err
+	}
+	r13 = rt.FunctionValue(functionsF25(nil))
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("__len"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 74); err != nil {
+		return nil, err
+	}
+	u13 = make([]*rt.Value, 2)
+	r13 = rt.FunctionValue(functionsF27(u13))
+	u13[0] = c0
+	u13[1] = c4
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("new"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 75); err != nil {
+		return nil, err
+	}
+	r13 = rt.FunctionValue(functionsF29(nil))
+	r14 = (*c4)
+	if err = rt.SetIndex(t, r14, rt.StringValue("get"), r13); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 76); err != nil {
+		return nil, err
+	}
+	if r13, err = rt.Index(t, (*c4), rt.StringValue("new")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 1, false, s, 205)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	r14 = rt.IntValue(1)
+	r13.AsCont().Push(t.Runtime, r14)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L205:
+	t.RequireCPU(6)
+	r13 = k13.Get(0)
+	if r14, err = rt.Index(t, (*c4), rt.StringValue("new")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k14 = rt.NewResumption(c, 1, false, s, 211)
+	if cont, err = rt.Continue(t, r14, k14); err != nil {
+		return nil, err
+	}
+	r14 = rt.ContValue(cont)
+	r15 = rt.IntValue(2)
+	r14.AsCont().Push(t.Runtime, r15)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r14.AsCont())
+L211:
+	t.RequireCPU(8)
+	r14 = k14.Get(0)
+	if v, ok := rt.Add(r13, r14); ok {
+		r14 = v
+	} else if r14, err = rt.BinaryOp(t, code.OpAdd, r13, r14); err != nil {
+		return nil, err
+	}
+	if err = c.SetLine(t, 77); err != nil {
+		return nil, err
+	}
+	if r13, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k13 = rt.NewResumption(c, 0, false, s, 246)
+	if cont, err = rt.Continue(t, r13, k13); err != nil {
+		return nil, err
+	}
+	r13 = rt.ContValue(cont)
+	if r15, err = rt.Index(t, r14, rt.StringValue("get")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k15 = rt.NewResumption(c, 1, false, s, 219)
+	if cont, err = rt.Continue(t, r15, k15); err != nil {
+		return nil, err
+	}
+	r15 = rt.ContValue(cont)
+	r15.AsCont().Push(t.Runtime, r14)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r15.AsCont())
+L219:
+	t.RequireCPU(7)
+	r15 = k15.Get(0)
+	r13.AsCont().Push(t.Runtime, r15)
+	if r15, err = rt.Index(t, (*c4), rt.StringValue("new")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k15 = rt.NewResumption(c, 1, false, s, 226)
+	if cont, err = rt.Continue(t, r15, k15); err != nil {
+		return nil, err
+	}
+	r15 = rt.ContValue(cont)
+	r16 = rt.IntValue(3)
+	r15.AsCont().Push(t.Runtime, r16)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r15.AsCont())
+L226:
+	t.RequireCPU(8)
+	r15 = k15.Get(0)
+	if r15, err = rt.BinaryOp(t, code.OpEq, r14, r15); err != nil {
+		return nil, err
+	}
+	r13.AsCont().Push(t.Runtime, r15)
+	if r15, err = rt.Index(t, (*c4), rt.StringValue("new")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k15 = rt.NewResumption(c, 1, false, s, 234)
+	if cont, err = rt.Continue(t, r15, k15); err != nil {
+		return nil, err
+	}
+	r15 = rt.ContValue(cont)
+	r16 = rt.IntValue(1)
+	r15.AsCont().Push(t.Runtime, r16)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r15.AsCont())
+L234:
+	t.RequireCPU(7)
+	r15 = k15.Get(0)
+	if r16, err = rt.BinaryOp(t, code.OpLt, r15, r14); err != nil {
+		return nil, err
+	}
+	r13.AsCont().Push(t.Runtime, r16)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k15 = rt.NewResumption(c, 1, false, s, 241)
+	if cont, err = rt.Continue(t, r14, k15); err != nil {
+		return nil, err
+	}
+	r15 = rt.ContValue(cont)
+	r16 = rt.IntValue(10)
+	r15.AsCont().Push(t.Runtime, r16)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r15.AsCont())
+L241:
+	t.RequireCPU(5)
+	r15 = k15.Get(0)
+	r13.AsCont().Push(t.Runtime, r15)
+	if r15, err = rt.UnaryOp(t, code.OpLen, r14); err != nil {
+		return nil, err
+	}
+	r13.AsCont().Push(t.Runtime, r15)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r13.AsCont())
+L246:
+	t.RequireCPU(9)
+	if err = c.SetLine(t, 81); err != nil {
+		return nil, err
+	}
+	if r16, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k16 = rt.NewResumption(c, 2, false, s, 255)
+	if cont, err = rt.Continue(t, r16, k16); err != nil {
+		return nil, err
+	}
+	r16 = rt.ContValue(cont)
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("error")); err != nil {
+		return nil, err
+	}
+	r16.AsCont().Push(t.Runtime, r17)
+	r17 = rt.TableValue(t.NewTableSize(0, 1))
+	r18 = rt.IntValue(1)
+	if err = rt.SetIndex(t, r17, rt.StringValue("code"), r18); err != nil {
+		return nil, err
+	}
+	r16.AsCont().Push(t.Runtime, r17)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r16.AsCont())
+L255:
+	t.RequireCPU(8)
+	r13 = k16.Get(0)
+	r15 = k16.Get(1)
+	if err = c.SetLine(t, 82); err != nil {
+		return nil, err
+	}
+	if r16, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k16 = rt.NewResumption(c, 0, false, s, 263)
+	if cont, err = rt.Continue(t, r16, k16); err != nil {
+		return nil, err
+	}
+	r16 = rt.ContValue(cont)
+	r16.AsCont().Push(t.Runtime, r13)
+	if r17, err = rt.Index(t, r15, rt.StringValue("code")); err != nil {
+		return nil, err
+	}
+	r16.AsCont().Push(t.Runtime, r17)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r16.AsCont())
+L263:
+	t.RequireCPU(12)
+	if err = c.SetLine(t, 85); err != nil {
+		return nil, err
+	}
+	if r16, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k16 = rt.NewResumption(c, 0, false, s, 281)
+	if cont, err = rt.Continue(t, r16, k16); err != nil {
+		return nil, err
+	}
+	r16 = rt.ContValue(cont)
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k17 = rt.NewResumption(c, 0, true, s, 278)
+	if cont, err = rt.Continue(t, r17, k17); err != nil {
+		return nil, err
+	}
+	r17 = rt.ContValue(cont)
+	r18 = rt.IntValue(2)
+	r17.AsCont().Push(t.Runtime, r18)
+	if r18, err = rt.Index(t, (*c0), rt.StringValue("pcall")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 0, true, s, 275)
+	if cont, err = rt.Continue(t, r18, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	u19 = make([]*rt.Value, 1)
+	r19 = rt.FunctionValue(functionsF35(u19))
+	u19[0] = c0
+	r18.AsCont().Push(t.Runtime, r19)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r18.AsCont())
+L275:
+	t.RequireCPU(3)
+	r18 = rt.ArrayValue(k18.Etc())
+	r17.AsCont().PushEtc(t.Runtime, r18.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r17.AsCont())
+L278:
+	t.RequireCPU(3)
+	r17 = rt.ArrayValue(k17.Etc())
+	r16.AsCont().PushEtc(t.Runtime, r17.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r16.AsCont())
+L281:
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 89); err != nil {
+		return nil, err
+	}
+	if r16, err = rt.Index(t, (*c0), rt.StringValue("coroutine")); err != nil {
+		return nil, err
+	}
+	if r17, err = rt.Index(t, r16, rt.StringValue("wrap")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k16 = rt.NewResumption(c, 1, false, s, 288)
+	if cont, err = rt.Continue(t, r17, k16); err != nil {
+		return nil, err
+	}
+	r16 = rt.ContValue(cont)
+	u17 = make([]*rt.Value, 1)
+	r17 = rt.FunctionValue(functionsF38(u17))
+	u17[0] = c0
+	r16.AsCont().Push(t.Runtime, r17)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r16.AsCont())
+L288:
+	t.RequireCPU(7)
+	r16 = k16.Get(0)
+	if err = c.SetLine(t, 94); err != nil {
+		return nil, err
+	}
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k17 = rt.NewResumption(c, 0, false, s, 310)
+	if cont, err = rt.Continue(t, r17, k17); err != nil {
+		return nil, err
+	}
+	r17 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 1, false, s, 295)
+	if cont, err = rt.Continue(t, r16, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	r19 = rt.IntValue(1)
+	r18.AsCont().Push(t.Runtime, r19)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r18.AsCont())
+L295:
+	t.RequireCPU(6)
+	r18 = k18.Get(0)
+	r17.AsCont().Push(t.Runtime, r18)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 1, false, s, 301)
+	if cont, err = rt.Continue(t, r16, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	r19 = rt.IntValue(10)
+	r18.AsCont().Push(t.Runtime, r19)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r18.AsCont())
+L301:
+	t.RequireCPU(6)
+	r18 = k18.Get(0)
+	r17.AsCont().Push(t.Runtime, r18)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 0, true, s, 307)
+	if cont, err = rt.Continue(t, r16, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	r19 = rt.IntValue(100)
+	r18.AsCont().Push(t.Runtime, r19)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r18.AsCont())
+L307:
+	t.RequireCPU(3)
+	r18 = rt.ArrayValue(k18.Etc())
+	r17.AsCont().PushEtc(t.Runtime, r18.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r17.AsCont())
+L310:
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 97); err != nil {
+		return nil, err
+	}
+	if r17, err = rt.Index(t, (*c0), rt.StringValue("coroutine")); err != nil {
+		return nil, err
+	}
+	if r18, err = rt.Index(t, r17, rt.StringValue("wrap")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k17 = rt.NewResumption(c, 1, false, s, 317)
+	if cont, err = rt.Continue(t, r18, k17); err != nil {
+		return nil, err
+	}
+	r17 = rt.ContValue(cont)
+	u18 = make([]*rt.Value, 1)
+	r18 = rt.FunctionValue(functionsF39(u18))
+	u18[0] = c0
+	r17.AsCont().Push(t.Runtime, r18)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r17.AsCont())
+L317:
+	t.RequireCPU(5)
+	r17 = k17.Get(0)
+	if err = c.SetLine(t, 100); err != nil {
+		return nil, err
+	}
+	if r18, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 0, false, s, 333)
+	if cont, err = rt.Continue(t, r18, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k19 = rt.NewResumption(c, 1, false, s, 322)
+	if cont, err = rt.Continue(t, r17, k19); err != nil {
+		return nil, err
+	}
+	r19 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r19.AsCont())
+L322:
+	t.RequireCPU(4)
+	r19 = k19.Get(0)
+	r18.AsCont().Push(t.Runtime, r19)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k19 = rt.NewResumption(c, 1, false, s, 326)
+	if cont, err = rt.Continue(t, r17, k19); err != nil {
+		return nil, err
+	}
+	r19 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r19.AsCont())
+L326:
+	t.RequireCPU(4)
+	r19 = k19.Get(0)
+	r18.AsCont().Push(t.Runtime, r19)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k19 = rt.NewResumption(c, 0, true, s, 330)
+	if cont, err = rt.Continue(t, r17, k19); err != nil {
+		return nil, err
+	}
+	r19 = rt.ContValue(cont)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r19.AsCont())
+L330:
+	t.RequireCPU(3)
+	r19 = rt.ArrayValue(k19.Etc())
+	r18.AsCont().PushEtc(t.Runtime, r19.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r18.AsCont())
+L333:
+	t.RequireCPU(5)
+	if err = c.SetLine(t, 104); err != nil {
+		return nil, err
+	}
+	if r18, err = rt.Index(t, (*c0), rt.StringValue("load")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k18 = rt.NewResumption(c, 1, false, s, 338)
+	if cont, err = rt.Continue(t, r18, k18); err != nil {
+		return nil, err
+	}
+	r18 = rt.ContValue(cont)
+	r19 = rt.StringValue("local g = ... return g(5) + 1")
+	r18.AsCont().Push(t.Runtime, r19)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r18.AsCont())
+L338:
+	t.RequireCPU(7)
+	r18 = k18.Get(0)
+	if err = c.SetLine(t, 105); err != nil {
+		return nil, err
+	}
+	if r19, err = rt.Index(t, (*c0), rt.StringValue("print")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k19 = rt.NewResumption(c, 0, false, s, 348)
+	if cont, err = rt.Continue(t, r19, k19); err != nil {
+		return nil, err
+	}
+	r19 = rt.ContValue(cont)
+	if s == nil {
+		s = &functionsF0State{c: c}
+	}
+	k20 = rt.NewResumption(c, 0, true, s, 345)
+	if cont, err = rt.Continue(t, r18, k20); err != nil {
+		return nil, err
+	}
+	r20 = rt.ContValue(cont)
+	r21 = rt.FunctionValue(functionsF42(nil))
+	r20.AsCont().Push(t.Runtime, r21)
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r20.AsCont())
+L345:
+	t.RequireCPU(3)
+	r20 = rt.ArrayValue(k20.Etc())
+	r19.AsCont().PushEtc(t.Runtime, r20.AsArray())
+	s.r0, s.c0, s.c1, s.c2, s.c3, s.c4, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.r8, s.r9, s.r10, s.r11, s.r12, s.r13, s.r14, s.r15, s.r16, s.r17, s.r18, s.r19, s.r20, s.r21, s.k3, s.k4, s.k5, s.k6, s.k7, s.k8, s.k9, s.k10, s.k11, s.k12, s.k13, s.k14, s.k15, s.k16, s.k17, s.k18, s.k19, s.k20, s.u12, s.u13, s.u15, s.u17, s.u18, s.u19, s.u2, s.u7, s.uc2, s.uc3 = r0, c0, c1, c2, c3, c4, r2, r3, r4, r5, r6, r7, r8, r9, r10, r11, r12, r13, r14, r15, r16, r17, r18, r19, r20, r21, k3, k4, k5, k6, k7, k8, k9, k10, k11, k12, k13, k14, k15, k16, k17, k18, k19, k20, u12, u13, u15, u17, u18, u19, u2, u7, uc2, uc3
+	return c.Call(t, r19.AsCont())
+L348:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// functionsF1 implements function sum [349 - 381].
+func functionsF1(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return functionsF1Run(t, c, ups, nil, 0)
+	}, "sum", 0, true)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF1State holds the variables of functionsF1 while it is suspended by a call.
+type functionsF1State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	r3 rt.Value
+	r4 rt.Value
+	r5 rt.Value
+	r6 rt.Value
+	r7 rt.Value
+	k3 *rt.Resumption
+	k4 *rt.Resumption
+	k7 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *functionsF1State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return functionsF1Run(t, s.c, nil, s, at)
+}
+
+// functionsF1Run runs functionsF1 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func functionsF1Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *functionsF1State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var r5 rt.Value
+	var r6 rt.Value
+	var r7 rt.Value
+	var k3 *rt.Resumption
+	var k4 *rt.Resumption
+	var k7 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, r3, r4, r5, r6, r7, k3, k4, k7 = s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.k3, s.k4, s.k7
+		switch at {
+		case 9:
+			goto L9
+		case 19:
+			goto L19
+		case 30:
+			goto L30
+		}
+	}
+	t.RequireCPU(9)
+	if err = c.SetLine(t, 2); err != nil {
+		return nil, err
+	}
+	r1 = rt.ArrayValue(c.Etc())
+	if err = c.SetLine(t, 3); err != nil {
+		return nil, err
+	}
+	r2 = rt.IntValue(0)
+	if err = c.SetLine(t, 4); err != nil {
+		return nil, err
+	}
+	r3 = rt.IntValue(1)
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF1State{c: c}
+	}
+	k4 = rt.NewResumption(c, 1, false, s, 9)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	r5 = rt.StringValue("#")
+	r4.AsCont().Push(t.Runtime, r5)
+	r4.AsCont().PushEtc(t.Runtime, r1.AsArray())
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.k3, s.k4, s.k7 = r0, c0, r1, r2, r3, r4, r5, r6, r7, k3, k4, k7
+	return c.Call(t, r4.AsCont())
+L9:
+	t.RequireCPU(3)
+	r4 = k4.Get(0)
+	r5 = rt.IntValue(1)
+	if r3, r4, r5, err = rt.PrepForLoop(r3, r4, r5); err != nil {
+		return nil, err
+	}
+L12:
+	t.RequireCPU(7)
+	if !rt.Truth(r3) {
+		goto L23
+	}
+	r6 = r3
+	if err = c.SetLine(t, 4); err != nil {
+		return nil, err
+	}
+	if r7, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF1State{c: c}
+	}
+	k7 = rt.NewResumption(c, 1, false, s, 19)
+	if cont, err = rt.Continue(t, r7, k7); err != nil {
+		return nil, err
+	}
+	r7 = rt.ContValue(cont)
+	r7.AsCont().Push(t.Runtime, r6)
+	r7.AsCont().PushEtc(t.Runtime, r1.AsArray())
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.k3, s.k4, s.k7 = r0, c0, r1, r2, r3, r4, r5, r6, r7, k3, k4, k7
+	return c.Call(t, r7.AsCont())
+L19:
+	t.RequireCPU(4)
+	r7 = k7.Get(0)
+	if v, ok := rt.Add(r2, r7); ok {
+		r2 = v
+	} else if r2, err = rt.BinaryOp(t, code.OpAdd, r2, r7); err != nil {
+		return nil, err
+	}
+	r3 = rt.AdvForLoop(r3, r4, rt.IntValue(1))
+	if rt.Truth(r3) {
+		goto L12
+	}
+L23:
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 5); err != nil {
+		return nil, err
+	}
+	r0.AsCont().Push(t.Runtime, r2)
+	if r3, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF1State{c: c}
+	}
+	k3 = rt.NewResumption(c, 0, true, s, 30)
+	if cont, err = rt.Continue(t, r3, k3); err != nil {
+		return nil, err
+	}
+	r3 = rt.ContValue(cont)
+	r4 = rt.StringValue("#")
+	r3.AsCont().Push(t.Runtime, r4)
+	r3.AsCont().PushEtc(t.Runtime, r1.AsArray())
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.r6, s.r7, s.k3, s.k4, s.k7 = r0, c0, r1, r2, r3, r4, r5, r6, r7, k3, k4, k7
+	return c.Call(t, r3.AsCont())
+L30:
+	t.RequireCPU(3)
+	r3 = rt.ArrayValue(k3.Etc())
+	r0.AsCont().PushEtc(t.Runtime, r3.AsArray())
+	return r0.AsCont(), nil
+}
+
+// functionsF3 implements function pack [382 - 386].
+func functionsF3(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2 rt.Value
+		var err error
+		t.RequireCPU(5)
+		if err = c.SetLine(t, 10); err != nil {
+			return nil, err
+		}
+		r1 = rt.ArrayValue(c.Etc())
+		r2 = rt.TableValue(rt.NewTable())
+		for i, v := range r1.AsArray() {
+			t.SetTable(r2.AsTable(), rt.IntValue(int64(i+1)), v)
+		}
+		r0.AsCont().Push(t.Runtime, r2)
+		return r0.AsCont(), nil
+	}, "pack", 0, true)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF4 implements function first [387 - 389].
+func functionsF4(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1 rt.Value
+		var err error
+		t.RequireCPU(3)
+		if err = c.SetLine(t, 11); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		r0.AsCont().Push(t.Runtime, r1)
+		return r0.AsCont(), nil
+	}, "first", 1, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF5 implements function multi [390 - 396].
+func functionsF5(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1 rt.Value
+		var err error
+		t.RequireCPU(7)
+		if err = c.SetLine(t, 16); err != nil {
+			return nil, err
+		}
+		r1 = rt.IntValue(1)
+		r0.AsCont().Push(t.Runtime, r1)
+		r1 = rt.IntValue(2)
+		r0.AsCont().Push(t.Runtime, r1)
+		r1 = rt.IntValue(3)
+		r0.AsCont().Push(t.Runtime, r1)
+		return r0.AsCont(), nil
+	}, "multi", 0, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF6 implements function tail [397 - 410].
+func functionsF6(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return functionsF6Run(t, c, ups, nil, 0)
+	}, "tail", 0, true)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF6State holds the variables of functionsF6 while it is suspended by a call.
+type functionsF6State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	r3 rt.Value
+	r4 rt.Value
+	r5 rt.Value
+	k4 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *functionsF6State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return functionsF6Run(t, s.c, nil, s, at)
+}
+
+// functionsF6Run runs functionsF6 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func functionsF6Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *functionsF6State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var r5 rt.Value
+	var k4 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, r3, r4, r5, k4 = s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.k4
+		switch at {
+		case 11:
+			goto L11
+		}
+	}
+	t.RequireCPU(11)
+	if err = c.SetLine(t, 23); err != nil {
+		return nil, err
+	}
+	r1 = rt.ArrayValue(c.Etc())
+	if err = c.SetLine(t, 24); err != nil {
+		return nil, err
+	}
+	r2 = functionsEtc(r1, 0)
+	r3 = functionsEtc(r1, 1)
+	if err = c.SetLine(t, 25); err != nil {
+		return nil, err
+	}
+	r0.AsCont().Push(t.Runtime, r3)
+	r0.AsCont().Push(t.Runtime, r2)
+	if r4, err = rt.Index(t, (*c0), rt.StringValue("select")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF6State{c: c}
+	}
+	k4 = rt.NewResumption(c, 0, true, s, 11)
+	if cont, err = rt.Continue(t, r4, k4); err != nil {
+		return nil, err
+	}
+	r4 = rt.ContValue(cont)
+	r5 = rt.StringValue("#")
+	r4.AsCont().Push(t.Runtime, r5)
+	r4.AsCont().PushEtc(t.Runtime, r1.AsArray())
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.k4 = r0, c0, r1, r2, r3, r4, r5, k4
+	return c.Call(t, r4.AsCont())
+L11:
+	t.RequireCPU(3)
+	r4 = rt.ArrayValue(k4.Etc())
+	r0.AsCont().PushEtc(t.Runtime, r4.AsArray())
+	return r0.AsCont(), nil
+}
+
+// functionsF10 implements function counter [411 - 415].
+func functionsF10(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := new(rt.Value)
+		var r1 rt.Value
+		var u1 []*rt.Value
+		var err error
+		t.RequireCPU(5)
+		if err = c.SetLine(t, 32); err != nil {
+			return nil, err
+		}
+		*c0 = rt.IntValue(0)
+		if err = c.SetLine(t, 33); err != nil {
+			return nil, err
+		}
+		u1 = make([]*rt.Value, 1)
+		r1 = rt.FunctionValue(functionsF44(u1))
+		u1[0] = c0
+		r0.AsCont().Push(t.Runtime, r1)
+		return r0.AsCont(), nil
+	}, "counter", 0, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF11 implements function <anon> [416 - 417].
+func functionsF11(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var err error
+		t.RequireCPU(2)
+		if err = c.SetLine(t, 41); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, (*c0))
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF12 implements function setx [418 - 420].
+func functionsF12(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var r1 rt.Value
+		var err error
+		t.RequireCPU(3)
+		if err = c.SetLine(t, 46); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		*c0 = r1
+		return r0.AsCont(), nil
+	}, "setx", 1, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF13 implements function fib [421 - 439].
+func functionsF13(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return functionsF13Run(t, c, ups, nil, 0)
+	}, "fib", 1, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF13State holds the variables of functionsF13 while it is suspended by a call.
+type functionsF13State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	r3 rt.Value
+	r4 rt.Value
+	k2 *rt.Resumption
+	k3 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *functionsF13State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return functionsF13Run(t, s.c, nil, s, at)
+}
+
+// functionsF13Run runs functionsF13 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func functionsF13Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *functionsF13State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var k2 *rt.Resumption
+	var k3 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, r3, r4, k2, k3 = s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k2, s.k3
+		switch at {
+		case 10:
+			goto L10
+		case 15:
+			goto L15
+		}
+	}
+	t.RequireCPU(6)
+	if err = c.SetLine(t, 52); err != nil {
+		return nil, err
+	}
+	r1 = c.Arg(0)
+	if err = c.SetLine(t, 53); err != nil {
+		return nil, err
+	}
+	r2 = rt.IntValue(2)
+	if r2, err = rt.BinaryOp(t, code.OpLt, r1, r2); err != nil {
+		return nil, err
+	}
+	if !rt.Truth(r2) {
+		goto L6
+	}
+	r0.AsCont().Push(t.Runtime, r1)
+	return r0.AsCont(), nil
+L6:
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 54); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF13State{c: c}
+	}
+	k2 = rt.NewResumption(c, 1, false, s, 10)
+	if cont, err = rt.Continue(t, (*c0), k2); err != nil {
+		return nil, err
+	}
+	r2 = rt.ContValue(cont)
+	if v, ok := rt.Sub(r1, rt.IntValue(1)); ok {
+		r3 = v
+	} else if r3, err = rt.BinaryOp(t, code.OpSub, r1, rt.IntValue(1)); err != nil {
+		return nil, err
+	}
+	r2.AsCont().Push(t.Runtime, r3)
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k2, s.k3 = r0, c0, r1, r2, r3, r4, k2, k3
+	return c.Call(t, r2.AsCont())
+L10:
+	t.RequireCPU(5)
+	r2 = k2.Get(0)
+	if s == nil {
+		s = &functionsF13State{c: c}
+	}
+	k3 = rt.NewResumption(c, 1, false, s, 15)
+	if cont, err = rt.Continue(t, (*c0), k3); err != nil {
+		return nil, err
+	}
+	r3 = rt.ContValue(cont)
+	if v, ok := rt.Sub(r1, rt.IntValue(2)); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpSub, r1, rt.IntValue(2)); err != nil {
+		return nil, err
+	}
+	r3.AsCont().Push(t.Runtime, r4)
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k2, s.k3 = r0, c0, r1, r2, r3, r4, k2, k3
+	return c.Call(t, r3.AsCont())
+L15:
+	t.RequireCPU(4)
+	r3 = k3.Get(0)
+	if v, ok := rt.Add(r2, r3); ok {
+		r3 = v
+	} else if r3, err = rt.BinaryOp(t, code.OpAdd, r2, r3); err != nil {
+		return nil, err
+	}
+	r0.AsCont().Push(t.Runtime, r3)
+	return r0.AsCont(), nil
+}
+
+// functionsF14 implements function loop [440 - 452].
+func functionsF14(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var r1, r2, r3, r4 rt.Value
+		var cont rt.Cont
+		var err error
+		t.RequireCPU(7)
+		if err = c.SetLine(t, 59); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		r2 = c.Arg(1)
+		if err = c.SetLine(t, 60); err != nil {
+			return nil, err
+		}
+		r3 = rt.IntValue(0)
+		if r3, err = rt.BinaryOp(t, code.OpEq, r1, r3); err != nil {
+			return nil, err
+		}
+		if !rt.Truth(r3) {
+			goto L7
+		}
+		r0.AsCont().Push(t.Runtime, r2)
+		return r0.AsCont(), nil
+	L7:
+		t.RequireCPU(6)
+		if err = c.SetLine(t, 61); err != nil {
+			return nil, err
+		}
+		if cont, err = rt.Continue(t, (*c0), c.Next()); err != nil {
+			return nil, err
+		}
+		r3 = rt.ContValue(cont)
+		if v, ok := rt.Sub(r1, rt.IntValue(1)); ok {
+			r4 = v
+		} else if r4, err = rt.BinaryOp(t, code.OpSub, r1, rt.IntValue(1)); err != nil {
+			return nil, err
+		}
+		r3.AsCont().Push(t.Runtime, r4)
+		if v, ok := rt.Add(r2, rt.IntValue(1)); ok {
+			r4 = v
+		} else if r4, err = rt.BinaryOp(t, code.OpAdd, r2, rt.IntValue(1)); err != nil {
+			return nil, err
+		}
+		r3.AsCont().Push(t.Runtime, r4)
+		return c.TailCall(t, r3.AsCont())
+	}, "loop", 2, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF17 implements function __add [453 - 464].
+func functionsF17(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		c0 := ups[0]
+		c1 := ups[1]
+		var r1, r2, r3, r4, r5, r6 rt.Value
+		var cont rt.Cont
+		var err error
+		t.RequireCPU(12)
+		if err = c.SetLine(t, 69); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		r2 = c.Arg(1)
+		if r3, err = rt.Index(t, (*c0), rt.StringValue("setmetatable")); err != nil {
+			return nil, err
+		}
+		if cont, err = rt.Continue(t, r3, c.Next()); err != nil {
+			return nil, err
+		}
+		r3 = rt.ContValue(cont)
+		r4 = rt.TableValue(t.NewTableSize(0, 1))
+		if r5, err = rt.Index(t, r1, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if r6, err = rt.Index(t, r2, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if v, ok := rt.Add(r5, r6); ok {
+			r6 = v
+		} else if r6, err = rt.BinaryOp(t, code.OpAdd, r5, r6); err != nil {
+			return nil, err
+		}
+		if err = rt.SetIndex(t, r4, rt.StringValue("x"), r6); err != nil {
+			return nil, err
+		}
+		r3.AsCont().Push(t.Runtime, r4)
+		r3.AsCont().Push(t.Runtime, (*c1))
+		return c.TailCall(t, r3.AsCont())
+	}, "__add", 2, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF19 implements function __eq [465 - 471].
+func functionsF19(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2, r3, r4 rt.Value
+		var err error
+		t.RequireCPU(7)
+		if err = c.SetLine(t, 70); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		r2 = c.Arg(1)
+		if r3, err = rt.Index(t, r1, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if r4, err = rt.Index(t, r2, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if r4, err = rt.BinaryOp(t, code.OpEq, r3, r4); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r4)
+		return r0.AsCont(), nil
+	}, "__eq", 2, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF21 implements function __lt [472 - 478].
+func functionsF21(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2, r3, r4 rt.Value
+		var err error
+		t.RequireCPU(7)
+		if err = c.SetLine(t, 71); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		r2 = c.Arg(1)
+		if r3, err = rt.Index(t, r1, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if r4, err = rt.Index(t, r2, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if r4, err = rt.BinaryOp(t, code.OpLt, r3, r4); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r4)
+		return r0.AsCont(), nil
+	}, "__lt", 2, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF23 implements function __call [479 - 484].
+func functionsF23(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2, r3, r4 rt.Value
+		var err error
+		t.RequireCPU(6)
+		if err = c.SetLine(t, 72); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		r2 = c.Arg(1)
+		if r3, err = rt.Index(t, r1, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		if v, ok := rt.Mul(r3, r2); ok {
+			r4 = v
+		} else if r4, err = rt.BinaryOp(t, code.OpMul, r3, r2); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r4)
+		return r0.AsCont(), nil
+	}, "__call", 2, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF25 implements function __len [485 - 487].
+func functionsF25(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1 rt.Value
+		var err error
+		t.RequireCPU(3)
+		if err = c.SetLine(t, 73); err != nil {
+			return nil, err
+		}
+		r1 = rt.IntValue(99)
+		r0.AsCont().Push(t.Runtime, r1)
+		return r0.AsCont(), nil
+	}, "__len", 0, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF27 implements function new [488 - 495].
+func functionsF27(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		c0 := ups[0]
+		c1 := ups[1]
+		var r1, r2, r3 rt.Value
+		var cont rt.Cont
+		var err error
+		t.RequireCPU(8)
+		if err = c.SetLine(t, 74); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		if r2, err = rt.Index(t, (*c0), rt.StringValue("setmetatable")); err != nil {
+			return nil, err
+		}
+		if cont, err = rt.Continue(t, r2, c.Next()); err != nil {
+			return nil, err
+		}
+		r2 = rt.ContValue(cont)
+		r3 = rt.TableValue(t.NewTableSize(0, 1))
+		if err = rt.SetIndex(t, r3, rt.StringValue("x"), r1); err != nil {
+			return nil, err
+		}
+		r2.AsCont().Push(t.Runtime, r3)
+		r2.AsCont().Push(t.Runtime, (*c1))
+		return c.TailCall(t, r2.AsCont())
+	}, "new", 1, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF29 implements function get [496 - 499].
+func functionsF29(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2 rt.Value
+		var err error
+		t.RequireCPU(4)
+		if err = c.SetLine(t, 75); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		if r2, err = rt.Index(t, r1, rt.StringValue("x")); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r2)
+		return r0.AsCont(), nil
+	}, "get", 1, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF35 implements function <anon> [500 - 507].
+func functionsF35(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return functionsF35Run(t, c, ups, nil, 0)
+	}, "", 0, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF35State holds the variables of functionsF35 while it is suspended by a call.
+type functionsF35State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	k1 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *functionsF35State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return functionsF35Run(t, s.c, nil, s, at)
+}
+
+// functionsF35Run runs functionsF35 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func functionsF35Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *functionsF35State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var k1 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, k1 = s.r0, s.c0, s.r1, s.r2, s.k1
+		switch at {
+		case 7:
+			goto L7
+		}
+	}
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 85); err != nil {
+		return nil, err
+	}
+	if r1, err = rt.Index(t, (*c0), rt.StringValue("error")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF35State{c: c}
+	}
+	k1 = rt.NewResumption(c, 0, false, s, 7)
+	if cont, err = rt.Continue(t, r1, k1); err != nil {
+		return nil, err
+	}
+	r1 = rt.ContValue(cont)
+	r2 = rt.StringValue("boom")
+	r1.AsCont().Push(t.Runtime, r2)
+	r2 = rt.IntValue(0)
+	r1.AsCont().Push(t.Runtime, r2)
+	s.r0, s.c0, s.r1, s.r2, s.k1 = r0, c0, r1, r2, k1
+	return c.Call(t, r1.AsCont())
+L7:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// functionsF38 implements function <anon> [508 - 527].
+func functionsF38(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return functionsF38Run(t, c, ups, nil, 0)
+	}, "", 1, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF38State holds the variables of functionsF38 while it is suspended by a call.
+type functionsF38State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	r3 rt.Value
+	r4 rt.Value
+	k2 *rt.Resumption
+	k3 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *functionsF38State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return functionsF38Run(t, s.c, nil, s, at)
+}
+
+// functionsF38Run runs functionsF38 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func functionsF38Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *functionsF38State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var k2 *rt.Resumption
+	var k3 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, r3, r4, k2, k3 = s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k2, s.k3
+		switch at {
+		case 7:
+			goto L7
+		case 15:
+			goto L15
+		}
+	}
+	t.RequireCPU(7)
+	if err = c.SetLine(t, 89); err != nil {
+		return nil, err
+	}
+	r1 = c.Arg(0)
+	if err = c.SetLine(t, 90); err != nil {
+		return nil, err
+	}
+	if r2, err = rt.Index(t, (*c0), rt.StringValue("coroutine")); err != nil {
+		return nil, err
+	}
+	if r3, err = rt.Index(t, r2, rt.StringValue("yield")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF38State{c: c}
+	}
+	k2 = rt.NewResumption(c, 1, false, s, 7)
+	if cont, err = rt.Continue(t, r3, k2); err != nil {
+		return nil, err
+	}
+	r2 = rt.ContValue(cont)
+	if v, ok := rt.Add(r1, rt.IntValue(1)); ok {
+		r3 = v
+	} else if r3, err = rt.BinaryOp(t, code.OpAdd, r1, rt.IntValue(1)); err != nil {
+		return nil, err
+	}
+	r2.AsCont().Push(t.Runtime, r3)
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k2, s.k3 = r0, c0, r1, r2, r3, r4, k2, k3
+	return c.Call(t, r2.AsCont())
+L7:
+	t.RequireCPU(8)
+	r2 = k2.Get(0)
+	if err = c.SetLine(t, 91); err != nil {
+		return nil, err
+	}
+	if r3, err = rt.Index(t, (*c0), rt.StringValue("coroutine")); err != nil {
+		return nil, err
+	}
+	if r4, err = rt.Index(t, r3, rt.StringValue("yield")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF38State{c: c}
+	}
+	k3 = rt.NewResumption(c, 1, false, s, 15)
+	if cont, err = rt.Continue(t, r4, k3); err != nil {
+		return nil, err
+	}
+	r3 = rt.ContValue(cont)
+	r4 = rt.IntValue(2)
+	if v, ok := rt.Mul(r2, r4); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpMul, r2, r4); err != nil {
+		return nil, err
+	}
+	r3.AsCont().Push(t.Runtime, r4)
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.k2, s.k3 = r0, c0, r1, r2, r3, r4, k2, k3
+	return c.Call(t, r3.AsCont())
+L15:
+	t.RequireCPU(5)
+	r3 = k3.Get(0)
+	if err = c.SetLine(t, 92); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r1, r2); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpAdd, r1, r2); err != nil {
+		return nil, err
+	}
+	if v, ok := rt.Add(r4, r3); ok {
+		r4 = v
+	} else if r4, err = rt.BinaryOp(t, code.OpAdd, r4, r3); err != nil {
+		return nil, err
+	}
+	r0.AsCont().Push(t.Runtime, r4)
+	return r0.AsCont(), nil
+}
+
+// functionsF39 implements function <anon> [528 - 541].
+func functionsF39(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return functionsF39Run(t, c, ups, nil, 0)
+	}, "", 0, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF39State holds the variables of functionsF39 while it is suspended by a call.
+type functionsF39State struct {
+	c  *rt.GoCont
+	r0 rt.Value
+	c0 *rt.Value
+	r1 rt.Value
+	r2 rt.Value
+	r3 rt.Value
+	r4 rt.Value
+	r5 rt.Value
+	r6 rt.Value
+	k5 *rt.Resumption
+}
+
+// Resume implements rt.Resumable.
+func (s *functionsF39State) Resume(t *rt.Thread, at int) (rt.Cont, error) {
+	return functionsF39Run(t, s.c, nil, s, at)
+}
+
+// functionsF39Run runs functionsF39 from the start if s is nil, otherwise it resumes it at
+// opcode at with the variables saved in s.
+func functionsF39Run(t *rt.Thread, c *rt.GoCont, ups []*rt.Value, s *functionsF39State, at int) (rt.Cont, error) {
+	var r0 rt.Value
+	var c0 *rt.Value
+	var r1 rt.Value
+	var r2 rt.Value
+	var r3 rt.Value
+	var r4 rt.Value
+	var r5 rt.Value
+	var r6 rt.Value
+	var k5 *rt.Resumption
+	var cont rt.Cont
+	var err error
+	if s == nil {
+		r0 = rt.ContValue(c.Next())
+		c0 = ups[0]
+	} else {
+		r0, c0, r1, r2, r3, r4, r5, r6, k5 = s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.r6, s.k5
+		switch at {
+		case 11:
+			goto L11
+		}
+	}
+	t.RequireCPU(4)
+	if err = c.SetLine(t, 98); err != nil {
+		return nil, err
+	}
+	r1 = rt.IntValue(1)
+	r2 = rt.IntValue(3)
+	r3 = rt.IntValue(1)
+	if r1, r2, r3, err = rt.PrepForLoop(r1, r2, r3); err != nil {
+		return nil, err
+	}
+L4:
+	t.RequireCPU(7)
+	if !rt.Truth(r1) {
+		goto L13
+	}
+	r4 = r1
+	if err = c.SetLine(t, 98); err != nil {
+		return nil, err
+	}
+	if r5, err = rt.Index(t, (*c0), rt.StringValue("coroutine")); err != nil {
+		return nil, err
+	}
+	if r6, err = rt.Index(t, r5, rt.StringValue("yield")); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &functionsF39State{c: c}
+	}
+	k5 = rt.NewResumption(c, 0, false, s, 11)
+	if cont, err = rt.Continue(t, r6, k5); err != nil {
+		return nil, err
+	}
+	r5 = rt.ContValue(cont)
+	r5.AsCont().Push(t.Runtime, r4)
+	s.r0, s.c0, s.r1, s.r2, s.r3, s.r4, s.r5, s.r6, s.k5 = r0, c0, r1, r2, r3, r4, r5, r6, k5
+	return c.Call(t, r5.AsCont())
+L11:
+	t.RequireCPU(2)
+	r1 = rt.AdvForLoop(r1, r2, rt.IntValue(1))
+	if rt.Truth(r1) {
+		goto L4
+	}
+L13:
+	t.RequireCPU(1)
+	return r0.AsCont(), nil
+}
+
+// functionsF42 implements function <anon> [542 - 545].
+func functionsF42(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		var r1, r2 rt.Value
+		var err error
+		t.RequireCPU(4)
+		if err = c.SetLine(t, 105); err != nil {
+			return nil, err
+		}
+		r1 = c.Arg(0)
+		if v, ok := rt.Mul(r1, r1); ok {
+			r2 = v
+		} else if r2, err = rt.BinaryOp(t, code.OpMul, r1, r1); err != nil {
+			return nil, err
+		}
+		r0.AsCont().Push(t.Runtime, r2)
+		return r0.AsCont(), nil
+	}, "", 1, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
+
+// functionsF44 implements function <anon> [546 - 548].
+func functionsF44(ups []*rt.Value) *rt.GoFunction {
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		r0 := rt.ContValue(c.Next())
+		c0 := ups[0]
+		var tmp rt.Value
+		var err error
+		t.RequireCPU(3)
+		if err = c.SetLine(t, 33); err != nil {
+			return nil, err
+		}
+		if v, ok := rt.Add((*c0), rt.IntValue(1)); ok {
+			tmp = v
+		} else if tmp, err = rt.BinaryOp(t, code.OpAdd, (*c0), rt.IntValue(1)); err != nil {
+			return nil, err
+		}
+		*c0 = tmp
+		r0.AsCont().Push(t.Runtime, (*c0))
+		return r0.AsCont(), nil
+	}, "", 0, false)
+	f.SetLuaSource(functionsSource)
+	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe, f)
+	return f
+}
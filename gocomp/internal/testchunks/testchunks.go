@@ -0,0 +1,14 @@
+// Package testchunks contains Lua scripts compiled to Go by package gocomp, to
+// test it.  Run "go test ./gocomp -update" to regenerate the Go files after
+// changing a script or the compiler.
+package testchunks
+
+import rt "github.com/arnodel/golua/runtime"
+
+// Chunks maps the name of each Lua script to the function returning its
+// compiled main chunk.
+var Chunks = map[string]func(env rt.Value) *rt.GoFunction{
+	"basics.lua":    Basics,
+	"errors.lua":    Errors,
+	"functions.lua": Functions,
+}
@@ -26,6 +26,11 @@ type CodeBuilder struct {
 	upnames      []string
 	code         []Instruction
 	lines        []int
+	columns      []int
+	inlined      []int
+	inlineFrames []InlineFrame
+	inlineFrame  int   // Inline frame of the instructions being emitted
+	inlineStarts []int // Index of the first instruction of each inline frame
 	labels       []bool
 	constantPool *ConstantPool
 }
@@ -139,6 +144,32 @@ func (c *CodeBuilder) getRegister(name Name, tags uint) (reg Register, ok bool)
 	return
 }
 
+// ResolveName returns the code builder in which the variable with the given
+// name is defined and its register in that code builder.  Unlike GetRegister,
+// it does not capture the variable as an upvalue if it is defined in an
+// enclosing function.
+func (c *CodeBuilder) ResolveName(name Name) (home *CodeBuilder, reg Register, ok bool) {
+	reg, ok = c.context.getRegister(name, 0)
+	if ok {
+		home, reg = c.resolveRegister(reg)
+		return
+	}
+	if c.parent == nil {
+		return nil, 0, false
+	}
+	return c.parent.ResolveName(name)
+}
+
+// resolveRegister follows reg to the register it is an upvalue of, if any.
+func (c *CodeBuilder) resolveRegister(reg Register) (*CodeBuilder, Register) {
+	for i, dst := range c.upvalueDests {
+		if dst == reg {
+			return c.parent.resolveRegister(c.upvalues[i])
+		}
+	}
+	return c, reg
+}
+
 func (c *CodeBuilder) GetFreeRegister() Register {
 	reg := Register(len(c.registers))
 	c.registers = append(c.registers, RegData{})
@@ -242,8 +273,49 @@ func (c *CodeBuilder) EmitNoLine(instr Instruction) {
 }
 
 func (c *CodeBuilder) Emit(instr Instruction, line int) {
+	c.EmitAt(instr, line, 0)
+}
+
+// EmitAt emits an instruction, associating it with a line and column in the
+// source code (0 if unknown).
+func (c *CodeBuilder) EmitAt(instr Instruction, line, column int) {
 	c.code = append(c.code, instr)
 	c.lines = append(c.lines, line)
+	c.columns = append(c.columns, column)
+	c.inlined = append(c.inlined, c.inlineFrame)
+}
+
+// PushInlineFrame starts emitting the body of the function with the given
+// name in place of a call to it at the given line and column.  Emitted
+// instructions belong to the new inline frame until PopInlineFrame is called.
+func (c *CodeBuilder) PushInlineFrame(name string, line, column int) {
+	c.inlineFrames = append(c.inlineFrames, InlineFrame{
+		Name:   name,
+		Line:   line,
+		Column: column,
+		Parent: c.inlineFrame,
+	})
+	c.inlineStarts = append(c.inlineStarts, len(c.code))
+	c.inlineFrame = len(c.inlineFrames)
+}
+
+// PopInlineFrame ends the current inline frame.
+func (c *CodeBuilder) PopInlineFrame() {
+	c.inlineFrame = c.inlineFrames[c.inlineFrame-1].Parent
+}
+
+// InlineFrameHasCode returns true if instructions were emitted in the current
+// inline frame (register hints and labels do not count, as they do not produce
+// any code).
+func (c *CodeBuilder) InlineFrameHasCode() bool {
+	for _, instr := range c.code[c.inlineStarts[c.inlineFrame-1]:] {
+		switch instr.(type) {
+		case TakeRegister, ReleaseRegister, DeclareLabel:
+		default:
+			return true
+		}
+	}
+	return false
 }
 
 func (c *CodeBuilder) Close() (uint, []Register) {
@@ -255,9 +327,16 @@ func (c *CodeBuilder) getConstantIndex(k Constant) uint {
 }
 
 func (c *CodeBuilder) getCode() *Code {
+	var inlined []int
+	if c.inlineFrames != nil {
+		inlined = c.inlined
+	}
 	return &Code{
 		Instructions: c.code,
 		Lines:        c.lines,
+		Columns:      c.columns,
+		Inlined:      inlined,
+		InlineFrames: c.inlineFrames,
 		Constants:    c.constantPool.Constants(),
 		Registers:    c.registers,
 		UpvalueDests: c.upvalueDests,
@@ -57,10 +57,31 @@ func (n NilType) ProcessConstant(p ConstantProcessor) {
 	p.ProcessNil(n)
 }
 
+// columns returns the source columns of the instructions in c, which are all 0
+// if c has no column information.
+func (c *Code) columns() []int {
+	if len(c.Columns) == len(c.Instructions) {
+		return c.Columns
+	}
+	return make([]int, len(c.Instructions))
+}
+
+// inlined returns the inline frames of the instructions in c, which are all 0
+// if c has no inlined function calls.
+func (c *Code) inlined() []int {
+	if len(c.Inlined) == len(c.Instructions) {
+		return c.Inlined
+	}
+	return make([]int, len(c.Instructions))
+}
+
 // Code is the type of code literals (i.e. function definitions).
 type Code struct {
 	Instructions []Instruction
 	Lines        []int
+	Columns      []int // Optional: source column for each instruction (0 if unknown)
+	Inlined      []int // Optional: inline frame of each instruction (see InlineFrames)
+	InlineFrames []InlineFrame
 	Constants    []Constant
 	UpvalueDests []Register
 	Registers    []RegData
@@ -68,6 +89,15 @@ type Code struct {
 	Name         string
 }
 
+// An InlineFrame is a call to a function whose body was compiled in place of
+// the call.  Inline frames are numbered from 1 in the order of
+// Code.InlineFrames, 0 standing for the function itself.
+type InlineFrame struct {
+	Name         string // Name of the inlined function
+	Line, Column int    // Position of the call
+	Parent       int    // Inline frame the call is made from
+}
+
 // ProcessConstant uses the given ConstantProcessor to process the receiver.
 func (c Code) ProcessConstant(p ConstantProcessor) {
 	p.ProcessCode(c)
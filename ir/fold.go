@@ -27,37 +27,42 @@ func FoldCode(c Code, f FoldFunc) Code {
 	}
 	var s foldStack
 	var i1 Instruction
-	var l1 int
+	var p1 position
+	columns, inlined := c.columns(), c.inlined()
 	for i, i2 := range c.Instructions {
-		l2 := c.Lines[i]
+		p2 := position{line: c.Lines[i], column: columns[i], frame: inlined[i]}
 		if i1 != nil {
 			i1, i2 = f(i1, i2, c.Registers)
 			switch {
 			case i1 == nil && i2 == nil:
 				// Folded to nothing, pop from the stack to be able to fold the
 				// next instruction.
-				l2, i2 = s.pop()
+				p2, i2 = s.pop()
 			case i1 == nil:
 				// Folded to i2
-				l2 = mergeLines(l1, l2)
+				p2 = mergePositions(p1, p2)
 			case i2 == nil:
 				// Folded to i1
 				i1, i2 = nil, i1
-				l2 = mergeLines(l1, l2)
+				p2 = mergePositions(p1, p2)
 			default:
 				// Not folded
 			}
 		}
 		if i1 != nil {
-			s.push(l1, i1)
+			s.push(p1, i1)
 		}
 		i1 = i2
-		l1 = l2
+		p1 = p2
 	}
 	if i1 != nil {
-		s.push(l1, i1)
+		s.push(p1, i1)
 	}
 	c.Lines = s.lines
+	c.Columns = s.columns
+	if c.InlineFrames != nil {
+		c.Inlined = s.inlined
+	}
 	c.Instructions = s.instructions
 	return c
 }
@@ -125,11 +130,15 @@ func ComposeFolds(fs ...FoldFunc) FoldFunc {
 
 type foldStack struct {
 	lines        []int
+	columns      []int
+	inlined      []int
 	instructions []Instruction
 }
 
-func (s *foldStack) push(l int, i Instruction) {
-	s.lines = append(s.lines, l)
+func (s *foldStack) push(p position, i Instruction) {
+	s.lines = append(s.lines, p.line)
+	s.columns = append(s.columns, p.column)
+	s.inlined = append(s.inlined, p.frame)
 	s.instructions = append(s.instructions, i)
 }
 
@@ -137,21 +146,31 @@ func (s *foldStack) empty() bool {
 	return len(s.instructions) == 0
 }
 
-func (s *foldStack) pop() (l int, i Instruction) {
+func (s *foldStack) pop() (p position, i Instruction) {
 	last := len(s.instructions) - 1
 	if last < 0 {
 		return
 	}
-	l = s.lines[last]
+	p = position{line: s.lines[last], column: s.columns[last], frame: s.inlined[last]}
 	i = s.instructions[last]
 	s.lines = s.lines[:last]
+	s.columns = s.columns[:last]
+	s.inlined = s.inlined[:last]
 	s.instructions = s.instructions[:last]
 	return
 }
 
-func mergeLines(l1, l2 int) int {
-	if l1 != 0 {
-		return l1
+// The position of an instruction is its line and column in the source code
+// and the inline frame it belongs to.
+type position struct {
+	line, column, frame int
+}
+
+// mergePositions returns the position of an instruction made from two
+// instructions with positions p1 and p2.
+func mergePositions(p1, p2 position) position {
+	if p1.line != 0 {
+		return p1
 	}
-	return l2
+	return p2
 }
@@ -289,9 +289,12 @@ func (i ClearReg) String() string {
 	return fmt.Sprintf("clrreg(%s)", i.Dst)
 }
 
-// MkTable creates a new empty table and puts it i Dst.
+// MkTable creates a new empty table and puts it i Dst.  ArraySize and HashSize
+// are hints for how many items the table will contain.
 type MkTable struct {
-	Dst Register
+	Dst       Register
+	ArraySize int
+	HashSize  int
 }
 
 // ProcessInstr makes the InstrProcessor process this instruction.
@@ -300,6 +303,9 @@ func (m MkTable) ProcessInstr(p InstrProcessor) {
 }
 
 func (m MkTable) String() string {
+	if m.ArraySize != 0 || m.HashSize != 0 {
+		return fmt.Sprintf("%s := mktable(%d, %d)", m.Dst, m.ArraySize, m.HashSize)
+	}
 	return fmt.Sprintf("%s := mktable()", m.Dst)
 }
 
@@ -0,0 +1,959 @@
+package ir
+
+import (
+	"github.com/arnodel/golua/ops"
+)
+
+// Optimizations is a set of optimization passes that can be applied to IR code
+// before it is compiled.  Each pass can be enabled individually.
+type Optimizations uint
+
+const (
+	// OptDeadCode removes instructions which cannot be reached (e.g. after an
+	// unconditional jump or a tail call) and definitions of registers whose
+	// values are never used.
+	OptDeadCode Optimizations = 1 << iota
+
+	// OptJumpThreading makes jumps to other jumps go directly to their final
+	// destination and removes jumps to the next instruction.
+	OptJumpThreading
+
+	// OptCopyPropagation removes register moves by reading directly from the
+	// source register or writing directly to the destination register.
+	OptCopyPropagation
+
+	// OptConstPropagation replaces reads of registers holding a constant value
+	// (e.g. locals declared <const>) with the constant and folds operations
+	// whose operands are constant.
+	OptConstPropagation
+
+	// OptClearReg removes instructions clearing registers when the function is
+	// about to return.
+	OptClearReg
+
+	// OptInline inlines calls to small local functions and immediately invoked
+	// function expressions.  Unlike the other optimizations, it is performed
+	// when compiling the AST to IR code (see astcomp.InlineFunctions).
+	OptInline
+
+	// OptSpecialize compiles common patterns (e.g. adding a small integer
+	// constant or indexing a table with a constant string) to specialized
+	// opcodes.  It is performed when compiling IR code to opcodes (see
+	// ircomp.ConstantCompiler.SetSpecialize).
+	OptSpecialize
+)
+
+const (
+	// NoOptimizations disables all optimization passes.
+	NoOptimizations Optimizations = 0
+
+	// AllOptimizations enables all optimization passes.
+	AllOptimizations = OptDeadCode | OptJumpThreading | OptCopyPropagation | OptConstPropagation | OptClearReg | OptInline | OptSpecialize
+
+	// DefaultOptimizations is the set of passes used when compiling chunks
+	// unless specified otherwise.  Inlining is not included because it must be
+	// asked for explicitly: it makes the code bigger and changes the number of
+	// instructions executed, which count hooks and CPU quotas can see.
+	DefaultOptimizations = AllOptimizations &^ OptInline
+)
+
+// Has returns true if all the optimizations in opts are enabled.
+func (o Optimizations) Has(opts Optimizations) bool {
+	return o&opts == opts
+}
+
+// The passes are repeated until they make no more change, but no more than
+// this many times.
+const maxOptimizationRounds = 10
+
+// OptimizeConstants applies the given optimizations to the code items in the
+// given constant slice.  It returns a new constant slice, which may contain
+// more constants than the original one (e.g. the results of folding constant
+// expressions).  Indices of existing constants are preserved.
+//
+// If requireCPU is not nil, it is called with the amount of work done as the
+// optimizer progresses (roughly one unit per instruction examined), so that it
+// can be charged to a CPU quota.
+func OptimizeConstants(consts []Constant, opts Optimizations, requireCPU func(uint64)) []Constant {
+	if opts == NoOptimizations {
+		return consts
+	}
+	pool := &ConstantPool{
+		constants: make([]Constant, len(consts)),
+		kmap:      make(map[Constant]uint, len(consts)),
+	}
+	copy(pool.constants, consts)
+	for i, k := range consts {
+		if _, ok := pool.kmap[k]; !ok {
+			pool.kmap[k] = uint(i)
+		}
+	}
+	for i, k := range consts {
+		if c, ok := k.(*Code); ok {
+			oc := optimizeCode(*c, pool, opts, requireCPU)
+			pool.constants[i] = &oc
+		}
+	}
+	return pool.constants
+}
+
+// OptimizeCode applies the given optimizations to the given code, which uses
+// the given constant slice.  It returns the new code and the new constant slice
+// (see OptimizeConstants, which also explains requireCPU).
+func OptimizeCode(c Code, consts []Constant, opts Optimizations, requireCPU func(uint64)) (Code, []Constant) {
+	pool := &ConstantPool{
+		constants: append([]Constant(nil), consts...),
+		kmap:      make(map[Constant]uint, len(consts)),
+	}
+	for i, k := range consts {
+		if _, ok := pool.kmap[k]; !ok {
+			pool.kmap[k] = uint(i)
+		}
+	}
+	return optimizeCode(c, pool, opts, requireCPU), pool.constants
+}
+
+func optimizeCode(c Code, pool *ConstantPool, opts Optimizations, requireCPU func(uint64)) Code {
+	o := &optimizer{
+		requireCPU: requireCPU,
+		instrs:     append([]Instruction(nil), c.Instructions...),
+		lines:      append([]int(nil), c.Lines...),
+		cols:       append([]int(nil), c.columns()...),
+		frames:     append([]int(nil), c.inlined()...),
+		regs:       c.Registers,
+		pool:       pool,
+	}
+	if !o.analyse() {
+		// The code contains instructions that the optimizer doesn't know
+		// about, so it is safer to leave it alone.
+		return c
+	}
+	o.frameCounts = make([]int, len(c.InlineFrames)+1)
+	o.countFrames()
+	o.defined = make([]bool, len(o.regs))
+	for r := range o.regs {
+		o.defined[r] = o.defCounts[r] > 0
+	}
+	for i := 0; i < maxOptimizationRounds; i++ {
+		changed := false
+		if opts.Has(OptConstPropagation) {
+			changed = o.propagateConstants() || changed
+		}
+		if opts.Has(OptCopyPropagation) {
+			changed = o.propagateCopies() || changed
+		}
+		if opts.Has(OptJumpThreading) {
+			changed = o.threadJumps() || changed
+		}
+		if opts.Has(OptDeadCode) {
+			changed = o.removeDeadCode() || changed
+		}
+		if opts.Has(OptClearReg) {
+			changed = o.removeClearRegs() || changed
+		}
+		if !changed {
+			break
+		}
+	}
+	c.Instructions = o.instrs
+	c.Lines = o.lines
+	c.Columns = o.cols
+	if c.InlineFrames != nil {
+		c.Inlined = o.frames
+	}
+	return c
+}
+
+// The optimizer holds the code being optimized.  Passes remove instructions by
+// setting them to nil, then call compact().
+type optimizer struct {
+	instrs []Instruction
+	lines  []int
+	cols   []int
+	frames []int
+	regs   []RegData
+	pool   *ConstantPool
+
+	requireCPU func(uint64) // Called with the amount of work done, may be nil
+
+	defCounts   []int  // Number of instructions setting each register
+	useCounts   []int  // Number of instructions reading each register
+	defined     []bool // Registers explicitly set by the original code
+	frameCounts []int  // Number of instructions in each inline frame
+}
+
+// analyse counts the definitions and uses of each register.  It returns false
+// if some instruction is not understood.
+func (o *optimizer) analyse() bool {
+	o.defCounts = make([]int, len(o.regs))
+	o.useCounts = make([]int, len(o.regs))
+	o.require(len(o.instrs))
+	for _, instr := range o.instrs {
+		defs, uses, ok := regUsage(instr)
+		if !ok {
+			return false
+		}
+		for _, r := range defs {
+			o.defCounts[r]++
+		}
+		for _, r := range uses {
+			o.useCounts[r]++
+		}
+	}
+	return true
+}
+
+// require accounts for n units of work.
+func (o *optimizer) require(n int) {
+	if o.requireCPU != nil {
+		o.requireCPU(uint64(n))
+	}
+}
+
+func (o *optimizer) compact() {
+	j := 0
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		if instr != nil {
+			o.instrs[j] = instr
+			o.lines[j] = o.lines[i]
+			o.cols[j] = o.cols[i]
+			o.frames[j] = o.frames[i]
+			j++
+		}
+	}
+	o.instrs = o.instrs[:j]
+	o.lines = o.lines[:j]
+	o.cols = o.cols[:j]
+	o.frames = o.frames[:j]
+	o.analyse()
+	o.countFrames()
+}
+
+// countFrames counts the instructions in each inline frame, not including hints
+// and labels as they do not produce any code.
+func (o *optimizer) countFrames() {
+	for f := range o.frameCounts {
+		o.frameCounts[f] = 0
+	}
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		switch instr.(type) {
+		case nil, TakeRegister, ReleaseRegister, DeclareLabel:
+		default:
+			o.frameCounts[o.frames[i]]++
+		}
+	}
+}
+
+// frameCovered returns true if removing the instruction at i would not remove
+// its inline frame from the code, so that debug hooks still see the call.
+func (o *optimizer) frameCovered(i int) bool {
+	frame := o.frames[i]
+	return frame == 0 || o.frameCounts[frame] > 1
+}
+
+// mergeable returns true if the instructions at i and j can be replaced with a
+// single instruction without removing an inline frame from the code.
+func (o *optimizer) mergeable(i, j int) bool {
+	return o.frames[i] == o.frames[j] || o.frameCovered(i) && o.frameCovered(j)
+}
+
+// remove removes the instruction at i, which must not be a hint or a label.
+func (o *optimizer) remove(i int) {
+	o.instrs[i] = nil
+	o.frameCounts[o.frames[i]]--
+}
+
+func (o *optimizer) position(i int) position {
+	return position{line: o.lines[i], column: o.cols[i], frame: o.frames[i]}
+}
+
+// setPosition sets the position of the instruction at i, which must not be a
+// hint or a label.
+func (o *optimizer) setPosition(i int, p position) {
+	o.frameCounts[o.frames[i]]--
+	o.lines[i], o.cols[i], o.frames[i] = p.line, p.column, p.frame
+	o.frameCounts[p.frame]++
+}
+
+// next returns the index of the first instruction after i which is not a hint
+// or a label, or len(o.instrs) if there is none.
+func (o *optimizer) next(i int) int {
+	for i++; i < len(o.instrs); i++ {
+		o.require(1)
+		switch o.instrs[i].(type) {
+		case nil, TakeRegister, ReleaseRegister, DeclareLabel:
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// nextReal returns the index of the first instruction after i which is not a
+// hint, or len(o.instrs) if there is none.
+func (o *optimizer) nextReal(i int) int {
+	for i++; i < len(o.instrs); i++ {
+		o.require(1)
+		switch o.instrs[i].(type) {
+		case nil, TakeRegister, ReleaseRegister:
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// A register is "simple" if it is not a cell and it is set exactly once.
+func (o *optimizer) isSimple(r Register) bool {
+	return !o.regs[r].IsCell && o.defCounts[r] == 1
+}
+
+// propagateConstants replaces operations on registers which are set once from
+// a constant with the result of the operation.
+func (o *optimizer) propagateConstants() bool {
+	consts := make(map[Register]Constant)
+	o.require(len(o.instrs))
+	for _, instr := range o.instrs {
+		if lc, ok := instr.(LoadConst); ok && o.isSimple(lc.Dst) {
+			consts[lc.Dst] = o.pool.constants[lc.Kidx]
+		}
+	}
+	if len(consts) == 0 {
+		return false
+	}
+	changed := false
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		switch x := instr.(type) {
+		case Transform:
+			k, ok := consts[x.Src]
+			if !ok {
+				continue
+			}
+			if x.Op != ops.OpId {
+				if k, ok = foldUnOp(x.Op, k); !ok {
+					continue
+				}
+			}
+			o.instrs[i] = LoadConst{Dst: x.Dst, Kidx: o.pool.GetConstantIndex(k)}
+		case Combine:
+			k1, ok1 := consts[x.Lsrc]
+			k2, ok2 := consts[x.Rsrc]
+			if !ok1 || !ok2 {
+				continue
+			}
+			k, ok := foldBinOp(x.Op, k1, k2)
+			if !ok {
+				continue
+			}
+			o.instrs[i] = LoadConst{Dst: x.Dst, Kidx: o.pool.GetConstantIndex(k)}
+		case JumpIf:
+			k, ok := consts[x.Cond]
+			if !ok {
+				continue
+			}
+			if isTrue(k) != x.Not {
+				o.instrs[i] = Jump{Label: x.Label}
+			} else if o.frameCovered(i) {
+				o.remove(i)
+			} else {
+				continue
+			}
+		default:
+			continue
+		}
+		changed = true
+	}
+	if changed {
+		o.compact()
+	}
+	return changed
+}
+
+func isTrue(k Constant) bool {
+	switch x := k.(type) {
+	case NilType:
+		return false
+	case Bool:
+		return bool(x)
+	default:
+		return true
+	}
+}
+
+// foldUnOp computes the result of a unary operation on a constant, if it can be
+// done safely at compile time.
+func foldUnOp(op ops.Op, k Constant) (Constant, bool) {
+	switch op {
+	case ops.OpNeg:
+		switch x := k.(type) {
+		case Int:
+			return -x, true
+		case Float:
+			return -x, true
+		}
+	case ops.OpNot:
+		return Bool(!isTrue(k)), true
+	case ops.OpLen:
+		if s, ok := k.(String); ok {
+			return Int(len(s)), true
+		}
+	}
+	return nil, false
+}
+
+// foldBinOp computes the result of a binary operation on two constants, if it
+// can be done safely at compile time (i.e. it cannot fail or trigger a
+// metamethod).
+func foldBinOp(op ops.Op, k1, k2 Constant) (Constant, bool) {
+	switch op {
+	case ops.OpEq, ops.OpNeq:
+		eq, ok := constEqual(k1, k2)
+		if !ok {
+			return nil, false
+		}
+		return Bool(eq == (op == ops.OpEq)), true
+	case ops.OpConcat:
+		s1, ok1 := k1.(String)
+		s2, ok2 := k2.(String)
+		if ok1 && ok2 {
+			return s1 + s2, true
+		}
+		return nil, false
+	}
+	n1, ok1 := k1.(Int)
+	n2, ok2 := k2.(Int)
+	if ok1 && ok2 {
+		switch op {
+		case ops.OpAdd:
+			return n1 + n2, true
+		case ops.OpSub:
+			return n1 - n2, true
+		case ops.OpMul:
+			return n1 * n2, true
+		case ops.OpLt:
+			return Bool(n1 < n2), true
+		case ops.OpLeq:
+			return Bool(n1 <= n2), true
+		case ops.OpGt:
+			return Bool(n1 > n2), true
+		case ops.OpGeq:
+			return Bool(n1 >= n2), true
+		}
+	}
+	x1, ok1 := constToFloat(k1)
+	x2, ok2 := constToFloat(k2)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	switch op {
+	case ops.OpAdd:
+		return Float(x1 + x2), true
+	case ops.OpSub:
+		return Float(x1 - x2), true
+	case ops.OpMul:
+		return Float(x1 * x2), true
+	case ops.OpDiv:
+		if x2 == 0 {
+			// Leave it to the runtime to produce infinities and NaNs.
+			return nil, false
+		}
+		return Float(x1 / x2), true
+	}
+	return nil, false
+}
+
+func constToFloat(k Constant) (float64, bool) {
+	switch x := k.(type) {
+	case Int:
+		return float64(x), true
+	case Float:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// constEqual returns whether two constants are equal.  The second return value
+// is false when it is not known.
+func constEqual(k1, k2 Constant) (bool, bool) {
+	if _, ok := k1.(*Code); ok {
+		return false, false
+	}
+	if _, ok := k2.(*Code); ok {
+		return false, false
+	}
+	_, isInt1 := k1.(Int)
+	_, isInt2 := k2.(Int)
+	_, isFloat1 := k1.(Float)
+	_, isFloat2 := k2.(Float)
+	if isInt1 && isFloat2 || isFloat1 && isInt2 {
+		// Leave it to the runtime to compare integers with floats.
+		return false, false
+	}
+	return k1 == k2, true
+}
+
+// propagateCopies removes register moves in the following two situations
+// (where r1 is only used once and only set once, and "..." stands for register
+// hints).
+//
+//	r1 <- X; ...; r2 <- r1 ==> r2 <- X; ...
+//	r1 <- r2; ...; X(r1) ==> X(r2)
+func (o *optimizer) propagateCopies() bool {
+	changed := false
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		if instr == nil {
+			continue
+		}
+		j := o.nextReal(i)
+		if j >= len(o.instrs) {
+			break
+		}
+		// Coalesce a register into the register it is moved to.
+		if sr, ok := instr.(SetRegInstruction); ok {
+			t := sr.DestReg()
+			mv, ok := o.instrs[j].(Transform)
+			_, isClosure := instr.(MkClosure)
+			if ok && !isClosure && mv.Op == ops.OpId && mv.Src == t && mv.Dst != t &&
+				o.isSimple(t) && o.useCounts[t] == 1 && o.mergeable(i, j) {
+				p := mergePositions(o.position(i), o.position(j))
+				o.remove(i)
+				o.instrs[j] = sr.WithDestReg(mv.Dst)
+				o.setPosition(j, p)
+				o.defCounts[t] = 0
+				o.useCounts[t] = 0
+				changed = true
+				continue
+			}
+		}
+		// Read from the source of a move directly.
+		if mv, ok := instr.(Transform); ok && mv.Op == ops.OpId {
+			t := mv.Dst
+			if !o.isSimple(t) || o.useCounts[t] != 1 || mv.Src == t {
+				continue
+			}
+			if defs, uses, _ := regUsage(o.instrs[j]); containsReg(defs, t) || !containsReg(uses, t) {
+				continue
+			}
+			if !o.frameCovered(i) {
+				continue
+			}
+			if renamed, ok := renameUse(o.instrs[j], t, mv.Src); ok {
+				o.remove(i)
+				o.instrs[j] = renamed
+				o.defCounts[t] = 0
+				o.useCounts[t] = 0
+				changed = true
+			}
+		}
+	}
+	if changed {
+		o.compact()
+	}
+	return changed
+}
+
+func containsReg(regs []Register, r Register) bool {
+	for _, r1 := range regs {
+		if r1 == r {
+			return true
+		}
+	}
+	return false
+}
+
+// threadJumps shortens chains of jumps.  It also removes jumps to the next
+// instruction and labels no longer used by any jump.
+func (o *optimizer) threadJumps() bool {
+	changed := false
+	labelPos := o.labelPositions()
+
+	// final returns the label that a jump to lbl eventually reaches.
+	final := func(lbl Label) Label {
+		seen := map[Label]bool{}
+		for !seen[lbl] {
+			seen[lbl] = true
+			pos, ok := labelPos[lbl]
+			if !ok {
+				break
+			}
+			j := o.next(pos)
+			if j >= len(o.instrs) {
+				break
+			}
+			jmp, ok := o.instrs[j].(Jump)
+			if !ok {
+				break
+			}
+			lbl = jmp.Label
+		}
+		return lbl
+	}
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		switch x := instr.(type) {
+		case Jump:
+			if lbl := final(x.Label); lbl != x.Label {
+				x.Label = lbl
+				o.instrs[i] = x
+				changed = true
+			}
+		case JumpIf:
+			if lbl := final(x.Label); lbl != x.Label {
+				x.Label = lbl
+				o.instrs[i] = x
+				changed = true
+			}
+			// if c jump L1; jump L2; L1: ==> if not c jump L2; L1:
+			j := o.nextReal(i)
+			if j >= len(o.instrs) {
+				continue
+			}
+			if jmp, ok := o.instrs[j].(Jump); ok && o.labelFollows(j, x.Label) && o.mergeable(i, j) {
+				o.instrs[i] = JumpIf{Cond: x.Cond, Label: jmp.Label, Not: !x.Not}
+				o.remove(j)
+				changed = true
+			}
+		}
+	}
+	// Remove jumps to the next instruction.  A conditional jump can be removed
+	// as well because evaluating the condition has no side effects.
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		var lbl Label
+		switch x := instr.(type) {
+		case Jump:
+			lbl = x.Label
+		case JumpIf:
+			lbl = x.Label
+		default:
+			continue
+		}
+		if o.labelFollows(i, lbl) && o.frameCovered(i) {
+			o.remove(i)
+			changed = true
+		}
+	}
+	// Remove labels that are not jumped to.
+	used := o.usedLabels()
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		if lbl, ok := instr.(DeclareLabel); ok && !used[lbl.Label] {
+			o.instrs[i] = nil
+			changed = true
+		}
+	}
+	if changed {
+		o.compact()
+	}
+	return changed
+}
+
+// labelFollows returns true if lbl is declared after the instruction at i with
+// only hints and labels in between.
+func (o *optimizer) labelFollows(i int, lbl Label) bool {
+	for i++; i < len(o.instrs); i++ {
+		o.require(1)
+		switch x := o.instrs[i].(type) {
+		case nil, TakeRegister, ReleaseRegister:
+		case DeclareLabel:
+			if x.Label == lbl {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func (o *optimizer) labelPositions() map[Label]int {
+	pos := make(map[Label]int)
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		if lbl, ok := instr.(DeclareLabel); ok {
+			pos[lbl.Label] = i
+		}
+	}
+	return pos
+}
+
+func (o *optimizer) usedLabels() map[Label]bool {
+	used := make(map[Label]bool)
+	o.require(len(o.instrs))
+	for _, instr := range o.instrs {
+		switch x := instr.(type) {
+		case Jump:
+			used[x.Label] = true
+		case JumpIf:
+			used[x.Label] = true
+		}
+	}
+	return used
+}
+
+// removeDeadCode removes unreachable instructions and instructions with no side
+// effects whose result is not used.  Register hints are kept (as they drive
+// register allocation) unless the register is no longer used at all.
+func (o *optimizer) removeDeadCode() bool {
+	changed := false
+	used := o.usedLabels()
+	reachable := true
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		switch x := instr.(type) {
+		case TakeRegister, ReleaseRegister:
+			continue
+		case DeclareLabel:
+			if used[x.Label] {
+				reachable = true
+				continue
+			}
+		}
+		if !reachable {
+			o.instrs[i] = nil
+			changed = true
+			continue
+		}
+		switch x := instr.(type) {
+		case Jump:
+			reachable = false
+		case Call:
+			reachable = !x.Tail
+		}
+	}
+	if changed {
+		o.compact()
+	}
+	// Remove definitions of unused registers until there are none left.
+	for {
+		removed := false
+		o.require(len(o.instrs))
+		for i, instr := range o.instrs {
+			if !isPure(instr) {
+				continue
+			}
+			dst := instr.(SetRegInstruction).DestReg()
+			if o.regs[dst].IsCell || o.useCounts[dst] > 0 || !o.lineCovered(i) || !o.frameCovered(i) {
+				continue
+			}
+			o.remove(i)
+			removed = true
+		}
+		if !removed {
+			break
+		}
+		changed = true
+		o.compact()
+	}
+	// Remove hints for registers which no longer appear in the code.  Some
+	// registers are set implicitly (e.g. r0 holds the continuation of the
+	// function) so only registers which used to be set explicitly are
+	// considered.
+	removed := false
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		if r, ok := hintReg(instr); ok && o.defined[r] && o.defCounts[r] == 0 && o.useCounts[r] == 0 {
+			o.instrs[i] = nil
+			removed = true
+		}
+	}
+	if removed {
+		changed = true
+		o.compact()
+	}
+	return changed
+}
+
+// lineCovered returns true if removing the instruction at i would not remove
+// its line from the code, so that debug line hooks still see the line.
+func (o *optimizer) lineCovered(i int) bool {
+	line, frame := o.lines[i], o.frames[i]
+	if line == 0 {
+		return true
+	}
+	for j := i - 1; j >= 0; j-- {
+		o.require(1)
+		switch o.instrs[j].(type) {
+		case nil, TakeRegister, ReleaseRegister:
+			continue
+		case DeclareLabel:
+		default:
+			if o.lines[j] == line && o.frames[j] == frame {
+				return true
+			}
+		}
+		break
+	}
+	j := o.nextReal(i)
+	return j < len(o.instrs) && o.lines[j] == line && o.frames[j] == frame
+}
+
+// isPure returns true if the instruction only sets a register and has no other
+// effect.
+func isPure(instr Instruction) bool {
+	switch x := instr.(type) {
+	case LoadConst, MkClosure, EtcLookup:
+		return true
+	case Transform:
+		return x.Op == ops.OpId || x.Op == ops.OpNot
+	}
+	return false
+}
+
+// removeClearRegs removes ClearReg instructions which are followed by the
+// function returning, as clearing the register is then useless.
+func (o *optimizer) removeClearRegs() bool {
+	changed := false
+	o.require(len(o.instrs))
+	for i, instr := range o.instrs {
+		if _, ok := instr.(ClearReg); !ok {
+			continue
+		}
+		if o.returnsAfter(i) && o.frameCovered(i) {
+			o.remove(i)
+			changed = true
+		}
+	}
+	if changed {
+		o.compact()
+	}
+	return changed
+}
+
+// returnsAfter returns true if the code after the instruction at i returns
+// without reading any register (apart from the tail call continuation).
+func (o *optimizer) returnsAfter(i int) bool {
+	for i++; i < len(o.instrs); i++ {
+		o.require(1)
+		switch x := o.instrs[i].(type) {
+		case nil, TakeRegister, ReleaseRegister, ClearReg, TruncateCloseStack:
+		case Call:
+			return x.Tail
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func hintReg(instr Instruction) (Register, bool) {
+	switch x := instr.(type) {
+	case TakeRegister:
+		return x.Reg, true
+	case ReleaseRegister:
+		return x.Reg, true
+	}
+	return 0, false
+}
+
+// RegisterUsage returns the registers that instr sets and the registers that it
+// reads.  Hints neither set nor read registers.
+func RegisterUsage(instr Instruction) (defs, uses []Register) {
+	defs, uses, _ = regUsage(instr)
+	return
+}
+
+// regUsage returns the registers set and read by an instruction.  It returns
+// false if the instruction is not known.
+func regUsage(instr Instruction) (defs, uses []Register, ok bool) {
+	switch x := instr.(type) {
+	case Combine:
+		return []Register{x.Dst}, []Register{x.Lsrc, x.Rsrc}, true
+	case Transform:
+		return []Register{x.Dst}, []Register{x.Src}, true
+	case LoadConst:
+		return []Register{x.Dst}, nil, true
+	case Push:
+		return nil, []Register{x.Cont, x.Item}, true
+	case Jump:
+		return nil, nil, true
+	case JumpIf:
+		return nil, []Register{x.Cond}, true
+	case Call:
+		return nil, []Register{x.Cont}, true
+	case MkClosure:
+		return []Register{x.Dst}, x.Upvalues, true
+	case MkCont:
+		return []Register{x.Dst}, []Register{x.Closure}, true
+	case ClearReg:
+		return []Register{x.Dst}, nil, true
+	case MkTable:
+		return []Register{x.Dst}, nil, true
+	case Lookup:
+		return []Register{x.Dst}, []Register{x.Table, x.Index}, true
+	case SetIndex:
+		return nil, []Register{x.Table, x.Index, x.Src}, true
+	case Receive:
+		return x.Dst, nil, true
+	case ReceiveEtc:
+		return append(append([]Register(nil), x.Dst...), x.Etc), nil, true
+	case EtcLookup:
+		return []Register{x.Dst}, []Register{x.Etc}, true
+	case FillTable:
+		return nil, []Register{x.Dst, x.Etc}, true
+	case TruncateCloseStack:
+		return nil, nil, true
+	case PushCloseStack:
+		return nil, []Register{x.Src}, true
+	case PrepForLoop:
+		regs := []Register{x.Start, x.Stop, x.Step}
+		return regs, regs, true
+	case AdvForLoop:
+		regs := []Register{x.Start, x.Stop, x.Step}
+		return regs, regs, true
+	case TakeRegister, ReleaseRegister, DeclareLabel:
+		return nil, nil, true
+	}
+	return nil, nil, false
+}
+
+// renameUse returns a copy of the instruction reading register to instead of
+// register from.  It returns false if that cannot be done.
+func renameUse(instr Instruction, from, to Register) (Instruction, bool) {
+	r := func(reg Register) Register {
+		if reg == from {
+			return to
+		}
+		return reg
+	}
+	switch x := instr.(type) {
+	case Combine:
+		x.Lsrc, x.Rsrc = r(x.Lsrc), r(x.Rsrc)
+		return x, true
+	case Transform:
+		x.Src = r(x.Src)
+		return x, true
+	case Push:
+		x.Cont, x.Item = r(x.Cont), r(x.Item)
+		return x, true
+	case JumpIf:
+		x.Cond = r(x.Cond)
+		return x, true
+	case Call:
+		x.Cont = r(x.Cont)
+		return x, true
+	case MkCont:
+		x.Closure = r(x.Closure)
+		return x, true
+	case Lookup:
+		x.Table, x.Index = r(x.Table), r(x.Index)
+		return x, true
+	case SetIndex:
+		x.Table, x.Index, x.Src = r(x.Table), r(x.Index), r(x.Src)
+		return x, true
+	case EtcLookup:
+		x.Etc = r(x.Etc)
+		return x, true
+	case FillTable:
+		x.Dst, x.Etc = r(x.Dst), r(x.Etc)
+		return x, true
+	case PushCloseStack:
+		x.Src = r(x.Src)
+		return x, true
+	}
+	return nil, false
+}
@@ -12,21 +12,32 @@ import (
 type instrCompiler struct {
 	*ConstantCompiler
 	*regAllocator
-	line int
+	spec   *specializer // nil if specialized opcodes are not emitted
+	index  int          // index of the instruction being compiled
+	line   int          // source line of the instruction being compiled
+	column int          // source column of the instruction being compiled
 }
 
 var _ ir.InstrProcessor = instrCompiler{}
 
 func (ic instrCompiler) Emit(opcode code.Opcode) {
-	ic.builder.Emit(opcode, ic.line)
+	ic.builder.Emit(opcode, ic.line, ic.column)
 }
 
 func (ic instrCompiler) EmitJump(opcode code.Opcode, lbl code.Label) {
-	ic.builder.EmitJump(opcode, lbl, ic.line)
+	ic.builder.EmitJump(opcode, lbl, ic.line, ic.column)
 }
 
 // ProcessCombineInstr compiles a Combine instruction.
 func (ic instrCompiler) ProcessCombineInstr(c ir.Combine) {
+	if n, ok := ic.foldedOperand(); ok {
+		emitInt8 := code.AddInt8
+		if c.Op == ops.OpSub {
+			emitInt8 = code.SubInt8
+		}
+		ic.Emit(emitInt8(ic.codeReg(c.Dst), ic.codeReg(c.Lsrc), n.ToInt8()))
+		return
+	}
 	codeOp, ok := codeBinOp[c.Op]
 	if !ok {
 		panic(fmt.Sprintf("Cannot compile %v: invalid op", c))
@@ -75,6 +86,9 @@ var codeUnOp = map[ops.Op]code.UnOp{
 
 // ProcessLoadConstInstr compiles a LoadConst instruction.
 func (ic instrCompiler) ProcessLoadConstInstr(l ir.LoadConst) {
+	if ic.spec != nil && ic.spec.foldConstant(ic.ConstantCompiler, ic.index, l) {
+		return
+	}
 	k := ic.GetConstant(l.Kidx)
 	dst := ic.codeReg(l.Dst)
 	var opcode code.Opcode
@@ -166,18 +180,26 @@ func (ic instrCompiler) ProcessClearRegInstr(i ir.ClearReg) {
 
 // ProcessMkTableInstr compiles a MkTable instruction.
 func (ic instrCompiler) ProcessMkTableInstr(m ir.MkTable) {
-	opcode := code.LoadEmptyTable(ic.codeReg(m.Dst))
+	opcode := code.LoadTable(ic.codeReg(m.Dst), m.ArraySize, m.HashSize)
 	ic.Emit(opcode)
 }
 
 // ProcessLookupInstr compiles a Lookup instruction.
 func (ic instrCompiler) ProcessLookupInstr(s ir.Lookup) {
+	if ki, ok := ic.foldedOperand(); ok {
+		ic.Emit(code.LoadLookupK(ic.codeReg(s.Dst), ic.codeReg(s.Table), code.KIndex(ki)))
+		return
+	}
 	opcode := code.LoadLookup(ic.codeReg(s.Dst), ic.codeReg(s.Table), ic.codeReg(s.Index))
 	ic.Emit(opcode)
 }
 
 // ProcessSetIndexInstr compiles a SetIndex instruction.
 func (ic instrCompiler) ProcessSetIndexInstr(s ir.SetIndex) {
+	if ki, ok := ic.foldedOperand(); ok {
+		ic.Emit(code.SetIndexK(ic.codeReg(s.Src), ic.codeReg(s.Table), code.KIndex(ki)))
+		return
+	}
 	opcode := code.SetIndex(ic.codeReg(s.Src), ic.codeReg(s.Table), ic.codeReg(s.Index))
 	ic.Emit(opcode)
 }
@@ -233,9 +255,24 @@ func (ic instrCompiler) ProcessPrepForLoopInstr(i ir.PrepForLoop) {
 
 // ProcessAdvForLoopInstr compiles an AdvForLoop instruction.
 func (ic instrCompiler) ProcessAdvForLoopInstr(i ir.AdvForLoop) {
+	if ic.spec != nil {
+		if step, ok := ic.spec.forLoopStep(ic.ConstantCompiler, i.Step); ok {
+			ic.Emit(code.AdvForLoopInt(ic.codeReg(i.Start), ic.codeReg(i.Stop), step))
+			return
+		}
+	}
 	ic.Emit(code.AdvForLoop(ic.codeReg(i.Start), ic.codeReg(i.Stop), ic.codeReg(i.Step)))
 }
 
+// foldedOperand returns the operand folded into the current instruction by the
+// specializer, if any.
+func (ic instrCompiler) foldedOperand() (code.Index8, bool) {
+	if ic.spec == nil {
+		return 0, false
+	}
+	return ic.spec.foldedOperand(ic.index)
+}
+
 func (ic instrCompiler) ProcessTakeRegisterInstr(t ir.TakeRegister) {
 	ic.takeRegister(t.Reg)
 }
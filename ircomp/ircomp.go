@@ -12,6 +12,7 @@ type ConstantCompiler struct {
 	compiledCount int
 	queue         []uint
 	offset        int
+	specialize    bool
 }
 
 var _ ir.ConstantProcessor = (*ConstantCompiler)(nil)
@@ -25,6 +26,13 @@ func NewConstantCompiler(constants []ir.Constant, cc *code.Builder) *ConstantCom
 	return kc
 }
 
+// SetSpecialize sets whether specialized opcodes are emitted for common
+// patterns, such as adding a small integer constant or indexing a table with a
+// constant string.
+func (kc *ConstantCompiler) SetSpecialize(specialize bool) {
+	kc.specialize = specialize
+}
+
 // ProcessFloat compiles a Float.
 func (kc *ConstantCompiler) ProcessFloat(k ir.Float) {
 	kc.addCompiled(code.Float(k))
@@ -65,10 +73,21 @@ func (kc *ConstantCompiler) ProcessCode(c ir.Code) {
 		ConstantCompiler: kc,
 		regAllocator:     regAllocator,
 	}
+	if kc.specialize {
+		ic.spec = newSpecializer(c)
+	}
 	for i, instr := range c.Instructions {
+		ic.index = i
 		ic.line = c.Lines[i]
+		if c.Columns != nil {
+			ic.column = c.Columns[i]
+		}
+		if c.Inlined != nil {
+			kc.builder.SetInlineFrame(c.Inlined[i])
+		}
 		instr.ProcessInstr(ic)
 	}
+	kc.builder.SetInlineFrame(0)
 	end := kc.builder.Offset()
 	kc.addCompiled(code.Code{
 		Name:         c.Name,
@@ -78,9 +97,26 @@ func (kc *ConstantCompiler) ProcessCode(c ir.Code) {
 		CellCount:    int16(len(regAllocator.cells)),
 		UpNames:      c.UpNames,
 		RegCount:     int16(len(regAllocator.regs)),
+		InlineFrames: compileInlineFrames(c.InlineFrames),
 	})
 }
 
+func compileInlineFrames(frames []ir.InlineFrame) []code.InlineFrame {
+	if frames == nil {
+		return nil
+	}
+	cframes := make([]code.InlineFrame, len(frames))
+	for i, f := range frames {
+		cframes[i] = code.InlineFrame{
+			Name:   f.Name,
+			Line:   int32(f.Line),
+			Column: int32(f.Column),
+			Parent: int16(f.Parent),
+		}
+	}
+	return cframes
+}
+
 func (kc *ConstantCompiler) compileConstant(ki uint) {
 	kc.constants[ki].ProcessConstant(kc)
 }
@@ -0,0 +1,157 @@
+package ircomp
+
+import (
+	"math"
+
+	"github.com/arnodel/golua/code"
+	"github.com/arnodel/golua/ir"
+	"github.com/arnodel/golua/ops"
+)
+
+// A specializer finds IR instructions that can be compiled to specialized
+// opcodes (see code.Type8Pfx).  E.g. the instructions
+//
+//	r1 := k0    (k0 = 1)
+//	r2 := r3 + r1
+//
+// can be compiled to the single opcode r2 <- r3 + 1, provided r1 is not used
+// anywhere else.
+type specializer struct {
+	instrs    []ir.Instruction
+	regs      []ir.RegData
+	defCounts []int                // Number of instructions setting each register
+	useCounts []int                // Number of instructions reading each register
+	constDefs map[ir.Register]uint // Constant loaded into registers (if they are set by a LoadConst)
+
+	// Instructions which take an operand from a LoadConst that was not
+	// emitted, with the encoding of that operand.
+	folded map[int]code.Index8
+}
+
+func newSpecializer(c ir.Code) *specializer {
+	s := &specializer{
+		instrs:    c.Instructions,
+		regs:      c.Registers,
+		defCounts: make([]int, len(c.Registers)),
+		useCounts: make([]int, len(c.Registers)),
+		constDefs: map[ir.Register]uint{},
+		folded:    map[int]code.Index8{},
+	}
+	for _, instr := range c.Instructions {
+		defs, uses := ir.RegisterUsage(instr)
+		switch x := instr.(type) {
+		case ir.LoadConst:
+			s.constDefs[x.Dst] = x.Kidx
+		case ir.PrepForLoop, ir.AdvForLoop:
+			// The step register is only converted to a float if the start
+			// value is a float, which does not change its numeric value.
+			defs = defs[:2]
+		}
+		for _, r := range defs {
+			s.defCounts[r]++
+		}
+		for _, r := range uses {
+			s.useCounts[r]++
+		}
+	}
+	return s
+}
+
+// foldConstant returns true if the LoadConst instruction at index i should not
+// be emitted because the constant it loads is encoded into a specialized opcode
+// for the next instruction.
+func (s *specializer) foldConstant(kc *ConstantCompiler, i int, l ir.LoadConst) bool {
+	r := l.Dst
+	if s.regs[r].IsCell || s.defCounts[r] != 1 || s.useCounts[r] != 1 {
+		return false
+	}
+	j := s.nextInstr(i)
+	if j < 0 {
+		return false
+	}
+	var n code.Index8
+	switch x := s.instrs[j].(type) {
+	case ir.Combine:
+		if x.Rsrc != r || (x.Op != ops.OpAdd && x.Op != ops.OpSub) {
+			return false
+		}
+		k, ok := toInt8(kc.GetConstant(l.Kidx))
+		if !ok {
+			return false
+		}
+		n = code.Index8FromInt8(k)
+	case ir.Lookup:
+		if x.Index != r {
+			return false
+		}
+		ki, ok := stringKIndex(kc, l.Kidx)
+		if !ok {
+			return false
+		}
+		n = ki
+	case ir.SetIndex:
+		if x.Index != r {
+			return false
+		}
+		ki, ok := stringKIndex(kc, l.Kidx)
+		if !ok {
+			return false
+		}
+		n = ki
+	default:
+		return false
+	}
+	s.folded[j] = n
+	return true
+}
+
+// foldedOperand returns the encoding of the operand that was folded into the
+// instruction at index i, if any.
+func (s *specializer) foldedOperand(i int) (code.Index8, bool) {
+	n, ok := s.folded[i]
+	return n, ok
+}
+
+// forLoopStep returns the value of the step of a numeric for loop if it is a
+// small integer constant.
+func (s *specializer) forLoopStep(kc *ConstantCompiler, step ir.Register) (int8, bool) {
+	kidx, ok := s.constDefs[step]
+	if !ok || s.defCounts[step] != 1 {
+		return 0, false
+	}
+	return toInt8(kc.GetConstant(kidx))
+}
+
+// nextInstr returns the index of the next instruction after i which is not a
+// register hint, or -1 if there is none.
+func (s *specializer) nextInstr(i int) int {
+	for i++; i < len(s.instrs); i++ {
+		switch s.instrs[i].(type) {
+		case ir.TakeRegister, ir.ReleaseRegister:
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+func toInt8(k ir.Constant) (int8, bool) {
+	n, ok := k.(ir.Int)
+	if !ok || n < math.MinInt8 || n > math.MaxInt8 {
+		return 0, false
+	}
+	return int8(n), true
+}
+
+// stringKIndex queues the constant with index ki, which must be a string for an
+// IndexK opcode.  It returns false if that is not possible.
+func stringKIndex(kc *ConstantCompiler, ki uint) (code.Index8, bool) {
+	if _, ok := kc.GetConstant(ki).(ir.String); !ok {
+		return 0, false
+	}
+	cki := kc.QueueConstant(ki)
+	if cki > math.MaxUint8 {
+		return 0, false
+	}
+	return code.Index8FromInt(cki), true
+}
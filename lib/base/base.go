@@ -24,8 +24,6 @@ func Load(r *rt.Runtime) (rt.Value, func()) {
 	rt.SolemnlyDeclareCompliance(
 		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
 
-		ipairsIterator,
-		nextGoFunc,
 		r.SetEnvGoFunc(env, "assert", assert, 1, true),
 		r.SetEnvGoFunc(env, "error", errorF, 2, false),
 		r.SetEnvGoFunc(env, "getmetatable", getmetatable, 1, false),
@@ -56,6 +54,17 @@ func Load(r *rt.Runtime) (rt.Value, func()) {
 	return rt.NilValue, nil
 }
 
+// Package-level functions are shared by all runtimes, so their compliance is
+// declared once rather than each time the library is loaded.
+func init() {
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+
+		ipairsIterator,
+		nextGoFunc,
+	)
+}
+
 func ToString(t *rt.Thread, v rt.Value) (string, error) {
 	next := rt.NewTerminationWith(t.CurrentCont(), 1, false)
 	err, ok := rt.Metacall(t, v, "__tostring", []rt.Value{v}, next)
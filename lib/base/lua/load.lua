@@ -66,4 +66,7 @@ load(string.dump(function() print("hi", z) end))()
 --> =hi	nil
 
 print(load("???", "", "t"))
---> ~nil\t.*invalid token
\ No newline at end of file
+--> ~nil\t.*invalid token
+-- Only the first syntax error is reported
+print(load("for i=1 do end end end"))
+--> =nil	chunk:1:9: expected ',' near 'do'
@@ -0,0 +1,256 @@
+// Package compatlib provides the functions of the standard library which were
+// removed after Lua 5.1, 5.2 or 5.3, when the runtime emulates one of these
+// versions (see runtime.LuaVersion).  It must be loaded after the other
+// libraries as some functions are aliases of functions in them.
+//
+// Lua 5.1 gets getfenv, setfenv and table.getn.  Lua 5.1 and 5.2 get unpack
+// (alias of table.unpack), loadstring (alias of load), module and
+// package.seeall.  Lua 5.1, 5.2 and 5.3 get math.pow.
+package compatlib
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/arnodel/golua/lib/packagelib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// LibLoader adds the compatibility functions to the global environment and
+// the other libraries.
+var LibLoader = packagelib.Loader{
+	Load: load,
+}
+
+func load(r *rt.Runtime) (rt.Value, func()) {
+	v := r.LuaVersion()
+	if v >= rt.Lua54 {
+		return rt.NilValue, nil
+	}
+	env := r.GlobalEnv()
+	r.SetEnv(env, "_VERSION", rt.StringValue("Golua "+v.String()))
+	if mathlib, ok := env.Get(rt.StringValue("math")).TryTable(); ok {
+		rt.SolemnlyDeclareCompliance(
+			rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+			r.SetEnvGoFunc(mathlib, "pow", pow, 2, false),
+		)
+	}
+	if v >= rt.Lua53 {
+		return rt.NilValue, nil
+	}
+	if tablelib, ok := env.Get(rt.StringValue("table")).TryTable(); ok {
+		r.SetEnv(env, "unpack", tablelib.Get(rt.StringValue("unpack")))
+	}
+	r.SetEnv(env, "loadstring", env.Get(rt.StringValue("load")))
+	if pkg, ok := env.Get(rt.StringValue("package")).TryTable(); ok {
+		rt.SolemnlyDeclareCompliance(
+			rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+			r.SetEnvGoFunc(env, "module", module, 1, true),
+			r.SetEnvGoFunc(pkg, "seeall", seeall, 1, false),
+		)
+	}
+	if v >= rt.Lua52 {
+		return rt.NilValue, nil
+	}
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+		r.SetEnvGoFunc(env, "getfenv", getfenv, 1, false),
+		r.SetEnvGoFunc(env, "setfenv", setfenv, 2, false),
+	)
+	if tablelib, ok := env.Get(rt.StringValue("table")).TryTable(); ok {
+		rt.SolemnlyDeclareCompliance(
+			rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+			r.SetEnvGoFunc(tablelib, "getn", getn, 1, false),
+		)
+	}
+	return rt.NilValue, nil
+}
+
+func pow(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.CheckNArgs(2); err != nil {
+		return nil, err
+	}
+	x, err := c.FloatArg(0)
+	if err != nil {
+		return nil, err
+	}
+	y, err := c.FloatArg(1)
+	if err != nil {
+		return nil, err
+	}
+	return c.PushingNext1(t.Runtime, rt.FloatValue(math.Pow(x, y))), nil
+}
+
+func getn(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	tbl, err := c.TableArg(0)
+	if err != nil {
+		return nil, err
+	}
+	return c.PushingNext1(t.Runtime, rt.IntValue(tbl.Len())), nil
+}
+
+// funcArg returns the function designated by argument n of c, which may be a
+// function or a stack level (1 being the function calling c's function).  Level
+// 0 is returned as a nil continuation and a nil value.
+func funcArg(c *rt.GoCont, n int) (rt.Cont, rt.Value, error) {
+	if c.NArgs() <= n || c.Arg(n).IsNil() {
+		return c.Parent(), rt.NilValue, nil
+	}
+	f := c.Arg(n)
+	if f.Type() == rt.FunctionType {
+		return nil, f, nil
+	}
+	level, ok := rt.ToInt(f)
+	if !ok || level < 0 {
+		return nil, rt.NilValue, errors.New("#1 must be a function or a non-negative level")
+	}
+	if level == 0 {
+		return nil, rt.NilValue, nil
+	}
+	var cont rt.Cont = c
+	for ; level > 0; level-- {
+		cont = cont.Parent()
+		if cont == nil {
+			return nil, rt.NilValue, errors.New("#1 level out of range")
+		}
+	}
+	return cont, rt.NilValue, nil
+}
+
+func getfenv(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	cont, f, err := funcArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	if luaCont, ok := cont.(*rt.LuaCont); ok {
+		f = rt.FunctionValue(luaCont.Closure)
+	}
+	env := rt.TableValue(t.GlobalEnv())
+	if clos, ok := f.TryClosure(); ok {
+		if cloEnv, ok := clos.Env(); ok {
+			env = cloEnv
+		}
+	}
+	return c.PushingNext1(t.Runtime, env), nil
+}
+
+func setfenv(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.CheckNArgs(2); err != nil {
+		return nil, err
+	}
+	env, err := c.TableArg(1)
+	if err != nil {
+		return nil, err
+	}
+	cont, f, err := funcArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := setEnv(cont, f, rt.TableValue(env)); err != nil {
+		return nil, err
+	}
+	if luaCont, ok := cont.(*rt.LuaCont); ok {
+		f = rt.FunctionValue(luaCont.Closure)
+	}
+	return c.PushingNext1(t.Runtime, f), nil
+}
+
+// setEnv sets the environment of the function running in cont if it is not
+// nil, otherwise of the function f.
+func setEnv(cont rt.Cont, f rt.Value, env rt.Value) error {
+	if cont != nil {
+		if luaCont, ok := cont.(*rt.LuaCont); ok {
+			luaCont.SetEnv(env)
+			return nil
+		}
+	} else if clos, ok := f.TryClosure(); ok {
+		clos.SetEnv(env)
+		return nil
+	}
+	return errors.New("cannot change the environment of given object")
+}
+
+// module implements the Lua 5.1 module function: module(name [, ...]).
+func module(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	name, err := c.StringArg(0)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := t.GlobalEnv().Get(rt.StringValue("package")).TryTable()
+	if !ok {
+		return nil, errors.New("package must be a table")
+	}
+	loaded, ok := pkg.Get(rt.StringValue("loaded")).TryTable()
+	if !ok {
+		return nil, errors.New("package.loaded must be a table")
+	}
+	nameVal := rt.StringValue(name)
+	mod, ok := loaded.Get(nameVal).TryTable()
+	if !ok {
+		mod, err = findTable(t, name)
+		if err != nil {
+			return nil, err
+		}
+		t.SetTable(loaded, nameVal, rt.TableValue(mod))
+	}
+	if mod.Get(rt.StringValue("_NAME")).IsNil() {
+		modVal := rt.TableValue(mod)
+		t.SetEnv(mod, "_M", modVal)
+		t.SetEnv(mod, "_NAME", nameVal)
+		t.SetEnv(mod, "_PACKAGE", rt.StringValue(name[:strings.LastIndexByte(name, '.')+1]))
+	}
+	if err := setEnv(c.Parent(), rt.NilValue, rt.TableValue(mod)); err != nil {
+		return nil, errors.New("'module' not called from a Lua function")
+	}
+	for _, opt := range c.Etc() {
+		if err := rt.Call(t, opt, []rt.Value{rt.TableValue(mod)}, rt.NewTerminationWith(c, 0, false)); err != nil {
+			return nil, err
+		}
+	}
+	return c.Next(), nil
+}
+
+// findTable returns the table with the given dotted name starting from the
+// global environment, creating the missing tables.
+func findTable(t *rt.Thread, name string) (*rt.Table, error) {
+	tbl := t.GlobalEnv()
+	for _, part := range strings.Split(name, ".") {
+		key := rt.StringValue(part)
+		v := tbl.Get(key)
+		if v.IsNil() {
+			next := rt.NewTable()
+			t.SetTable(tbl, key, rt.TableValue(next))
+			tbl = next
+			continue
+		}
+		next, ok := v.TryTable()
+		if !ok {
+			return nil, errors.New("name conflict for module '" + name + "'")
+		}
+		tbl = next
+	}
+	return tbl, nil
+}
+
+// seeall implements package.seeall(module), which makes the global variables
+// visible in the module.
+func seeall(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	mod, err := c.TableArg(0)
+	if err != nil {
+		return nil, err
+	}
+	meta := mod.Metatable()
+	if meta == nil {
+		meta = rt.NewTable()
+		mod.SetMetatable(meta)
+	}
+	t.SetEnv(meta, "__index", rt.TableValue(t.GlobalEnv()))
+	return c.Next(), nil
+}
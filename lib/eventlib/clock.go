@@ -0,0 +1,69 @@
+package eventlib
+
+import (
+	"sync"
+	"time"
+)
+
+// A Clock is used by the event loop to tell the time and to wait for timers to
+// be due.  It can be replaced with a FakeClock to make tests deterministic.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel which receives the current time after duration
+	// d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock that uses the system time.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// A FakeClock is a Clock where time only passes when the event loop waits for
+// it, so waiting never blocks.
+type FakeClock struct {
+	mux sync.Mutex
+	now time.Time
+}
+
+var _ Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a new FakeClock whose current time is now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now implements Clock.Now.
+func (c *FakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+// After implements Clock.After.  It advances the clock by d and returns a
+// channel which is ready to receive the new time.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(d)
+	return ch
+}
+
+// Advance moves the clock forward by d and returns the new time.
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+	return c.now
+}
@@ -0,0 +1,353 @@
+// Package eventlib implements the "event" Lua library, which provides an event
+// loop with timers and promises.
+//
+// Tasks are Lua functions running in their own coroutine, scheduled by the
+// event loop (event.run).  Within a task, event.await (or p:await()) suspends
+// the task until a promise is settled, allowing other tasks to run in the
+// meantime.  Timer callbacks also run as tasks.
+package eventlib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	"github.com/arnodel/golua/lib/packagelib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// LibLoader can load the event lib.  Its event loop uses the system clock.
+var LibLoader = NewLibLoader(SystemClock)
+
+// NewLibLoader returns a loader for the event lib whose event loop uses the
+// given clock.  Using a FakeClock makes scripts using timers run
+// deterministically and without waiting.
+func NewLibLoader(clock Clock) packagelib.Loader {
+	return packagelib.Loader{
+		Load: func(r *rt.Runtime) (rt.Value, func()) {
+			return load(r, clock)
+		},
+		Name: "event",
+	}
+}
+
+type eventKeyType struct{}
+
+var eventKey = rt.AsValue(eventKeyType{})
+
+type eventData struct {
+	loop        *loop
+	promiseMeta *rt.Table
+	timerMeta   *rt.Table
+}
+
+func getEventData(r *rt.Runtime) *eventData {
+	return r.Registry(eventKey).Interface().(*eventData)
+}
+
+func load(r *rt.Runtime, clock Clock) (rt.Value, func()) {
+	promiseMethods := rt.NewTable()
+	promiseMeta := rt.NewTable()
+	r.SetEnv(promiseMeta, "__name", rt.StringValue("promise"))
+	r.SetEnv(promiseMeta, "__index", rt.TableValue(promiseMethods))
+
+	timerMethods := rt.NewTable()
+	timerMeta := rt.NewTable()
+	r.SetEnv(timerMeta, "__name", rt.StringValue("timer"))
+	r.SetEnv(timerMeta, "__index", rt.TableValue(timerMethods))
+
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(promiseMethods, "resolve", promiseresolve, 1, true),
+		r.SetEnvGoFunc(promiseMethods, "reject", promisereject, 2, false),
+		r.SetEnvGoFunc(promiseMethods, "status", promisestatus, 1, false),
+		r.SetEnvGoFunc(promiseMethods, "await", await, 1, false),
+
+		r.SetEnvGoFunc(timerMethods, "cancel", timercancel, 1, false),
+	)
+
+	r.SetRegistry(eventKey, rt.AsValue(&eventData{
+		loop:        newLoop(clock),
+		promiseMeta: promiseMeta,
+		timerMeta:   timerMeta,
+	}))
+
+	pkg := rt.NewTable()
+
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(pkg, "all", all, 0, true),
+		r.SetEnvGoFunc(pkg, "await", await, 1, false),
+		r.SetEnvGoFunc(pkg, "now", now, 0, false),
+		r.SetEnvGoFunc(pkg, "promise", newpromise, 0, false),
+		r.SetEnvGoFunc(pkg, "setinterval", setinterval, 2, true),
+		r.SetEnvGoFunc(pkg, "settimeout", settimeout, 2, true),
+		r.SetEnvGoFunc(pkg, "sleep", sleep, 1, false),
+		r.SetEnvGoFunc(pkg, "spawn", spawn, 1, true),
+	)
+
+	// The loop may wait for timers so it is not timesafe.
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(pkg, "run", run, 1, true),
+	)
+
+	return rt.TableValue(pkg), nil
+}
+
+func newPromiseValue(t *rt.Thread, p *promise) rt.Value {
+	return t.NewUserDataValue(p, getEventData(t.Runtime).promiseMeta)
+}
+
+func newPromise(t *rt.Thread) *promise {
+	t.RequireSize(unsafe.Sizeof(promise{}))
+	return new(promise)
+}
+
+func promiseArg(c *rt.GoCont, n int) (*promise, error) {
+	u, ok := c.Arg(n).TryUserData()
+	if ok {
+		if p, ok := u.Value().(*promise); ok {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("#%d must be a promise", n+1)
+}
+
+func timerArg(c *rt.GoCont, n int) (*timer, error) {
+	u, ok := c.Arg(n).TryUserData()
+	if ok {
+		if tm, ok := u.Value().(*timer); ok {
+			return tm, nil
+		}
+	}
+	return nil, fmt.Errorf("#%d must be a timer", n+1)
+}
+
+// durationArg returns argument n as a duration, given in seconds.
+func durationArg(c *rt.GoCont, n int) (time.Duration, error) {
+	secs, err := c.FloatArg(n)
+	if err != nil {
+		return 0, err
+	}
+	if secs < 0 || math.IsNaN(secs) {
+		return 0, fmt.Errorf("#%d must be a non-negative number", n+1)
+	}
+	if secs > math.MaxInt64/float64(time.Second) {
+		return math.MaxInt64, nil
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+func run(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	l := getEventData(t.Runtime).loop
+	if c.NArgs() > 0 {
+		f, err := c.CallableArg(0)
+		if err != nil {
+			return nil, err
+		}
+		l.spawn(t.Runtime, f, c.Etc())
+	}
+	if err := l.run(t); err != nil {
+		return nil, err
+	}
+	return c.Next(), nil
+}
+
+func spawn(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	f, err := c.CallableArg(0)
+	if err != nil {
+		return nil, err
+	}
+	t.RequireSize(unsafe.Sizeof(task{}))
+	tk := getEventData(t.Runtime).loop.spawn(t.Runtime, f, c.Etc())
+	return c.PushingNext1(t.Runtime, newPromiseValue(t, tk.promise)), nil
+}
+
+func newpromise(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return c.PushingNext1(t.Runtime, newPromiseValue(t, newPromise(t))), nil
+}
+
+func promiseresolve(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	p, err := promiseArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	return c.PushingNext1(t.Runtime, rt.BoolValue(p.resolve(c.Etc()))), nil
+}
+
+func promisereject(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	p, err := promiseArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	errVal := rt.NilValue
+	if c.NArgs() >= 2 {
+		errVal = c.Arg(1)
+	}
+	return c.PushingNext1(t.Runtime, rt.BoolValue(p.reject(rt.NewError(errVal)))), nil
+}
+
+func promisestatus(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	p, err := promiseArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	return c.PushingNext1(t.Runtime, rt.StringValue(p.state.String())), nil
+}
+
+var errAwaitOutsideLoop = errors.New("cannot await outside of the event loop")
+
+func await(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	p, err := promiseArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	switch p.state {
+	case promiseResolved:
+		return c.PushingNext(t.Runtime, p.values...), nil
+	case promiseRejected:
+		return nil, p.err
+	}
+	l := getEventData(t.Runtime).loop
+	if !l.running || t.IsMain() {
+		return nil, errAwaitOutsideLoop
+	}
+	return l.await(c, p), nil
+}
+
+func sleep(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	d, err := durationArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	l := getEventData(t.Runtime).loop
+	if !l.running || t.IsMain() {
+		return nil, errAwaitOutsideLoop
+	}
+	p := newPromise(t)
+	t.RequireSize(unsafe.Sizeof(timer{}))
+	l.after(d, 0, func() { p.resolve(nil) })
+	return l.await(c, p), nil
+}
+
+func settimeout(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return setTimer(t, c, false)
+}
+
+func setinterval(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return setTimer(t, c, true)
+}
+
+// setTimer schedules a task running the callable in argument 0 after the delay
+// in seconds in argument 1 (and after every such delay if repeat is true).
+func setTimer(t *rt.Thread, c *rt.GoCont, repeat bool) (rt.Cont, error) {
+	if err := c.CheckNArgs(2); err != nil {
+		return nil, err
+	}
+	f, err := c.CallableArg(0)
+	if err != nil {
+		return nil, err
+	}
+	d, err := durationArg(c, 1)
+	if err != nil {
+		return nil, err
+	}
+	var interval time.Duration
+	if repeat {
+		if d == 0 {
+			return nil, errors.New("#2 must be positive")
+		}
+		interval = d
+	}
+	var (
+		r    = t.Runtime
+		args = c.Etc()
+		l    = getEventData(r).loop
+	)
+	t.RequireSize(unsafe.Sizeof(timer{}))
+	tm := l.after(d, interval, func() { l.spawn(r, f, args) })
+	return c.PushingNext1(r, t.NewUserDataValue(tm, getEventData(r).timerMeta)), nil
+}
+
+func timercancel(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	tm, err := timerArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	getEventData(t.Runtime).loop.cancel(tm)
+	return c.Next(), nil
+}
+
+func now(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return c.PushingNext1(t.Runtime, rt.FloatValue(getEventData(t.Runtime).loop.elapsed())), nil
+}
+
+// all returns a promise which is resolved when all the promises passed as
+// arguments are resolved, with a table containing the first value of each of
+// them.  It is rejected as soon as one of them is rejected.
+func all(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	args := c.Etc()
+	ps := make([]*promise, len(args))
+	for i, arg := range args {
+		u, ok := arg.TryUserData()
+		if ok {
+			ps[i], ok = u.Value().(*promise)
+		}
+		if !ok {
+			return nil, fmt.Errorf("#%d must be a promise", i+1)
+		}
+	}
+	var (
+		r         = t.Runtime
+		res       = newPromise(t)
+		vals      = rt.NewTable()
+		remaining = len(ps)
+	)
+	for i, p := range ps {
+		i, p := i, p
+		p.then(func() {
+			if p.state == promiseRejected {
+				res.reject(p.err)
+				return
+			}
+			if len(p.values) > 0 {
+				r.SetTable(vals, rt.IntValue(int64(i+1)), p.values[0])
+			}
+			remaining--
+			if remaining == 0 {
+				res.resolve([]rt.Value{rt.TableValue(vals)})
+			}
+		})
+	}
+	if remaining == 0 {
+		res.resolve([]rt.Value{rt.TableValue(vals)})
+	}
+	return c.PushingNext1(t.Runtime, newPromiseValue(t, res)), nil
+}
@@ -0,0 +1,313 @@
+package eventlib
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+	"time"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// A loop schedules tasks and timers for a Runtime.  Tasks are Lua functions
+// running in their own coroutine.  A task can be suspended waiting for a
+// Pending continuation (e.g. when awaiting a promise), and the loop resumes it
+// when that is done.
+//
+// All loop operations happen in the goroutine of the Runtime, except for
+// Pending values created by other Go functions, which may be completed in any
+// goroutine.
+type loop struct {
+	clock   Clock
+	start   time.Time
+	running bool
+
+	ready    []*task                  // Tasks which can be resumed
+	owned    map[*rt.Pending]struct{} // Pending values created by await
+	awaiting map[*rt.Pending]*task    // Tasks suspended waiting for a promise
+	external map[*rt.Pending]*task    // Tasks suspended waiting for other Pending values
+	timers   timerHeap                // Scheduled timers
+	timerSeq uint64                   // Used to order timers due at the same time
+
+	// External Pending values which are done are added to doneQueue from other
+	// goroutines, which then signal notify.  Those goroutines never block, so
+	// they do not leak if the loop stops running.
+	doneMux   sync.Mutex
+	doneQueue []*rt.Pending
+	notify    chan struct{} // Has a pending item when doneQueue may be non-empty
+}
+
+func newLoop(clock Clock) *loop {
+	return &loop{
+		clock:    clock,
+		start:    clock.Now(),
+		owned:    map[*rt.Pending]struct{}{},
+		awaiting: map[*rt.Pending]*task{},
+		external: map[*rt.Pending]*task{},
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// A task is a Lua function running in its own coroutine.  Its promise is
+// settled with the outcome of the function.
+type task struct {
+	co      *rt.Thread
+	args    []rt.Value // Arguments to resume the coroutine with
+	promise *promise
+}
+
+// spawn creates a new task for f, ready to run.
+func (l *loop) spawn(r *rt.Runtime, f rt.Callable, args []rt.Value) *task {
+	co := rt.NewThread(r)
+	co.Start(f)
+	tk := &task{co: co, args: args, promise: new(promise)}
+	l.ready = append(l.ready, tk)
+	return tk
+}
+
+// wake makes the task waiting for pend ready to run again.
+func (l *loop) wake(pend *rt.Pending) {
+	if tk, ok := l.awaiting[pend]; ok {
+		delete(l.awaiting, pend)
+		l.ready = append(l.ready, tk)
+	}
+}
+
+// await returns a Pending continuation which will receive the result of p,
+// and arranges for the task suspended on it to be resumed when p is settled.
+func (l *loop) await(c *rt.GoCont, p *promise) *rt.Pending {
+	pend := rt.NewPending(c)
+	l.owned[pend] = struct{}{}
+	p.then(func() {
+		p.settle(pend)
+		delete(l.owned, pend)
+		l.wake(pend)
+	})
+	return pend
+}
+
+var errLoopRunning = errors.New("event loop already running")
+
+// run runs tasks and timers in the thread t until there are none left.  It
+// returns early if the runtime context of t is due.
+func (l *loop) run(t *rt.Thread) error {
+	if l.running {
+		return errLoopRunning
+	}
+	l.running = true
+	defer func() { l.running = false }()
+	limit := newTimeLimit(t)
+	for !t.Due() {
+		t.RequireCPU(1)
+		if len(l.ready) > 0 {
+			tk := l.ready[0]
+			l.ready[0] = nil
+			l.ready = l.ready[1:]
+			l.resume(t, tk)
+			continue
+		}
+		if !l.wait(t, limit) {
+			break
+		}
+	}
+	return nil
+}
+
+// resume runs the task tk in t until it finishes or is suspended.
+func (l *loop) resume(t *rt.Thread, tk *task) {
+	args := tk.args
+	tk.args = nil
+	res, pend, err := tk.co.ResumeAsync(t, args)
+	switch {
+	case err != nil:
+		tk.promise.reject(err)
+	case pend == nil:
+		tk.promise.resolve(res)
+	default:
+		select {
+		case <-pend.Done():
+			l.ready = append(l.ready, tk)
+			return
+		default:
+		}
+		if _, ok := l.owned[pend]; ok {
+			l.awaiting[pend] = tk
+			return
+		}
+		// The Pending value was created by some other Go function, it may
+		// be completed in another goroutine.
+		l.external[pend] = tk
+		go func() {
+			<-pend.Done()
+			l.doneMux.Lock()
+			l.doneQueue = append(l.doneQueue, pend)
+			l.doneMux.Unlock()
+			select {
+			case l.notify <- struct{}{}:
+			default:
+			}
+		}()
+	}
+}
+
+// A timeLimit is the time at which the runtime context of a thread reaches its
+// time limit (soft or hard, whichever comes first).  The context time is real
+// time, whatever the clock of the loop.
+type timeLimit struct {
+	when time.Time // Zero if there is no time limit
+	hard bool      // True if the limit is the hard limit
+}
+
+func newTimeLimit(t *rt.Thread) timeLimit {
+	var l timeLimit
+	now, used := time.Now(), t.UsedResources().Millis
+	// The hard limit comes first so that it is chosen if the soft limit is the
+	// same.
+	for _, limit := range []struct {
+		millis uint64
+		hard   bool
+	}{{t.HardLimits().Millis, true}, {t.SoftLimits().Millis, false}} {
+		if limit.millis == 0 {
+			continue
+		}
+		var left time.Duration
+		if used < limit.millis {
+			left = time.Duration(limit.millis-used) * time.Millisecond
+		}
+		if when := now.Add(left); l.when.IsZero() || when.Before(l.when) {
+			l.when = when
+			l.hard = limit.hard
+		}
+	}
+	return l
+}
+
+// wait blocks until a task is ready to run or a timer has been triggered.  It
+// returns false if there is nothing left to wait for or if the soft time limit
+// of the runtime context of t is reached.  It terminates the context if its hard
+// time limit is reached.
+func (l *loop) wait(t *rt.Thread, limit timeLimit) bool {
+	var due <-chan time.Time
+	if len(l.timers) > 0 {
+		due = l.clock.After(l.timers[0].when.Sub(l.clock.Now()))
+	} else if len(l.external) == 0 {
+		return false
+	}
+	var expired <-chan time.Time
+	if !limit.when.IsZero() {
+		tm := time.NewTimer(time.Until(limit.when))
+		defer tm.Stop()
+		expired = tm.C
+	}
+	select {
+	case <-l.notify:
+		l.doneMux.Lock()
+		queue := l.doneQueue
+		l.doneQueue = nil
+		l.doneMux.Unlock()
+		for _, pend := range queue {
+			l.ready = append(l.ready, l.external[pend])
+			delete(l.external, pend)
+		}
+	case now := <-due:
+		l.fireTimers(now)
+	case <-expired:
+		if limit.hard {
+			t.TerminateContext("time limit of %d exceeded", t.HardLimits().Millis)
+		}
+		return false
+	}
+	return true
+}
+
+// fireTimers triggers all the timers which are due at the given time.
+func (l *loop) fireTimers(now time.Time) {
+	for len(l.timers) > 0 && !l.timers[0].when.After(now) {
+		tm := heap.Pop(&l.timers).(*timer)
+		tm.fire()
+		if tm.interval > 0 && !tm.cancelled {
+			tm.when = tm.when.Add(tm.interval)
+			l.schedule(tm)
+		}
+	}
+}
+
+// elapsed returns the time in seconds since the loop was created.
+func (l *loop) elapsed() float64 {
+	return l.clock.Now().Sub(l.start).Seconds()
+}
+
+//
+// Timers
+//
+
+type timer struct {
+	when      time.Time
+	interval  time.Duration // If > 0, the timer repeats
+	fire      func()
+	seq       uint64
+	index     int // Index in the timer heap, or -1
+	cancelled bool
+}
+
+func (l *loop) schedule(tm *timer) {
+	l.timerSeq++
+	tm.seq = l.timerSeq
+	heap.Push(&l.timers, tm)
+}
+
+// after schedules f to be called after duration d, then every interval if it is
+// positive.
+func (l *loop) after(d, interval time.Duration, f func()) *timer {
+	tm := &timer{
+		when:     l.clock.Now().Add(d),
+		interval: interval,
+		fire:     f,
+	}
+	l.schedule(tm)
+	return tm
+}
+
+func (l *loop) cancel(tm *timer) {
+	tm.cancelled = true
+	if tm.index >= 0 {
+		heap.Remove(&l.timers, tm.index)
+	}
+}
+
+// timerHeap implements heap.Interface, ordering timers by due time then by
+// scheduling order.
+type timerHeap []*timer
+
+func (h timerHeap) Len() int {
+	return len(h)
+}
+
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].when.Equal(h[j].when) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].when.Before(h[j].when)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	tm := x.(*timer)
+	tm.index = len(*h)
+	*h = append(*h, tm)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	tm := old[n-1]
+	old[n-1] = nil
+	tm.index = -1
+	*h = old[:n-1]
+	return tm
+}
@@ -0,0 +1,92 @@
+package eventlib
+
+import (
+	"testing"
+	"time"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Check that a goroutine waiting for an external Pending value does not block
+// forever when the loop is not running to receive it.
+func TestExternalPendingWithLoopStopped(t *testing.T) {
+	r := rt.New(nil)
+	defer r.Close(nil)
+	var pend *rt.Pending
+	f := rt.NewGoFunction(func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		pend = rt.NewPending(c)
+		return pend, nil
+	}, "f", 0, false)
+	l := newLoop(NewFakeClock(time.Now()))
+	tk := l.spawn(r, f, nil)
+	l.ready = nil
+	l.resume(r.MainThread(), tk)
+	if l.external[pend] != tk {
+		t.Fatal("expected the task to wait for an external Pending value")
+	}
+
+	pend.Complete()
+	deadline := time.After(5 * time.Second)
+	for {
+		l.doneMux.Lock()
+		n := len(l.doneQueue)
+		l.doneMux.Unlock()
+		if n == 1 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("the done Pending value was not queued")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	// When the loop runs again, the task is resumed.
+	if err := l.run(r.MainThread()); err != nil {
+		t.Fatal(err)
+	}
+	if tk.promise.state != promiseResolved {
+		t.Errorf("wrong task state %s", tk.promise.state)
+	}
+}
+
+// Check that the loop does not wait for longer than the time limits of the
+// runtime context allow.
+func TestWaitTimeLimits(t *testing.T) {
+	if !rt.QuotasAvailable {
+		t.Skip("quotas are not available")
+	}
+	r := rt.New(nil)
+	defer r.Close(nil)
+	l := newLoop(SystemClock)
+	l.after(time.Hour, 0, func() {})
+	th := r.MainThread()
+
+	// The loop stops when the soft limit is reached.
+	start := time.Now()
+	_, err := th.CallContext(rt.RuntimeContextDef{
+		SoftLimits: rt.RuntimeResources{Millis: 50},
+	}, func() error {
+		return l.run(th)
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the loop waited for %s", elapsed)
+	}
+
+	// The context is terminated when the hard limit is reached.
+	start = time.Now()
+	_, err = th.CallContext(rt.RuntimeContextDef{
+		HardLimits: rt.RuntimeResources{Millis: 50},
+	}, func() error {
+		return l.run(th)
+	})
+	if _, ok := err.(rt.ContextTerminationError); !ok {
+		t.Errorf("expected a context termination error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the loop waited for %s", elapsed)
+	}
+}
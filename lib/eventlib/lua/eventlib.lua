@@ -0,0 +1,146 @@
+-- Timers run in order of due time
+do
+    local function log(...) print(event.now(), ...) end
+    event.settimeout(log, 2, "two")
+    event.settimeout(log, 1, "one")
+    event.settimeout(log, 1, "one again")
+    event.settimeout(log, 0, "zero")
+    event.run()
+    --> =0	zero
+    --> =1	one
+    --> =1	one again
+    --> =2	two
+end
+
+-- Intervals repeat until cancelled
+do
+    local start = event.now()
+    local n = 0
+    local tm
+    tm = event.setinterval(function()
+        n = n + 1
+        print(n, event.now() - start)
+        if n == 3 then tm:cancel() end
+    end, 0.5)
+    event.run()
+    --> =1	0.5
+    --> =2	1
+    --> =3	1.5
+    print(pcall(event.setinterval, print, 0))
+    --> ~false	.*must be positive
+end
+
+-- Cancelled timers do not run
+do
+    local tm = event.settimeout(print, 1, "not printed")
+    tm:cancel()
+    event.run()
+    print("nothing")
+    --> =nothing
+end
+
+-- Tasks can sleep and await promises
+do
+    local start = event.now()
+    local p = event.promise()
+    print(p:status())
+    --> =pending
+
+    local t1 = event.spawn(function(name)
+        print(name, "waiting")
+        local x, y = p:await()
+        print(name, "got", x, y, event.now() - start)
+        return x + y
+    end, "t1")
+
+    event.spawn(function()
+        event.sleep(2)
+        print("resolving", event.now() - start)
+        print(p:resolve(1, 2))
+        print(p:resolve(3, 4))
+    end)
+
+    event.run(function()
+        print("total", event.await(t1))
+    end)
+    --> =t1	waiting
+    --> =resolving	2
+    --> =true
+    --> =false
+    --> =t1	got	1	2	2
+    --> =total	3
+
+    print(p:status(), t1:status())
+    --> =resolved	resolved
+
+    -- Awaiting a settled promise returns straight away, even outside the loop
+    print(p:await())
+    --> =1	2
+end
+
+-- Rejected promises raise an error when awaited
+do
+    local p = event.promise()
+    local task = event.spawn(function() return pcall(event.await, p) end)
+    event.settimeout(function() p:reject("oops") end, 1)
+    event.run()
+    print(task:await())
+    --> =false	oops
+    print(p:status())
+    --> =rejected
+
+    local failing = event.spawn(function() error({code=1}) end)
+    event.run()
+    print(failing:status(), pcall(failing.await, failing))
+    --> ~rejected	false	table: .*
+end
+
+-- Awaiting from nested coroutines
+do
+    event.run(function()
+        local gen = coroutine.wrap(function()
+            for i = 1, 3 do
+                event.sleep(1)
+                coroutine.yield(i)
+            end
+        end)
+        print(gen(), gen(), gen(), event.now())
+    end)
+    --> ~1	2	3	.*
+end
+
+-- event.all waits for all promises
+do
+    event.run(function()
+        local p1 = event.spawn(function() event.sleep(2) return "a" end)
+        local p2 = event.spawn(function() event.sleep(1) return "b" end)
+        local r = event.all(p1, p2):await()
+        print(r[1], r[2])
+    end)
+    --> =a	b
+
+    local p = event.all()
+    print(p:status(), #p:await())
+    --> =resolved	0
+end
+
+-- Errors
+do
+    local p = event.promise()
+    print(pcall(event.await, p))
+    --> ~false	.*cannot await outside of the event loop
+
+    print(pcall(event.sleep, 1))
+    --> ~false	.*cannot await outside of the event loop
+
+    print(pcall(event.settimeout, print, -1))
+    --> ~false	.*must be a non-negative number
+
+    print(pcall(event.await, {}))
+    --> ~false	.*must be a promise
+
+    event.run(function()
+        print(pcall(event.run))
+    end)
+    --> ~false	.*event loop already running
+end
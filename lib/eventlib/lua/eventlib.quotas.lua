@@ -0,0 +1,21 @@
+-- The loop stops when the soft limits of the context are reached
+do
+    local n = 0
+    local tm = event.setinterval(function() n = n + 1 end, 1)
+    print(runtime.callcontext({stop={cpu=1000}}, event.run))
+    --> =done
+    print(n > 0)
+    --> =true
+    tm:cancel()
+    event.run()
+end
+
+-- The loop is not timesafe as it waits for timers
+do
+    print(runtime.callcontext({flags="timesafe"}, event.run))
+    --> ~error	.*missing flags: timesafe
+
+    local ctx, p = runtime.callcontext({flags="timesafe"}, event.promise)
+    print(ctx, p:status())
+    --> =done	pending
+end
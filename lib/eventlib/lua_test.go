@@ -0,0 +1,23 @@
+package eventlib_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arnodel/golua/lib"
+	"github.com/arnodel/golua/lib/eventlib"
+	"github.com/arnodel/golua/luatesting"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+func TestEventLib(t *testing.T) {
+	setup := func(r *rt.Runtime) func() {
+		cleanup := lib.LoadAll(r)
+		// Replace the event lib with one using a fake clock so that tests
+		// are deterministic and do not wait.
+		clock := eventlib.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+		eventlib.NewLibLoader(clock).Run(r)
+		return cleanup
+	}
+	luatesting.RunLuaTestsInDir(t, "lua", setup)
+}
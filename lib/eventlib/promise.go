@@ -0,0 +1,80 @@
+package eventlib
+
+import (
+	rt "github.com/arnodel/golua/runtime"
+)
+
+type promiseState uint8
+
+const (
+	promisePending promiseState = iota
+	promiseResolved
+	promiseRejected
+)
+
+func (s promiseState) String() string {
+	switch s {
+	case promiseResolved:
+		return "resolved"
+	case promiseRejected:
+		return "rejected"
+	default:
+		return "pending"
+	}
+}
+
+// A promise holds the result of an operation which may not be complete yet.
+// Once settled (i.e. resolved or rejected), its state does not change any more.
+type promise struct {
+	state     promiseState
+	values    []rt.Value // Set when resolved
+	err       error      // Set when rejected
+	onSettled []func()   // Called in order when the promise is settled
+}
+
+func (p *promise) resolve(vals []rt.Value) bool {
+	if p.state != promisePending {
+		return false
+	}
+	p.state = promiseResolved
+	p.values = vals
+	p.settled()
+	return true
+}
+
+func (p *promise) reject(err error) bool {
+	if p.state != promisePending {
+		return false
+	}
+	p.state = promiseRejected
+	p.err = err
+	p.settled()
+	return true
+}
+
+func (p *promise) settled() {
+	callbacks := p.onSettled
+	p.onSettled = nil
+	for _, f := range callbacks {
+		f()
+	}
+}
+
+// then arranges for f to be called when p is settled (straight away if it
+// already is).
+func (p *promise) then(f func()) {
+	if p.state == promisePending {
+		p.onSettled = append(p.onSettled, f)
+	} else {
+		f()
+	}
+}
+
+// settle completes the Pending continuation pend with the result of p.
+func (p *promise) settle(pend *rt.Pending) {
+	if p.state == promiseRejected {
+		pend.Fail(p.err)
+	} else {
+		pend.Complete(p.values...)
+	}
+}
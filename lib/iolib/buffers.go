@@ -14,6 +14,8 @@ type bufReader interface {
 	Discard(int) (int, error)
 	Peek(n int) ([]byte, error)
 	ReadString(delim byte) (string, error)
+	ReadByte() (byte, error)
+	UnreadByte() error
 }
 
 type bufWriter interface {
@@ -58,6 +60,16 @@ func (u *nobufReader) ReadString(delim byte) (string, error) {
 	return "", errors.New("unimplemented")
 }
 
+func (u *nobufReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(u.Reader, b[:])
+	return b[0], err
+}
+
+func (u *nobufReader) UnreadByte() error {
+	return errors.New("nobufReader cannot unread")
+}
+
 type nobufWriter struct {
 	io.Writer
 }
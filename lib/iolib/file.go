@@ -266,6 +266,18 @@ func (f *File) ReadNumber() (rt.Value, error) {
 	}
 }
 
+// ReadByte reads a single byte from the file.  Together with UnreadByte it
+// makes File implement io.ByteScanner, so that Go libraries can consume the
+// file's input without reading past what they need.
+func (f *File) ReadByte() (byte, error) {
+	return f.reader.ReadByte()
+}
+
+// UnreadByte unreads the last byte read from the file.
+func (f *File) UnreadByte() error {
+	return f.reader.UnreadByte()
+}
+
 // WriteString writes a string to the file.
 func (f *File) WriteString(s string) error {
 	_, err := f.writer.Write([]byte(s))
@@ -0,0 +1,468 @@
+package jsonlib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// A decoder reads JSON values from a byte stream.  It never reads past the end
+// of the value it decodes (except for one byte, which it unreads), so that
+// successive values can be decoded from the same stream.
+type decoder struct {
+	t      *rt.Thread
+	r      io.ByteScanner
+	null   rt.Value  // The value JSON null decodes to
+	array  *rt.Table // Metatable of decoded arrays
+	object *rt.Table // Metatable of decoded objects
+	offset int       // Number of bytes consumed so far
+	depth  int
+	buf    []byte // Scratch buffer for strings and numbers
+}
+
+// A syntaxError reports invalid JSON input.
+type syntaxError struct {
+	msg    string
+	offset int
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("invalid JSON at offset %d: %s", e.offset, e.msg)
+}
+
+func (d *decoder) syntaxError(format string, args ...interface{}) error {
+	return &syntaxError{msg: fmt.Sprintf(format, args...), offset: d.offset}
+}
+
+// decodeAll decodes a single value which must make up the whole input.
+func (d *decoder) decodeAll() (rt.Value, error) {
+	v, err := d.decodeValue()
+	if err != nil {
+		return rt.NilValue, err
+	}
+	b, err := d.skipSpace()
+	switch err {
+	case nil:
+		return rt.NilValue, d.syntaxError("unexpected %q after value", b)
+	case io.EOF:
+		return v, nil
+	default:
+		return rt.NilValue, err
+	}
+}
+
+// decodeNext decodes the next value in the input.  It returns nil if there is
+// no value before the end of the input.
+func (d *decoder) decodeNext() (rt.Value, error) {
+	_, err := d.skipSpace()
+	if err == io.EOF {
+		return rt.NilValue, nil
+	}
+	if err != nil {
+		return rt.NilValue, err
+	}
+	d.unreadByte()
+	return d.decodeValue()
+}
+
+func (d *decoder) readByte() (byte, error) {
+	b, err := d.r.ReadByte()
+	if err == nil {
+		d.offset++
+	}
+	return b, err
+}
+
+func (d *decoder) unreadByte() {
+	if d.r.UnreadByte() == nil {
+		d.offset--
+	}
+}
+
+// nextByte reads a byte, treating the end of input as a syntax error.
+func (d *decoder) nextByte() (byte, error) {
+	b, err := d.readByte()
+	if err == io.EOF {
+		return 0, d.syntaxError("unexpected end of input")
+	}
+	return b, err
+}
+
+// skipSpace reads the next byte which is not whitespace.
+func (d *decoder) skipSpace() (byte, error) {
+	for {
+		b, err := d.readByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			d.t.RequireCPU(1)
+		default:
+			return b, nil
+		}
+	}
+}
+
+func (d *decoder) nextNonSpace() (byte, error) {
+	b, err := d.skipSpace()
+	if err == io.EOF {
+		return 0, d.syntaxError("unexpected end of input")
+	}
+	return b, err
+}
+
+func (d *decoder) decodeValue() (rt.Value, error) {
+	d.t.RequireCPU(1)
+	b, err := d.nextNonSpace()
+	if err != nil {
+		return rt.NilValue, err
+	}
+	switch b {
+	case '{':
+		return d.decodeObject()
+	case '[':
+		return d.decodeArray()
+	case '"':
+		s, err := d.decodeString()
+		if err != nil {
+			return rt.NilValue, err
+		}
+		return rt.StringValue(s), nil
+	case 't':
+		return rt.BoolValue(true), d.expect("rue")
+	case 'f':
+		return rt.BoolValue(false), d.expect("alse")
+	case 'n':
+		return d.null, d.expect("ull")
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		d.unreadByte()
+		return d.decodeNumber()
+	default:
+		return rt.NilValue, d.syntaxError("unexpected %q", b)
+	}
+}
+
+// expect reads the rest of a literal.
+func (d *decoder) expect(rest string) error {
+	for i := 0; i < len(rest); i++ {
+		b, err := d.nextByte()
+		if err != nil {
+			return err
+		}
+		if b != rest[i] {
+			return d.syntaxError("unexpected %q", b)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) enter() error {
+	if d.depth >= maxDepth {
+		return d.syntaxError("%s", errMaxDepth)
+	}
+	d.depth++
+	return nil
+}
+
+func (d *decoder) decodeArray() (rt.Value, error) {
+	if err := d.enter(); err != nil {
+		return rt.NilValue, err
+	}
+	defer func() { d.depth-- }()
+	tbl := rt.NewTable()
+	tbl.SetMetatable(d.array)
+	b, err := d.nextNonSpace()
+	if err != nil {
+		return rt.NilValue, err
+	}
+	if b == ']' {
+		return rt.TableValue(tbl), nil
+	}
+	d.unreadByte()
+	for i := int64(1); ; i++ {
+		v, err := d.decodeValue()
+		if err != nil {
+			return rt.NilValue, err
+		}
+		d.t.SetTable(tbl, rt.IntValue(i), v)
+		b, err := d.nextNonSpace()
+		if err != nil {
+			return rt.NilValue, err
+		}
+		switch b {
+		case ',':
+		case ']':
+			return rt.TableValue(tbl), nil
+		default:
+			return rt.NilValue, d.syntaxError("unexpected %q in array", b)
+		}
+	}
+}
+
+func (d *decoder) decodeObject() (rt.Value, error) {
+	if err := d.enter(); err != nil {
+		return rt.NilValue, err
+	}
+	defer func() { d.depth-- }()
+	tbl := rt.NewTable()
+	tbl.SetMetatable(d.object)
+	b, err := d.nextNonSpace()
+	if err != nil {
+		return rt.NilValue, err
+	}
+	if b == '}' {
+		return rt.TableValue(tbl), nil
+	}
+	for {
+		if b != '"' {
+			return rt.NilValue, d.syntaxError("unexpected %q, expected object key", b)
+		}
+		k, err := d.decodeString()
+		if err != nil {
+			return rt.NilValue, err
+		}
+		b, err = d.nextNonSpace()
+		if err != nil {
+			return rt.NilValue, err
+		}
+		if b != ':' {
+			return rt.NilValue, d.syntaxError("unexpected %q after object key", b)
+		}
+		v, err := d.decodeValue()
+		if err != nil {
+			return rt.NilValue, err
+		}
+		d.t.SetTable(tbl, rt.StringValue(k), v)
+		b, err = d.nextNonSpace()
+		if err != nil {
+			return rt.NilValue, err
+		}
+		switch b {
+		case ',':
+		case '}':
+			return rt.TableValue(tbl), nil
+		default:
+			return rt.NilValue, d.syntaxError("unexpected %q in object", b)
+		}
+		b, err = d.nextNonSpace()
+		if err != nil {
+			return rt.NilValue, err
+		}
+	}
+}
+
+// Memory for strings is required in chunks of this size as they are read, so
+// that a huge string is not read in full before running out of memory.
+const stringChunkSize = 1024
+
+// decodeString decodes a string whose opening quote has already been read.
+func (d *decoder) decodeString() (string, error) {
+	buf := d.buf[:0]
+	required := 0
+	require := func() {
+		n := len(buf) - required
+		d.t.RequireCPU(uint64(n))
+		d.t.RequireBytes(n)
+		required += n
+	}
+	for {
+		if len(buf)-required >= stringChunkSize {
+			require()
+		}
+		b, err := d.nextByte()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case b == '"':
+			require()
+			d.buf = buf
+			return string(buf), nil
+		case b < 0x20:
+			return "", d.syntaxError("unexpected control character %q in string", b)
+		case b != '\\':
+			buf = append(buf, b)
+			continue
+		}
+		b, err = d.nextByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case '"', '\\', '/':
+			buf = append(buf, b)
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r, err := d.decodeRune()
+			if err != nil {
+				return "", err
+			}
+			buf = appendRune(buf, r)
+		default:
+			return "", d.syntaxError("invalid escape sequence \\%c", b)
+		}
+	}
+}
+
+// decodeRune decodes the escape sequence for a rune after "\u", which may be a
+// UTF-16 surrogate pair.
+func (d *decoder) decodeRune() (rune, error) {
+	r, err := d.decodeHex4()
+	if err != nil || !utf16.IsSurrogate(r) {
+		return r, err
+	}
+	// Try to read the second half of the surrogate pair.
+	b, err := d.nextByte()
+	if err != nil {
+		return 0, err
+	}
+	if b != '\\' {
+		d.unreadByte()
+		return utf8.RuneError, nil
+	}
+	if b, err = d.nextByte(); err != nil {
+		return 0, err
+	}
+	if b != 'u' {
+		return 0, d.syntaxError("invalid surrogate pair")
+	}
+	r2, err := d.decodeHex4()
+	if err != nil {
+		return r, err
+	}
+	return utf16.DecodeRune(r, r2), nil
+}
+
+func (d *decoder) decodeHex4() (rune, error) {
+	var r rune
+	for i := 0; i < 4; i++ {
+		b, err := d.nextByte()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case '0' <= b && b <= '9':
+			b -= '0'
+		case 'a' <= b && b <= 'f':
+			b -= 'a' - 10
+		case 'A' <= b && b <= 'F':
+			b -= 'A' - 10
+		default:
+			return 0, d.syntaxError("invalid hexadecimal digit %q", b)
+		}
+		r = r<<4 | rune(b)
+	}
+	return r, nil
+}
+
+func appendRune(buf []byte, r rune) []byte {
+	var tmp [utf8.UTFMax]byte
+	n := utf8.EncodeRune(tmp[:], r)
+	return append(buf, tmp[:n]...)
+}
+
+var errInvalidNumber = errors.New("invalid number")
+
+// decodeNumber decodes a number, returning an integer value if it has no
+// fractional part or exponent and fits in an int64, and a float otherwise.
+func (d *decoder) decodeNumber() (rt.Value, error) {
+	buf := d.buf[:0]
+	isFloat := false
+	digits := func() error {
+		n := 0
+		for {
+			b, err := d.readByte()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if b < '0' || b > '9' {
+				d.unreadByte()
+				break
+			}
+			buf = append(buf, b)
+			n++
+		}
+		if n == 0 {
+			return d.syntaxError("%s", errInvalidNumber)
+		}
+		return nil
+	}
+	// optional reads b if it is the next byte, returning true if it was.
+	optional := func(bs string) (bool, error) {
+		b, err := d.readByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		for i := 0; i < len(bs); i++ {
+			if b == bs[i] {
+				buf = append(buf, b)
+				return true, nil
+			}
+		}
+		d.unreadByte()
+		return false, nil
+	}
+	if _, err := optional("-"); err != nil {
+		return rt.NilValue, err
+	}
+	start := len(buf)
+	if err := digits(); err != nil {
+		return rt.NilValue, err
+	}
+	if buf[start] == '0' && len(buf) > start+1 {
+		return rt.NilValue, d.syntaxError("%s", errInvalidNumber)
+	}
+	if ok, err := optional("."); err != nil {
+		return rt.NilValue, err
+	} else if ok {
+		isFloat = true
+		if err := digits(); err != nil {
+			return rt.NilValue, err
+		}
+	}
+	if ok, err := optional("eE"); err != nil {
+		return rt.NilValue, err
+	} else if ok {
+		isFloat = true
+		if _, err := optional("+-"); err != nil {
+			return rt.NilValue, err
+		}
+		if err := digits(); err != nil {
+			return rt.NilValue, err
+		}
+	}
+	d.buf = buf
+	d.t.RequireCPU(uint64(len(buf)))
+	s := string(buf)
+	if !isFloat {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			return rt.IntValue(n), nil
+		}
+	}
+	// The syntax has been checked already so the only possible error is a range
+	// error, in which case x is the nearest float (e.g. infinity).
+	x, _ := strconv.ParseFloat(s, 64)
+	return rt.FloatValue(x), nil
+}
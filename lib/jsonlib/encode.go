@@ -0,0 +1,427 @@
+package jsonlib
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// The metafield which forces a table to be encoded as an "array" or an
+// "object".
+const jsonTypeField = "__jsontype"
+
+// Tables nested deeper than this cannot be encoded or decoded.
+const maxDepth = 1000
+
+var (
+	errCycle         = errors.New("cannot encode table containing a cycle")
+	errMaxDepth      = errors.New("maximum nesting depth exceeded")
+	errSparseArray   = errors.New("cannot encode sparse array")
+	errNaNOrInf      = errors.New("cannot encode NaN or infinity")
+	errArrayKeys     = errors.New("table encoded as an array has non positive integer keys")
+	errInvalidKey    = errors.New("table key must be a string or a number")
+	errInvalidIndent = errors.New("indent must be a string or a non-negative integer")
+)
+
+// How to encode tables whose keys are positive integers with gaps.
+type sparseMode uint8
+
+const (
+	sparseError  sparseMode = iota // Raise an error
+	sparseNull                     // Encode as an array, filling gaps with null
+	sparseObject                   // Encode as an object
+)
+
+// How to encode NaN and infinite floats, which JSON cannot represent.
+type nanMode uint8
+
+const (
+	nanError  nanMode = iota // Raise an error
+	nanNull                  // Encode as null
+	nanString                // Encode as "NaN", "Infinity" or "-Infinity"
+)
+
+// How to encode tables which contain themselves.
+type cycleMode uint8
+
+const (
+	cycleError cycleMode = iota // Raise an error
+	cycleNull                   // Encode the repeated table as null
+)
+
+type encoder struct {
+	t   *rt.Thread
+	buf strings.Builder
+
+	indent      string // If not empty, output is pretty-printed
+	sortKeys    bool
+	sparse      sparseMode
+	nan         nanMode
+	cycles      cycleMode
+	emptyArrays bool // Encode empty tables as arrays
+
+	visiting map[*rt.Table]struct{} // Tables being encoded
+	depth    int
+}
+
+func newEncoder(t *rt.Thread) *encoder {
+	return &encoder{
+		t:        t,
+		visiting: map[*rt.Table]struct{}{},
+	}
+}
+
+// setOptions reads encoding options from a table.
+func (e *encoder) setOptions(opts *rt.Table) error {
+	switch v := opts.Get(rt.StringValue("indent")); v.Type() {
+	case rt.NilType:
+	case rt.StringType:
+		e.indent = v.AsString()
+	case rt.BoolType:
+		if v.AsBool() {
+			e.indent = "  "
+		}
+	default:
+		n, ok := rt.ToInt(v)
+		if !ok || n < 0 || n > 16 {
+			return errInvalidIndent
+		}
+		e.indent = strings.Repeat(" ", int(n))
+	}
+	e.sortKeys = rt.Truth(opts.Get(rt.StringValue("sortkeys")))
+	sparse, err := enumOption(opts, "sparse", "error", "null", "object")
+	if err != nil {
+		return err
+	}
+	nan, err := enumOption(opts, "nan", "error", "null", "string")
+	if err != nil {
+		return err
+	}
+	cycles, err := enumOption(opts, "cycles", "error", "null")
+	if err != nil {
+		return err
+	}
+	empty, err := enumOption(opts, "emptytable", "object", "array")
+	if err != nil {
+		return err
+	}
+	e.sparse = sparseMode(sparse)
+	e.nan = nanMode(nan)
+	e.cycles = cycleMode(cycles)
+	e.emptyArrays = empty == 1
+	return nil
+}
+
+// enumOption returns the index in names of the string value of the given
+// option.  If the option is not set, it returns 0.
+func enumOption(opts *rt.Table, name string, names ...string) (int, error) {
+	v := opts.Get(rt.StringValue(name))
+	if v.IsNil() {
+		return 0, nil
+	}
+	if s, ok := v.TryString(); ok {
+		for i, n := range names {
+			if s == n {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%s option must be one of %q", name, names)
+}
+
+func (e *encoder) write(s string) {
+	e.t.RequireBytes(len(s))
+	e.buf.WriteString(s)
+}
+
+func (e *encoder) writeByte(b byte) {
+	e.t.RequireBytes(1)
+	e.buf.WriteByte(b)
+}
+
+func (e *encoder) newline() {
+	if e.indent == "" {
+		return
+	}
+	e.writeByte('\n')
+	for i := 0; i < e.depth; i++ {
+		e.write(e.indent)
+	}
+}
+
+func (e *encoder) encode(v rt.Value) error {
+	e.t.RequireCPU(1)
+	switch v.Type() {
+	case rt.NilType:
+		e.write("null")
+	case rt.BoolType:
+		if v.AsBool() {
+			e.write("true")
+		} else {
+			e.write("false")
+		}
+	case rt.IntType:
+		e.write(strconv.FormatInt(v.AsInt(), 10))
+	case rt.FloatType:
+		return e.encodeFloat(v.AsFloat())
+	case rt.StringType:
+		e.encodeString(v.AsString())
+	case rt.TableType:
+		return e.encodeTable(v.AsTable())
+	default:
+		if isNull(v) {
+			e.write("null")
+			return nil
+		}
+		return fmt.Errorf("cannot encode value of type %s", v.TypeName())
+	}
+	return nil
+}
+
+// encodeFloat encodes f so that it decodes back to a float, i.e. 1.0 is
+// encoded as "1.0" rather than "1".
+func (e *encoder) encodeFloat(f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		switch e.nan {
+		case nanNull:
+			e.write("null")
+		case nanString:
+			switch {
+			case math.IsNaN(f):
+				e.write(`"NaN"`)
+			case f > 0:
+				e.write(`"Infinity"`)
+			default:
+				e.write(`"-Infinity"`)
+			}
+		default:
+			return errNaNOrInf
+		}
+		return nil
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	e.write(s)
+	return nil
+}
+
+func (e *encoder) encodeString(s string) {
+	const hex = "0123456789abcdef"
+	e.t.RequireCPU(uint64(len(s)))
+	e.writeByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b >= utf8.RuneSelf {
+			r, n := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && n == 1 {
+				// Invalid UTF-8 is replaced so that the output is valid JSON.
+				e.write(s[start:i])
+				e.write(`\ufffd`)
+				start = i + 1
+			}
+			i += n
+			continue
+		}
+		if b >= 0x20 && b != '"' && b != '\\' {
+			i++
+			continue
+		}
+		e.write(s[start:i])
+		switch b {
+		case '"', '\\':
+			e.write(string([]byte{'\\', b}))
+		case '\n':
+			e.write(`\n`)
+		case '\r':
+			e.write(`\r`)
+		case '\t':
+			e.write(`\t`)
+		default:
+			e.write(string([]byte{'\\', 'u', '0', '0', hex[b>>4], hex[b&0xf]}))
+		}
+		i++
+		start = i
+	}
+	e.write(s[start:])
+	e.writeByte('"')
+}
+
+// An entry in a table being encoded.
+type tableEntry struct {
+	key, val rt.Value
+	name     string // Set when the table is encoded as an object
+}
+
+func (e *encoder) encodeTable(tbl *rt.Table) error {
+	if _, ok := e.visiting[tbl]; ok {
+		if e.cycles == cycleNull {
+			e.write("null")
+			return nil
+		}
+		return errCycle
+	}
+	if e.depth >= maxDepth {
+		return errMaxDepth
+	}
+	e.visiting[tbl] = struct{}{}
+	defer delete(e.visiting, tbl)
+
+	var (
+		entries []tableEntry
+		maxKey  int64
+		intKeys = true
+	)
+	for k, v, ok := tbl.Next(rt.NilValue); ok && !k.IsNil(); k, v, ok = tbl.Next(k) {
+		e.t.RequireCPU(1)
+		if v.IsNil() {
+			continue
+		}
+		if n, ok := k.TryInt(); ok && n > 0 {
+			if n > maxKey {
+				maxKey = n
+			}
+		} else {
+			intKeys = false
+		}
+		entries = append(entries, tableEntry{key: k, val: v})
+	}
+	mem := e.t.RequireArrSize(unsafe.Sizeof(tableEntry{}), len(entries))
+	defer e.t.ReleaseMem(mem)
+
+	var isArray bool
+	switch jsonType(tbl) {
+	case "array":
+		if !intKeys {
+			return errArrayKeys
+		}
+		isArray = true
+	case "object":
+		isArray = false
+	default:
+		if len(entries) == 0 {
+			isArray = e.emptyArrays
+		} else if intKeys {
+			isArray = true
+			if maxKey > int64(len(entries)) {
+				switch e.sparse {
+				case sparseNull:
+				case sparseObject:
+					isArray = false
+				default:
+					return errSparseArray
+				}
+			}
+		}
+	}
+	if isArray {
+		return e.encodeArray(tbl, maxKey)
+	}
+	return e.encodeObject(entries)
+}
+
+// jsonType returns the value of the __jsontype metafield of tbl.
+func jsonType(tbl *rt.Table) string {
+	meta := tbl.Metatable()
+	if meta == nil {
+		return ""
+	}
+	s, _ := meta.Get(rt.StringValue(jsonTypeField)).TryString()
+	return s
+}
+
+func (e *encoder) encodeArray(tbl *rt.Table, n int64) error {
+	if n == 0 {
+		e.write("[]")
+		return nil
+	}
+	e.writeByte('[')
+	e.depth++
+	for i := int64(1); i <= n; i++ {
+		if i > 1 {
+			e.writeByte(',')
+		}
+		e.newline()
+		if err := e.encode(tbl.Get(rt.IntValue(i))); err != nil {
+			return err
+		}
+	}
+	e.depth--
+	e.newline()
+	e.writeByte(']')
+	return nil
+}
+
+func (e *encoder) encodeObject(entries []tableEntry) error {
+	if len(entries) == 0 {
+		e.write("{}")
+		return nil
+	}
+	for i := range entries {
+		k := entries[i].key
+		switch k.Type() {
+		case rt.StringType:
+			entries[i].name = k.AsString()
+		case rt.IntType:
+			entries[i].name = strconv.FormatInt(k.AsInt(), 10)
+		case rt.FloatType:
+			entries[i].name = strconv.FormatFloat(k.AsFloat(), 'g', -1, 64)
+		default:
+			return errInvalidKey
+		}
+	}
+	if err := e.checkDuplicateNames(entries); err != nil {
+		return err
+	}
+	if e.sortKeys {
+		e.t.RequireCPU(uint64(len(entries)))
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+	}
+	e.writeByte('{')
+	e.depth++
+	for i, entry := range entries {
+		if i > 0 {
+			e.writeByte(',')
+		}
+		e.newline()
+		e.encodeString(entry.name)
+		if e.indent != "" {
+			e.write(": ")
+		} else {
+			e.writeByte(':')
+		}
+		if err := e.encode(entry.val); err != nil {
+			return err
+		}
+	}
+	e.depth--
+	e.newline()
+	e.writeByte('}')
+	return nil
+}
+
+// checkDuplicateNames returns an error if two entries have the same name, which
+// happens when a table has keys such as "1" and 1.
+func (e *encoder) checkDuplicateNames(entries []tableEntry) error {
+	mem := e.t.RequireArrSize(unsafe.Sizeof(""), len(entries))
+	defer e.t.ReleaseMem(mem)
+	names := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if _, ok := names[entry.name]; ok {
+			return fmt.Errorf("duplicate key %q in object", entry.name)
+		}
+		names[entry.name] = struct{}{}
+	}
+	return nil
+}
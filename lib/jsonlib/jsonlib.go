@@ -0,0 +1,199 @@
+// Package jsonlib implements the "json" Lua library, which converts between Lua
+// values and JSON text.
+//
+// JSON objects decode to tables with string keys and JSON arrays to sequences.
+// Decoded tables have the same metatables as tables returned by json.object and
+// json.array, so that they are encoded back to the same JSON type.
+// The JSON null value decodes to the json.null sentinel (so that it can be
+// stored in tables), JSON integers decode to Lua integers when they fit and all
+// other numbers decode to floats.
+package jsonlib
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/arnodel/golua/lib/iolib"
+	"github.com/arnodel/golua/lib/packagelib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// LibLoader can load the json lib.
+var LibLoader = packagelib.Loader{
+	Load: load,
+	Name: "json",
+}
+
+type jsonKeyType struct{}
+
+var jsonKey = rt.AsValue(jsonKeyType{})
+
+type jsonData struct {
+	null       rt.Value
+	arrayMeta  *rt.Table
+	objectMeta *rt.Table
+}
+
+func getJSONData(r *rt.Runtime) *jsonData {
+	return r.Registry(jsonKey).Interface().(*jsonData)
+}
+
+// The Go value of the json.null userdata.
+type nullType struct{}
+
+func isNull(v rt.Value) bool {
+	u, ok := v.TryUserData()
+	if !ok {
+		return false
+	}
+	_, ok = u.Value().(*nullType)
+	return ok
+}
+
+func load(r *rt.Runtime) (rt.Value, func()) {
+	nullMeta := rt.NewTable()
+	r.SetEnv(nullMeta, "__name", rt.StringValue("json.null"))
+
+	arrayMeta := rt.NewTable()
+	r.SetEnv(arrayMeta, jsonTypeField, rt.StringValue("array"))
+
+	objectMeta := rt.NewTable()
+	r.SetEnv(objectMeta, jsonTypeField, rt.StringValue("object"))
+
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(nullMeta, "__tostring", nulltostring, 1, false),
+	)
+
+	null := r.NewUserDataValue(new(nullType), nullMeta)
+	r.SetRegistry(jsonKey, rt.AsValue(&jsonData{
+		null:       null,
+		arrayMeta:  arrayMeta,
+		objectMeta: objectMeta,
+	}))
+
+	pkg := rt.NewTable()
+	r.SetEnv(pkg, "null", null)
+
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(pkg, "array", array, 1, false),
+		r.SetEnvGoFunc(pkg, "encode", encode, 2, false),
+		r.SetEnvGoFunc(pkg, "object", object, 1, false),
+	)
+
+	// Decoding may read from a file, which may block so it is not timesafe.
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(pkg, "decode", decode, 2, false),
+	)
+
+	return rt.TableValue(pkg), nil
+}
+
+func nulltostring(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return c.PushingNext1(t.Runtime, rt.StringValue("null")), nil
+}
+
+func encode(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	e := newEncoder(t)
+	if c.NArgs() >= 2 && !c.Arg(1).IsNil() {
+		opts, err := c.TableArg(1)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.setOptions(opts); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.encode(c.Arg(0)); err != nil {
+		return nil, err
+	}
+	return c.PushingNext1(t.Runtime, rt.StringValue(e.buf.String())), nil
+}
+
+// decode decodes a JSON string, or the next JSON value in a file.  In the
+// latter case, it returns nil when the end of the file is reached.
+func decode(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	data := getJSONData(t.Runtime)
+	d := &decoder{
+		t:      t,
+		null:   data.null,
+		array:  data.arrayMeta,
+		object: data.objectMeta,
+	}
+	if c.NArgs() >= 2 && !c.Arg(1).IsNil() {
+		opts, err := c.TableArg(1)
+		if err != nil {
+			return nil, err
+		}
+		if null := opts.Get(rt.StringValue("null")); !null.IsNil() {
+			d.null = null
+		}
+	}
+	var (
+		v   rt.Value
+		err error
+	)
+	if f, ok := fileArg(c, 0); ok {
+		d.r = f
+		v, err = d.decodeNext()
+	} else {
+		s, serr := c.StringArg(0)
+		if serr != nil {
+			return nil, errors.New("#1 must be a string or a file")
+		}
+		d.r = strings.NewReader(s)
+		v, err = d.decodeAll()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.PushingNext1(t.Runtime, v), nil
+}
+
+func fileArg(c *rt.GoCont, n int) (*iolib.File, bool) {
+	u, ok := c.Arg(n).TryUserData()
+	if !ok {
+		return nil, false
+	}
+	f, ok := u.Value().(*iolib.File)
+	return f, ok
+}
+
+func array(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return setJSONType(t, c, getJSONData(t.Runtime).arrayMeta)
+}
+
+func object(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return setJSONType(t, c, getJSONData(t.Runtime).objectMeta)
+}
+
+// setJSONType gives the table in argument 0 (or a new table) a metatable
+// forcing it to be encoded as an array or an object.
+func setJSONType(t *rt.Thread, c *rt.GoCont, meta *rt.Table) (rt.Cont, error) {
+	var tbl *rt.Table
+	if c.NArgs() == 0 || c.Arg(0).IsNil() {
+		tbl = rt.NewTable()
+	} else {
+		var err error
+		tbl, err = c.TableArg(0)
+		if err != nil {
+			return nil, err
+		}
+		if mt := tbl.Metatable(); mt != nil && mt != meta {
+			return nil, errors.New("#1 already has a metatable")
+		}
+	}
+	tbl.SetMetatable(meta)
+	return c.PushingNext1(t.Runtime, rt.TableValue(tbl)), nil
+}
@@ -0,0 +1,177 @@
+-- Encoding scalars
+do
+    print(json.encode(nil), json.encode(true), json.encode(false))
+    --> =null	true	false
+    print(json.encode(42), json.encode(-1.5), json.encode(2.0), json.encode(1e300))
+    --> =42	-1.5	2.0	1e+300
+    print(json.encode("a\"b\\c\n\t\1/é"))
+    --> ="a\"b\\c\n\t\u0001/é"
+    print(json.encode("bad\xffutf8"))
+    --> ="bad\ufffdutf8"
+    print(json.encode(json.null), tostring(json.null))
+    --> =null	null
+end
+
+-- Arrays and objects
+do
+    print(json.encode({1, 2, "three", {}}))
+    --> =[1,2,"three",{}]
+    print(json.encode({b=1, a={x=true}, c={}}, {sortkeys=true}))
+    --> ={"a":{"x":true},"b":1,"c":{}}
+    print(json.encode({}, {emptytable="array"}))
+    --> =[]
+    print(json.encode(json.array()), json.encode(json.object({1, 2})))
+    --> =[]	{"1":1,"2":2}
+    print(pcall(json.encode, {["1"]=1, [1]=2}))
+    --> ~false	.*duplicate key "1" in object
+    print(json.encode({[1.5]="x", [-1]="y"}, {sortkeys=true}))
+    --> ={"-1":"y","1.5":"x"}
+    print(pcall(json.encode, {[true]=1}))
+    --> ~false	.*table key must be a string or a number
+    print(pcall(json.encode, {print}))
+    --> ~false	.*cannot encode value of type function
+    print(pcall(json.array, setmetatable({}, {})))
+    --> ~false	.*already has a metatable
+    print(pcall(json.encode, json.array({x=1})))
+    --> ~false	.*non positive integer keys
+end
+
+-- Pretty printing
+do
+    print(json.encode({a={1, 2}, b={}}, {indent=2, sortkeys=true}))
+    --> ={
+    --> =  "a": [
+    --> =    1,
+    --> =    2
+    --> =  ],
+    --> =  "b": {}
+    --> =}
+    print(json.encode({1}, {indent="\t"}))
+    --> =[
+    --> =	1
+    --> =]
+    print(pcall(json.encode, {}, {indent={}}))
+    --> ~false	.*indent must be
+end
+
+-- Sparse arrays
+do
+    local t = {1, nil, 3}
+    print(pcall(json.encode, t))
+    --> ~false	.*cannot encode sparse array
+    print(json.encode(t, {sparse="null"}))
+    --> =[1,null,3]
+    print(json.encode(t, {sparse="object", sortkeys=true}))
+    --> ={"1":1,"3":3}
+    print(pcall(json.encode, t, {sparse="foo"}))
+    --> ~false	.*sparse option must be one of \["error" "null" "object"\]
+end
+
+-- NaN and infinity
+do
+    print(pcall(json.encode, 0/0))
+    --> ~false	.*cannot encode NaN or infinity
+    print(json.encode({1/0, -1/0, 0/0}, {nan="null"}))
+    --> =[null,null,null]
+    print(json.encode({1/0, -1/0, 0/0}, {nan="string"}))
+    --> =["Infinity","-Infinity","NaN"]
+end
+
+-- Cycles
+do
+    local t = {1}
+    t[2] = t
+    print(pcall(json.encode, t))
+    --> ~false	.*cannot encode table containing a cycle
+    print(json.encode(t, {cycles="null"}))
+    --> =[1,null]
+
+    -- Shared tables are not cycles
+    local s = {}
+    print(json.encode({s, s}))
+    --> =[{},{}]
+end
+
+-- Decoding
+do
+    print(json.decode("42"), math.type(json.decode("42")))
+    --> =42	integer
+    print(json.decode("-1.5"), math.type(json.decode("2.0")), math.type(json.decode("1e2")))
+    --> =-1.5	float	float
+    print(math.type(json.decode("123456789012345678901234567890")))
+    --> =float
+    print(json.decode("true"), json.decode("false"), json.decode("null") == json.null)
+    --> =true	false	true
+    print(json.decode([["a\"b\\c\/\né😀"]]))
+    --> =a"b\c/
+    --> =é😀
+
+    local t = json.decode([[ {"a": [1, 2, {"b": null}], "c": "d", "e": {}} ]])
+    print(#t.a, t.a[1], t.a[3].b == json.null, t.c, next(t.e))
+    --> =3	1	true	d	nil
+
+    local t = json.decode("[1, null, 3]", {null=false})
+    print(#t, t[2])
+    --> =3	false
+end
+
+-- Round trip
+do
+    local v = {1, 2.0, "x", {y={true, false}}}
+    local s = json.encode(v)
+    print(s)
+    --> =[1,2.0,"x",{"y":[true,false]}]
+    print(json.encode(json.decode(s)) == s)
+    --> =true
+
+    print(json.encode(json.decode("[]")), json.encode(json.decode("{}")))
+    --> =[]	{}
+    print(json.encode(json.decode([[ {"a": [], "b": {}} ]]), {sortkeys=true}))
+    --> ={"a":[],"b":{}}
+end
+
+-- Decoding errors
+do
+    local function try(s)
+        print(pcall(json.decode, s))
+    end
+    try("")
+    --> ~false	.*invalid JSON at offset 0: unexpected end of input
+    try("[1,]")
+    --> ~false	.*invalid JSON at offset 4: unexpected ']'
+    try("{1: 2}")
+    --> ~false	.*expected object key
+    try("[1 2]")
+    --> ~false	.*unexpected '2' in array
+    try("01")
+    --> ~false	.*invalid number
+    try("1.")
+    --> ~false	.*invalid number
+    try("tru")
+    --> ~false	.*unexpected end of input
+    try("1 2")
+    --> ~false	.*unexpected '2' after value
+    try('"\\x"')
+    --> ~false	.*invalid escape sequence
+    try('"a\nb"')
+    --> ~false	.*unexpected control character
+    try(("["):rep(2000))
+    --> ~false	.*maximum nesting depth exceeded
+    try({})
+    --> ~false	.*must be a string or a file
+end
+
+-- Streaming from files
+do
+    local f = io.tmpfile()
+    f:write('{"n": 1} {"n": 2}\n[3]\n  "four"  ')
+    f:seek("set")
+    print(json.decode(f).n, json.decode(f).n, json.decode(f)[1], json.decode(f), json.decode(f))
+    --> =1	2	3	four	nil
+
+    -- The file can still be read after decoding a value.
+    f:seek("set")
+    print(json.decode(f).n, f:read("l"))
+    --> =1	 {"n": 2}
+    f:close()
+end
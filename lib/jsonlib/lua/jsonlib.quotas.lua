@@ -0,0 +1,59 @@
+local function mk(s, n)
+    local t = {}
+    for i = 1, n do
+        t[i] = s
+    end
+    return t
+end
+
+-- json.encode uses memory
+do
+    local s1000 = ("a"):rep(1000)
+    local ctx, res = runtime.callcontext({kill={memory=10000}}, json.encode, mk(s1000, 4))
+    print(ctx, #res)
+    --> =done	4013
+    print(ctx.used.memory >= 4000)
+    --> =true
+
+    print(runtime.callcontext({kill={memory=10000}}, json.encode, mk(s1000, 10)))
+    --> =killed
+end
+
+-- json.encode uses cpu
+do
+    local ctx = runtime.callcontext({kill={cpu=1000}}, json.encode, mk(1, 100))
+    print(ctx)
+    --> =done
+    print(ctx.used.cpu >= 100)
+    --> =true
+
+    print(runtime.callcontext({kill={cpu=1000}}, json.encode, mk(1, 1000)))
+    --> =killed
+end
+
+-- json.decode uses memory
+do
+    local s = json.encode(("a"):rep(5000))
+    print((runtime.callcontext({kill={memory=10000}}, json.decode, s)))
+    --> =done
+    print(runtime.callcontext({kill={memory=10000}}, json.decode, json.encode({s, s})))
+    --> =killed
+end
+
+-- json.decode uses cpu
+do
+    local s = json.encode(mk(1, 1000))
+    print(runtime.callcontext({kill={cpu=1000}}, json.decode, s))
+    --> =killed
+end
+
+-- json functions are safe to call in a restricted context, except decode which
+-- may read from a file.
+do
+    print(runtime.callcontext({flags="cpusafe memsafe timesafe iosafe"}, json.encode, {1, 2}))
+    --> =done	[1,2]
+    print(runtime.callcontext({flags="cpusafe memsafe iosafe"}, json.decode, "[1, 2]"))
+    --> ~done	table: .*
+    print(runtime.callcontext({flags="timesafe"}, json.decode, "[1, 2]"))
+    --> ~error	.*missing flags: timesafe
+end
@@ -0,0 +1,12 @@
+package jsonlib_test
+
+import (
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	"github.com/arnodel/golua/luatesting"
+)
+
+func TestJSONLib(t *testing.T) {
+	luatesting.RunLuaTestsInDir(t, "lua", lib.LoadAll)
+}
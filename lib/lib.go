@@ -2,17 +2,22 @@ package lib
 
 import (
 	"github.com/arnodel/golua/lib/base"
+	"github.com/arnodel/golua/lib/compatlib"
 	"github.com/arnodel/golua/lib/coroutine"
 	"github.com/arnodel/golua/lib/debuglib"
+	"github.com/arnodel/golua/lib/eventlib"
 	"github.com/arnodel/golua/lib/golib"
 	"github.com/arnodel/golua/lib/iolib"
+	"github.com/arnodel/golua/lib/jsonlib"
 	"github.com/arnodel/golua/lib/mathlib"
 	"github.com/arnodel/golua/lib/oslib"
 	"github.com/arnodel/golua/lib/packagelib"
+	"github.com/arnodel/golua/lib/relib"
 	"github.com/arnodel/golua/lib/runtimelib"
 	"github.com/arnodel/golua/lib/stringlib"
 	"github.com/arnodel/golua/lib/tablelib"
 	"github.com/arnodel/golua/lib/utf8lib"
+	"github.com/arnodel/golua/lib/workerlib"
 	rt "github.com/arnodel/golua/runtime"
 )
 
@@ -46,5 +51,10 @@ func LoadAll(r *rt.Runtime) func() {
 		debuglib.LibLoader,
 		golib.LibLoader,
 		runtimelib.LibLoader,
+		eventlib.LibLoader,
+		jsonlib.LibLoader,
+		relib.LibLoader,
+		workerlib.NewLibLoader(LoadAll),
+		compatlib.LibLoader,
 	)
 }
@@ -0,0 +1,151 @@
+-- Compiling
+do
+    local rx = re.compile("[0-9]+")
+    print(rx)
+    --> =regex("[0-9]+")
+    print(re.compile("abc", "i"))
+    --> =regex("(?i)abc")
+    print(pcall(re.compile, "a(b"))
+    --> ~false	.*missing closing \)
+    print(pcall(re.compile, "a", "x"))
+    --> ~false	.*invalid flag
+    print(re.quote("1+1=2?"))
+    --> =1\+1=2\?
+    print(pcall(re.compile("x").find, {}, "x"))
+    --> ~false	.*#1 must be a regex
+end
+
+-- find returns positions then captures, like string.find
+do
+    local rx = re.compile("(\\w+)@(\\w+)")
+    print(rx:find("mail bob@example now"))
+    --> =6	16	bob	example
+    print(re.compile("[0-9]+"):find("abc 123 def"))
+    --> =5	7
+    print(re.compile("x"):find("abc"))
+    --> =nil
+
+    -- init position, which can be negative
+    local digits = re.compile("[0-9]")
+    print(digits:find("1a2b3", 2))
+    --> =3	3
+    print(digits:find("1a2b3", -2))
+    --> =5	5
+    print(digits:find("1a2b3", 10))
+    --> =nil
+
+    -- ^ anchors at the init position, as with string.find
+    print(re.compile("^b"):find("abc", 2))
+    --> =2	2
+
+    -- empty match
+    print(re.compile("x*"):find("abc"))
+    --> =1	0
+
+    -- unmatched captures are false
+    print(re.compile("(a)|(b)"):find("b"))
+    --> =1	1	false	b
+end
+
+-- match and test
+do
+    print(re.compile("(\\d+)-(\\d+)"):match("tel: 555-1234"))
+    --> =555	1234
+    print(re.compile("\\d+"):match("tel: 555-1234", 11))
+    --> =234
+    print(re.compile("z"):match("abc"))
+    --> =nil
+    local rx = re.compile("^h", "i")
+    print(rx:test("Hello"), rx:test("oh"))
+    --> =true	false
+end
+
+-- exec and named captures
+do
+    local rx = re.compile("(?P<key>\\w+)=(?P<value>\\w*)(;)?")
+    local i, j, m = rx:exec("  a=1;b=")
+    print(i, j, m[0], m[1], m[2], m[3], m.key, m.value)
+    --> =3	6	a=1;	a	1	;	a	1
+    local _, _, m = rx:exec("b=")
+    print(m[0], m.value == "", m[3])
+    --> =b=	true	false
+    local names = rx:names()
+    print(names[1], names[2], names[3])
+    --> =key	value	nil
+end
+
+-- findall
+do
+    local ms = re.compile("(\\w)(\\d)"):findall("a1 b2 c3")
+    print(#ms, ms[1][0], ms[2][1], ms[3][2])
+    --> =3	a1	b	3
+    print(#re.compile("\\d"):findall("1234", 2))
+    --> =2
+    print(#re.compile("x"):findall("abc"))
+    --> =0
+end
+
+-- gmatch
+do
+    for k, v in re.compile("(\\w+)=(\\w+)"):gmatch("a=1, b=2") do
+        print(k, v)
+    end
+    --> =a	1
+    --> =b	2
+    for w in re.compile("\\w+"):gmatch("one two three", 5) do
+        print(w)
+    end
+    --> =two
+    --> =three
+    local n = 0
+    for m in re.compile("x*"):gmatch("abc") do
+        n = n + 1
+    end
+    print(n)
+    --> =4
+end
+
+-- split
+do
+    local parts = re.compile("\\s*,\\s*"):split("a , b,c ,d")
+    print(#parts, table.concat(parts, "|"))
+    --> =4	a|b|c|d
+    parts = re.compile(","):split("a,b,c", 2)
+    print(#parts, parts[1], parts[2])
+    --> =2	a	b,c
+    print(#re.compile(","):split("", -1), #re.compile(","):split("a,b", 0))
+    --> =1	0
+end
+
+-- replace
+do
+    local rx = re.compile("(?P<first>\\w+) (?P<last>\\w+)")
+    print(rx:replace("John Smith, Jane Doe", "$last ${first}"))
+    --> =Smith John, Doe Jane	2
+    print(rx:replace("John Smith, Jane Doe", "$2", 1))
+    --> =Smith, Jane Doe	1
+    print(re.compile("\\d"):replace("a1b2", "$$"))
+    --> =a$b$	2
+    print(re.compile("x"):replace("abc", "y"))
+    --> =abc	0
+
+    -- table replacement, indexed with the first capture
+    local vars = {name="golua", version=5}
+    print(re.compile("\\$(\\w+)"):replace("$name $version $unknown", vars))
+    --> =golua 5 $unknown	3
+
+    -- function replacement, called with the captures
+    print(re.compile("\\d+"):replace("1 22 333", function(d) return #d end))
+    --> =1 2 3	3
+    print(re.compile("(\\w)(\\w*)"):replace("hello world", function(a, b)
+        return a:upper() .. b
+    end))
+    --> =Hello World	2
+    print(re.compile("\\w+"):replace("keep this", function() return false end))
+    --> =keep this	2
+    local a = re.compile("a")
+    print(pcall(a.replace, a, "a", function() return {} end))
+    --> ~false	.*invalid replacement value \(a table\)
+    print(pcall(a.replace, a, "a", true))
+    --> ~false	.*#3 must be a string, table or function
+end
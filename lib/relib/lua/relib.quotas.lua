@@ -0,0 +1,41 @@
+-- Searching uses cpu proportional to the size of the subject
+do
+    local rx = re.compile("x")
+    local s = ("a"):rep(10000)
+    local ctx = runtime.callcontext({kill={cpu=20000}}, rx.find, rx, s)
+    print(ctx, ctx.used.cpu >= 10000)
+    --> =done	true
+    print(runtime.callcontext({kill={cpu=5000}}, rx.find, rx, s))
+    --> =killed
+    print(runtime.callcontext({kill={cpu=5000}}, rx.replace, rx, s, "y"))
+    --> =killed
+    print(runtime.callcontext({kill={cpu=5000}}, rx.split, rx, s))
+    --> =killed
+end
+
+-- Replacing uses memory
+do
+    local rx = re.compile("a")
+    local s = ("a"):rep(1000)
+    print(runtime.callcontext({kill={memory=100000}}, rx.replace, rx, s, "bb"))
+    --> ~done	b+	1000
+    print(runtime.callcontext({kill={memory=10000}}, rx.replace, rx, s, ("b"):rep(100)))
+    --> =killed
+end
+
+-- Compiling requires cpu and memory proportional to the program size
+do
+    print(runtime.callcontext({kill={memory=10000}}, re.compile, "a{1000}"))
+    --> =killed
+    print((runtime.callcontext({kill={memory=10000}}, re.compile, "a{10}")))
+    --> =done
+end
+
+-- The library is safe to use in a restricted context
+do
+    local ctx, rx = runtime.callcontext({flags="cpusafe memsafe timesafe iosafe"}, re.compile, "a+")
+    print(ctx)
+    --> =done
+    print(runtime.callcontext({flags="cpusafe memsafe timesafe iosafe"}, rx.replace, rx, "baab", "c"))
+    --> =done	bcb	1
+end
@@ -0,0 +1,12 @@
+package relib_test
+
+import (
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	"github.com/arnodel/golua/luatesting"
+)
+
+func TestReLib(t *testing.T) {
+	luatesting.RunLuaTestsInDir(t, "lua", lib.LoadAll)
+}
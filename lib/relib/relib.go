@@ -0,0 +1,538 @@
+// Package relib implements the "re" Lua library, which gives access to Go
+// regular expressions (RE2 syntax, see https://golang.org/s/re2syntax).
+//
+// Positions are byte offsets following the conventions of the string library:
+// they start at 1, ranges are inclusive and a negative init position counts
+// from the end of the subject.  When searching from an init position, the
+// subject is considered to start there, so that "^" matches at init as it does
+// with string.find.
+//
+// Regular expressions run in time linear in the size of their input, so all
+// functions in this library are declared cpu safe.
+package relib
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unsafe"
+
+	"github.com/arnodel/golua/lib/packagelib"
+	"github.com/arnodel/golua/luastrings"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// LibLoader can load the re lib.
+var LibLoader = packagelib.Loader{
+	Load: load,
+	Name: "re",
+}
+
+type regexMetaKeyType struct{}
+
+var regexMetaKey = rt.AsValue(regexMetaKeyType{})
+
+func load(r *rt.Runtime) (rt.Value, func()) {
+	methods := rt.NewTable()
+	meta := rt.NewTable()
+	r.SetEnv(meta, "__name", rt.StringValue("regex"))
+	r.SetEnv(meta, "__index", rt.TableValue(methods))
+	r.SetRegistry(regexMetaKey, rt.TableValue(meta))
+
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(methods, "exec", exec, 3, false),
+		r.SetEnvGoFunc(methods, "find", find, 3, false),
+		r.SetEnvGoFunc(methods, "findall", findall, 3, false),
+		r.SetEnvGoFunc(methods, "gmatch", gmatch, 3, false),
+		r.SetEnvGoFunc(methods, "match", match, 3, false),
+		r.SetEnvGoFunc(methods, "names", names, 1, false),
+		r.SetEnvGoFunc(methods, "replace", replace, 4, false),
+		r.SetEnvGoFunc(methods, "split", split, 3, false),
+		r.SetEnvGoFunc(methods, "test", test, 3, false),
+
+		r.SetEnvGoFunc(meta, "__tostring", tostring, 1, false),
+	)
+
+	pkg := rt.NewTable()
+
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
+
+		r.SetEnvGoFunc(pkg, "compile", compile, 2, false),
+		r.SetEnvGoFunc(pkg, "quote", quote, 1, false),
+	)
+
+	return rt.TableValue(pkg), nil
+}
+
+func regexArg(c *rt.GoCont, n int) (*regexp.Regexp, error) {
+	u, ok := c.Arg(n).TryUserData()
+	if ok {
+		if re, ok := u.Value().(*regexp.Regexp); ok {
+			return re, nil
+		}
+	}
+	return nil, fmt.Errorf("#%d must be a regex", n+1)
+}
+
+// regexAndSubject returns the regex and the subject string in arguments 0 and
+// 1, and the index in the subject of the init position in argument 2
+// (defaulting to 1).  The index is -1 if init is past the end of the subject.
+func regexAndSubject(c *rt.GoCont) (re *regexp.Regexp, s string, si int, err error) {
+	if err = c.CheckNArgs(2); err != nil {
+		return
+	}
+	if re, err = regexArg(c, 0); err != nil {
+		return
+	}
+	if s, err = c.StringArg(1); err != nil {
+		return
+	}
+	init := int64(1)
+	if c.NArgs() >= 3 && !c.Arg(2).IsNil() {
+		if init, err = c.IntArg(2); err != nil {
+			return
+		}
+	}
+	si = luastrings.StringNormPos(s, int(init)) - 1
+	if si < 0 {
+		si = 0
+	}
+	if si > len(s) {
+		si = -1
+	}
+	return
+}
+
+var errInvalidFlag = errors.New("invalid flag, must be one of 'i', 'm', 's', 'U'")
+
+// compileRegex compiles a regular expression with flags, requiring cpu and
+// memory in proportion to the size of the compiled program.
+func compileRegex(t *rt.Thread, ptn string, flags string) (*regexp.Regexp, error) {
+	for _, f := range flags {
+		if !strings.ContainsRune("imsU", f) {
+			return nil, errInvalidFlag
+		}
+	}
+	if flags != "" {
+		ptn = "(?" + flags + ")" + ptn
+	}
+	t.RequireCPU(uint64(len(ptn)))
+	parsed, err := syntax.Parse(ptn, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	t.RequireCPU(uint64(len(prog.Inst)))
+	t.RequireArrSize(unsafe.Sizeof(syntax.Inst{}), len(prog.Inst))
+	return regexp.Compile(ptn)
+}
+
+func compile(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	var (
+		ptn, flags string
+		err        error
+	)
+	if err = c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	if ptn, err = c.StringArg(0); err != nil {
+		return nil, err
+	}
+	if c.NArgs() >= 2 && !c.Arg(1).IsNil() {
+		if flags, err = c.StringArg(1); err != nil {
+			return nil, err
+		}
+	}
+	re, err := compileRegex(t, ptn, flags)
+	if err != nil {
+		return nil, err
+	}
+	meta := t.Registry(regexMetaKey).AsTable()
+	return c.PushingNext1(t.Runtime, t.NewUserDataValue(re, meta)), nil
+}
+
+func quote(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	s, err := c.StringArg(0)
+	if err != nil {
+		return nil, err
+	}
+	t.RequireCPU(uint64(len(s)))
+	q := regexp.QuoteMeta(s)
+	t.RequireBytes(len(q))
+	return c.PushingNext1(t.Runtime, rt.StringValue(q)), nil
+}
+
+func tostring(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	re, err := regexArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	s := fmt.Sprintf("regex(%q)", re.String())
+	t.RequireBytes(len(s))
+	return c.PushingNext1(t.Runtime, rt.StringValue(s)), nil
+}
+
+// findAt returns the submatch indices of the first match of re in s[si:],
+// offset so they are indices in s.  It requires cpu for the part of the subject
+// that was searched.
+func findAt(t *rt.Thread, re *regexp.Regexp, s string, si int) []int {
+	loc := re.FindStringSubmatchIndex(s[si:])
+	if loc == nil {
+		t.RequireCPU(uint64(len(s) - si))
+		return nil
+	}
+	t.RequireCPU(uint64(loc[1]) + 1)
+	for i, x := range loc {
+		if x >= 0 {
+			loc[i] = x + si
+		}
+	}
+	return loc
+}
+
+// findAll returns the submatch indices of up to n matches of re in s (all of
+// them if n < 0).  It requires cpu for the whole subject and memory for the
+// indices.
+func findAll(t *rt.Thread, re *regexp.Regexp, s string, n int) [][]int {
+	t.RequireCPU(uint64(len(s)) + 1)
+	locs := re.FindAllStringSubmatchIndex(s, n)
+	t.RequireArrSize(unsafe.Sizeof(int(0)), len(locs)*2*(re.NumSubexp()+1))
+	return locs
+}
+
+// captureValue returns the value of capture i in a match, which is false if
+// the capture did not participate in the match.
+func captureValue(t *rt.Thread, s string, loc []int, i int) rt.Value {
+	start, end := loc[2*i], loc[2*i+1]
+	if start < 0 {
+		return rt.BoolValue(false)
+	}
+	t.RequireBytes(end - start)
+	return rt.StringValue(s[start:end])
+}
+
+// pushCaptures pushes the captures of a match, or the whole match if the regex
+// has no captures, like string.match does.
+func pushCaptures(t *rt.Thread, next rt.Cont, s string, loc []int) {
+	n := len(loc)/2 - 1
+	if n == 0 {
+		t.Push1(next, captureValue(t, s, loc, 0))
+		return
+	}
+	for i := 1; i <= n; i++ {
+		t.Push1(next, captureValue(t, s, loc, i))
+	}
+}
+
+// matchTable returns a table containing the whole match at index 0, the
+// captures at index 1, 2, ... and named captures with their name as key.
+func matchTable(t *rt.Thread, re *regexp.Regexp, s string, loc []int) *rt.Table {
+	tbl := rt.NewTable()
+	for i, name := range re.SubexpNames() {
+		v := captureValue(t, s, loc, i)
+		t.SetTable(tbl, rt.IntValue(int64(i)), v)
+		if _, ok := v.TryString(); ok && name != "" {
+			t.SetTable(tbl, rt.StringValue(name), v)
+		}
+	}
+	return tbl
+}
+
+// find returns the start and end positions of the first match, followed by the
+// captures, like string.find.
+func find(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	re, s, si, err := regexAndSubject(c)
+	if err != nil {
+		return nil, err
+	}
+	next := c.Next()
+	var loc []int
+	if si >= 0 {
+		loc = findAt(t, re, s, si)
+	}
+	if loc == nil {
+		t.Push1(next, rt.NilValue)
+		return next, nil
+	}
+	t.Push1(next, rt.IntValue(int64(loc[0]+1)))
+	t.Push1(next, rt.IntValue(int64(loc[1])))
+	for i := 1; i < len(loc)/2; i++ {
+		t.Push1(next, captureValue(t, s, loc, i))
+	}
+	return next, nil
+}
+
+// match returns the captures of the first match, or the whole match if there
+// are no captures, like string.match.
+func match(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	re, s, si, err := regexAndSubject(c)
+	if err != nil {
+		return nil, err
+	}
+	next := c.Next()
+	var loc []int
+	if si >= 0 {
+		loc = findAt(t, re, s, si)
+	}
+	if loc == nil {
+		t.Push1(next, rt.NilValue)
+		return next, nil
+	}
+	pushCaptures(t, next, s, loc)
+	return next, nil
+}
+
+// exec returns the start and end positions of the first match, followed by a
+// match table (see matchTable).
+func exec(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	re, s, si, err := regexAndSubject(c)
+	if err != nil {
+		return nil, err
+	}
+	next := c.Next()
+	var loc []int
+	if si >= 0 {
+		loc = findAt(t, re, s, si)
+	}
+	if loc == nil {
+		t.Push1(next, rt.NilValue)
+		return next, nil
+	}
+	t.Push1(next, rt.IntValue(int64(loc[0]+1)))
+	t.Push1(next, rt.IntValue(int64(loc[1])))
+	t.Push1(next, rt.TableValue(matchTable(t, re, s, loc)))
+	return next, nil
+}
+
+func test(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	re, s, si, err := regexAndSubject(c)
+	if err != nil {
+		return nil, err
+	}
+	ok := si >= 0 && findAt(t, re, s, si) != nil
+	return c.PushingNext1(t.Runtime, rt.BoolValue(ok)), nil
+}
+
+// limitArg returns the optional argument n as a limit on the number of matches,
+// which is -1 (no limit) if it is nil.
+func limitArg(c *rt.GoCont, n int) (int, error) {
+	if c.NArgs() <= n || c.Arg(n).IsNil() {
+		return -1, nil
+	}
+	lim, err := c.IntArg(n)
+	if err != nil {
+		return 0, err
+	}
+	if lim < 0 {
+		return -1, nil
+	}
+	return int(lim), nil
+}
+
+// findall returns a sequence of match tables for all the matches (or the first
+// n ones).
+func findall(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.CheckNArgs(2); err != nil {
+		return nil, err
+	}
+	re, err := regexArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.StringArg(1)
+	if err != nil {
+		return nil, err
+	}
+	n, err := limitArg(c, 2)
+	if err != nil {
+		return nil, err
+	}
+	res := rt.NewTable()
+	for i, loc := range findAll(t, re, s, n) {
+		t.SetTable(res, rt.IntValue(int64(i+1)), rt.TableValue(matchTable(t, re, s, loc)))
+	}
+	return c.PushingNext1(t.Runtime, rt.TableValue(res)), nil
+}
+
+// gmatch returns an iterator over the matches, returning the captures of each
+// match (or the whole match if there are no captures) like string.gmatch.
+func gmatch(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	re, s, si, err := regexAndSubject(c)
+	if err != nil {
+		return nil, err
+	}
+	var locs [][]int
+	if si >= 0 {
+		locs = findAll(t, re, s[si:], -1)
+	}
+	var iterator = func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		next := c.Next()
+		if len(locs) == 0 {
+			t.Push1(next, rt.NilValue)
+			return next, nil
+		}
+		pushCaptures(t, next, s[si:], locs[0])
+		locs = locs[1:]
+		return next, nil
+	}
+	iterGof := rt.NewGoFunction(iterator, "gmatchiterator", 0, false)
+	iterGof.SolemnlyDeclareCompliance(rt.ComplyCpuSafe | rt.ComplyMemSafe | rt.ComplyTimeSafe | rt.ComplyIoSafe)
+	return c.PushingNext1(t.Runtime, rt.FunctionValue(iterGof)), nil
+}
+
+func names(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	re, err := regexArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	res := rt.NewTable()
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			t.RequireBytes(len(name))
+			t.SetTable(res, rt.IntValue(int64(i)), rt.StringValue(name))
+		}
+	}
+	return c.PushingNext1(t.Runtime, rt.TableValue(res)), nil
+}
+
+// split returns a sequence of the substrings between matches (at most n of
+// them if n >= 0).
+func split(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.CheckNArgs(2); err != nil {
+		return nil, err
+	}
+	re, err := regexArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.StringArg(1)
+	if err != nil {
+		return nil, err
+	}
+	n, err := limitArg(c, 2)
+	if err != nil {
+		return nil, err
+	}
+	t.RequireCPU(uint64(len(s)) + 1)
+	parts := re.Split(s, n)
+	res := rt.NewTable()
+	for i, part := range parts {
+		t.RequireBytes(len(part))
+		t.SetTable(res, rt.IntValue(int64(i+1)), rt.StringValue(part))
+	}
+	return c.PushingNext1(t.Runtime, rt.TableValue(res)), nil
+}
+
+// replace replaces matches in a string (all of them or the first n ones) and
+// returns the new string and the number of matches.  The replacement can be:
+//   - a string, where $1 or ${name} stand for captures (see regexp.Expand);
+//   - a table, indexed with the first capture (or the whole match);
+//   - a function, called with the captures (or the whole match).
+//
+// When a table or function gives false or nil, the match is kept unchanged.
+func replace(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.CheckNArgs(3); err != nil {
+		return nil, err
+	}
+	re, err := regexArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.StringArg(1)
+	if err != nil {
+		return nil, err
+	}
+	n, err := limitArg(c, 3)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		repl  = c.Arg(2)
+		replF func(loc []int) (string, error)
+	)
+	if tpl, ok := repl.TryString(); ok {
+		replF = func(loc []int) (string, error) {
+			t.RequireCPU(uint64(len(tpl)))
+			sub := string(re.ExpandString(nil, tpl, s, loc))
+			t.RequireBytes(len(sub))
+			return sub, nil
+		}
+	} else if tbl, ok := repl.TryTable(); ok {
+		replF = func(loc []int) (string, error) {
+			key := captureValue(t, s, loc, 0)
+			if len(loc) > 2 {
+				key = captureValue(t, s, loc, 1)
+			}
+			val, err := rt.Index(t, rt.TableValue(tbl), key)
+			if err != nil {
+				return "", err
+			}
+			return replacementString(t, s[loc[0]:loc[1]], val)
+		}
+	} else if f, ok := repl.TryCallable(); ok {
+		replF = func(loc []int) (string, error) {
+			term := rt.NewTerminationWith(c, 1, false)
+			cont := f.Continuation(t, term)
+			pushCaptures(t, cont, s, loc)
+			if err := t.RunContinuation(cont); err != nil {
+				return "", err
+			}
+			return replacementString(t, s[loc[0]:loc[1]], term.Get(0))
+		}
+	} else {
+		return nil, errors.New("#3 must be a string, table or function")
+	}
+	locs := findAll(t, re, s, n)
+	if len(locs) == 0 {
+		return c.PushingNext(t.Runtime, c.Arg(1), rt.IntValue(0)), nil
+	}
+	var (
+		sb strings.Builder
+		sj int // Index in s of the first byte not yet copied
+	)
+	for _, loc := range locs {
+		sub, err := replF(loc)
+		if err != nil {
+			return nil, err
+		}
+		t.RequireBytes(loc[0] - sj)
+		sb.WriteString(s[sj:loc[0]])
+		sb.WriteString(sub)
+		sj = loc[1]
+	}
+	t.RequireBytes(len(s) - sj)
+	sb.WriteString(s[sj:])
+	return c.PushingNext(t.Runtime, rt.StringValue(sb.String()), rt.IntValue(int64(len(locs)))), nil
+}
+
+// replacementString returns the string to substitute for a match given the
+// value returned by a replacement table or function.
+func replacementString(t *rt.Thread, match string, val rt.Value) (string, error) {
+	if !rt.Truth(val) {
+		t.RequireBytes(len(match))
+		return match, nil
+	}
+	res, ok := val.ToString()
+	if !ok {
+		return "", fmt.Errorf("invalid replacement value (a %s)", val.TypeName())
+	}
+	t.RequireBytes(len(res))
+	return res, nil
+}
@@ -45,61 +45,65 @@ func callcontext(t *rt.Thread, c *rt.GoCont) (next rt.Cont, retErr error) {
 	if err != nil {
 		return nil, err
 	}
+	var (
+		f     = c.Arg(1)
+		fArgs = c.Etc()
+	)
+	def, err := GetContextDef(t, quotas)
+	if err != nil {
+		return nil, err
+	}
+
+	next = c.Next()
+	res := rt.NewTerminationWith(c, 0, true)
+
+	ctx, err := t.CallContext(def, func() error {
+		return rt.Call(t, f, fArgs, res)
+	})
+	t.Push1(next, newContextValue(t.Runtime, ctx))
+	switch ctx.Status() {
+	case rt.StatusDone:
+		t.Push(next, res.Etc()...)
+	case rt.StatusError:
+		t.Push1(next, rt.ErrorValue(err))
+	}
+	return next, nil
+}
+
+// GetContextDef makes a RuntimeContextDef out of a Lua table with the same
+// fields as the first argument of runtime.callcontext (i.e. "kill", "stop" and
+// "flags").
+func GetContextDef(t *rt.Thread, quotas *rt.Table) (def rt.RuntimeContextDef, err error) {
 	var (
 		flagsV      = quotas.Get(rt.StringValue("flags"))
 		limitsV     = quotas.Get(rt.StringValue("kill"))
 		softLimitsV = quotas.Get(rt.StringValue("stop"))
-		hardLimits  rt.RuntimeResources
-		softLimits  rt.RuntimeResources
-		f           = c.Arg(1)
-		fArgs       = c.Etc()
-		flags       rt.ComplianceFlags
 	)
 	if !limitsV.IsNil() {
-		var err error
-		hardLimits, err = getResources(t, limitsV)
+		def.HardLimits, err = getResources(t, limitsV)
 		if err != nil {
-			return nil, err
+			return
 		}
 	}
 	if !softLimitsV.IsNil() {
-		var err error
-		softLimits, err = getResources(t, softLimitsV)
+		def.SoftLimits, err = getResources(t, softLimitsV)
 		if err != nil {
-			return nil, err
+			return
 		}
 	}
 	if !flagsV.IsNil() {
 		flagsStr, ok := flagsV.TryString()
 		if !ok {
-			return nil, errors.New("flags must be a string")
+			return def, errors.New("flags must be a string")
 		}
 		for _, name := range strings.Fields(flagsStr) {
-			flags, ok = flags.AddFlagWithName(name)
+			def.RequiredFlags, ok = def.RequiredFlags.AddFlagWithName(name)
 			if !ok {
-				return nil, fmt.Errorf("unknown flag: %q", name)
+				return def, fmt.Errorf("unknown flag: %q", name)
 			}
 		}
 	}
-
-	next = c.Next()
-	res := rt.NewTerminationWith(c, 0, true)
-
-	ctx, err := t.CallContext(rt.RuntimeContextDef{
-		HardLimits:    hardLimits,
-		SoftLimits:    softLimits,
-		RequiredFlags: flags,
-	}, func() error {
-		return rt.Call(t, f, fArgs, res)
-	})
-	t.Push1(next, newContextValue(t.Runtime, ctx))
-	switch ctx.Status() {
-	case rt.StatusDone:
-		t.Push(next, res.Etc()...)
-	case rt.StatusError:
-		t.Push1(next, rt.ErrorValue(err))
-	}
-	return next, nil
+	return
 }
 
 func getResources(t *rt.Thread, resources rt.Value) (res rt.RuntimeResources, err error) {
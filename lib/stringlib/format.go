@@ -24,7 +24,7 @@ func format(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
 		return nil, err
 	}
 	t.RequireBytes(len(s))
-	return c.PushingNext1(t.Runtime, rt.StringValue(s)), nil
+	return c.PushingNext1(t.Runtime, t.InternString(s)), nil
 }
 
 var errNotEnoughValues = errors.New("not enough values for format string")
@@ -111,7 +111,7 @@ func pushCaptures(r *rt.Runtime, captures []pattern.Capture, s string, next rt.C
 	case 1:
 		c := captures[0]
 		r.RequireBytes(c.End() - c.Start())
-		r.Push1(next, rt.StringValue(s[c.Start():c.End()]))
+		r.Push1(next, r.InternSubstring(s[c.Start():c.End()]))
 	default:
 		pushExtraCaptures(r, captures, s, next)
 	}
@@ -131,7 +131,7 @@ func captureValue(r *rt.Runtime, c pattern.Capture, s string) rt.Value {
 		return rt.IntValue(int64(c.Start() + 1))
 	}
 	r.RequireBytes(c.End() - c.Start())
-	return rt.StringValue(s[c.Start():c.End()])
+	return r.InternSubstring(s[c.Start():c.End()])
 }
 
 func gmatch(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
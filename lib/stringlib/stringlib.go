@@ -124,7 +124,7 @@ func char(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
 		buf[i] = byte(x)
 	}
 	t.RequireBytes(len(buf))
-	return c.PushingNext1(t.Runtime, rt.StringValue(string(buf))), nil
+	return c.PushingNext1(t.Runtime, t.InternString(string(buf))), nil
 }
 
 func lenf(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
@@ -148,7 +148,7 @@ func lower(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
 	}
 	t.RequireBytes(len(s))
 	s = strings.ToLower(string(s))
-	return c.PushingNext1(t.Runtime, rt.StringValue(s)), nil
+	return c.PushingNext1(t.Runtime, t.InternString(s)), nil
 }
 
 func upper(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
@@ -161,7 +161,7 @@ func upper(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
 	}
 	t.RequireBytes(len(s))
 	s = strings.ToUpper(string(s))
-	return c.PushingNext1(t.Runtime, rt.StringValue(s)), nil
+	return c.PushingNext1(t.Runtime, t.InternString(s)), nil
 }
 
 func rep(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
@@ -269,5 +269,5 @@ func sub(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
 		t.RequireBytes(j - i + 1)
 		slice = s[i-1 : j]
 	}
-	return c.PushingNext1(t.Runtime, rt.StringValue(slice)), nil
+	return c.PushingNext1(t.Runtime, t.InternSubstring(slice)), nil
 }
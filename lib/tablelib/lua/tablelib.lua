@@ -287,3 +287,29 @@ do
     print(pcall(table.unpack, tt))
     --> ~false\t.* g
 end
+
+do
+    local t = table.create(10, 5)
+    print(type(t), #t, next(t))
+    --> =table	0	nil
+
+    for i = 1, 20 do t[i] = i end
+    t.x = 1
+    print(#t, t[10], t[20], t.x)
+    --> =20	10	20	1
+
+    print(#table.create(0), #table.create(3, 0))
+    --> =0	0
+
+    print(pcall(table.create))
+    --> ~^false\t.*value needed
+
+    print(pcall(table.create, "a"))
+    --> ~^false\t.*must be an integer
+
+    print(pcall(table.create, -1))
+    --> ~^false\t.*#1 out of range
+
+    print(pcall(table.create, 1, -1))
+    --> ~^false\t.*#2 out of range
+end
@@ -176,3 +176,17 @@ do
     print(runtime.callcontext({kill={cpu=200}}, table.unpack, mk("x", 200)))
     --> =killed
 end
+
+-- table.create
+do
+    -- table.create requires memory upfront
+    local ctx = runtime.callcontext({kill={memory=100000}}, table.create, 1000)
+    print(ctx, ctx.used.memory >= 16000)
+    --> =done	true
+
+    print(runtime.callcontext({kill={memory=100000}}, table.create, 10000))
+    --> =killed
+
+    print(runtime.callcontext({kill={memory=100000}}, table.create, 0, 10000))
+    --> =killed
+end
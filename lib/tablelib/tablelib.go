@@ -25,6 +25,7 @@ func load(r *rt.Runtime) (rt.Value, func()) {
 		rt.ComplyCpuSafe|rt.ComplyMemSafe|rt.ComplyTimeSafe|rt.ComplyIoSafe,
 
 		r.SetEnvGoFunc(pkg, "concat", concat, 4, false),
+		r.SetEnvGoFunc(pkg, "create", create, 2, false),
 		r.SetEnvGoFunc(pkg, "insert", insert, 3, false),
 		r.SetEnvGoFunc(pkg, "move", move, 5, false),
 		r.SetEnvGoFunc(pkg, "pack", pack, 0, true),
@@ -120,6 +121,31 @@ func errInvalidConcatValue(v rt.Value, i int64) error {
 	return fmt.Errorf("invalid value (%s) at index %d in table for 'concat'", s, i)
 }
 
+func create(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	narr, err := c.IntArg(0)
+	if err != nil {
+		return nil, err
+	}
+	var nhash int64
+	if c.NArgs() >= 2 {
+		nhash, err = c.IntArg(1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if narr < 0 || narr > math.MaxInt32 {
+		return nil, errors.New("#1 out of range")
+	}
+	if nhash < 0 || nhash > math.MaxInt32 {
+		return nil, errors.New("#2 out of range")
+	}
+	tbl := t.NewTableSize(int(narr), int(nhash))
+	return c.PushingNext1(t.Runtime, rt.TableValue(tbl)), nil
+}
+
 func insert(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
 	if err := c.CheckNArgs(2); err != nil {
 		return nil, err
@@ -0,0 +1,151 @@
+-- Spawning a worker and joining it
+do
+    local w = worker.spawn(function(x, y) return x + y, x * y end, 3, 4)
+    print(w)
+    --> ~worker: 0x[0-9a-f]+
+    print(w:join())
+    --> =done	7	12
+
+    -- Joining again returns the same results
+    print(w:join())
+    --> =done	7	12
+
+    print(w:status())
+    --> =done
+end
+
+-- Errors in workers
+do
+    local w = worker.spawn(function() error({code=42}) end)
+    local st, err = w:join()
+    print(st, err.code)
+    --> =error	42
+
+    w = worker.spawn(function() error("boom") end)
+    print(w:join())
+    --> ~error	.*boom
+end
+
+-- Only Lua functions can be spawned
+do
+    print(pcall(worker.spawn, print))
+    --> ~false	.*must be a Lua function
+
+    print(pcall(worker.spawn))
+    --> ~false	.*value needed
+end
+
+-- Values are deep-copied
+do
+    local t = {1, 2, {x="a"}}
+    t.self = t
+    local w = worker.spawn(function(t)
+        t[1] = 100
+        return t, t.self == t, #t, t[3].x
+    end, t)
+    local st, t2, same, len, x = w:join()
+    print(st, same, len, x)
+    --> =done	true	3	a
+    print(t[1], t2[1], t2 == t, t2.self == t2)
+    --> =1	100	false	true
+end
+
+-- Upvalues are copied too, and the global environment is the worker's
+do
+    local n = 10
+    local function add(x) return x + n end
+    local w = worker.spawn(function(f, y) return f(y), type(print) end, add, 5)
+    print(w:join())
+    --> =done	15	function
+
+    local function fact(n)
+        if n <= 1 then return 1 end
+        return n * fact(n - 1)
+    end
+    print(worker.spawn(fact, 5):join())
+    --> =done	120
+end
+
+-- Some values cannot be sent
+do
+    print(pcall(worker.spawn, function() end, print))
+    --> ~false	.*cannot send a Go function
+
+    print(pcall(worker.spawn, function() end, coroutine.create(print)))
+    --> ~false	.*cannot send a thread value
+
+    local w = worker.spawn(function() return print end)
+    print(w:join())
+    --> ~error	.*cannot send a Go function
+end
+
+-- Passing messages to and from workers
+do
+    local w = worker.spawn(function()
+        local sum = 0
+        while true do
+            local _, x = worker.receive()
+            if x == nil then break end
+            sum = sum + x
+            worker.send("partial", sum)
+        end
+        return sum
+    end)
+    for i = 1, 4 do
+        w:send(i)
+        print(w:receive())
+    end
+    --> =true	partial	1
+    --> =true	partial	3
+    --> =true	partial	6
+    --> =true	partial	10
+    w:send(nil)
+    print(w:join())
+    --> =done	10
+end
+
+-- Receiving with a timeout
+do
+    local w = worker.spawn(function() return worker.receive(0.01) end)
+    print(w:join())
+    --> =done	false
+
+    local m = worker.mailbox()
+    print(m:receive(0))
+    --> =false
+end
+
+-- Mailboxes can be shared between workers
+do
+    local results = worker.mailbox()
+    local workers = {}
+    for i = 1, 4 do
+        workers[i] = worker.spawn(function(i, m)
+            m:send(i * i)
+        end, i, results)
+    end
+    for i = 1, 4 do
+        workers[i]:join()
+    end
+    local total = 0
+    for i = 1, 4 do
+        local _, x = results:receive()
+        total = total + x
+    end
+    print(total, results:receive(0))
+    --> =30	false
+end
+
+-- worker.send and worker.receive only work in workers
+do
+    print(pcall(worker.send, 1))
+    --> ~false	.*not running in a worker
+
+    print(pcall(worker.receive))
+    --> ~false	.*not running in a worker
+end
+
+do
+    print(math.type(worker.ncpu()), worker.ncpu() > 0)
+    --> =integer	true
+end
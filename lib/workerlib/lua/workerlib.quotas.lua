@@ -0,0 +1,69 @@
+-- Workers can be given their own limits
+do
+    local w = worker.spawncontext({kill={cpu=1000}}, function()
+        while true do end
+    end)
+    print(w:join())
+    --> =killed
+
+    w = worker.spawncontext({kill={memory=10000}}, function()
+        return ("x"):rep(100000)
+    end)
+    print(w:join())
+    --> =killed
+
+    w = worker.spawncontext({kill={cpu=100000}}, function(n)
+        local s = 0
+        for i = 1, n do s = s + i end
+        return s
+    end, 100)
+    print(w:join())
+    --> =done	5050
+
+    print(pcall(worker.spawncontext, {kill={cpu="x"}}, function() end))
+    --> ~false	.*cpu must be an integer
+end
+
+-- The worker lib is not safe to use in a constrained context
+do
+    print(runtime.callcontext({flags="cpusafe"}, worker.spawn, function() end))
+    --> ~error	.*missing flags: cpusafe
+end
+
+-- Joining with a timeout kills the worker if it is not done in time
+do
+    local w = worker.spawn(function() while true do end end)
+    print(w:join(0.01))
+    --> =killed
+    print(w:status())
+    --> =killed
+
+    -- Also when it is waiting for a message
+    w = worker.spawn(function() return worker.receive() end)
+    print(w:join(0.01))
+    --> =killed
+
+    w = worker.spawn(function(x) return x end, 42)
+    print(w:join(5))
+    --> =done	42
+end
+
+-- Waiting is bounded by the time limit of the caller
+do
+    local w = worker.spawn(function() while true do end end)
+    print(runtime.callcontext({kill={millis=20}}, function() w:join() end))
+    --> =killed
+
+    -- The worker is still running
+    print(w:status())
+    --> =live
+    print(w:join(0))
+    --> =killed
+
+    print(runtime.callcontext({kill={millis=20}}, function() worker.mailbox():receive() end))
+    --> =killed
+
+    -- A timeout shorter than the time limit just expires
+    print(runtime.callcontext({kill={millis=1000}}, function() return worker.mailbox():receive(0.01) end))
+    --> =done	false
+end
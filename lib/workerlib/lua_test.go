@@ -0,0 +1,12 @@
+package workerlib_test
+
+import (
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	"github.com/arnodel/golua/luatesting"
+)
+
+func TestWorkerLib(t *testing.T) {
+	luatesting.RunLuaTestsInDir(t, "lua", lib.LoadAll)
+}
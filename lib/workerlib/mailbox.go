@@ -0,0 +1,63 @@
+package workerlib
+
+import (
+	"sync"
+)
+
+// A mailbox is an unbounded FIFO queue of messages which can be shared between
+// goroutines.  Sending to a mailbox never blocks.
+type mailbox struct {
+	mux    sync.Mutex
+	queue  []*message
+	notify chan struct{} // Has a pending item when the queue may be non-empty
+}
+
+func newMailbox() *mailbox {
+	return &mailbox{notify: make(chan struct{}, 1)}
+}
+
+// send adds msg at the end of the queue.
+func (m *mailbox) send(msg *message) {
+	m.mux.Lock()
+	m.queue = append(m.queue, msg)
+	m.mux.Unlock()
+	m.signal()
+}
+
+// receive removes the message at the front of the queue and returns it.  If
+// the queue is empty it waits for a message to be sent.  If wt gives up
+// waiting, it returns false.
+func (m *mailbox) receive(wt *waiter) (*message, bool) {
+	for {
+		if msg, ok := m.tryReceive(); ok {
+			return msg, true
+		}
+		if !wt.wait(m.notify) {
+			// A message may have arrived just before giving up.
+			return m.tryReceive()
+		}
+	}
+}
+
+func (m *mailbox) tryReceive() (*message, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if len(m.queue) == 0 {
+		return nil, false
+	}
+	msg := m.queue[0]
+	m.queue[0] = nil
+	m.queue = m.queue[1:]
+	if len(m.queue) > 0 {
+		// Wake up another receiver if there is one.
+		m.signal()
+	}
+	return msg, true
+}
+
+func (m *mailbox) signal() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,266 @@
+package workerlib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// A message is a deep copy of a list of Lua values which does not refer to any
+// Runtime.  It is immutable once built so it can be safely handed over to
+// another goroutine and unpacked into a different Runtime.
+//
+// Tables, Lua functions and upvalue cells are stored in separate slices and
+// referred to by index, so that shared references and cycles are preserved in
+// the copy.
+type message struct {
+	values []packedValue
+	tables []packedTable
+	funcs  []packedFunc
+	cells  []packedValue
+}
+
+type packedKind uint8
+
+const (
+	scalarKind    packedKind = iota // nil, booleans, numbers and strings
+	globalEnvKind                   // the global environment of the Runtime
+	tableKind                       // index in message.tables
+	funcKind                        // index in message.funcs
+	handleKind                      // a worker or a mailbox
+)
+
+type packedValue struct {
+	kind   packedKind
+	scalar rt.Value
+	index  int
+	handle interface{}
+}
+
+type packedTable struct {
+	keys, values []packedValue
+}
+
+type packedFunc struct {
+	code  []byte // marshalled with rt.MarshalConst
+	cells []int  // indices in message.cells
+}
+
+//
+// Packing values into a message
+//
+
+type packer struct {
+	t      *rt.Thread
+	msg    *message
+	tables map[*rt.Table]int
+	funcs  map[*rt.Closure]int
+	cells  map[rt.Cell]int
+	err    error
+}
+
+// packValues makes a message out of a list of values.  Values which cannot be
+// copied across runtimes (Go functions, threads, userdata other than workers
+// and mailboxes) cause an error.
+func packValues(t *rt.Thread, vals []rt.Value) (*message, error) {
+	p := packer{
+		t:      t,
+		msg:    new(message),
+		tables: map[*rt.Table]int{},
+		funcs:  map[*rt.Closure]int{},
+		cells:  map[rt.Cell]int{},
+	}
+	p.msg.values = make([]packedValue, len(vals))
+	for i, v := range vals {
+		p.msg.values[i] = p.pack(v)
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+	return p.msg, nil
+}
+
+func (p *packer) pack(v rt.Value) packedValue {
+	if p.err != nil {
+		return packedValue{}
+	}
+	p.t.RequireCPU(1)
+	switch v.Type() {
+	case rt.NilType, rt.BoolType, rt.IntType, rt.FloatType, rt.StringType:
+		return packedValue{scalar: v}
+	case rt.TableType:
+		return p.packTable(v.AsTable())
+	case rt.FunctionType:
+		cl, ok := v.TryClosure()
+		if !ok {
+			p.err = errors.New("cannot send a Go function")
+			return packedValue{}
+		}
+		return p.packClosure(cl)
+	case rt.UserDataType:
+		switch h := v.AsUserData().Value().(type) {
+		case *worker, *mailbox:
+			return packedValue{kind: handleKind, handle: h}
+		}
+	}
+	p.err = fmt.Errorf("cannot send a %s value", v.CustomTypeName())
+	return packedValue{}
+}
+
+func (p *packer) packTable(tbl *rt.Table) packedValue {
+	if tbl == p.t.GlobalEnv() {
+		return packedValue{kind: globalEnvKind}
+	}
+	if i, ok := p.tables[tbl]; ok {
+		return packedValue{kind: tableKind, index: i}
+	}
+	i := len(p.msg.tables)
+	p.tables[tbl] = i
+	p.msg.tables = append(p.msg.tables, packedTable{})
+	var keys, values []packedValue
+	for k, v, _ := tbl.Next(rt.NilValue); !k.IsNil(); k, v, _ = tbl.Next(k) {
+		keys = append(keys, p.pack(k))
+		values = append(values, p.pack(v))
+		if p.err != nil {
+			return packedValue{}
+		}
+	}
+	p.msg.tables[i] = packedTable{keys: keys, values: values}
+	return packedValue{kind: tableKind, index: i}
+}
+
+func (p *packer) packClosure(cl *rt.Closure) packedValue {
+	if i, ok := p.funcs[cl]; ok {
+		return packedValue{kind: funcKind, index: i}
+	}
+	var buf bytes.Buffer
+	code := p.t.RefactorCodeConsts(cl.Code)
+	used, err := rt.MarshalConst(&buf, rt.CodeValue(code), p.t.LinearUnused(10))
+	p.t.LinearRequire(10, used)
+	if err != nil {
+		p.err = err
+		return packedValue{}
+	}
+	i := len(p.msg.funcs)
+	p.funcs[cl] = i
+	p.msg.funcs = append(p.msg.funcs, packedFunc{})
+	cells := make([]int, len(cl.Upvalues))
+	for j, cell := range cl.Upvalues {
+		k, ok := p.cells[cell]
+		if !ok {
+			k = len(p.msg.cells)
+			p.cells[cell] = k
+			p.msg.cells = append(p.msg.cells, packedValue{})
+			p.msg.cells[k] = p.pack(cl.GetUpvalue(j))
+		}
+		cells[j] = k
+	}
+	p.msg.funcs[i] = packedFunc{code: buf.Bytes(), cells: cells}
+	return packedValue{kind: funcKind, index: i}
+}
+
+//
+// Unpacking a message into a Runtime
+//
+
+type unpacker struct {
+	t      *rt.Thread
+	msg    *message
+	tables []*rt.Table
+	funcs  []*rt.Closure
+	cells  []*rt.Cell
+	err    error
+}
+
+// unpackValues recreates the values contained in msg in the Runtime of t.
+func unpackValues(t *rt.Thread, msg *message) ([]rt.Value, error) {
+	u := unpacker{
+		t:      t,
+		msg:    msg,
+		tables: make([]*rt.Table, len(msg.tables)),
+		funcs:  make([]*rt.Closure, len(msg.funcs)),
+		cells:  make([]*rt.Cell, len(msg.cells)),
+	}
+	vals := make([]rt.Value, len(msg.values))
+	for i, v := range msg.values {
+		vals[i] = u.unpack(v)
+		if u.err != nil {
+			return nil, u.err
+		}
+	}
+	return vals, nil
+}
+
+func (u *unpacker) unpack(v packedValue) rt.Value {
+	if u.err != nil {
+		return rt.NilValue
+	}
+	u.t.RequireCPU(1)
+	switch v.kind {
+	case scalarKind:
+		return v.scalar
+	case globalEnvKind:
+		return rt.TableValue(u.t.GlobalEnv())
+	case tableKind:
+		return rt.TableValue(u.unpackTable(v.index))
+	case funcKind:
+		return rt.FunctionValue(u.unpackClosure(v.index))
+	case handleKind:
+		return newHandleValue(u.t.Runtime, v.handle)
+	default:
+		panic("invalid packed value")
+	}
+}
+
+func (u *unpacker) unpackTable(i int) *rt.Table {
+	if tbl := u.tables[i]; tbl != nil {
+		return tbl
+	}
+	tbl := rt.NewTable()
+	u.tables[i] = tbl
+	ptbl := u.msg.tables[i]
+	for j, k := range ptbl.keys {
+		u.t.SetTable(tbl, u.unpack(k), u.unpack(ptbl.values[j]))
+	}
+	return tbl
+}
+
+func (u *unpacker) unpackClosure(i int) *rt.Closure {
+	if cl := u.funcs[i]; cl != nil {
+		return cl
+	}
+	pf := u.msg.funcs[i]
+	k, used, err := rt.UnmarshalConst(bytes.NewReader(pf.code), u.t.LinearUnused(10))
+	u.t.LinearRequire(10, used)
+	if err != nil {
+		u.err = err
+		return nil
+	}
+	code, ok := k.TryCode()
+	if !ok {
+		u.err = errors.New("expected function code")
+		return nil
+	}
+	cl := rt.NewClosure(u.t.Runtime, code)
+	u.funcs[i] = cl
+
+	// Cells must all be added to the closure before their values are
+	// unpacked, as the closure may be reachable from its own upvalues.
+	var newCells []int
+	for j, k := range pf.cells {
+		cell := u.cells[k]
+		if cell == nil {
+			cell = new(rt.Cell)
+			*cell = rt.NewCell(rt.NilValue)
+			u.cells[k] = cell
+			newCells = append(newCells, j)
+		}
+		cl.AddUpvalue(*cell)
+	}
+	for _, j := range newCells {
+		cl.SetUpvalue(j, u.unpack(u.msg.cells[pf.cells[j]]))
+	}
+	return cl
+}
@@ -0,0 +1,76 @@
+package workerlib
+
+import (
+	"time"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// A waiter bounds the time a function of the worker lib can block the calling
+// thread for.  It gives up waiting when its timeout expires, when the time
+// limit of the runtime context of the caller is reached or when the caller is
+// a worker which is being killed.
+type waiter struct {
+	timer     *time.Timer
+	expired   <-chan time.Time // nil if there is no timeout
+	killed    <-chan struct{}  // nil if the caller is not a worker
+	timeLimit bool             // true if the timeout is the caller's time limit
+
+	timedOut, wasKilled bool // Why the last wait gave up
+}
+
+// newWaiter returns a waiter for the thread t with the given timeout (no
+// timeout if negative).  Call stop when done with it.
+func newWaiter(t *rt.Thread, timeout time.Duration) *waiter {
+	wt := &waiter{}
+	if limit := t.HardLimits().Millis; limit > 0 {
+		var left time.Duration
+		if used := t.UsedResources().Millis; used < limit {
+			left = time.Duration(limit-used) * time.Millisecond
+		}
+		if timeout < 0 || left < timeout {
+			timeout = left
+			wt.timeLimit = true
+		}
+	}
+	if timeout >= 0 {
+		wt.timer = time.NewTimer(timeout)
+		wt.expired = wt.timer.C
+	}
+	if self := getWorkerData(t.Runtime).self; self != nil {
+		wt.killed = self.killed
+	}
+	return wt
+}
+
+// wait waits until a value can be received from ready, and returns true.  It
+// returns false if it gives up waiting first.
+func (wt *waiter) wait(ready <-chan struct{}) bool {
+	select {
+	case <-ready:
+		return true
+	case <-wt.expired:
+		wt.timedOut = true
+	case <-wt.killed:
+		wt.wasKilled = true
+	}
+	return false
+}
+
+// abort terminates the runtime context of t if the last wait gave up because of
+// the time limit of the context or because the calling worker is being killed.
+// It returns normally if the timeout given to newWaiter expired.
+func (wt *waiter) abort(t *rt.Thread) {
+	switch {
+	case wt.wasKilled:
+		t.TerminateContext("force kill")
+	case wt.timedOut && wt.timeLimit:
+		t.TerminateContext("time limit of %d exceeded", t.HardLimits().Millis)
+	}
+}
+
+func (wt *waiter) stop() {
+	if wt.timer != nil {
+		wt.timer.Stop()
+	}
+}
@@ -0,0 +1,460 @@
+// Package workerlib implements the "worker" Lua library, which allows running
+// Lua functions in parallel, each in its own Runtime.
+//
+// A Runtime is single-threaded, so workers do not share any state.  Values
+// passed to or from a worker (arguments, return values and messages) are
+// deep-copied: tables are copied (preserving shared references and cycles but
+// not metatables), Lua functions are marshalled and their upvalues are copied.
+// The global environment is never copied, it is replaced with the global
+// environment of the receiving Runtime.  Workers and mailboxes themselves can
+// be sent by reference.
+//
+// Functions which wait (joining a worker or receiving a message) give up when
+// the time limit of the calling runtime context is reached.  A worker is killed
+// when it is joined with a timeout that expires, or when its handle is no longer
+// referenced.  Killing workers requires quotas (see rt.QuotasAvailable).
+package workerlib
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/arnodel/golua/lib/packagelib"
+	"github.com/arnodel/golua/lib/runtimelib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// LibLoader can load the worker lib.  Runtimes created for workers spawned
+// from it only have the worker lib loaded.  Use NewLibLoader to load more
+// libraries into worker runtimes.
+var LibLoader = NewLibLoader(nil)
+
+// NewLibLoader returns a loader for the worker lib which will call setup on the
+// runtime of each new worker before running it (e.g. to load libraries).  The
+// function returned by setup is called when the worker is done.  If setup is
+// nil, only the worker lib is loaded into worker runtimes.
+func NewLibLoader(setup func(*rt.Runtime) func()) packagelib.Loader {
+	l := packagelib.Loader{Name: "worker"}
+	if setup == nil {
+		setup = func(r *rt.Runtime) func() {
+			return l.Run(r)
+		}
+	}
+	l.Load = func(r *rt.Runtime) (rt.Value, func()) {
+		return load(r, setup)
+	}
+	return l
+}
+
+type workerKeyType struct{}
+
+var workerKey = rt.AsValue(workerKeyType{})
+
+// Data stored in the registry of a runtime where the worker lib is loaded.
+type workerData struct {
+	setup       func(*rt.Runtime) func()
+	self        *workerState // The worker running in this runtime, if any
+	workerMeta  *rt.Table
+	mailboxMeta *rt.Table
+}
+
+func getWorkerData(r *rt.Runtime) *workerData {
+	return r.Registry(workerKey).Interface().(*workerData)
+}
+
+func load(r *rt.Runtime, setup func(*rt.Runtime) func()) (rt.Value, func()) {
+	workerMethods := rt.NewTable()
+	workerMeta := rt.NewTable()
+	r.SetEnv(workerMeta, "__name", rt.StringValue("worker"))
+	r.SetEnv(workerMeta, "__index", rt.TableValue(workerMethods))
+
+	// Waiting is bounded by the time limit of the caller, so the functions
+	// are timesafe.  They are not cpusafe or memsafe as workers use their own
+	// resources.
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyTimeSafe,
+
+		r.SetEnvGoFunc(workerMethods, "join", join, 2, false),
+		r.SetEnvGoFunc(workerMethods, "send", workersend, 1, true),
+		r.SetEnvGoFunc(workerMethods, "receive", workerreceive, 2, false),
+		r.SetEnvGoFunc(workerMethods, "status", status, 1, false),
+	)
+
+	mailboxMethods := rt.NewTable()
+	mailboxMeta := rt.NewTable()
+	r.SetEnv(mailboxMeta, "__name", rt.StringValue("mailbox"))
+	r.SetEnv(mailboxMeta, "__index", rt.TableValue(mailboxMethods))
+
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyTimeSafe,
+
+		r.SetEnvGoFunc(mailboxMethods, "send", mailboxsend, 1, true),
+		r.SetEnvGoFunc(mailboxMethods, "receive", mailboxreceive, 2, false),
+	)
+
+	data := &workerData{
+		setup:       setup,
+		workerMeta:  workerMeta,
+		mailboxMeta: mailboxMeta,
+	}
+	if prev, ok := r.Registry(workerKey).Interface().(*workerData); ok {
+		// The lib is loaded into a worker runtime, which was registered
+		// before setup was called.
+		data.self = prev.self
+	}
+	r.SetRegistry(workerKey, rt.AsValue(data))
+
+	pkg := rt.NewTable()
+	rt.SolemnlyDeclareCompliance(
+		rt.ComplyTimeSafe,
+
+		r.SetEnvGoFunc(pkg, "spawn", spawn, 1, true),
+		r.SetEnvGoFunc(pkg, "spawncontext", spawncontext, 2, true),
+		r.SetEnvGoFunc(pkg, "mailbox", newmailbox, 0, false),
+		r.SetEnvGoFunc(pkg, "send", send, 0, true),
+		r.SetEnvGoFunc(pkg, "receive", receive, 1, false),
+		r.SetEnvGoFunc(pkg, "ncpu", ncpu, 0, false),
+	)
+
+	return rt.TableValue(pkg), nil
+}
+
+// A worker is a handle on a Lua function running in its own Runtime and
+// goroutine.  The goroutine only refers to the workerState, so that the worker
+// can be killed when the handle is garbage collected.
+type worker struct {
+	*workerState
+}
+
+type workerState struct {
+	inbox  *mailbox      // messages sent to the worker
+	outbox *mailbox      // messages sent by the worker
+	done   chan struct{} // closed when the worker has finished running
+
+	interrupt func()        // interrupts the worker's runtime (from any goroutine)
+	killOnce  sync.Once     // so that killed is only closed once
+	killed    chan struct{} // closed when the worker is killed
+
+	// These are only valid once done is closed.
+	status rt.RuntimeContextStatus
+	result *message
+}
+
+// kill stops the worker as soon as possible.  It can be called from any
+// goroutine.
+func (w *workerState) kill() {
+	w.killOnce.Do(func() {
+		close(w.killed)
+		w.interrupt()
+	})
+}
+
+func newHandleValue(r *rt.Runtime, h interface{}) rt.Value {
+	data := getWorkerData(r)
+	switch h.(type) {
+	case *worker:
+		return r.NewUserDataValue(h, data.workerMeta)
+	case *mailbox:
+		return r.NewUserDataValue(h, data.mailboxMeta)
+	default:
+		panic("invalid handle")
+	}
+}
+
+func spawn(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	return startWorker(t, c, rt.RuntimeContextDef{}, c.Arg(0), c.Etc())
+}
+
+func spawncontext(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.CheckNArgs(2); err != nil {
+		return nil, err
+	}
+	quotas, err := c.TableArg(0)
+	if err != nil {
+		return nil, err
+	}
+	def, err := runtimelib.GetContextDef(t, quotas)
+	if err != nil {
+		return nil, err
+	}
+	return startWorker(t, c, def, c.Arg(1), c.Etc())
+}
+
+func startWorker(t *rt.Thread, c *rt.GoCont, def rt.RuntimeContextDef, f rt.Value, args []rt.Value) (rt.Cont, error) {
+	if _, ok := f.TryClosure(); !ok {
+		return nil, errors.New("worker function must be a Lua function")
+	}
+	msg, err := packValues(t, append([]rt.Value{f}, args...))
+	if err != nil {
+		return nil, err
+	}
+	r := rt.New(t.Stdout, rt.WithInterrupts())
+	w := &worker{&workerState{
+		inbox:     newMailbox(),
+		outbox:    newMailbox(),
+		done:      make(chan struct{}),
+		interrupt: r.Interrupt,
+		killed:    make(chan struct{}),
+	}}
+	runtime.SetFinalizer(w, func(w *worker) { w.kill() })
+	go w.run(r, getWorkerData(t.Runtime).setup, def, msg)
+	return c.PushingNext1(t.Runtime, newHandleValue(t.Runtime, w)), nil
+}
+
+// run executes the function contained in msg in the runtime r, constrained by
+// def.
+func (w *workerState) run(r *rt.Runtime, setup func(*rt.Runtime) func(), def rt.RuntimeContextDef, msg *message) {
+	// If the worker is killed while its results are packed, the runtime
+	// panics and r.Close recovers.  It is then reported as killed.
+	status, result := rt.StatusKilled, new(message)
+	defer func() {
+		w.status, w.result = status, result
+		close(w.done)
+	}()
+	defer r.Close(nil)
+	r.SetRegistry(workerKey, rt.AsValue(&workerData{self: w}))
+	if cleanup := setup(r); cleanup != nil {
+		defer cleanup()
+	}
+	t := r.MainThread()
+	var res []rt.Value
+	ctx, err := t.CallContext(def, func() error {
+		vals, err := unpackValues(t, msg)
+		if err != nil {
+			return err
+		}
+		term := rt.NewTerminationWith(nil, 0, true)
+		if err := rt.Call(t, vals[0], vals[1:], term); err != nil {
+			return err
+		}
+		res = term.Etc()
+		return nil
+	})
+	st := contextStatus(ctx, err)
+	switch st {
+	case rt.StatusError:
+		res = []rt.Value{rt.ErrorValue(err)}
+	case rt.StatusKilled:
+		return
+	}
+	packed, err := packValues(t, res)
+	if err != nil {
+		st = rt.StatusError
+		packed, _ = packValues(t, []rt.Value{rt.StringValue(err.Error())})
+	}
+	status, result = st, packed
+}
+
+// When quotas are not available, CallContext returns a nil context so the
+// status needs to be worked out from the error.
+func contextStatus(ctx rt.RuntimeContext, err error) rt.RuntimeContextStatus {
+	if ctx != nil {
+		return ctx.Status()
+	}
+	switch err.(type) {
+	case nil:
+		return rt.StatusDone
+	case rt.ContextTerminationError:
+		return rt.StatusKilled
+	default:
+		return rt.StatusError
+	}
+}
+
+func workerArg(c *rt.GoCont, n int) (*worker, error) {
+	u, ok := c.Arg(n).TryUserData()
+	if ok {
+		if w, ok := u.Value().(*worker); ok {
+			return w, nil
+		}
+	}
+	return nil, fmt.Errorf("#%d must be a worker", n+1)
+}
+
+func mailboxArg(c *rt.GoCont, n int) (*mailbox, error) {
+	u, ok := c.Arg(n).TryUserData()
+	if ok {
+		if m, ok := u.Value().(*mailbox); ok {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("#%d must be a mailbox", n+1)
+}
+
+// timeoutArg returns the optional timeout (in seconds) in argument n, or a
+// negative duration if there is none.
+func timeoutArg(c *rt.GoCont, n int) (time.Duration, error) {
+	if c.NArgs() <= n || c.Arg(n).IsNil() {
+		return -1, nil
+	}
+	secs, err := c.FloatArg(n)
+	if err != nil {
+		return 0, err
+	}
+	if secs < 0 {
+		secs = 0
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+func join(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	w, err := workerArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := timeoutArg(c, 1)
+	if err != nil {
+		return nil, err
+	}
+	wt := newWaiter(t, timeout)
+	defer wt.stop()
+	if !wt.wait(w.done) {
+		wt.abort(t)
+		// The timeout expired, so the worker is killed.
+		w.kill()
+		if !rt.QuotasAvailable {
+			// The worker cannot be stopped, do not wait for it.
+			return c.PushingNext1(t.Runtime, rt.StringValue(rt.StatusKilled.String())), nil
+		}
+		<-w.done
+	}
+	res, err := unpackValues(t, w.result)
+	if err != nil {
+		return nil, err
+	}
+	next := c.Next()
+	t.Push1(next, rt.StringValue(w.status.String()))
+	t.Push(next, res...)
+	return next, nil
+}
+
+func status(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	w, err := workerArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	st := rt.StatusLive
+	select {
+	case <-w.done:
+		st = w.status
+	default:
+	}
+	return c.PushingNext1(t.Runtime, rt.StringValue(st.String())), nil
+}
+
+func workersend(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	w, err := workerArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sendTo(t, c, w.inbox, c.Etc())
+}
+
+func workerreceive(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	w, err := workerArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	return receiveFrom(t, c, w.outbox, 1)
+}
+
+func newmailbox(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return c.PushingNext1(t.Runtime, newHandleValue(t.Runtime, newMailbox())), nil
+}
+
+func mailboxsend(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	m, err := mailboxArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sendTo(t, c, m, c.Etc())
+}
+
+func mailboxreceive(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	m, err := mailboxArg(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	return receiveFrom(t, c, m, 1)
+}
+
+func send(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	self := getWorkerData(t.Runtime).self
+	if self == nil {
+		return nil, errNotInWorker
+	}
+	return sendTo(t, c, self.outbox, c.Etc())
+}
+
+func receive(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	self := getWorkerData(t.Runtime).self
+	if self == nil {
+		return nil, errNotInWorker
+	}
+	return receiveFrom(t, c, self.inbox, 0)
+}
+
+func ncpu(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	return c.PushingNext1(t.Runtime, rt.IntValue(int64(runtime.NumCPU()))), nil
+}
+
+var errNotInWorker = errors.New("not running in a worker")
+
+func sendTo(t *rt.Thread, c *rt.GoCont, m *mailbox, vals []rt.Value) (rt.Cont, error) {
+	msg, err := packValues(t, vals)
+	if err != nil {
+		return nil, err
+	}
+	m.send(msg)
+	return c.Next(), nil
+}
+
+// receiveFrom waits for a message in m, with an optional timeout in argument
+// n.  It pushes true followed by the message values, or false if the timeout
+// expired.
+func receiveFrom(t *rt.Thread, c *rt.GoCont, m *mailbox, n int) (rt.Cont, error) {
+	timeout, err := timeoutArg(c, n)
+	if err != nil {
+		return nil, err
+	}
+	wt := newWaiter(t, timeout)
+	defer wt.stop()
+	msg, ok := m.receive(wt)
+	if !ok {
+		wt.abort(t)
+	}
+	next := c.Next()
+	t.Push1(next, rt.BoolValue(ok))
+	if ok {
+		vals, err := unpackValues(t, msg)
+		if err != nil {
+			return nil, err
+		}
+		t.Push(next, vals...)
+	}
+	return next, nil
+}
@@ -0,0 +1,52 @@
+package workerlib
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/arnodel/golua/lib/packagelib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+func TestDroppedWorkerIsKilled(t *testing.T) {
+	if !rt.QuotasAvailable {
+		t.Skip("workers cannot be killed without quotas")
+	}
+	finished := make(chan struct{})
+	var loader = NewLibLoader(nil)
+	loader = NewLibLoader(func(r *rt.Runtime) func() {
+		packagelib.LibLoader.Run(r)
+		cleanup := loader.Run(r)
+		return func() {
+			if cleanup != nil {
+				cleanup()
+			}
+			close(finished)
+		}
+	})
+	r := rt.New(nil)
+	defer r.Close(nil)
+	packagelib.LibLoader.Run(r)
+	loader.Run(r)
+	clos, err := r.CompileAndLoadLuaChunk("test", []byte(`
+(function() worker.spawn(function() while true do end end) end)()
+`), rt.TableValue(r.GlobalEnv()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos)); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case <-finished:
+			return
+		case <-deadline:
+			t.Fatal("worker still running")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
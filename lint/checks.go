@@ -0,0 +1,225 @@
+package lint
+
+import (
+	"math"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ops"
+)
+
+// Globals defined by the standard library (see lib.LoadAll).
+var stdGlobals = []string{
+	"_G", "_VERSION", "assert", "collectgarbage", "dofile", "error",
+	"getmetatable", "ipairs", "load", "loadfile", "next", "pairs", "pcall",
+	"print", "rawequal", "rawget", "rawlen", "rawset", "require", "select",
+	"setmetatable", "tonumber", "tostring", "type", "warn", "xpcall",
+
+	"coroutine", "debug", "io", "math", "os", "package", "string", "table",
+	"utf8",
+
+	// Golua specific libraries
+	"event", "golib", "json", "re", "runtime", "worker",
+}
+
+// An arity is the number of arguments a function accepts.  A max of -1 means
+// the function is variadic.
+type arity struct {
+	min, max int
+}
+
+// Arities of known standard library functions.  Only arguments that the
+// functions require are taken into account, i.e. passing fewer than min
+// arguments is an error at runtime and passing more than max arguments is
+// useless.
+var stdArities = map[string]arity{
+	"assert":       {1, -1},
+	"error":        {0, 2},
+	"getmetatable": {1, 1},
+	"ipairs":       {1, 1},
+	"next":         {1, 2},
+	"pairs":        {1, 1},
+	"pcall":        {1, -1},
+	"rawequal":     {2, 2},
+	"rawget":       {2, 2},
+	"rawlen":       {1, 1},
+	"rawset":       {3, 3},
+	"select":       {1, -1},
+	"setmetatable": {2, 2},
+	"tonumber":     {1, 2},
+	"tostring":     {1, 1},
+	"type":         {1, 1},
+	"xpcall":       {2, -1},
+
+	"coroutine.create": {1, 1},
+	"coroutine.resume": {1, -1},
+	"coroutine.status": {1, 1},
+	"coroutine.wrap":   {1, 1},
+
+	"math.abs":       {1, 1},
+	"math.ceil":      {1, 1},
+	"math.cos":       {1, 1},
+	"math.exp":       {1, 1},
+	"math.floor":     {1, 1},
+	"math.fmod":      {2, 2},
+	"math.log":       {1, 2},
+	"math.max":       {1, -1},
+	"math.min":       {1, -1},
+	"math.random":    {0, 2},
+	"math.sin":       {1, 1},
+	"math.sqrt":      {1, 1},
+	"math.tan":       {1, 1},
+	"math.tointeger": {1, 1},
+	"math.type":      {1, 1},
+
+	"os.clock":  {0, 0},
+	"os.getenv": {1, 1},
+	"os.time":   {0, 1},
+
+	"string.byte":    {1, 3},
+	"string.find":    {2, 4},
+	"string.format":  {1, -1},
+	"string.gmatch":  {2, 3},
+	"string.gsub":    {3, 4},
+	"string.len":     {1, 1},
+	"string.lower":   {1, 1},
+	"string.match":   {2, 3},
+	"string.rep":     {2, 3},
+	"string.reverse": {1, 1},
+	"string.sub":     {2, 3},
+	"string.upper":   {1, 1},
+
+	"table.concat": {1, 4},
+	"table.create": {1, 2},
+	"table.insert": {2, 3},
+	"table.remove": {1, 2},
+	"table.sort":   {1, 2},
+	"table.unpack": {1, 3},
+}
+
+// checkArgCount reports calls to known standard library functions with the
+// wrong number of arguments.
+func (l *linter) checkArgCount(f ast.BFunctionCall) {
+	name := l.stdName(f.Target)
+	if name == "" {
+		return
+	}
+	ar, ok := stdArities[name]
+	if !ok {
+		return
+	}
+	n := len(f.Args)
+	multi := false
+	if n > 0 {
+		switch f.Args[n-1].(type) {
+		case ast.FunctionCall, ast.Etc:
+			// The last argument can expand to any number of values
+			multi = true
+			n--
+		}
+	}
+	pos := f.Target.Locate().StartPos()
+	switch {
+	case n < ar.min && !multi:
+		if ar.min == ar.max {
+			l.report(pos, "'%s' expects %d argument(s), got %d", name, ar.min, n)
+		} else {
+			l.report(pos, "'%s' expects at least %d argument(s), got %d", name, ar.min, n)
+		}
+	case ar.max >= 0 && n > ar.max:
+		if ar.min == ar.max {
+			l.report(pos, "'%s' expects %d argument(s), got %d", name, ar.max, n)
+		} else {
+			l.report(pos, "'%s' expects at most %d argument(s), got %d", name, ar.max, n)
+		}
+	}
+}
+
+// stdName returns the name of a standard library value the expression refers
+// to (e.g. "print" or "string.format"), or "" if it doesn't refer to one.
+func (l *linter) stdName(e ast.ExpNode) string {
+	switch x := e.(type) {
+	case ast.Name:
+		if l.resolve(x.Val) == nil && !l.defined[x.Val] {
+			return x.Val
+		}
+	case ast.IndexExp:
+		lib := l.stdName(x.Coll)
+		key, ok := x.Idx.(ast.String)
+		if lib != "" && ok {
+			return lib + "." + string(key.Val)
+		}
+	}
+	return ""
+}
+
+// checkNaNComparison reports comparisons with a constant NaN value (e.g. x ==
+// 0/0), which always have the same result.
+func (l *linter) checkNaNComparison(b ast.BinOp) {
+	if b.OpType != ops.OpEq.Type() {
+		return
+	}
+	left := b.Left
+	for _, r := range b.Right {
+		if l.isNaN(left) || l.isNaN(r.Operand) {
+			result := "false"
+			if r.Op == ops.OpNeq {
+				result = "true"
+			}
+			l.report(b.StartPos(), "comparison with NaN is always %s", result)
+		}
+		// Subsequent comparisons are with a boolean
+		left = nil
+	}
+}
+
+func (l *linter) isNaN(e ast.ExpNode) bool {
+	if e == nil {
+		return false
+	}
+	x, ok := l.constNumber(e)
+	return ok && math.IsNaN(x)
+}
+
+// constNumber returns the value of e if it is a constant numeric expression
+// (only arithmetic operations and math.huge are supported).
+func (l *linter) constNumber(e ast.ExpNode) (float64, bool) {
+	switch x := e.(type) {
+	case ast.Int:
+		return float64(int64(x.Val)), true
+	case ast.Float:
+		return x.Val, true
+	case *ast.UnOp:
+		if x.Op != ops.OpNeg {
+			return 0, false
+		}
+		v, ok := l.constNumber(x.Operand)
+		return -v, ok
+	case *ast.BinOp:
+		v, ok := l.constNumber(x.Left)
+		for _, r := range x.Right {
+			if !ok {
+				return 0, false
+			}
+			var w float64
+			w, ok = l.constNumber(r.Operand)
+			switch r.Op {
+			case ops.OpAdd:
+				v += w
+			case ops.OpSub:
+				v -= w
+			case ops.OpMul:
+				v *= w
+			case ops.OpDiv:
+				v /= w
+			default:
+				return 0, false
+			}
+		}
+		return v, ok
+	case ast.IndexExp:
+		if l.stdName(x) == "math.huge" {
+			return math.Inf(1), true
+		}
+	}
+	return 0, false
+}
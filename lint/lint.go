@@ -0,0 +1,485 @@
+// Package lint implements a static analyser for Lua code.  It walks the AST
+// produced by the parsing package and reports common mistakes such as
+// undefined globals, unused locals or unreachable code.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/parsing"
+	"github.com/arnodel/golua/scanner"
+	"github.com/arnodel/golua/token"
+)
+
+// A Diagnostic is a problem found in a Lua chunk.
+type Diagnostic struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String returns the diagnostic in the "file:line:col: message" format
+// understood by most editors.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+}
+
+// Config configures the linter.
+type Config struct {
+	// Globals that are defined in addition to the standard library ones.
+	Globals []string
+}
+
+// LintSource parses the Lua source and lints it.  If the source cannot be
+// parsed, the syntax errors are returned as diagnostics instead.
+func LintSource(chunkName string, src []byte, config Config) []Diagnostic {
+	stat, errs := parsing.ParseChunkWithRecovery(scanner.New(chunkName, src))
+	if len(errs) > 0 {
+		diags := make([]Diagnostic, len(errs))
+		for i, err := range errs {
+			diags[i] = syntaxDiagnostic(chunkName, err)
+		}
+		return diags
+	}
+	return Lint(chunkName, stat, config)
+}
+
+// syntaxDiagnostic returns the diagnostic for a syntax error.
+func syntaxDiagnostic(chunkName string, err parsing.Error) Diagnostic {
+	line, col := err.Got.Line, err.Got.Column
+	return Diagnostic{
+		File:    chunkName,
+		Line:    line,
+		Column:  col,
+		Message: strings.TrimPrefix(err.Error(), fmt.Sprintf("%d:%d: ", line, col)),
+	}
+}
+
+// Lint returns the diagnostics for a chunk, sorted by position.
+func Lint(chunkName string, chunk ast.BlockStat, config Config) []Diagnostic {
+	l := &linter{
+		chunkName: chunkName,
+		globals:   map[string]bool{},
+		defined:   map[string]bool{},
+	}
+	for _, name := range stdGlobals {
+		l.globals[name] = true
+	}
+	for _, name := range config.Globals {
+		l.globals[name] = true
+	}
+
+	l.block(chunk)
+
+	for _, g := range l.undefined {
+		if !l.defined[g.name] {
+			l.report(g.pos, "undefined global '%s'", g.name)
+		}
+	}
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		di, dj := l.diags[i], l.diags[j]
+		if di.Line != dj.Line {
+			return di.Line < dj.Line
+		}
+		return di.Column < dj.Column
+	})
+	return l.diags
+}
+
+type varKind uint8
+
+const (
+	localVar varKind = iota
+	localFunction
+	parameter
+	loopVar
+)
+
+var unusedMessages = [...]string{
+	localVar:      "unused local variable '%s'",
+	localFunction: "unused local function '%s'",
+	parameter:     "unused parameter '%s'",
+	loopVar:       "unused loop variable '%s'",
+}
+
+// A variable is a local name declared in the code being linted.
+type variable struct {
+	name   string
+	pos    *token.Pos
+	kind   varKind
+	attrib ast.LocalAttrib
+	used   bool
+}
+
+type scope struct {
+	vars   []*variable
+	parent *scope
+}
+
+type globalAccess struct {
+	name string
+	pos  *token.Pos
+}
+
+type linter struct {
+	chunkName string
+	globals   map[string]bool // Known globals (standard + configured)
+	defined   map[string]bool // Globals assigned to in the main chunk
+	undefined []globalAccess  // Accesses to globals not in l.globals
+	scope     *scope
+	funcDepth int
+	diags     []Diagnostic
+}
+
+var _ ast.StatProcessor = (*linter)(nil)
+var _ ast.ExpProcessor = (*linter)(nil)
+
+func (l *linter) report(pos *token.Pos, format string, args ...interface{}) {
+	if pos == nil {
+		return
+	}
+	l.diags = append(l.diags, Diagnostic{
+		File:    l.chunkName,
+		Line:    pos.Line,
+		Column:  pos.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+//
+// Scopes
+//
+
+func (l *linter) pushScope() {
+	l.scope = &scope{parent: l.scope}
+}
+
+func (l *linter) popScope() {
+	for _, v := range l.scope.vars {
+		if !v.used && !ignored(v.name) {
+			l.report(v.pos, unusedMessages[v.kind], v.name)
+		}
+	}
+	l.scope = l.scope.parent
+}
+
+func (l *linter) declare(name ast.Name, kind varKind, attrib ast.LocalAttrib) {
+	pos := name.StartPos()
+	if prev := l.resolve(name.Val); prev != nil && prev.pos != nil && !ignored(name.Val) {
+		l.report(pos, "'%s' shadows the declaration on line %d", name.Val, prev.pos.Line)
+	}
+	l.scope.vars = append(l.scope.vars, &variable{
+		name:   name.Val,
+		pos:    pos,
+		kind:   kind,
+		attrib: attrib,
+	})
+}
+
+func (l *linter) resolve(name string) *variable {
+	for s := l.scope; s != nil; s = s.parent {
+		for i := len(s.vars) - 1; i >= 0; i-- {
+			if v := s.vars[i]; v.name == name {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// Names starting with "_" are conventionally unused.
+func ignored(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+//
+// Helpers
+//
+
+func (l *linter) exp(e ast.ExpNode) {
+	e.ProcessExp(l)
+}
+
+func (l *linter) exps(es []ast.ExpNode) {
+	for _, e := range es {
+		l.exp(e)
+	}
+}
+
+// block lints a block in a new scope.
+func (l *linter) block(b ast.BlockStat) {
+	l.pushScope()
+	l.blockStats(b)
+	l.popScope()
+}
+
+// blockStats lints the statements of a block in the current scope, reporting
+// the first unreachable statement following a jump.
+func (l *linter) blockStats(b ast.BlockStat) {
+	jumped, reported := false, false
+	for _, s := range b.Stats {
+		if _, ok := s.(ast.LabelStat); ok {
+			jumped, reported = false, false
+		} else if jumped && !reported {
+			if pos := s.Locate().StartPos(); pos != nil {
+				l.report(pos, "unreachable code")
+				reported = true
+			}
+		}
+		s.ProcessStat(l)
+		if isJump(s) {
+			jumped = true
+		}
+	}
+	if jumped && !reported && len(b.Return) > 0 {
+		l.report(b.Return[0].Locate().StartPos(), "unreachable code")
+	}
+	l.exps(b.Return)
+}
+
+// isJump returns true if control never flows past the statement.
+func isJump(s ast.Stat) bool {
+	switch x := s.(type) {
+	case ast.GotoStat, ast.BreakStat, ast.ContinueStat:
+		return true
+	case ast.BlockStat:
+		return x.Return != nil
+	default:
+		return false
+	}
+}
+
+func (l *linter) function(f ast.Function) {
+	l.funcDepth++
+	l.pushScope()
+	for _, p := range f.Params {
+		l.declare(p, parameter, ast.NoAttrib)
+	}
+	l.blockStats(f.Body)
+	l.popScope()
+	l.funcDepth--
+}
+
+//
+// Statements
+//
+
+// ProcessAssignStat lints an AssignStat.
+func (l *linter) ProcessAssignStat(s ast.AssignStat) {
+	l.exps(s.Src)
+	for _, v := range s.Dest {
+		switch x := v.(type) {
+		case ast.Name:
+			l.assignName(x)
+		case ast.IndexExp:
+			l.exp(x.Coll)
+			l.exp(x.Idx)
+		default:
+			l.exp(v)
+		}
+	}
+}
+
+func (l *linter) assignName(n ast.Name) {
+	v := l.resolve(n.Val)
+	switch {
+	case v == nil:
+		if l.funcDepth == 0 {
+			l.defined[n.Val] = true
+		} else if !l.globals[n.Val] {
+			l.undefined = append(l.undefined, globalAccess{n.Val, n.StartPos()})
+		}
+	case v.attrib == ast.ConstAttrib:
+		l.report(n.StartPos(), "assignment to const variable '%s'", n.Val)
+	case v.attrib == ast.CloseAttrib:
+		l.report(n.StartPos(), "assignment to close variable '%s'", n.Val)
+	}
+}
+
+// ProcessCompoundAssignStat lints a CompoundAssignStat.
+func (l *linter) ProcessCompoundAssignStat(s ast.CompoundAssignStat) {
+	l.exp(s.Value)
+	l.exp(s.Dest)
+	if n, ok := s.Dest.(ast.Name); ok {
+		l.assignName(n)
+	}
+}
+
+// ProcessContinueStat lints a ContinueStat.
+func (l *linter) ProcessContinueStat(s ast.ContinueStat) {}
+
+// ProcessBlockStat lints a BlockStat.
+func (l *linter) ProcessBlockStat(s ast.BlockStat) {
+	l.block(s)
+}
+
+// ProcessBreakStat lints a BreakStat.
+func (l *linter) ProcessBreakStat(s ast.BreakStat) {}
+
+// ProcessEmptyStat lints an EmptyStat.
+func (l *linter) ProcessEmptyStat(s ast.EmptyStat) {}
+
+// ProcessForInStat lints a ForInStat.
+func (l *linter) ProcessForInStat(s ast.ForInStat) {
+	l.exps(s.Params)
+	l.pushScope()
+	for _, v := range s.Vars {
+		l.declare(v, loopVar, ast.NoAttrib)
+	}
+	l.blockStats(s.Body)
+	l.popScope()
+}
+
+// ProcessForStat lints a ForStat.
+func (l *linter) ProcessForStat(s ast.ForStat) {
+	l.exp(s.Start)
+	l.exp(s.Stop)
+	l.exp(s.Step)
+	l.pushScope()
+	l.declare(s.Var, loopVar, ast.NoAttrib)
+	l.blockStats(s.Body)
+	l.popScope()
+}
+
+// ProcessFunctionCallStat lints a FunctionCall statement.
+func (l *linter) ProcessFunctionCallStat(f ast.FunctionCall) {
+	l.ProcessBFunctionCallExp(*f.BFunctionCall)
+}
+
+// ProcessGotoStat lints a GotoStat.
+func (l *linter) ProcessGotoStat(s ast.GotoStat) {}
+
+// ProcessIfStat lints an IfStat.
+func (l *linter) ProcessIfStat(s ast.IfStat) {
+	l.exp(s.If.Cond)
+	l.block(s.If.Body)
+	for _, c := range s.ElseIfs {
+		l.exp(c.Cond)
+		l.block(c.Body)
+	}
+	if s.Else != nil {
+		l.block(*s.Else)
+	}
+}
+
+// ProcessLabelStat lints a LabelStat.
+func (l *linter) ProcessLabelStat(s ast.LabelStat) {}
+
+// ProcessLocalFunctionStat lints a LocalFunctionStat.
+func (l *linter) ProcessLocalFunctionStat(s ast.LocalFunctionStat) {
+	// The function name is in scope in its body
+	l.declare(s.Name, localFunction, ast.NoAttrib)
+	l.function(s.Function)
+}
+
+// ProcessLocalStat lints a LocalStat.
+func (l *linter) ProcessLocalStat(s ast.LocalStat) {
+	l.exps(s.Values)
+	for _, na := range s.NameAttribs {
+		l.declare(na.Name, localVar, na.Attrib)
+	}
+}
+
+// ProcessRepeatStat lints a RepeatStat.
+func (l *linter) ProcessRepeatStat(s ast.RepeatStat) {
+	// The condition is in the scope of the body
+	l.pushScope()
+	l.blockStats(s.Body)
+	l.exp(s.Cond)
+	l.popScope()
+}
+
+// ProcessWhileStat lints a WhileStat.
+func (l *linter) ProcessWhileStat(s ast.WhileStat) {
+	l.exp(s.Cond)
+	l.block(s.Body)
+}
+
+//
+// Expressions
+//
+
+// ProcessBFunctionCallExp lints a BFunctionCall.
+func (l *linter) ProcessBFunctionCallExp(f ast.BFunctionCall) {
+	l.exp(f.Target)
+	l.exps(f.Args)
+	if f.Method.Val == "" {
+		l.checkArgCount(f)
+	}
+}
+
+// ProcessBinOpExp lints a BinOp.
+func (l *linter) ProcessBinOpExp(b ast.BinOp) {
+	l.exp(b.Left)
+	for _, r := range b.Right {
+		l.exp(r.Operand)
+	}
+	l.checkNaNComparison(b)
+}
+
+// ProcesBoolExp lints a Bool.
+func (l *linter) ProcesBoolExp(b ast.Bool) {}
+
+// ProcessEtcExp lints an Etc.
+func (l *linter) ProcessEtcExp(e ast.Etc) {}
+
+// ProcessFunctionExp lints a Function.
+func (l *linter) ProcessFunctionExp(f ast.Function) {
+	l.function(f)
+}
+
+// ProcessFunctionCallExp lints a FunctionCall.
+func (l *linter) ProcessFunctionCallExp(f ast.FunctionCall) {
+	l.ProcessBFunctionCallExp(*f.BFunctionCall)
+}
+
+// ProcessIndexExp lints an IndexExp.
+func (l *linter) ProcessIndexExp(e ast.IndexExp) {
+	l.exp(e.Coll)
+	l.exp(e.Idx)
+}
+
+// ProcessNameExp lints a Name.
+func (l *linter) ProcessNameExp(n ast.Name) {
+	if v := l.resolve(n.Val); v != nil {
+		v.used = true
+	} else if !l.globals[n.Val] {
+		l.undefined = append(l.undefined, globalAccess{n.Val, n.StartPos()})
+	}
+}
+
+// ProcessNilExp lints a Nil.
+func (l *linter) ProcessNilExp(n ast.Nil) {}
+
+// ProcessIntExp lints an Int.
+func (l *linter) ProcessIntExp(n ast.Int) {}
+
+// ProcessFloatExp lints a Float.
+func (l *linter) ProcessFloatExp(f ast.Float) {}
+
+// ProcessStringExp lints a String.
+func (l *linter) ProcessStringExp(s ast.String) {}
+
+// ProcessInterpolatedStringExp lints an InterpolatedString.
+func (l *linter) ProcessInterpolatedStringExp(s ast.InterpolatedString) {
+	l.exps(s.Exps)
+}
+
+// ProcessTableConstructorExp lints a TableConstructor.
+func (l *linter) ProcessTableConstructorExp(t ast.TableConstructor) {
+	for _, f := range t.Fields {
+		if _, noKey := f.Key.(ast.NoTableKey); !noKey {
+			l.exp(f.Key)
+		}
+		l.exp(f.Value)
+	}
+}
+
+// ProcessUnOpExp lints an UnOp.
+func (l *linter) ProcessUnOpExp(u ast.UnOp) {
+	l.exp(u.Operand)
+}
@@ -0,0 +1,153 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLintSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		globals []string
+		want    []string
+	}{
+		{
+			name: "clean",
+			src: `
+local t = {}
+function t.f(x, ...) return x, ... end
+for i = 1, 10 do t[i] = i end
+return t`,
+		},
+		{
+			name: "parse error",
+			src:  "local x = ",
+			want: []string{"1:11: unexpected symbol near <eof>"},
+		},
+		{
+			name: "several parse errors",
+			src: `
+local x = = 1
+print(x)
+if x then y = ) end`,
+			want: []string{
+				"2:11: unexpected symbol near '='",
+				"4:15: unexpected symbol near ')'",
+			},
+		},
+		{
+			name: "undefined globals",
+			src: `
+foo = 1
+print(foo, bar)
+local function f() baz = 2 end
+f()`,
+			want: []string{
+				"3:12: undefined global 'bar'",
+				"4:20: undefined global 'baz'",
+			},
+		},
+		{
+			name:    "configured globals",
+			src:     "print(bar)",
+			globals: []string{"bar"},
+		},
+		{
+			name: "unused",
+			src: `
+local a, _b = 1, 2
+local function f(x, y, _) return y end
+for k, v in pairs(f) do print(v) end
+local function g() end`,
+			want: []string{
+				"2:7: unused local variable 'a'",
+				"3:18: unused parameter 'x'",
+				"4:5: unused loop variable 'k'",
+				"5:16: unused local function 'g'",
+			},
+		},
+		{
+			name: "shadowing",
+			src: `
+local x = 1
+do local x = x + 1; print(x) end
+local function f(x) return x end
+print(f)`,
+			want: []string{
+				"3:10: 'x' shadows the declaration on line 2",
+				"4:18: 'x' shadows the declaration on line 2",
+			},
+		},
+		{
+			name: "unreachable",
+			src: `
+for i = 1, 10 do
+  if i > 2 then
+    break
+    print(i)
+  end
+  goto continue
+  print(i)
+  ::continue::
+end
+do return end
+print("end")`,
+			want: []string{
+				"5:5: unreachable code",
+				"8:3: unreachable code",
+				"12:1: unreachable code",
+			},
+		},
+		{
+			name: "const",
+			src: `
+local x <const> = 1
+local f <close> = nil
+x, f = 2, 3
+print(x, f)`,
+			want: []string{
+				"4:1: assignment to const variable 'x'",
+				"4:4: assignment to close variable 'f'",
+			},
+		},
+		{
+			name: "arg count",
+			src: `
+print(type(), type(1, 2))
+print(string.sub("x"), string.format(...), math.max(...))
+local string = {sub = print}
+string.sub()
+print(("x"):rep(2))`,
+			want: []string{
+				"2:7: 'type' expects 1 argument(s), got 0",
+				"2:15: 'type' expects 1 argument(s), got 2",
+				"3:7: 'string.sub' expects at least 2 argument(s), got 1",
+			},
+		},
+		{
+			name: "nan",
+			src: `
+local x = ...
+print(x == 0/0, x ~= -(0/0), 0.0/0 < x, x == math.huge - math.huge, x ~= x)`,
+			want: []string{
+				"3:9: comparison with NaN is always false",
+				"3:19: comparison with NaN is always true",
+				"3:36: comparison with NaN is always false",
+				"3:43: comparison with NaN is always false",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, d := range LintSource("test", []byte(test.src), Config{Globals: test.globals}) {
+				got = append(got, strings.TrimPrefix(d.String(), "test:"))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
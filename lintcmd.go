@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/arnodel/golua/lint"
+)
+
+func init() {
+	subcommands["lint"] = lintMain
+}
+
+// lintMain implements "golua lint [-globals names] files...".  It prints
+// diagnostics in the file:line:col: message format and returns 1 if there
+// were any.
+func lintMain(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	globals := fs.String("globals", "", "comma separated list of additional globals")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: golua lint [options] file...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var config lint.Config
+	if *globals != "" {
+		config.Globals = strings.Split(*globals, ",")
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	found := false
+	for _, fname := range fs.Args() {
+		src, err := ioutil.ReadFile(fname)
+		if err != nil {
+			return fatal("Error reading '%s': %s", fname, err)
+		}
+		for _, d := range lint.LintSource(fname, src, config) {
+			fmt.Println(d)
+			found = true
+		}
+	}
+	if found {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,954 @@
+// Package luafmt formats Lua source code.  It prints an AST back to canonical
+// Lua source, interleaving the comments of the original source.
+//
+// The output is a valid Lua chunk with the same semantics as the original one,
+// and formatting it again gives the same output.
+package luafmt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ops"
+	"github.com/arnodel/golua/parsing"
+	"github.com/arnodel/golua/scanner"
+	"github.com/arnodel/golua/token"
+)
+
+// Indentation of nested blocks
+const indent = "    "
+
+// Source parses the Lua source and returns it formatted.
+func Source(name string, src []byte) ([]byte, error) {
+	chunk, err := parsing.ParseChunk(scanner.New(name, src, scanner.WithComments()))
+	if err != nil {
+		return nil, err
+	}
+	p := &printer{src: src, comments: chunk.Comments, lineStart: true}
+	p.print(chunk)
+	return p.buf.Bytes(), nil
+}
+
+// Fprint writes the chunk to w as Lua source.  The comments in chunk.Comments
+// are printed according to their position relative to the statements.  As the
+// source is not known, number literals are printed in a canonical form.
+func Fprint(w io.Writer, chunk ast.BlockStat) error {
+	p := &printer{comments: chunk.Comments, lineStart: true}
+	p.print(chunk)
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+type printer struct {
+	buf       bytes.Buffer
+	src       []byte          // Source of the chunk if known, used to print number literals as written
+	depth     int             // Indentation depth
+	inline    int             // When > 0, everything is printed on one line
+	comments  []token.Comment // Comments not printed yet
+	lastLine  int             // Source line of the last thing printed (0 at the start of a block)
+	maxLine   int             // Last source line of the long strings in the current statement
+	lineStart bool            // True when nothing has been written on the current line
+}
+
+var _ ast.StatProcessor = (*printer)(nil)
+var _ ast.ExpProcessor = (*printer)(nil)
+
+func (p *printer) print(chunk ast.BlockStat) {
+	p.stats(chunk, false)
+	p.flushComments(nil)
+}
+
+//
+// Output
+//
+
+func (p *printer) write(s string) {
+	if p.lineStart {
+		for i := 0; i < p.depth; i++ {
+			p.buf.WriteString(indent)
+		}
+		p.lineStart = false
+	}
+	p.buf.WriteString(s)
+}
+
+func (p *printer) newline() {
+	if p.inline > 0 {
+		p.write(" ")
+		return
+	}
+	p.buf.WriteByte('\n')
+	p.lineStart = true
+}
+
+// blankLine outputs an empty line if the source line is not adjacent to the
+// last thing printed.
+func (p *printer) blankLine(line int) {
+	if p.inline == 0 && p.lineStart && p.lastLine > 0 && line > p.lastLine+1 {
+		p.buf.WriteByte('\n')
+	}
+}
+
+// flushComments prints all the comments before pos (or all the remaining
+// comments if pos is nil), each on its own line.
+func (p *printer) flushComments(pos *token.Pos) {
+	if p.inline > 0 {
+		return
+	}
+	for len(p.comments) > 0 && (pos == nil || p.comments[0].Offset < pos.Offset) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		if !p.lineStart {
+			p.newline()
+		}
+		p.blankLine(c.Line)
+		p.write(string(c.Lit))
+		p.newline()
+		p.lastLine = commentEndLine(c)
+	}
+}
+
+// endStat ends the line of a statement, printing the comments that follow it
+// on the same source line unless the next item (if not nil) starts on that line
+// too, in which case the comments belong to the next item.
+func (p *printer) endStat(end, next *token.Pos) {
+	if end != nil && p.inline == 0 {
+		// The end position is the start of the last token, which could be a
+		// multiline string.
+		line := end.Line
+		if p.maxLine > line {
+			line = p.maxLine
+		}
+		p.lastLine = line
+		for len(p.comments) > 0 && p.comments[0].Line == line && (next == nil || next.Line != line) {
+			c := p.comments[0]
+			p.comments = p.comments[1:]
+			p.write(" ")
+			p.write(string(c.Lit))
+			p.lastLine = commentEndLine(c)
+		}
+	}
+	p.maxLine = 0
+	p.newline()
+}
+
+func commentEndLine(c token.Comment) int {
+	return c.Line + bytes.Count(c.Lit, []byte{'\n'})
+}
+
+// canInline returns true if the node was on a single line in the source, with
+// no comment in it.
+func (p *printer) canInline(n ast.Locator) bool {
+	if p.inline > 0 {
+		return true
+	}
+	start, end := n.Locate().StartPos(), n.Locate().EndPos()
+	if start == nil || end == nil || start.Line != end.Line {
+		return false
+	}
+	for _, c := range p.comments {
+		if c.Offset > end.Offset {
+			break
+		}
+		if c.Offset >= start.Offset {
+			return false
+		}
+	}
+	return true
+}
+
+//
+// Blocks
+//
+
+// block prints the statements of a block indented on new lines.  Comments
+// before end (if not nil) are printed in the block.
+func (p *printer) block(b ast.BlockStat, end *token.Pos, isFunc bool) {
+	p.depth++
+	p.newline()
+	p.lastLine = 0
+	p.stats(b, isFunc)
+	if end != nil {
+		p.flushComments(end)
+	}
+	p.depth--
+}
+
+// stats prints the statements of a block, each followed by a newline.
+func (p *printer) stats(b ast.BlockStat, isFunc bool) {
+	// Start of the statement after stats[i], ignoring empty statements.
+	next := func(i int) *token.Pos {
+		for _, s := range b.Stats[i+1:] {
+			if _, ok := s.(ast.EmptyStat); !ok {
+				return s.Locate().StartPos()
+			}
+		}
+		if len(b.Return) > 0 {
+			return b.Return[0].Locate().StartPos()
+		}
+		return nil
+	}
+	for i, s := range b.Stats {
+		if _, ok := s.(ast.EmptyStat); ok {
+			continue
+		}
+		if start := s.Locate().StartPos(); start != nil {
+			p.flushComments(start)
+			p.blankLine(start.Line)
+		}
+		if startsWithBracket(s) {
+			// Prevent the statement from being parsed as a continuation of
+			// the previous one.
+			p.write(";")
+		}
+		s.ProcessStat(p)
+		p.endStat(s.Locate().EndPos(), next(i))
+	}
+	// A bare return at the end of a function is implicit.
+	if b.Return == nil || isFunc && len(b.Return) == 0 {
+		return
+	}
+	var end *token.Pos
+	if n := len(b.Return); n > 0 {
+		if start := b.Return[0].Locate().StartPos(); start != nil {
+			p.flushComments(start)
+			p.blankLine(start.Line)
+		}
+		end = b.Return[n-1].Locate().EndPos()
+	}
+	p.write("return")
+	if len(b.Return) > 0 {
+		p.write(" ")
+		p.expList(b.Return)
+	}
+	p.endStat(end, nil)
+}
+
+// startsWithBracket returns true if the statement would be printed starting
+// with a "(".
+func startsWithBracket(s ast.Stat) bool {
+	var e ast.ExpNode
+	switch x := s.(type) {
+	case ast.FunctionCall:
+		e = x
+	case ast.AssignStat:
+		if _, ok := functionStat(x); ok {
+			return false
+		}
+		e = x.Dest[0]
+	default:
+		return false
+	}
+	for {
+		switch x := e.(type) {
+		case ast.Name:
+			return false
+		case ast.IndexExp:
+			e = x.Coll
+		case ast.FunctionCall:
+			e = x.Target
+		default:
+			return true
+		}
+	}
+}
+
+func (p *printer) funcBody(f ast.Function, params []ast.Name) {
+	p.write("(")
+	for i, param := range params {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.write(param.Val)
+	}
+	if f.HasDots {
+		if len(params) > 0 {
+			p.write(", ")
+		}
+		p.write("...")
+	}
+	p.write(")")
+	inline := p.canInline(f)
+	if inline {
+		p.inline++
+	}
+	p.block(f.Body, f.EndPos(), true)
+	p.write("end")
+	if inline {
+		p.inline--
+	}
+}
+
+// condBlock prints "<cond> <kw>" followed by the body block, on one line if it
+// was so in the source.
+func (p *printer) condBlock(cond ast.ExpNode, kw string, body ast.BlockStat, end *token.Pos) {
+	p.exp(cond)
+	p.write(" " + kw)
+	p.block(body, end, false)
+}
+
+// functionStat returns the function if the assignment can be printed as a
+// function statement ("function a.b() ... end").
+func functionStat(s ast.AssignStat) (ast.Function, bool) {
+	if len(s.Dest) != 1 || len(s.Src) != 1 {
+		return ast.Function{}, false
+	}
+	f, ok := s.Src[0].(ast.Function)
+	return f, ok && isFuncName(s.Dest[0])
+}
+
+func isFuncName(v ast.ExpNode) bool {
+	switch x := v.(type) {
+	case ast.Name:
+		return true
+	case ast.IndexExp:
+		s, ok := x.Idx.(ast.String)
+		return ok && isName(s.Val) && isFuncName(x.Coll)
+	default:
+		return false
+	}
+}
+
+// isDefaultStep returns true if the step of a numeric for loop was omitted in
+// the source.
+func isDefaultStep(step ast.ExpNode) bool {
+	n, ok := step.(ast.Int)
+	return ok && n.Val == 1 && n.StartPos() == nil
+}
+
+// isMethod returns true if the function has an implicit "self" parameter.
+func isMethod(f ast.Function) bool {
+	return len(f.Params) > 0 && f.Params[0].Val == "self" && f.Params[0].StartPos() == nil
+}
+
+//
+// Statements
+//
+
+// ProcessAssignStat prints an AssignStat.
+func (p *printer) ProcessAssignStat(s ast.AssignStat) {
+	if f, ok := functionStat(s); ok {
+		p.write("function ")
+		params := f.Params
+		if idx, ok := s.Dest[0].(ast.IndexExp); ok && isMethod(f) {
+			p.exp(idx.Coll)
+			p.write(":" + string(idx.Idx.(ast.String).Val))
+			params = params[1:]
+		} else {
+			p.exp(s.Dest[0])
+		}
+		p.funcBody(f, params)
+		return
+	}
+	for i, v := range s.Dest {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.exp(v)
+	}
+	p.write(" = ")
+	p.expList(s.Src)
+}
+
+// ProcessCompoundAssignStat prints a CompoundAssignStat.
+func (p *printer) ProcessCompoundAssignStat(s ast.CompoundAssignStat) {
+	p.exp(s.Dest)
+	p.write(" " + binOpStrings[s.Op] + "= ")
+	p.exp(s.Value)
+}
+
+// ProcessContinueStat prints a ContinueStat.
+func (p *printer) ProcessContinueStat(s ast.ContinueStat) {
+	p.write("continue")
+}
+
+// ProcessBlockStat prints a BlockStat.
+func (p *printer) ProcessBlockStat(s ast.BlockStat) {
+	p.inlineIf(s, func() {
+		p.write("do")
+		p.block(s, s.EndPos(), false)
+		p.write("end")
+	})
+}
+
+// ProcessBreakStat prints a BreakStat.
+func (p *printer) ProcessBreakStat(s ast.BreakStat) {
+	p.write("break")
+}
+
+// ProcessEmptyStat prints an EmptyStat.
+func (p *printer) ProcessEmptyStat(s ast.EmptyStat) {
+	p.write(";")
+}
+
+// ProcessForInStat prints a ForInStat.
+func (p *printer) ProcessForInStat(s ast.ForInStat) {
+	p.inlineIf(s, func() {
+		p.write("for ")
+		for i, v := range s.Vars {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.write(v.Val)
+		}
+		p.write(" in ")
+		p.expList(s.Params)
+		p.write(" do")
+		p.block(s.Body, s.EndPos(), false)
+		p.write("end")
+	})
+}
+
+// ProcessForStat prints a ForStat.
+func (p *printer) ProcessForStat(s ast.ForStat) {
+	p.inlineIf(s, func() {
+		p.write("for " + s.Var.Val + " = ")
+		p.exp(s.Start)
+		p.write(", ")
+		p.exp(s.Stop)
+		// The parser uses 1 when the step is omitted.
+		if !isDefaultStep(s.Step) {
+			p.write(", ")
+			p.exp(s.Step)
+		}
+		p.write(" do")
+		p.block(s.Body, s.EndPos(), false)
+		p.write("end")
+	})
+}
+
+// ProcessFunctionCallStat prints a FunctionCall statement.
+func (p *printer) ProcessFunctionCallStat(f ast.FunctionCall) {
+	p.ProcessFunctionCallExp(f)
+}
+
+// ProcessGotoStat prints a GotoStat.
+func (p *printer) ProcessGotoStat(s ast.GotoStat) {
+	p.write("goto " + s.Label.Val)
+}
+
+// ProcessIfStat prints an IfStat.
+func (p *printer) ProcessIfStat(s ast.IfStat) {
+	p.inlineIf(s, func() {
+		end := s.EndPos()
+		next := func(i int) *token.Pos {
+			if i < len(s.ElseIfs) {
+				return s.ElseIfs[i].Cond.Locate().StartPos()
+			}
+			if s.Else != nil {
+				return nil
+			}
+			return end
+		}
+		p.write("if ")
+		p.condBlock(s.If.Cond, "then", s.If.Body, next(0))
+		for i, c := range s.ElseIfs {
+			p.write("elseif ")
+			p.condBlock(c.Cond, "then", c.Body, next(i+1))
+		}
+		if s.Else != nil {
+			p.write("else")
+			p.block(*s.Else, end, false)
+		}
+		p.write("end")
+	})
+}
+
+// ProcessLabelStat prints a LabelStat.
+func (p *printer) ProcessLabelStat(s ast.LabelStat) {
+	p.write("::" + s.Name.Val + "::")
+}
+
+// ProcessLocalFunctionStat prints a LocalFunctionStat.
+func (p *printer) ProcessLocalFunctionStat(s ast.LocalFunctionStat) {
+	p.write("local function " + s.Name.Val)
+	p.funcBody(s.Function, s.Params)
+}
+
+// ProcessLocalStat prints a LocalStat.
+func (p *printer) ProcessLocalStat(s ast.LocalStat) {
+	p.write("local ")
+	for i, na := range s.NameAttribs {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.write(na.Name.Val)
+		switch na.Attrib {
+		case ast.ConstAttrib:
+			p.write(" <const>")
+		case ast.CloseAttrib:
+			p.write(" <close>")
+		}
+	}
+	if len(s.Values) > 0 {
+		p.write(" = ")
+		p.expList(s.Values)
+	}
+}
+
+// ProcessRepeatStat prints a RepeatStat.
+func (p *printer) ProcessRepeatStat(s ast.RepeatStat) {
+	p.inlineIf(s, func() {
+		p.write("repeat")
+		p.block(s.Body, s.Cond.Locate().StartPos(), false)
+		p.write("until ")
+		p.exp(s.Cond)
+	})
+}
+
+// ProcessWhileStat prints a WhileStat.
+func (p *printer) ProcessWhileStat(s ast.WhileStat) {
+	p.inlineIf(s, func() {
+		p.write("while ")
+		p.condBlock(s.Cond, "do", s.Body, s.EndPos())
+		p.write("end")
+	})
+}
+
+// inlineIf calls print, printing on a single line if the node was on a single
+// line in the source.
+func (p *printer) inlineIf(n ast.Locator, print func()) {
+	if p.canInline(n) {
+		p.inline++
+		defer func() { p.inline-- }()
+	}
+	print()
+}
+
+//
+// Expressions
+//
+
+func (p *printer) exp(e ast.ExpNode) {
+	e.ProcessExp(p)
+}
+
+// expList prints a comma separated list of expressions.  If there are comments
+// between two expressions, the line is broken after the comma and following
+// lines are indented.
+func (p *printer) expList(es []ast.ExpNode) {
+	depth := p.depth
+	for i, e := range es {
+		if i > 0 {
+			p.write(",")
+			if !p.breakLine(es[i-1].Locate().EndPos(), e.Locate().StartPos(), depth+1) {
+				p.write(" ")
+			}
+		}
+		p.exp(e)
+	}
+	p.depth = depth
+}
+
+// breakLine prints the comments before next, if any, after ending the line
+// of end.  The new line has the given indentation depth.  It returns true if it
+// broke the line.
+func (p *printer) breakLine(end, next *token.Pos, depth int) bool {
+	if p.inline > 0 || next == nil || len(p.comments) == 0 || p.comments[0].Offset >= next.Offset {
+		return false
+	}
+	p.depth = depth
+	p.endStat(end, next)
+	p.flushComments(next)
+	return true
+}
+
+// operand prints e, in brackets if it binds less tightly than an operator of
+// precedence prec (or as tightly, if bracketEqual is true).
+func (p *printer) operand(e ast.ExpNode, prec int, bracketEqual bool) {
+	ep := precedence(e)
+	if ep < prec || ep == prec && bracketEqual {
+		p.write("(")
+		p.exp(e)
+		p.write(")")
+	} else {
+		p.exp(e)
+	}
+}
+
+// Precedence of unary operators.
+var unaryPrecedence = ops.OpNeg.Precedence()
+
+// Precedence of expressions that do not need brackets.
+const atomPrecedence = 100
+
+func precedence(e ast.ExpNode) int {
+	switch x := e.(type) {
+	case *ast.BinOp:
+		return x.OpType.Precedence()
+	case ast.BinOp:
+		return x.OpType.Precedence()
+	case *ast.UnOp, ast.UnOp:
+		return unaryPrecedence
+	default:
+		return atomPrecedence
+	}
+}
+
+// prefixExp prints e so that it can be indexed or called.
+func (p *printer) prefixExp(e ast.ExpNode) {
+	switch e.(type) {
+	case ast.Name, ast.IndexExp, ast.FunctionCall, *ast.BFunctionCall, ast.BFunctionCall:
+		p.exp(e)
+	default:
+		p.write("(")
+		p.exp(e)
+		p.write(")")
+	}
+}
+
+// bracketed prints "[e]", taking care that "[[" does not start a long string.
+func (p *printer) bracketed(e ast.ExpNode) {
+	if s, ok := e.(ast.String); ok {
+		if lit := p.stringLit(s.Val); lit[0] == '[' {
+			p.write("[ " + lit + " ]")
+			return
+		}
+	}
+	p.write("[")
+	p.exp(e)
+	p.write("]")
+}
+
+func (p *printer) call(f ast.BFunctionCall) {
+	p.prefixExp(f.Target)
+	if f.Method.Val != "" {
+		p.write(":" + f.Method.Val)
+	}
+	p.write("(")
+	p.expList(f.Args)
+	p.write(")")
+}
+
+// ProcessBFunctionCallExp prints a BFunctionCall, i.e. a function call in
+// brackets.
+func (p *printer) ProcessBFunctionCallExp(f ast.BFunctionCall) {
+	p.write("(")
+	p.call(f)
+	p.write(")")
+}
+
+// ProcessBinOpExp prints a BinOp.
+func (p *printer) ProcessBinOpExp(b ast.BinOp) {
+	prec := b.OpType.Precedence()
+	rightAssoc := b.OpType == ops.OpConcat || b.OpType == ops.OpPow
+	// Operations are applied from left to right, which requires brackets for
+	// right associative operators.
+	if rightAssoc {
+		p.write(strings.Repeat("(", len(b.Right)-1))
+	}
+	p.operand(b.Left, prec, rightAssoc)
+	for i, r := range b.Right {
+		if rightAssoc && i > 0 {
+			p.write(")")
+		}
+		p.write(" " + binOpStrings[r.Op] + " ")
+		p.operand(r.Operand, prec, !rightAssoc)
+	}
+}
+
+// ProcesBoolExp prints a Bool.
+func (p *printer) ProcesBoolExp(b ast.Bool) {
+	if b.Val {
+		p.write("true")
+	} else {
+		p.write("false")
+	}
+}
+
+// ProcessEtcExp prints an Etc.
+func (p *printer) ProcessEtcExp(e ast.Etc) {
+	p.write("...")
+}
+
+// ProcessFunctionExp prints a Function.
+func (p *printer) ProcessFunctionExp(f ast.Function) {
+	p.write("function")
+	p.funcBody(f, f.Params)
+}
+
+// ProcessFunctionCallExp prints a FunctionCall.
+func (p *printer) ProcessFunctionCallExp(f ast.FunctionCall) {
+	p.call(*f.BFunctionCall)
+}
+
+// ProcessIndexExp prints an IndexExp.
+func (p *printer) ProcessIndexExp(e ast.IndexExp) {
+	p.prefixExp(e.Coll)
+	if s, ok := e.Idx.(ast.String); ok && isName(s.Val) {
+		p.write("." + string(s.Val))
+	} else {
+		p.bracketed(e.Idx)
+	}
+}
+
+// ProcessNameExp prints a Name.
+func (p *printer) ProcessNameExp(n ast.Name) {
+	p.write(n.Val)
+}
+
+// ProcessNilExp prints a Nil.
+func (p *printer) ProcessNilExp(n ast.Nil) {
+	p.write("nil")
+}
+
+// numberLit returns the literal of the number n as written in the source, if
+// available.
+func (p *printer) numberLit(n ast.Locator) (string, bool) {
+	start := n.Locate().StartPos()
+	if start == nil || p.src == nil || start.Offset >= len(p.src) {
+		return "", false
+	}
+	tok := scanner.New("", p.src[start.Offset:]).Scan()
+	if tok.Type != token.NUMDEC && tok.Type != token.NUMHEX {
+		return "", false
+	}
+	return string(tok.Lit), true
+}
+
+// ProcessIntExp prints an Int.
+func (p *printer) ProcessIntExp(n ast.Int) {
+	if lit, ok := p.numberLit(n); ok {
+		p.write(lit)
+	} else if int64(n.Val) >= 0 {
+		p.write(strconv.FormatUint(n.Val, 10))
+	} else {
+		// A decimal literal would be a float
+		p.write(fmt.Sprintf("0x%x", n.Val))
+	}
+}
+
+// ProcessFloatExp prints a Float.
+func (p *printer) ProcessFloatExp(f ast.Float) {
+	if lit, ok := p.numberLit(f); ok {
+		p.write(lit)
+		return
+	}
+	if math.IsInf(f.Val, 0) {
+		p.write("1e9999")
+		return
+	}
+	s := strconv.FormatFloat(f.Val, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	p.write(s)
+}
+
+// ProcessStringExp prints a String.
+func (p *printer) ProcessStringExp(s ast.String) {
+	lit := p.stringLit(s.Val)
+	if start := s.StartPos(); start != nil {
+		if line := start.Line + strings.Count(lit, "\n"); line > p.maxLine {
+			p.maxLine = line
+		}
+	}
+	p.write(lit)
+}
+
+// ProcessInterpolatedStringExp prints an InterpolatedString.
+func (p *printer) ProcessInterpolatedStringExp(s ast.InterpolatedString) {
+	var b strings.Builder
+	b.WriteByte('`')
+	for i, text := range s.Texts {
+		writeEscaped(&b, text, "`{}\\")
+		if i < len(s.Exps) {
+			b.WriteByte('{')
+			p.write(b.String())
+			b.Reset()
+			p.exp(s.Exps[i])
+			b.WriteByte('}')
+		}
+	}
+	b.WriteByte('`')
+	p.write(b.String())
+}
+
+// ProcessTableConstructorExp prints a TableConstructor.  It is printed with
+// one field per line if it spanned several lines in the source.
+func (p *printer) ProcessTableConstructorExp(t ast.TableConstructor) {
+	if len(t.Fields) == 0 {
+		p.write("{}")
+		return
+	}
+	if p.canInline(t) || t.StartPos() == nil || t.EndPos() == nil {
+		p.write("{")
+		for i, f := range t.Fields {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.field(f)
+		}
+		p.write("}")
+		return
+	}
+	p.write("{")
+	p.depth++
+	p.newline()
+	p.lastLine = 0
+	for i, f := range t.Fields {
+		if start := f.StartPos(); start != nil {
+			p.flushComments(start)
+			p.blankLine(start.Line)
+		}
+		p.field(f)
+		p.write(",")
+		var next *token.Pos
+		if i+1 < len(t.Fields) {
+			next = t.Fields[i+1].StartPos()
+		}
+		p.endStat(f.EndPos(), next)
+	}
+	p.flushComments(t.EndPos())
+	p.depth--
+	p.write("}")
+}
+
+func (p *printer) field(f ast.TableField) {
+	switch k := f.Key.(type) {
+	case ast.NoTableKey:
+	case ast.String:
+		if isName(k.Val) {
+			p.write(string(k.Val) + " = ")
+		} else {
+			p.bracketed(k)
+			p.write(" = ")
+		}
+	default:
+		p.bracketed(k)
+		p.write(" = ")
+	}
+	p.exp(f.Value)
+}
+
+// ProcessUnOpExp prints an UnOp.
+func (p *printer) ProcessUnOpExp(u ast.UnOp) {
+	p.write(unOpStrings[u.Op])
+	if x, ok := u.Operand.(*ast.UnOp); ok && u.Op == ops.OpNeg && x.Op == ops.OpNeg {
+		// "--" would start a comment
+		p.write(" ")
+	}
+	p.operand(u.Operand, unaryPrecedence, false)
+}
+
+var binOpStrings = map[ops.Op]string{
+	ops.OpOr:       "or",
+	ops.OpAnd:      "and",
+	ops.OpLt:       "<",
+	ops.OpLeq:      "<=",
+	ops.OpGt:       ">",
+	ops.OpGeq:      ">=",
+	ops.OpEq:       "==",
+	ops.OpNeq:      "~=",
+	ops.OpBitOr:    "|",
+	ops.OpBitXor:   "~",
+	ops.OpBitAnd:   "&",
+	ops.OpShiftL:   "<<",
+	ops.OpShiftR:   ">>",
+	ops.OpConcat:   "..",
+	ops.OpAdd:      "+",
+	ops.OpSub:      "-",
+	ops.OpMul:      "*",
+	ops.OpDiv:      "/",
+	ops.OpFloorDiv: "//",
+	ops.OpMod:      "%",
+	ops.OpPow:      "^",
+}
+
+var unOpStrings = map[ops.Op]string{
+	ops.OpNeg:    "-",
+	ops.OpNot:    "not ",
+	ops.OpLen:    "#",
+	ops.OpBitNot: "~",
+}
+
+var keywords = map[string]bool{
+	"and": true, "break": true, "do": true, "else": true, "elseif": true,
+	"end": true, "false": true, "for": true, "function": true, "goto": true,
+	"if": true, "in": true, "local": true, "nil": true, "not": true, "or": true,
+	"repeat": true, "return": true, "then": true, "true": true, "until": true,
+	"while": true,
+}
+
+// isName returns true if s is a valid Lua name.
+func isName(s []byte) bool {
+	if len(s) == 0 || keywords[string(s)] {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// stringLit returns a Lua literal for the string s.  Multiline strings are
+// written as long strings when possible (unless printing on a single line),
+// other strings are double quoted.
+func (p *printer) stringLit(s []byte) string {
+	if p.inline == 0 {
+		if lit, ok := longStringLit(s); ok {
+			return lit
+		}
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	writeEscaped(&b, s, `"\`)
+	b.WriteByte('"')
+	return b.String()
+}
+
+// writeEscaped writes s to b as the contents of a short string literal, where
+// the characters in special must be escaped with a backslash.
+func writeEscaped(b *strings.Builder, s []byte, special string) {
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRune(s[i:])
+		switch {
+		case strings.ContainsRune(special, r):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == utf8.RuneError && n == 1 || r < ' ' || r == 0x7f:
+			fmt.Fprintf(b, `\%03d`, s[i])
+		default:
+			b.Write(s[i : i+n])
+		}
+		i += n
+	}
+}
+
+// longStringLit returns a long string literal for s if it contains a newline
+// and can be written as a long string (long strings cannot represent "\r" or
+// invalid UTF-8 faithfully).
+func longStringLit(s []byte) (string, bool) {
+	if bytes.IndexByte(s, '\n') < 0 || !utf8.Valid(s) {
+		return "", false
+	}
+	for _, c := range s {
+		if c < ' ' && c != '\n' && c != '\t' || c == 0x7f {
+			return "", false
+		}
+	}
+	// Find a level that does not occur in s.
+	level := ""
+	for strings.Contains(string(s)+"]", "]"+level+"]") {
+		level += "="
+	}
+	// The first newline is skipped by the scanner, so add one.
+	return "[" + level + "[\n" + string(s) + "]" + level + "]", true
+}
@@ -0,0 +1,208 @@
+package luafmt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/arnodel/golua/parsing"
+	rt "github.com/arnodel/golua/runtime"
+	"github.com/arnodel/golua/scanner"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{
+			name: "statements",
+			src: `local x<const>,y=1,2;;
+if x then print(x) elseif y then
+print(y) else end
+for i=1,10 do end for i=1,10,1 do end
+while x do break end repeat local z=x until z
+function a.b.c(x,...) end function a.b:c() return self end
+local function f() return end`,
+			want: `local x <const>, y = 1, 2
+if x then
+    print(x)
+elseif y then
+    print(y)
+else
+end
+for i = 1, 10 do end
+for i = 1, 10, 1 do end
+while x do break end
+repeat local z = x until z
+function a.b.c(x, ...) end
+function a.b:c() return self end
+local function f() end
+`,
+		},
+		{
+			name: "expressions",
+			src: `x = (a+b)*c-d-(e-f)..g..(h..i)^-(j^k)^l
+y = - -x, -2^2, (-2)^2, not a == b, #t+1
+z = t["x"], t["end"], t[1], ("s"):rep(2), (f()), {1, x=2, ["y z"]=3, [4]=5}
+w = 0xff, 1e100, 2.0, 1/0, 0xffffffffffffffff`,
+			want: `x = (a + b) * c - d - (e - f) .. g .. (h .. i) ^ (-(j ^ k) ^ l)
+y = - -x, -2 ^ 2, (-2) ^ 2, not a == b, #t + 1
+z = t.x, t["end"], t[1], ("s"):rep(2), (f()), {1, x = 2, ["y z"] = 3, [4] = 5}
+w = 0xff, 1e100, 2.0, 1 / 0, 0xffffffffffffffff
+`,
+		},
+		{
+			name: "strings",
+			src: `a = "x\\\"\0012\r"
+b = "line1\nline2"
+c = t["a\nb"]`,
+			want: `a = "x\\\"\0012\r"
+b = [[
+line1
+line2]]
+c = t[ [[
+a
+b]] ]
+`,
+		},
+		{
+			name: "ambiguous call",
+			src:  "local a = f\n;(g or h)()",
+			want: "local a = f\n;(g or h)()\n",
+		},
+		{
+			name: "comments",
+			src: `-- header
+
+local x = 1 -- trailing
+--[[ long
+comment ]]
+local t = {
+  1, -- one
+
+  -- two
+  2,
+}
+function f()
+  -- body
+  return x
+  -- end of body
+end
+-- end of file`,
+			want: `-- header
+
+local x = 1 -- trailing
+--[[ long
+comment ]]
+local t = {
+    1, -- one
+
+    -- two
+    2,
+}
+function f()
+    -- body
+    return x
+    -- end of body
+end
+-- end of file
+`,
+		},
+		{
+			name: "trailing comments",
+			src: `local a = 1 local b = 2 -- b
+local t = {1, 2, 3, -- three
+  4}
+f(x, -- x
+  y, --[[ y ]] z) -- z
+print(1) -- done`,
+			want: `local a = 1
+local b = 2 -- b
+local t = {
+    1,
+    2,
+    3, -- three
+    4,
+}
+f(x, -- x
+    y,
+    --[[ y ]]
+    z) -- z
+print(1) -- done
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := Source("test", []byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", out, test.want)
+			}
+		})
+	}
+}
+
+// Check that formatting the Lua test files of the repository is idempotent and
+// does not change the compiled code.
+func TestRoundTrip(t *testing.T) {
+	var files []string
+	for _, pattern := range []string{"../runtime/lua/*.lua", "../lib/*/lua/*.lua", "../benchmarks/lua/*.lua"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			src, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := rt.New(nil)
+			unit, _, err := r.CompileLuaChunk(file, src)
+			if err != nil {
+				t.Skip("does not compile")
+			}
+			out, err := Source(file, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out2, err := Source(file, out)
+			if err != nil {
+				t.Fatalf("error parsing formatted source: %s\n%s", err, out)
+			}
+			if !bytes.Equal(out, out2) {
+				t.Errorf("formatting is not idempotent:\n%s\n---\n%s", out, out2)
+			}
+			if !reflect.DeepEqual(comments(src), comments(out)) {
+				t.Errorf("comments not preserved:\n%s", out)
+			}
+			unit2, _, err := r.CompileLuaChunk(file, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(unit.Code, unit2.Code) || !reflect.DeepEqual(unit.Constants, unit2.Constants) {
+				t.Errorf("formatted code compiles differently:\n%s", out)
+			}
+		})
+	}
+}
+
+func comments(src []byte) []string {
+	chunk, err := parsing.ParseChunk(scanner.New("test", src, scanner.WithComments()))
+	if err != nil {
+		return nil
+	}
+	var lits []string
+	for _, c := range chunk.Comments {
+		lits = append(lits, string(c.Lit))
+	}
+	return lits
+}
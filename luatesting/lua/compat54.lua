@@ -0,0 +1,12 @@
+-- Compatibility functions are not available in Lua 5.4
+
+print(_VERSION)
+--> =Golua 5.4
+
+print(getfenv, unpack, loadstring, module, math.pow)
+--> =nil	nil	nil	nil	nil
+
+local mt = {__lt = function(a, b) return a.v < b.v end}
+local x, y = setmetatable({v = 1}, mt), setmetatable({v = 2}, mt)
+print(pcall(function() return x <= y end))
+--> ~false	.*attempt to compare
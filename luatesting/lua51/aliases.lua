@@ -0,0 +1,11 @@
+print(unpack({1, 2, 3}))
+--> =1	2	3
+
+print(loadstring("return 1 + 1")())
+--> =2
+
+print(table.getn({1, 2, 3, nil}))
+--> =3
+
+print(math.pow(2, 10))
+--> =1024
@@ -0,0 +1,39 @@
+local env = {print = print}
+
+local function f()
+    return x
+end
+
+print(getfenv(f) == _G, getfenv() == _G, getfenv(0) == _G)
+--> =true	true	true
+
+x = "global"
+print(setfenv(f, {x = "local"}) == f)
+--> =true
+
+print(f(), x)
+--> =local	global
+
+-- Other functions sharing the _ENV upvalue are not affected
+local function g()
+    return x
+end
+print(g())
+--> =global
+
+local function h()
+    setfenv(1, env)
+    y = 42
+    return y
+end
+print(h(), y, env.y)
+--> =42	nil	42
+
+print(getfenv(h) == env)
+--> =true
+
+print(pcall(setfenv, print, {}))
+--> ~false	.*cannot change the environment of given object
+
+print(pcall(setfenv, 0, {}))
+--> ~false	.*cannot change the environment of given object
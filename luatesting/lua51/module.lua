@@ -0,0 +1,23 @@
+local function mymod()
+    module("a.b.mymod", package.seeall)
+    function hello(x)
+        return "hello " .. x
+    end
+end
+mymod()
+
+print(a.b.mymod.hello("world"))
+--> =hello world
+
+print(package.loaded["a.b.mymod"] == a.b.mymod)
+--> =true
+
+print(a.b.mymod._NAME, a.b.mymod._PACKAGE, a.b.mymod._M == a.b.mymod)
+--> =a.b.mymod	a.b.	true
+
+print(hello)
+--> =nil
+
+a.c = 1
+print(pcall(module, "a.c.d"))
+--> ~false	.*name conflict for module 'a.c.d'
@@ -0,0 +1,35 @@
+-- Lua 5.1 has no integers
+
+print(math.type(1), math.type(2^53), math.type(3 // 2))
+--> =float	float	float
+
+print(1, 10 // 3, 7 % 4)
+--> =1	3	3
+
+print(math.type(tonumber("12")), math.type(tonumber("0x10")))
+--> =float	float
+
+print(tonumber("ff", 16))
+--> =255
+
+-- Integers coming from the length operator, library functions and string
+-- arithmetic are converted to floats.
+print(math.type(#"abc"), math.type(#{1, 2}), math.type(string.len("abc")))
+--> =float	float	float
+
+print(math.type(math.floor(2.5)), math.type(select("#", 1, 2)), math.type(("abc"):find("b")))
+--> =float	float	float
+
+print(math.type("10" * "2"), math.type(-"3"))
+--> =float	float
+
+for i in ipairs({"a"}) do print(math.type(i)) end
+--> =float
+
+local function id(...) return ... end
+local t = setmetatable({}, {__len = function() return math.tointeger(3) end})
+print(math.type(id(string.byte("a"))), math.type(#t))
+--> =float	float
+
+print(_VERSION)
+--> =Golua 5.1
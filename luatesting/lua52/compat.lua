@@ -0,0 +1,18 @@
+-- Lua 5.2 has integers but still unpack, loadstring and module
+
+print(math.type(1), _VERSION)
+--> =integer	Golua 5.2
+
+print(getfenv, setfenv, table.getn)
+--> =nil	nil	nil
+
+print(loadstring("return 3")(), math.pow(3, 2), unpack({1, 2}))
+--> =3	9	1	2
+
+local function define()
+    module("mymod")
+    value = 1
+end
+define()
+print(mymod.value, value)
+--> =1	nil
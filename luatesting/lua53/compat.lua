@@ -0,0 +1,19 @@
+print(_VERSION, math.pow(2, 0.5) == math.sqrt(2))
+--> =Golua 5.3	true
+
+print(unpack, loadstring, module, package.seeall)
+--> =nil	nil	nil	nil
+
+-- __le is emulated with __lt
+local mt = {__lt = function(a, b) return a.v < b.v end}
+local x, y = setmetatable({v = 1}, mt), setmetatable({v = 2}, mt)
+print(x <= y, y <= x, x >= y, x <= x)
+--> =true	false	false	true
+
+-- unless __le is defined
+mt.__le = function() return false end
+print(x <= y)
+--> =false
+
+print(pcall(function() return {} <= {} end))
+--> ~false	.*attempt to compare
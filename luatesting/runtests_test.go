@@ -34,6 +34,25 @@ func TestLua(t *testing.T) {
 	luatesting.RunLuaTestsInDir(t, "lua", setup)
 }
 
+func TestLua51(t *testing.T) {
+	luatesting.RunLuaTestsInDir(t, "lua51", setupVersion(rt.Lua51))
+}
+
+func TestLua52(t *testing.T) {
+	luatesting.RunLuaTestsInDir(t, "lua52", setupVersion(rt.Lua52))
+}
+
+func TestLua53(t *testing.T) {
+	luatesting.RunLuaTestsInDir(t, "lua53", setupVersion(rt.Lua53))
+}
+
+func setupVersion(v rt.LuaVersion) func(*rt.Runtime) func() {
+	return func(r *rt.Runtime) func() {
+		r.SetLuaVersion(v)
+		return setup(r)
+	}
+}
+
 func setup(r *rt.Runtime) func() {
 	cleanup := lib.LoadAll(r)
 	g := r.GlobalEnv()
@@ -9,6 +9,9 @@ import (
 )
 
 func main() {
+	if code, ok := runSubcommand(); ok {
+		os.Exit(code)
+	}
 	cmd := new(luaCmd)
 	cmd.setFlags()
 	flag.Parse()
@@ -11,6 +11,9 @@ import (
 )
 
 func main() {
+	if code, ok := runSubcommand(); ok {
+		os.Exit(code)
+	}
 	cmd := new(luaCmd)
 	cmd.setFlags()
 	cpuprofile := flag.String("cpuprofile", "", "write cpu profile to `file`")
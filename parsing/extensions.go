@@ -0,0 +1,95 @@
+package parsing
+
+import (
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ops"
+	"github.com/arnodel/golua/token"
+)
+
+// This file contains the parsing of the extensions to the Lua syntax (see
+// WithExtensions).
+
+// WithExtensions makes the parser accept the given extensions to the Lua
+// syntax.  The scanner should be created with the same extensions (see
+// scanner.WithExtensions) so that it produces the tokens they need.
+func WithExtensions(ext token.Extensions) Option {
+	return func(p *Parser) {
+		p.extensions |= ext
+	}
+}
+
+// expectExtension panics with a syntax error at t if the extension ext is not
+// enabled.
+func (p *Parser) expectExtension(t *token.Token, ext token.Extensions) {
+	if !p.extensions.Has(ext) {
+		tokenError(t, "")
+	}
+}
+
+// isContinue returns true if t is a "continue" which may start a continue
+// statement.  With ExtContinue, "continue" is not a reserved word but it is
+// treated as a keyword at the start of a statement (a name on its own is not
+// a valid statement so this does not change the meaning of any Lua code).
+// Whether it is in a loop is checked by the compiler.
+func (p *Parser) isContinue(t *token.Token) bool {
+	return p.extensions.Has(token.ExtContinue) &&
+		t.Type == token.IDENT && string(t.Lit) == "continue"
+}
+
+// continuesExpStat returns true if t can follow the first name of an
+// expression statement (i.e. a function call or an assignment).
+func continuesExpStat(t *token.Token) bool {
+	switch t.Type {
+	case token.SgAssign, token.SgComma, token.SgOpAssign,
+		token.SgOpenSquareBkt, token.SgDot, token.SgColon,
+		token.SgOpenBkt, token.SgOpenBrace, token.STRING, token.LONGSTRING:
+		return true
+	default:
+		return false
+	}
+}
+
+var compoundAssignMap = map[string]ops.Op{
+	"+=":  ops.OpAdd,
+	"-=":  ops.OpSub,
+	"*=":  ops.OpMul,
+	"/=":  ops.OpDiv,
+	"//=": ops.OpFloorDiv,
+	"%=":  ops.OpMod,
+	"^=":  ops.OpPow,
+	"..=": ops.OpConcat,
+	"&=":  ops.OpBitAnd,
+	"|=":  ops.OpBitOr,
+	"<<=": ops.OpShiftL,
+	">>=": ops.OpShiftR,
+}
+
+// CompoundAssign parses a compound assignment "var op= exp".  It assumes that
+// the variable has been parsed and that opTok is the operator token.
+func (p *Parser) CompoundAssign(dest ast.Var, opTok *token.Token) (ast.Stat, *token.Token) {
+	p.expectExtension(opTok, token.ExtCompoundAssign)
+	value, t := p.Exp(p.Scan())
+	return ast.NewCompoundAssignStat(dest, compoundAssignMap[string(opTok.Lit)], value), t
+}
+
+// InterpolatedString parses an interpolated string.  It assumes that t is an
+// INTERPSTRING or INTERPBEGIN token.
+func (p *Parser) InterpolatedString(t *token.Token) (ast.ExpNode, *token.Token) {
+	p.expectExtension(t, token.ExtInterpolation)
+	toks := []*token.Token{t}
+	var exps []ast.ExpNode
+	for t.Type != token.INTERPSTRING && t.Type != token.INTERPEND {
+		var exp ast.ExpNode
+		exp, t = p.Exp(p.Scan())
+		if t.Type != token.INTERPMID && t.Type != token.INTERPEND {
+			tokenError(t, "'}'")
+		}
+		exps = append(exps, exp)
+		toks = append(toks, t)
+	}
+	s, err := ast.NewInterpolatedString(toks, exps)
+	if err != nil {
+		panic(err)
+	}
+	return s, p.Scan()
+}
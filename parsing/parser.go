@@ -13,7 +13,49 @@ import (
 
 // Parser can parse lua statements or expressions
 type Parser struct {
-	scanner Scanner
+	scanner  Scanner
+	comments []token.Comment
+
+	types       bool            // True if type annotations are accepted
+	typeAliases []ast.TypeAlias // Type aliases in the block being parsed
+
+	extensions token.Extensions // Extensions to the syntax (see WithExtensions)
+
+	// The fields below are used when recovering from errors (see
+	// ParseChunkWithRecovery).
+	recover    bool
+	errors     []Error
+	nesting    int          // Number of blocks opened by the scanned tokens
+	last       *token.Token // Last scanned token
+	scanFailed bool         // True after the scanner has failed
+	eof        *token.Token // Returned by Scan after the scanner has failed
+}
+
+// An Option configures a Parser.
+type Option func(*Parser)
+
+// WithTypes makes the parser accept type annotations, which are recorded in the
+// AST (see ast.TypeNode).  The syntax is a subset of Luau's:
+//
+//	local x: number = 1
+//	local function f(a: string, b: {number}?, ...: any): (boolean, string)
+//	type Point = {x: number, y: number}
+//	type Callback = (Point, string | nil) -> ()
+//
+// The scanner should be created with scanner.WithTypes() so that it accepts
+// the "?" and "->" tokens.
+func WithTypes() Option {
+	return func(p *Parser) {
+		p.types = true
+	}
+}
+
+func newParser(scanner Scanner, opts []Option) *Parser {
+	p := &Parser{scanner: scanner}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 type Scanner interface {
@@ -48,7 +90,7 @@ func (e Error) Error() string {
 
 // ParseExp takes in a function that returns tokens and builds an ExpNode for it
 // (or returns an error).
-func ParseExp(scanner Scanner) (exp ast.ExpNode, err error) {
+func ParseExp(scanner Scanner, opts ...Option) (exp ast.ExpNode, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			exp = nil
@@ -59,7 +101,7 @@ func ParseExp(scanner Scanner) (exp ast.ExpNode, err error) {
 			}
 		}
 	}()
-	parser := &Parser{scanner}
+	parser := newParser(scanner, opts)
 	var t *token.Token
 	exp, t = parser.Exp(parser.Scan())
 	expectType(t, token.EOF, "<eof>")
@@ -68,7 +110,7 @@ func ParseExp(scanner Scanner) (exp ast.ExpNode, err error) {
 
 // ParseChunk takes in a function that returns tokens and builds a BlockStat for it
 // (or returns an error).
-func ParseChunk(scanner Scanner) (stat ast.BlockStat, err error) {
+func ParseChunk(scanner Scanner, opts ...Option) (stat ast.BlockStat, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			stat = ast.BlockStat{}
@@ -79,22 +121,164 @@ func ParseChunk(scanner Scanner) (stat ast.BlockStat, err error) {
 			}
 		}
 	}()
-	parser := &Parser{scanner}
+	parser := newParser(scanner, opts)
 	var t *token.Token
 	stat, t = parser.Block(parser.Scan())
 	expectType(t, token.EOF, "<eof>")
+	stat.Comments = parser.comments
 	return
 }
 
+// ParseChunkWithRecovery parses a chunk like ParseChunk, but does not stop at
+// the first syntax error.  Instead it records the error and resumes parsing at
+// the next statement boundary.  It returns a partial AST made of the
+// statements that could be parsed, and all the errors found, in source order.
+// Errors are only returned until the scanner fails, as it cannot resume after
+// an invalid token.
+func ParseChunkWithRecovery(scanner Scanner, opts ...Option) (ast.BlockStat, []Error) {
+	parser := newParser(scanner, opts)
+	parser.recover = true
+	var (
+		stats   []ast.Stat
+		aliases []ast.TypeAlias
+		ret     []ast.ExpNode
+		t       = parser.Scan()
+	)
+	for {
+		var stat ast.BlockStat
+		stat, t = parser.Block(t)
+		stats = append(stats, stat.Stats...)
+		aliases = append(aliases, stat.TypeAliases...)
+		ret = stat.Return
+		if t.Type == token.EOF {
+			break
+		}
+		// A block closing token without a matching opening token
+		parser.addError(Error{Got: t, Expected: "<eof>"})
+		t = parser.skip(parser.Scan(), 0, t.Line)
+	}
+	stat := ast.NewBlockStat(stats, ret)
+	stat.TypeAliases = aliases
+	stat.Comments = parser.comments
+	return stat, parser.errors
+}
+
 // Scan returns the next token.
 func (p *Parser) Scan() *token.Token {
+	if p.scanFailed {
+		return p.eof
+	}
 	tok := p.scanner.Scan()
+	if p.recover {
+		switch tok.Type {
+		case token.INVALID, token.UNFINISHED:
+			// The scanner cannot carry on, so pretend the input ends here.
+			err := Error{Got: tok}
+			if tok.Type == token.INVALID {
+				err.Expected = p.scanner.ErrorMsg()
+			}
+			p.addError(err)
+			p.scanFailed = true
+			p.eof = &token.Token{Type: token.EOF, Pos: tok.Pos}
+			return p.eof
+		}
+		p.nesting += blockDelta(tok.Type)
+		p.last = tok
+	}
 	if tok.Type == token.INVALID {
 		panic(Error{Got: tok, Expected: p.scanner.ErrorMsg()})
 	}
+	p.comments = append(p.comments, tok.Comments...)
 	return tok
 }
 
+func (p *Parser) addError(err Error) {
+	// Errors following a scanner failure are caused by the input being cut
+	// short.
+	if p.scanFailed {
+		return
+	}
+	// Only report the first error for a given token (e.g. EOF may be
+	// unexpected by all the enclosing blocks).
+	if n := len(p.errors); n > 0 && p.errors[n-1].Got == err.Got {
+		return
+	}
+	p.errors = append(p.errors, err)
+}
+
+// recovering calls parse, which parses a construct starting at t and returns
+// the token following it.  If the parser is recovering from errors and parse
+// fails with a syntax error, the error is recorded and tokens are skipped
+// until the next statement boundary.  In that case ok is false and next is
+// the token to resume parsing from.
+func (p *Parser) recovering(t *token.Token, parse func() *token.Token) (next *token.Token, ok bool) {
+	if !p.recover {
+		return parse(), true
+	}
+	nesting := p.nesting - blockDelta(t.Type)
+	defer func() {
+		if r := recover(); r != nil {
+			err, isErr := r.(Error)
+			if !isErr {
+				panic(r)
+			}
+			p.addError(err)
+			// Skip the blocks opened since t, so that their closing tokens
+			// are not mistaken for those of enclosing blocks.  Skipping
+			// starts at the last scanned token, which is usually err.Got.
+			last := p.last
+			next = p.skip(last, p.nesting-blockDelta(last.Type)-nesting, last.Line)
+			if next == t && t.Type != token.EOF {
+				// Make sure we make progress
+				next = p.skip(p.Scan(), 0, t.Line)
+			}
+		}
+	}()
+	return parse(), true
+}
+
+// skip returns the first token starting from t that is a statement boundary,
+// after closing depth blocks.  The line of the token preceding t is prevLine.
+func (p *Parser) skip(t *token.Token, depth int, prevLine int) *token.Token {
+	for t.Type != token.EOF && (depth > 0 || !isStatBoundary(t, prevLine)) {
+		depth += blockDelta(t.Type)
+		prevLine = t.Line
+		t = p.Scan()
+	}
+	return t
+}
+
+// blockDelta returns the change in block nesting caused by a token.
+func blockDelta(tp token.Type) int {
+	switch tp {
+	case token.KwFunction, token.KwIf, token.KwDo, token.KwRepeat:
+		return 1
+	case token.KwEnd, token.KwUntil:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// isStatBoundary returns true if a statement can start or a block can end at
+// t, given the line of the previous token.  As line breaks are not
+// significant in Lua, names and "function" are only considered to start a
+// statement when they are at the start of a line (otherwise "function" is
+// likely to start a function expression).
+func isStatBoundary(t *token.Token, prevLine int) bool {
+	switch t.Type {
+	case token.EOF, token.KwEnd, token.KwElse, token.KwElseIf, token.KwUntil,
+		token.KwLocal, token.KwReturn, token.KwIf, token.KwWhile, token.KwFor,
+		token.KwRepeat, token.KwDo, token.KwGoto, token.KwBreak,
+		token.SgDoubleColon, token.SgSemicolon:
+		return true
+	case token.IDENT, token.KwFunction:
+		return t.Line > prevLine
+	default:
+		return false
+	}
+}
+
 // Stat parses any statement.
 func (p *Parser) Stat(t *token.Token) (ast.Stat, *token.Token) {
 	switch t.Type {
@@ -109,6 +293,7 @@ func (p *Parser) Stat(t *token.Token) (ast.Stat, *token.Token) {
 	case token.KwDo:
 		stat, closer := p.Block(p.Scan())
 		expectType(closer, token.KwEnd, "'end'")
+		stat.Location = ast.LocFromTokens(t, closer)
 		return stat, p.Scan()
 	case token.KwWhile:
 		cond, doTok := p.Exp(p.Scan())
@@ -135,12 +320,32 @@ func (p *Parser) Stat(t *token.Token) (ast.Stat, *token.Token) {
 		return ast.NewLabelStat(name), p.Scan()
 	default:
 		var exp ast.ExpNode
-		exp, t = p.PrefixExp(t)
+		if p.isContinue(t) {
+			// "continue" is only a keyword if it cannot start an expression
+			// statement
+			next := p.Scan()
+			if !continuesExpStat(next) {
+				return ast.NewContinueStat(t), next
+			}
+			exp, t = p.prefixExpSuffix(ast.NewName(t), next)
+		} else if p.types && t.Type == token.IDENT && string(t.Lit) == "type" {
+			// "type Name" can only start a type alias definition
+			next := p.Scan()
+			if next.Type == token.IDENT {
+				return nil, p.TypeAlias(t, next)
+			}
+			exp, t = p.prefixExpSuffix(ast.NewName(t), next)
+		} else {
+			exp, t = p.PrefixExp(t)
+		}
 		switch e := exp.(type) {
 		case ast.Stat:
 			// This is a function call
 			return e, t
 		case ast.Var:
+			if t.Type == token.SgOpAssign {
+				return p.CompoundAssign(e, t)
+			}
 			// This should be the start of 'varlist = explist'
 			vars := []ast.Var{e}
 			var pexp ast.ExpNode
@@ -177,14 +382,15 @@ func (p *Parser) If(t *token.Token) (ast.IfStat, *token.Token) {
 			thenBlock, endTok = p.Block(p.Scan())
 			ifStat = ifStat.AddElseIf(cond, thenBlock)
 		case token.KwEnd:
+			ifStat.Location = ast.MergeLocations(ifStat, ast.LocFromToken(endTok))
 			return ifStat, p.Scan()
 		case token.KwElse:
 			elseBlock, elseTok := p.Block(p.Scan())
 			expectType(elseTok, token.KwEnd, "'end'")
-			ifStat = ifStat.WithElse(endTok, elseBlock)
+			ifStat = ifStat.WithElse(elseTok, elseBlock)
 			return ifStat, p.Scan()
 		default:
-			tokenError(t, "'elseif' or 'end' or 'else'")
+			tokenError(endTok, "'elseif' or 'end' or 'else'")
 		}
 	}
 }
@@ -280,16 +486,41 @@ func (p *Parser) FunctionStat(*token.Token) (ast.Stat, *token.Token) {
 func (p *Parser) Block(t *token.Token) (ast.BlockStat, *token.Token) {
 	var stats []ast.Stat
 	var next ast.Stat
+	var ok bool
+
+	// Type aliases are collected by TypeAlias.
+	outerAliases := p.typeAliases
+	p.typeAliases = nil
+	defer func() { p.typeAliases = outerAliases }()
+	newBlock := func(ret []ast.ExpNode) ast.BlockStat {
+		block := ast.NewBlockStat(stats, ret)
+		block.TypeAliases = p.typeAliases
+		return block
+	}
+
 	for {
 		switch t.Type {
 		case token.KwReturn:
-			ret, t := p.Return(t)
-			return ast.NewBlockStat(stats, ret), t
+			var ret []ast.ExpNode
+			start := t
+			t, ok = p.recovering(start, func() (after *token.Token) {
+				ret, after = p.Return(start)
+				return
+			})
+			if ok {
+				return newBlock(ret), t
+			}
 		case token.KwEnd, token.KwElse, token.KwElseIf, token.KwUntil, token.EOF:
-			return ast.NewBlockStat(stats, nil), t
+			return newBlock(nil), t
 		default:
-			next, t = p.Stat(t)
-			stats = append(stats, next)
+			start := t
+			t, ok = p.recovering(start, func() (after *token.Token) {
+				next, after = p.Stat(start)
+				return
+			})
+			if ok && next != nil {
+				stats = append(stats, next)
+			}
 		}
 	}
 }
@@ -379,6 +610,8 @@ func (p *Parser) ShortExp(t *token.Token) (ast.ExpNode, *token.Token) {
 		exp, t = s, p.Scan()
 	case token.LONGSTRING:
 		exp, t = ast.NewLongString(t), p.Scan()
+	case token.INTERPSTRING, token.INTERPBEGIN:
+		exp, t = p.InterpolatedString(t)
 	case token.SgOpenBrace:
 		exp, t = p.TableConstructor(t)
 	case token.SgEtc:
@@ -445,19 +678,25 @@ func (p *Parser) FunctionDef(startTok *token.Token) (ast.Function, *token.Token)
 	t := p.Scan()
 	var names []ast.Name
 	hasEtc := false
+	var types []ast.TypeNode
+	var dotsType ast.TypeNode
+	annotated := false
 ParamsLoop:
 	for {
 		switch t.Type {
 		case token.IDENT:
 			names = append(names, ast.NewName(t))
-			t = p.Scan()
+			var tp ast.TypeNode
+			tp, t = p.optionalAnnotation(p.Scan())
+			types = append(types, tp)
+			annotated = annotated || tp != nil
 			if t.Type != token.SgComma {
 				break ParamsLoop
 			}
 			t = p.Scan()
 		case token.SgEtc:
 			hasEtc = true
-			t = p.Scan()
+			dotsType, t = p.optionalAnnotation(p.Scan())
 			break ParamsLoop
 		case token.SgCloseBkt:
 			break ParamsLoop
@@ -466,9 +705,22 @@ ParamsLoop:
 		}
 	}
 	expectType(t, token.SgCloseBkt, "')'")
-	body, endTok := p.Block(p.Scan())
+	t = p.Scan()
+	var returnTypes *ast.TypeList
+	if p.types && t.Type == token.SgColon {
+		var list ast.TypeList
+		list, t = p.returnTypes(p.Scan())
+		returnTypes = &list
+	}
+	body, endTok := p.Block(t)
 	expectType(endTok, token.KwEnd, "'end'")
-	def := ast.NewFunction(startTok, endTok, ast.NewParList(names, hasEtc), body)
+	parList := ast.NewParList(names, hasEtc)
+	if annotated {
+		parList.ParamTypes = types
+	}
+	parList.DotsType = dotsType
+	def := ast.NewFunction(startTok, endTok, parList, body)
+	def.ReturnTypes = returnTypes
 	return def, p.Scan()
 }
 
@@ -488,7 +740,12 @@ func (p *Parser) PrefixExp(t *token.Token) (ast.ExpNode, *token.Token) {
 	default:
 		tokenError(t, "")
 	}
-	t = p.Scan()
+	return p.prefixExpSuffix(exp, p.Scan())
+}
+
+// prefixExpSuffix parses the indexing operations and function applications
+// following exp in a prefix expression.
+func (p *Parser) prefixExpSuffix(exp ast.ExpNode, t *token.Token) (ast.ExpNode, *token.Token) {
 	for {
 		switch t.Type {
 		case token.SgOpenSquareBkt:
@@ -629,7 +886,12 @@ func (p *Parser) NameAttrib(t *token.Token) (ast.NameAttrib, *token.Token) {
 		expectType(t, token.SgGreater, "'>'")
 		t = p.Scan()
 	}
-	return ast.NewNameAttrib(name, attribName, attrib), t
+	nameAttrib := ast.NewNameAttrib(name, attribName, attrib)
+	nameAttrib.Type, t = p.optionalAnnotation(t)
+	if nameAttrib.Type != nil {
+		nameAttrib.Location = ast.MergeLocations(nameAttrib, nameAttrib.Type)
+	}
+	return nameAttrib, t
 }
 
 func expectIdent(t *token.Token) {
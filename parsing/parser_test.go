@@ -1402,3 +1402,206 @@ func TestError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestParseChunkWithRecovery(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantErrs  []string
+		wantStats int
+	}{
+		{
+			name:      "no error",
+			input:     "local x = 1\nprint(x)",
+			wantStats: 2,
+		},
+		{
+			name:      "resume at next line",
+			input:     "local x = = 1\nprint(x)\nlocal y = )\nreturn y",
+			wantErrs:  []string{"1:11: unexpected symbol near '='", "3:11: unexpected symbol near ')'"},
+			wantStats: 1,
+		},
+		{
+			name:      "error in nested block",
+			input:     "function f()\n  local x = = 1\n  return x\nend\nfunction g( end\nprint 'ok'",
+			wantErrs:  []string{"2:13: unexpected symbol near '='", "5:13: unexpected symbol near 'end'"},
+			wantStats: 2,
+		},
+		{
+			name:      "skip whole block",
+			input:     "if x = 1 then foo() end\nbar()",
+			wantErrs:  []string{"1:6: expected 'then' near '='"},
+			wantStats: 1,
+		},
+		{
+			name:      "unmatched block closers",
+			input:     "end\nx = 1\nuntil y\nz = 2",
+			wantErrs:  []string{"1:1: expected <eof> near 'end'", "3:1: expected <eof> near 'until'"},
+			wantStats: 2,
+		},
+		{
+			name:     "unexpected eof reported once",
+			input:    "function f() if x then",
+			wantErrs: []string{"1:23: expected 'elseif' or 'end' or 'else' near <eof>"},
+		},
+		{
+			name:      "stop at scanner error",
+			input:     "x = = 1\ny = 'abc\nz = )",
+			wantErrs:  []string{"1:5: unexpected symbol near '='", "2:5: invalid token: illegal new line in string literal near '\\'abc\\n'"},
+			wantStats: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stat, errs := ParseChunkWithRecovery(scanner.New("test", []byte(tt.input)))
+			var gotErrs []string
+			for _, err := range errs {
+				gotErrs = append(gotErrs, err.Error())
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("ParseChunkWithRecovery() errors = %q, want %q", gotErrs, tt.wantErrs)
+			}
+			if len(stat.Stats) != tt.wantStats {
+				t.Errorf("ParseChunkWithRecovery() got %d statements, want %d", len(stat.Stats), tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	parse := func(src string) (ast.BlockStat, error) {
+		return ParseChunk(scanner.New("test", []byte(src), scanner.WithTypes()), WithTypes())
+	}
+	stat, err := parse(`
+type Point = {x: number, y: number}
+type Tree = {value: any; children: {Tree}?}
+type F = (number, ...string) -> (boolean, Point?)
+type G = ((number) -> ()) | {[string]: {number}} | nil
+local p: Point, n <const>: integer? = {x = 1, y = 2}, 3
+local function f(a: string, b, ...: number): ...any
+    type Inner = string | number
+    return a
+end
+function t:m(x: Point): (number) -> number
+end
+type(x)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var aliases []string
+	for _, alias := range stat.TypeAliases {
+		aliases = append(aliases, alias.Name.Val+" = "+alias.Type.String())
+	}
+	expectedAliases := []string{
+		"Point = {x: number, y: number}",
+		"Tree = {value: any, children: {Tree}?}",
+		"F = (number, ...string) -> (boolean, Point?)",
+		"G = ((number) -> ()) | {[string]: {number}} | nil",
+	}
+	if !reflect.DeepEqual(aliases, expectedAliases) {
+		t.Errorf("got aliases %q", aliases)
+	}
+	if len(stat.Stats) != 4 {
+		t.Fatalf("got %d statements", len(stat.Stats))
+	}
+	local := stat.Stats[0].(ast.LocalStat)
+	if tp := local.NameAttribs[0].Type.String(); tp != "Point" {
+		t.Errorf("got type %s for p", tp)
+	}
+	if na := local.NameAttribs[1]; na.Attrib != ast.ConstAttrib || na.Type.String() != "integer?" {
+		t.Errorf("got %v for n", na)
+	}
+	f := stat.Stats[1].(ast.LocalFunctionStat)
+	if len(f.ParamTypes) != 2 || f.ParamTypes[0].String() != "string" || f.ParamTypes[1] != nil {
+		t.Errorf("got param types %v", f.ParamTypes)
+	}
+	if f.DotsType.String() != "number" || f.ReturnTypes.String() != "(...any)" {
+		t.Errorf("got dots type %s and return types %s", f.DotsType, f.ReturnTypes)
+	}
+	if len(f.Body.TypeAliases) != 1 {
+		t.Errorf("expected an alias in the function body")
+	}
+	m := stat.Stats[2].(ast.AssignStat).Src[0].(ast.Function)
+	if len(m.ParamTypes) != 2 || m.ParamTypes[0] != nil || m.ReturnTypes.String() != "((number) -> number)" {
+		t.Errorf("got method param types %v and return types %s", m.ParamTypes, m.ReturnTypes)
+	}
+
+	for _, src := range []string{"local x: = 1", "type T = (a, b)", "local f: {x: number = 1"} {
+		if _, err := parse(src); err == nil {
+			t.Errorf("expected error parsing %q", src)
+		}
+	}
+
+	// Annotations are not accepted by default
+	if _, err := ParseChunk(scanner.New("test", []byte("local x: number = 1"))); err == nil {
+		t.Error("expected error parsing annotation without WithTypes")
+	}
+}
+
+func TestParseExtensions(t *testing.T) {
+	parse := func(src string, ext token.Extensions) (ast.BlockStat, error) {
+		return ParseChunk(testScanner{scanner.New("test", []byte(src), scanner.WithExtensions(ext))}, WithExtensions(ext))
+	}
+	stat, err := parse("x += 1 t[1] ..= `a{x}b` while x do continue end", token.AllExtensions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ast.Stat{
+		ast.CompoundAssignStat{Dest: name("x"), Op: ops.OpAdd, Value: ast.Int{Val: 1}},
+		ast.CompoundAssignStat{
+			Dest: ast.IndexExp{Coll: name("t"), Idx: ast.Int{Val: 1}},
+			Op:   ops.OpConcat,
+			Value: ast.InterpolatedString{
+				Texts: [][]byte{[]byte("a"), []byte("b")},
+				Exps:  []ast.ExpNode{name("x")},
+			},
+		},
+		ast.WhileStat{CondStat: ast.CondStat{
+			Cond: name("x"),
+			Body: ast.BlockStat{Stats: []ast.Stat{ast.ContinueStat{}}},
+		}},
+	}
+	if !reflect.DeepEqual(stat.Stats, expected) {
+		t.Errorf("got %v", stat.Stats)
+	}
+
+	for _, src := range []string{"x, y += 1", "f() += 1", "x += 1, 2", "s = `a{}`", "s = `a{x`"} {
+		if _, err := parse(src, token.AllExtensions); err == nil {
+			t.Errorf("expected error parsing %q", src)
+		}
+	}
+
+	// Each extension must be enabled separately
+	for _, src := range []string{"x += 1", "while x do continue end", "s = `a{x}`"} {
+		if _, err := parse(src, token.NoExtensions); err == nil {
+			t.Errorf("expected error parsing %q without extensions", src)
+		}
+	}
+	if _, err := parse("continue = 1", token.ExtCompoundAssign); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// "continue" is still a valid name with ExtContinue (parsing it as a
+	// continue statement would make these fail)
+	for _, src := range []string{
+		"continue = 1",
+		"local continue = 1 continue()",
+		"while x do continue.y = 1 continue(1) continue += 1 end",
+		"repeat continue, y = 1, 2 until x",
+		"for i = 1, 2 do f(function() continue = i end) end",
+	} {
+		if _, err := parse(src, token.AllExtensions); err != nil {
+			t.Errorf("unexpected error parsing %q: %s", src, err)
+		}
+	}
+
+	stat, err = parse("for k in x do continue continue = k end", token.AllExtensions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := stat.Stats[0].(*ast.ForInStat).Body.Stats
+	if _, ok := body[0].(ast.ContinueStat); !ok || len(body) != 2 {
+		t.Errorf("got %v", body)
+	}
+}
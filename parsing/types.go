@@ -0,0 +1,199 @@
+package parsing
+
+import (
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/token"
+)
+
+// This file contains the parsing of type annotations (see WithTypes).
+
+// TypeAlias parses a type alias definition "type Name = T", recording it in
+// the enclosing block.  It assumes that t is the "type" token and nameTok the
+// token following it.
+func (p *Parser) TypeAlias(t, nameTok *token.Token) *token.Token {
+	name, next := p.Name(nameTok)
+	expectType(next, token.SgAssign, "'='")
+	tp, next := p.Type(p.Scan())
+	p.typeAliases = append(p.typeAliases, ast.TypeAlias{
+		Location: ast.MergeLocations(ast.LocFromToken(t), tp),
+		Name:     name,
+		Type:     tp,
+	})
+	return next
+}
+
+// optionalAnnotation parses ": T" if type annotations are enabled and t is a
+// colon.  Otherwise it returns a nil type.
+func (p *Parser) optionalAnnotation(t *token.Token) (ast.TypeNode, *token.Token) {
+	if !p.types || t.Type != token.SgColon {
+		return nil, t
+	}
+	return p.Type(p.Scan())
+}
+
+// Type parses a type.
+func (p *Parser) Type(t *token.Token) (ast.TypeNode, *token.Token) {
+	tp, t := p.simpleType(t)
+	return p.typeSuffix(tp, t)
+}
+
+// typeSuffix parses the "?" and "| T" which may follow the type tp.
+func (p *Parser) typeSuffix(tp ast.TypeNode, t *token.Token) (ast.TypeNode, *token.Token) {
+	tp, t = p.optionalSuffix(tp, t)
+	if t.Type != token.SgPipe {
+		return tp, t
+	}
+	types := []ast.TypeNode{tp}
+	for t.Type == token.SgPipe {
+		tp, t = p.simpleType(p.Scan())
+		tp, t = p.optionalSuffix(tp, t)
+		types = append(types, tp)
+	}
+	return ast.UnionType{
+		Location: ast.MergeLocations(types[0], tp),
+		Types:    types,
+	}, t
+}
+
+func (p *Parser) optionalSuffix(tp ast.TypeNode, t *token.Token) (ast.TypeNode, *token.Token) {
+	for t.Type == token.SgQuestion {
+		tp = ast.OptionalType{
+			Location: ast.MergeLocations(tp, ast.LocFromToken(t)),
+			Type:     tp,
+		}
+		t = p.Scan()
+	}
+	return tp, t
+}
+
+// simpleType parses a type which is not an optional type or a union.
+func (p *Parser) simpleType(t *token.Token) (ast.TypeNode, *token.Token) {
+	switch t.Type {
+	case token.KwNil, token.IDENT:
+		return ast.NamedType{Location: ast.LocFromToken(t), Name: string(t.Lit)}, p.Scan()
+	case token.SgOpenBrace:
+		return p.tableType(t)
+	case token.SgOpenBkt:
+		list, next := p.typeList(t)
+		if next.Type == token.SgArrow {
+			return p.functionType(t, list, next)
+		}
+		if len(list.Types) != 1 || list.Etc != nil {
+			tokenError(next, "'->'")
+		}
+		// A type in brackets
+		return list.Types[0], next
+	default:
+		tokenError(t, "type")
+	}
+	return nil, nil
+}
+
+// functionType parses the return types of a function type, whose parameters
+// have already been parsed.  It assumes that startTok is the "(" token starting
+// the parameters and arrowTok the "->" token.
+func (p *Parser) functionType(startTok *token.Token, params ast.TypeList, arrowTok *token.Token) (ast.TypeNode, *token.Token) {
+	returns, t := p.returnTypes(p.Scan())
+	loc := ast.LocFromTokens(startTok, arrowTok)
+	if n := len(returns.Types); n > 0 {
+		loc = ast.MergeLocations(loc, returns.Types[n-1])
+	}
+	if returns.Etc != nil {
+		loc = ast.MergeLocations(loc, returns.Etc)
+	}
+	return ast.FunctionType{Location: loc, Params: params, Returns: returns}, t
+}
+
+// returnTypes parses the types of the values returned by a function, which may
+// be a single type, "...T" or a list of types in brackets.
+func (p *Parser) returnTypes(t *token.Token) (ast.TypeList, *token.Token) {
+	switch t.Type {
+	case token.SgOpenBkt:
+		list, next := p.typeList(t)
+		if next.Type == token.SgArrow {
+			// The function returns a function
+			tp, next := p.functionType(t, list, next)
+			return ast.TypeList{Types: []ast.TypeNode{tp}}, next
+		}
+		return list, next
+	case token.SgEtc:
+		tp, next := p.Type(p.Scan())
+		return ast.TypeList{Etc: tp}, next
+	default:
+		tp, next := p.Type(t)
+		return ast.TypeList{Types: []ast.TypeNode{tp}}, next
+	}
+}
+
+// typeList parses a list of types in brackets, the last one of which may be
+// "...T".  It assumes that t is the "(" token.
+func (p *Parser) typeList(t *token.Token) (ast.TypeList, *token.Token) {
+	var list ast.TypeList
+	t = p.Scan()
+	for t.Type != token.SgCloseBkt {
+		if t.Type == token.SgEtc {
+			list.Etc, t = p.Type(p.Scan())
+			break
+		}
+		var tp ast.TypeNode
+		tp, t = p.Type(t)
+		list.Types = append(list.Types, tp)
+		if t.Type != token.SgComma {
+			break
+		}
+		t = p.Scan()
+	}
+	expectType(t, token.SgCloseBkt, "')'")
+	return list, p.Scan()
+}
+
+// tableType parses a table type: "{T}", "{[K]: V}" or "{name: T, ...}".  It
+// assumes that t is the "{" token.
+func (p *Parser) tableType(openTok *token.Token) (ast.TypeNode, *token.Token) {
+	t := p.Scan()
+	switch t.Type {
+	case token.SgOpenSquareBkt:
+		key, t := p.Type(p.Scan())
+		expectType(t, token.SgCloseSquareBkt, "']'")
+		expectType(p.Scan(), token.SgColon, "':'")
+		value, t := p.Type(p.Scan())
+		expectType(t, token.SgCloseBrace, "'}'")
+		return ast.MapType{Location: ast.LocFromTokens(openTok, t), Key: key, Value: value}, p.Scan()
+	case token.IDENT:
+		nameTok := t
+		t = p.Scan()
+		if t.Type == token.SgColon {
+			return p.recordType(openTok, nameTok)
+		}
+		// An array of named types
+		elem, t := p.typeSuffix(ast.NamedType{Location: ast.LocFromToken(nameTok), Name: string(nameTok.Lit)}, t)
+		expectType(t, token.SgCloseBrace, "'}'")
+		return ast.ArrayType{Location: ast.LocFromTokens(openTok, t), Elem: elem}, p.Scan()
+	case token.SgCloseBrace:
+		return ast.RecordType{Location: ast.LocFromTokens(openTok, t)}, p.Scan()
+	default:
+		elem, t := p.Type(t)
+		expectType(t, token.SgCloseBrace, "'}'")
+		return ast.ArrayType{Location: ast.LocFromTokens(openTok, t), Elem: elem}, p.Scan()
+	}
+}
+
+// recordType parses a record type.  It assumes that openTok is the "{" token,
+// nameTok the name of the first field, and that the colon following it has
+// been scanned.
+func (p *Parser) recordType(openTok, nameTok *token.Token) (ast.TypeNode, *token.Token) {
+	var fields []ast.RecordField
+	for {
+		tp, t := p.Type(p.Scan())
+		fields = append(fields, ast.RecordField{Name: ast.NewName(nameTok), Type: tp})
+		if t.Type == token.SgComma || t.Type == token.SgSemicolon {
+			t = p.Scan()
+		}
+		if t.Type == token.SgCloseBrace {
+			return ast.RecordType{Location: ast.LocFromTokens(openTok, t), Fields: fields}, p.Scan()
+		}
+		expectIdent(t)
+		nameTok = t
+		expectType(p.Scan(), token.SgColon, "':'")
+	}
+}
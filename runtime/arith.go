@@ -221,7 +221,7 @@ func Pow(x, y Value) (Value, bool) {
 func binaryArithFallback(t *Thread, op string, x, y Value) (Value, error) {
 	res, err, ok := metabin(t, op, x, y)
 	if ok {
-		return res, err
+		return t.compatNumber(res), err
 	}
 	return NilValue, BinaryArithmeticError(op[2:], x, y)
 }
@@ -244,7 +244,7 @@ func BinaryArithmeticError(op string, x, y Value) error {
 func unaryArithFallback(t *Thread, op string, x Value) (Value, error) {
 	res, err, ok := metaun(t, op, x)
 	if ok {
-		return res, err
+		return t.compatNumber(res), err
 	}
 	return NilValue, UnaryArithmeticError(op[2:], x)
 }
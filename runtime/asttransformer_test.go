@@ -0,0 +1,66 @@
+package runtime_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/lib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+func TestASTTransformer(t *testing.T) {
+	// Replace the __CHUNK__ name with the name of the chunk
+	chunkMacro := func(name string, chunk ast.BlockStat) (ast.BlockStat, error) {
+		return ast.Rewrite(chunk, func(n ast.Node) ast.Node {
+			if v, ok := n.(ast.Name); ok && v.Val == "__CHUNK__" {
+				return ast.String{Location: v.Location, Val: []byte(name)}
+			}
+			return n
+		}).(ast.BlockStat), nil
+	}
+	noGoto := func(name string, chunk ast.BlockStat) (ast.BlockStat, error) {
+		var err error
+		ast.Inspect(chunk, func(n ast.Node) bool {
+			if _, ok := n.(ast.GotoStat); ok {
+				err = errors.New("goto is forbidden")
+			}
+			return err == nil
+		})
+		return chunk, err
+	}
+	r := rt.New(nil, rt.WithASTTransformer(chunkMacro))
+	r.AddASTTransformer(noGoto)
+
+	clos, err := r.CompileAndLoadLuaChunk("mychunk", []byte("return load('return __CHUNK__', 'inner')() .. __CHUNK__"), rt.TableValue(r.GlobalEnv()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib.LoadAll(r)
+	v, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := v.TryString(); s != "innermychunk" {
+		t.Errorf("got %q", s)
+	}
+
+	_, err = r.CompileAndLoadLuaChunk("bad", []byte("::x:: goto x"), rt.NilValue)
+	if err == nil || err.Error() != "bad: goto is forbidden" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// Nodes made by transformers may have no location, which must not get in the
+// way of reporting compilation errors.
+func TestASTTransformerCompileError(t *testing.T) {
+	addGoto := func(name string, chunk ast.BlockStat) (ast.BlockStat, error) {
+		chunk.Stats = append(chunk.Stats, ast.GotoStat{Label: ast.Name{Val: "nowhere"}})
+		return chunk, nil
+	}
+	r := rt.New(nil, rt.WithASTTransformer(addGoto))
+	_, err := r.CompileAndLoadLuaChunk("bad", []byte("local x = 1"), rt.NilValue)
+	if err == nil || err.Error() != "bad: no visible label 'nowhere'" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
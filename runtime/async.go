@@ -0,0 +1,118 @@
+package runtime
+
+import "sync"
+
+// Pending is a continuation that a GoFunction can return when it is not able to
+// produce its results straight away, e.g. because it is waiting for some I/O to
+// complete.  The results are provided later by calling Complete or Fail (from
+// any goroutine).  Example:
+//
+//	func read(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+//		p := rt.NewPending(c)
+//		go func() {
+//			data, err := doSomeIO()
+//			if err != nil {
+//				p.Fail(err)
+//			} else {
+//				p.Complete(rt.StringValue(data))
+//			}
+//		}()
+//		return p, nil
+//	}
+//
+// When the thread running the function gets to the Pending continuation, it is
+// suspended until the Pending is done.  If the thread was resumed by another
+// thread, control goes back to it (see Thread.ResumeAsync), so a host can run
+// other Lua threads in the meantime.  Otherwise the thread simply blocks, until
+// the time limit of its runtime context is reached if there is one.
+type Pending struct {
+	next Cont
+	info *DebugInfo
+
+	once   sync.Once
+	done   chan struct{}
+	values []Value
+	err    error
+}
+
+var _ Cont = (*Pending)(nil)
+
+// NewPending returns a new Pending continuation which will push its results to
+// c.Next() when it is completed.
+func NewPending(c *GoCont) *Pending {
+	return &Pending{
+		next: c.Next(),
+		info: c.DebugInfo(),
+		done: make(chan struct{}),
+	}
+}
+
+// Complete marks p as done, with the given values as results.  If p is already
+// done, it has no effect.
+func (p *Pending) Complete(vals ...Value) {
+	p.once.Do(func() {
+		p.values = vals
+		close(p.done)
+	})
+}
+
+// Fail marks p as done, with an error.  If p is already done, it has no effect.
+func (p *Pending) Fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		close(p.done)
+	})
+}
+
+// Done returns a channel that is closed when p is done.
+func (p *Pending) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *Pending) isDone() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Push implements Cont.Push.  It does nothing as the values of p are provided
+// by calling Complete.
+func (p *Pending) Push(r *Runtime, v Value) {}
+
+// PushEtc implements Cont.PushEtc.  It does nothing as the values of p are
+// provided by calling Complete.
+func (p *Pending) PushEtc(r *Runtime, etc []Value) {}
+
+// RunInThread implements Cont.RunInThread.  It suspends the thread until p is
+// done, then passes its results on to the next continuation.
+func (p *Pending) RunInThread(t *Thread) (Cont, error) {
+	for !p.isDone() {
+		if err := t.await(p); err != nil {
+			return nil, err
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	t.Push(p.next, p.values...)
+	return p.next, nil
+}
+
+// Next implements Cont.Next.
+func (p *Pending) Next() Cont {
+	return p.next
+}
+
+// Parent implements Cont.Parent.
+func (p *Pending) Parent() Cont {
+	return p.next
+}
+
+// DebugInfo implements Cont.DebugInfo.  It returns the debug info of the Go
+// function that created p.
+func (p *Pending) DebugInfo() *DebugInfo {
+	return p.info
+}
@@ -0,0 +1,174 @@
+package runtime_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arnodel/golua/lib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// A host that runs a number of Lua threads concurrently.  The "fetch" Go
+// function returns a Pending continuation which the host completes later.
+type testAsyncHost struct {
+	r       *rt.Runtime
+	pending []*testAsyncOp
+}
+
+type testAsyncOp struct {
+	p   *rt.Pending
+	arg rt.Value
+}
+
+func (h *testAsyncHost) fetch(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+	if err := c.Check1Arg(); err != nil {
+		return nil, err
+	}
+	p := rt.NewPending(c)
+	h.pending = append(h.pending, &testAsyncOp{p: p, arg: c.Arg(0)})
+	return p, nil
+}
+
+func (h *testAsyncHost) start(src string) *rt.Thread {
+	clos, err := h.r.CompileAndLoadLuaChunk("test", []byte(src), rt.TableValue(h.r.GlobalEnv()))
+	if err != nil {
+		panic(err)
+	}
+	co := rt.NewThread(h.r)
+	co.Start(clos)
+	return co
+}
+
+func newTestAsyncHost(stdout *bytes.Buffer) *testAsyncHost {
+	r := rt.New(stdout)
+	lib.LoadAll(r)
+	h := &testAsyncHost{r: r}
+	r.SetEnvGoFunc(r.GlobalEnv(), "fetch", h.fetch, 1, false)
+	return h
+}
+
+func TestPending_eventLoop(t *testing.T) {
+	out := new(bytes.Buffer)
+	h := newTestAsyncHost(out)
+	threads := []*rt.Thread{
+		h.start(`
+			print("A", fetch("a1"))
+			print("A", pcall(fetch, "fail"))
+			return "A done"
+		`),
+		h.start(`
+			local f = coroutine.wrap(function()
+				coroutine.yield(fetch("b1"))
+				coroutine.yield(fetch("b2"))
+			end)
+			print("B", f())
+			print("B", f())
+			return "B done"
+		`),
+	}
+	main := h.r.MainThread()
+	waiting := map[*rt.Pending]*rt.Thread{}
+	var results []string
+	resume := func(co *rt.Thread) {
+		res, p, err := co.ResumeAsync(main, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != nil {
+			waiting[p] = co
+		} else {
+			results = append(results, res[0].AsString())
+		}
+	}
+	for _, co := range threads {
+		resume(co)
+	}
+	for len(h.pending) > 0 {
+		// Complete operations in reverse order, so that threads are resumed
+		// in a different order from the one they were started in.
+		op := h.pending[len(h.pending)-1]
+		h.pending = h.pending[:len(h.pending)-1]
+		arg := op.arg.AsString()
+		if arg == "fail" {
+			op.p.Fail(errors.New("failed"))
+		} else {
+			op.p.Complete(rt.StringValue(arg + "!"))
+		}
+		<-op.p.Done()
+		co := waiting[op.p]
+		delete(waiting, op.p)
+		resume(co)
+	}
+	if len(waiting) != 0 {
+		t.Fatalf("threads still waiting: %d", len(waiting))
+	}
+	const expectedOut = "B\tb1!\nB\tb2!\nA\ta1!\nA\tfalse\ttest:3: failed\n"
+	if out.String() != expectedOut {
+		t.Errorf("expected output %q, got %q", expectedOut, out.String())
+	}
+	if len(results) != 2 || results[0] != "B done" || results[1] != "A done" {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestPending_mainThread(t *testing.T) {
+	// When there is no thread to yield to, the thread blocks until the
+	// operation completes.
+	r := rt.New(nil)
+	lib.LoadAll(r)
+	r.SetEnvGoFunc(r.GlobalEnv(), "fetch", func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		p := rt.NewPending(c)
+		arg := c.Arg(0)
+		go p.Complete(arg, arg)
+		return p, nil
+	}, 1, false)
+	clos, err := r.CompileAndLoadLuaChunk("test", []byte(`
+		local f = coroutine.wrap(function(x) return fetch(x) end)
+		local a, b = f(21)
+		return a + b
+	`), rt.TableValue(r.GlobalEnv()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := v.TryInt(); !ok || n != 42 {
+		t.Errorf("expected 42, got %v", v)
+	}
+}
+
+func TestPending_mainThreadTimeLimit(t *testing.T) {
+	if !rt.QuotasAvailable {
+		t.Skip("quotas are not available")
+	}
+	// A thread blocked waiting for an operation which does not complete is
+	// stopped when the time limit of its context is reached.
+	r := rt.New(nil)
+	never := r.SetEnvGoFunc(r.GlobalEnv(), "never", func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return rt.NewPending(c), nil
+	}, 0, false)
+	// Waiting is bounded by the time limit, which makes it timesafe.
+	rt.SolemnlyDeclareCompliance(rt.ComplyTimeSafe, never)
+	clos, err := r.CompileAndLoadLuaChunk("test", []byte(`never()`), rt.TableValue(r.GlobalEnv()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	th := r.MainThread()
+	start := time.Now()
+	_, err = th.CallContext(rt.RuntimeContextDef{
+		HardLimits: rt.RuntimeResources{Millis: 50},
+	}, func() error {
+		_, err := rt.Call1(th, rt.FunctionValue(clos))
+		return err
+	})
+	if _, ok := err.(rt.ContextTerminationError); !ok {
+		t.Errorf("expected a context termination error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the thread blocked for %s", elapsed)
+	}
+}
@@ -22,6 +22,13 @@ func newCell(v Value) Cell {
 	return Cell{&v}
 }
 
+// NewCell returns a new Cell instance containing the given value.  It allows
+// building closures outside of the runtime, e.g. when copying a function from
+// one Runtime to another.
+func NewCell(v Value) Cell {
+	return newCell(v)
+}
+
 // get returns the value that the cell c contains.
 func (c Cell) get() Value {
 	return *c.ref
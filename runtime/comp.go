@@ -187,6 +187,14 @@ func le(t *Thread, x, y Value) (bool, error) {
 	if ok {
 		return Truth(res), err
 	}
+	if t.luaVersion <= Lua53 {
+		// Before Lua 5.4, a <= b is computed as not (b < a) if there is no
+		// __le metamethod.
+		res, err, ok = metabin(t, "__lt", y, x)
+		if ok {
+			return !Truth(res), err
+		}
+	}
 	return false, compareError(x, y)
 }
 
@@ -0,0 +1,138 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/arnodel/golua/ast"
+)
+
+// A LuaVersion is a version of Lua whose behaviour a Runtime emulates.  By
+// default a Runtime implements Lua 5.4.  Older versions are supported to help
+// running legacy scripts; they change the following.
+//
+// With Lua 5.3 and older, comparing values with "<=" falls back to the "__lt"
+// metamethod (as "not (b < a)") when they have no "__le" metamethod.
+//
+// With Lua 5.1, numbers are floats: number literals are compiled to floats, and
+// integers are converted to floats when they are passed to functions (so values
+// returned by library functions are floats) and when they result from the
+// length operator or arithmetic on strings or with metamethods.  However
+// integers stored in tables by Go code (e.g. the "n" field set by table.pack)
+// and the results of bitwise operators (which Lua 5.1 does not have) are not
+// converted.
+//
+// Libraries also provide the functions that were removed after these versions
+// (see package compatlib).
+type LuaVersion uint8
+
+// Supported Lua versions.
+const (
+	Lua51 LuaVersion = 51
+	Lua52 LuaVersion = 52
+	Lua53 LuaVersion = 53
+	Lua54 LuaVersion = 54
+)
+
+// ParseLuaVersion returns the LuaVersion with the given name, e.g. "5.1".
+func ParseLuaVersion(name string) (LuaVersion, error) {
+	for _, v := range []LuaVersion{Lua51, Lua52, Lua53, Lua54} {
+		if v.String() == name {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported Lua version: %s", name)
+}
+
+// String returns the name of the version, e.g. "5.1".
+func (v LuaVersion) String() string {
+	return fmt.Sprintf("%d.%d", v/10, v%10)
+}
+
+// WithLuaVersion sets the version of Lua emulated by the Runtime.  The default
+// is Lua54.
+func WithLuaVersion(v LuaVersion) RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.luaVersion = v
+	}
+}
+
+// LuaVersion returns the version of Lua emulated by the runtime.
+func (r *Runtime) LuaVersion() LuaVersion {
+	return r.luaVersion
+}
+
+// SetLuaVersion sets the version of Lua emulated by the runtime.  It should be
+// called before loading libraries or compiling any code.
+func (r *Runtime) SetLuaVersion(v LuaVersion) {
+	r.luaVersion = v
+}
+
+// floatLiterals returns a copy of the chunk where integer literals are replaced
+// with float literals, as Lua 5.1 has no integers.
+func floatLiterals(chunk ast.BlockStat) ast.BlockStat {
+	return ast.Rewrite(chunk, func(n ast.Node) ast.Node {
+		if i, ok := n.(ast.Int); ok {
+			return ast.Float{Location: i.Location, Val: float64(i.Val)}
+		}
+		return n
+	}).(ast.BlockStat)
+}
+
+// compatNumber returns v converted for the emulated version of Lua, i.e. with
+// Lua 5.1 an integer is converted to a float.
+func (r *Runtime) compatNumber(v Value) Value {
+	if r.luaVersion <= Lua51 {
+		if n, ok := v.TryInt(); ok {
+			return FloatValue(float64(n))
+		}
+	}
+	return v
+}
+
+// envUpvalue returns the index of the "_ENV" upvalue of the closure, or -1 if
+// it has none (which means it does not access global variables).
+func (c *Closure) envUpvalue() int {
+	for i, name := range c.UpNames {
+		if name == "_ENV" {
+			return i
+		}
+	}
+	return -1
+}
+
+// Env returns the value of the "_ENV" upvalue of the closure, i.e. the table
+// where its global variables are looked up.  It returns false if the closure
+// has no such upvalue.
+func (c *Closure) Env() (Value, bool) {
+	i := c.envUpvalue()
+	if i < 0 {
+		return NilValue, false
+	}
+	return c.GetUpvalue(i), true
+}
+
+// SetEnv replaces the "_ENV" upvalue of the closure with a new one containing
+// env, so that the global variables of the closure are looked up in env,
+// without affecting other functions sharing the upvalue.  This emulates Lua
+// 5.1 setfenv().  It returns false if the closure has no "_ENV" upvalue.
+func (c *Closure) SetEnv(env Value) bool {
+	i := c.envUpvalue()
+	if i < 0 {
+		return false
+	}
+	c.Upvalues[i] = newCell(env)
+	return true
+}
+
+// SetEnv sets the environment of the closure being run by the continuation
+// (see Closure.SetEnv), including for the rest of the current call.
+func (c *LuaCont) SetEnv(env Value) bool {
+	i := c.envUpvalue()
+	if i < 0 {
+		return false
+	}
+	cell := newCell(env)
+	c.Upvalues[i] = cell
+	c.cells[i] = cell
+	return true
+}
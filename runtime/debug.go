@@ -5,12 +5,109 @@ import "fmt"
 // DebugInfo contains info about a continuation that can be looked at for
 // debugging purposes (and tracebacks).
 type DebugInfo struct {
-	Source      string
-	Name        string
-	CurrentLine int32
+	Source        string
+	Name          string
+	CurrentLine   int32
+	CurrentColumn int32 // 0 if unknown
 }
 
 // String formats the data contained in DebugInfo in a human-readable way.
 func (i DebugInfo) String() string {
+	if i.CurrentColumn > 0 {
+		return fmt.Sprintf("file=%s func=%s line=%d col=%d", i.Source, i.Name, i.CurrentLine, i.CurrentColumn)
+	}
 	return fmt.Sprintf("file=%s func=%s line=%d", i.Source, i.Name, i.CurrentLine)
 }
+
+// FrameKind tells whether a Frame is a call to a Lua function or a Go function.
+type FrameKind uint8
+
+const (
+	LuaFrame FrameKind = iota // A call to a Lua function
+	GoFrame                   // A call to a Go function
+)
+
+// String returns "lua" or "go".
+func (k FrameKind) String() string {
+	if k == GoFrame {
+		return "go"
+	}
+	return "lua"
+}
+
+// MarshalText implements encoding.TextMarshaler so that a FrameKind is
+// encoded as a string in JSON.
+func (k FrameKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// A Frame is a function call in a traceback.  Lines and columns are 0 if
+// unknown, which is always the case for Go functions.
+type Frame struct {
+	Kind     FrameKind `json:"kind"`
+	Function string    `json:"function"`
+	Source   string    `json:"source"`
+	Line     int       `json:"line,omitempty"`
+	Column   int       `json:"column,omitempty"`
+}
+
+// Frames returns the call stack of the continuation c as a slice of frames,
+// innermost call first.  It is the structured counterpart of Traceback.
+func (r *Runtime) Frames(c Cont) []Frame {
+	var frames []Frame
+	for ; c != nil; c = c.Parent() {
+		info := c.DebugInfo()
+		if info == nil {
+			continue
+		}
+		frame := Frame{Kind: frameKind(c), Function: info.Name, Source: info.Source}
+		if info.CurrentLine > 0 {
+			frame.Line = int(info.CurrentLine)
+			frame.Column = int(info.CurrentColumn)
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// frameKind returns the kind of the function call c stands for.
+func frameKind(c Cont) FrameKind {
+	for {
+		switch cc := c.(type) {
+		case *LuaCont:
+			return LuaFrame
+		case *GoCont:
+			if cc.source != "" {
+				// It was compiled from Lua
+				return LuaFrame
+			}
+			return GoFrame
+		case *Resumption:
+			// It stands for the function it resumes
+			c = cc.cont
+		case *Termination:
+			// It stands for its parent
+			if cc.parent == nil {
+				return GoFrame
+			}
+			c = cc.parent
+		case *messageHandlerCont:
+			// It stands for the continuation where the error occurred
+			c = cc.c
+		default:
+			return GoFrame
+		}
+	}
+}
+
+// position returns the position in the source as "source:line" or
+// "source:line:column", the column being included only if withColumn is true.
+func (i *DebugInfo) position(withColumn bool) string {
+	if i.CurrentLine <= 0 {
+		return i.Source
+	}
+	if withColumn && i.CurrentColumn > 0 {
+		return fmt.Sprintf("%s:%d:%d", i.Source, i.CurrentLine, i.CurrentColumn)
+	}
+	return fmt.Sprintf("%s:%d", i.Source, i.CurrentLine)
+}
@@ -10,11 +10,19 @@ import (
 // error has a message and a context, which is a slice of continuations.  There
 // is no call stack, but you can imagine you "unwind" the call stack by
 // iterating over this slice.
+//
+// If the error was made from a Go error (e.g. returned by a GoFunction), that
+// error can be reached with errors.Is / errors.As.  If the runtime was created
+// with WithErrorTracebacks, the error also records the call stack where it was
+// raised (see Frames).
 type Error struct {
 	message Value
 	handled bool
 	lineno  int
+	column  int
 	source  string
+	cause   error   // The Go error this error was made from, if any
+	frames  []Frame // The traceback, if recorded
 }
 
 // NewError returns a new error with the given message and no context.
@@ -26,6 +34,12 @@ func newHandledError(message Value) *Error {
 	return &Error{message: message, handled: true}
 }
 
+// handledWith returns a handled error with the given message, keeping the Go
+// error and the traceback of e.
+func (e *Error) handledWith(message Value) *Error {
+	return &Error{message: message, handled: true, cause: e.cause, frames: e.frames}
+}
+
 // AsError check if err can be converted to an *Error and returns that if
 // successful.
 func AsError(err error) (rtErr *Error, ok bool) {
@@ -42,7 +56,7 @@ func ToError(err error) *Error {
 	if ok {
 		return rtErr
 	}
-	return NewError(StringValue(err.Error()))
+	return &Error{message: StringValue(err.Error()), cause: err}
 }
 
 // ErrorValue extracts a Value from err.  If err is an *Error then it returns
@@ -68,6 +82,8 @@ func (e *Error) AddContext(c Cont, depth int) *Error {
 		lineno:  -1,
 		source:  "?",
 		message: e.message,
+		cause:   e.cause,
+		frames:  e.frames,
 	}
 	if depth == 0 {
 		return e
@@ -79,7 +95,7 @@ func (e *Error) AddContext(c Cont, depth int) *Error {
 		}
 	} else {
 		for c != nil {
-			if _, ok := c.(*LuaCont); ok {
+			if frameKind(c) == LuaFrame {
 				break
 			}
 			c = c.Parent()
@@ -92,13 +108,38 @@ func (e *Error) AddContext(c Cont, depth int) *Error {
 	if info == nil {
 		return e
 	}
-	if info.CurrentLine != 0 {
-		e.lineno = int(info.CurrentLine)
+	var column int
+	if lc, ok := c.(*LuaCont); ok && lc.errorColumns {
+		column = int(info.CurrentColumn)
+	}
+	return e.addLineContext(info.Source, int(info.CurrentLine), column)
+}
+
+// AddLineContext returns a new error with the lineno / source fields set to
+// the given values if not already set.  It is useful for code which does not
+// run in a LuaCont but knows the source line it is executing.
+func (e *Error) AddLineContext(source string, lineno int) *Error {
+	if e.lineno != 0 || e.handled {
+		return e
+	}
+	return (&Error{lineno: -1, message: e.message, cause: e.cause, frames: e.frames}).addLineContext(source, lineno, 0)
+}
+
+// addLineContext sets the source, line and column of the error and prefixes
+// its message with them if it is a string.  The column is omitted if it is 0.
+func (e *Error) addLineContext(source string, lineno, column int) *Error {
+	if lineno != 0 {
+		e.lineno = lineno
 	}
-	e.source = info.Source
+	e.source = source
+	e.column = column
 	s, ok := e.message.TryString()
 	if ok && e.lineno > 0 {
-		e.message = StringValue(fmt.Sprintf("%s:%d: %s", e.source, e.lineno, s))
+		if e.column > 0 {
+			e.message = StringValue(fmt.Sprintf("%s:%d:%d: %s", e.source, e.lineno, e.column, s))
+		} else {
+			e.message = StringValue(fmt.Sprintf("%s:%d: %s", e.source, e.lineno, s))
+		}
 	}
 	return e
 }
@@ -123,6 +164,20 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("error: %s", s)
 }
 
+// Unwrap returns the Go error that e was made from, or nil if there is none.
+// It allows errors.Is and errors.As to look at the errors returned by Go
+// functions called from Lua.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Frames returns the call stack at the point where the error was raised,
+// innermost call first.  It is only recorded if the runtime was created with
+// the WithErrorTracebacks option, otherwise it returns nil.
+func (e *Error) Frames() []Frame {
+	return e.frames
+}
+
 // Traceback produces a traceback string of the continuation, requiring memory
 // for the string.
 func (r *Runtime) Traceback(pfx string, c Cont) string {
@@ -140,11 +195,7 @@ func (r *Runtime) Traceback(pfx string, c Cont) string {
 				r.RequireBytes(1)
 				sb.WriteByte('\n')
 			}
-			sourceInfo := info.Source
-			if info.CurrentLine > 0 {
-				sourceInfo = fmt.Sprintf("%s:%d", sourceInfo, info.CurrentLine)
-			}
-			line := fmt.Sprintf("in function %s (file %s)", info.Name, sourceInfo)
+			line := fmt.Sprintf("in function %s (file %s)", info.Name, info.position(r.errorColumns))
 			r.RequireBytes(len(line))
 			sb.WriteString(line)
 			needNewline = true
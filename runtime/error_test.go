@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -46,6 +47,7 @@ func TestAsError(t *testing.T) {
 }
 
 func TestToError(t *testing.T) {
+	errHello, errHi := errors.New("hello"), errors.New("hi")
 	tests := []struct {
 		name string
 		arg  error
@@ -63,13 +65,13 @@ func TestToError(t *testing.T) {
 		},
 		{
 			name: "non nil *Error",
-			arg:  errors.New("hello"),
-			want: NewError(StringValue("hello")),
+			arg:  errHello,
+			want: &Error{message: StringValue("hello"), cause: errHello},
 		},
 		{
 			name: "string error",
-			arg:  errors.New("hi"),
-			want: NewError(StringValue("hi")),
+			arg:  errHi,
+			want: &Error{message: StringValue("hi"), cause: errHi},
 		},
 		// TODO: Add test cases.
 	}
@@ -81,3 +83,17 @@ func TestToError(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorUnwrap(t *testing.T) {
+	errBase := errors.New("base")
+	err := ToError(fmt.Errorf("wrapped: %w", errBase)).AddLineContext("test", 3)
+	if !errors.Is(err, errBase) {
+		t.Error("expected err to wrap errBase")
+	}
+	if s, _ := err.Value().TryString(); s != "test:3: wrapped: base" {
+		t.Errorf("wrong message %q", s)
+	}
+	if errors.Unwrap(NewError(StringValue("x"))) != nil {
+		t.Error("expected nothing to unwrap")
+	}
+}
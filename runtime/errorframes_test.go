@@ -0,0 +1,94 @@
+package runtime_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+type codedError struct {
+	code int
+}
+
+func (e codedError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+const errorFramesSrc = `local function inner() fail() end
+local function outer() inner() end
+local ok, msg = pcall(outer)
+assert(not ok and msg == "test:1: failed: code 42", msg)
+call(outer)
+`
+
+func runErrorFrames(t *testing.T, r *rt.Runtime) error {
+	lib.LoadAll(r)
+	env := r.GlobalEnv()
+	r.SetEnvGoFunc(env, "fail", func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return nil, fmt.Errorf("failed: %w", codedError{42})
+	}, 0, false)
+	r.SetEnvGoFunc(env, "call", func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		if err := rt.Call(t, c.Arg(0), nil, rt.NewTerminationWith(c, 0, false)); err != nil {
+			return nil, err
+		}
+		return c.Next(), nil
+	}, 1, false)
+	clos, err := r.CompileAndLoadLuaChunk("test", []byte(errorFramesSrc), rt.TableValue(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rt.Call(r.MainThread(), rt.FunctionValue(clos), nil, rt.NewTerminationWith(nil, 0, false))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	return err
+}
+
+func TestErrorFrames(t *testing.T) {
+	err := runErrorFrames(t, rt.New(nil, rt.WithErrorTracebacks()))
+
+	// The Lua error value is unchanged
+	if s, _ := rt.ErrorValue(err).TryString(); s != "test:1: failed: code 42" {
+		t.Errorf("wrong error value %q", s)
+	}
+
+	// The Go error returned by fail() can be inspected
+	var coded codedError
+	if !errors.As(err, &coded) || coded.code != 42 {
+		t.Errorf("expected a codedError, got %v", err)
+	}
+	if !errors.Is(err, codedError{42}) {
+		t.Error("expected err to be codedError{42}")
+	}
+
+	rtErr, ok := rt.AsError(err)
+	if !ok {
+		t.Fatalf("expected *rt.Error, got %T", err)
+	}
+	data, jsonErr := json.Marshal(rtErr.Frames())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	const want = `[{"kind":"go","function":"fail","source":"[Go]"},` +
+		`{"kind":"lua","function":"inner","source":"test","line":1,"column":24},` +
+		`{"kind":"lua","function":"outer","source":"test","line":2,"column":24},` +
+		`{"kind":"go","function":"call","source":"[Go]"},` +
+		`{"kind":"lua","function":"\u003cmain chunk\u003e","source":"test","line":5,"column":1}]`
+	if string(data) != want {
+		t.Errorf("wrong frames:\n%s", data)
+	}
+}
+
+func TestErrorFramesDisabled(t *testing.T) {
+	err := runErrorFrames(t, rt.New(nil))
+	if rtErr, _ := rt.AsError(err); rtErr.Frames() != nil {
+		t.Errorf("expected no frames, got %v", rtErr.Frames())
+	}
+	if !errors.Is(err, codedError{42}) {
+		t.Error("expected err to be codedError{42}")
+	}
+}
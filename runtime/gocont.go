@@ -13,6 +13,11 @@ type GoCont struct {
 	args  []Value
 	etc   *[]Value
 	nArgs int
+
+	// Only used by functions compiled from Lua (see GoFunction.SetLuaSource)
+	line      int32 // Line currently executing
+	tailCall  bool  // True if the function ended with a tail call
+	suspended bool  // True if the function is waiting for a call to return
 }
 
 var _ Cont = (*GoCont)(nil)
@@ -41,6 +46,7 @@ func NewGoCont(t *Thread, f *GoFunction, next Cont) *GoCont {
 
 // Push implements Cont.Push.
 func (c *GoCont) Push(r *Runtime, v Value) {
+	v = r.compatNumber(v)
 	if c.nArgs < len(c.args) {
 		c.args[c.nArgs] = v
 		c.nArgs++
@@ -101,13 +107,26 @@ func (c *GoCont) RunInThread(t *Thread) (next Cont, err error) {
 		return nil, errors.New("stack overflow")
 	}
 	next, err = c.f(t, c)
-	_ = t.triggerReturn(t, c)
+	return c.exit(t, next, err)
+}
+
+// exit is called when c's function returns next and err, which it returns.
+// The function may have been suspended by a call, in which case c is needed to
+// resume it so it is kept.  Otherwise the function has finished, the return
+// hook is called and c is given back to the pool.
+func (c *GoCont) exit(t *Thread, next Cont, err error) (Cont, error) {
+	if c.suspended {
+		return next, err
+	}
+	if !c.tailCall {
+		_ = t.triggerReturn(t, c)
+	}
 
 	if err != nil {
 		// If there is an error, c is still potentially needed for error
 		// handling, so do not return it to the pool.  It will get GCed when no
 		// longer referenced, so it's OK.
-		return
+		return next, err
 	}
 	if c.args != nil {
 		t.ReleaseArrSize(unsafe.Sizeof(Value{}), c.nArgs)
@@ -115,7 +134,7 @@ func (c *GoCont) RunInThread(t *Thread) (next Cont, err error) {
 	}
 	t.ReleaseSize(unsafe.Sizeof(GoCont{}))
 	t.goContPool.release(c)
-	return
+	return next, nil
 }
 
 // Next returns the next continuation.
@@ -128,9 +147,48 @@ func (c *GoCont) Parent() Cont {
 	return c.next
 }
 
+// SetLine records that the function compiled from Lua is now executing the
+// given line of its source, calling the line debug hook if it is enabled.
+func (c *GoCont) SetLine(t *Thread, line int32) error {
+	c.line = line
+	return t.triggerLine(t, c, line)
+}
+
+// TailCall returns next, calling the tail call debug hook if it is enabled.
+// Functions compiled from Lua return its result to make a tail call.
+func (c *GoCont) TailCall(t *Thread, next Cont) (Cont, error) {
+	c.tailCall = true
+	if t.areFlagsEnabled(HookFlagCall | HookFlagReturn) {
+		_ = t.triggerTailCall(t, next)
+	}
+	return next, nil
+}
+
+// Call returns next, calling the call debug hook if it is enabled.  Functions
+// compiled from Lua return its result to make a call whose results are received
+// by a Resumption, so that the call runs in the thread's loop rather than
+// nesting Go calls.  The function is suspended until the Resumption runs.
+func (c *GoCont) Call(t *Thread, next Cont) (Cont, error) {
+	c.suspended = true
+	if t.areFlagsEnabled(HookFlagCall | HookFlagReturn) {
+		_ = t.triggerCall(t, next)
+	}
+	return next, nil
+}
+
 // DebugInfo returns c's debug info.
 func (c *GoCont) DebugInfo() *DebugInfo {
 	name := c.name
+	if c.source != "" {
+		if name == "" {
+			name = "<lua function>"
+		}
+		return &DebugInfo{
+			Source:      c.source,
+			CurrentLine: c.line,
+			Name:        name,
+		}
+	}
 	if name == "" {
 		name = "<go function>"
 	}
@@ -7,6 +7,7 @@ type GoFunction struct {
 	f           GoFunctionFunc
 	safetyFlags ComplianceFlags
 	name        string
+	source      string // Set for functions compiled from Lua (see SetLuaSource)
 	nArgs       int
 	hasEtc      bool
 }
@@ -28,6 +29,13 @@ func (f *GoFunction) Continuation(t *Thread, next Cont) Cont {
 	return NewGoCont(t, f, next)
 }
 
+// SetLuaSource records that f was compiled from Lua code in the given source.
+// Its continuations then report the source and the line set with GoCont.SetLine
+// in errors and debug info, and are treated as Lua functions in tracebacks.
+func (f *GoFunction) SetLuaSource(source string) {
+	f.source = source
+}
+
 // SolemnlyDeclareCompliance adds compliance flags to f.  See quotas.md for
 // details about compliance flags.
 func (f *GoFunction) SolemnlyDeclareCompliance(flags ComplianceFlags) {
@@ -23,6 +23,17 @@ type mixedTable struct {
 	*array
 }
 
+func newMixedTable(narr, nhash int) *mixedTable {
+	t := &mixedTable{}
+	if narr > 0 {
+		t.array = &array{values: make([]Value, narr)}
+	}
+	if nhash > 0 {
+		t.hashTable = newHashTable(uintptr(nhash))
+	}
+	return t
+}
+
 // Return v such that k => v, else return nil.
 func (t *mixedTable) get(k Value) Value {
 	i, ok := ToIntNoString(k)
@@ -297,6 +308,26 @@ func (t *hashTable) find(k Value) Value {
 	return it.value
 }
 
+// findSlotCached is like findSlot but first tries the slot at index *hint,
+// which is updated to the index of the slot containing k if it is found.  The
+// hint is an inline cache, e.g. for an opcode which always looks up the same
+// key.
+func (t *hashTable) findSlotCached(k Value, hint *uint32) *hashTableSlot {
+	if t == nil {
+		return nil
+	}
+	if i := int(*hint); i < len(t.slots) {
+		if it := &t.slots[i]; it.key.Equals(k) {
+			return it
+		}
+	}
+	it, i := findSlot(t.slots, (1<<t.base)-1, k)
+	if it != nil {
+		*hint = uint32(i)
+	}
+	return it
+}
+
 func (t *hashTable) removeKey(k Value) (wasSet bool) {
 	if t == nil {
 		return false
@@ -308,6 +339,19 @@ func (t *hashTable) full() bool {
 	return t == nil || t.nextFree == noNextFree
 }
 
+// newHashTable returns an empty hash table with at least sz slots.
+func newHashTable(sz uintptr) *hashTable {
+	var (
+		base          = uint8(bits.Len(uint(sz - 1)))
+		n     uintptr = 1 << base
+	)
+	return &hashTable{
+		slots:    make([]hashTableSlot, n),
+		nextFree: n - 1,
+		base:     base,
+	}
+}
+
 func (t *hashTable) grow() *hashTable {
 	if t == nil {
 		return &hashTable{
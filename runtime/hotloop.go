@@ -0,0 +1,554 @@
+package runtime
+
+import (
+	"unsafe"
+
+	"github.com/arnodel/golua/code"
+)
+
+// Hot loops
+//
+// The interpreter counts how many times each backward jump in a function is
+// taken.  When that reaches the runtime's hot loop threshold, the opcodes in
+// the body of the loop (i.e. from the jump target to the jump) are compiled to
+// a slice of Go closures, one per opcode.  Subsequent iterations of the loop
+// run these closures instead of decoding each opcode in turn.  Where possible,
+// an opcode and the conditional jump that follows it are fused into a single
+// closure (e.g. a comparison and the branch testing its result).
+//
+// Only opcodes which cannot call Lua or Go functions can be compiled.  The
+// closures guard the types of their operands: e.g. an addition only succeeds
+// if both operands are numbers.  When a guard fails, the closure leaves all
+// registers untouched and the compiled loop exits so that the interpreter
+// resumes at that opcode (where metamethods, errors, etc. are dealt with).  A
+// loop containing an opcode that cannot be compiled is never compiled.
+
+// A loopStep executes one opcode (or a few fused ones).  It returns the pc of
+// the next opcode to execute, or false if its guard failed.
+type loopStep func(regs []Value, cells []Cell) (int16, bool)
+
+// A compiledLoop is the compiled body of a hot loop.
+type compiledLoop struct {
+	start, end int16      // The loop body is opcodes [start, end]
+	steps      []loopStep // The step for the opcode at pc is steps[pc - start]
+}
+
+// A hotLoop tracks how often a backward jump is taken.
+type hotLoop struct {
+	count    uint
+	compiled *compiledLoop
+	failed   bool // Set if the loop cannot be compiled
+}
+
+// run executes the compiled loop from its start.  It returns the pc of the
+// opcode the interpreter should carry on from, which is either outside the
+// loop or an opcode whose guard failed.
+func (l *compiledLoop) run(t *Thread, regs []Value, cells []Cell) int16 {
+	pc := l.start
+	steps := l.steps
+	for {
+		t.RequireCPU(1)
+		next, ok := steps[pc-l.start](regs, cells)
+		if !ok {
+			return pc
+		}
+		if next < l.start || next > l.end {
+			return next
+		}
+		pc = next
+	}
+}
+
+// hotLoop records that the backward jump at pc to target has been taken and
+// returns the compiled loop for it, or nil if it has not been compiled (yet).
+func (c *Code) hotLoop(t *Thread, target, pc int16) *compiledLoop {
+	if c.loops == nil {
+		t.RequireArrSize(unsafe.Sizeof(hotLoop{}), len(c.code))
+		c.loops = make([]hotLoop, len(c.code))
+	}
+	loop := &c.loops[pc]
+	if loop.compiled != nil || loop.failed {
+		return loop.compiled
+	}
+	loop.count++
+	if loop.count < t.hotLoopThreshold {
+		return nil
+	}
+	loop.compiled = c.compileLoop(t, target, pc)
+	loop.failed = loop.compiled == nil
+	return loop.compiled
+}
+
+// compileLoop compiles the opcodes [start, end].  It returns nil if one of the
+// opcodes is not supported.
+func (c *Code) compileLoop(t *Thread, start, end int16) *compiledLoop {
+	steps := make([]loopStep, end-start+1)
+	for pc := start; pc <= end; pc++ {
+		step := c.compileFusedStep(pc)
+		if step == nil {
+			step = c.compileStep(t, pc)
+		}
+		if step == nil {
+			return nil
+		}
+		steps[pc-start] = step
+	}
+	t.RequireArrSize(unsafe.Sizeof(loopStep(nil)), len(steps))
+	return &compiledLoop{start: start, end: end, steps: steps}
+}
+
+// compileFusedStep returns a step for the opcode at pc fused with the next
+// opcode if that is a conditional jump on its result.  If that is not possible
+// it returns nil.
+func (c *Code) compileFusedStep(pc int16) loopStep {
+	if int(pc)+1 >= len(c.code) {
+		return nil
+	}
+	opcode, jump := c.code[pc], c.code[pc+1]
+	if jump.TypePfx() != code.Type5Pfx || jump.GetJ() != code.OpJumpIf {
+		return nil
+	}
+	test := jump.GetA()
+	if test.IsCell() {
+		return nil
+	}
+	jumpTo := pc + 1 + int16(jump.GetOffset())
+	noJumpTo := pc + 2
+	if !jump.GetF() {
+		jumpTo, noJumpTo = noJumpTo, jumpTo
+	}
+	// The step jumps to jumpTo if the register tested by the jump is true and
+	// to noJumpTo otherwise.
+	switch {
+	case opcode.HasType1():
+		dst := opcode.GetA()
+		if dst != test {
+			return nil
+		}
+		cmp := numComparison(opcode.GetX())
+		if cmp == nil {
+			return nil
+		}
+		x, y, i := opcode.GetB(), opcode.GetC(), dst.Idx()
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			res, ok := cmp(getReg(regs, cells, x), getReg(regs, cells, y))
+			if !ok {
+				return 0, false
+			}
+			regs[i] = BoolValue(res)
+			if res {
+				return jumpTo, true
+			}
+			return noJumpTo, true
+		}
+	case opcode.TypePfx() == code.Type6Pfx && opcode.HasType6b():
+		startReg, stopReg := opcode.GetA(), opcode.GetB()
+		if startReg != test {
+			return nil
+		}
+		step := int64(opcode.GetM().ToInt8())
+		i := startReg.Idx()
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			n, ok := regs[i].TryInt()
+			if !ok {
+				return 0, false
+			}
+			m, ok := getReg(regs, cells, stopReg).TryInt()
+			if !ok {
+				return 0, false
+			}
+			next := n + step
+			if step > 0 && (next > m || next < n) || step < 0 && (next < m || next > n) {
+				regs[i] = NilValue
+				return noJumpTo, true
+			}
+			regs[i] = IntValue(next)
+			return jumpTo, true
+		}
+	}
+	return nil
+}
+
+// compileStep returns a step for the opcode at pc, or nil if that is not
+// possible.
+func (c *Code) compileStep(t *Thread, pc int16) loopStep {
+	opcode := c.code[pc]
+	next := pc + 1
+	if opcode.HasType1() {
+		dst, x, y := opcode.GetA(), opcode.GetB(), opcode.GetC()
+		if cmp := numComparison(opcode.GetX()); cmp != nil {
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				res, ok := cmp(getReg(regs, cells, x), getReg(regs, cells, y))
+				if !ok {
+					return 0, false
+				}
+				setReg(regs, cells, dst, BoolValue(res))
+				return next, true
+			}
+		}
+		op := numBinOp(opcode.GetX())
+		if op == nil {
+			return nil
+		}
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			res, ok := op(getReg(regs, cells, x), getReg(regs, cells, y))
+			if !ok {
+				return 0, false
+			}
+			setReg(regs, cells, dst, res)
+			return next, true
+		}
+	}
+	switch opcode.TypePfx() {
+	case code.Type0Pfx:
+		if opcode.GetF() {
+			return nil
+		}
+		dst := opcode.GetA()
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			setReg(regs, cells, dst, NilValue)
+			return next, true
+		}
+	case code.Type2Pfx:
+		reg, coll, idx := opcode.GetA(), opcode.GetB(), opcode.GetC()
+		if opcode.GetF() {
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				if !rawReset(getReg(regs, cells, coll), getReg(regs, cells, idx), getReg(regs, cells, reg)) {
+					return 0, false
+				}
+				return next, true
+			}
+		}
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			val, ok := rawIndex(getReg(regs, cells, coll), getReg(regs, cells, idx))
+			if !ok {
+				return 0, false
+			}
+			setReg(regs, cells, reg, val)
+			return next, true
+		}
+	case code.Type3Pfx:
+		if opcode.GetF() {
+			return nil
+		}
+		n := opcode.GetN()
+		var val Value
+		switch opcode.GetY() {
+		case code.OpInt16:
+			val = IntValue(int64(int16(n)))
+		case code.OpStr2:
+			val = StringValue(string(code.Lit16(n).ToStr2()))
+		case code.OpK:
+			val = c.consts[n]
+		default:
+			return nil
+		}
+		dst := opcode.GetA()
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			setReg(regs, cells, dst, val)
+			return next, true
+		}
+	case code.Type4Pfx:
+		if opcode.GetF() {
+			return nil
+		}
+		dst := opcode.GetA()
+		if opcode.HasType4a() {
+			op := numUnOp(opcode.GetUnOp())
+			if op == nil {
+				return nil
+			}
+			src := opcode.GetB()
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				res, ok := op(getReg(regs, cells, src))
+				if !ok {
+					return 0, false
+				}
+				setReg(regs, cells, dst, res)
+				return next, true
+			}
+		}
+		var val Value
+		switch code.UnOpK(opcode.GetUnOp()) {
+		case code.OpNil:
+			val = NilValue
+		case code.OpStr0:
+			val = StringValue("")
+		case code.OpStr1:
+			val = StringValue(string(opcode.GetL().ToStr1()))
+		case code.OpBool:
+			val = BoolValue(opcode.GetL().ToBool())
+		case code.OpClear:
+			i := dst.Idx()
+			if dst.IsCell() {
+				return func(regs []Value, cells []Cell) (int16, bool) {
+					cells[i] = newCell(NilValue)
+					return next, true
+				}
+			}
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				regs[i] = NilValue
+				return next, true
+			}
+		default:
+			return nil
+		}
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			setReg(regs, cells, dst, val)
+			return next, true
+		}
+	case code.Type5Pfx:
+		jumpTo := pc + int16(opcode.GetOffset())
+		switch opcode.GetJ() {
+		case code.OpJump:
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				return jumpTo, true
+			}
+		case code.OpJumpIf:
+			reg, flag := opcode.GetA(), opcode.GetF()
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				if Truth(getReg(regs, cells, reg)) == flag {
+					return jumpTo, true
+				}
+				return next, true
+			}
+		}
+	case code.Type6Pfx:
+		if !opcode.HasType6b() {
+			return nil
+		}
+		startReg, stopReg := opcode.GetA(), opcode.GetB()
+		step := int64(opcode.GetM().ToInt8())
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			n, ok := getReg(regs, cells, startReg).TryInt()
+			if !ok {
+				return 0, false
+			}
+			m, ok := getReg(regs, cells, stopReg).TryInt()
+			if !ok {
+				return 0, false
+			}
+			next := n + step
+			if step > 0 && (next > m || next < n) || step < 0 && (next < m || next > n) {
+				setReg(regs, cells, startReg, NilValue)
+			} else {
+				setReg(regs, cells, startReg, IntValue(next))
+			}
+			return pc + 1, true
+		}
+	case code.Type7Pfx:
+		if !opcode.GetF() {
+			// Preparing a for loop may fail.
+			return nil
+		}
+		startReg, stopReg, stepReg := opcode.GetA(), opcode.GetB(), opcode.GetC()
+		return func(regs []Value, cells []Cell) (int16, bool) {
+			start := getReg(regs, cells, startReg)
+			stop := getReg(regs, cells, stopReg)
+			setReg(regs, cells, startReg, AdvForLoop(start, stop, getReg(regs, cells, stepReg)))
+			return next, true
+		}
+	case code.Type8Pfx:
+		reg, src := opcode.GetA(), opcode.GetB()
+		m := opcode.GetM()
+		switch opcode.GetSpecOp() {
+		case code.OpAddInt8:
+			n := int64(m.ToInt8())
+			if opcode.GetF() {
+				n = -n
+			}
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				x := getReg(regs, cells, src)
+				var res Value
+				if k, ok := x.TryInt(); ok {
+					res = IntValue(k + n)
+				} else if f, ok := x.TryFloat(); ok {
+					res = FloatValue(f + float64(n))
+				} else {
+					return 0, false
+				}
+				setReg(regs, cells, reg, res)
+				return next, true
+			}
+		case code.OpIndexK:
+			k := c.consts[m]
+			hint := c.hint(t, pc)
+			if opcode.GetF() {
+				return func(regs []Value, cells []Cell) (int16, bool) {
+					tbl, ok := getReg(regs, cells, src).TryTable()
+					if !ok {
+						return 0, false
+					}
+					val := getReg(regs, cells, reg)
+					if val.IsNil() || !tbl.resetCached(k, val, hint) {
+						return 0, false
+					}
+					return next, true
+				}
+			}
+			return func(regs []Value, cells []Cell) (int16, bool) {
+				tbl, ok := getReg(regs, cells, src).TryTable()
+				if !ok {
+					return 0, false
+				}
+				val := tbl.getCached(k, hint)
+				if val.IsNil() && tbl.meta != nil {
+					return 0, false
+				}
+				setReg(regs, cells, reg, val)
+				return next, true
+			}
+		}
+	}
+	return nil
+}
+
+// numComparison returns a function implementing the comparison op when it does
+// not involve metamethods.  The function returns false if that is not the case
+// for its operands.
+func numComparison(op code.BinOp) func(x, y Value) (bool, bool) {
+	switch op {
+	case code.OpEq:
+		return func(x, y Value) (bool, bool) {
+			if res, ok := RawEqual(x, y); ok {
+				return res, true
+			}
+			// Tables and userdata may have an __eq metamethod.
+			_, isTable := x.TryTable()
+			_, isUserData := x.TryUserData()
+			return false, !isTable && !isUserData
+		}
+	case code.OpLt:
+		return isLessThan
+	case code.OpLeq:
+		return isLessOrEqual
+	}
+	return nil
+}
+
+// isLessOrEqual is like isLessThan for x <= y.
+func isLessOrEqual(x, y Value) (bool, bool) {
+	switch x.NumberType() {
+	case IntType:
+		switch y.NumberType() {
+		case IntType:
+			return x.AsInt() <= y.AsInt(), true
+		case FloatType:
+			return leIntAndFloat(x.AsInt(), y.AsFloat()), true
+		}
+	case FloatType:
+		switch y.NumberType() {
+		case IntType:
+			return leFloatAndInt(x.AsFloat(), y.AsInt()), true
+		case FloatType:
+			return x.AsFloat() <= y.AsFloat(), true
+		}
+	}
+	return false, false
+}
+
+// numBinOp returns a function implementing the binary operator op for numbers.
+// The function returns false if its operands are not numbers or if the
+// operation would raise an error.
+func numBinOp(op code.BinOp) func(x, y Value) (Value, bool) {
+	switch op {
+	case code.OpAdd:
+		return Add
+	case code.OpSub:
+		return Sub
+	case code.OpMul:
+		return Mul
+	case code.OpDiv:
+		return Div
+	case code.OpPow:
+		return Pow
+	case code.OpMod:
+		return func(x, y Value) (Value, bool) {
+			res, ok, err := Mod(x, y)
+			return res, ok && err == nil
+		}
+	case code.OpFloorDiv:
+		return func(x, y Value) (Value, bool) {
+			res, ok, err := Idiv(x, y)
+			return res, ok && err == nil
+		}
+	case code.OpBitAnd:
+		return func(x, y Value) (Value, bool) {
+			n, okx := x.TryInt()
+			m, oky := y.TryInt()
+			return IntValue(n & m), okx && oky
+		}
+	case code.OpBitOr:
+		return func(x, y Value) (Value, bool) {
+			n, okx := x.TryInt()
+			m, oky := y.TryInt()
+			return IntValue(n | m), okx && oky
+		}
+	case code.OpBitXor:
+		return func(x, y Value) (Value, bool) {
+			n, okx := x.TryInt()
+			m, oky := y.TryInt()
+			return IntValue(n ^ m), okx && oky
+		}
+	}
+	return nil
+}
+
+// numUnOp returns a function implementing the unary operator op when it does
+// not involve metamethods.  The function returns false if that is not the case
+// for its operand.
+func numUnOp(op code.UnOp) func(x Value) (Value, bool) {
+	switch op {
+	case code.OpNeg:
+		return Unm
+	case code.OpBitNot:
+		return func(x Value) (Value, bool) {
+			n, ok := x.TryInt()
+			return IntValue(^n), ok
+		}
+	case code.OpLen:
+		return func(x Value) (Value, bool) {
+			if s, ok := x.TryString(); ok {
+				return IntValue(int64(len(s))), true
+			}
+			if tbl, ok := x.TryTable(); ok && tbl.meta == nil {
+				return IntValue(tbl.Len()), true
+			}
+			return NilValue, false
+		}
+	case code.OpId:
+		return func(x Value) (Value, bool) {
+			return x, true
+		}
+	case code.OpTruth:
+		return func(x Value) (Value, bool) {
+			return BoolValue(Truth(x)), true
+		}
+	case code.OpNot:
+		return func(x Value) (Value, bool) {
+			return BoolValue(!Truth(x)), true
+		}
+	}
+	return nil
+}
+
+// rawIndex returns coll[k] if coll is a table and that does not involve
+// metamethods.
+func rawIndex(coll, k Value) (Value, bool) {
+	tbl, ok := coll.TryTable()
+	if !ok {
+		return NilValue, false
+	}
+	val := RawGet(tbl, k)
+	if val.IsNil() && tbl.meta != nil {
+		return NilValue, false
+	}
+	return val, true
+}
+
+// rawReset sets coll[k] = val if coll is a table, k is already set in it and
+// val is not nil.  It returns true if it did that.
+func rawReset(coll, k, val Value) bool {
+	tbl, ok := coll.TryTable()
+	return ok && !val.IsNil() && !k.IsNaN() && tbl.Reset(k, val)
+}
@@ -0,0 +1,72 @@
+package runtime_test
+
+import (
+	"testing"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Compare the performance of compiled loops with that of interpreted loops.
+func BenchmarkHotLoops(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		src  string
+	}{
+		{"forloop", `
+			local n = 0
+			for i = 1, 10000 do n = n + i end
+		`},
+		{"arith", `
+			local s = 0
+			for i = 1, 10000 do
+				if i % 3 == 0 then s = s + i * 2 else s = s - 1 end
+			end
+		`},
+		{"while", `
+			local i, s = 0, 0.0
+			while i < 10000 do
+				s = s + i / 2
+				i = i + 1
+			end
+		`},
+		{"tables", `
+			local t = {}
+			for i = 1, 1000 do t[i] = i end
+			for j = 1, 10 do
+				for i = 1, 1000 do t[i] = t[i] + j end
+			end
+		`},
+		{"fields", `
+			local p = {x = 0, y = 0}
+			for i = 1, 10000 do
+				p.x = p.x + 1
+				p.y = p.y - 1
+			end
+		`},
+	}
+	variants := []struct {
+		name      string
+		threshold uint
+	}{
+		{"interpreted", 0},
+		{"compiled", 64},
+	}
+	for _, bm := range benchmarks {
+		for _, v := range variants {
+			bm, v := bm, v
+			b.Run(bm.name+"/"+v.name, func(b *testing.B) {
+				r := rt.New(nil, rt.WithHotLoopThreshold(v.threshold))
+				clos, err := r.CompileAndLoadLuaChunk("bench", []byte(bm.src), rt.TableValue(r.GlobalEnv()))
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
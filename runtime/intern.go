@@ -0,0 +1,58 @@
+package runtime
+
+// Number of slots of a stringInterner (must be a power of 2).
+const maxInternedStrings = 4096
+
+// A stringInterner keeps a set of strings so that equal strings can share the
+// same data.  Comparing such strings is fast as it only requires comparing
+// pointers.  Only strings whose hash is cached in their value are interned.
+//
+// The interner is a fixed size table indexed by that hash, so looking up a
+// string does not require hashing it again and the interner cannot grow
+// unbounded.  A string replaces the string in its slot, if any.
+type stringInterner struct {
+	slots []string
+}
+
+// intern returns a value equal to v, sharing the same data as a previously
+// interned equal string value if possible.  Otherwise v is interned.  If
+// mayBeSubstring is true, the string of v is copied before being interned so
+// that the interner does not retain a larger string it is a substring of.
+func (i *stringInterner) intern(v Value, mayBeSubstring bool) Value {
+	h, ok := internKey(v)
+	if !ok {
+		return v
+	}
+	if i.slots == nil {
+		i.slots = make([]string, maxInternedStrings)
+	}
+	slot := &i.slots[(h^h>>32)&(maxInternedStrings-1)]
+	s := v.AsString()
+	if *slot == s {
+		return internedValue(v, *slot)
+	}
+	if mayBeSubstring {
+		s = string([]byte(s))
+		v = internedValue(v, s)
+	}
+	*slot = s
+	return v
+}
+
+// InternString returns a string value for s.  If s is not too long, it is
+// interned so that it shares the same data as other equal interned strings,
+// which makes comparing them and looking them up in tables faster.  This is
+// used for string constants in Lua code and for the results of common string
+// operations.
+//
+// The interner may keep s, so it should not be a substring of a longer string
+// (see InternSubstring).
+func (r *Runtime) InternString(s string) Value {
+	return r.strings.intern(StringValue(s), false)
+}
+
+// InternSubstring is like InternString but s may be a substring of a longer
+// string.  If s needs to be kept by the interner, it is copied first.
+func (r *Runtime) InternSubstring(s string) Value {
+	return r.strings.intern(StringValue(s), true)
+}
@@ -0,0 +1,75 @@
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Measure the performance of method calls and field lookups with string keys
+// long enough not to be stored inline in values.
+func BenchmarkStringKeys(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		src  string
+	}{
+		{"methods", `
+			local Account = {}
+			Account.__index = Account
+			function Account.newAccount(balance)
+				return setmetatable({currentBalance = balance, transactionCount = 0}, Account)
+			end
+			function Account:depositAmount(v)
+				self.currentBalance = self.currentBalance + v
+				self.transactionCount = self.transactionCount + 1
+			end
+			function Account:getCurrentBalance()
+				return self.currentBalance
+			end
+			local acc = Account.newAccount(100)
+			for i = 1, 1000 do
+				acc:depositAmount(i)
+				acc:getCurrentBalance()
+			end
+		`},
+		{"dynamic", `
+			local t = {}
+			for i = 1, 20 do t["property" .. i] = i end
+			local n = 0
+			for j = 1, 50 do
+				for i = 1, 20 do n = n + t["property" .. i] end
+			end
+		`},
+		{"concat", `
+			local t = {}
+			for i = 1, 10000 do t["key number " .. i] = i end
+		`},
+		{"strings", `
+			local s = ("some_identifier "):rep(50)
+			local counts = {}
+			for w in s:gmatch("%S+") do counts[w] = (counts[w] or 0) + 1 end
+			for i = 1, 100 do
+				local k = s:sub(1, 15)
+				counts[k] = counts[k] + 1
+			end
+		`},
+	}
+	for _, bm := range benchmarks {
+		bm := bm
+		b.Run(bm.name, func(b *testing.B) {
+			r := rt.New(nil)
+			defer lib.LoadAll(r)()
+			clos, err := r.CompileAndLoadLuaChunk("bench", []byte(bm.src), rt.TableValue(r.GlobalEnv()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ type ClonePool struct {
 
 	pendingFinalize sortablePendingClones
 	pendingRelease  sortablePendingClones
+	pending         pendingFlag // Set when the lists above may not be empty
 }
 
 // NewClonePool returns a new *ClonePool ready to be used.
@@ -70,6 +71,12 @@ func (p *ClonePool) Mark(v Value, flags MarkFlags) {
 	p.cloneRegister[k] = c
 }
 
+// HasPending returns true if there may be values pending finalizing or
+// releasing.
+func (p *ClonePool) HasPending() bool {
+	return p.pending.isSet()
+}
+
 // ExtractPendingFinalize returns the set of values which are being garbage
 // collected and need their finalizer running, in the order that they should be
 // run.  The caller of this function has the responsibility to run all the
@@ -83,6 +90,7 @@ func (p *ClonePool) ExtractPendingFinalize() []Value {
 		return nil
 	}
 	p.pendingFinalize = nil
+	p.clearPending()
 	p.mx.Unlock()
 
 	for _, c := range pending {
@@ -104,6 +112,7 @@ func (p *ClonePool) ExtractPendingRelease() []Value {
 		return nil
 	}
 	p.pendingRelease = nil
+	p.clearPending()
 	p.mx.Unlock()
 
 	sort.Sort(pending)
@@ -169,6 +178,7 @@ func (p *ClonePool) goFinalizer(v Value) {
 
 	if !c.hasFlag(wrFinalized) {
 		p.pendingFinalize = append(p.pendingFinalize, c)
+		p.pending.set()
 		c.setFlag(wrFinalized)
 		p.cloneRegister[k] = c
 		return
@@ -176,11 +186,19 @@ func (p *ClonePool) goFinalizer(v Value) {
 
 	if !c.hasFlag(wrReleased) {
 		p.pendingRelease = append(p.pendingRelease, c)
+		p.pending.set()
 	}
 
 	delete(p.cloneRegister, k)
 }
 
+// Must be called with the lock held.
+func (p *ClonePool) clearPending() {
+	if p.pendingFinalize == nil && p.pendingRelease == nil {
+		p.pending.clear()
+	}
+}
+
 type cloneEntry struct {
 	value     Value
 	markOrder int
@@ -0,0 +1,20 @@
+package luagc
+
+import "sync/atomic"
+
+// A pendingFlag records whether a pool may have values pending finalizing or
+// releasing, so that checking for them does not require taking the pool's lock.
+// It must only be set or cleared with the lock held.
+type pendingFlag int32
+
+func (f *pendingFlag) set() {
+	atomic.StoreInt32((*int32)(f), 1)
+}
+
+func (f *pendingFlag) clear() {
+	atomic.StoreInt32((*int32)(f), 0)
+}
+
+func (f *pendingFlag) isSet() bool {
+	return atomic.LoadInt32((*int32)(f)) != 0
+}
@@ -46,6 +46,12 @@ func appendNoDup(xs []Value, y Value) []Value {
 	return append(xs, y)
 }
 
+// HasPending returns false because all marked values are kept alive by the
+// pool.
+func (p *SafePool) HasPending() bool {
+	return false
+}
+
 // ExtractPendingFinalize returns nil because all marked values are kept alive by the
 // pool.
 func (p *SafePool) ExtractPendingFinalize() []Value {
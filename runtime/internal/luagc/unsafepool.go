@@ -23,7 +23,8 @@ type UnsafePool struct {
 	weakrefs        map[uintptr]*weakRef //
 	pendingFinalize sortableVals         // Values pending Lua finalization
 	pendingRelease  sortableVals
-	lastMarkOrder   int // this is to sort values by reverse order of mark for finalize
+	pending         pendingFlag // Set when the lists above may not be empty
+	lastMarkOrder   int         // this is to sort values by reverse order of mark for finalize
 }
 
 var _ Pool = &UnsafePool{}
@@ -80,6 +81,12 @@ func (p *UnsafePool) Mark(v Value, flags MarkFlags) {
 	}
 }
 
+// HasPending returns true if there may be values pending finalizing or
+// releasing.
+func (p *UnsafePool) HasPending() bool {
+	return p.pending.isSet()
+}
+
 // ExtractPendingFinalize returns the set of values which are being garbage
 // collected and need their finalizer running, in the order that they should be
 // run.  The caller of this function has the responsibility to run all the
@@ -105,6 +112,7 @@ func (p *UnsafePool) ExtractPendingFinalize() []Value {
 		}
 	}
 	p.pendingFinalize = nil
+	p.clearPending()
 	p.mx.Unlock()
 
 	// Lua wants to run finalizers in reverse order
@@ -120,6 +128,7 @@ func (p *UnsafePool) ExtractPendingRelease() []Value {
 		return nil
 	}
 	p.pendingRelease = nil
+	p.clearPending()
 
 	for _, rval := range pending {
 		rval.r.setFlag(wrReleased)
@@ -221,6 +230,7 @@ func (p *UnsafePool) goFinalizer(v Value) {
 	// When it is extracted to be processed, its finalized flag will be set.
 	if !r.hasFlag(wrFinalized) {
 		p.pendingFinalize = append(p.pendingFinalize, rval)
+		p.pending.set()
 		setFinalizer(v, p.goFinalizer)
 		return
 	}
@@ -231,6 +241,7 @@ func (p *UnsafePool) goFinalizer(v Value) {
 	// A not yet released value is added to the pendingRelease list.
 	if !r.hasFlag(wrReleased) {
 		p.pendingRelease = append(p.pendingRelease, rval)
+		p.pending.set()
 	}
 
 	// It is now safe to remove this value from the weakref pool.
@@ -312,6 +323,13 @@ func (w wiface) iface() Value {
 	return *(*Value)(unsafe.Pointer(&w))
 }
 
+// Must be called with the lock held.
+func (p *UnsafePool) clearPending() {
+	if p.pendingFinalize == nil && p.pendingRelease == nil {
+		p.pending.clear()
+	}
+}
+
 //
 // Values need to be sorted by reverse mark order.  The data structures below help with that.
 //
@@ -73,6 +73,11 @@ type Pool interface {
 	// "Mark and Sweep".
 	Mark(v Value, flags MarkFlags)
 
+	// HasPending returns true if there may be values for ExtractPendingFinalize
+	// or ExtractPendingRelease to return.  It is cheap so that the Golua
+	// Runtime can call it very often.  It may return true spuriously.
+	HasPending() bool
+
 	// ExtractPendingFinalize returns all marked values which are no longer reachable
 	// and haven't been returned yet, so that some finalizing code can be run
 	// with them.  The returned values are ordered in reverse order of marking
@@ -13,6 +13,8 @@ import (
 	"github.com/arnodel/golua/ircomp"
 	"github.com/arnodel/golua/parsing"
 	"github.com/arnodel/golua/scanner"
+	"github.com/arnodel/golua/token"
+	"github.com/arnodel/golua/typecheck"
 )
 
 // RawGet returns the item in a table for the given key, or nil if t is nil.  It
@@ -38,7 +40,7 @@ func Index(t *Thread, coll Value, k Value) (Value, error) {
 				return val, nil
 			}
 		}
-		metaIdx := t.metaGetS(coll, "__index")
+		metaIdx := t.metaGet(coll, metaFieldIndexValue)
 		if metaIdx.IsNil() {
 			if ok {
 				return NilValue, nil
@@ -78,7 +80,7 @@ func SetIndex(t *Thread, coll Value, idx Value, val Value) error {
 		if isTable && tbl.Reset(idx, val) {
 			return nil
 		}
-		metaNewIndex := t.metaGetS(coll, "__newindex")
+		metaNewIndex := t.metaGet(coll, metaFieldNewIndexValue)
 		if metaNewIndex.IsNil() {
 			if isTable {
 				// No need to call SetTableCheck
@@ -167,7 +169,7 @@ func Concat(t *Thread, x, y Value) (Value, error) {
 	if sx, okx = x.ToString(); okx {
 		if sy, oky = y.ToString(); oky {
 			t.RequireBytes(len(sx) + len(sy))
-			return StringValue(sx + sy), nil
+			return t.InternString(sx + sy), nil
 		}
 	}
 	res, err, ok := metabin(t, "__concat", x, y)
@@ -217,7 +219,7 @@ func IntLen(t *Thread, v Value) (int64, error) {
 // Len returns the length of v, possibly calling the '__len' metamethod.
 func Len(t *Thread, v Value) (Value, error) {
 	if s, ok := v.TryString(); ok {
-		return IntValue(int64(len(s))), nil
+		return t.compatNumber(IntValue(int64(len(s)))), nil
 	}
 	res := NewTerminationWith(t.CurrentCont(), 1, false)
 	err, ok := Metacall(t, v, "__len", []Value{v}, res)
@@ -225,10 +227,10 @@ func Len(t *Thread, v Value) (Value, error) {
 		if err != nil {
 			return NilValue, err
 		}
-		return res.Get(0), nil
+		return t.compatNumber(res.Get(0)), nil
 	}
 	if tbl, ok := v.TryTable(); ok {
-		return IntValue(tbl.Len()), nil
+		return t.compatNumber(IntValue(tbl.Len())), nil
 	}
 	return NilValue, lenError(v)
 }
@@ -258,7 +260,7 @@ func (r *Runtime) SetEnvGoFunc(t *Table, name string, f GoFunctionFunc, nArgs in
 
 // ParseLuaChunk parses a string as a Lua statement and returns the AST.
 func (r *Runtime) ParseLuaChunk(name string, source []byte, scannerOptions ...scanner.Option) (stat *ast.BlockStat, statSize uint64, err error) {
-	s := scanner.New(name, source, scannerOptions...)
+	s := scanner.New(name, source, r.scannerOptions(scannerOptions)...)
 
 	// Account for CPU and memory used to make the AST.  This is an estimate,
 	// but statSize is proportional to the size of the source.
@@ -266,7 +268,7 @@ func (r *Runtime) ParseLuaChunk(name string, source []byte, scannerOptions ...sc
 	r.LinearRequire(4, uint64(len(source))) // 4 is a factor pulled out of thin air
 
 	stat = new(ast.BlockStat)
-	*stat, err = parsing.ParseChunk(s)
+	*stat, err = parsing.ParseChunk(s, r.parserOptions()...)
 	if err != nil {
 		r.ReleaseMem(statSize)
 		var parseErr parsing.Error
@@ -280,14 +282,14 @@ func (r *Runtime) ParseLuaChunk(name string, source []byte, scannerOptions ...sc
 
 // ParseLuaExp parses a string as a Lua expression and returns the AST.
 func (r *Runtime) ParseLuaExp(name string, source []byte, scannerOptions ...scanner.Option) (stat *ast.BlockStat, statSize uint64, err error) {
-	s := scanner.New(name, source, scannerOptions...)
+	s := scanner.New(name, source, r.scannerOptions(scannerOptions)...)
 
 	// Account for CPU and memory used to make the AST.  This is an estimate,
 	// but statSize is proportional to the size of the source.
 	statSize = uint64(len(source))
 	r.LinearRequire(4, uint64(len(source))) // 4 is a factor pulled out of thin air
 
-	exp, err := parsing.ParseExp(s)
+	exp, err := parsing.ParseExp(s, r.parserOptions()...)
 	if err != nil {
 		r.ReleaseMem(statSize)
 		var parseErr parsing.Error
@@ -301,6 +303,45 @@ func (r *Runtime) ParseLuaExp(name string, source []byte, scannerOptions ...scan
 	return
 }
 
+// scannerOptions returns the options for scanning a chunk, which are the given
+// options and the options required by the runtime configuration.
+func (r *Runtime) scannerOptions(opts []scanner.Option) []scanner.Option {
+	opts = opts[:len(opts):len(opts)]
+	if r.typeAnnotations {
+		opts = append(opts, scanner.WithTypes())
+	}
+	if r.syntaxExtensions != token.NoExtensions {
+		opts = append(opts, scanner.WithExtensions(r.syntaxExtensions))
+	}
+	return opts
+}
+
+// parserOptions returns the options for parsing a chunk required by the
+// runtime configuration.
+func (r *Runtime) parserOptions() []parsing.Option {
+	var opts []parsing.Option
+	if r.typeAnnotations {
+		opts = append(opts, parsing.WithTypes())
+	}
+	if r.syntaxExtensions != token.NoExtensions {
+		opts = append(opts, parsing.WithExtensions(r.syntaxExtensions))
+	}
+	return opts
+}
+
+// checkTypes checks the type annotations of a chunk and returns it without
+// them.
+func checkTypes(name string, stat ast.BlockStat) (ast.BlockStat, error) {
+	if typeErrs := typecheck.Check(stat); len(typeErrs) > 0 {
+		msgs := make([]string, len(typeErrs))
+		for i, typeErr := range typeErrs {
+			msgs[i] = fmt.Sprintf("%s:%s", name, typeErr)
+		}
+		return stat, errors.New(strings.Join(msgs, "\n"))
+	}
+	return typecheck.Strip(stat), nil
+}
+
 func (r *Runtime) compileLuaStat(name string, stat *ast.BlockStat, statSize uint64) (*code.Unit, uint64, error) {
 	// In any event the AST goes out of scope when leaving this function
 	defer func() { r.ReleaseMem(statSize) }()
@@ -313,13 +354,40 @@ func (r *Runtime) compileLuaStat(name string, stat *ast.BlockStat, statSize uint
 	// The IR consts go out of scope when we leave the function
 	defer r.ReleaseMem(constsSize)
 
+	if r.typeAnnotations {
+		checked, err := checkTypes(name, *stat)
+		if err != nil {
+			return nil, 0, err
+		}
+		stat = &checked
+	}
+
+	// Apply the transformers registered with the runtime
+	for _, transform := range r.astTransformers {
+		transformed, err := transform(name, *stat)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", name, err)
+		}
+		stat = &transformed
+	}
+	if r.luaVersion <= Lua51 {
+		*stat = floatLiterals(*stat)
+	}
+
 	// Compile ast to ir
-	kidx, constants, err := astcomp.CompileLuaChunk(name, *stat)
+	var compOpts []astcomp.Option
+	if r.optimizations.Has(ir.OptInline) {
+		compOpts = append(compOpts, astcomp.WithOptimizationLevel(astcomp.InlineFunctions))
+	}
+	kidx, constants, err := astcomp.CompileLuaChunk(name, *stat, compOpts...)
 
 	// We no longer need the AST (whether that succeeded or not)
 	r.ReleaseMem(statSize)
 
 	if err != nil {
+		if cerr, ok := err.(astcomp.Error); ok && cerr.Pos() == nil {
+			return nil, 0, fmt.Errorf("%s: %s", name, err)
+		}
 		return nil, 0, fmt.Errorf("%s:%s", name, err)
 	}
 
@@ -327,9 +395,11 @@ func (r *Runtime) compileLuaStat(name string, stat *ast.BlockStat, statSize uint
 
 	// "Optimise" the ir code
 	constants = ir.FoldConstants(constants, ir.DefaultFold)
+	constants = ir.OptimizeConstants(constants, r.optimizations, r.RequireCPU)
 
 	// Set up the IR to code compiler
 	kc := ircomp.NewConstantCompiler(constants, code.NewBuilder(name))
+	kc.SetSpecialize(r.optimizations.Has(ir.OptSpecialize))
 	kc.QueueConstant(kidx)
 
 	// Account for CPU and memory needed to compile IR to a code unit.  This is
@@ -382,6 +452,12 @@ func (r *Runtime) CompileLuaChunk(name string, source []byte, scannerOptions ...
 	return r.compileLuaStat(name, stat, statSize)
 }
 
+// CompileLuaAST compiles the AST of a Lua chunk (e.g. decoded with package
+// astjson) and returns the compiled code Unit.
+func (r *Runtime) CompileLuaAST(name string, stat ast.BlockStat) (*code.Unit, uint64, error) {
+	return r.compileLuaStat(name, &stat, 0)
+}
+
 // CompileAndLoadLuaChunk parses, compiles and loads a Lua chunk from source and
 // returns the closure that runs the chunk in the given global environment.
 func (r *Runtime) CompileAndLoadLuaChunkOrExp(name string, source []byte, env Value, scannerOptions ...scanner.Option) (*Closure, error) {
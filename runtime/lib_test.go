@@ -2,8 +2,10 @@ package runtime
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/arnodel/golua/ir"
 	"github.com/arnodel/golua/scanner"
 )
 
@@ -90,3 +92,29 @@ func TestSetIndexNoNewIndex(t *testing.T) {
 		t.Error("expected error indexing userdata value")
 	}
 }
+
+// The work of the IR optimizer is charged to the CPU quota of the context that
+// compiles a chunk.
+func TestCompileLuaChunkOptimizationsCPU(t *testing.T) {
+	if !QuotasAvailable {
+		t.Skip("quotas are not available")
+	}
+	src := []byte(strings.Repeat("local x <const> = 1\nlocal y = x + 1\nprint(y)\n", 100))
+	compileCPU := func(opts ir.Optimizations) uint64 {
+		r := New(nil, WithOptimizations(opts))
+		// CPU is only tracked when there is a limit.
+		def := RuntimeContextDef{HardLimits: RuntimeResources{Cpu: 1e9}}
+		ctx, err := r.MainThread().CallContext(def, func() error {
+			_, _, err := r.CompileLuaChunk("test", src)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx.UsedResources().Cpu
+	}
+	withOpts, withoutOpts := compileCPU(ir.DefaultOptimizations), compileCPU(ir.NoOptimizations)
+	if withOpts <= withoutOpts {
+		t.Errorf("optimizing used %d CPU, not optimizing used %d", withOpts, withoutOpts)
+	}
+}
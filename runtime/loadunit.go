@@ -4,6 +4,7 @@ import (
 	"unsafe"
 
 	"github.com/arnodel/golua/code"
+	"github.com/arnodel/golua/sourcemap"
 )
 
 // Code represents the code for a Lua function together with all the constants
@@ -12,11 +13,48 @@ type Code struct {
 	source, name string
 	code         []code.Opcode
 	lines        []int32
+	columns      []int32 // Optional, see code.Unit.Columns
+	inlined      []int16 // Optional, see code.Unit.Inlined
+	inlineFrames []code.InlineFrame
 	consts       []Value
 	UpvalueCount int16
 	UpNames      []string
 	RegCount     int16
 	CellCount    int16
+
+	// Inline caches for opcodes which look up a constant key in a table
+	// (indexed by pc and allocated when first needed).
+	hints []uint32
+
+	// Backward jumps counters and compiled loops (indexed by the pc of the
+	// jump and allocated when first needed).
+	loops []hotLoop
+
+	// Maps the lines and columns of the code to the original source if it
+	// was generated (see Runtime.SetSourceMap).
+	sourceMap *sourcemap.Map
+
+	// True if error messages should include the column (see
+	// WithErrorColumns).
+	errorColumns bool
+}
+
+// inlineFrame returns the inline frame the opcode at pc belongs to (see
+// code.Code.InlineFrames).
+func (c *Code) inlineFrame(pc int16) int16 {
+	if pc >= 0 && int(pc) < len(c.inlined) {
+		return c.inlined[pc]
+	}
+	return 0
+}
+
+// hint returns the inline cache for the opcode at pc.
+func (c *Code) hint(t *Thread, pc int16) *uint32 {
+	if c.hints == nil {
+		t.RequireArrSize(4, len(c.code))
+		c.hints = make([]uint32, len(c.code))
+	}
+	return &c.hints[pc]
 }
 
 // RefactorConsts returns an equivalent *Code this consts "refactored", which
@@ -31,32 +69,53 @@ func (r *Runtime) RefactorCodeConsts(c *Code) *Code {
 	// Require CPU for the loop below
 	r.RequireCPU(uint64(len(c.code)))
 
+	getIndex := func(n code.KIndex, isClosure bool) code.KIndex {
+		m, ok := constMap[n]
+		if !ok {
+			m = code.KIndexFromInt(len(consts))
+			constMap[n] = m
+			newConst := c.consts[n]
+			if isClosure {
+				// It's a closure so we need to refactor its consts
+				newConst = CodeValue(r.RefactorCodeConsts(newConst.AsCode()))
+			}
+			r.RequireSize(unsafe.Sizeof(Value{}))
+			consts = append(consts, newConst)
+		}
+		return m
+	}
+
+	// Constants indexed by specialized opcodes must have an index less than
+	// 256, so they are allocated first.  There are no more than 256 of them
+	// because their index in c.consts is less than 256 already.
+	for _, op := range c.code {
+		if op.TypePfx() == code.Type8Pfx && op.GetSpecOp() == code.OpIndexK {
+			getIndex(code.KIndex(op.GetM()), false)
+		}
+	}
+
 	for i, op := range c.code {
-		if op.TypePfx() == code.Type3Pfx {
+		switch op.TypePfx() {
+		case code.Type3Pfx:
 			unop := op.GetY()
 			if unop.LoadsK() {
 				// We are loading a constant
-				n := op.GetKIndex()
-				m, ok := constMap[n]
-				if !ok {
-					m = code.KIndexFromInt(len(consts))
-					constMap[n] = m
-					newConst := c.consts[n]
-					if unop == code.OpClosureK {
-						// It's a closure so we need to refactor its consts
-						newConst = CodeValue(r.RefactorCodeConsts(newConst.AsCode()))
-					}
-					r.RequireSize(unsafe.Sizeof(Value{}))
-					consts = append(consts, newConst)
-				}
+				m := getIndex(op.GetKIndex(), unop == code.OpClosureK)
 				op = op.SetKIndex(m)
 			}
+		case code.Type8Pfx:
+			if op.GetSpecOp() == code.OpIndexK {
+				m := getIndex(code.KIndex(op.GetM()), false)
+				op = op.SetM(code.Index8FromInt(int(m)))
+			}
 		}
 		opcodes[i] = op
 	}
 	cc := *c
 	cc.code = opcodes
 	cc.consts = consts
+	cc.hints = nil
+	cc.loops = nil
 	return &cc
 }
 
@@ -69,6 +128,9 @@ func (r *Runtime) LoadLuaUnit(unit *code.Unit, env Value) *Closure {
 	// code.Code case below
 	r.RequireArrSize(unsafe.Sizeof(code.Opcode(0)), len(unit.Code))
 	r.RequireArrSize(4, len(unit.Lines))
+	r.RequireArrSize(4, len(unit.Columns))
+	r.RequireArrSize(2, len(unit.Inlined))
+	sourceMap := r.sourceMaps[unit.Source]
 
 	// Require CPU for the loop below
 	r.RequireCPU(uint64(len(unit.Constants)))
@@ -81,27 +143,39 @@ func (r *Runtime) LoadLuaUnit(unit *code.Unit, env Value) *Closure {
 			constants[i] = FloatValue(float64(k))
 		case code.String:
 			// The strings are already accounted for memory-wise
-			constants[i] = StringValue(string(k))
+			constants[i] = r.InternString(string(k))
 		case code.Bool:
 			constants[i] = BoolValue(bool(k))
 		case code.NilType:
 			// Do nothing as constants[i] == nil
 		case code.Code:
 			r.RequireSize(unsafe.Sizeof(Code{}))
-			var lines []int32
+			var lines, columns []int32
+			var inlined []int16
 			if unit.Lines != nil {
 				lines = unit.Lines[k.StartOffset:k.EndOffset]
 			}
+			if unit.Columns != nil {
+				columns = unit.Columns[k.StartOffset:k.EndOffset]
+			}
+			if unit.Inlined != nil && k.InlineFrames != nil {
+				inlined = unit.Inlined[k.StartOffset:k.EndOffset]
+			}
 			constants[i] = CodeValue(&Code{
 				source:       unit.Source,
 				name:         k.Name,
 				code:         unit.Code[k.StartOffset:k.EndOffset],
 				lines:        lines,
+				columns:      columns,
+				inlined:      inlined,
+				inlineFrames: k.InlineFrames,
 				consts:       constants,
 				UpvalueCount: k.UpvalueCount,
 				UpNames:      k.UpNames,
 				RegCount:     k.RegCount,
 				CellCount:    k.CellCount,
+				sourceMap:    sourceMap,
+				errorColumns: r.errorColumns,
 			})
 		default:
 			panic("Unsupported constant type")
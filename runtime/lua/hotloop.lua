@@ -0,0 +1,130 @@
+-- Loops are compiled after they have run enough times.  These tests check that
+-- compiled loops give the same results as the interpreter, in particular when
+-- the type guards in the compiled code fail.
+
+-- Integer arithmetic
+local s = 0
+for i = 1, 1000 do
+    if i % 3 == 0 then s = s + i * 2 else s = s - 1 end
+end
+print(s)
+--> =332999
+
+-- Operands changing type during the loop
+local x = 0
+for i = 1, 200 do
+    if i == 100 then x = x + 0.5 end
+    x = x + 1
+end
+print(x, math.type(x))
+--> =200.5	float
+
+-- Strings are converted to numbers by the interpreter
+local n = 0
+for i = 1, 200 do
+    local v = i
+    if i > 150 then v = tostring(i) end
+    n = n + v
+end
+print(n)
+--> =20100
+
+-- Metamethods called in the middle of a loop
+local meta = {__add = function(a, b) return a.v + b end}
+local obj = setmetatable({v = 1000}, meta)
+local acc = 0
+for i = 1, 200 do
+    local a = i
+    if i == 150 then a = obj end
+    acc = acc + (a + 1)
+end
+print(acc)
+--> =21150
+
+-- Comparisons with NaN and mixed numbers
+local count = 0
+local nan = 0/0
+for i = 1, 200 do
+    local y = i
+    if i % 50 == 0 then y = nan end
+    if y <= 100 then count = count + 1 end
+    if y == y then count = count + 1 end
+    if 0.5 < y then count = count + 1 end
+end
+print(count)
+--> =490
+
+-- Errors are raised by the interpreter
+print(pcall(function()
+    local z = 0
+    for i = 1, 200 do
+        z = z + 10 // (100 - i)
+    end
+end))
+--> ~false\t.*attempt to divide by zero
+
+-- Tables, with and without metatables
+local t = {}
+for i = 1, 200 do t[i] = i end
+for i = 1, 200 do t[i] = t[i] * 2 end
+local p = {x = 0}
+for i = 1, 200 do p.x = p.x + t[i] end
+print(p.x, #t)
+--> =40200	200
+
+local log = {}
+local proxy = setmetatable({}, {
+    __index = function(_, k) return k * 10 end,
+    __newindex = function(_, k, v) log[#log + 1] = k end,
+})
+local total = 0
+for i = 1, 200 do
+    total = total + proxy[i]
+    proxy.y = i
+end
+print(total, #log)
+--> =201000	200
+
+-- While and repeat loops, break
+local w = 0
+while w < 500 do w = w + 3 end
+print(w)
+--> =501
+
+local r = 1000
+repeat r = r - 7 until r < 0
+print(r)
+--> =-1
+
+local found
+for i = 1, 1000 do
+    if i * i > 500 then
+        found = i
+        break
+    end
+end
+print(found)
+--> =23
+
+-- Captured loop variables
+local fs = {}
+for i = 1, 200 do
+    local j = i
+    fs[i] = function() return j end
+end
+print(fs[1](), fs[100](), fs[200]())
+--> =1	100	200
+
+-- Integer overflow in for loops
+local steps = 0
+for i = math.maxinteger - 300, math.maxinteger do
+    steps = steps + 1
+end
+print(steps)
+--> =301
+
+-- Float loops
+local f = 0
+for i = 0, 20, 0.25 do f = f + i end
+print(f)
+--> =810
@@ -0,0 +1,198 @@
+-- These tests check that inlining functions does not change the meaning of the
+-- code (they are also run without inlining).
+
+-- Calls in various contexts
+do
+    local function sq(x) return x * x end
+    local function two(a, b) return a, b end
+    local function none() end
+
+    print(sq(3), sq(2) + 1, (sq(5)))
+    --> =9	5	25
+
+    local a, b, c = two(1, 2)
+    print(a, b, c)
+    --> =1	2	nil
+
+    local x, y = two(1)
+    print(x, y, none())
+    --> =1	nil
+
+    local t = {sq(2), two(3, 4)}
+    print(#t, t[1], t[2], t[3])
+    --> =3	4	3	4
+
+    local function f(n) return sq(n) + sq(n + 1) end
+    print(f(2))
+    --> =13
+
+    local function g(n) return two(n, sq(n)) end
+    print(g(3))
+    --> =3	9
+
+    print((function(u, v) return u .. v end)("a", "b"))
+    --> =ab
+end
+
+-- Arguments are evaluated once and in order
+do
+    local log = {}
+    local function arg(x) log[#log + 1] = x return x end
+    local function add(a, b) return b + a end
+    print(add(arg(1), arg(2)), table.concat(log, ","))
+    --> =3	1,2
+
+    local function ignore(a) end
+    ignore(arg(3))
+    print(table.concat(log, ","))
+    --> =1,2,3
+
+    local function side(a) return a, arg(a + 1) end
+    local r = side(5)
+    print(r, table.concat(log, ","))
+    --> =5	1,2,3,6
+end
+
+-- Parameters are local to the function
+do
+    local function inc(x) x = x + 1 return x end
+    local x = 1
+    print(inc(x), x)
+    --> =2	1
+
+    local function loop(n)
+        local s = 0
+        for i = 1, n do
+            if i > 3 then break end
+            s = s + i
+        end
+        return s
+    end
+    print(loop(10), loop(2))
+    --> =6	3
+end
+
+-- Free names are resolved where the function is defined
+do
+    local k = 10
+    local function addk(x) return x + k end
+    do
+        local k = 100
+        print(addk(1))
+        --> =11
+    end
+    k = 20
+    print(addk(1))
+    --> =21
+
+    local function setk(v) k = v end
+    setk(5)
+    print(k, addk(0))
+    --> =5	5
+
+    local function getg() return gv end
+    gv = "global"
+    print(getg())
+    --> =global
+    do
+        local gv = "local"
+        print(getg())
+        --> =global
+    end
+    do
+        local print = print
+        local _ENV = {gv = "env"}
+        print(getg())
+        --> =global
+    end
+end
+
+-- Functions that are reassigned are not inlined
+do
+    local function f() return 1 end
+    local r1 = f()
+    f = function() return 2 end
+    print(r1, f())
+    --> =1	2
+end
+
+-- Recursive functions
+do
+    local function fact(n)
+        if n <= 1 then return 1 end
+        return n * fact(n - 1)
+    end
+    print(fact(5))
+    --> =120
+end
+
+-- Errors are reported at the line of the function body
+do
+    local function bad(x)
+        return x + {}
+    end
+    print(pcall(function() return bad(1) end))
+    --> =false	luatest:132: attempt to perform arithmetic on a table value
+end
+
+-- Inlined functions appear in tracebacks
+do
+    local function where()
+        print(debug.traceback("here"))
+    end
+    local function outer()
+        where()
+        print(debug.getinfo(1).name, debug.getinfo(2).name)
+    end
+    outer()
+    --> =here
+    --> =in function where (file luatest:141)
+    --> =in function outer (file luatest:144)
+    --> =in function <main chunk> (file luatest:147)
+    --> =outer	<main chunk>
+end
+
+-- Error levels count inlined functions
+do
+    local function check(x)
+        if not x then error("bad x", 2) end
+    end
+    print(pcall(function()
+        check(false)
+    end))
+    --> =false	luatest:161: bad x
+end
+
+-- Inlined functions are seen by debug hooks
+do
+    local function inc(x)
+        return x + 1
+    end
+    local function run()
+        local y = inc(1)
+        return y
+    end
+    local co = coroutine.create(run)
+    debug.sethook(co, function(ev, line)
+        print(ev, line, debug.getinfo(2).name)
+    end, "clr")
+    coroutine.resume(co)
+    --> =call	nil	run
+    --> =line	172	run
+    --> =call	nil	inc
+    --> =line	169	inc
+    --> =return	nil	inc
+    --> =line	173	run
+    --> =return	nil	run
+    debug.sethook(co)
+end
+
+-- Inlined calls are kept track of in dumped functions
+do
+    local function f()
+        local function g() error("in g", 2) end
+        g()
+    end
+    print(pcall(load(string.dump(f))))
+    --> =false	luatest:194: in g
+end
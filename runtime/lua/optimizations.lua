@@ -0,0 +1,113 @@
+-- These tests are run with each optimization pass turned on and off, so they
+-- check that optimizations do not change the meaning of the code.
+
+-- Operations on constants
+do
+    local N <const> = 10
+    local F <const> = 2.5
+    local S <const> = "abc"
+    print(N * 2 + 1, N - F, N / 4, -N, -F)
+    --> =21	7.5	2.5	-10	-2.5
+
+    print(math.maxinteger + N == math.mininteger + N - 1)
+    --> =true
+
+    local big <const> = 9223372036854775807
+    print(big + 1, -(-big - 1))
+    --> =-9223372036854775808	-9223372036854775808
+
+    print(1 / 0, -1 / 0, 0 / 0 ~= 0 / 0)
+    --> =+Inf	-Inf	true
+
+    print(S .. "def", #S, S == "abc", S ~= "abc", N == 10.0, N < 11, N >= 11)
+    --> =abcdef	3	true	false	true	true	false
+
+    print(not N, not nil, 1 == 1.0, "1" == 1)
+    --> =false	true	true	false
+end
+
+-- Constant conditions
+do
+    local DEBUG <const> = false
+    local ON <const> = true
+    if DEBUG then
+        print("debug")
+    elseif ON then
+        print("on")
+    end
+    --> =on
+
+    local n = 0
+    while ON do
+        n = n + 1
+        if n == 3 then break end
+    end
+    print(n)
+    --> =3
+end
+
+-- Copies of locals
+do
+    local a, b = 1, 2
+    local c = a
+    a = b
+    b = c
+    print(a, b, c)
+    --> =2	1	1
+
+    local fs = {}
+    for i = 1, 3 do
+        local j = i
+        fs[i] = function() j = j + 10; return j end
+    end
+    print(fs[1](), fs[1](), fs[2](), fs[3]())
+    --> =11	21	12	13
+end
+
+-- Jumps to jumps
+do
+    local out = {}
+    for i = 1, 3 do
+        for j = 1, 3 do
+            if j == 2 then goto continue end
+            if i == 3 then break end
+            out[#out + 1] = i .. j
+            ::continue::
+        end
+    end
+    print(table.concat(out, " "))
+    --> =11 13 21 23
+end
+
+-- Unreachable code and unused values
+do
+    local function f(x)
+        if x then
+            return "yes"
+        else
+            return "no"
+        end
+        local unused = x
+    end
+    print(f(1), f(false))
+    --> =yes	no
+
+    local function g(...)
+        local a, b = ...
+        local t = {}
+        return select("#", ...)
+    end
+    print(g(1, 2, 3))
+    --> =3
+end
+
+-- Errors raised by folded expressions are still raised at runtime
+do
+    local Z <const> = 0
+    print(pcall(function() return 1 // Z end))
+    --> =false	luatest:107: attempt to divide by zero
+
+    local T <const> = "x"
+    print(pcall(function() return T + 1 end))
+    --> =false	luatest:111: attempt to perform arithmetic on a string value
+end
@@ -0,0 +1,121 @@
+-- Adding / subtracting a small integer constant
+local function inc(x) return x + 1, x - 3 end
+print(inc(10))
+--> =11	7
+
+print(inc(2.5))
+--> =3.5	-0.5
+
+print(inc("10"))
+--> =11	7
+
+print(math.maxinteger + 1 == math.mininteger)
+--> =true
+
+local v = setmetatable({}, {
+    __add = function(a, b) return "add " .. tostring(b) end,
+    __sub = function(a, b) return "sub " .. tostring(b) end,
+})
+print(inc(v))
+--> =add 1	sub 3
+
+print(pcall(inc, {}))
+--> ~false\t.*attempt to perform arithmetic on a table value
+
+-- Large constants are not specialized but must still work
+local function big(x) return x + 1000, x - 1000 end
+print(big(1))
+--> =1001	-999
+
+-- Table lookups with constant string keys
+local t = {x = 1, y = 2}
+local function getx(t) return t.x end
+local function setx(t, v) t.x = v end
+
+print(getx(t), getx({y = 1, x = 3}), getx({}))
+--> =1	3	nil
+
+setx(t, 10)
+print(t.x, getx(t))
+--> =10	10
+
+-- Removing the key and adding more keys moves things around
+setx(t, nil)
+print(getx(t))
+--> =nil
+
+for i = 1, 20 do
+    t["k" .. i] = i
+end
+setx(t, 5)
+print(getx(t), t.k20)
+--> =5	20
+
+-- Metamethods are used when the key is not present
+local proxied = {}
+local p = setmetatable({}, {
+    __index = function(_, k) return "index " .. k end,
+    __newindex = function(_, k, v) proxied[k] = v end,
+})
+print(getx(p))
+--> =index x
+
+setx(p, 42)
+print(rawget(p, "x"), proxied.x)
+--> =nil	42
+
+rawset(p, "x", 1)
+setx(p, 2)
+print(getx(p), rawget(p, "x"))
+--> =2	2
+
+local s = setmetatable({}, {__index = t})
+print(getx(s))
+--> =5
+
+print(pcall(getx, 1))
+--> ~false\t.*attempt to index a number value
+
+print(pcall(setx, nil, 1))
+--> ~false\t.*attempt to index nil value
+
+-- Strings have a metatable
+print(("abc").len, ("abc"):len())
+--> ~function: .*\t3
+
+-- Numeric for loops with a constant step
+local function sum(a, b)
+    local s = 0
+    for i = a, b do s = s + i end
+    return s
+end
+print(sum(1, 10), sum(10, 1), sum(1.5, 3), sum(1, 3.5))
+--> =55	0	4	6
+
+print(math.type(sum(1.5, 3)), math.type(sum(1, 3.5)))
+--> =float	integer
+
+local function count(a, b, c)
+    local n, last = 0
+    for i = a, b, -2 do n, last = n + 1, i end
+    return n, last
+end
+print(count(10, 1))
+--> =5	2
+
+print(count(1, 10))
+--> =0	nil
+
+local _, last = count(10.0, 1.5)
+print(last, math.type(last))
+--> =2	float
+
+local n = 0
+for i = math.maxinteger - 2, math.maxinteger do n = n + 1 end
+print(n)
+--> =3
+
+n = 0
+for i = math.mininteger + 2, math.mininteger, -1 do n = n + 1 end
+print(n)
+--> =3
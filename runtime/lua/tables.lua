@@ -89,3 +89,22 @@ do
     print(pcall(table.insert, t, 5))
     --> ~false\t.* haha
 end
+
+-- table constructors are presized, check they still behave normally
+do
+    local function f(...) return {1, 2, x = 3, [4] = 4, ...} end
+    local t = f(5, 6, 7)
+    print(#t, t[3], t[4], t[5], t.x)
+    --> =5	5	6	7	3
+
+    local t = {nil, nil, 3}
+    print(t[3], select("#", table.unpack(t, 1, 3)))
+    --> =3	3
+
+    local t = {a = 1, b = 2, c = 3, d = 4, e = 5}
+    t.f = 6
+    local n = 0
+    for k, v in pairs(t) do n = n + v end
+    print(n)
+    --> =21
+end
@@ -24,3 +24,26 @@ print(pcall(string.char, "7.1"))
 
 print(string.byte("123", -1))
 --> =51
+
+-- Strings are equal whichever way they are built (they may be interned and
+-- have their hash cached depending on their length).
+do
+    local t = {}
+    for _, n in ipairs({3, 7, 8, 20, 40, 41, 100}) do
+        local s = ("k"):rep(n - 1)
+        t[s .. "x"] = n
+    end
+    for _, n in ipairs({3, 7, 8, 20, 40, 41, 100}) do
+        local s = ("k"):rep(n - 1) .. "x"
+        print(n, t[s], t[s:upper():lower()], s == string.format("%sx", ("k"):rep(n - 1)))
+    end
+    --> =3	3	3	true
+    --> =7	7	7	true
+    --> =8	8	8	true
+    --> =20	20	20	true
+    --> =40	40	40	true
+    --> =41	41	41	true
+    --> =100	100	100	true
+    print(t.kkkkkkkx, t.kkkkkkkkkkkkkkkkkkkx, t.kkkkkkkkkkkkkkkkkkky)
+    --> =8	20	nil
+end
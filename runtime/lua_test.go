@@ -3,8 +3,10 @@ package runtime_test
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"testing"
 
+	"github.com/arnodel/golua/ir"
 	"github.com/arnodel/golua/lib"
 	"github.com/arnodel/golua/luatesting"
 	rt "github.com/arnodel/golua/runtime"
@@ -14,6 +16,55 @@ func TestRuntime(t *testing.T) {
 	luatesting.RunLuaTestsInDir(t, "lua", setup)
 }
 
+// Run the tests with each optimization pass on its own and with none, to check
+// that optimizations do not change the behaviour of the code.
+func TestRuntimeOptimizations(t *testing.T) {
+	passes := []struct {
+		name string
+		opts ir.Optimizations
+	}{
+		{"none", ir.NoOptimizations},
+		{"deadcode", ir.OptDeadCode},
+		{"jumpthreading", ir.OptJumpThreading},
+		{"copypropagation", ir.OptCopyPropagation},
+		{"constpropagation", ir.OptConstPropagation},
+		{"clearreg", ir.OptClearReg},
+		{"inline", ir.OptInline},
+		{"specialize", ir.OptSpecialize},
+		{"all", ir.AllOptimizations},
+	}
+	for _, pass := range passes {
+		pass := pass
+		t.Run(pass.name, func(t *testing.T) {
+			paths, err := filepath.Glob("lua/*.lua")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range paths {
+				luatesting.RunLuaTestFile(t, path, func(r *rt.Runtime) func() {
+					r.SetOptimizations(pass.opts)
+					return setup(r)
+				})
+			}
+		})
+	}
+}
+
+// Run the tests compiling loops after their first iteration, to check that
+// compiled loops behave like interpreted ones.
+func TestRuntimeHotLoops(t *testing.T) {
+	paths, err := filepath.Glob("lua/*.lua")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		luatesting.RunLuaTestFile(t, path, func(r *rt.Runtime) func() {
+			r.SetHotLoopThreshold(1)
+			return setup(r)
+		})
+	}
+}
+
 func setup(r *rt.Runtime) func() {
 	rt.SolemnlyDeclareCompliance(rt.ComplyCpuSafe, r.SetEnvGoFunc(r.GlobalEnv(), "testudata", testudata, 1, false))
 	return lib.LoadAll(r)
@@ -1,8 +1,6 @@
 package runtime
 
 import (
-	"errors"
-	"fmt"
 	"unsafe"
 
 	"github.com/arnodel/golua/code"
@@ -19,6 +17,17 @@ type LuaCont struct {
 	running        bool
 	borrowedCells  bool
 	closeStackBase int
+
+	// If not nil, the continuation stands for a call inlined in it rather than
+	// for the function itself (see inlineCont).
+	inlined *inlinePos
+}
+
+// An inlinePos is a position in a function call inlined by the compiler, or in
+// the function itself if frame is 0.
+type inlinePos struct {
+	frame        int16
+	line, column int32
 }
 
 var _ Cont = (*LuaCont)(nil)
@@ -46,13 +55,13 @@ func NewLuaCont(t *Thread, clos *Closure, next Cont) *LuaCont {
 	registers[0] = ContValue(next)
 	cont := t.luaContPool.get()
 	t.RequireSize(unsafe.Sizeof(LuaCont{}))
-	*cont = LuaCont{
-		Closure:        clos,
-		registers:      registers,
-		cells:          cells,
-		borrowedCells:  borrowCells,
-		closeStackBase: t.closeStack.size(),
-	}
+	// Continuations from the pool are zeroed when released, so only set the
+	// non-zero fields (this is cheaper than assigning the whole struct).
+	cont.Closure = clos
+	cont.registers = registers
+	cont.cells = cells
+	cont.borrowedCells = borrowCells
+	cont.closeStackBase = t.closeStack.size()
 	return cont
 }
 
@@ -72,6 +81,7 @@ func (c *LuaCont) Push(r *Runtime, val Value) {
 	opcode := c.code[c.pc]
 	if opcode.HasType0() {
 		r.RequireCPU(1)
+		val = r.compatNumber(val)
 		dst := opcode.GetA()
 		if opcode.GetF() {
 			// It's an etc
@@ -84,6 +94,16 @@ func (c *LuaCont) Push(r *Runtime, val Value) {
 	}
 }
 
+// pushToCont pushes val to cont.  Arguments to Lua functions are pushed
+// directly into the callee's registers, avoiding a dynamic call.
+func pushToCont(r *Runtime, cont Cont, val Value) {
+	if lc, ok := cont.(*LuaCont); ok {
+		lc.Push(r, val)
+	} else {
+		cont.Push(r, val)
+	}
+}
+
 // PushEtc implements Cont.PushEtc.  TODO: optimise.
 func (c *LuaCont) PushEtc(r *Runtime, vals []Value) {
 	for _, val := range vals {
@@ -100,8 +120,95 @@ func (c *LuaCont) Next() Cont {
 	return next
 }
 
+// Parent implements Cont.Parent.  When c is running the body of a function
+// inlined by the compiler, the call to that function is its parent.
 func (c *LuaCont) Parent() Cont {
-	return c.Next()
+	frame := c.currentInlineFrame()
+	if frame == 0 {
+		return c.Next()
+	}
+	f := &c.inlineFrames[frame-1]
+	return c.inlineCont(f.Parent, f.Line, f.Column)
+}
+
+// inlineCont returns a copy of c which stands for the given inline frame at the
+// given position in tracebacks and to debug hooks, so that inlined functions
+// appear like functions that are called.  It must not be run.
+func (c *LuaCont) inlineCont(frame int16, line, column int32) *LuaCont {
+	ic := *c
+	ic.inlined = &inlinePos{frame: frame, line: line, column: column}
+	return &ic
+}
+
+// debugPC returns the pc of the opcode c is executing (or calling from if it is
+// not running).
+func (c *LuaCont) debugPC() int16 {
+	if !c.running {
+		return c.pc - 1
+	}
+	return c.pc
+}
+
+func (c *LuaCont) currentInlineFrame() int16 {
+	if c.inlined != nil {
+		return c.inlined.frame
+	}
+	return c.inlineFrame(c.debugPC())
+}
+
+// triggerInlineHooks emits the debug hook events for leaving inline frame from
+// and entering inline frame to, which happens when the opcode at pc is not in
+// the same inline frame as the previous opcode, which was at line lastLine.
+func (c *LuaCont) triggerInlineHooks(t *Thread, from, to int16, pc int16, lastLine int32) error {
+	// Find the closest common ancestor of the two frames (0 is an ancestor of
+	// every frame and parents have a lower index than their children).
+	anc := to
+	for f := from; f != anc; {
+		if f > anc {
+			f = c.inlineFrames[f-1].Parent
+		} else {
+			anc = c.inlineFrames[anc-1].Parent
+		}
+	}
+	var line, column int32 = lastLine, 0
+	for f := from; f != anc; {
+		if err := t.triggerReturn(t, c.inlineCont(f, line, column)); err != nil {
+			return err
+		}
+		frame := &c.inlineFrames[f-1]
+		f, line, column = frame.Parent, frame.Line, frame.Column
+	}
+	// Enter the frames outermost first, each one being at the position of
+	// the call to the next one.
+	var entered []int16
+	for f := to; f != anc; f = c.inlineFrames[f-1].Parent {
+		entered = append(entered, f)
+	}
+	for i := len(entered) - 1; i >= 0; i-- {
+		if i > 0 {
+			frame := &c.inlineFrames[entered[i-1]-1]
+			line, column = frame.Line, frame.Column
+		} else {
+			line, column = c.position(pc)
+		}
+		if err := t.triggerCall(t, c.inlineCont(entered[i], line, column)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// position returns the line and column of the opcode at pc (-1 and 0 if
+// unknown).
+func (c *LuaCont) position(pc int16) (line, column int32) {
+	line = -1
+	if pc >= 0 && int(pc) < len(c.lines) {
+		line = c.lines[pc]
+		if int(pc) < len(c.columns) {
+			column = c.columns[pc]
+		}
+	}
+	return
 }
 
 // RunInThread implements Cont.RunInThread.
@@ -110,6 +217,7 @@ func (c *LuaCont) RunInThread(t *Thread) (Cont, error) {
 	consts := c.consts
 	lines := c.lines
 	var lastLine int32
+	lastFrame := c.inlineFrame(pc)
 	c.running = true
 	opcodes := c.code
 	regs := c.registers
@@ -118,7 +226,18 @@ RunLoop:
 	for {
 		t.RequireCPU(1)
 
-		if t.DebugHooks.areFlagsEnabled(HookFlagLine) {
+		if t.DebugHooks.areFlagsEnabled(HookFlagLine | HookFlagCall | HookFlagReturn) {
+			// Hooks may inspect c, which must know where it is.
+			c.pc = pc
+			// Calls to inlined functions are not executed, so their debug
+			// hook events are emitted when going in and out of their code.
+			if frame := c.inlineFrame(pc); frame != lastFrame {
+				if err := c.triggerInlineHooks(t, lastFrame, frame, pc, lastLine); err != nil {
+					return nil, err
+				}
+				lastFrame = frame
+				lastLine = 0
+			}
 			line := lines[pc]
 			if line > 0 && line != lastLine {
 				lastLine = line
@@ -132,84 +251,7 @@ RunLoop:
 			dst := opcode.GetA()
 			x := getReg(regs, cells, opcode.GetB())
 			y := getReg(regs, cells, opcode.GetC())
-			var res Value
-			var err error
-			var ok bool
-			switch opcode.GetX() {
-
-			// Arithmetic
-
-			case code.OpAdd:
-				res, ok = Add(x, y)
-				if !ok {
-					res, err = binaryArithFallback(t, "__add", x, y)
-				}
-			case code.OpSub:
-				res, ok = Sub(x, y)
-				if !ok {
-					res, err = binaryArithFallback(t, "__sub", x, y)
-				}
-			case code.OpMul:
-				res, ok = Mul(x, y)
-				if !ok {
-					res, err = binaryArithFallback(t, "__mul", x, y)
-				}
-			case code.OpDiv:
-				res, ok = Div(x, y)
-				if !ok {
-					res, err = binaryArithFallback(t, "__div", x, y)
-				}
-			case code.OpFloorDiv:
-				res, ok, err = Idiv(x, y)
-				if !ok {
-					res, err = binaryArithFallback(t, "__idiv", x, y)
-				}
-			case code.OpMod:
-				res, ok, err = Mod(x, y)
-				if !ok {
-					res, err = binaryArithFallback(t, "__mod", x, y)
-				}
-			case code.OpPow:
-				res, ok = Pow(x, y)
-				if !ok {
-					res, err = binaryArithFallback(t, "__pow", x, y)
-				}
-
-			// Bitwise
-
-			case code.OpBitAnd:
-				res, err = band(t, x, y)
-			case code.OpBitOr:
-				res, err = bor(t, x, y)
-			case code.OpBitXor:
-				res, err = bxor(t, x, y)
-			case code.OpShiftL:
-				res, err = shl(t, x, y)
-			case code.OpShiftR:
-				res, err = shr(t, x, y)
-
-			// Comparison
-
-			case code.OpEq:
-				var r bool
-				r, err = eq(t, x, y)
-				res = BoolValue(r)
-			case code.OpLt:
-				var r bool
-				r, err = Lt(t, x, y)
-				res = BoolValue(r)
-			case code.OpLeq:
-				var r bool
-				r, err = le(t, x, y)
-				res = BoolValue(r)
-
-			// Concatenation
-
-			case code.OpConcat:
-				res, err = Concat(t, x, y)
-			default:
-				panic("unsupported")
-			}
+			res, err := BinaryOp(t, opcode.GetX(), x, y)
 			if err != nil {
 				c.pc = pc
 				return nil, err
@@ -267,8 +309,7 @@ RunLoop:
 			dst := opcode.GetA()
 			if opcode.GetF() {
 				// dst must contain a continuation
-				cont := getReg(regs, cells, dst).AsCont()
-				cont.Push(t.Runtime, val)
+				pushToCont(t.Runtime, getReg(regs, cells, dst).AsCont(), val)
 			} else {
 				setReg(regs, cells, dst, val)
 			}
@@ -326,7 +367,11 @@ RunLoop:
 				case code.OpCC:
 					res = ContValue(c)
 				case code.OpTable:
-					res = TableValue(NewTable())
+					if narr, nhash := opcode.GetL().ToTableSize(); narr != 0 || nhash != 0 {
+						res = TableValue(t.NewTableSize(narr, nhash))
+					} else {
+						res = TableValue(NewTable())
+					}
 				case code.OpStr0:
 					res = StringValue("")
 				case code.OpStr1:
@@ -349,7 +394,7 @@ RunLoop:
 				return nil, err
 			}
 			if opcode.GetF() {
-				getReg(regs, cells, dst).AsCont().Push(t.Runtime, res)
+				pushToCont(t.Runtime, getReg(regs, cells, dst).AsCont(), res)
 			} else {
 				setReg(regs, cells, dst, res)
 			}
@@ -358,12 +403,26 @@ RunLoop:
 		case code.Type5Pfx:
 			switch opcode.GetJ() {
 			case code.OpJump:
-				pc += int16(opcode.GetOffset())
+				offset := int16(opcode.GetOffset())
+				if offset < 0 && t.hotLoopThreshold != 0 && !t.DebugHooks.areFlagsEnabled(HookFlagLine|HookFlagCall|HookFlagReturn) {
+					if loop := c.hotLoop(t, pc+offset, pc); loop != nil {
+						pc = loop.run(t, regs, cells)
+						continue RunLoop
+					}
+				}
+				pc += offset
 				continue RunLoop
 			case code.OpJumpIf:
 				test := Truth(getReg(regs, cells, opcode.GetA()))
 				if test == opcode.GetF() {
-					pc += int16(opcode.GetOffset())
+					offset := int16(opcode.GetOffset())
+					if offset < 0 && t.hotLoopThreshold != 0 && !t.DebugHooks.areFlagsEnabled(HookFlagLine|HookFlagCall|HookFlagReturn) {
+						if loop := c.hotLoop(t, pc+offset, pc); loop != nil {
+							pc = loop.run(t, regs, cells)
+							continue RunLoop
+						}
+					}
+					pc += offset
 				} else {
 					pc++
 				}
@@ -409,16 +468,39 @@ RunLoop:
 					// are called because they may use c.
 					c.release(t.Runtime)
 				}
-				return next, nil
+
+				// If the next continuation is a Lua one (a Lua function being
+				// called or returned to), run it in this loop rather than
+				// going back to the thread's loop.  This saves a lot of
+				// overhead for Lua to Lua calls.
+				lc, ok := next.(*LuaCont)
+				if !ok {
+					return next, nil
+				}
+				// This bypasses Thread.RunContinuation so pending finalizers
+				// must be run here.
+				if t != t.gcThread {
+					t.runPendingFinalizers()
+				}
+				c = lc
+				c.running = true
+				t.currentCont = c
+				pc = c.pc
+				consts = c.consts
+				lines = c.lines
+				lastLine = 0
+				lastFrame = c.inlineFrame(pc)
+				opcodes = c.code
+				regs = c.registers
+				cells = c.cells
+				continue RunLoop
 			case code.OpClStack:
 				if opcode.GetF() {
 					// Push to close stack
-					v := getReg(regs, cells, opcode.GetA())
-					if Truth(v) && t.metaGetS(v, "__close").IsNil() {
+					if err := t.PushToBeClosed(getReg(regs, cells, opcode.GetA())); err != nil {
 						c.pc = pc
-						return nil, errors.New("to be closed value missing a __close metamethod")
+						return nil, err
 					}
-					t.closeStack.push(v)
 				} else {
 					// Truncate close stack
 					h := c.closeStackBase + int(opcode.GetClStackOffset())
@@ -433,6 +515,37 @@ RunLoop:
 				panic("unsupported")
 			}
 		case code.Type6Pfx:
+			if opcode.HasType6b() {
+				// Advance for loop with a constant integer step.
+				startReg, stopReg := opcode.GetA(), opcode.GetB()
+				start := getReg(regs, cells, startReg)
+				stop := getReg(regs, cells, stopReg)
+				step := int64(opcode.GetM().ToInt8())
+				var nextStart Value
+				if n, ok := start.TryInt(); ok {
+					next := n + step
+					if m, ok := stop.TryInt(); ok {
+						// Fast path for the common case of an integer loop.
+						if step > 0 && (next > m || next < n) || step < 0 && (next < m || next > n) {
+							nextStart = NilValue
+						} else {
+							nextStart = IntValue(next)
+						}
+						setReg(regs, cells, startReg, nextStart)
+						pc++
+						continue RunLoop
+					}
+					nextStart = IntValue(next)
+				} else {
+					nextStart = FloatValue(start.AsFloat() + float64(step))
+				}
+				if forLoopDone(start, nextStart, stop, step > 0) {
+					nextStart = NilValue
+				}
+				setReg(regs, cells, startReg, nextStart)
+				pc++
+				continue RunLoop
+			}
 			dst := opcode.GetA()
 			etc := getReg(regs, cells, opcode.GetB()).AsArray()
 			idx := int(opcode.GetM())
@@ -458,70 +571,55 @@ RunLoop:
 			if opcode.GetF() {
 				// Advance for loop.  All registers are assumed to contain
 				// numeric values because they have been prepared previously.
-				nextStart, _ := Add(start, step)
-
-				// Check if the loop is done.  It can be done if we have gone
-				// over the stop value or if there has been overflow /
-				// underflow.
-				var done bool
-				if isPositive(step) {
-					done = numIsLessThan(stop, nextStart) || numIsLessThan(nextStart, start)
-				} else {
-					done = numIsLessThan(nextStart, stop) || numIsLessThan(start, nextStart)
-				}
-				if done {
-					nextStart = NilValue
-				}
-				setReg(regs, cells, startReg, nextStart)
+				setReg(regs, cells, startReg, AdvForLoop(start, stop, step))
 			} else {
 				// Prepare for loop
-				start, tstart := ToNumberValue(start)
-				stop, tstop := ToNumberValue(stop)
-				step, tstep := ToNumberValue(step)
-				if tstart == NaN || tstop == NaN || tstep == NaN {
+				var err error
+				start, stop, step, err = PrepForLoop(start, stop, step)
+				if err != nil {
 					c.pc = pc
-					var (
-						role string
-						val  Value
-					)
-					switch {
-					case tstart == NaN:
-						role, val = "initial value", start
-					case tstop == NaN:
-						role, val = "limit", stop
-					default:
-						role, val = "step", step
-					}
-					return nil, fmt.Errorf("'for' %s: expected number, got %s", role, val.CustomTypeName())
+					return nil, err
 				}
-				// Make sure start and step have the same numeric type
-				if tstart != tstep {
-					// One is a float, one is an int, turn them both to floats
-					if tstart == IsInt {
-						start = FloatValue(float64(start.AsInt()))
-					} else {
-						step = FloatValue(float64(step.AsInt()))
-					}
+				setReg(regs, cells, startReg, start)
+				setReg(regs, cells, stopReg, stop)
+				setReg(regs, cells, stepReg, step)
+			}
+			pc++
+			continue RunLoop
+		case code.Type8Pfx:
+			reg := opcode.GetA()
+			src := getReg(regs, cells, opcode.GetB())
+			m := opcode.GetM()
+			var err error
+			switch opcode.GetSpecOp() {
+			case code.OpAddInt8:
+				var res Value
+				if opcode.GetF() {
+					res, err = subInt(t, src, int64(m.ToInt8()))
+				} else {
+					res, err = addInt(t, src, int64(m.ToInt8()))
 				}
-				// A 0 step is an error
-				if isZero(step) {
-					c.pc = pc
-					return nil, errors.New("'for' step is zero")
+				if err == nil {
+					setReg(regs, cells, reg, res)
 				}
-				// Check the loop is not already finished. If so, startReg is
-				// set to nil.
-				var done bool
-				if isPositive(step) {
-					done, _ = isLessThan(stop, start)
+			case code.OpIndexK:
+				k := consts[m]
+				hint := c.hint(t, pc)
+				if opcode.GetF() {
+					err = setIndexCached(t, src, k, getReg(regs, cells, reg), hint)
 				} else {
-					done, _ = isLessThan(start, stop)
-				}
-				if done {
-					start = NilValue
+					var val Value
+					val, err = indexCached(t, src, k, hint)
+					if err == nil {
+						setReg(regs, cells, reg, val)
+					}
 				}
-				setReg(regs, cells, startReg, start)
-				setReg(regs, cells, stopReg, stop)
-				setReg(regs, cells, stepReg, step)
+			default:
+				panic("unsupported")
+			}
+			if err != nil {
+				c.pc = pc
+				return nil, err
 			}
 			pc++
 			continue RunLoop
@@ -530,25 +628,91 @@ RunLoop:
 	// return nil, errors.New("Invalid PC")
 }
 
+// forLoopDone returns true if a numeric for loop should stop after advancing
+// its variable from start to nextStart.  It can be done if we have gone over the
+// stop value or if there has been overflow / underflow.
+func forLoopDone(start, nextStart, stop Value, positiveStep bool) bool {
+	if positiveStep {
+		return numIsLessThan(stop, nextStart) || numIsLessThan(nextStart, start)
+	}
+	return numIsLessThan(nextStart, stop) || numIsLessThan(start, nextStart)
+}
+
+// addInt returns x + n, with a fast path for numbers.
+func addInt(t *Thread, x Value, n int64) (Value, error) {
+	if m, ok := x.TryInt(); ok {
+		return IntValue(m + n), nil
+	}
+	y := IntValue(n)
+	if res, ok := Add(x, y); ok {
+		return res, nil
+	}
+	return binaryArithFallback(t, "__add", x, y)
+}
+
+// subInt returns x - n, with a fast path for numbers.
+func subInt(t *Thread, x Value, n int64) (Value, error) {
+	if m, ok := x.TryInt(); ok {
+		return IntValue(m - n), nil
+	}
+	y := IntValue(n)
+	if res, ok := Sub(x, y); ok {
+		return res, nil
+	}
+	return binaryArithFallback(t, "__sub", x, y)
+}
+
+// indexCached is like Index for a constant string key k, with a fast path for
+// tables where the key is found (or which have no metatable).
+func indexCached(t *Thread, coll, k Value, hint *uint32) (Value, error) {
+	if tbl, ok := coll.TryTable(); ok {
+		if val := tbl.getCached(k, hint); !val.IsNil() || tbl.meta == nil {
+			t.RequireCPU(1)
+			return val, nil
+		}
+	}
+	return Index(t, coll, k)
+}
+
+// setIndexCached is like SetIndex for a constant string key k, with a fast path
+// for tables where the key is already set.
+func setIndexCached(t *Thread, coll, k, val Value, hint *uint32) error {
+	if tbl, ok := coll.TryTable(); ok && !val.IsNil() && tbl.resetCached(k, val, hint) {
+		t.RequireCPU(1)
+		return nil
+	}
+	return SetIndex(t, coll, k, val)
+}
+
 // DebugInfo implements Cont.DebugInfo.
 func (c *LuaCont) DebugInfo() *DebugInfo {
-	pc := c.pc
-	if !c.running {
-		pc--
-	}
-	var currentLine int32 = -1
-	if pc >= 0 && int(pc) < len(c.lines) {
-		currentLine = c.lines[pc]
+	var currentLine, currentColumn int32
+	if c.inlined != nil {
+		currentLine, currentColumn = c.inlined.line, c.inlined.column
+	} else {
+		currentLine, currentColumn = c.position(c.debugPC())
 	}
 	name := c.name
+	if frame := c.currentInlineFrame(); frame != 0 {
+		name = c.inlineFrames[frame-1].Name
+	}
 	if name == "" {
 		name = "<lua function>"
 	}
-	return &DebugInfo{
-		Source:      c.source,
-		Name:        name,
-		CurrentLine: currentLine,
+	info := &DebugInfo{
+		Source:        c.source,
+		Name:          name,
+		CurrentLine:   currentLine,
+		CurrentColumn: currentColumn,
+	}
+	if c.sourceMap != nil && currentLine > 0 {
+		if pos, ok := c.sourceMap.Lookup(int(currentLine), int(currentColumn)); ok {
+			info.Source = pos.Source
+			info.CurrentLine = int32(pos.Line)
+			info.CurrentColumn = int32(pos.Column)
+		}
 	}
+	return info
 }
 
 func (c *LuaCont) getRegCell(reg code.Reg) Cell {
@@ -0,0 +1,52 @@
+package runtime_test
+
+import (
+	"testing"
+
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Measure the overhead of Lua to Lua function calls.
+func BenchmarkLuaCalls(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		src  string
+	}{
+		{"call", `
+			local function add(a, b) return a + b end
+			local s = 0
+			for i = 1, 10000 do s = add(s, i) end
+		`},
+		{"recursive", `
+			local function fib(n)
+				if n < 2 then return n end
+				return fib(n - 1) + fib(n - 2)
+			end
+			fib(15)
+		`},
+		{"tailcall", `
+			local function count(n)
+				if n == 0 then return end
+				return count(n - 1)
+			end
+			count(10000)
+		`},
+	}
+	for _, bm := range benchmarks {
+		bm := bm
+		b.Run(bm.name, func(b *testing.B) {
+			r := rt.New(nil)
+			clos, err := r.CompileAndLoadLuaChunk("bench", []byte(bm.src), rt.TableValue(r.GlobalEnv()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
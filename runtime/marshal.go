@@ -91,15 +91,22 @@ func (w *bwriter) writeConst(c Value) {
 }
 
 func (w *bwriter) writeCode(c *Code) {
-	w.consumeBudget(1 + 0 + 0 + 8 + 8 + 8)
+	w.consumeBudget(1 + 0 + 0 + 8 + 8 + 8 + 8)
 	w.write(
 		CodeType,
 		c.source,
 		c.name,
 		int64(len(c.code)), c.code,
 		int64(len(c.lines)), c.lines,
-		int64(len(c.consts)),
+		int64(len(c.inlined)), c.inlined,
+		int64(len(c.inlineFrames)),
 	)
+	for _, f := range c.inlineFrames {
+		w.consumeBudget(0 + 4 + 4 + 2)
+		w.write(f.Name, f.Line, f.Column, f.Parent)
+	}
+	w.consumeBudget(8)
+	w.write(int64(len(c.consts)))
 	for _, k := range c.consts {
 		w.writeConst(k)
 	}
@@ -223,6 +230,28 @@ func (r *breader) readCode(c *Code) {
 		c.lines,
 		&sz,
 	)
+	if sz > 0 {
+		c.inlined = make([]int16, sz)
+	}
+	r.read(
+		2*uint64(sz)+8,
+		c.inlined,
+		&sz,
+	)
+	if sz > 0 {
+		c.inlineFrames = make([]code.InlineFrame, sz)
+	}
+	for i := range c.inlineFrames {
+		f := &c.inlineFrames[i]
+		r.read(
+			0+4+4+2,
+			&f.Name,
+			&f.Line,
+			&f.Column,
+			&f.Parent,
+		)
+	}
+	r.read(8, &sz)
 	c.consts = make([]Value, sz)
 	for i := range c.consts {
 		c.consts[i] = r.readConst()
@@ -10,4 +10,8 @@ const (
 
 var (
 	MetaFieldGcValue = StringValue(MetaFieldGcString)
+
+	// Used in hot paths, so they are built once and for all.
+	metaFieldIndexValue    = StringValue("__index")
+	metaFieldNewIndexValue = StringValue("__newindex")
 )
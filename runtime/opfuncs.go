@@ -0,0 +1,182 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/arnodel/golua/code"
+)
+
+// This file contains functions implementing the semantics of some opcodes.
+// They are exported so that Go code compiled from Lua (see package gocomp) can
+// behave the same as the interpreter.
+
+// BinaryOp returns the result of applying the binary operator op to x and y,
+// calling metamethods if necessary.
+func BinaryOp(t *Thread, op code.BinOp, x, y Value) (Value, error) {
+	var (
+		res Value
+		ok  bool
+		err error
+	)
+	switch op {
+
+	// Arithmetic
+
+	case code.OpAdd:
+		res, ok = Add(x, y)
+		if !ok {
+			res, err = binaryArithFallback(t, "__add", x, y)
+		}
+	case code.OpSub:
+		res, ok = Sub(x, y)
+		if !ok {
+			res, err = binaryArithFallback(t, "__sub", x, y)
+		}
+	case code.OpMul:
+		res, ok = Mul(x, y)
+		if !ok {
+			res, err = binaryArithFallback(t, "__mul", x, y)
+		}
+	case code.OpDiv:
+		res, ok = Div(x, y)
+		if !ok {
+			res, err = binaryArithFallback(t, "__div", x, y)
+		}
+	case code.OpFloorDiv:
+		res, ok, err = Idiv(x, y)
+		if !ok {
+			res, err = binaryArithFallback(t, "__idiv", x, y)
+		}
+	case code.OpMod:
+		res, ok, err = Mod(x, y)
+		if !ok {
+			res, err = binaryArithFallback(t, "__mod", x, y)
+		}
+	case code.OpPow:
+		res, ok = Pow(x, y)
+		if !ok {
+			res, err = binaryArithFallback(t, "__pow", x, y)
+		}
+
+	// Bitwise
+
+	case code.OpBitAnd:
+		res, err = band(t, x, y)
+	case code.OpBitOr:
+		res, err = bor(t, x, y)
+	case code.OpBitXor:
+		res, err = bxor(t, x, y)
+	case code.OpShiftL:
+		res, err = shl(t, x, y)
+	case code.OpShiftR:
+		res, err = shr(t, x, y)
+
+	// Comparison
+
+	case code.OpEq:
+		var r bool
+		r, err = eq(t, x, y)
+		res = BoolValue(r)
+	case code.OpLt:
+		var r bool
+		r, err = Lt(t, x, y)
+		res = BoolValue(r)
+	case code.OpLeq:
+		var r bool
+		r, err = le(t, x, y)
+		res = BoolValue(r)
+
+	// Concatenation
+
+	case code.OpConcat:
+		res, err = Concat(t, x, y)
+	default:
+		return NilValue, fmt.Errorf("unsupported binary operator %d", op)
+	}
+	return res, err
+}
+
+// UnaryOp returns the result of applying the unary operator op to x, calling
+// metamethods if necessary.  Only operators which compute a value are
+// supported (i.e. not OpCont, OpTailCont, OpUpvalue, OpEtcId).
+func UnaryOp(t *Thread, op code.UnOp, x Value) (Value, error) {
+	switch op {
+	case code.OpNeg:
+		if res, ok := Unm(x); ok {
+			return res, nil
+		}
+		return unaryArithFallback(t, "__unm", x)
+	case code.OpBitNot:
+		return bnot(t, x)
+	case code.OpLen:
+		return Len(t, x)
+	case code.OpId:
+		return x, nil
+	case code.OpTruth:
+		return BoolValue(Truth(x)), nil
+	case code.OpNot:
+		return BoolValue(!Truth(x)), nil
+	default:
+		return NilValue, fmt.Errorf("unsupported unary operator %d", op)
+	}
+}
+
+// PrepForLoop prepares the control values of a numeric for loop, returning
+// the values to use to run the loop.  If the loop should not run at all, the
+// returned start value is nil.
+func PrepForLoop(start, stop, step Value) (Value, Value, Value, error) {
+	start, tstart := ToNumberValue(start)
+	stop, tstop := ToNumberValue(stop)
+	step, tstep := ToNumberValue(step)
+	if tstart == NaN || tstop == NaN || tstep == NaN {
+		var (
+			role string
+			val  Value
+		)
+		switch {
+		case tstart == NaN:
+			role, val = "initial value", start
+		case tstop == NaN:
+			role, val = "limit", stop
+		default:
+			role, val = "step", step
+		}
+		return NilValue, NilValue, NilValue, fmt.Errorf("'for' %s: expected number, got %s", role, val.CustomTypeName())
+	}
+	// Make sure start and step have the same numeric type
+	if tstart != tstep {
+		// One is a float, one is an int, turn them both to floats
+		if tstart == IsInt {
+			start = FloatValue(float64(start.AsInt()))
+		} else {
+			step = FloatValue(float64(step.AsInt()))
+		}
+	}
+	// A 0 step is an error
+	if isZero(step) {
+		return NilValue, NilValue, NilValue, errors.New("'for' step is zero")
+	}
+	// Check the loop is not already finished. If so, start is set to nil.
+	var done bool
+	if isPositive(step) {
+		done, _ = isLessThan(stop, start)
+	} else {
+		done, _ = isLessThan(start, stop)
+	}
+	if done {
+		start = NilValue
+	}
+	return start, stop, step, nil
+}
+
+// AdvForLoop returns the next value of the variable of a numeric for loop
+// whose control values have been prepared with PrepForLoop, or nil if the loop
+// is finished.
+func AdvForLoop(start, stop, step Value) Value {
+	nextStart, _ := Add(start, step)
+	if forLoopDone(start, nextStart, stop, isPositive(step)) {
+		return NilValue
+	}
+	return nextStart
+}
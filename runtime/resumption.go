@@ -0,0 +1,44 @@
+package runtime
+
+// A Resumable is the state of a Go function compiled from Lua which was
+// suspended by a call (see GoCont.Call).  Resume resumes the function at the
+// given point.
+type Resumable interface {
+	Resume(t *Thread, at int) (Cont, error)
+}
+
+// A Resumption receives the results of a call made by a Go function compiled
+// from Lua like a Termination does.  When it is run, the function is resumed
+// where it made the call.
+type Resumption struct {
+	Termination
+	cont   *GoCont
+	resume Resumable
+	at     int
+}
+
+var _ Cont = (*Resumption)(nil)
+
+// NewResumption returns a new Resumption receiving nArgs values (and the
+// remaining ones in an etc if hasEtc is true) which resumes the function of c
+// by calling resume.Resume with at.
+func NewResumption(c *GoCont, nArgs int, hasEtc bool, resume Resumable, at int) *Resumption {
+	r := &Resumption{cont: c, resume: resume, at: at}
+	r.parent = c
+	if nArgs > 0 {
+		r.args = make([]Value, nArgs)
+	}
+	if hasEtc {
+		r.etc = new([]Value)
+	}
+	return r
+}
+
+// RunInThread implements Cont.RunInThread.  It resumes the suspended function.
+func (r *Resumption) RunInThread(t *Thread) (Cont, error) {
+	c := r.cont
+	t.RequireCPU(1)
+	c.suspended = false
+	next, err := r.resume.Resume(t, r.at)
+	return c.exit(t, next, err)
+}
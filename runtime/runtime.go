@@ -6,8 +6,13 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"unsafe"
 
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ir"
 	"github.com/arnodel/golua/runtime/internal/luagc"
+	"github.com/arnodel/golua/sourcemap"
+	"github.com/arnodel/golua/token"
 )
 
 // A Runtime is a Lua runtime.  It contains all the global state of the runtime
@@ -27,6 +32,25 @@ type Runtime struct {
 
 	warner Warner // Lua 5.4 introduces a warning system, implemented by this
 
+	optimizations ir.Optimizations // Applied to IR code when compiling chunks
+
+	astTransformers []ASTTransformer // Applied to the AST of chunks before compiling them
+
+	luaVersion LuaVersion // Version of Lua emulated (see compat.go)
+
+	typeAnnotations bool // True if chunks may contain type annotations
+
+	syntaxExtensions token.Extensions // Extensions to the Lua syntax allowed in chunks
+
+	errorColumns    bool                      // True if error messages include columns
+	errorTracebacks bool                      // True if errors record a traceback (see Error.Frames)
+	sourceMaps      map[string]*sourcemap.Map // Source maps by chunk name (see SetSourceMap)
+
+	// Number of iterations after which a loop is compiled (0 means never).
+	hotLoopThreshold uint
+
+	strings stringInterner // Interned strings, see InternString
+
 	// This has an almost empty implementation when the noquotas build tag is
 	// set.  It should allow the compiler to compile away almost all runtime
 	// context manager methods.
@@ -48,11 +72,23 @@ type runtimeOptions struct {
 	regPoolSize       uint
 	regSetMaxAge      uint
 	runtimeContextDef *RuntimeContextDef
+	optimizations     ir.Optimizations
+	hotLoopThreshold  uint
+	astTransformers   []ASTTransformer
+	luaVersion        LuaVersion
+	typeAnnotations   bool
+	syntaxExtensions  token.Extensions
+	errorColumns      bool
+	errorTracebacks   bool
+	interrupts        bool
 }
 
 var defaultRuntimeOptions = runtimeOptions{
-	regPoolSize:  10,
-	regSetMaxAge: 10,
+	regPoolSize:      10,
+	regSetMaxAge:     10,
+	optimizations:    ir.DefaultOptimizations,
+	hotLoopThreshold: 64,
+	luaVersion:       Lua54,
 }
 
 // A RuntimeOption configures the Runtime.
@@ -74,6 +110,76 @@ func WithRegSetMaxAge(age uint) RuntimeOption {
 	}
 }
 
+// WithOptimizations sets the optimizations applied to the IR code of chunks
+// compiled by the Runtime.  The default is ir.DefaultOptimizations.
+func WithOptimizations(opts ir.Optimizations) RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.optimizations = opts
+	}
+}
+
+// WithHotLoopThreshold sets the number of iterations after which a loop is
+// compiled to Go closures (see hotloop.go).  A threshold of 0 disables loop
+// compilation.  The default is 64.
+func WithHotLoopThreshold(n uint) RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.hotLoopThreshold = n
+	}
+}
+
+// An ASTTransformer transforms the AST of a chunk before it is compiled, e.g.
+// to add instrumentation or implement syntactic sugar (see ast.Rewrite).  It
+// is given the name of the chunk.  If it returns an error, the chunk fails to
+// compile with that error.
+type ASTTransformer func(chunkName string, chunk ast.BlockStat) (ast.BlockStat, error)
+
+// WithASTTransformer adds a transformer applied to the AST of all the chunks
+// compiled by the Runtime (including by the load() function).  Transformers
+// are applied in the order they are added.
+func WithASTTransformer(t ASTTransformer) RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.astTransformers = append(rtOpts.astTransformers, t)
+	}
+}
+
+// WithTypeAnnotations allows type annotations in the chunks compiled by the
+// Runtime (see parsing.WithTypes).  Annotations are checked by package
+// typecheck when a chunk is compiled, and a chunk with type errors fails to
+// compile.  They are then removed, so they have no cost at runtime.
+func WithTypeAnnotations() RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.typeAnnotations = true
+	}
+}
+
+// WithSyntaxExtensions allows the given extensions to the Lua syntax (see
+// token.Extensions) in the chunks loaded by the runtime.  They are translated
+// to standard Lua constructs when a chunk is compiled.
+func WithSyntaxExtensions(ext token.Extensions) RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.syntaxExtensions |= ext
+	}
+}
+
+// WithErrorTracebacks makes errors raised in the runtime record the call stack
+// where they are raised, available from Error.Frames.  It is off by default as
+// errors caught by pcall would pay the cost of walking the call stack.
+func WithErrorTracebacks() RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.errorTracebacks = true
+	}
+}
+
+// WithInterrupts makes it possible to stop the runtime from another goroutine
+// by calling Interrupt.  This has a small cost as the runtime then keeps track
+// of the CPU it uses.  Interrupts are not available with the noquotas build
+// tag.
+func WithInterrupts() RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.interrupts = true
+	}
+}
+
 func WithRuntimeContext(def RuntimeContextDef) RuntimeOption {
 	return func(rtOpts *runtimeOptions) {
 		rtOpts.runtimeContextDef = &def
@@ -94,6 +200,15 @@ func New(stdout io.Writer, opts ...RuntimeOption) *Runtime {
 		regPool:   mkValuePool(rtOpts.regPoolSize, rtOpts.regSetMaxAge),
 		argsPool:  mkValuePool(rtOpts.regPoolSize, rtOpts.regSetMaxAge),
 		cellPool:  mkCellPool(rtOpts.regPoolSize, rtOpts.regSetMaxAge),
+
+		optimizations:    rtOpts.optimizations,
+		hotLoopThreshold: rtOpts.hotLoopThreshold,
+		astTransformers:  rtOpts.astTransformers,
+		luaVersion:       rtOpts.luaVersion,
+		typeAnnotations:  rtOpts.typeAnnotations,
+		syntaxExtensions: rtOpts.syntaxExtensions,
+		errorColumns:     rtOpts.errorColumns,
+		errorTracebacks:  rtOpts.errorTracebacks,
 	}
 
 	mainThread := NewThread(r)
@@ -105,6 +220,9 @@ func New(stdout io.Writer, opts ...RuntimeOption) *Runtime {
 	r.gcThread = gcThread
 
 	r.runtimeContextManager.initRoot()
+	if rtOpts.interrupts {
+		r.runtimeContextManager.enableInterrupts()
+	}
 
 	if rtOpts.runtimeContextDef != nil {
 		r.PushContext(*rtOpts.runtimeContextDef)
@@ -129,6 +247,30 @@ func (r *Runtime) SetRegistry(k, v Value) {
 	r.SetTable(r.registry, k, v)
 }
 
+// SetOptimizations sets the optimizations applied to the IR code of chunks
+// compiled from now on.
+func (r *Runtime) SetOptimizations(opts ir.Optimizations) {
+	r.optimizations = opts
+}
+
+// SetHotLoopThreshold sets the number of iterations after which a loop is
+// compiled (0 means never).
+func (r *Runtime) SetHotLoopThreshold(n uint) {
+	r.hotLoopThreshold = n
+}
+
+// AddASTTransformer adds a transformer applied to the AST of chunks compiled
+// from now on (see WithASTTransformer).
+func (r *Runtime) AddASTTransformer(t ASTTransformer) {
+	r.astTransformers = append(r.astTransformers, t)
+}
+
+// Optimizations returns the optimizations applied to the IR code of chunks
+// compiled by the runtime.
+func (r *Runtime) Optimizations() ir.Optimizations {
+	return r.optimizations
+}
+
 // MainThread returns the runtime's main thread.
 func (r *Runtime) MainThread() *Thread {
 	return r.mainThread
@@ -210,6 +352,9 @@ func (r *Runtime) addFinalizer(ref luagc.Value, flags luagc.MarkFlags) {
 }
 
 func (r *Runtime) runPendingFinalizers() {
+	if !r.weakRefPool.HasPending() {
+		return
+	}
 
 	// Running finalizers may panic if we run out of resources
 	pendingFinalize := r.weakRefPool.ExtractPendingFinalize()
@@ -287,6 +432,14 @@ func (r *Runtime) Metatable(v Value) Value {
 	return TableValue(meta)
 }
 
+// NewTableSize returns a new table with room for narr values in its array part
+// and nhash values in its hash part, requiring the memory upfront.
+func (r *Runtime) NewTableSize(narr, nhash int) *Table {
+	r.RequireArrSize(unsafe.Sizeof(Value{}), narr)
+	r.RequireArrSize(unsafe.Sizeof(hashTableSlot{}), nhash)
+	return NewTableSize(narr, nhash)
+}
+
 // Set a value in a table, requiring memory if needed, and always consuming >0
 // CPU.
 func (r *Runtime) SetTable(t *Table, k, v Value) {
@@ -311,6 +464,10 @@ func (r *Runtime) SetTableCheck(t *Table, k, v Value) error {
 }
 
 func (r *Runtime) metaGetS(v Value, k string) Value {
+	return r.metaGet(v, StringValue(k))
+}
+
+func (r *Runtime) metaGet(v Value, k Value) Value {
 	meta := r.RawMetatable(v)
-	return RawGet(meta, StringValue(k))
+	return RawGet(meta, k)
 }
@@ -6,6 +6,7 @@ package runtime
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/arnodel/golua/runtime/internal/luagc"
@@ -42,6 +43,10 @@ type runtimeContextManager struct {
 
 	weakRefPool luagc.Pool
 	gcPolicy    GCPolicy
+
+	// Set to 1 by Interrupt, possibly from another goroutine.  It is nil
+	// unless the runtime was created with WithInterrupts.
+	interrupt *uint32
 }
 
 var _ RuntimeContext = (*runtimeContextManager)(nil)
@@ -51,6 +56,23 @@ func (m *runtimeContextManager) initRoot() {
 	m.weakRefPool = luagc.NewDefaultPool()
 }
 
+// enableInterrupts makes it possible to call Interrupt.  The CPU is then always
+// tracked, so that the interruption is noticed.
+func (m *runtimeContextManager) enableInterrupts() {
+	m.interrupt = new(uint32)
+	m.trackCpu = true
+}
+
+// Interrupt kills the current runtime context, and then any context that
+// the runtime runs code in, as soon as possible.  Unlike KillContext, it is safe
+// to call from any goroutine.  It has no effect unless the runtime was created
+// with WithInterrupts.
+func (m *runtimeContextManager) Interrupt() {
+	if m.interrupt != nil {
+		atomic.StoreUint32(m.interrupt, 1)
+	}
+}
+
 func (m *runtimeContextManager) HardLimits() RuntimeResources {
 	return m.hardLimits
 }
@@ -123,7 +145,7 @@ func (m *runtimeContextManager) PushContext(ctx RuntimeContextDef) {
 		m.requiredFlags |= ComplyTimeSafe
 	}
 	m.trackTime = m.hardLimits.Millis > 0 || m.softLimits.Millis > 0
-	m.trackCpu = m.hardLimits.Cpu > 0 || m.softLimits.Cpu > 0 || m.trackTime
+	m.trackCpu = m.hardLimits.Cpu > 0 || m.softLimits.Cpu > 0 || m.trackTime || m.interrupt != nil
 	m.trackMem = m.hardLimits.Memory > 0 || m.softLimits.Memory > 0
 	m.status = StatusLive
 	m.messageHandler = ctx.MessageHandler
@@ -175,6 +197,9 @@ func (m *runtimeContextManager) requireCPU(cpuAmount uint64) {
 	if m.stopLevel&HardStop != 0 {
 		m.KillContext()
 	}
+	if m.interrupt != nil && atomic.LoadUint32(m.interrupt) != 0 {
+		m.TerminateContext("interrupted")
+	}
 	cpuUsed := m.usedResources.Cpu + cpuAmount
 	if atLimit(cpuUsed, m.hardLimits.Cpu) {
 		m.TerminateContext("CPU limit of %d exceeded", m.hardLimits.Cpu)
@@ -256,6 +281,19 @@ func (m *runtimeContextManager) UnusedMem() uint64 {
 	return m.hardLimits.Memory - m.usedResources.Memory
 }
 
+// timeLeft returns the time left until the hard time limit of the context is
+// reached.  It returns false if there is no time limit.
+func (m *runtimeContextManager) timeLeft() (time.Duration, bool) {
+	if m.hardLimits.Millis == 0 {
+		return 0, false
+	}
+	used := now() - m.startTime
+	if used >= m.hardLimits.Millis {
+		return 0, true
+	}
+	return time.Duration(m.hardLimits.Millis-used) * time.Millisecond, true
+}
+
 func (m *runtimeContextManager) updateTimeUsed() {
 	m.usedResources.Millis = now() - m.startTime
 	if atLimit(m.usedResources.Millis, m.hardLimits.Millis) {
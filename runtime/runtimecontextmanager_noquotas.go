@@ -5,6 +5,7 @@ package runtime
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/arnodel/golua/runtime/internal/luagc"
 )
@@ -23,6 +24,13 @@ func (m *runtimeContextManager) initRoot() {
 	m.weakRefPool = luagc.NewDefaultPool()
 }
 
+func (m *runtimeContextManager) enableInterrupts() {
+}
+
+// Interrupt has no effect as runtime contexts cannot be killed without quotas.
+func (m *runtimeContextManager) Interrupt() {
+}
+
 func (m *runtimeContextManager) HardLimits() (r RuntimeResources) {
 	return
 }
@@ -58,6 +66,10 @@ func (m *runtimeContextManager) Due() bool {
 	return false
 }
 
+func (m *runtimeContextManager) timeLeft() (time.Duration, bool) {
+	return 0, false
+}
+
 func (m *runtimeContextManager) SetStopLevel(StopLevel) {
 }
 
@@ -0,0 +1,29 @@
+package runtime
+
+import "github.com/arnodel/golua/sourcemap"
+
+// WithErrorColumns makes the runtime include the source column as well as the
+// line in error messages and tracebacks, e.g. "foo.lua:3:12: boom" instead of
+// "foo.lua:3: boom".  It is off by default as Lua code may parse error
+// messages.  Columns are available in DebugInfo regardless.
+func WithErrorColumns() RuntimeOption {
+	return func(rtOpts *runtimeOptions) {
+		rtOpts.errorColumns = true
+	}
+}
+
+// SetSourceMap attaches a source map to the chunks with the given name loaded
+// after the call (including by the load() function).  This is useful for Lua
+// code generated by a transpiler: positions in DebugInfo, error messages and
+// tracebacks are then those in the original source whenever the map has one.
+// A nil map removes the source map for the chunk name.
+func (r *Runtime) SetSourceMap(chunkName string, m *sourcemap.Map) {
+	if m == nil {
+		delete(r.sourceMaps, chunkName)
+		return
+	}
+	if r.sourceMaps == nil {
+		r.sourceMaps = map[string]*sourcemap.Map{}
+	}
+	r.sourceMaps[chunkName] = m
+}
@@ -0,0 +1,97 @@
+package runtime_test
+
+import (
+	"testing"
+
+	rt "github.com/arnodel/golua/runtime"
+	"github.com/arnodel/golua/sourcemap"
+)
+
+const positionsSrc = `local t = {}
+local info = where()
+local ok, err = pcall(function() return 1 + t.x.y end)
+return info, err, traceback()
+`
+
+// runPositions runs positionsSrc and returns the debug info of the call to
+// where(), the error message of the pcall and the traceback.
+func runPositions(t *testing.T, r *rt.Runtime) (string, string, string) {
+	env := r.GlobalEnv()
+	r.SetEnvGoFunc(env, "where", func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return c.PushingNext1(t.Runtime, rt.StringValue(c.Next().DebugInfo().String())), nil
+	}, 0, false)
+	r.SetEnvGoFunc(env, "pcall", func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		err := rt.Call(t, c.Arg(0), nil, rt.NewTerminationWith(c, 0, false))
+		return c.PushingNext(t.Runtime, rt.BoolValue(err == nil), rt.ErrorValue(err)), nil
+	}, 1, false)
+	r.SetEnvGoFunc(env, "traceback", func(t *rt.Thread, c *rt.GoCont) (rt.Cont, error) {
+		return c.PushingNext1(t.Runtime, rt.StringValue(t.Runtime.Traceback("", c.Next()))), nil
+	}, 0, false)
+	clos, err := r.CompileAndLoadLuaChunk("test", []byte(positionsSrc), rt.TableValue(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	term := rt.NewTerminationWith(nil, 3, false)
+	if err := rt.Call(r.MainThread(), rt.FunctionValue(clos), nil, term); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := term.Get(0).TryString()
+	msg, _ := term.Get(1).TryString()
+	tb, _ := term.Get(2).TryString()
+	return info, msg, tb
+}
+
+func TestPositions(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []rt.RuntimeOption
+		sourceMap     *sourcemap.Map
+		info, msg, tb string
+	}{
+		{
+			name: "default",
+			info: "file=test func=<main chunk> line=2 col=14",
+			msg:  "test:3: attempt to index a nil value",
+			tb:   "in function <main chunk> (file test:4)",
+		},
+		{
+			name: "error columns",
+			opts: []rt.RuntimeOption{rt.WithErrorColumns()},
+			info: "file=test func=<main chunk> line=2 col=14",
+			msg:  "test:3:45: attempt to index a nil value",
+			tb:   "in function <main chunk> (file test:4:19)",
+		},
+		{
+			name:      "source map",
+			opts:      []rt.RuntimeOption{rt.WithErrorColumns()},
+			sourceMap: positionsMap(),
+			info:      "file=orig.tl func=<main chunk> line=10 col=3",
+			msg:       "orig.tl:12:8: attempt to index a nil value",
+			tb:        "in function <main chunk> (file test:4:19)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := rt.New(nil, test.opts...)
+			r.SetSourceMap("test", test.sourceMap)
+			info, msg, tb := runPositions(t, r)
+			if info != test.info {
+				t.Errorf("info: got %q, want %q", info, test.info)
+			}
+			if msg != test.msg {
+				t.Errorf("msg: got %q, want %q", msg, test.msg)
+			}
+			if tb != test.tb {
+				t.Errorf("traceback: got %q, want %q", tb, test.tb)
+			}
+		})
+	}
+}
+
+func positionsMap() *sourcemap.Map {
+	var m sourcemap.Map
+	m.Add(2, 1, sourcemap.Pos{Source: "orig.tl", Line: 10, Column: 3})
+	m.Add(3, 1, sourcemap.Pos{Source: "orig.tl", Line: 11, Column: 1})
+	m.Add(3, 34, sourcemap.Pos{Source: "orig.tl", Line: 12, Column: 8})
+	return &m
+}
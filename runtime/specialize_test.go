@@ -0,0 +1,72 @@
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/arnodel/golua/ir"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Compare the performance of specialized opcodes with that of the generic
+// opcodes they replace.
+func BenchmarkSpecializedOpcodes(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		src  string
+	}{
+		{"forloop", `
+			local n = 0
+			for i = 1, 10000 do n = i end
+		`},
+		{"addint", `
+			local n = 0
+			for i = 1, 10000 do n = n + 1 end
+		`},
+		{"lookupk", `
+			local t = {a = 1, b = 2, c = 3, x = 4}
+			local n
+			for i = 1, 10000 do n = t.x end
+		`},
+		{"setindexk", `
+			local t = {a = 1, b = 2, c = 3, x = 4}
+			for i = 1, 10000 do t.x = i end
+		`},
+		{"globals", `
+			local f
+			for i = 1, 10000 do f = print end
+		`},
+		{"fields", `
+			local p = {x = 0, y = 0}
+			for i = 1, 10000 do
+				p.x = p.x + 1
+				p.y = p.y - 1
+			end
+		`},
+	}
+	variants := []struct {
+		name string
+		opts ir.Optimizations
+	}{
+		{"generic", ir.DefaultOptimizations &^ ir.OptSpecialize},
+		{"specialized", ir.DefaultOptimizations | ir.OptSpecialize},
+	}
+	for _, bm := range benchmarks {
+		for _, v := range variants {
+			bm, v := bm, v
+			b.Run(bm.name+"/"+v.name, func(b *testing.B) {
+				r := rt.New(nil, rt.WithOptimizations(v.opts))
+				r.SetEnv(r.GlobalEnv(), "print", rt.BoolValue(true))
+				clos, err := r.CompileAndLoadLuaChunk("bench", []byte(bm.src), rt.TableValue(r.GlobalEnv()))
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,51 @@
+//go:build !noscalar
+// +build !noscalar
+
+package runtime
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStringInterner(t *testing.T) {
+	var i stringInterner
+
+	// A fresh string is kept as it is.
+	s1 := string([]byte("hello, world"))
+	v1 := i.intern(StringValue(s1), false)
+	if stringData(v1.AsString()) != stringData(s1) {
+		t.Error("expected fresh string not to be copied")
+	}
+
+	// Equal strings share the data of the interned string.
+	v2 := i.intern(StringValue(string([]byte("hello, world"))), false)
+	if stringData(v2.AsString()) != stringData(s1) {
+		t.Error("expected equal string to share interned data")
+	}
+
+	// A substring is copied so that the larger string is not retained.
+	long := "a long string containing 'substring'"
+	v3 := i.intern(StringValue(long[26:35]), true)
+	if v3.AsString() != "substring" || stringData(v3.AsString()) == stringData(long[26:35]) {
+		t.Error("expected substring to be copied")
+	}
+	v4 := i.intern(StringValue(long[26:35]), true)
+	if stringData(v4.AsString()) != stringData(v3.AsString()) {
+		t.Error("expected substring to share interned data")
+	}
+
+	// Interned strings are not discarded when many strings are interned.
+	for n := 0; n < 2*maxInternedStrings; n++ {
+		i.intern(StringValue(fmt.Sprintf("string number %d", n)), false)
+	}
+	kept := 0
+	for _, s := range i.slots {
+		if s != "" {
+			kept++
+		}
+	}
+	if kept < maxInternedStrings/2 {
+		t.Errorf("expected interner to stay full, only %d strings kept", kept)
+	}
+}
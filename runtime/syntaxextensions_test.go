@@ -0,0 +1,98 @@
+package runtime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	rt "github.com/arnodel/golua/runtime"
+	"github.com/arnodel/golua/token"
+)
+
+func TestSyntaxExtensions(t *testing.T) {
+	r := rt.New(nil, rt.WithSyntaxExtensions(token.AllExtensions))
+	lib.LoadAll(r)
+
+	clos, err := r.CompileAndLoadLuaChunk("good", []byte(`
+local t, n, s = {x = 1}, 10, "a"
+local function key() n += 1 return "x" end
+t[key()] += 2
+n *= 2
+s ..= "b"
+local out = {}
+for i = 1, 4 do
+    if i == 2 then continue end
+    out[#out + 1] = i
+end
+for _, v in ipairs({5, 6, 7}) do
+    if v == 6 then continue end
+    out[#out + 1] = v
+end
+local i = 0
+while i < 3 do
+    i += 1
+    if i == 2 then continue end
+    out[#out + 1] = i * 10
+end
+i = 0
+repeat
+    i += 1
+    if i == 2 then continue end
+    local j = i * 100
+    out[#out + 1] = j
+until i == 3
+return `+"`t.x={t.x} n={n} s={s} {`{nil}`} out={table.concat(out, \",\")} \\{`"+`
+`), rt.TableValue(r.GlobalEnv()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "t.x=3 n=22 s=ab nil out=1,3,4,5,7,10,30,100,300 {"
+	if s, _ := v.TryString(); s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+
+	// "continue" is only a keyword where it starts a continue statement.
+	clos, err = r.CompileAndLoadLuaChunk("names", []byte(`
+local continue, n = 0, 0
+for i = 1, 3 do
+    continue += i
+    if i == 2 then goto continue end
+    n += 1
+    ::continue::
+end
+return continue * 10 + n
+`), rt.TableValue(r.GlobalEnv()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = rt.Call1(r.MainThread(), rt.FunctionValue(clos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := v.TryInt(); n != 62 {
+		t.Errorf("got %v, want 62", v)
+	}
+
+	for src, msg := range map[string]string{
+		"continue": "'continue' outside a loop",
+		"for i = 1, 2 do (function() continue end)() end": "'continue' outside a loop",
+		"repeat continue local x = 1 until x":             "'continue' jumps into the scope of local 'x'",
+	} {
+		_, err := r.CompileAndLoadLuaChunk("bad", []byte(src), rt.NilValue)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: unexpected error: %v", src, err)
+		}
+	}
+
+	// Without the option, extensions are syntax errors.
+	for _, src := range []string{"local x = 1 x += 1", "for i = 1, 2 do continue end", "return `x`"} {
+		_, err = rt.New(nil).CompileAndLoadLuaChunk("plain", []byte(src), rt.NilValue)
+		if err == nil {
+			t.Errorf("%s: expected a syntax error", src)
+		}
+	}
+}
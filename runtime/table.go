@@ -19,6 +19,13 @@ func NewTable() *Table {
 	return &Table{mixedTable: &mixedTable{}}
 }
 
+// NewTableSize returns a new Table with room for narr values in its array part
+// (i.e. with keys 1 to narr) and nhash other values.  It does not require
+// memory, see Runtime.NewTableSize for that.
+func NewTableSize(narr, nhash int) *Table {
+	return &Table{mixedTable: newMixedTable(narr, nhash)}
+}
+
 // Metatable returns the table's metatable.
 func (t *Table) Metatable() *Table {
 	return t.meta
@@ -64,6 +71,26 @@ func (t *Table) Reset(k, v Value) (wasSet bool) {
 	return t.mixedTable.reset(k, v)
 }
 
+// getCached is like Get, but uses *hint as an inline cache for the location of
+// k.  The key k must not be a number (so that it is not in the array part).
+func (t *Table) getCached(k Value, hint *uint32) Value {
+	if it := t.hashTable.findSlotCached(k, hint); it != nil {
+		return it.value
+	}
+	return NilValue
+}
+
+// resetCached is like Reset for a non-nil v, but uses *hint as an inline cache
+// for the location of k.  The key k must not be a number.
+func (t *Table) resetCached(k, v Value, hint *uint32) (wasSet bool) {
+	it := t.hashTable.findSlotCached(k, hint)
+	if it == nil || it.value.IsNil() {
+		return false
+	}
+	it.value = v
+	return true
+}
+
 // Len returns a length for t (see lua docs for details).
 func (t *Table) Len() int64 {
 	return int64(t.mixedTable.len())
@@ -53,3 +53,48 @@ func TestTable_Next(t *testing.T) {
 		t.Errorf("Expected (1, x) and (2, y) to be the items, got (%v, %v) and (%v, %v)", k1, v1, k2, v2)
 	}
 }
+
+func TestTable_NewTableSize(t *testing.T) {
+	tbl := NewTableSize(4, 3)
+	if tbl.array.size() != 4 || len(tbl.hashTable.slots) != 4 {
+		t.Fatalf("Expected sizes 4 and 4, got %d and %d", tbl.array.size(), len(tbl.hashTable.slots))
+	}
+	for i := 1; i <= 10; i++ {
+		tbl.Set(v(i), v(i))
+	}
+	tbl.Set(v("x"), v(1))
+	tbl.Set(v("y"), v(2))
+	tbl.Set(v(3), NilValue)
+	if tlen := tbl.Len(); tlen != 10 {
+		t.Errorf("Expected table length to be 10, got %d", tlen)
+	}
+	if val := tbl.Get(v("y")); val != v(2) {
+		t.Errorf("Expected 2, got %v", val)
+	}
+	if val := tbl.Get(v(3)); !val.IsNil() {
+		t.Errorf("Expected nil, got %v", val)
+	}
+}
+
+func BenchmarkTable_Fill(b *testing.B) {
+	const n = 1000
+	variants := []struct {
+		name string
+		new  func() *Table
+	}{
+		{"NewTable", NewTable},
+		{"NewTableSize", func() *Table { return NewTableSize(n, n) }},
+	}
+	for _, vt := range variants {
+		vt := vt
+		b.Run(vt.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tbl := vt.new()
+				for j := 1; j <= n; j++ {
+					tbl.Set(IntValue(int64(j)), IntValue(int64(j)))
+					tbl.Set(IntValue(int64(-j)), IntValue(int64(j)))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package runtime_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+// Measure the performance of table constructors with many entries, which are
+// presized thanks to the size hints in the table opcode.
+func BenchmarkTableConstructors(b *testing.B) {
+	const n = 1000
+	var arr, hash, mixed strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&arr, "%d, ", i)
+		fmt.Fprintf(&hash, "k%d = %d, ", i, i)
+		fmt.Fprintf(&mixed, "%d, k%d = %d, ", i, i, i)
+	}
+	benchmarks := []struct {
+		name string
+		src  string
+	}{
+		{"array", "return {" + arr.String() + "}"},
+		{"hash", "return {" + hash.String() + "}"},
+		{"mixed", "return {" + mixed.String() + "}"},
+		{"create", `
+			local t = table.create(1000)
+			for i = 1, 1000 do t[i] = i end
+			return t
+		`},
+		{"nocreate", `
+			local t = {}
+			for i = 1, 1000 do t[i] = i end
+			return t
+		`},
+	}
+	for _, bm := range benchmarks {
+		bm := bm
+		b.Run(bm.name, func(b *testing.B) {
+			r := rt.New(nil)
+			defer lib.LoadAll(r)()
+			clos, err := r.CompileAndLoadLuaChunk("bench", []byte(bm.src), rt.TableValue(r.GlobalEnv()))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"errors"
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -30,6 +31,7 @@ type valuesError struct {
 	args      []Value     // arguments ot yield or resume
 	err       error       // execution error
 	exception interface{} // used when the thread should be closed right away
+	pending   *Pending    // set when the thread is waiting for pending to complete
 }
 
 // A Thread is a lua thread.
@@ -89,6 +91,7 @@ var errErrorInMessageHandler = StringValue("error in error handling")
 func (t *Thread) RunContinuation(c Cont) (err error) {
 	var next Cont
 	var errContCount = 0
+	prevCont := t.currentCont
 	_ = t.triggerCall(t, c)
 	for c != nil {
 		if t != t.gcThread {
@@ -97,24 +100,35 @@ func (t *Thread) RunContinuation(c Cont) (err error) {
 		t.currentCont = c
 		next, err = c.RunInThread(t)
 		if err != nil {
+			// A Lua continuation may have run other Lua continuations before
+			// the error occurred, in which case the current continuation is
+			// the one where the error is.
+			c = t.currentCont
 			rtErr := ToError(err)
 			if rtErr.Handled() {
+				t.currentCont = prevCont
 				return rtErr
 			}
-			err = rtErr.AddContext(c, -1)
+			rtErr = rtErr.AddContext(c, -1)
+			if t.errorTracebacks && rtErr.frames == nil {
+				rtErr.frames = t.Frames(c)
+			}
+			err = rtErr
 			errContCount++
 			if t.messageHandler != nil {
 				if errContCount > maxErrorsInMessageHandler {
+					t.currentCont = prevCont
 					return newHandledError(errErrorInMessageHandler)
 				}
-				next = t.messageHandler.Continuation(t, newMessageHandlerCont(c))
+				next = t.messageHandler.Continuation(t, newMessageHandlerCont(c, rtErr))
 			} else {
-				next = newMessageHandlerCont(c)
+				next = newMessageHandlerCont(c, rtErr)
 			}
 			next.Push(t.Runtime, ErrorValue(err))
 		}
 		c = next
 	}
+	t.currentCont = prevCont
 	return
 }
 
@@ -169,15 +183,37 @@ func (t *Thread) Status() ThreadStatus {
 
 // Resume execution of a suspended thread.  Its status switches to
 // running while its caller's status switches to suspended.
+//
+// If the thread becomes suspended waiting for a Pending continuation to
+// complete (see ResumeAsync), the caller waits as well and then resumes the
+// thread again, so from the point of view of the caller this is transparent.
 func (t *Thread) Resume(caller *Thread, args []Value) ([]Value, error) {
+	for {
+		res, pending, err := t.ResumeAsync(caller, args)
+		if pending == nil {
+			return res, err
+		}
+		if err := caller.await(pending); err != nil {
+			return nil, err
+		}
+		args = nil
+	}
+}
+
+// ResumeAsync is like Resume, except that if the thread becomes suspended
+// waiting for a Pending continuation to complete, it returns straight away with
+// that Pending value.  The caller should then resume the thread again (with no
+// arguments) once the Pending value is done.  This allows a host to run a number
+// of Lua threads concurrently in an event loop.
+func (t *Thread) ResumeAsync(caller *Thread, args []Value) ([]Value, *Pending, error) {
 	t.mux.Lock()
 	if t.status != ThreadSuspended {
 		t.mux.Unlock()
 		switch t.status {
 		case ThreadDead:
-			return nil, errors.New("cannot resume dead thread")
+			return nil, nil, errors.New("cannot resume dead thread")
 		default:
-			return nil, errors.New("cannot resume running thread")
+			return nil, nil, errors.New("cannot resume running thread")
 		}
 	}
 	caller.mux.Lock()
@@ -189,7 +225,8 @@ func (t *Thread) Resume(caller *Thread, args []Value) ([]Value, error) {
 	t.mux.Unlock()
 	caller.mux.Unlock()
 	t.sendResumeValues(args, nil, nil)
-	return caller.getResumeValues()
+	res := caller.receiveResumeValues()
+	return res.args, res.pending, res.err
 }
 
 // Close a suspended thread.  If successful, its status switches to dead.  The
@@ -235,6 +272,47 @@ func (t *Thread) Yield(args []Value) ([]Value, error) {
 		t.mux.Unlock()
 		return nil, errors.New("cannot yield from main thread")
 	}
+	return t.suspend(caller, valuesError{args: args})
+}
+
+// await suspends t until p is done.  If t was resumed by another thread, it
+// yields p to that thread (which may in turn wait for p).  Otherwise, there is
+// no one to yield to so it simply blocks until p is done.
+func (t *Thread) await(p *Pending) error {
+	t.mux.Lock()
+	if t.status != ThreadOK {
+		panic("Thread to suspend is not running")
+	}
+	caller := t.caller
+	if caller == nil {
+		t.mux.Unlock()
+		t.block(p)
+		return nil
+	}
+	_, err := t.suspend(caller, valuesError{pending: p})
+	return err
+}
+
+// block blocks until p is done.  If the runtime context has a time limit, it
+// terminates the context when the limit is reached.
+func (t *Thread) block(p *Pending) {
+	left, ok := t.timeLeft()
+	if !ok {
+		<-p.Done()
+		return
+	}
+	timer := time.NewTimer(left)
+	defer timer.Stop()
+	select {
+	case <-p.Done():
+	case <-timer.C:
+		t.TerminateContext("time limit of %d exceeded", t.HardLimits().Millis)
+	}
+}
+
+// suspend switches t to suspended, hands over v to the caller and waits to be
+// resumed.  It must be called with t.mux locked.
+func (t *Thread) suspend(caller *Thread, v valuesError) ([]Value, error) {
 	caller.mux.Lock()
 	if caller.status != ThreadOK {
 		panic("Caller of thread to yield is not OK")
@@ -243,7 +321,7 @@ func (t *Thread) Yield(args []Value) ([]Value, error) {
 	t.caller = nil
 	t.mux.Unlock()
 	caller.mux.Unlock()
-	caller.sendResumeValues(args, nil, nil)
+	caller.resumeCh <- v
 	return t.getResumeValues()
 }
 
@@ -277,11 +355,16 @@ func (t *Thread) call(c Callable, args []Value, next Cont) error {
 }
 
 func (t *Thread) getResumeValues() ([]Value, error) {
+	res := t.receiveResumeValues()
+	return res.args, res.err
+}
+
+func (t *Thread) receiveResumeValues() valuesError {
 	res := <-t.resumeCh
 	if res.exception != nil {
 		panic(res.exception)
 	}
-	return res.args, res.err
+	return res
 }
 
 func (t *Thread) sendResumeValues(args []Value, err error, exception interface{}) {
@@ -359,6 +442,29 @@ func (s *closeStack) truncate(h int) {
 	}
 }
 
+// CloseStackHeight returns the current height of the stack of to-be-closed
+// values.
+func (t *Thread) CloseStackHeight() int {
+	return t.closeStack.size()
+}
+
+// PushToBeClosed pushes v onto the stack of to-be-closed values.  It is an
+// error if v is neither false nor nil and has no "__close" metamethod.
+func (t *Thread) PushToBeClosed(v Value) error {
+	if Truth(v) && t.metaGetS(v, "__close").IsNil() {
+		return errors.New("to be closed value missing a __close metamethod")
+	}
+	t.closeStack.push(v)
+	return nil
+}
+
+// CloseToBeClosed truncates the stack of to-be-closed values to height h,
+// calling the "__close" metamethods of the values removed in the context of the
+// continuation c.
+func (t *Thread) CloseToBeClosed(c Cont, h int) error {
+	return t.cleanupCloseStack(c, h, nil)
+}
+
 // Truncate the close stack to size h, calling the __close metamethods in the
 // context of the given continuation c and feeding them with the given error.
 func (t *Thread) cleanupCloseStack(c Cont, h int, err error) error {
@@ -383,13 +489,14 @@ func (t *Thread) cleanupCloseStack(c Cont, h int, err error) error {
 // turns it to handled).
 //
 type messageHandlerCont struct {
-	c    Cont
-	err  Value
-	done bool
+	c      Cont
+	origin *Error // The error being handled
+	err    Value
+	done   bool
 }
 
-func newMessageHandlerCont(c Cont) *messageHandlerCont {
-	return &messageHandlerCont{c: c}
+func newMessageHandlerCont(c Cont, origin *Error) *messageHandlerCont {
+	return &messageHandlerCont{c: c, origin: origin}
 }
 
 var _ Cont = (*messageHandlerCont)(nil)
@@ -421,5 +528,5 @@ func (c *messageHandlerCont) PushEtc(r *Runtime, etc []Value) {
 }
 
 func (c *messageHandlerCont) RunInThread(t *Thread) (Cont, error) {
-	return nil, newHandledError(c.err)
+	return nil, c.origin.handledWith(c.err)
 }
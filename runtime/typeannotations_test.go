@@ -0,0 +1,46 @@
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/arnodel/golua/lib"
+	rt "github.com/arnodel/golua/runtime"
+)
+
+func TestTypeAnnotations(t *testing.T) {
+	r := rt.New(nil, rt.WithTypeAnnotations())
+	lib.LoadAll(r)
+
+	clos, err := r.CompileAndLoadLuaChunk("good", []byte(`
+type Point = {x: number, y: number}
+local function add(p: Point, q: Point): Point
+    return {x = p.x + q.x, y = p.y + q.y}
+end
+local p: Point = add({x = 1, y = 2}, {x = 3, y = 4})
+return load("local n: integer = ... return n * 2")(p.x + p.y)`), rt.TableValue(r.GlobalEnv()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := rt.Call1(r.MainThread(), rt.FunctionValue(clos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := v.TryInt(); n != 20 {
+		t.Errorf("got %v", v)
+	}
+
+	_, err = r.CompileAndLoadLuaChunk("bad", []byte(`
+local s: string = 1
+local function f(x: number) end
+f("x")`), rt.NilValue)
+	want := "bad:2:19: local 's': integer is not assignable to string\nbad:4:3: argument #1: string is not assignable to number"
+	if err == nil || err.Error() != want {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Without the option, annotations are syntax errors.
+	_, err = rt.New(nil).CompileAndLoadLuaChunk("plain", []byte("local x: number = 1"), rt.NilValue)
+	if err == nil {
+		t.Error("expected a syntax error")
+	}
+}
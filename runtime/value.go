@@ -104,9 +104,12 @@ func (v Value) Equals(v2 Value) bool {
 		return true
 	case string:
 		// Short strings are equal if their scalar are
-		if v.scalar != 0 {
+		if v.scalar != 0 && !hasCachedHash(v.scalar) {
 			return true
 		}
+		// Fast path for strings sharing the same data (e.g. interned strings)
+		y := v2.iface.(string)
+		return len(x) == len(y) && (stringData(x) == stringData(y) || x == y)
 	case *Closure:
 		return x.Equals(v2.iface.(*Closure))
 	}
@@ -117,6 +120,10 @@ func (v Value) Equals(v2 Value) bool {
 //go:noescape
 func goRuntimeInt64Hash(i uint64, seed uintptr) uintptr
 
+//go:linkname goRuntimeStrHash runtime.strhash
+//go:noescape
+func goRuntimeStrHash(p unsafe.Pointer, seed uintptr) uintptr
+
 //go:linkname goRuntimeEfaceHash runtime.efaceHash
 //go:noescape
 func goRuntimeEfaceHash(i interface{}, seed uintptr) uintptr
@@ -148,6 +155,10 @@ func BoolValue(b bool) Value {
 	return Value{s, dummyBool}
 }
 
+// Strings up to this length have their hash cached in the scalar field of
+// their value, and can be interned (see Runtime.InternString).
+const maxCachedHashStringLen = 40
+
 // StringValue returns a Value holding the given arg.
 func StringValue(s string) (v Value) {
 	v.iface = s
@@ -160,10 +171,42 @@ func StringValue(s string) (v Value) {
 		copy(bs, s)
 		bs[7] = byte(ls)
 		v.scalar = *(*uint64)(unsafe.Pointer(&bs[0]))
+	} else if ls <= maxCachedHashStringLen {
+		// Put the hash of the string in the scalar value, so that it is not
+		// computed again each time the string is used as a table key.  Byte 7
+		// is marked so that it cannot be mistaken for a short string.
+		v.scalar = uint64(goRuntimeStrHash(unsafe.Pointer(&s), 0))
+		(*[8]byte)(unsafe.Pointer(&v.scalar))[7] |= cachedHashFlag
 	}
 	return
 }
 
+// Flag set in byte 7 of the scalar field of a string value whose hash is
+// cached (for short strings, byte 7 contains the length so is at most 7).
+const cachedHashFlag = 0x80
+
+func hasCachedHash(scalar uint64) bool {
+	return (*[8]byte)(unsafe.Pointer(&scalar))[7]&cachedHashFlag != 0
+}
+
+// internKey returns the key to use to intern v if v is a string that can be
+// interned.
+func internKey(v Value) (uint64, bool) {
+	_, ok := v.iface.(string)
+	return v.scalar, ok && v.scalar != 0 && hasCachedHash(v.scalar)
+}
+
+// internedValue returns a value equal to the string value v, holding s (which
+// must be equal to the string held by v).
+func internedValue(v Value, s string) Value {
+	return Value{scalar: v.scalar, iface: s}
+}
+
+// stringData returns a pointer to the bytes of s.
+func stringData(s string) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&s))
+}
+
 // TableValue returns a Value holding the given arg.
 func TableValue(t *Table) Value {
 	return Value{iface: t}
@@ -386,6 +429,8 @@ func (v Value) AsCont() Cont {
 		return cont
 	case *messageHandlerCont:
 		return cont
+	case *Resumption:
+		return cont
 	default:
 		panic("value is not a continuation")
 	}
@@ -517,6 +562,8 @@ func (v Value) TryCont() (c Cont, ok bool) {
 		return cont, true
 	case *messageHandlerCont:
 		return cont, true
+	case *Resumption:
+		return cont, true
 	default:
 		return nil, false
 	}
@@ -40,6 +40,19 @@ func StringValue(s string) Value {
 	return Value{iface: s}
 }
 
+// internKey returns the key to use to intern v if v is a string that can be
+// interned.  Strings are never interned in this implementation, as their hash
+// is not cached.
+func internKey(v Value) (uint64, bool) {
+	return 0, false
+}
+
+// internedValue returns a value equal to the string value v, holding s (which
+// must be equal to the string held by v).
+func internedValue(v Value, s string) Value {
+	return Value{iface: s}
+}
+
 // TableValue returns a Value holding the given arg.
 func TableValue(t *Table) Value {
 	return Value{iface: t}
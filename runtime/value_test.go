@@ -245,3 +245,29 @@ func TestValue_AsCallable(t *testing.T) {
 		})
 	}
 }
+
+func TestValue_StringEquals(t *testing.T) {
+	r := New(nil)
+	strs := []string{
+		"", "a", "abcdefg", "abcdefgh", "abcdefgi",
+		strings.Repeat("x", 40), strings.Repeat("x", 39) + "y",
+		strings.Repeat("x", 41), strings.Repeat("x", 40) + "y",
+	}
+	for i, s1 := range strs {
+		for j, s2 := range strs {
+			// Build the strings from different data, interned or not.
+			v1 := StringValue(string([]byte(s1)))
+			v2 := r.InternString(string([]byte(s2)))
+			v3 := r.InternString(string([]byte(s2)))
+			if eq := v1.Equals(v2); eq != (i == j) {
+				t.Errorf("%q == %q: got %t", s1, s2, eq)
+			}
+			if !v2.Equals(v3) {
+				t.Errorf("%q should equal itself", s2)
+			}
+			if i == j && (v1.Hash() != v2.Hash() || v1 != v2) {
+				t.Errorf("%q: interned value is different", s1)
+			}
+		}
+	}
+}
@@ -18,6 +18,11 @@ type Scanner struct {
 	items            chan *token.Token // channel of scanned items.
 	state            stateFn
 	errorMsg         string
+	keepComments     bool
+	types            bool
+	extensions       token.Extensions
+	interpDepths     []int           // brace depth in each open interpolated string
+	comments         []token.Comment // comments to attach to the next token
 }
 
 type Option func(*Scanner)
@@ -29,6 +34,30 @@ func ForNumber() Option {
 	}
 }
 
+// WithComments makes the scanner keep comments, attaching them to the token
+// that follows them (see token.Token.Comments).
+func WithComments() Option {
+	return func(s *Scanner) {
+		s.keepComments = true
+	}
+}
+
+// WithTypes makes the scanner accept the tokens used in type annotations ("?"
+// and "->", see parsing.WithTypes).
+func WithTypes() Option {
+	return func(s *Scanner) {
+		s.types = true
+	}
+}
+
+// WithExtensions makes the scanner accept the tokens used by the given
+// extensions to the Lua syntax (see parsing.WithExtensions).
+func WithExtensions(ext token.Extensions) Option {
+	return func(s *Scanner) {
+		s.extensions |= ext
+	}
+}
+
 func WithStartLine(l int) Option {
 	return func(s *Scanner) {
 		pos := token.Pos{Line: l, Column: 1}
@@ -65,13 +94,29 @@ func (l *Scanner) emit(tp token.Type) {
 		panic("emit bails out")
 	}
 	l.items <- &token.Token{
-		Type: tp,
-		Lit:  lit,
-		Pos:  l.start,
+		Type:     tp,
+		Lit:      lit,
+		Pos:      l.start,
+		Comments: l.takeComments(),
 	}
 	l.start = l.pos
 }
 
+// comment skips over the pending input, which is a comment, keeping it if
+// required.
+func (l *Scanner) comment() {
+	if l.keepComments {
+		l.comments = append(l.comments, token.Comment{Lit: l.lit(), Pos: l.start})
+	}
+	l.ignore()
+}
+
+func (l *Scanner) takeComments() []token.Comment {
+	comments := l.comments
+	l.comments = nil
+	return comments
+}
+
 func (l *Scanner) lit() []byte {
 	return l.input[l.start.Offset:l.pos.Offset]
 }
@@ -152,9 +197,10 @@ func (l *Scanner) acceptRune(r rune) bool {
 func (l *Scanner) errorf(tp token.Type, format string, args ...interface{}) stateFn {
 	l.errorMsg = fmt.Sprintf(format, args...)
 	l.items <- &token.Token{
-		Type: tp,
-		Lit:  l.lit(),
-		Pos:  l.start,
+		Type:     tp,
+		Lit:      l.lit(),
+		Pos:      l.start,
+		Comments: l.takeComments(),
 	}
 	return nil
 }
@@ -230,3 +230,102 @@ func TestScanner(t *testing.T) {
 		})
 	}
 }
+
+func TestScannerWithComments(t *testing.T) {
+	src := "-- one\nx --[[ two\n]] --[ three\n--[==[ four ]==]"
+	scanner := New("test", []byte(src), WithComments())
+	var comments []token.Comment
+	for tok := scanner.Scan(); tok != nil; tok = scanner.Scan() {
+		if tok.Type == token.IDENT && len(tok.Comments) != 1 {
+			t.Fatalf("expected 1 comment before x, got %d", len(tok.Comments))
+		}
+		comments = append(comments, tok.Comments...)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	expected := []token.Comment{
+		{Lit: []byte("-- one"), Pos: token.Pos{Offset: 0, Line: 1, Column: 1}},
+		{Lit: []byte("--[[ two\n]]"), Pos: token.Pos{Offset: 9, Line: 2, Column: 3}},
+		{Lit: []byte("--[ three"), Pos: token.Pos{Offset: 21, Line: 3, Column: 4}},
+		{Lit: []byte("--[==[ four ]==]"), Pos: token.Pos{Offset: 31, Line: 4, Column: 1}},
+	}
+	if !reflect.DeepEqual(comments, expected) {
+		t.Fatalf("expected %q, got %q", expected, comments)
+	}
+}
+
+func TestScannerWithTypes(t *testing.T) {
+	src := "x: number? -> a-->c\n-b"
+	scanner := New("test", []byte(src), WithTypes())
+	var types []token.Type
+	for tok := scanner.Scan(); tok != nil; tok = scanner.Scan() {
+		types = append(types, tok.Type)
+		if tok.Type == token.EOF || tok.Type == token.INVALID {
+			break
+		}
+	}
+	expected := []token.Type{
+		token.IDENT, token.SgColon, token.IDENT, token.SgQuestion, token.SgArrow,
+		token.IDENT, token.SgMinus, token.IDENT, token.EOF,
+	}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("expected %v, got %v", expected, types)
+	}
+
+	// Without the option, "?" is illegal
+	scanner = New("test", []byte("x?"))
+	scanner.Scan()
+	if tok := scanner.Scan(); tok.Type != token.INVALID {
+		t.Fatalf("expected invalid token, got %s", tok)
+	}
+}
+
+func TestScannerWithExtensions(t *testing.T) {
+	src := "x += 1 y ..= `a{ {b} }c{d}e` continue `` -= >>="
+	scanner := New("test", []byte(src), WithExtensions(token.AllExtensions))
+	var toks []string
+	for tok := scanner.Scan(); tok != nil; tok = scanner.Scan() {
+		toks = append(toks, fmt.Sprintf("%d:%s", tok.Type, tok.Lit))
+		if tok.Type == token.EOF || tok.Type == token.INVALID {
+			break
+		}
+	}
+	expected := []string{
+		fmt.Sprintf("%d:x", token.IDENT),
+		fmt.Sprintf("%d:+=", token.SgOpAssign),
+		fmt.Sprintf("%d:1", token.NUMDEC),
+		fmt.Sprintf("%d:y", token.IDENT),
+		fmt.Sprintf("%d:..=", token.SgOpAssign),
+		fmt.Sprintf("%d:`a{", token.INTERPBEGIN),
+		fmt.Sprintf("%d:{", token.SgOpenBrace),
+		fmt.Sprintf("%d:b", token.IDENT),
+		fmt.Sprintf("%d:}", token.SgCloseBrace),
+		fmt.Sprintf("%d:}c{", token.INTERPMID),
+		fmt.Sprintf("%d:d", token.IDENT),
+		fmt.Sprintf("%d:}e`", token.INTERPEND),
+		fmt.Sprintf("%d:continue", token.IDENT),
+		fmt.Sprintf("%d:``", token.INTERPSTRING),
+		fmt.Sprintf("%d:-=", token.SgOpAssign),
+		fmt.Sprintf("%d:>>=", token.SgOpAssign),
+		fmt.Sprintf("%d:", token.EOF),
+	}
+	if !reflect.DeepEqual(toks, expected) {
+		t.Fatalf("expected %q, got %q", expected, toks)
+	}
+
+	// Without the option, the standard syntax is scanned
+	scanner = New("test", []byte("continue x+=1"))
+	var types []token.Type
+	for tok := scanner.Scan(); tok.Type != token.EOF; tok = scanner.Scan() {
+		types = append(types, tok.Type)
+	}
+	expectedTypes := []token.Type{token.IDENT, token.IDENT, token.SgPlus, token.SgAssign, token.NUMDEC}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Fatalf("expected %v, got %v", expectedTypes, types)
+	}
+	scanner = New("test", []byte("`x`"))
+	if tok := scanner.Scan(); tok.Type != token.INVALID {
+		t.Fatalf("expected invalid token, got %s", tok)
+	}
+}
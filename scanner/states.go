@@ -1,6 +1,8 @@
 package scanner
 
 import (
+	"strings"
+
 	"github.com/arnodel/golua/token"
 )
 
@@ -12,9 +14,18 @@ func scanToken(l *Scanner) stateFn {
 				return scanComment
 			}
 			l.backup()
-			l.emit(token.SgMinus)
+			switch {
+			case l.types && l.acceptRune('>'):
+				l.emit(token.SgArrow)
+			case l.acceptOpAssign():
+				l.emit(token.SgOpAssign)
+			default:
+				l.emit(token.SgMinus)
+			}
 		case c == '"' || c == '\'':
 			return scanShortString(c)
+		case c == '`' && l.extensions.Has(token.ExtInterpolation):
+			return scanInterpolatedString
 		case isDec(c):
 			l.backup()
 			return scanNumber
@@ -32,7 +43,20 @@ func scanToken(l *Scanner) stateFn {
 			l.ignore()
 		default:
 			switch c {
-			case ';', '(', ')', ',', '|', '&', '+', '*', '%', '^', '#', ']', '{', '}':
+			case ';', '(', ')', ',', '|', '&', '+', '*', '%', '^', '#', ']':
+			case '{':
+				if n := len(l.interpDepths); n > 0 {
+					l.interpDepths[n-1]++
+				}
+			case '}':
+				if n := len(l.interpDepths); n > 0 {
+					if l.interpDepths[n-1] == 0 {
+						// This closes an expression in an interpolated string
+						l.interpDepths = l.interpDepths[:n-1]
+						return scanInterpolatedString
+					}
+					l.interpDepths[n-1]--
+				}
 			case '=':
 				l.accept("=")
 			case ':':
@@ -52,18 +76,41 @@ func scanToken(l *Scanner) stateFn {
 				l.accept("=")
 			case '/':
 				l.accept("/")
+			case '?':
+				if !l.types {
+					return l.errorf(token.INVALID, "illegal character")
+				}
 			case -1:
 				l.emit(token.EOF)
 				return nil
 			default:
 				return l.errorf(token.INVALID, "illegal character")
 			}
-			l.emit(sgType[string(l.lit())])
+			if l.acceptOpAssign() {
+				l.emit(token.SgOpAssign)
+			} else {
+				l.emit(sgType[string(l.lit())])
+			}
 		}
 		return scanToken
 	}
 }
 
+// Operators that can be followed by "=" to make a compound assignment (see
+// token.ExtCompoundAssign).
+var compoundAssignOps = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "//": true, "%": true,
+	"^": true, "..": true, "&": true, "|": true, "<<": true, ">>": true,
+}
+
+// acceptOpAssign consumes a "=" following the pending input if it makes a
+// compound assignment operator and compound assignments are enabled.
+func (l *Scanner) acceptOpAssign() bool {
+	return l.extensions.Has(token.ExtCompoundAssign) &&
+		compoundAssignOps[string(l.lit())] &&
+		l.acceptRune('=')
+}
+
 func scanComment(l *Scanner) stateFn {
 	c := l.next()
 	if c == '[' {
@@ -77,11 +124,11 @@ func scanShortComment(l *Scanner) stateFn {
 	for {
 		switch c := l.next(); c {
 		case '\n':
-			l.acceptRune('\r')
-			l.ignore()
+			l.backup()
+			l.comment()
 			return scanToken
 		case -1:
-			l.ignore()
+			l.comment()
 			l.emit(token.EOF)
 			return nil
 		}
@@ -104,7 +151,7 @@ func scanLong(comment bool) stateFn {
 				break OpeningLoop
 			default:
 				if comment {
-					l.ignore()
+					l.backup()
 					return scanShortComment
 				}
 				return l.errorf(token.INVALID, "expected opening long bracket")
@@ -119,7 +166,7 @@ func scanLong(comment bool) stateFn {
 			case ']':
 				if closeLevel == level {
 					if comment {
-						l.ignore()
+						l.comment()
 					} else {
 						l.emit(token.LONGSTRING)
 					}
@@ -147,34 +194,8 @@ func scanShortString(q rune) stateFn {
 				l.emit(token.STRING)
 				return scanToken
 			case '\\':
-				switch c := l.next(); {
-				case c == 'x':
-					if accept(l, isHex, 2) != 2 {
-						return l.errorf(token.INVALID, `\x must be followed by 2 hex digits`)
-					}
-				case isDec(c):
-					accept(l, isDec, 2)
-				case c == 'u':
-					if l.next() != '{' {
-						return l.errorf(token.INVALID, `\u must be followed by '{'`)
-					}
-					if accept(l, isHex, -1) == 0 {
-						return l.errorf(token.INVALID, "at least 1 hex digit required")
-					}
-					if l.next() != '}' {
-						return l.errorf(token.INVALID, "missing '}'")
-					}
-				case c == 'z':
-					accept(l, isSpace, -1)
-				default:
-					switch c {
-					case '\n':
-						// Nothing to do
-					case 'a', 'b', 'f', 'n', 'r', 't', 'v', 'z', '"', '\'', '\\':
-						break
-					default:
-						return l.errorf(token.INVALID, "illegal escaped character")
-					}
+				if !scanEscapeSeq(l, "\"'\\") {
+					return nil
 				}
 			case '\n', '\r':
 				return l.errorf(token.INVALID, "illegal new line in string literal")
@@ -185,6 +206,83 @@ func scanShortString(q rune) stateFn {
 	}
 }
 
+// scanEscapeSeq scans an escape sequence in a string after the backslash.
+// The characters in escaped can be escaped as themselves.  It returns false
+// after emitting an error if the sequence is invalid.
+func scanEscapeSeq(l *Scanner, escaped string) bool {
+	switch c := l.next(); {
+	case c == 'x':
+		if accept(l, isHex, 2) != 2 {
+			l.errorf(token.INVALID, `\x must be followed by 2 hex digits`)
+			return false
+		}
+	case isDec(c):
+		accept(l, isDec, 2)
+	case c == 'u':
+		if l.next() != '{' {
+			l.errorf(token.INVALID, `\u must be followed by '{'`)
+			return false
+		}
+		if accept(l, isHex, -1) == 0 {
+			l.errorf(token.INVALID, "at least 1 hex digit required")
+			return false
+		}
+		if l.next() != '}' {
+			l.errorf(token.INVALID, "missing '}'")
+			return false
+		}
+	case c == 'z':
+		accept(l, isSpace, -1)
+	default:
+		switch c {
+		case '\n':
+			// Nothing to do
+		case 'a', 'b', 'f', 'n', 'r', 't', 'v', 'z':
+			break
+		default:
+			if !strings.ContainsRune(escaped, c) {
+				l.errorf(token.INVALID, "illegal escaped character")
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// scanInterpolatedString scans the text of an interpolated string up to the
+// end of the string or the start of an expression.  The pending input is the
+// opening "`" or the "}" closing the previous expression.
+func scanInterpolatedString(l *Scanner) stateFn {
+	first := l.input[l.start.Offset] == '`'
+	for {
+		switch c := l.next(); c {
+		case '`':
+			if first {
+				l.emit(token.INTERPSTRING)
+			} else {
+				l.emit(token.INTERPEND)
+			}
+			return scanToken
+		case '{':
+			if first {
+				l.emit(token.INTERPBEGIN)
+			} else {
+				l.emit(token.INTERPMID)
+			}
+			l.interpDepths = append(l.interpDepths, 0)
+			return scanToken
+		case '\\':
+			if !scanEscapeSeq(l, "\"'\\`{}") {
+				return nil
+			}
+		case '\n', '\r':
+			return l.errorf(token.INVALID, "illegal new line in string literal")
+		case -1:
+			return l.errorf(token.INVALID, "illegal <eof> in string literal")
+		}
+	}
+}
+
 // For scanning numbers e.g. in files
 func scanNumberPrefix(l *Scanner) stateFn {
 	accept(l, isSpace, -1)
@@ -296,6 +394,7 @@ var sgType = map[string]token.Type{
 	"=":  token.SgAssign,
 	"#":  token.SgHash,
 	"~":  token.SgTilde,
+	"?":  token.SgQuestion,
 }
 
 func scanIdent(l *Scanner) stateFn {
@@ -0,0 +1,175 @@
+// Package sourcemap maps positions in generated Lua code back to positions in
+// the original source files.  Attaching a source map to a chunk produced by a
+// transpiler makes error messages and tracebacks point to the code that was
+// written by hand rather than to the generated Lua code.
+//
+// Maps can be built with Map.Add or parsed from the JSON source map format
+// (version 3) that most transpilers produce.
+package sourcemap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// A Pos is a position in a source file.  Lines and columns start at 1, and 0
+// means unknown.
+type Pos struct {
+	Source string
+	Line   int
+	Column int
+}
+
+// String returns the position as "source:line:column", omitting the column if
+// it is unknown.
+func (p Pos) String() string {
+	if p.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", p.Source, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d", p.Source, p.Line)
+}
+
+// A Map maps positions in a generated chunk to positions in its original
+// sources.  The zero value is an empty map ready to use.
+type Map struct {
+	lines [][]segment // Segments of each generated line, sorted by column
+}
+
+// A segment maps the generated code from a column to the next segment.
+type segment struct {
+	column int
+	orig   Pos // Line is 0 if the generated code has no original position
+}
+
+// Add records that the generated code from the given line and column comes
+// from orig.  It applies until the next column added for the same line.  If
+// orig.Line is 0, the generated code from that column has no original
+// position.
+func (m *Map) Add(line, column int, orig Pos) {
+	if line < 1 {
+		return
+	}
+	for len(m.lines) < line {
+		m.lines = append(m.lines, nil)
+	}
+	segs := m.lines[line-1]
+	i := sort.Search(len(segs), func(i int) bool { return segs[i].column > column })
+	segs = append(segs, segment{})
+	copy(segs[i+1:], segs[i:])
+	segs[i] = segment{column: column, orig: orig}
+	m.lines[line-1] = segs
+}
+
+// Lookup returns the original position of the generated code at the given
+// line and column.  If the column is unknown (0) or before the first mapped
+// column of the line, the first segment of the line is used.  It returns false
+// if there is no original position for the generated code.
+func (m *Map) Lookup(line, column int) (Pos, bool) {
+	if m == nil || line < 1 || line > len(m.lines) {
+		return Pos{}, false
+	}
+	segs := m.lines[line-1]
+	if len(segs) == 0 {
+		return Pos{}, false
+	}
+	i := sort.Search(len(segs), func(i int) bool { return segs[i].column > column }) - 1
+	if i < 0 {
+		i = 0
+	}
+	orig := segs[i].orig
+	return orig, orig.Line > 0
+}
+
+// Parse parses a source map in the JSON format version 3 (see
+// https://sourcemaps.info/spec.html).  Names are ignored.
+func Parse(data []byte) (*Map, error) {
+	var v3 struct {
+		Version    int      `json:"version"`
+		SourceRoot string   `json:"sourceRoot"`
+		Sources    []string `json:"sources"`
+		Mappings   string   `json:"mappings"`
+	}
+	if err := json.Unmarshal(data, &v3); err != nil {
+		return nil, fmt.Errorf("sourcemap: %w", err)
+	}
+	if v3.Version != 3 {
+		return nil, fmt.Errorf("sourcemap: unsupported version %d", v3.Version)
+	}
+	sources := make([]string, len(v3.Sources))
+	for i, src := range v3.Sources {
+		if v3.SourceRoot != "" {
+			src = path.Join(v3.SourceRoot, src)
+		}
+		sources[i] = src
+	}
+	m := &Map{}
+	// All the fields are relative to the previous segment, except the
+	// generated column which is reset on each line.  They are all 0-based.
+	var fields [5]int
+	for i, line := range strings.Split(v3.Mappings, ";") {
+		fields[0] = 0
+		for _, seg := range strings.Split(line, ",") {
+			if seg == "" {
+				continue
+			}
+			n, err := decodeVLQs(seg, &fields)
+			if err != nil {
+				return nil, fmt.Errorf("sourcemap: line %d: %w", i+1, err)
+			}
+			var orig Pos
+			switch n {
+			case 1:
+				// No original position
+			case 4, 5:
+				if fields[1] < 0 || fields[1] >= len(sources) {
+					return nil, fmt.Errorf("sourcemap: line %d: invalid source index %d", i+1, fields[1])
+				}
+				orig = Pos{Source: sources[fields[1]], Line: fields[2] + 1, Column: fields[3] + 1}
+			default:
+				return nil, fmt.Errorf("sourcemap: line %d: invalid segment %q", i+1, seg)
+			}
+			m.Add(i+1, fields[0]+1, orig)
+		}
+	}
+	return m, nil
+}
+
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+var errInvalidVLQ = errors.New("invalid base64 VLQ")
+
+// decodeVLQs adds the base64 VLQ values in seg to the fields and returns the
+// number of values.
+func decodeVLQs(seg string, fields *[5]int) (int, error) {
+	n := 0
+	value, shift := 0, 0
+	for i := 0; i < len(seg); i++ {
+		digit := strings.IndexByte(base64Digits, seg[i])
+		if digit < 0 || shift > 25 {
+			return 0, errInvalidVLQ
+		}
+		value |= (digit & 31) << shift
+		if digit&32 != 0 {
+			shift += 5
+			continue
+		}
+		if n == len(fields) {
+			return 0, errInvalidVLQ
+		}
+		if value&1 != 0 {
+			fields[n] -= value >> 1
+		} else {
+			fields[n] += value >> 1
+		}
+		n++
+		value, shift = 0, 0
+	}
+	if shift != 0 {
+		return 0, errInvalidVLQ
+	}
+	return n, nil
+}
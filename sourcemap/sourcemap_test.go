@@ -0,0 +1,80 @@
+package sourcemap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const src = `{
+		"version": 3,
+		"file": "out.lua",
+		"sourceRoot": "src",
+		"sources": ["a.tl", "b.tl"],
+		"names": [],
+		"mappings": "AAAA,IAAI;;AACA,EAAD,CCAgB,E;"
+	}`
+	m, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line, column int
+		pos          string
+	}{
+		{1, 0, "src/a.tl:1:1"},
+		{1, 4, "src/a.tl:1:1"},
+		{1, 5, "src/a.tl:1:5"},
+		{1, 100, "src/a.tl:1:5"},
+		{2, 1, ""},
+		{3, 1, "src/a.tl:2:5"},
+		{3, 3, "src/a.tl:2:4"},
+		{3, 4, "src/b.tl:2:20"},
+		{3, 6, ""},
+		{4, 1, ""},
+	}
+	for _, test := range tests {
+		pos, ok := m.Lookup(test.line, test.column)
+		if ok != (test.pos != "") || ok && pos.String() != test.pos {
+			t.Errorf("%d:%d: expected %q, got %q (%t)", test.line, test.column, test.pos, pos, ok)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src, err string
+	}{
+		{`[]`, "cannot unmarshal"},
+		{`{"version": 2}`, "unsupported version 2"},
+		{`{"version": 3, "sources": ["a"], "mappings": "AA"}`, "invalid segment"},
+		{`{"version": 3, "sources": ["a"], "mappings": "ACAA"}`, "invalid source index 1"},
+		{`{"version": 3, "sources": ["a"], "mappings": "AA!A"}`, "invalid base64 VLQ"},
+		{`{"version": 3, "sources": ["a"], "mappings": "AAAg"}`, "invalid base64 VLQ"},
+	}
+	for _, test := range tests {
+		_, err := Parse([]byte(test.src))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected error %q, got %v", test.src, test.err, err)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	var m Map
+	m.Add(2, 10, Pos{Source: "x", Line: 5, Column: 3})
+	m.Add(2, 1, Pos{Source: "x", Line: 4})
+	m.Add(2, 20, Pos{})
+	if _, ok := m.Lookup(1, 1); ok {
+		t.Error("expected no position on line 1")
+	}
+	if pos, _ := m.Lookup(2, 9); pos.String() != "x:4" {
+		t.Errorf("got %s", pos)
+	}
+	if pos, _ := m.Lookup(2, 19); pos.String() != "x:5:3" {
+		t.Errorf("got %s", pos)
+	}
+	if _, ok := m.Lookup(2, 20); ok {
+		t.Error("expected no position from column 20")
+	}
+}
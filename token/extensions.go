@@ -0,0 +1,89 @@
+package token
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Extensions is a set of extensions to the Lua syntax.  They are all disabled
+// by default so that standard Lua is parsed strictly.  They can be enabled for
+// a chunk with scanner.WithExtensions and parsing.WithExtensions.
+type Extensions uint
+
+const (
+	// ExtCompoundAssign enables compound assignments, e.g. "x += 1".  The
+	// operators are +, -, *, /, //, %, ^, .., &, |, << and >>.
+	ExtCompoundAssign Extensions = 1 << iota
+
+	// ExtContinue enables the "continue" statement in loops.  "continue" is
+	// not reserved: it only starts a continue statement at the start of a
+	// statement, when it is not followed by something that makes it an
+	// assignment or a function call.
+	ExtContinue
+
+	// ExtInterpolation enables interpolated strings, e.g. `x = {x}`.  The
+	// expressions in braces are converted to strings with tostring.
+	ExtInterpolation
+)
+
+const (
+	// NoExtensions disables all the extensions.
+	NoExtensions Extensions = 0
+
+	// AllExtensions enables all the extensions.
+	AllExtensions = ExtCompoundAssign | ExtContinue | ExtInterpolation
+)
+
+var extensionNames = []struct {
+	ext  Extensions
+	name string
+}{
+	{ExtCompoundAssign, "compound"},
+	{ExtContinue, "continue"},
+	{ExtInterpolation, "interpolation"},
+}
+
+// Has returns true if all the extensions in ext are enabled.
+func (e Extensions) Has(ext Extensions) bool {
+	return e&ext == ext
+}
+
+// String returns the names of the extensions in e separated by commas.
+func (e Extensions) String() string {
+	var names []string
+	for _, n := range extensionNames {
+		if e.Has(n.ext) {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseExtensions parses a list of extension names separated by commas, as
+// returned by Extensions.String.  The name "all" stands for all the
+// extensions.
+func ParseExtensions(s string) (Extensions, error) {
+	var e Extensions
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			e |= AllExtensions
+			continue
+		}
+		found := false
+		for _, n := range extensionNames {
+			if n.name == name {
+				e |= n.ext
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown syntax extension %q", name)
+		}
+	}
+	return e, nil
+}
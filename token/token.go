@@ -8,6 +8,13 @@ type Token struct {
 	Type
 	Lit []byte
 	Pos
+	Comments []Comment // Comments preceding the token (if kept by the scanner)
+}
+
+// A Comment is a comment in Lua source code (including the leading "--").
+type Comment struct {
+	Lit []byte
+	Pos
 }
 
 func (t *Token) String() string {
@@ -29,6 +36,16 @@ const (
 	NUMHEX
 	IDENT
 
+	// Interpolated strings (see ExtInterpolation).  An interpolated string
+	// without expressions is a single INTERPSTRING token.  Otherwise it starts
+	// with an INTERPBEGIN token (e.g. "`x = {"), its expressions are separated
+	// by INTERPMID tokens (e.g. "}, y = {") and it ends with an INTERPEND token
+	// (e.g. "}`").
+	INTERPSTRING
+	INTERPBEGIN
+	INTERPMID
+	INTERPEND
+
 	KwBreak
 	KwGoto
 	KwDo
@@ -65,6 +82,9 @@ const (
 	SgDoubleColon
 	SgAssign
 	SgHash
+	SgQuestion // Only in type annotations
+	SgArrow    // Only in type annotations
+	SgOpAssign // Only with ExtCompoundAssign, e.g. "+=" or "..="
 
 	beforeBinOp
 
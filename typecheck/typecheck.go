@@ -0,0 +1,1446 @@
+// Package typecheck checks the type annotations of Lua code parsed with
+// parsing.WithTypes.
+//
+// Typing is gradual: values whose type is not known (e.g. global variables,
+// local variables and parameters without annotations, values returned by
+// functions without annotations) have type "any", which is compatible with all
+// types.  So code without annotations never has type errors, and annotations
+// can be added progressively.  Local variables without annotations have type
+// any, except when they are initialised with an annotated function.
+//
+// The types are:
+//
+//	any, nil, boolean, number, integer, string, table, function, thread, userdata
+//	T?                     T or nil
+//	T1 | T2                T1 or T2
+//	{T}                    a table whose values of type T are indexed by integers
+//	{[K]: V}               a table with keys of type K and values of type V
+//	{name1: T1, ...}       a table with the given fields
+//	(P1, P2, ...P) -> R    a function with the given parameter and return types
+//	(P1, P2) -> (R1, ...R)
+//	Name                   an alias defined with "type Name = T"
+//
+// The checker reports values whose type does not match an annotation (in
+// assignments, function arguments and return values), calls with the wrong
+// number of arguments, accesses to fields that do not exist and operations
+// which always fail (e.g. arithmetic on booleans).  The type of a local
+// variable is narrowed in the body of "if x then" and "if x ~= nil then"
+// statements so that it excludes nil.
+package typecheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/ops"
+	"github.com/arnodel/golua/token"
+)
+
+// An Error is a type error found in a chunk.
+type Error struct {
+	ast.Location
+	Message string
+}
+
+// Error returns the error in the "line:col: message" format.
+func (e Error) Error() string {
+	if pos := e.StartPos(); pos != nil {
+		return fmt.Sprintf("%d:%d: %s", pos.Line, pos.Column, e.Message)
+	}
+	return e.Message
+}
+
+// Check checks the type annotations of a chunk and returns the type errors
+// found, in source order.
+func Check(chunk ast.BlockStat) []Error {
+	c := &checker{fn: &funcContext{returns: anyValues, dots: anyType}}
+	c.block(chunk)
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		pi, pj := c.errors[i].StartPos(), c.errors[j].StartPos()
+		return pi != nil && (pj == nil || pi.Offset < pj.Offset)
+	})
+	return c.errors
+}
+
+// Strip returns a copy of the chunk without type annotations.  The chunk is
+// not modified.
+func Strip(chunk ast.BlockStat) ast.BlockStat {
+	return ast.Rewrite(chunk, func(n ast.Node) ast.Node {
+		switch x := n.(type) {
+		case ast.BlockStat:
+			x.TypeAliases = nil
+			return x
+		case ast.LocalStat:
+			nameAttribs := make([]ast.NameAttrib, len(x.NameAttribs))
+			for i, na := range x.NameAttribs {
+				na.Type = nil
+				nameAttribs[i] = na
+			}
+			x.NameAttribs = nameAttribs
+			return x
+		case ast.Function:
+			x.ParamTypes = nil
+			x.DotsType = nil
+			x.ReturnTypes = nil
+			return x
+		}
+		return n
+	}).(ast.BlockStat)
+}
+
+// A variable is a local variable.
+type variable struct {
+	declared Type // The type of values that can be assigned to the variable
+	current  Type // The type of the variable where it is used
+}
+
+type scope struct {
+	parent *scope
+	vars   map[string]*variable
+	types  map[string]*aliasType
+}
+
+func (s *scope) lookupVar(name string) *variable {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func (s *scope) lookupType(name string) *aliasType {
+	for ; s != nil; s = s.parent {
+		if t, ok := s.types[name]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// funcContext holds information about the function being checked.
+type funcContext struct {
+	returns typeList // The types of the values the function returns
+	checked bool     // True if the values returned must be checked
+	dots    Type     // The type of "...", nil if the function has none
+
+	end ast.Locator // Where missing return values are reported for bare returns
+}
+
+type checker struct {
+	scope  *scope
+	fn     *funcContext
+	errors []Error
+}
+
+// builtinGlobals are the types of standard library functions which are checked
+// when they are not shadowed by local variables.
+var builtinGlobals = map[string]Type{
+	"tostring": &funcType{params: typeList{types: []Type{anyType}}, returns: typeList{types: []Type{stringType}}},
+	"type":     &funcType{params: typeList{types: []Type{anyType}}, returns: typeList{types: []Type{stringType}}},
+}
+
+func (c *checker) errorf(loc ast.Locator, format string, args ...interface{}) {
+	c.errors = append(c.errors, Error{
+		Location: loc.Locate(),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+//
+// Scopes
+//
+
+func (c *checker) pushScope() {
+	c.scope = &scope{
+		parent: c.scope,
+		vars:   map[string]*variable{},
+		types:  map[string]*aliasType{},
+	}
+}
+
+func (c *checker) popScope() {
+	c.scope = c.scope.parent
+}
+
+func (c *checker) declare(name string, t Type) {
+	c.scope.vars[name] = &variable{declared: t, current: t}
+}
+
+// declareTypes declares type aliases in the current scope.
+func (c *checker) declareTypes(aliases []ast.TypeAlias) {
+	var declared []*aliasType
+	for _, alias := range aliases {
+		name := alias.Name.Val
+		switch {
+		case basicTypes[name] != "":
+			c.errorf(alias.Name, "cannot redefine builtin type '%s'", name)
+		case c.scope.types[name] != nil:
+			c.errorf(alias.Name, "type '%s' is already defined", name)
+		default:
+			t := &aliasType{name: name, def: alias.Type, scope: c.scope}
+			c.scope.types[name] = t
+			declared = append(declared, t)
+		}
+	}
+	// Resolve the aliases now to report errors in their definitions.
+	for _, t := range declared {
+		c.underlying(t)
+	}
+}
+
+//
+// Types
+//
+
+// resolveType returns the type for a type annotation in the current scope.
+func (c *checker) resolveType(n ast.TypeNode) Type {
+	return c.resolveTypeIn(c.scope, n)
+}
+
+func (c *checker) resolveTypeIn(s *scope, n ast.TypeNode) Type {
+	switch x := n.(type) {
+	case ast.NamedType:
+		if t := s.lookupType(x.Name); t != nil {
+			return t
+		}
+		if t, ok := basicTypes[x.Name]; ok {
+			return t
+		}
+		c.errorf(x, "unknown type '%s'", x.Name)
+		return anyType
+	case ast.OptionalType:
+		return newUnion(c.resolveTypeIn(s, x.Type), nilType)
+	case ast.UnionType:
+		types := make([]Type, len(x.Types))
+		for i, t := range x.Types {
+			types[i] = c.resolveTypeIn(s, t)
+		}
+		return newUnion(types...)
+	case ast.ArrayType:
+		return &arrayType{elem: c.resolveTypeIn(s, x.Elem)}
+	case ast.MapType:
+		return &mapType{key: c.resolveTypeIn(s, x.Key), value: c.resolveTypeIn(s, x.Value)}
+	case ast.RecordType:
+		rec := newRecordType()
+		for _, f := range x.Fields {
+			if _, ok := rec.fields[f.Name.Val]; ok {
+				c.errorf(f.Name, "duplicate field '%s'", f.Name.Val)
+				continue
+			}
+			rec.addField(f.Name.Val, c.resolveTypeIn(s, f.Type))
+		}
+		return rec
+	case ast.FunctionType:
+		return &funcType{
+			params:  c.resolveTypeListIn(s, x.Params),
+			returns: c.resolveTypeListIn(s, x.Returns),
+		}
+	default:
+		panic(fmt.Sprintf("typecheck: unexpected type node %T", n))
+	}
+}
+
+func (c *checker) resolveTypeListIn(s *scope, l ast.TypeList) typeList {
+	var tl typeList
+	for _, t := range l.Types {
+		tl.types = append(tl.types, c.resolveTypeIn(s, t))
+	}
+	if l.Etc != nil {
+		tl.etc = c.resolveTypeIn(s, l.Etc)
+	}
+	return tl
+}
+
+// underlying returns the type t refers to if it is an alias, t otherwise.
+func (c *checker) underlying(t Type) Type {
+	var seen map[*aliasType]bool
+	for {
+		alias, ok := t.(*aliasType)
+		if !ok {
+			return t
+		}
+		if seen[alias] {
+			c.errorf(alias.def, "type alias '%s' is circular", alias.name)
+			alias.resolved = anyType
+			return anyType
+		}
+		if seen == nil {
+			seen = map[*aliasType]bool{}
+		}
+		seen[alias] = true
+		if alias.resolved == nil {
+			alias.resolved = c.resolveTypeIn(alias.scope, alias.def)
+		}
+		t = alias.resolved
+	}
+}
+
+// assignable returns true if values of type src can be used where values of
+// type dst are expected.
+func (c *checker) assignable(src, dst Type) bool {
+	return c.assignableSeen(src, dst, map[[2]Type]bool{})
+}
+
+func (c *checker) assignableSeen(src, dst Type, seen map[[2]Type]bool) bool {
+	if src == dst {
+		return true
+	}
+	// Recursive types are assignable if they are assignable assuming that
+	// their recursive occurrences are.
+	key := [2]Type{src, dst}
+	if seen[key] {
+		return true
+	}
+	seen[key] = true
+	src, dst = c.underlying(src), c.underlying(dst)
+	if src == dst || src == anyType || dst == anyType {
+		return true
+	}
+	if u, ok := src.(*unionType); ok {
+		for _, t := range u.types {
+			if !c.assignableSeen(t, dst, seen) {
+				return false
+			}
+		}
+		return true
+	}
+	if u, ok := dst.(*unionType); ok {
+		for _, t := range u.types {
+			if c.assignableSeen(src, t, seen) {
+				return true
+			}
+		}
+		return false
+	}
+	switch d := dst.(type) {
+	case basicType:
+		switch d {
+		case numberType:
+			return src == integerType
+		case tableType:
+			return isTableType(src)
+		case functionType:
+			_, ok := src.(*funcType)
+			return ok
+		}
+	case *arrayType:
+		switch s := src.(type) {
+		case basicType:
+			return s == tableType
+		case *arrayType:
+			return c.assignableSeen(s.elem, d.elem, seen)
+		case *mapType:
+			return c.assignableSeen(s.key, numberType, seen) && c.assignableSeen(s.value, d.elem, seen)
+		case *recordType:
+			return len(s.names) == 0
+		}
+	case *mapType:
+		switch s := src.(type) {
+		case basicType:
+			return s == tableType
+		case *arrayType:
+			return c.assignableSeen(integerType, d.key, seen) && c.assignableSeen(s.elem, d.value, seen)
+		case *mapType:
+			return c.assignableSeen(s.key, d.key, seen) && c.assignableSeen(s.value, d.value, seen)
+		case *recordType:
+			if len(s.names) > 0 && !c.assignableSeen(stringType, d.key, seen) {
+				return false
+			}
+			for _, name := range s.names {
+				if !c.assignableSeen(s.fields[name], d.value, seen) {
+					return false
+				}
+			}
+			return true
+		}
+	case *recordType:
+		switch s := src.(type) {
+		case basicType:
+			return s == tableType
+		case *recordType:
+			for _, name := range d.names {
+				st, ok := s.fields[name]
+				if !ok {
+					st = nilType
+				}
+				if !c.assignableSeen(st, d.fields[name], seen) {
+					return false
+				}
+			}
+			return true
+		}
+	case *funcType:
+		switch s := src.(type) {
+		case basicType:
+			return s == functionType
+		case *funcType:
+			// Parameters are contravariant
+			for i, dp := range d.params.types {
+				if sp := s.params.at(i); sp != nil && !c.assignableSeen(dp, sp, seen) {
+					return false
+				}
+			}
+			if d.params.etc != nil && s.params.etc != nil && !c.assignableSeen(d.params.etc, s.params.etc, seen) {
+				return false
+			}
+			// Return values are covariant
+			for i, dr := range d.returns.types {
+				sr := s.returns.at(i)
+				if sr == nil {
+					sr = nilType
+				}
+				if !c.assignableSeen(sr, dr, seen) {
+					return false
+				}
+			}
+			if d.returns.etc != nil {
+				for _, sr := range s.returns.types[min(len(d.returns.types), len(s.returns.types)):] {
+					if !c.assignableSeen(sr, d.returns.etc, seen) {
+						return false
+					}
+				}
+				if s.returns.etc != nil && !c.assignableSeen(s.returns.etc, d.returns.etc, seen) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func isTableType(t Type) bool {
+	switch t.(type) {
+	case *arrayType, *mapType, *recordType:
+		return true
+	}
+	return t == tableType
+}
+
+// canBeFalsy returns true if values of type t can be nil or false.
+func (c *checker) canBeFalsy(t Type) bool {
+	switch u := c.underlying(t).(type) {
+	case basicType:
+		return u == anyType || u == nilType || u == booleanType
+	case *unionType:
+		for _, m := range u.types {
+			if c.canBeFalsy(m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeNil returns the type t without nil.
+func (c *checker) removeNil(t Type) Type {
+	u, ok := c.underlying(t).(*unionType)
+	if !ok {
+		return t
+	}
+	var types []Type
+	for _, m := range u.types {
+		if m != nilType {
+			types = append(types, m)
+		}
+	}
+	return newUnion(types...)
+}
+
+// isNumber returns true if values of type t are always numbers.
+func (c *checker) isNumber(t Type) bool {
+	u := c.underlying(t)
+	return u == numberType || u == integerType
+}
+
+// checkAssignable reports an error if values of type t cannot be used where
+// values of type want are expected.
+func (c *checker) checkAssignable(t, want Type, loc ast.Locator, desc string) {
+	if !c.assignable(t, want) {
+		c.errorf(loc, "%s: %s is not assignable to %s", desc, t, want)
+	}
+}
+
+//
+// Statements
+//
+
+// block checks a block in a new scope.
+func (c *checker) block(b ast.BlockStat) {
+	c.pushScope()
+	c.blockContents(b)
+	c.popScope()
+}
+
+// blockContents checks a block in the current scope.
+func (c *checker) blockContents(b ast.BlockStat) {
+	c.declareTypes(b.TypeAliases)
+	for _, s := range b.Stats {
+		c.stat(s)
+	}
+	if b.Return != nil {
+		c.returnValues(b.Return)
+	}
+}
+
+func (c *checker) stat(s ast.Stat) {
+	switch x := s.(type) {
+	case ast.AssignStat:
+		c.assign(x)
+	case ast.BlockStat:
+		c.block(x)
+	case ast.CompoundAssignStat:
+		c.compoundAssign(x)
+	case ast.BreakStat, ast.ContinueStat, ast.EmptyStat, ast.GotoStat, ast.LabelStat:
+		// Nothing to check
+	case *ast.ForInStat:
+		c.forIn(*x)
+	case ast.ForInStat:
+		c.forIn(x)
+	case *ast.ForStat:
+		c.forStat(*x)
+	case ast.ForStat:
+		c.forStat(x)
+	case ast.FunctionCall:
+		c.call(*x.BFunctionCall)
+	case ast.IfStat:
+		c.ifStat(x)
+	case ast.LocalFunctionStat:
+		ft := c.functionType(x.Function)
+		c.declare(x.Name.Val, ft)
+		c.functionBody(x.Function, ft)
+	case ast.LocalStat:
+		c.local(x)
+	case ast.RepeatStat:
+		c.pushScope()
+		c.blockContents(x.Body)
+		c.exp(x.Cond)
+		c.popScope()
+	case ast.WhileStat:
+		c.exp(x.Cond)
+		c.block(x.Body)
+	default:
+		panic(fmt.Sprintf("typecheck: unexpected statement %T", s))
+	}
+}
+
+func (c *checker) local(s ast.LocalStat) {
+	want := typeList{types: make([]Type, len(s.NameAttribs))}
+	for i, na := range s.NameAttribs {
+		if na.Type != nil {
+			want.types[i] = c.resolveType(na.Type)
+		}
+	}
+	var got []Type
+	if len(s.Values) > 0 {
+		got = c.checkValues(s.Values, want, false, s, func(i int) string {
+			return fmt.Sprintf("local '%s'", s.NameAttribs[i].Name.Val)
+		})
+	}
+	for i, na := range s.NameAttribs {
+		t := want.types[i]
+		if t == nil {
+			t = anyType
+			if i < len(got) {
+				if ft, ok := got[i].(*funcType); ok {
+					t = ft
+				}
+			}
+		}
+		c.declare(na.Name.Val, t)
+	}
+}
+
+func (c *checker) assign(s ast.AssignStat) {
+	want := typeList{types: make([]Type, len(s.Dest))}
+	for i, dest := range s.Dest {
+		switch x := dest.(type) {
+		case ast.Name:
+			if v := c.scope.lookupVar(x.Val); v != nil {
+				want.types[i] = v.declared
+				v.current = v.declared
+			}
+		case ast.IndexExp:
+			want.types[i] = c.index(x)
+		default:
+			c.exp(dest)
+		}
+	}
+	c.checkValues(s.Src, want, false, s, func(i int) string {
+		return destDesc(s.Dest[i])
+	})
+}
+
+// compoundAssign checks "dest op= value" as "dest = dest op value".
+func (c *checker) compoundAssign(s ast.CompoundAssignStat) {
+	t := c.binOpType(s.Op, c.exp(s.Dest), c.exp(s.Value), s)
+	var want Type
+	switch x := s.Dest.(type) {
+	case ast.Name:
+		if v := c.scope.lookupVar(x.Val); v != nil {
+			want = v.declared
+			v.current = v.declared
+		}
+	case ast.IndexExp:
+		want = c.index(x)
+	}
+	if want != nil {
+		c.checkAssignable(t, want, s.Value, destDesc(s.Dest))
+	}
+}
+
+// destDesc describes the destination of an assignment in error messages.
+func destDesc(dest ast.Var) string {
+	switch x := dest.(type) {
+	case ast.Name:
+		return fmt.Sprintf("variable '%s'", x.Val)
+	case ast.IndexExp:
+		if k, ok := x.Idx.(ast.String); ok {
+			return fmt.Sprintf("field '%s'", k.Val)
+		}
+	}
+	return "value"
+}
+
+func (c *checker) ifStat(s ast.IfStat) {
+	c.condStat(s.If)
+	for _, cs := range s.ElseIfs {
+		c.condStat(cs)
+	}
+	if s.Else != nil {
+		c.block(*s.Else)
+	}
+}
+
+// condStat checks the condition and body of an if or elseif clause, narrowing
+// the types of local variables in the body.
+func (c *checker) condStat(s ast.CondStat) {
+	c.exp(s.Cond)
+	c.pushScope()
+	for name, t := range c.narrowing(s.Cond) {
+		v := c.scope.lookupVar(name)
+		c.scope.vars[name] = &variable{declared: v.declared, current: t}
+	}
+	c.blockContents(s.Body)
+	c.popScope()
+}
+
+// narrowing returns the types of local variables which are narrowed when the
+// condition is true.
+func (c *checker) narrowing(cond ast.ExpNode) map[string]Type {
+	narrowed := map[string]Type{}
+	var visit func(e ast.ExpNode)
+	narrowName := func(e ast.ExpNode) {
+		if name, ok := e.(ast.Name); ok {
+			if v := c.scope.lookupVar(name.Val); v != nil {
+				narrowed[name.Val] = c.removeNil(v.current)
+			}
+		}
+	}
+	visit = func(e ast.ExpNode) {
+		switch x := derefExp(e).(type) {
+		case ast.Name:
+			narrowName(x)
+		case ast.BinOp:
+			switch x.OpType {
+			case ops.OpAnd:
+				visit(x.Left)
+				for _, op := range x.Right {
+					visit(op.Operand)
+				}
+			case ops.OpNeq.Type():
+				if len(x.Right) != 1 || x.Right[0].Op != ops.OpNeq {
+					return
+				}
+				left, right := x.Left, x.Right[0].Operand
+				if _, ok := right.(ast.Nil); ok {
+					narrowName(left)
+				} else if _, ok := left.(ast.Nil); ok {
+					narrowName(right)
+				}
+			}
+		}
+	}
+	visit(cond)
+	return narrowed
+}
+
+func (c *checker) forStat(s ast.ForStat) {
+	start := c.exp(s.Start)
+	stop := c.exp(s.Stop)
+	step := c.exp(s.Step)
+	for _, p := range []struct {
+		t    Type
+		exp  ast.ExpNode
+		what string
+	}{{start, s.Start, "initial value"}, {stop, s.Stop, "limit"}, {step, s.Step, "step"}} {
+		if !c.assignable(p.t, numberType) {
+			c.errorf(p.exp, "'for' %s must be a number, not %s", p.what, p.t)
+		}
+	}
+	varType := Type(anyType)
+	switch {
+	case c.underlying(start) == integerType && c.underlying(step) == integerType:
+		varType = integerType
+	case c.isNumber(start) && c.isNumber(step):
+		varType = numberType
+	}
+	c.pushScope()
+	c.declare(s.Var.Val, varType)
+	c.blockContents(s.Body)
+	c.popScope()
+}
+
+func (c *checker) forIn(s ast.ForInStat) {
+	varTypes := c.iteratorTypes(s.Params)
+	c.pushScope()
+	for i, v := range s.Vars {
+		t := Type(anyType)
+		if i < len(varTypes) {
+			t = varTypes[i]
+		}
+		c.declare(v.Val, t)
+	}
+	c.blockContents(s.Body)
+	c.popScope()
+}
+
+// iteratorTypes checks the expressions of a generic for loop and returns the
+// types of the loop variables when they are known, i.e. when iterating over a
+// typed table with ipairs or pairs.
+func (c *checker) iteratorTypes(params []ast.ExpNode) []Type {
+	if len(params) == 1 {
+		if call, ok := params[0].(ast.FunctionCall); ok && call.Method.Val == "" && len(call.Args) == 1 {
+			if name, ok := call.Target.(ast.Name); ok && c.scope.lookupVar(name.Val) == nil {
+				t := c.underlying(c.exp(call.Args[0]))
+				switch name.Val {
+				case "ipairs":
+					switch x := t.(type) {
+					case *arrayType:
+						return []Type{integerType, x.elem}
+					case *mapType:
+						return []Type{integerType, x.value}
+					}
+				case "pairs":
+					switch x := t.(type) {
+					case *arrayType:
+						return []Type{integerType, x.elem}
+					case *mapType:
+						return []Type{x.key, x.value}
+					}
+				}
+				return nil
+			}
+		}
+	}
+	for _, p := range params {
+		c.exp(p)
+	}
+	return nil
+}
+
+// returnValues checks the values returned by a return statement.
+func (c *checker) returnValues(exps []ast.ExpNode) {
+	if !c.fn.checked {
+		for _, e := range exps {
+			c.exp(e)
+		}
+		return
+	}
+	// Return statements have no location, so missing values are reported at
+	// the last value returned or at the end of the function.
+	loc := c.fn.end
+	if len(exps) > 0 {
+		loc = exps[len(exps)-1]
+	}
+	c.checkValues(exps, c.fn.returns, true, loc, func(i int) string {
+		return fmt.Sprintf("return value #%d", i+1)
+	})
+}
+
+// checkValues checks that the values of a list of expressions have the
+// expected types.  If an expected type is nil, the value is not checked.  If
+// strict is true, it is an error to have more values than expected.  It
+// returns the types of the values.
+func (c *checker) checkValues(exps []ast.ExpNode, want typeList, strict bool, loc ast.Locator, desc func(int) string) []Type {
+	var got []Type
+	tooMany := func(e ast.ExpNode) {
+		c.errorf(e, "too many %s (expected %d)", plural(desc(len(want.types))), len(want.types))
+	}
+	for i, e := range exps {
+		if i == len(exps)-1 && isMultiValued(e) {
+			values := c.multiValues(e)
+			for j, t := range values.types {
+				k := i + j
+				got = append(got, t)
+				if w := want.at(k); w != nil {
+					c.checkAssignable(t, w, e, desc(k))
+				} else if strict && k == len(want.types) {
+					tooMany(e)
+				}
+			}
+			rest := values.etc
+			if rest == nil {
+				rest = nilType
+			}
+			for k := i + len(values.types); k < len(want.types); k++ {
+				got = append(got, rest)
+				if w := want.types[k]; w != nil {
+					c.checkAssignable(rest, w, e, desc(k))
+				}
+			}
+			if values.etc != nil && want.etc != nil {
+				c.checkAssignable(values.etc, want.etc, e, desc(max(i+len(values.types), len(want.types))))
+			}
+			return got
+		}
+		w := want.at(i)
+		if w == nil && strict && i == len(want.types) {
+			tooMany(e)
+		}
+		got = append(got, c.checkExp(e, w, desc(i)))
+	}
+	for k := len(exps); k < len(want.types); k++ {
+		if w := want.types[k]; w != nil && !c.assignable(nilType, w) {
+			c.errorf(loc, "missing %s of type %s", desc(k), w)
+		}
+	}
+	return got
+}
+
+func max(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+// plural returns the plural of a value description, e.g. "arguments" for
+// "argument #2".
+func plural(desc string) string {
+	if i := strings.Index(desc, " #"); i >= 0 {
+		desc = desc[:i]
+	}
+	return desc + "s"
+}
+
+// endLocation returns the location of the end of a function.
+func endLocation(f ast.Function) ast.Locator {
+	if end := f.EndPos(); end != nil {
+		return ast.LocFromToken(&token.Token{Pos: *end})
+	}
+	return f
+}
+
+//
+// Functions
+//
+
+// functionType returns the type of a function definition: a funcType if it has
+// type annotations, functionType otherwise.
+func (c *checker) functionType(f ast.Function) Type {
+	if f.ParamTypes == nil && f.DotsType == nil && f.ReturnTypes == nil {
+		return functionType
+	}
+	ft := &funcType{returns: anyValues}
+	for i := range f.Params {
+		t := Type(anyType)
+		if f.ParamTypes != nil && f.ParamTypes[i] != nil {
+			t = c.resolveType(f.ParamTypes[i])
+		}
+		ft.params.types = append(ft.params.types, t)
+	}
+	if f.HasDots {
+		ft.params.etc = anyType
+		if f.DotsType != nil {
+			ft.params.etc = c.resolveType(f.DotsType)
+		}
+	}
+	if f.ReturnTypes != nil {
+		ft.returns = c.resolveTypeListIn(c.scope, *f.ReturnTypes)
+	}
+	return ft
+}
+
+// functionBody checks the body of a function, whose type is ft.
+func (c *checker) functionBody(f ast.Function, ft Type) {
+	fn := &funcContext{returns: anyValues}
+	var params typeList
+	if t, ok := ft.(*funcType); ok {
+		params = t.params
+		fn.returns = t.returns
+		fn.checked = f.ReturnTypes != nil
+	}
+	if f.HasDots {
+		fn.dots = params.etc
+		if fn.dots == nil {
+			fn.dots = anyType
+		}
+	}
+	c.checkFunction(f, params, fn)
+}
+
+func (c *checker) checkFunction(f ast.Function, params typeList, fn *funcContext) {
+	outer := c.fn
+	c.fn = fn
+	fn.end = endLocation(f)
+	c.pushScope()
+	for i, p := range f.Params {
+		t := Type(anyType)
+		if i < len(params.types) {
+			t = params.types[i]
+		}
+		c.declare(p.Val, t)
+	}
+	body := f.Body
+	c.declareTypes(body.TypeAliases)
+	for _, s := range body.Stats {
+		c.stat(s)
+	}
+	// The body of a function always ends with a return statement (see
+	// ast.NewFunction), which may not be reachable.
+	if len(body.Return) > 0 || !terminates(body.Stats) {
+		c.returnValues(body.Return)
+	}
+	c.popScope()
+	c.fn = outer
+}
+
+// terminates returns true if control cannot reach the end of a list of
+// statements.
+func terminates(stats []ast.Stat) bool {
+	if len(stats) == 0 {
+		return false
+	}
+	switch x := stats[len(stats)-1].(type) {
+	case ast.BlockStat:
+		return x.Return != nil || terminates(x.Stats)
+	case ast.IfStat:
+		if x.Else == nil || !blockTerminates(x.If.Body) || !blockTerminates(*x.Else) {
+			return false
+		}
+		for _, cs := range x.ElseIfs {
+			if !blockTerminates(cs.Body) {
+				return false
+			}
+		}
+		return true
+	case ast.FunctionCall:
+		name, ok := x.Target.(ast.Name)
+		return ok && name.Val == "error" && x.Method.Val == ""
+	case ast.RepeatStat:
+		return blockTerminates(x.Body)
+	case ast.WhileStat:
+		b, ok := x.Cond.(ast.Bool)
+		return ok && b.Val && !hasBreak(x.Body)
+	case ast.GotoStat:
+		return true
+	}
+	return false
+}
+
+func blockTerminates(b ast.BlockStat) bool {
+	return b.Return != nil || terminates(b.Stats)
+}
+
+// hasBreak returns true if the block contains a break statement that exits the
+// loop whose body it is.
+func hasBreak(b ast.BlockStat) bool {
+	found := false
+	ast.Inspect(b, func(n ast.Node) bool {
+		switch n.(type) {
+		case ast.BreakStat:
+			found = true
+		case ast.WhileStat, ast.RepeatStat, ast.ForStat, *ast.ForStat, ast.ForInStat, *ast.ForInStat, ast.Function:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+//
+// Expressions
+//
+
+// derefExp returns the value pointed to by expressions which the parser
+// creates as pointers.
+func derefExp(e ast.ExpNode) ast.ExpNode {
+	switch x := e.(type) {
+	case *ast.BinOp:
+		return *x
+	case *ast.UnOp:
+		return *x
+	case *ast.BFunctionCall:
+		return *x
+	}
+	return e
+}
+
+func isMultiValued(e ast.ExpNode) bool {
+	switch e.(type) {
+	case ast.FunctionCall, ast.Etc:
+		return true
+	}
+	return false
+}
+
+// multiValues returns the types of the values of an expression which can have
+// several values (i.e. function calls and "...").
+func (c *checker) multiValues(e ast.ExpNode) typeList {
+	switch x := e.(type) {
+	case ast.FunctionCall:
+		return c.call(*x.BFunctionCall)
+	case ast.Etc:
+		return typeList{etc: c.dots()}
+	}
+	return typeList{types: []Type{c.exp(e)}}
+}
+
+func (c *checker) dots() Type {
+	if c.fn.dots == nil {
+		return anyType
+	}
+	return c.fn.dots
+}
+
+// first returns the type of the first value in a list.
+func first(l typeList) Type {
+	if t := l.at(0); t != nil {
+		return t
+	}
+	return nilType
+}
+
+// checkExp checks that the value of an expression has the expected type
+// (unless it is nil) and returns its type.  Table constructors and functions
+// without annotations are checked using the expected type.
+func (c *checker) checkExp(e ast.ExpNode, want Type, desc string) Type {
+	if want == nil || want == anyType {
+		return c.exp(e)
+	}
+	switch x := e.(type) {
+	case ast.TableConstructor:
+		if c.tableWithType(x, want, desc) {
+			return want
+		}
+	case ast.Function:
+		if ft, ok := c.underlying(want).(*funcType); ok && x.ParamTypes == nil && x.DotsType == nil && x.ReturnTypes == nil {
+			fn := &funcContext{returns: ft.returns, checked: true}
+			if x.HasDots {
+				fn.dots = ft.params.etc
+			}
+			c.checkFunction(x, ft.params, fn)
+			return want
+		}
+	}
+	t := c.exp(e)
+	c.checkAssignable(t, want, e, desc)
+	return t
+}
+
+// exp checks an expression and returns the type of its (first) value.
+func (c *checker) exp(e ast.ExpNode) Type {
+	switch x := derefExp(e).(type) {
+	case ast.Nil:
+		return nilType
+	case ast.Bool:
+		return booleanType
+	case ast.Int:
+		return integerType
+	case ast.Float:
+		return numberType
+	case ast.String:
+		return stringType
+	case ast.Etc:
+		return c.dots()
+	case ast.Name:
+		if v := c.scope.lookupVar(x.Val); v != nil {
+			return v.current
+		}
+		if t, ok := builtinGlobals[x.Val]; ok {
+			return t
+		}
+		return anyType
+	case ast.IndexExp:
+		return c.index(x)
+	case ast.FunctionCall:
+		return first(c.call(*x.BFunctionCall))
+	case ast.BFunctionCall:
+		return first(c.call(x))
+	case ast.Function:
+		ft := c.functionType(x)
+		c.functionBody(x, ft)
+		return ft
+	case ast.TableConstructor:
+		return c.tableConstructor(x)
+	case ast.BinOp:
+		return c.binOp(x)
+	case ast.UnOp:
+		return c.unOp(x)
+	case ast.InterpolatedString:
+		// Any value can be converted with tostring
+		for _, e := range x.Exps {
+			c.exp(e)
+		}
+		return stringType
+	default:
+		panic(fmt.Sprintf("typecheck: unexpected expression %T", e))
+	}
+}
+
+// call checks a function call and returns the types of the values it returns.
+func (c *checker) call(f ast.BFunctionCall) typeList {
+	var ft Type
+	isMethod := f.Method.Val != ""
+	if isMethod {
+		ft = c.field(c.exp(f.Target), f.Method.Val, f.Method)
+	} else {
+		ft = c.exp(f.Target)
+	}
+	switch t := c.underlying(ft).(type) {
+	case *funcType:
+		params := t.params
+		if isMethod && len(params.types) > 0 {
+			// The first parameter is the object
+			params.types = params.types[1:]
+		}
+		c.checkValues(f.Args, params, true, f, func(i int) string {
+			return fmt.Sprintf("argument #%d", i+1)
+		})
+		return t.returns
+	case basicType:
+		switch t {
+		case nilType, booleanType, numberType, integerType, stringType:
+			c.errorf(f.Target, "attempt to call a %s value", t)
+		}
+	}
+	for _, arg := range f.Args {
+		c.exp(arg)
+	}
+	return anyValues
+}
+
+// index checks an index expression and returns its type.
+func (c *checker) index(e ast.IndexExp) Type {
+	coll := c.exp(e.Coll)
+	if key, ok := e.Idx.(ast.String); ok {
+		return c.field(coll, string(key.Val), e.Idx)
+	}
+	key := c.exp(e.Idx)
+	switch t := c.underlying(coll).(type) {
+	case *arrayType:
+		if !c.assignable(key, numberType) {
+			c.errorf(e.Idx, "cannot index %s with %s", coll, key)
+		}
+		return t.elem
+	case *mapType:
+		if !c.assignable(key, t.key) {
+			c.errorf(e.Idx, "cannot index %s with %s", coll, key)
+		}
+		return t.value
+	}
+	c.checkIndexable(coll, e.Coll)
+	return anyType
+}
+
+// field returns the type of the field of a value of type t with the given
+// name.
+func (c *checker) field(t Type, name string, loc ast.Locator) Type {
+	switch u := c.underlying(t).(type) {
+	case *recordType:
+		if ft, ok := u.fields[name]; ok {
+			return ft
+		}
+		c.errorf(loc, "field '%s' does not exist in %s", name, t)
+	case *mapType:
+		if !c.assignable(stringType, u.key) {
+			c.errorf(loc, "cannot index %s with string", t)
+		}
+		return u.value
+	case *arrayType:
+		c.errorf(loc, "cannot index %s with string", t)
+	default:
+		c.checkIndexable(t, loc)
+	}
+	return anyType
+}
+
+func (c *checker) checkIndexable(t Type, loc ast.Locator) {
+	switch u := c.underlying(t).(type) {
+	case basicType:
+		switch u {
+		case nilType, booleanType, numberType, integerType, functionType:
+			c.errorf(loc, "attempt to index a %s value", u)
+		}
+	case *funcType:
+		c.errorf(loc, "attempt to index a function value")
+	}
+}
+
+// tableConstructor checks a table constructor and returns its type.
+func (c *checker) tableConstructor(t ast.TableConstructor) Type {
+	var elems []Type
+	rec := newRecordType()
+	other := false
+	for i, f := range t.Fields {
+		switch k := f.Key.(type) {
+		case ast.NoTableKey:
+			if i == len(t.Fields)-1 && isMultiValued(f.Value) {
+				values := c.multiValues(f.Value)
+				elems = append(elems, values.types...)
+				if values.etc != nil {
+					elems = append(elems, values.etc)
+				}
+			} else {
+				elems = append(elems, c.exp(f.Value))
+			}
+		case ast.String:
+			name := string(k.Val)
+			if _, ok := rec.fields[name]; ok {
+				other = true
+			}
+			rec.addField(name, c.exp(f.Value))
+		default:
+			c.exp(f.Key)
+			c.exp(f.Value)
+			other = true
+		}
+	}
+	switch {
+	case other || len(elems) > 0 && len(rec.names) > 0:
+		return tableType
+	case len(elems) > 0:
+		return &arrayType{elem: newUnion(elems...)}
+	default:
+		return rec
+	}
+}
+
+// tableWithType checks the fields of a table constructor against the expected
+// type.  It returns false if the expected type is not a table type, in which
+// case nothing is checked.
+func (c *checker) tableWithType(t ast.TableConstructor, want Type, desc string) bool {
+	u := c.underlying(want)
+	if union, ok := u.(*unionType); ok {
+		// Use the only table type in the union, if there is one
+		u = nil
+		for _, m := range union.types {
+			if isTableType(c.underlying(m)) {
+				if u != nil {
+					return false
+				}
+				u = c.underlying(m)
+			}
+		}
+	}
+	var keyType, valueType Type
+	var rec *recordType
+	switch x := u.(type) {
+	case *recordType:
+		rec = x
+	case *arrayType:
+		keyType, valueType = integerType, x.elem
+	case *mapType:
+		keyType, valueType = x.key, x.value
+	default:
+		return false
+	}
+	provided := map[string]bool{}
+	position := 0
+	for i, f := range t.Fields {
+		switch k := f.Key.(type) {
+		case ast.NoTableKey:
+			position++
+			if rec != nil {
+				c.errorf(f.Value, "%s: unexpected positional field in %s", desc, want)
+				c.exp(f.Value)
+				continue
+			}
+			if !c.assignable(integerType, keyType) {
+				c.errorf(f.Value, "%s: unexpected positional field in %s", desc, want)
+			}
+			if i == len(t.Fields)-1 && isMultiValued(f.Value) {
+				values := c.multiValues(f.Value)
+				for _, vt := range values.types {
+					c.checkAssignable(vt, valueType, f.Value, fmt.Sprintf("%s, item #%d", desc, position))
+				}
+				if values.etc != nil {
+					c.checkAssignable(values.etc, valueType, f.Value, fmt.Sprintf("%s, item #%d", desc, position))
+				}
+			} else {
+				c.checkExp(f.Value, valueType, fmt.Sprintf("%s, item #%d", desc, position))
+			}
+		case ast.String:
+			name := string(k.Val)
+			fieldDesc := fmt.Sprintf("%s, field '%s'", desc, name)
+			if rec == nil {
+				if !c.assignable(stringType, keyType) {
+					c.errorf(f.Key, "%s: unexpected field '%s' in %s", desc, name, want)
+				}
+				c.checkExp(f.Value, valueType, fieldDesc)
+				continue
+			}
+			ft, ok := rec.fields[name]
+			if !ok {
+				c.errorf(f.Key, "%s: field '%s' does not exist in %s", desc, name, want)
+				c.exp(f.Value)
+				continue
+			}
+			provided[name] = true
+			c.checkExp(f.Value, ft, fieldDesc)
+		default:
+			if rec != nil {
+				c.exp(f.Key)
+				c.exp(f.Value)
+				continue
+			}
+			c.checkExp(f.Key, keyType, desc+", key")
+			c.checkExp(f.Value, valueType, desc+", value")
+		}
+	}
+	if rec != nil {
+		for _, name := range rec.names {
+			if ft := rec.fields[name]; !provided[name] && !c.assignable(nilType, ft) {
+				c.errorf(t, "%s: missing field '%s' of type %s", desc, name, ft)
+			}
+		}
+	}
+	return true
+}
+
+// binOp checks a binary operation and returns its type.
+func (c *checker) binOp(e ast.BinOp) Type {
+	t := c.exp(e.Left)
+	for _, op := range e.Right {
+		t = c.binOpType(op.Op, t, c.exp(op.Operand), e)
+	}
+	return t
+}
+
+func (c *checker) binOpType(op ops.Op, l, r Type, loc ast.Locator) Type {
+	switch op {
+	case ops.OpAnd:
+		if !c.canBeFalsy(l) {
+			return r
+		}
+		if c.underlying(l) == anyType {
+			return anyType
+		}
+		return newUnion(c.falsyPart(l), r)
+	case ops.OpOr:
+		if !c.canBeFalsy(l) {
+			return l
+		}
+		return newUnion(c.removeNil(l), r)
+	case ops.OpEq, ops.OpNeq:
+		return booleanType
+	case ops.OpLt, ops.OpLeq, ops.OpGt, ops.OpGeq:
+		lu, ru := c.underlying(l), c.underlying(r)
+		if c.isNumber(lu) && ru == stringType || lu == stringType && c.isNumber(ru) {
+			c.errorf(loc, "attempt to compare %s with %s", l, r)
+		} else {
+			c.checkOperand(l, "compare", loc)
+			c.checkOperand(r, "compare", loc)
+		}
+		return booleanType
+	case ops.OpConcat:
+		c.checkOperand(l, "concatenate", loc)
+		c.checkOperand(r, "concatenate", loc)
+		return stringType
+	case ops.OpBitOr, ops.OpBitXor, ops.OpBitAnd, ops.OpShiftL, ops.OpShiftR:
+		c.checkOperand(l, "perform bitwise operation on", loc)
+		c.checkOperand(r, "perform bitwise operation on", loc)
+		if c.isNumber(l) && c.isNumber(r) {
+			return integerType
+		}
+		return anyType
+	default:
+		c.checkOperand(l, "perform arithmetic on", loc)
+		c.checkOperand(r, "perform arithmetic on", loc)
+		lu, ru := c.underlying(l), c.underlying(r)
+		switch {
+		case lu == integerType && ru == integerType && op != ops.OpDiv && op != ops.OpPow:
+			return integerType
+		case c.isNumber(lu) && c.isNumber(ru):
+			return numberType
+		}
+		return anyType
+	}
+}
+
+// falsyPart returns the type of the values of type t which are nil or false.
+func (c *checker) falsyPart(t Type) Type {
+	var types []Type
+	var visit func(Type)
+	visit = func(t Type) {
+		switch u := c.underlying(t).(type) {
+		case basicType:
+			if u == nilType || u == booleanType {
+				types = append(types, u)
+			}
+		case *unionType:
+			for _, m := range u.types {
+				visit(m)
+			}
+		}
+	}
+	visit(t)
+	return newUnion(types...)
+}
+
+// checkOperand reports an error if values of type t cannot be operands of the
+// given operation (which only metamethods can implement).
+func (c *checker) checkOperand(t Type, operation string, loc ast.Locator) {
+	switch u := c.underlying(t).(type) {
+	case basicType:
+		switch u {
+		case nilType, booleanType, functionType:
+			c.errorf(loc, "attempt to %s a %s value", operation, u)
+		}
+	case *funcType:
+		c.errorf(loc, "attempt to %s a function value", operation)
+	}
+}
+
+// unOp checks a unary operation and returns its type.
+func (c *checker) unOp(e ast.UnOp) Type {
+	t := c.exp(e.Operand)
+	switch e.Op {
+	case ops.OpNot:
+		return booleanType
+	case ops.OpLen:
+		switch u := c.underlying(t).(type) {
+		case basicType:
+			switch u {
+			case nilType, booleanType, numberType, integerType, functionType:
+				c.errorf(e, "attempt to get length of a %s value", u)
+			}
+		case *funcType:
+			c.errorf(e, "attempt to get length of a function value")
+		}
+		return integerType
+	case ops.OpBitNot:
+		c.checkOperand(t, "perform bitwise operation on", e)
+		if c.isNumber(t) {
+			return integerType
+		}
+		return anyType
+	default:
+		c.checkOperand(t, "perform arithmetic on", e)
+		if u := c.underlying(t); c.isNumber(u) {
+			return u
+		}
+		return anyType
+	}
+}
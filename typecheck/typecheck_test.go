@@ -0,0 +1,381 @@
+package typecheck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arnodel/golua/ast"
+	"github.com/arnodel/golua/parsing"
+	"github.com/arnodel/golua/scanner"
+	"github.com/arnodel/golua/token"
+)
+
+func parse(t *testing.T, src string) ast.BlockStat {
+	chunk, err := parsing.ParseChunk(
+		scanner.New("test", []byte(src), scanner.WithTypes(), scanner.WithExtensions(token.AllExtensions)),
+		parsing.WithTypes(), parsing.WithExtensions(token.AllExtensions),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chunk
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "no annotations",
+			src: `
+local t = {}
+function t.f(x, ...) return x + 1, #t, ... end
+local s = t.f(1) .. "x"
+for i = 1, 10 do t[i] = s end
+return t`,
+		},
+		{
+			name: "locals",
+			src: `
+local n: number = 1
+local i: integer = 1.5
+local s: string, b: boolean = "x", 2
+local o: string? = nil
+local m: string = nil
+local x: number = n
+x = "foo"`,
+			want: []string{
+				"3:20: local 'i': number is not assignable to integer",
+				"4:36: local 'b': integer is not assignable to boolean",
+				"6:19: local 'm': nil is not assignable to string",
+				"8:5: variable 'x': string is not assignable to number",
+			},
+		},
+		{
+			name: "unknown types",
+			src: `
+local x: Foo = 1
+type number = string
+type A = B
+type B = A`,
+			want: []string{
+				"2:10: unknown type 'Foo'",
+				"3:6: cannot redefine builtin type 'number'",
+				"4:10: type alias 'A' is circular",
+			},
+		},
+		{
+			name: "function arguments",
+			src: `
+local function f(x: number, y: string?): number
+    return x
+end
+f(1)
+f(1, "a")
+f("a", "b")
+f(1, "a", 3)
+f()
+local g = f
+g(true)`,
+			want: []string{
+				"7:3: argument #1: string is not assignable to number",
+				"8:11: too many arguments (expected 2)",
+				"9:1: missing argument #1 of type number",
+				"11:3: argument #1: boolean is not assignable to number",
+			},
+		},
+		{
+			name: "return values",
+			src: `
+local function f(x: number): (string, number)
+    if x > 0 then
+        return "pos", x
+    elseif x < 0 then
+        return "neg"
+    end
+    return x, x, x
+end
+local function g(): number
+    if true then
+        return 1
+    end
+end
+local function h(x: any): number
+    if x then
+        return 1
+    else
+        error("no")
+    end
+end`,
+			want: []string{
+				"6:16: missing return value #2 of type number",
+				"8:12: return value #1: number is not assignable to string",
+				"8:18: too many return values (expected 2)",
+				"14:1: missing return value #1 of type number",
+			},
+		},
+		{
+			name: "multiple values",
+			src: `
+local function two(): (number, string)
+    return 1, "a"
+end
+local a: number, b: string = two()
+local c: string, d: number = two()
+local e: number, f: string, g: boolean = two()
+local function f2(x: number, y: string) end
+f2(two())
+f2("x", two())`,
+			want: []string{
+				"6:30: local 'c': number is not assignable to string",
+				"6:30: local 'd': string is not assignable to number",
+				"7:42: local 'g': nil is not assignable to boolean",
+				"10:4: argument #1: string is not assignable to number",
+				"10:9: argument #2: number is not assignable to string",
+				"10:9: too many arguments (expected 2)",
+			},
+		},
+		{
+			name: "records",
+			src: `
+type Point = {x: number, y: number}
+local p: Point = {x = 1, y = 2}
+local q: Point = {x = 1}
+local r: Point = {x = 1, y = 2, z = 3}
+local s: Point = {x = "a", y = 2}
+print(p.x, p.z)
+p.y = "b"
+local function norm(p: Point): number
+    return p.x * p.x + p.y * p.y
+end
+norm({x = 1, y = true})`,
+			want: []string{
+				"4:18: local 'q': missing field 'y' of type number",
+				"5:33: local 'r': field 'z' does not exist in Point",
+				"6:23: local 's', field 'x': string is not assignable to number",
+				"7:14: field 'z' does not exist in Point",
+				"8:7: field 'y': string is not assignable to number",
+				"12:18: argument #1, field 'y': boolean is not assignable to number",
+			},
+		},
+		{
+			name: "arrays and maps",
+			src: `
+local a: {string} = {"a", "b", 3}
+local m: {[string]: number} = {x = 1, ["y"] = 2, [3] = 4}
+local s: string = a[1]
+local n: number = m.x
+local b: boolean = a[2]
+a.x = 1
+for i, v in ipairs(a) do
+    local w: number = v
+end
+for k, v in pairs(m) do
+    local k2: number = k
+end`,
+			want: []string{
+				"2:32: local 'a', item #3: integer is not assignable to string",
+				"3:51: local 'm', key: integer is not assignable to string",
+				"6:20: local 'b': string is not assignable to boolean",
+				"7:3: cannot index {string} with string",
+				"9:23: local 'w': string is not assignable to number",
+				"12:24: local 'k2': string is not assignable to number",
+			},
+		},
+		{
+			name: "function types",
+			src: `
+type Handler = (string) -> boolean
+local h: Handler = function(s) return #s > 0 end
+local h2: Handler = function(s) return s end
+local function apply(f: (number) -> number, x: number): number
+    return f(x)
+end
+apply(function(x) return x * 2 end, 1)
+apply(function(x: number): string return "" end, 1)
+apply(print, 2)
+local function num(x: number): number return x end
+local h3: Handler = num`,
+			want: []string{
+				"4:40: return value #1: string is not assignable to boolean",
+				"9:15: argument #1: (number) -> string is not assignable to (number) -> number",
+				"12:21: local 'h3': (number) -> number is not assignable to Handler",
+			},
+		},
+		{
+			name: "recursive types",
+			src: `
+type List = {value: number, next: List?}
+local l: List = {value = 1, next = {value = 2}}
+local function sum(l: List?): number
+    if l then
+        return l.value + sum(l.next)
+    end
+    return 0
+end
+local l2: List = {value = 1, next = {value = "x"}}`,
+			want: []string{
+				"10:46: local 'l2', field 'next', field 'value': string is not assignable to number",
+			},
+		},
+		{
+			name: "narrowing",
+			src: `
+local function f(s: string?, t: {number}?): number
+    local n: string = s
+    if s ~= nil then
+        n = s
+    end
+    if t and #t > 0 then
+        return t[1]
+    end
+    if s then
+        s = nil
+        local u: string = s
+        return #n
+    end
+    return 0
+end`,
+			want: []string{
+				"3:23: local 'n': string? is not assignable to string",
+				"12:27: local 'u': string? is not assignable to string",
+			},
+		},
+		{
+			name: "operations",
+			src: `
+local b: boolean = true
+local n: number = 1
+local f: () -> () = function() end
+print(b + 1, -b, n .. "x", b .. "x", n < "1", f.x, n(), #n)
+local s: string = n + 1`,
+			want: []string{
+				"5:9: attempt to perform arithmetic on a boolean value",
+				"5:14: attempt to perform arithmetic on a boolean value",
+				"5:30: attempt to concatenate a boolean value",
+				"5:40: attempt to compare number with string",
+				"5:49: attempt to index a function value",
+				"5:52: attempt to call a number value",
+				"5:57: attempt to get length of a number value",
+				"6:21: local 's': number is not assignable to string",
+			},
+		},
+		{
+			name: "numeric for",
+			src: `
+for i = 1, 10 do
+    local s: string = i
+end
+for x = 1, 2, 0.5 do
+    local i: integer = x
+end
+for i = "a", 10 do end`,
+			want: []string{
+				"3:23: local 's': integer is not assignable to string",
+				"6:24: local 'i': number is not assignable to integer",
+				"8:9: 'for' initial value must be a number, not string",
+			},
+		},
+		{
+			name: "varargs",
+			src: `
+local function f(...: number): ...number
+    local x: string = ...
+    return ...
+end
+local a: number, b: string = f(1, 2, "3")`,
+			want: []string{
+				"3:23: local 'x': number is not assignable to string",
+				"6:30: local 'b': number is not assignable to string",
+				"6:38: argument #3: string is not assignable to number",
+			},
+		},
+		{
+			name: "method calls",
+			src: `
+type Counter = {n: integer, inc: (Counter, integer) -> integer}
+local c: Counter = {n = 0, inc = function(self, k) self.n = self.n + k return self.n end}
+c:inc(1)
+c:inc("x")
+c:dec()`,
+			want: []string{
+				"5:7: argument #1: string is not assignable to integer",
+				"6:3: field 'dec' does not exist in Counter",
+			},
+		},
+		{
+			name: "scoped aliases",
+			src: `
+do
+    type T = number
+    local x: T = 1
+end
+local y: T = 1`,
+			want: []string{
+				"6:10: unknown type 'T'",
+			},
+		},
+		{
+			name: "syntax extensions",
+			src: `
+local n: integer = 1
+n += 1
+n /= 2
+local p: {x: string} = {x = "a"}
+p.x ..= ` + "`{n}`" + `
+local b: boolean = true
+b += 1
+for i = 1, 10 do
+    if i > n then continue end
+end`,
+			want: []string{
+				"4:6: variable 'n': number is not assignable to integer",
+				"8:1: attempt to perform arithmetic on a boolean value",
+			},
+		},
+		{
+			name: "builtins",
+			src: `
+local s: string = tostring(1)
+local n: number = type(s)
+local function f(tostring) local x: number = tostring(1) end`,
+			want: []string{
+				"3:19: local 'n': string is not assignable to number",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, err := range Check(parse(t, test.src)) {
+				got = append(got, err.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	chunk := parse(t, `
+type P = {x: number}
+local p: P = {x = 1}
+local function f(a: number, ...: string): number return a end`)
+	stripped := Strip(chunk)
+	if stripped.TypeAliases != nil {
+		t.Error("type aliases not stripped")
+	}
+	if stripped.Stats[0].(ast.LocalStat).NameAttribs[0].Type != nil {
+		t.Error("local type not stripped")
+	}
+	f := stripped.Stats[1].(ast.LocalFunctionStat).Function
+	if f.ParamTypes != nil || f.DotsType != nil || f.ReturnTypes != nil {
+		t.Error("function types not stripped")
+	}
+	if chunk.Stats[0].(ast.LocalStat).NameAttribs[0].Type == nil {
+		t.Error("original chunk modified")
+	}
+}
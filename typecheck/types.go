@@ -0,0 +1,214 @@
+package typecheck
+
+import (
+	"strings"
+
+	"github.com/arnodel/golua/ast"
+)
+
+// A Type is the type of a Lua value as known by the checker.
+type Type interface {
+	String() string
+}
+
+// A basicType is a type given by a name.
+type basicType string
+
+// Basic types.  Values of type anyType are compatible with all types; it is the
+// type of all values whose type is not known (e.g. variables without
+// annotations).
+const (
+	anyType      basicType = "any"
+	nilType      basicType = "nil"
+	booleanType  basicType = "boolean"
+	numberType   basicType = "number"
+	integerType  basicType = "integer"
+	stringType   basicType = "string"
+	tableType    basicType = "table"
+	functionType basicType = "function"
+	threadType   basicType = "thread"
+	userdataType basicType = "userdata"
+)
+
+var basicTypes = map[string]basicType{}
+
+func init() {
+	for _, t := range []basicType{
+		anyType, nilType, booleanType, numberType, integerType,
+		stringType, tableType, functionType, threadType, userdataType,
+	} {
+		basicTypes[string(t)] = t
+	}
+}
+
+func (t basicType) String() string {
+	return string(t)
+}
+
+// arrayType is the type of tables whose values of type elem are indexed by
+// integers.
+type arrayType struct {
+	elem Type
+}
+
+func (t *arrayType) String() string {
+	return "{" + t.elem.String() + "}"
+}
+
+// mapType is the type of tables with keys of type key and values of type
+// value.
+type mapType struct {
+	key, value Type
+}
+
+func (t *mapType) String() string {
+	return "{[" + t.key.String() + "]: " + t.value.String() + "}"
+}
+
+// recordType is the type of tables with the given fields.
+type recordType struct {
+	names  []string // In definition order
+	fields map[string]Type
+}
+
+func newRecordType() *recordType {
+	return &recordType{fields: map[string]Type{}}
+}
+
+func (t *recordType) addField(name string, tp Type) {
+	t.names = append(t.names, name)
+	t.fields[name] = tp
+}
+
+func (t *recordType) String() string {
+	parts := make([]string, len(t.names))
+	for i, name := range t.names {
+		parts[i] = name + ": " + t.fields[name].String()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// funcType is the type of functions whose parameters and return values are
+// annotated.
+type funcType struct {
+	params  typeList
+	returns typeList
+}
+
+func (t *funcType) String() string {
+	ret := t.returns.String()
+	if len(t.returns.types) == 1 && t.returns.etc == nil {
+		ret = operandString(t.returns.types[0])
+	}
+	return t.params.String() + " -> " + ret
+}
+
+// A typeList is the type of a list of values: a fixed number of values
+// followed by any number of values of type etc if it is not nil.
+type typeList struct {
+	types []Type
+	etc   Type
+}
+
+// at returns the type of the i-th value in the list, or nil if there is none.
+func (l typeList) at(i int) Type {
+	if i < len(l.types) {
+		return l.types[i]
+	}
+	return l.etc
+}
+
+func (l typeList) String() string {
+	parts := make([]string, len(l.types), len(l.types)+1)
+	for i, tp := range l.types {
+		parts[i] = tp.String()
+	}
+	if l.etc != nil {
+		parts = append(parts, "..."+operandString(l.etc))
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// anyValues is the type of a list of values of unknown length and types.
+var anyValues = typeList{etc: anyType}
+
+// unionType is the type of values of any of its member types.  Use newUnion to
+// create instances.
+type unionType struct {
+	types []Type
+}
+
+func (t *unionType) String() string {
+	if len(t.types) == 2 && t.types[1] == nilType {
+		return operandString(t.types[0]) + "?"
+	}
+	parts := make([]string, len(t.types))
+	for i, tp := range t.types {
+		parts[i] = operandString(tp)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// newUnion returns the union of the given types, flattening unions and
+// removing duplicates.
+func newUnion(types ...Type) Type {
+	var members []Type
+	hasNil := false
+	add := func(t Type) {
+		if t == nilType {
+			hasNil = true
+			return
+		}
+		for _, m := range members {
+			if m == t {
+				return
+			}
+		}
+		members = append(members, t)
+	}
+	for _, t := range types {
+		if t == anyType {
+			return anyType
+		}
+		if u, ok := t.(*unionType); ok {
+			for _, m := range u.types {
+				add(m)
+			}
+		} else {
+			add(t)
+		}
+	}
+	// Put nil last so that optional types print as "T?"
+	if hasNil {
+		members = append(members, nilType)
+	}
+	if len(members) == 1 {
+		return members[0]
+	}
+	return &unionType{types: members}
+}
+
+// aliasType is a type defined with "type Name = T".  Its definition is
+// resolved when it is needed, so that aliases can be recursive.
+type aliasType struct {
+	name     string
+	def      ast.TypeNode
+	scope    *scope // The scope where the alias is defined
+	resolved Type
+}
+
+func (t *aliasType) String() string {
+	return t.name
+}
+
+func operandString(t Type) string {
+	switch x := t.(type) {
+	case *funcType:
+		return "(" + t.String() + ")"
+	case *unionType:
+		if len(x.types) != 2 || x.types[1] != nilType {
+			return "(" + t.String() + ")"
+		}
+	}
+	return t.String()
+}